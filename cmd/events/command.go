@@ -0,0 +1,40 @@
+package events
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jrasell/sherpa/cmd/events/get"
+	"github.com/jrasell/sherpa/cmd/events/report"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Interact with and analyse the scaling events held by a Sherpa server",
+		Run: func(cmd *cobra.Command, args []string) {
+			runEvents(cmd, args)
+		},
+	}
+	rootCmd.AddCommand(cmd)
+
+	if err := registerCommands(cmd); err != nil {
+		fmt.Println("Error registering commands:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	return nil
+}
+
+func runEvents(cmd *cobra.Command, _ []string) {
+	_ = cmd.Usage()
+}
+
+func registerCommands(rootCmd *cobra.Command) error {
+	if err := get.RegisterCommand(rootCmd); err != nil {
+		return err
+	}
+	return report.RegisterCommand(rootCmd)
+}
@@ -0,0 +1,217 @@
+package get
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jrasell/sherpa/cmd/helper"
+	"github.com/jrasell/sherpa/pkg/api"
+	clientCfg "github.com/jrasell/sherpa/pkg/config/client"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+const (
+	groupOutputHeader = "Job:Group|Direction|Count|DesiredCount"
+	checkOutputHeader = "Job:Group|Check|Value|Threshold"
+	snapOutputHeader  = "Job:Group|Metric|Value"
+	metaOutputHeader  = "Job:Group|Key|Value"
+
+	// The autoscaler stores the value and threshold of each check which triggered scaling as a
+	// pair of meta keys using these suffixes.
+	metaSuffixCheckValue     = "-value"
+	metaSuffixCheckThreshold = "-threshold"
+)
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "get <id>",
+		Short: "Display the detail of a single scaling event",
+		Run: func(cmd *cobra.Command, args []string) {
+			runGet(cmd, args)
+		},
+	}
+	rootCmd.AddCommand(cmd)
+
+	return nil
+}
+
+func runGet(_ *cobra.Command, args []string) {
+	if len(args) != 1 {
+		fmt.Println("Incorrect number of arguments, expected 1, got", len(args))
+		os.Exit(sysexits.Usage)
+	}
+
+	clientConfig := clientCfg.GetConfig()
+	mergedConfig := api.DefaultConfig(&clientConfig)
+
+	timeFormatter, err := helper.NewTimeFormatter(clientConfig.TimeFormat)
+	if err != nil {
+		fmt.Println("Error parsing time format:", err)
+		os.Exit(sysexits.Usage)
+	}
+
+	client, err := api.NewClient(mergedConfig)
+	if err != nil {
+		fmt.Println("Error setting up Sherpa client:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	resp, err := client.Scale().Info(args[0])
+	if err != nil {
+		fmt.Println("Error getting scaling event:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	if len(resp) == 0 {
+		fmt.Println("Scaling event not found:", args[0])
+		os.Exit(sysexits.NoInput)
+	}
+
+	jobGroups := make([]string, 0, len(resp))
+	for jg := range resp {
+		jobGroups = append(jobGroups, jg)
+	}
+	sort.Strings(jobGroups)
+
+	fmt.Println(helper.FormatKV(formatHeader(args[0], resp[jobGroups[0]], timeFormatter)))
+
+	groups, checks, meta := []string{groupOutputHeader}, []string{checkOutputHeader}, []string{metaOutputHeader}
+	snapshot := []string{snapOutputHeader}
+
+	for _, jg := range jobGroups {
+		event := resp[jg]
+		groups = append(groups, fmt.Sprintf("%s|%s|%v|%s",
+			jg, event.Details.Direction, event.Details.Count, formatDesiredCount(event.Details.DesiredCount)))
+
+		c, m := splitMeta(event.Meta)
+		for _, check := range c {
+			checks = append(checks, fmt.Sprintf("%s|%s|%s|%s", jg, check[0], check[1], check[2]))
+		}
+		for _, kv := range m {
+			meta = append(meta, fmt.Sprintf("%s|%s|%s", jg, kv[0], kv[1]))
+		}
+		for _, kv := range formatSnapshot(event.Snapshot) {
+			snapshot = append(snapshot, fmt.Sprintf("%s|%s|%s", jg, kv[0], kv[1]))
+		}
+	}
+
+	printSection("Groups", groups)
+	printSection("Checks Evaluated", checks)
+	printSection("Resource Snapshot", snapshot)
+	printSection("Meta", meta)
+
+	os.Exit(sysexits.OK)
+}
+
+// formatHeader builds the event detail which is shared by all the job groups of the scaling event.
+func formatHeader(id string, event *api.ScalingEvent, tf *helper.TimeFormatter) []string {
+	header := []string{
+		fmt.Sprintf("ID|%s", id),
+		fmt.Sprintf("EvalID|%s", orNone(event.EvalID)),
+		fmt.Sprintf("DeploymentID|%s", orNone(event.DeploymentID)),
+		fmt.Sprintf("Status|%s", event.Status),
+		fmt.Sprintf("Source|%s", event.Source),
+		fmt.Sprintf("Reason|%s", event.Reason),
+		fmt.Sprintf("Time|%s", tf.UnixNano(event.Time)),
+	}
+	if event.RunbookURL != "" {
+		header = append(header, fmt.Sprintf("RunbookURL|%s", event.RunbookURL))
+	}
+	if event.Notes != "" {
+		header = append(header, fmt.Sprintf("Notes|%s", event.Notes))
+	}
+	return header
+}
+
+// splitMeta separates the checks which triggered scaling from the remaining meta of the event.
+// Checks are returned as name, value and threshold, and the remaining meta as key and value, each
+// ordered by name.
+func splitMeta(meta map[string]string) ([][3]string, [][2]string) {
+	var (
+		checks [][3]string
+		other  [][2]string
+	)
+
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if strings.HasSuffix(k, metaSuffixCheckValue) {
+			name := strings.TrimSuffix(k, metaSuffixCheckValue)
+			if threshold, ok := meta[name+metaSuffixCheckThreshold]; ok {
+				checks = append(checks, [3]string{name, meta[k], threshold})
+				continue
+			}
+		}
+
+		if strings.HasSuffix(k, metaSuffixCheckThreshold) {
+			if _, ok := meta[strings.TrimSuffix(k, metaSuffixCheckThreshold)+metaSuffixCheckValue]; ok {
+				continue
+			}
+		}
+		other = append(other, [2]string{k, meta[k]})
+	}
+	return checks, other
+}
+
+// formatSnapshot returns the metric name and value of each entry of the resource snapshot, with
+// the Nomad resources first followed by the external checks ordered by name.
+func formatSnapshot(snap *api.ResourceSnapshot) [][2]string {
+	if snap == nil {
+		return nil
+	}
+
+	var out [][2]string
+
+	if n := snap.Nomad; n != nil {
+		out = append(out,
+			[2]string{"nomad-cpu", fmt.Sprintf("%.2f", n.CPU)},
+			[2]string{"nomad-memory", fmt.Sprintf("%.2f", n.Memory)},
+			[2]string{"nomad-disk", fmt.Sprintf("%.2f", n.Disk)},
+			[2]string{"nomad-gpu", fmt.Sprintf("%.2f", n.GPU)},
+			[2]string{"stats-coverage", fmt.Sprintf("%.2f", n.StatsCoverage)},
+		)
+	}
+
+	names := make([]string, 0, len(snap.External))
+	for name := range snap.External {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		out = append(out, [2]string{name, fmt.Sprintf("%.2f", snap.External[name])})
+	}
+	return out
+}
+
+// printSection prints the list output under the title, if the list contains entries beyond its
+// header.
+func printSection(title string, list []string) {
+	if len(list) < 2 {
+		return
+	}
+	fmt.Println("")
+	fmt.Println(title)
+	fmt.Println(helper.FormatList(list))
+}
+
+func formatDesiredCount(count int) string {
+	if count == 0 {
+		return "<none>"
+	}
+	return fmt.Sprint(count)
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "<none>"
+	}
+	return s
+}
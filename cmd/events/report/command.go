@@ -0,0 +1,99 @@
+package report
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/jrasell/sherpa/cmd/helper"
+	"github.com/jrasell/sherpa/pkg/api"
+	clientCfg "github.com/jrasell/sherpa/pkg/config/client"
+	"github.com/jrasell/sherpa/pkg/config/events"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+const outputHeader = "Job:Group|Out|In|Failed|FailureRate|DirectionChanges|Flapping|TimeAtMax|TimeAtMaxPercent"
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "report",
+		Short: "Display aggregated scaling statistics over a time range",
+		Run: func(cmd *cobra.Command, args []string) {
+			runReport(cmd, args)
+		},
+	}
+	rootCmd.AddCommand(cmd)
+	events.RegisterEventsReportConfig(cmd)
+
+	return nil
+}
+
+func runReport(_ *cobra.Command, args []string) {
+	if len(args) > 0 {
+		fmt.Println("Too many arguments, expected 0, got", len(args))
+		os.Exit(sysexits.Usage)
+	}
+
+	reportConfig := events.GetEventsReportConfig()
+
+	from, err := parseTime(reportConfig.From)
+	if err != nil {
+		fmt.Println("Error parsing from flag:", err)
+		os.Exit(sysexits.Usage)
+	}
+
+	to, err := parseTime(reportConfig.To)
+	if err != nil {
+		fmt.Println("Error parsing to flag:", err)
+		os.Exit(sysexits.Usage)
+	}
+
+	clientConfig := clientCfg.GetConfig()
+	mergedConfig := api.DefaultConfig(&clientConfig)
+
+	timeFormatter, err := helper.NewTimeFormatter(clientConfig.TimeFormat)
+	if err != nil {
+		fmt.Println("Error parsing time format:", err)
+		os.Exit(sysexits.Usage)
+	}
+
+	client, err := api.NewClient(mergedConfig)
+	if err != nil {
+		fmt.Println("Error setting up Sherpa client:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	resp, err := client.Scale().Report(from, to, reportConfig.FlapThreshold)
+	if err != nil {
+		fmt.Println("Error getting scaling report:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	fmt.Println(helper.FormatKV([]string{
+		fmt.Sprintf("From|%s", timeFormatter.Time(resp.From)),
+		fmt.Sprintf("To|%s", timeFormatter.Time(resp.To)),
+	}))
+	fmt.Println("")
+	fmt.Println(helper.FormatList(formatGroupReports(resp.Groups)))
+
+	os.Exit(sysexits.OK)
+}
+
+func formatGroupReports(groups []*api.GroupScalingReport) []string {
+	out := []string{outputHeader}
+
+	for _, g := range groups {
+		out = append(out, fmt.Sprintf("%s:%s|%v|%v|%v|%.1f%%|%v|%v|%s|%.1f%%",
+			g.JobID, g.Group, g.ScaleOut, g.ScaleIn, g.Failed, g.FailureRate, g.DirectionChanges, g.Flapping,
+			helper.FormatDuration(time.Duration(g.TimeAtMax*float64(time.Second))), g.TimeAtMaxPercent))
+	}
+	return out
+}
+
+func parseTime(raw string) (time.Time, error) {
+	if raw == "" {
+		return time.Time{}, nil
+	}
+	return time.Parse(time.RFC3339, raw)
+}
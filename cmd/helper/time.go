@@ -1,9 +1,111 @@
 package helper
 
-import "time"
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// The supported formats of timestamps within command output.
+const (
+	// TimeFormatUTC displays timestamps in UTC using the Go time format, and is the default.
+	TimeFormatUTC = "utc"
+
+	// TimeFormatRFC3339 displays timestamps in UTC using RFC3339.
+	TimeFormatRFC3339 = "rfc3339"
+
+	// TimeFormatLocal displays timestamps in the local timezone using RFC3339.
+	TimeFormatLocal = "local"
+
+	// TimeFormatRelative displays timestamps relative to the current time, such as "5m ago".
+	TimeFormatRelative = "relative"
+)
 
 // UnixNanoToHumanUTC is used to convert the internally used UnixNano timestamp to a human readable
 // output.
 func UnixNanoToHumanUTC(t int64) time.Time {
 	return time.Unix(0, t).UTC().Round(10 * time.Millisecond)
 }
+
+// TimeFormatter formats timestamps for command output, so that all commands display time using
+// the format selected by the operator.
+type TimeFormatter struct {
+	format string
+	loc    *time.Location
+	now    func() time.Time
+}
+
+// NewTimeFormatter returns a TimeFormatter for the named format. An empty format uses the UTC
+// format.
+func NewTimeFormatter(format string) (*TimeFormatter, error) {
+	switch format {
+	case "":
+		format = TimeFormatUTC
+	case TimeFormatUTC, TimeFormatRFC3339, TimeFormatLocal, TimeFormatRelative:
+	default:
+		return nil, errors.Errorf("unsupported time format %q, must be one of utc, rfc3339, local or relative", format)
+	}
+	return &TimeFormatter{format: format, loc: time.Local, now: time.Now}, nil
+}
+
+// UnixNano formats the internally used UnixNano timestamp.
+func (f *TimeFormatter) UnixNano(t int64) string {
+	return f.Time(time.Unix(0, t))
+}
+
+// Time formats the timestamp. Zero timestamps are displayed as "<none>".
+func (f *TimeFormatter) Time(t time.Time) string {
+	if t.IsZero() {
+		return "<none>"
+	}
+
+	switch f.format {
+	case TimeFormatRFC3339:
+		return t.UTC().Format(time.RFC3339)
+	case TimeFormatLocal:
+		return t.In(f.loc).Format(time.RFC3339)
+	case TimeFormatRelative:
+		d := f.now().Sub(t)
+		if d < 0 {
+			return "in " + FormatDuration(-d)
+		}
+		return FormatDuration(d) + " ago"
+	default:
+		return UnixNanoToHumanUTC(t.UnixNano()).String()
+	}
+}
+
+// FormatDuration formats the duration using its two most significant units, such as "2d3h" or
+// "5m30s", rounded to the second.
+func FormatDuration(d time.Duration) string {
+	d = d.Round(time.Second)
+	if d < 0 {
+		return "-" + FormatDuration(-d)
+	}
+
+	units := []struct {
+		suffix string
+		size   time.Duration
+	}{
+		{"d", 24 * time.Hour},
+		{"h", time.Hour},
+		{"m", time.Minute},
+		{"s", time.Second},
+	}
+
+	var out string
+
+	for i, u := range units {
+		if d < u.size && i < len(units)-1 {
+			continue
+		}
+		out = fmt.Sprintf("%d%s", d/u.size, u.suffix)
+
+		if rem := d % u.size; i < len(units)-1 && rem >= units[i+1].size {
+			out += fmt.Sprintf("%d%s", rem/units[i+1].size, units[i+1].suffix)
+		}
+		break
+	}
+	return out
+}
@@ -13,3 +13,54 @@ func Test_UnixNanoToHumanUTC(t *testing.T) {
 
 	assert.Equal(t, expectedOutput, actualOutput)
 }
+
+func TestTimeFormatter(t *testing.T) {
+	ts := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		format         string
+		input          time.Time
+		expectedOutput string
+	}{
+		{format: "", input: ts, expectedOutput: "2020-01-26 10:00:00 +0000 UTC"},
+		{format: TimeFormatUTC, input: ts, expectedOutput: "2020-01-26 10:00:00 +0000 UTC"},
+		{format: TimeFormatRFC3339, input: ts, expectedOutput: "2020-01-26T10:00:00Z"},
+		{format: TimeFormatLocal, input: ts, expectedOutput: "2020-01-26T11:00:00+01:00"},
+		{format: TimeFormatRelative, input: ts.Add(-5 * time.Minute), expectedOutput: "5m ago"},
+		{format: TimeFormatRelative, input: ts.Add(90 * time.Second), expectedOutput: "in 1m30s"},
+		{format: TimeFormatRFC3339, input: time.Time{}, expectedOutput: "<none>"},
+	}
+
+	for _, tc := range testCases {
+		f, err := NewTimeFormatter(tc.format)
+		assert.Nil(t, err, tc.format)
+
+		f.loc = time.FixedZone("CET", 3600)
+		f.now = func() time.Time { return ts }
+
+		assert.Equal(t, tc.expectedOutput, f.Time(tc.input), tc.format)
+	}
+
+	_, err := NewTimeFormatter("unix")
+	assert.NotNil(t, err)
+}
+
+func Test_FormatDuration(t *testing.T) {
+	testCases := []struct {
+		input          time.Duration
+		expectedOutput string
+	}{
+		{input: 0, expectedOutput: "0s"},
+		{input: 400 * time.Millisecond, expectedOutput: "0s"},
+		{input: 45 * time.Second, expectedOutput: "45s"},
+		{input: 5*time.Minute + 30*time.Second, expectedOutput: "5m30s"},
+		{input: 4 * time.Hour, expectedOutput: "4h"},
+		{input: 4*time.Hour + 10*time.Second, expectedOutput: "4h"},
+		{input: 51 * time.Hour, expectedOutput: "2d3h"},
+		{input: -90 * time.Second, expectedOutput: "-1m30s"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedOutput, FormatDuration(tc.input))
+	}
+}
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/jrasell/sherpa/cmd/events"
+	"github.com/jrasell/sherpa/cmd/pack"
 	"github.com/jrasell/sherpa/cmd/policy"
 	"github.com/jrasell/sherpa/cmd/scale"
 	"github.com/jrasell/sherpa/cmd/server"
@@ -54,5 +56,13 @@ func registerCommands(rootCmd *cobra.Command) error {
 		return err
 	}
 
+	if err := events.RegisterCommand(rootCmd); err != nil {
+		return err
+	}
+
+	if err := pack.RegisterCommand(rootCmd); err != nil {
+		return err
+	}
+
 	return policy.RegisterCommand(rootCmd)
 }
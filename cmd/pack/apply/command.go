@@ -0,0 +1,126 @@
+package apply
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jrasell/sherpa/pkg/api"
+	clientCfg "github.com/jrasell/sherpa/pkg/config/client"
+	packCfg "github.com/jrasell/sherpa/pkg/config/pack"
+	"github.com/jrasell/sherpa/pkg/policy/pack"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "apply",
+		Short: "Renders a policy pack and writes its policies",
+		Run: func(cmd *cobra.Command, args []string) {
+			runApply(cmd, args)
+		},
+	}
+	packCfg.RegisterApplyConfig(cmd)
+	rootCmd.AddCommand(cmd)
+
+	return nil
+}
+
+func runApply(_ *cobra.Command, args []string) {
+	switch {
+	case len(args) < 1:
+		fmt.Println("Not enough arguments, expected 1 got", len(args))
+		os.Exit(sysexits.Usage)
+	case len(args) > 1:
+		fmt.Println("Too many arguments, expected 1 got", len(args))
+		os.Exit(sysexits.Usage)
+	}
+
+	b, err := ioutil.ReadFile(strings.TrimSpace(args[0]))
+	if err != nil {
+		fmt.Println("Error reading policy pack file:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	applyConfig := packCfg.GetApplyConfig()
+
+	vars, err := parseVars(applyConfig.Vars)
+	if err != nil {
+		fmt.Println("Error parsing variables:", err)
+		os.Exit(sysexits.Usage)
+	}
+
+	p, err := pack.Parse(b)
+	if err != nil {
+		fmt.Println("Error parsing policy pack:", err)
+		os.Exit(sysexits.Usage)
+	}
+
+	// Render all the policies before writing any, so an invalid pack does not leave the server
+	// with a partially applied pack.
+	policies, err := p.Render(vars)
+	if err != nil {
+		fmt.Println("Error rendering policy pack:", err)
+		os.Exit(sysexits.Usage)
+	}
+
+	jobs := make([]string, 0, len(policies))
+	for job := range policies {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+
+	if applyConfig.DryRun {
+		os.Exit(printPolicies(jobs, policies))
+	}
+
+	clientConfig := clientCfg.GetConfig()
+	mergedConfig := api.DefaultConfig(&clientConfig)
+
+	client, err := api.NewClient(mergedConfig)
+	if err != nil {
+		fmt.Println("Error setting up Sherpa client:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	for _, job := range jobs {
+		if err := client.Policies().WriteJobPolicyRaw(job, policies[job]); err != nil {
+			fmt.Printf("Error writing job scaling policy %s: %v\n", job, err)
+			os.Exit(sysexits.Software)
+		}
+		fmt.Println("Successfully wrote job scaling policy", job)
+	}
+	os.Exit(sysexits.OK)
+}
+
+// printPolicies prints the indented rendered policy of each job.
+func printPolicies(jobs []string, policies map[string]json.RawMessage) int {
+	for _, job := range jobs {
+		var buf bytes.Buffer
+		if err := json.Indent(&buf, policies[job], "", "  "); err != nil {
+			fmt.Println("Error formatting job scaling policy:", err)
+			return sysexits.Software
+		}
+		fmt.Printf("Job: %s\n%s\n\n", job, buf.String())
+	}
+	fmt.Println("Dry-run: the above scaling policies would be written")
+	return sysexits.OK
+}
+
+func parseVars(input []string) (map[string]string, error) {
+	vars := make(map[string]string, len(input))
+
+	for _, v := range input {
+		split := strings.SplitN(v, "=", 2)
+		if len(split) != 2 || split[0] == "" {
+			return nil, fmt.Errorf("invalid variable %q, must be in the form key=value", v)
+		}
+		vars[split[0]] = split[1]
+	}
+	return vars, nil
+}
@@ -0,0 +1,36 @@
+package pack
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jrasell/sherpa/cmd/pack/apply"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "pack",
+		Short: "Interact with policy packs",
+		Run: func(cmd *cobra.Command, args []string) {
+			runPack(cmd, args)
+		},
+	}
+
+	rootCmd.AddCommand(cmd)
+
+	if err := registerCommands(cmd); err != nil {
+		fmt.Println("Error registering commands:", err)
+		os.Exit(sysexits.Software)
+	}
+	return nil
+}
+
+func runPack(cmd *cobra.Command, _ []string) {
+	_ = cmd.Usage()
+}
+
+func registerCommands(cmd *cobra.Command) error {
+	return apply.RegisterCommand(cmd)
+}
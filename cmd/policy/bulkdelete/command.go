@@ -0,0 +1,105 @@
+package bulkdelete
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jrasell/sherpa/cmd/helper"
+	"github.com/jrasell/sherpa/pkg/api"
+	clientCfg "github.com/jrasell/sherpa/pkg/config/client"
+	policyCfg "github.com/jrasell/sherpa/pkg/config/policy"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputHeader = "Job|Groups"
+)
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "bulk-delete",
+		Short: "Deletes all scaling policies matching a job prefix or labels",
+		Run: func(cmd *cobra.Command, args []string) {
+			runBulkDelete(cmd, args)
+		},
+	}
+	policyCfg.RegisterBulkDeleteConfig(cmd)
+	rootCmd.AddCommand(cmd)
+
+	return nil
+}
+
+func runBulkDelete(_ *cobra.Command, args []string) {
+	if len(args) > 0 {
+		fmt.Println("Too many arguments, expected 0 args got", len(args))
+		os.Exit(sysexits.Usage)
+	}
+
+	bulkConfig := policyCfg.GetBulkDeleteConfig()
+
+	labels, err := parseLabels(bulkConfig.Labels)
+	if err != nil {
+		fmt.Println("Error parsing labels:", err)
+		os.Exit(sysexits.Usage)
+	}
+
+	if bulkConfig.JobPrefix == "" && len(labels) == 0 {
+		fmt.Println("At least one of --job-prefix or --label is required")
+		os.Exit(sysexits.Usage)
+	}
+
+	clientConfig := clientCfg.GetConfig()
+	mergedConfig := api.DefaultConfig(&clientConfig)
+
+	client, err := api.NewClient(mergedConfig)
+	if err != nil {
+		fmt.Println("Error setting up Sherpa client:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	resp, err := client.Policies().BulkDelete(bulkConfig.JobPrefix, labels, bulkConfig.DryRun)
+	if err != nil {
+		fmt.Println("Error bulk deleting scaling policies:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	if len(resp.Deleted) == 0 {
+		fmt.Println("No scaling policies matched the selectors")
+		os.Exit(sysexits.OK)
+	}
+
+	out := []string{outputHeader}
+	out = append(out, produceSortedList(resp.Deleted)...)
+	fmt.Println(helper.FormatList(out))
+
+	if resp.DryRun {
+		fmt.Println("\nDry-run: the above scaling policies would be deleted")
+		os.Exit(sysexits.OK)
+	}
+	fmt.Println("\nSuccessfully deleted the above scaling policies")
+}
+
+func parseLabels(input []string) (map[string]string, error) {
+	labels := make(map[string]string, len(input))
+
+	for _, label := range input {
+		split := strings.SplitN(label, "=", 2)
+		if len(split) != 2 || split[0] == "" {
+			return nil, fmt.Errorf("invalid label %q, must be in the form key=value", label)
+		}
+		labels[split[0]] = split[1]
+	}
+	return labels, nil
+}
+
+func produceSortedList(deleted map[string][]string) []string {
+	out := make([]string, 0, len(deleted))
+	for job, groups := range deleted {
+		out = append(out, fmt.Sprintf("%s|%s", job, strings.Join(groups, ",")))
+	}
+	sort.Strings(out)
+	return out
+}
@@ -4,10 +4,13 @@ import (
 	"fmt"
 	"os"
 
+	"github.com/jrasell/sherpa/cmd/policy/bulkdelete"
 	"github.com/jrasell/sherpa/cmd/policy/delete"
+	"github.com/jrasell/sherpa/cmd/policy/diff"
 	initcmd "github.com/jrasell/sherpa/cmd/policy/init"
 	"github.com/jrasell/sherpa/cmd/policy/list"
 	"github.com/jrasell/sherpa/cmd/policy/read"
+	"github.com/jrasell/sherpa/cmd/policy/rollout"
 	"github.com/jrasell/sherpa/cmd/policy/write"
 	policyCfg "github.com/jrasell/sherpa/pkg/config/policy"
 	"github.com/sean-/sysexits"
@@ -46,6 +49,10 @@ func registerCommands(cmd *cobra.Command) error {
 		return err
 	}
 
+	if err := bulkdelete.RegisterCommand(cmd); err != nil {
+		return err
+	}
+
 	if err := write.RegisterCommand(cmd); err != nil {
 		return err
 	}
@@ -54,5 +61,13 @@ func registerCommands(cmd *cobra.Command) error {
 		return err
 	}
 
+	if err := diff.RegisterCommand(cmd); err != nil {
+		return err
+	}
+
+	if err := rollout.RegisterCommand(cmd); err != nil {
+		return err
+	}
+
 	return read.RegisterCommand(cmd)
 }
@@ -0,0 +1,114 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"github.com/jrasell/sherpa/pkg/api"
+	clientCfg "github.com/jrasell/sherpa/pkg/config/client"
+	policyCfg "github.com/jrasell/sherpa/pkg/config/policy"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+// exitDifferent is the exit code used when the local and server policies differ, matching the
+// convention of the diff utility.
+const exitDifferent = 1
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "diff",
+		Short: "Compares a local policy file to the server stored policy",
+		Run: func(cmd *cobra.Command, args []string) {
+			runDiff(cmd, args)
+		},
+	}
+	rootCmd.AddCommand(cmd)
+
+	return nil
+}
+
+func runDiff(_ *cobra.Command, args []string) {
+	switch {
+	case len(args) < 2:
+		fmt.Println("Not enough arguments, expected 2 args got", len(args))
+		os.Exit(sysexits.Usage)
+	case len(args) > 2:
+		fmt.Println("Too many arguments, expected 2 args got", len(args))
+		os.Exit(sysexits.Usage)
+	}
+
+	path := strings.TrimSpace(args[1])
+
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		fmt.Println("Error reading scaling policy file:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	clientConfig := clientCfg.GetConfig()
+	mergedConfig := api.DefaultConfig(&clientConfig)
+
+	client, err := api.NewClient(mergedConfig)
+	if err != nil {
+		fmt.Println("Error setting up Sherpa client:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	job := strings.TrimSpace(strings.ToLower(args[0]))
+
+	var serverPolicies, localPolicies map[string]json.RawMessage
+
+	// When a group is specified, the file holds the single group policy. Both policies are keyed
+	// by the group so the field paths match the whole job comparison.
+	if group := policyCfg.GetConfig().GroupName; group != "" {
+		resp, err := client.Policies().ReadJobGroupPolicyRaw(job, group)
+		if err != nil {
+			fmt.Println("Error reading scaling policy:", err)
+			os.Exit(sysexits.Software)
+		}
+		serverPolicies = map[string]json.RawMessage{group: resp}
+		localPolicies = map[string]json.RawMessage{group: b}
+	} else {
+		if serverPolicies, err = client.Policies().ReadJobPolicyRaw(job); err != nil {
+			fmt.Println("Error reading scaling policy:", err)
+			os.Exit(sysexits.Software)
+		}
+		if err := json.Unmarshal(b, &localPolicies); err != nil {
+			fmt.Println("Error parsing scaling policy file:", err)
+			os.Exit(sysexits.Software)
+		}
+	}
+
+	os.Exit(runPolicyDiff(job, path, serverPolicies, localPolicies))
+}
+
+func runPolicyDiff(job, path string, server, local map[string]json.RawMessage) int {
+	serverFields, err := flattenPolicies(server)
+	if err != nil {
+		fmt.Println("Error parsing server scaling policy:", err)
+		return sysexits.Software
+	}
+
+	localFields, err := flattenPolicies(local)
+	if err != nil {
+		fmt.Println("Error parsing scaling policy file:", err)
+		return sysexits.Software
+	}
+
+	lines := diffFields(serverFields, localFields)
+	if len(lines) == 0 {
+		fmt.Println("No differences found")
+		return sysexits.OK
+	}
+
+	fmt.Println("--- server:" + job)
+	fmt.Println("+++ " + path)
+	for _, line := range lines {
+		fmt.Println(line)
+	}
+	return exitDifferent
+}
@@ -0,0 +1,88 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+)
+
+// flattenPolicies returns the fields of each group scaling policy keyed by their dotted path, such
+// as cache.ExternalChecks.queue.Query. The server defaults are applied to each policy, as they are
+// when the policy is written, so that unset fields do not differ from the stored policy.
+func flattenPolicies(policies map[string]json.RawMessage) (map[string]string, error) {
+	out := make(map[string]string)
+
+	for group, raw := range policies {
+		var pol policy.GroupScalingPolicy
+		if err := json.Unmarshal(raw, &pol); err != nil {
+			return nil, fmt.Errorf("failed to parse %s group policy: %v", group, err)
+		}
+
+		b, err := json.Marshal(pol.MergeWithDefaults())
+		if err != nil {
+			return nil, err
+		}
+
+		var fields interface{}
+		if err := json.Unmarshal(b, &fields); err != nil {
+			return nil, err
+		}
+		flatten(group, fields, out)
+	}
+	return out, nil
+}
+
+// flatten adds the leaf values of v to out, keyed by their path from prefix. Null values and empty
+// objects are omitted, as they are equivalent to unset fields.
+func flatten(prefix string, v interface{}, out map[string]string) {
+	switch val := v.(type) {
+	case nil:
+		return
+	case map[string]interface{}:
+		for k := range val {
+			flatten(prefix+"."+k, val[k], out)
+		}
+	default:
+		b, _ := json.Marshal(val)
+		out[prefix] = string(b)
+	}
+}
+
+// diffFields returns the unified diff lines of the fields which differ between the server and the
+// local policy, ordered by field path. Removed server values are prefixed with - and local values
+// with +.
+func diffFields(server, local map[string]string) []string {
+	paths := make(map[string]struct{})
+	for k := range server {
+		paths[k] = struct{}{}
+	}
+	for k := range local {
+		paths[k] = struct{}{}
+	}
+
+	sorted := make([]string, 0, len(paths))
+	for k := range paths {
+		sorted = append(sorted, k)
+	}
+	sort.Strings(sorted)
+
+	var out []string
+
+	for _, path := range sorted {
+		s, inServer := server[path]
+		l, inLocal := local[path]
+
+		if inServer && inLocal && s == l {
+			continue
+		}
+		if inServer {
+			out = append(out, fmt.Sprintf("-%s = %s", path, s))
+		}
+		if inLocal {
+			out = append(out, fmt.Sprintf("+%s = %s", path, l))
+		}
+	}
+	return out
+}
@@ -0,0 +1,39 @@
+package diff
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_flattenPolicies(t *testing.T) {
+	fields, err := flattenPolicies(map[string]json.RawMessage{
+		"cache": json.RawMessage(`{"Enabled":true,"MaxCount":8,"ExternalChecks":{"queue":{"Query":"depth"}}}`),
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, "true", fields["cache.Enabled"])
+	assert.Equal(t, "8", fields["cache.MaxCount"])
+	assert.Equal(t, "2", fields["cache.MinCount"])
+	assert.Equal(t, `"depth"`, fields["cache.ExternalChecks.queue.Query"])
+
+	_, ok := fields["cache.ScaleOutCPUPercentageThreshold"]
+	assert.False(t, ok)
+
+	_, err = flattenPolicies(map[string]json.RawMessage{"cache": json.RawMessage(`[]`)})
+	assert.NotNil(t, err)
+}
+
+func Test_diffFields(t *testing.T) {
+	server := map[string]string{"cache.MaxCount": "8", "cache.MinCount": "2", "web.Enabled": "true"}
+	local := map[string]string{"cache.MaxCount": "10", "cache.MinCount": "2", "cache.Cooldown": "60"}
+
+	assert.Equal(t, []string{
+		"+cache.Cooldown = 60",
+		"-cache.MaxCount = 8",
+		"+cache.MaxCount = 10",
+		"-web.Enabled = true",
+	}, diffFields(server, local))
+
+	assert.Nil(t, diffFields(server, server))
+}
@@ -0,0 +1,109 @@
+package rollout
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/jrasell/sherpa/cmd/helper"
+	"github.com/jrasell/sherpa/pkg/api"
+	clientCfg "github.com/jrasell/sherpa/pkg/config/client"
+	policyCfg "github.com/jrasell/sherpa/pkg/config/policy"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+const (
+	outputHeader = "Job|Canary Groups|Pending Groups|Started"
+)
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "rollout",
+		Short: "Lists, promotes or aborts canary scaling policy rollouts",
+		Run: func(cmd *cobra.Command, args []string) {
+			runRollout(cmd, args)
+		},
+	}
+	policyCfg.RegisterRolloutConfig(cmd)
+	rootCmd.AddCommand(cmd)
+
+	return nil
+}
+
+func runRollout(_ *cobra.Command, args []string) {
+	if len(args) > 1 {
+		fmt.Println("Too many arguments, expected 0 or 1 args got", len(args))
+		os.Exit(sysexits.Usage)
+	}
+
+	rolloutConfig := policyCfg.GetRolloutConfig()
+
+	if len(args) == 0 && (rolloutConfig.Promote || rolloutConfig.Abort) {
+		fmt.Println("A job is required when using --promote or --abort")
+		os.Exit(sysexits.Usage)
+	}
+	if len(args) == 1 && rolloutConfig.Promote == rolloutConfig.Abort {
+		fmt.Println("Exactly one of --promote or --abort is required")
+		os.Exit(sysexits.Usage)
+	}
+
+	clientConfig := clientCfg.GetConfig()
+	mergedConfig := api.DefaultConfig(&clientConfig)
+
+	client, err := api.NewClient(mergedConfig)
+	if err != nil {
+		fmt.Println("Error setting up Sherpa client:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	if len(args) == 0 {
+		os.Exit(runList(client))
+	}
+
+	job := strings.TrimSpace(args[0])
+
+	if rolloutConfig.Promote {
+		if err := client.Policies().PromoteRollout(job); err != nil {
+			fmt.Println("Error promoting scaling policy rollout:", err)
+			os.Exit(sysexits.Software)
+		}
+		fmt.Println("Successfully promoted scaling policy rollout")
+		os.Exit(sysexits.OK)
+	}
+
+	if err := client.Policies().AbortRollout(job); err != nil {
+		fmt.Println("Error aborting scaling policy rollout:", err)
+		os.Exit(sysexits.Software)
+	}
+	fmt.Println("Successfully aborted scaling policy rollout")
+}
+
+func runList(c *api.Client) int {
+	rollouts, err := c.Policies().Rollouts()
+	if err != nil {
+		fmt.Println("Error listing scaling policy rollouts:", err)
+		return sysexits.Software
+	}
+
+	if len(rollouts) == 0 {
+		fmt.Println("No scaling policy rollouts in progress")
+		return sysexits.OK
+	}
+
+	out := []string{outputHeader}
+	out = append(out, produceSortedList(rollouts)...)
+	fmt.Println(helper.FormatList(out))
+	return sysexits.OK
+}
+
+func produceSortedList(rollouts map[string]*api.PolicyRollout) []string {
+	out := make([]string, 0, len(rollouts))
+	for job, r := range rollouts {
+		out = append(out, fmt.Sprintf("%s|%s|%s|%s", job, strings.Join(r.CanaryGroups, ","),
+			strings.Join(r.PendingGroups, ","), r.StartTime.Format("2006-01-02T15:04:05Z")))
+	}
+	sort.Strings(out)
+	return out
+}
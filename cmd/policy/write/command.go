@@ -22,6 +22,7 @@ func RegisterCommand(rootCmd *cobra.Command) error {
 			runWrite(cmd, args)
 		},
 	}
+	policyCfg.RegisterWriteConfig(cmd)
 	rootCmd.AddCommand(cmd)
 
 	return nil
@@ -72,6 +73,10 @@ func runWrite(_ *cobra.Command, args []string) {
 		fmt.Println("Error parsing scaling policy file:", err)
 		os.Exit(sysexits.Software)
 	}
+
+	if pct := policyCfg.GetWriteConfig().CanaryPercentage; pct > 0 {
+		os.Exit(runJobCanaryWrite(client, name, &policy, pct))
+	}
 	os.Exit(runJobWrite(client, name, &policy))
 }
 
@@ -85,6 +90,23 @@ func runJobWrite(c *api.Client, job string, policy *map[string]*api.JobGroupPoli
 	return sysexits.OK
 }
 
+func runJobCanaryWrite(c *api.Client, job string, policy *map[string]*api.JobGroupPolicy, pct int) int {
+	rollout, err := c.Policies().WriteJobPolicyCanary(job, policy, pct)
+	if err != nil {
+		fmt.Println("Error writing job scaling policy:", err)
+		return sysexits.Software
+	}
+
+	if rollout == nil {
+		fmt.Println("Successfully wrote job scaling policy to all changed groups")
+		return sysexits.OK
+	}
+
+	fmt.Printf("Successfully wrote job scaling policy to canary groups %s\n", strings.Join(rollout.CanaryGroups, ","))
+	fmt.Printf("Groups %s will use their previous policy until the rollout is promoted\n", strings.Join(rollout.PendingGroups, ","))
+	return sysexits.OK
+}
+
 func runJobGroupWrite(c *api.Client, job, group string, policy *api.JobGroupPolicy) int {
 	if err := c.Policies().WriteJobGroupPolicy(job, group, policy); err != nil {
 		fmt.Println("Error writing job group scaling policy:", err)
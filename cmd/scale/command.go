@@ -7,6 +7,7 @@ import (
 	"github.com/jrasell/sherpa/cmd/scale/in"
 	"github.com/jrasell/sherpa/cmd/scale/out"
 	"github.com/jrasell/sherpa/cmd/scale/status"
+	"github.com/jrasell/sherpa/cmd/scale/to"
 	scaleCfg "github.com/jrasell/sherpa/pkg/config/scale"
 	"github.com/sean-/sysexits"
 	"github.com/spf13/cobra"
@@ -44,5 +45,9 @@ func registerCommands(cmd *cobra.Command) error {
 		return err
 	}
 
+	if err := to.RegisterCommand(cmd); err != nil {
+		return err
+	}
+
 	return out.RegisterCommand(cmd)
 }
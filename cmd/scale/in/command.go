@@ -51,11 +51,11 @@ func runIn(_ *cobra.Command, args []string) {
 		os.Exit(sysexits.Usage)
 	}
 
-	os.Exit(runJobGroupScaleIn(client, args[0], scaleConfig.GroupName, scaleConfig.Count, scaleConfig.Meta))
+	os.Exit(runJobGroupScaleIn(client, args[0], scaleConfig.GroupName, scaleConfig.Count, scaleConfig.Cooldown, scaleConfig.Meta))
 }
 
-func runJobGroupScaleIn(c *api.Client, job, group string, count int, meta map[string]string) int {
-	resp, err := c.Scale().JobGroupIn(job, group, count, meta)
+func runJobGroupScaleIn(c *api.Client, job, group string, count int, cooldown string, meta map[string]string) int {
+	resp, err := c.Scale().JobGroupInWithOptions(job, group, count, meta, &api.ScaleOptions{Cooldown: cooldown})
 	if err != nil {
 		fmt.Println("Error scaling in job group:", err)
 		return sysexits.Software
@@ -17,7 +17,7 @@ import (
 
 const (
 	listOutputHeader = "ID|Job:Group|Status|Time"
-	infoOutputHeader = "Job:Group|ChangeCount|Direction|Meta"
+	infoOutputHeader = "Job:Group|ChangeCount|Direction|Reason|Meta"
 )
 
 func RegisterCommand(rootCmd *cobra.Command) error {
@@ -44,6 +44,12 @@ func runStatus(_ *cobra.Command, args []string) {
 	mergedConfig := api.DefaultConfig(&clientConfig)
 	latestConfig := scale.GetScaleStatusConfig()
 
+	timeFormatter, err := helper.NewTimeFormatter(clientConfig.TimeFormat)
+	if err != nil {
+		fmt.Println("Error parsing time format:", err)
+		os.Exit(sysexits.Usage)
+	}
+
 	client, err := api.NewClient(mergedConfig)
 	if err != nil {
 		fmt.Println("Error setting up Sherpa client:", err)
@@ -52,13 +58,13 @@ func runStatus(_ *cobra.Command, args []string) {
 
 	switch len(args) {
 	case 0:
-		os.Exit(runList(client, latestConfig.Latest))
+		os.Exit(runList(client, timeFormatter, latestConfig.Latest))
 	case 1:
-		os.Exit(runInfo(client, args[0]))
+		os.Exit(runInfo(client, timeFormatter, args[0]))
 	}
 }
 
-func runList(c *api.Client, latest bool) int {
+func runList(c *api.Client, tf *helper.TimeFormatter, latest bool) int {
 	resp, err := c.Scale().List(latest)
 	if err != nil {
 		fmt.Println("Error getting scaling list:", err)
@@ -71,8 +77,8 @@ func runList(c *api.Client, latest bool) int {
 
 	for i := range orderedIDs {
 		for jg, event := range resp[orderedIDs[i]] {
-			out = append(out, fmt.Sprintf("%v|%s|%s|%v",
-				orderedIDs[i], jg, event.Status, helper.UnixNanoToHumanUTC(event.Time)))
+			out = append(out, fmt.Sprintf("%v|%s|%s|%s",
+				orderedIDs[i], jg, event.Status, tf.UnixNano(event.Time)))
 		}
 	}
 
@@ -102,7 +108,7 @@ func orderStatusIDs(input map[uuid.UUID]map[string]*api.ScalingEvent) []uuid.UUI
 	return resp
 }
 
-func runInfo(c *api.Client, id string) int {
+func runInfo(c *api.Client, tf *helper.TimeFormatter, id string) int {
 	resp, err := c.Scale().Info(id)
 	if err != nil {
 		fmt.Println("Error getting scaling info:", err)
@@ -113,16 +119,23 @@ func runInfo(c *api.Client, id string) int {
 
 	events := []string{infoOutputHeader}
 	for jobGroup, event := range resp {
-		events = append(events, fmt.Sprintf("%s|%v|%v|%s",
-			jobGroup, event.Details.Count, event.Details.Direction, strings.Join(metaToStrings(event.Meta), ",")))
+		events = append(events, fmt.Sprintf("%s|%v|%v|%s|%s",
+			jobGroup, event.Details.Count, event.Details.Direction, event.Reason, strings.Join(metaToStrings(event.Meta), ",")))
 
 		if len(header) == 0 {
 			header = []string{
 				fmt.Sprintf("ID|%s", id),
 				fmt.Sprintf("EvalID|%v", event.EvalID),
+				fmt.Sprintf("DeploymentID|%v", event.DeploymentID),
 				fmt.Sprintf("Status|%s", event.Status),
 				fmt.Sprintf("Source|%v", event.Source),
-				fmt.Sprintf("Time|%v", helper.UnixNanoToHumanUTC(event.Time)),
+				fmt.Sprintf("Time|%s", tf.UnixNano(event.Time)),
+			}
+			if event.RunbookURL != "" {
+				header = append(header, fmt.Sprintf("RunbookURL|%s", event.RunbookURL))
+			}
+			if event.Notes != "" {
+				header = append(header, fmt.Sprintf("Notes|%s", event.Notes))
 			}
 		}
 	}
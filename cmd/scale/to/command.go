@@ -0,0 +1,80 @@
+package to
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jrasell/sherpa/cmd/helper"
+	"github.com/jrasell/sherpa/pkg/api"
+	clientCfg "github.com/jrasell/sherpa/pkg/config/client"
+	scaleCfg "github.com/jrasell/sherpa/pkg/config/scale"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "to",
+		Short: "Scale a Nomad job group to an exact count",
+		Run: func(cmd *cobra.Command, args []string) {
+			runTo(cmd, args)
+		},
+	}
+	scaleCfg.RegisterScaleToConfig(cmd)
+	rootCmd.AddCommand(cmd)
+
+	return nil
+}
+
+func runTo(cmd *cobra.Command, args []string) {
+	switch {
+	case len(args) < 1:
+		fmt.Println("Not enough arguments, expected 1 arg got", len(args))
+		os.Exit(sysexits.Usage)
+	case len(args) > 1:
+		fmt.Println("Too many arguments, expected 1 arg got", len(args))
+		os.Exit(sysexits.Usage)
+	}
+
+	scaleConfig := scaleCfg.GetScaleConfig()
+	toConfig := scaleCfg.GetScaleToConfig()
+
+	if scaleConfig.GroupName == "" {
+		fmt.Println("Please specify a job group to scale")
+		os.Exit(sysexits.Usage)
+	}
+
+	// A count of zero is a valid target, so the flag must be explicitly set rather than relying
+	// on its value.
+	if !cmd.Flags().Changed("count") || scaleConfig.Count < 0 {
+		fmt.Println("Please specify a non-negative count to scale the job group to")
+		os.Exit(sysexits.Usage)
+	}
+
+	clientConfig := clientCfg.GetConfig()
+	mergedConfig := api.DefaultConfig(&clientConfig)
+
+	client, err := api.NewClient(mergedConfig)
+	if err != nil {
+		fmt.Println("Error setting up Sherpa client:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	os.Exit(runJobGroupScaleTo(client, args[0], scaleConfig.GroupName, scaleConfig.Count, toConfig.Force, scaleConfig.Cooldown, scaleConfig.Meta))
+}
+
+func runJobGroupScaleTo(c *api.Client, job, group string, count int, force bool, cooldown string, meta map[string]string) int {
+	resp, err := c.Scale().JobGroupCountWithOptions(job, group, count, force, meta, &api.ScaleOptions{Cooldown: cooldown})
+	if err != nil {
+		fmt.Println("Error scaling job group to count:", err)
+		return sysexits.Software
+	}
+
+	out := []string{
+		fmt.Sprintf("ID|%s", resp.ID),
+		fmt.Sprintf("EvalID|%v", resp.EvaluationID),
+	}
+
+	fmt.Println(helper.FormatKV(out))
+	return sysexits.OK
+}
@@ -26,8 +26,14 @@ func RegisterCommand(rootCmd *cobra.Command) error {
 	serverCfg.RegisterTLSConfig(cmd)
 	serverCfg.RegisterTelemetryConfig(cmd)
 	serverCfg.RegisterClusterConfig(cmd)
+	serverCfg.RegisterClusterScalingConfig(cmd)
 	serverCfg.RegisterMetricProviderConfig(cmd)
+	serverCfg.RegisterNotifyConfig(cmd)
+	serverCfg.RegisterNomadConfig(cmd)
 	serverCfg.RegisterDebugConfig(cmd)
+	serverCfg.RegisterChaosConfig(cmd)
+	serverCfg.RegisterJobFilterConfig(cmd)
+	serverCfg.RegisterSecretsConfig(cmd)
 	logCfg.RegisterConfig(cmd)
 	rootCmd.AddCommand(cmd)
 
@@ -39,12 +45,22 @@ func runServer(_ *cobra.Command, _ []string) {
 	tlsConfig := serverCfg.GetTLSConfig()
 	telemetryConfig := serverCfg.GetTelemetryConfig()
 	clusterConfig := serverCfg.GetClusterConfig()
+	clusterScalingConfig := serverCfg.GetClusterScalingConfig()
 	metricProviderConfig := serverCfg.GetMetricProviderConfig()
+	notifyConfig := serverCfg.GetNotifyConfig()
+	nomadConfig := serverCfg.GetNomadConfig()
+	chaosConfig := serverCfg.GetChaosConfig()
+	jobFilterConfig := serverCfg.GetJobFilterConfig()
+	secretsConfig := serverCfg.GetSecretsConfig()
 
 	if err := verifyServerConfig(serverConfig); err != nil {
 		fmt.Println(err)
 		os.Exit(sysexits.Usage)
 	}
+	if err := clusterScalingConfig.Validate(); err != nil {
+		fmt.Println(err)
+		os.Exit(sysexits.Usage)
+	}
 
 	// Setup the server logging.
 	logConfig := logCfg.GetConfig()
@@ -55,8 +71,14 @@ func runServer(_ *cobra.Command, _ []string) {
 
 	cfg := &server.Config{
 		Debug:          serverCfg.GetDebugEnabled(),
+		Chaos:          &chaosConfig,
 		Cluster:        &clusterConfig,
+		ClusterScaling: &clusterScalingConfig,
+		JobFilter:      &jobFilterConfig,
 		MetricProvider: metricProviderConfig,
+		Nomad:          &nomadConfig,
+		Notify:         &notifyConfig,
+		Secrets:        &secretsConfig,
 		Server:         &serverConfig,
 		TLS:            &tlsConfig,
 		Telemetry:      &telemetryConfig,
@@ -69,9 +91,38 @@ func runServer(_ *cobra.Command, _ []string) {
 	}
 }
 
+// storageBackends returns the number of persistent storage backends enabled.
+func storageBackends(cfg serverCfg.Config) int {
+	var n int
+	for _, enabled := range []bool{cfg.ConsulStorageBackend, len(cfg.EtcdStorageBackendEndpoints) > 0, cfg.PostgresStorageBackendURL != ""} {
+		if enabled {
+			n++
+		}
+	}
+	return n
+}
+
 func verifyServerConfig(cfg serverCfg.Config) error {
 	if cfg.NomadMetaPolicyEngine && cfg.APIPolicyEngine {
 		return errors.New("Please only enable one policy engine")
 	}
-	return nil
+	if cfg.PolicyEngineFileDir != "" && (cfg.NomadMetaPolicyEngine || cfg.APIPolicyEngine) {
+		return errors.New("Please only enable one policy engine")
+	}
+	if cfg.PolicyEngineFileDir != "" && (cfg.ConsulStorageBackend || len(cfg.EtcdStorageBackendEndpoints) > 0 || cfg.PostgresStorageBackendURL != "") {
+		return errors.New("Please do not enable a policy storage backend with the file policy engine")
+	}
+	if storageBackends(cfg) > 1 {
+		return errors.New("Please only enable one of the Consul, etcd or PostgreSQL storage backends")
+	}
+	if cfg.NomadMetaPolicyEngine && cfg.NomadMetaPolicyEngineKeyPrefix == "" {
+		return errors.New("Please specify a non-empty Nomad meta key prefix")
+	}
+	if cfg.InternalAutoScalerMinStatsCoverage <= 0 || cfg.InternalAutoScalerMinStatsCoverage > 100 {
+		return errors.New("Please specify a minimum stats coverage greater than 0 and at most 100")
+	}
+	if err := cfg.InternalAutoScalerBoundsEnforcement.Validate(); err != nil {
+		return err
+	}
+	return cfg.ScaleInPromotionGuard.Validate()
 }
@@ -7,7 +7,10 @@ import (
 	"github.com/jrasell/sherpa/cmd/system/health"
 	"github.com/jrasell/sherpa/cmd/system/info"
 	"github.com/jrasell/sherpa/cmd/system/leader"
+	"github.com/jrasell/sherpa/cmd/system/loglevel"
+	"github.com/jrasell/sherpa/cmd/system/members"
 	"github.com/jrasell/sherpa/cmd/system/metrics"
+	"github.com/jrasell/sherpa/cmd/system/providers"
 	"github.com/sean-/sysexits"
 	"github.com/spf13/cobra"
 )
@@ -43,9 +46,21 @@ func registerCommands(rootCmd *cobra.Command) error {
 		return err
 	}
 
+	if err := loglevel.RegisterCommand(rootCmd); err != nil {
+		return err
+	}
+
+	if err := members.RegisterCommand(rootCmd); err != nil {
+		return err
+	}
+
 	if err := metrics.RegisterCommand(rootCmd); err != nil {
 		return err
 	}
 
+	if err := providers.RegisterCommand(rootCmd); err != nil {
+		return err
+	}
+
 	return health.RegisterCommand(rootCmd)
 }
@@ -3,6 +3,8 @@ package info
 import (
 	"fmt"
 	"os"
+	"sort"
+	"strings"
 
 	"github.com/jrasell/sherpa/cmd/helper"
 	"github.com/jrasell/sherpa/pkg/api"
@@ -46,6 +48,39 @@ func runInfo(_ *cobra.Command, _ []string) {
 	out = append(out, fmt.Sprintf("%s|%s", "Storage Backend", info.StorageBackend))
 	out = append(out, fmt.Sprintf("%s|%v", "Internal AutoScaling Engine", info.InternalAutoScalingEngine))
 	out = append(out, fmt.Sprintf("%s|%v", "Strict Policy Checking", info.StrictPolicyChecking))
+	out = append(out, fmt.Sprintf("%s|%s", "Metric Providers", formatNames(info.MetricProviders)))
+	out = append(out, fmt.Sprintf("%s|%s", "Notifiers", formatNames(info.Notifiers)))
+	out = append(out, fmt.Sprintf("%s|%s", "Version", info.Build.Version))
+	out = append(out, fmt.Sprintf("%s|%s", "Git Commit", info.Build.GitCommit))
+	out = append(out, fmt.Sprintf("%s|%s", "Go Version", info.Build.GoVersion))
 
 	fmt.Println(helper.FormatList(out))
+
+	if len(info.Features) == 0 {
+		return
+	}
+
+	features := []string{"Feature|Enabled"}
+	for _, name := range sortedKeys(info.Features) {
+		features = append(features, fmt.Sprintf("%s|%v", name, info.Features[name]))
+	}
+	fmt.Println("\nExperimental Features")
+	fmt.Println(helper.FormatList(features))
+}
+
+// formatNames joins the names for display, using a placeholder when there are none.
+func formatNames(names []string) string {
+	if len(names) == 0 {
+		return "<none>"
+	}
+	return strings.Join(names, ", ")
+}
+
+func sortedKeys(m map[string]bool) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
 }
@@ -0,0 +1,92 @@
+package loglevel
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/jrasell/sherpa/cmd/helper"
+	"github.com/jrasell/sherpa/pkg/api"
+	clientCfg "github.com/jrasell/sherpa/pkg/config/client"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+// defaultLevel is the level argument which removes component level overrides.
+const defaultLevel = "default"
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "loglevel <level> [<component>...]",
+		Short: "Change the log level of a Sherpa server, or of individual server components",
+		Run: func(cmd *cobra.Command, args []string) {
+			runLogLevel(cmd, args)
+		},
+	}
+	rootCmd.AddCommand(cmd)
+
+	return nil
+}
+
+func runLogLevel(_ *cobra.Command, args []string) {
+	if len(args) < 1 {
+		fmt.Println("Not enough arguments, expected at least 1 arg got", len(args))
+		os.Exit(sysexits.Usage)
+	}
+
+	req, err := buildRequest(args[0], args[1:])
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(sysexits.Usage)
+	}
+
+	clientConfig := clientCfg.GetConfig()
+	mergedConfig := api.DefaultConfig(&clientConfig)
+
+	client, err := api.NewClient(mergedConfig)
+	if err != nil {
+		fmt.Println("Error setting up Sherpa client:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	levels, err := client.System().SetLogLevel(req)
+	if err != nil {
+		fmt.Println("Error calling server log level:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	out := []string{fmt.Sprintf("Server|%s", levels.Level)}
+
+	components := make([]string, 0, len(levels.Components))
+	for component := range levels.Components {
+		components = append(components, component)
+	}
+	sort.Strings(components)
+
+	for _, component := range components {
+		out = append(out, fmt.Sprintf("%s|%s", component, levels.Components[component]))
+	}
+
+	fmt.Println(helper.FormatKV(out))
+}
+
+// buildRequest sets the level of the named components, or the server if no components are named.
+// The default level removes the component overrides.
+func buildRequest(level string, components []string) (*api.LogLevels, error) {
+	if len(components) == 0 {
+		if level == defaultLevel {
+			return nil, fmt.Errorf("the %s level can only be used with components", defaultLevel)
+		}
+		return &api.LogLevels{Level: level}, nil
+	}
+
+	if level == defaultLevel {
+		level = ""
+	}
+
+	req := &api.LogLevels{Components: make(map[string]string)}
+	for _, component := range components {
+		req.Components[component] = level
+	}
+	return req, nil
+}
@@ -0,0 +1,49 @@
+package members
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jrasell/sherpa/cmd/helper"
+	"github.com/jrasell/sherpa/pkg/api"
+	clientCfg "github.com/jrasell/sherpa/pkg/config/client"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "members",
+		Short: "List the cluster members discovered via gossip",
+		Run: func(cmd *cobra.Command, args []string) {
+			runMembers(cmd, args)
+		},
+	}
+	rootCmd.AddCommand(cmd)
+
+	return nil
+}
+
+func runMembers(_ *cobra.Command, _ []string) {
+	clientConfig := clientCfg.GetConfig()
+	mergedConfig := api.DefaultConfig(&clientConfig)
+
+	client, err := api.NewClient(mergedConfig)
+	if err != nil {
+		fmt.Println("Error setting up Sherpa client:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	resp, err := client.System().Members()
+	if err != nil {
+		fmt.Println("Error calling server members:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	out := []string{"ID|Address|Gossip Address|Status|Leader"}
+	for _, m := range resp.Members {
+		out = append(out, fmt.Sprintf("%s|%s|%s|%s|%v", m.ID, m.Addr, m.GossipAddr, m.Status, m.Leader))
+	}
+
+	fmt.Println(helper.FormatList(out))
+}
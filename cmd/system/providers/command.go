@@ -0,0 +1,50 @@
+package providers
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/jrasell/sherpa/cmd/helper"
+	"github.com/jrasell/sherpa/pkg/api"
+	clientCfg "github.com/jrasell/sherpa/pkg/config/client"
+	"github.com/sean-/sysexits"
+	"github.com/spf13/cobra"
+)
+
+func RegisterCommand(rootCmd *cobra.Command) error {
+	cmd := &cobra.Command{
+		Use:   "providers",
+		Short: "Retrieve the status of the autoscaler metric providers",
+		Run: func(cmd *cobra.Command, args []string) {
+			runProviders(cmd, args)
+		},
+	}
+	rootCmd.AddCommand(cmd)
+
+	return nil
+}
+
+func runProviders(_ *cobra.Command, _ []string) {
+	clientConfig := clientCfg.GetConfig()
+	mergedConfig := api.DefaultConfig(&clientConfig)
+
+	client, err := api.NewClient(mergedConfig)
+	if err != nil {
+		fmt.Println("Error setting up Sherpa client:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	status, err := client.System().ProviderStatus()
+	if err != nil {
+		fmt.Println("Error calling server provider status:", err)
+		os.Exit(sysexits.Software)
+	}
+
+	out := []string{"Provider|State|Reachable|Error Rate|Queries|Last Error"}
+	for _, s := range status {
+		out = append(out, fmt.Sprintf("%s|%s|%v|%.1f%%|%v|%s",
+			s.Name, s.State, s.Reachable, s.ErrorRate, s.Queries, s.LastError))
+	}
+
+	fmt.Println(helper.FormatList(out))
+}
@@ -1,34 +1,39 @@
 module github.com/jrasell/sherpa
 
-go 1.12
+go 1.13
 
 require (
 	github.com/BurntSushi/toml v0.3.1 // indirect
 	github.com/armon/go-metrics v0.0.0-20190430140413-ec5e00d3c878
+	github.com/fsnotify/fsnotify v1.4.7
 	github.com/gofrs/uuid v3.2.0+incompatible
 	github.com/gorilla/mux v1.7.1
 	github.com/hashicorp/consul/api v1.1.0
 	github.com/hashicorp/go-cleanhttp v0.5.1
 	github.com/hashicorp/go-immutable-radix v1.1.0 // indirect
-	github.com/hashicorp/go-rootcerts v1.0.0
+	github.com/hashicorp/go-rootcerts v1.0.2
 	github.com/hashicorp/golang-lru v0.5.3 // indirect
-	github.com/hashicorp/nomad/api v0.0.0-20190508234936-7ba2378a159e
+	github.com/hashicorp/hcl v1.0.0
+	github.com/hashicorp/memberlist v0.1.3
+	github.com/hashicorp/nomad/api v0.0.0-20210308180628-d03afe26b0ac
 	github.com/inconshreveable/mousetrap v1.0.0 // indirect
 	github.com/liamg/tml v0.2.0
+	github.com/lib/pq v1.3.0
 	github.com/mattn/go-isatty v0.0.7
 	github.com/oklog/run v1.0.0
 	github.com/panjf2000/ants/v2 v2.1.1
 	github.com/pkg/errors v0.8.1
 	github.com/prometheus/client_golang v0.9.2
+	github.com/prometheus/client_model v0.0.0-20180712105110-5c3871d89910
+	github.com/prometheus/common v0.0.0-20181126121408-4724e9255275
 	github.com/rs/zerolog v1.14.3
 	github.com/ryanuber/columnize v2.1.0+incompatible
 	github.com/sean-/sysexits v0.0.0-20171026162210-598690305aaa
 	github.com/spf13/cobra v0.0.3
 	github.com/spf13/viper v1.3.2
-	github.com/stretchr/testify v1.3.0
+	github.com/stretchr/testify v1.5.1
 	golang.org/x/crypto v0.0.0-20190513172903-22d7a77e9e5f // indirect
 	golang.org/x/net v0.0.0-20190514140710-3ec191127204 // indirect
 	golang.org/x/sys v0.0.0-20190514135907-3a4b5fb9f71f // indirect
 	golang.org/x/text v0.3.2 // indirect
-	gopkg.in/check.v1 v1.0.0-20180628173108-788fd7840127 // indirect
 )
@@ -177,14 +177,23 @@ func (c *Client) post(endpoint string, in, out interface{}, q *QueryOptions) err
 	return nil
 }
 
-func (c *Client) delete(endpoint string, out interface{}) error {
+// delete performs a DELETE request against the endpoint. Endpoints which return a response body
+// are expected to respond with a 200, and all others with a 204.
+func (c *Client) delete(endpoint string, out interface{}, q *QueryOptions) error {
 	r, err := c.newRequest(http.MethodDelete, endpoint)
 	if err != nil {
 		return err
 	}
 
+	r.setQueryOptions(q)
+
+	expected := http.StatusNoContent
+	if out != nil {
+		expected = http.StatusOK
+	}
+
 	resp, err := c.doRequest(r)
-	resp, err = requireOK(resp, err, http.StatusNoContent)
+	resp, err = requireOK(resp, err, expected)
 	if err != nil {
 		return err
 	}
@@ -1,7 +1,12 @@
 package api
 
 import (
+	"encoding/json"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type Policies struct {
@@ -25,6 +30,7 @@ type JobGroupPolicy struct {
 	ScaleInCPUPercentageThreshold     *int
 	ScaleInMemoryPercentageThreshold  *int
 	ExternalChecks                    map[string]*ExternalCheck
+	Labels                            map[string]string
 }
 
 // ExternalCheck represents an individual external check within a group scaling policy.
@@ -67,10 +73,36 @@ func (p *Policies) ReadJobGroupPolicy(job, group string) (*JobGroupPolicy, error
 	return &resp, nil
 }
 
+// ReadJobPolicyRaw returns the JSON of each group scaling policy of the job as stored by the
+// server, including fields which are not represented by JobGroupPolicy.
+func (p *Policies) ReadJobPolicyRaw(job string) (map[string]json.RawMessage, error) {
+	var resp map[string]json.RawMessage
+	if err := p.client.get("/v1/policy/"+job, &resp, nil); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// ReadJobGroupPolicyRaw returns the JSON of the job group scaling policy as stored by the server,
+// including fields which are not represented by JobGroupPolicy.
+func (p *Policies) ReadJobGroupPolicyRaw(job, group string) (json.RawMessage, error) {
+	var resp json.RawMessage
+	if err := p.client.get(fmt.Sprintf("/v1/policy/%s/%s", job, group), &resp, nil); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
 func (p *Policies) WriteJobPolicy(job string, policy *map[string]*JobGroupPolicy) error {
 	return p.client.post("/v1/policy/"+job, policy, nil, nil)
 }
 
+// WriteJobPolicyRaw writes the job scaling policy JSON document, allowing policies to include
+// fields which are not represented by JobGroupPolicy.
+func (p *Policies) WriteJobPolicyRaw(job string, policy json.RawMessage) error {
+	return p.client.post("/v1/policy/"+job, policy, nil, nil)
+}
+
 func (p *Policies) WriteJobGroupPolicy(job, group string, policy *JobGroupPolicy) error {
 	path := fmt.Sprintf("/v1/policy/%s/%s", job, group)
 
@@ -78,10 +110,89 @@ func (p *Policies) WriteJobGroupPolicy(job, group string, policy *JobGroupPolicy
 }
 
 func (p *Policies) DeleteJobPolicy(job string) error {
-	return p.client.delete("/v1/policy/"+job, nil)
+	return p.client.delete("/v1/policy/"+job, nil, nil)
 }
 
 func (p *Policies) DeleteJobGroupPolicy(job, group string) error {
 	path := fmt.Sprintf("/v1/policy/%s/%s", job, group)
-	return p.client.delete(path, nil)
+	return p.client.delete(path, nil, nil)
+}
+
+// PolicyBulkDeleteResponse details the job groups whose scaling policies were deleted, or would
+// be deleted if the request was a dry-run.
+type PolicyBulkDeleteResponse struct {
+	DryRun  bool
+	Deleted map[string][]string
+}
+
+// BulkDelete deletes all scaling policies of jobs with the job prefix and groups with all the
+// labels. When dryRun is true, the matching policies are returned without being deleted.
+func (p *Policies) BulkDelete(jobPrefix string, labels map[string]string, dryRun bool) (*PolicyBulkDeleteResponse, error) {
+	q := &QueryOptions{Params: map[string]string{}}
+
+	if jobPrefix != "" {
+		q.Params["job_prefix"] = jobPrefix
+	}
+	if len(labels) > 0 {
+		pairs := make([]string, 0, len(labels))
+		for k, v := range labels {
+			pairs = append(pairs, k+"="+v)
+		}
+		sort.Strings(pairs)
+		q.Params["label"] = strings.Join(pairs, ",")
+	}
+	if dryRun {
+		q.Params["dry_run"] = "true"
+	}
+
+	var resp PolicyBulkDeleteResponse
+
+	if err := p.client.delete("/v1/policies", &resp, q); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// PolicyRollout is a change to a job scaling policy which has only been applied to the canary
+// groups. The pending groups use their previous policy until the rollout is promoted.
+type PolicyRollout struct {
+	Percentage    int
+	CanaryGroups  []string
+	PendingGroups []string
+	Policy        map[string]*JobGroupPolicy
+	Previous      map[string]*JobGroupPolicy
+	StartTime     time.Time
+}
+
+// WriteJobPolicyCanary writes the job scaling policy to the percentage of changed groups, returning
+// the started rollout. Nil is returned if the policy was applied to all groups.
+func (p *Policies) WriteJobPolicyCanary(job string, policy *map[string]*JobGroupPolicy, percentage int) (*PolicyRollout, error) {
+	q := &QueryOptions{Params: map[string]string{"canary_percentage": strconv.Itoa(percentage)}}
+
+	var resp *PolicyRollout
+
+	if err := p.client.post("/v1/policy/"+job, policy, &resp, q); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Rollouts lists the in-progress scaling policy rollouts, keyed by the job.
+func (p *Policies) Rollouts() (map[string]*PolicyRollout, error) {
+	var resp map[string]*PolicyRollout
+
+	if err := p.client.get("/v1/policies/rollouts", &resp, nil); err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// PromoteRollout applies the new scaling policy of the job rollout to all of its groups.
+func (p *Policies) PromoteRollout(job string) error {
+	return p.client.post("/v1/policies/rollouts/"+job+"/promote", nil, nil, nil)
+}
+
+// AbortRollout aborts the job rollout, restoring the previous policy of the canary groups.
+func (p *Policies) AbortRollout(job string) error {
+	return p.client.delete("/v1/policies/rollouts/"+job, nil, nil)
 }
@@ -3,6 +3,7 @@ package api
 import (
 	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/gofrs/uuid"
 )
@@ -25,34 +26,82 @@ type ScaleResp struct {
 }
 
 type ScalingEvent struct {
-	ID      string
-	EvalID  string
-	Source  string
-	Time    int64
-	Status  string
-	Details EventDetails
-	Meta    map[string]string
+	ID           string
+	EvalID       string
+	DeploymentID string
+	Source       string
+	Time         int64
+	Status       string
+	Details      EventDetails
+	Reason       string
+	Meta         map[string]string
+
+	RunbookURL string
+	Notes      string
+
+	Snapshot *ResourceSnapshot
+}
+
+// ResourceSnapshot is the data the internal autoscaler acted upon when deciding to scale a job
+// group.
+type ResourceSnapshot struct {
+	Nomad    *NomadResourceSnapshot
+	External map[string]float64
+}
+
+// NomadResourceSnapshot is the aggregated resource utilisation percentages of the allocations of
+// a job group.
+type NomadResourceSnapshot struct {
+	CPU           float64
+	Memory        float64
+	Disk          float64
+	GPU           float64
+	StatsCoverage float64
 }
 
 type EventDetails struct {
-	Count     int
-	Direction string
+	Count        int
+	Direction    string
+	DesiredCount int
 }
 
 func (c *Client) Scale() *Scale {
 	return &Scale{client: c}
 }
 
+// ScaleOptions are the optional params of a manual scaling request.
+type ScaleOptions struct {
+
+	// Cooldown overrides an active job group scaling cooldown, and must be empty or one of bypass
+	// or reset.
+	Cooldown string
+}
+
+// params adds the options to the request query params.
+func (o *ScaleOptions) params(q *QueryOptions) {
+	if o == nil {
+		return
+	}
+	if o.Cooldown != "" {
+		q.Params["cooldown"] = o.Cooldown
+	}
+}
+
 func (s *Scale) JobGroupOut(job, group string, count int, meta map[string]string) (*ScaleResp, error) {
+	return s.JobGroupOutWithOptions(job, group, count, meta, nil)
+}
+
+// JobGroupOutWithOptions scales out the job group, applying the passed options to the request.
+func (s *Scale) JobGroupOutWithOptions(job, group string, count int, meta map[string]string, opts *ScaleOptions) (*ScaleResp, error) {
 	var resp ScaleResp
 
 	path := fmt.Sprintf("/v1/scale/out/%s/%s", job, group)
 
-	var q QueryOptions
+	q := QueryOptions{Params: make(map[string]string)}
 	if count > 0 {
-		q.Params = make(map[string]string)
 		q.Params["count"] = strconv.Itoa(count)
 	}
+	opts.params(&q)
 
 	err := s.client.post(path, buildScaleReqBody(meta), &resp, &q)
 	if err != nil {
@@ -62,13 +111,18 @@ func (s *Scale) JobGroupOut(job, group string, count int, meta map[string]string
 }
 
 func (s *Scale) JobGroupIn(job, group string, count int, meta map[string]string) (*ScaleResp, error) {
+	return s.JobGroupInWithOptions(job, group, count, meta, nil)
+}
+
+// JobGroupInWithOptions scales in the job group, applying the passed options to the request.
+func (s *Scale) JobGroupInWithOptions(job, group string, count int, meta map[string]string, opts *ScaleOptions) (*ScaleResp, error) {
 	var resp ScaleResp
 
-	var q QueryOptions
+	q := QueryOptions{Params: make(map[string]string)}
 	if count > 0 {
-		q.Params = make(map[string]string)
 		q.Params["count"] = strconv.Itoa(count)
 	}
+	opts.params(&q)
 
 	path := fmt.Sprintf("/v1/scale/in/%s/%s", job, group)
 
@@ -79,11 +133,74 @@ func (s *Scale) JobGroupIn(job, group string, count int, meta map[string]string)
 	return &resp, nil
 }
 
+// JobGroupCount scales the job group to the exact count. When force is true, the count is not
+// checked against the job group scaling policy bounds; this requires the server to have force
+// enabled.
+func (s *Scale) JobGroupCount(job, group string, count int, force bool, meta map[string]string) (*ScaleResp, error) {
+	return s.JobGroupCountWithOptions(job, group, count, force, meta, nil)
+}
+
+// JobGroupCountWithOptions scales the job group to the exact count, applying the passed options
+// to the request.
+func (s *Scale) JobGroupCountWithOptions(job, group string, count int, force bool, meta map[string]string, opts *ScaleOptions) (*ScaleResp, error) {
+	var resp ScaleResp
+
+	q := QueryOptions{Params: map[string]string{"count": strconv.Itoa(count)}}
+	if force {
+		q.Params["force"] = "true"
+	}
+	opts.params(&q)
+
+	path := fmt.Sprintf("/v1/scale/count/%s/%s", job, group)
+
+	err := s.client.post(path, buildScaleReqBody(meta), &resp, &q)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ScalingEventFilter selects the scaling events returned by ListWithFilter. Fields with their zero
+// value do not filter events.
+type ScalingEventFilter struct {
+	Job       string
+	Group     string
+	Direction string
+
+	// Outcome is one of success, failure or skipped.
+	Outcome string
+
+	From time.Time
+	To   time.Time
+}
+
 func (s *Scale) List(latest bool) (map[uuid.UUID]map[string]*ScalingEvent, error) {
+	return s.ListWithFilter(latest, nil)
+}
+
+// ListWithFilter lists the scaling events which pass the filter. The filter is evaluated by the
+// Sherpa server, so only matching events are returned.
+func (s *Scale) ListWithFilter(latest bool, filter *ScalingEventFilter) (map[uuid.UUID]map[string]*ScalingEvent, error) {
 	var resp map[uuid.UUID]map[string]*ScalingEvent
 
 	q := QueryOptions{Params: map[string]string{"latest": strconv.FormatBool(latest)}}
 
+	if filter != nil {
+		for k, v := range map[string]string{
+			"job": filter.Job, "group": filter.Group, "direction": filter.Direction, "outcome": filter.Outcome,
+		} {
+			if v != "" {
+				q.Params[k] = v
+			}
+		}
+		if !filter.From.IsZero() {
+			q.Params["from"] = filter.From.Format(time.RFC3339)
+		}
+		if !filter.To.IsZero() {
+			q.Params["to"] = filter.To.Format(time.RFC3339)
+		}
+	}
+
 	err := s.client.get("/v1/scale/status", &resp, &q)
 	if err != nil {
 		return nil, err
@@ -100,6 +217,50 @@ func (s *Scale) Info(id string) (map[string]*ScalingEvent, error) {
 	return resp, nil
 }
 
+// ScalingReport is the summary of scaling activity over a time range.
+type ScalingReport struct {
+	From   time.Time
+	To     time.Time
+	Groups []*GroupScalingReport
+}
+
+// GroupScalingReport is the summary of scaling activity for a single job group.
+type GroupScalingReport struct {
+	JobID            string
+	Group            string
+	ScaleOut         int
+	ScaleIn          int
+	Failed           int
+	FailureRate      float64
+	DirectionChanges int
+	Flapping         bool
+	TimeAtMax        float64
+	TimeAtMaxPercent float64
+}
+
+// Report returns aggregated statistics of the scaling events between from and to. Zero times use
+// the server defaults, and a zero flap threshold uses the server default threshold.
+func (s *Scale) Report(from, to time.Time, flapThreshold int) (*ScalingReport, error) {
+	var resp ScalingReport
+
+	q := QueryOptions{Params: map[string]string{}}
+	if !from.IsZero() {
+		q.Params["from"] = from.Format(time.RFC3339)
+	}
+	if !to.IsZero() {
+		q.Params["to"] = to.Format(time.RFC3339)
+	}
+	if flapThreshold > 0 {
+		q.Params["flap_threshold"] = strconv.Itoa(flapThreshold)
+	}
+
+	err := s.client.get("/v1/scale/report", &resp, &q)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
 func buildScaleReqBody(meta map[string]string) interface{} {
 	if meta == nil {
 		return nil
@@ -0,0 +1,37 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaleOptions_params(t *testing.T) {
+	testCases := []struct {
+		testName       string
+		opts           *ScaleOptions
+		expectedParams map[string]string
+	}{
+		{
+			testName:       "Test case: nil options",
+			opts:           nil,
+			expectedParams: map[string]string{},
+		},
+		{
+			testName:       "Test case: empty cooldown",
+			opts:           &ScaleOptions{},
+			expectedParams: map[string]string{},
+		},
+		{
+			testName:       "Test case: cooldown bypass",
+			opts:           &ScaleOptions{Cooldown: "bypass"},
+			expectedParams: map[string]string{"cooldown": "bypass"},
+		},
+	}
+
+	for _, tc := range testCases {
+		q := QueryOptions{Params: make(map[string]string)}
+		tc.opts.params(&q)
+		assert.Equal(t, tc.expectedParams, q.Params, tc.testName)
+	}
+}
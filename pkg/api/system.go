@@ -16,6 +16,20 @@ type InfoResp struct {
 	StorageBackend            string
 	InternalAutoScalingEngine bool
 	StrictPolicyChecking      bool
+
+	Build           BuildInfo
+	MetricProviders []string
+	Notifiers       []string
+	Features        map[string]bool
+}
+
+// BuildInfo describes the build of the Sherpa server binary.
+type BuildInfo struct {
+	Version   string
+	GitCommit string
+	GitBranch string
+	GitState  string
+	GoVersion string
 }
 
 // LeaderResp is the response from the Leader API call.
@@ -26,6 +40,34 @@ type LeaderResp struct {
 	LeaderClusterAddress string
 }
 
+// MembersResp is the response from the Members API call.
+type MembersResp struct {
+	Members []*Member
+}
+
+// Member is a single Sherpa server discovered via the cluster gossip layer.
+type Member struct {
+	ID         string
+	Addr       string
+	GossipAddr string
+	Leader     bool
+	Status     string
+}
+
+// ProviderStatus is the status of a single metric provider from the ProviderStatus API call.
+type ProviderStatus struct {
+	Name            string
+	State           string
+	Reachable       bool
+	ErrorRate       float64
+	Queries         int
+	Errors          int
+	LastError       string
+	LastErrorTime   int64
+	LastSuccessTime int64
+	OpenedTime      int64
+}
+
 func (c *Client) System() *System {
 	return &System{client: c}
 }
@@ -65,3 +107,41 @@ func (s *System) Leader() (*LeaderResp, error) {
 	}
 	return &resp, nil
 }
+
+// Members returns the cluster members known to the server via the gossip layer.
+func (s *System) Members() (*MembersResp, error) {
+	var resp MembersResp
+	err := s.client.get("/v1/system/members", &resp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// ProviderStatus returns the health and circuit breaker state of each autoscaler metric provider.
+func (s *System) ProviderStatus() ([]*ProviderStatus, error) {
+	var resp []*ProviderStatus
+	err := s.client.get("/v1/providers/status", &resp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+// LogLevels describes the server log level and the level overrides of each component. It is
+// used as both the request and response of the SetLogLevel API call.
+type LogLevels struct {
+	Level      string
+	Components map[string]string
+}
+
+// SetLogLevel changes the server log level, and component level overrides, at runtime. An empty
+// Level leaves the server level unchanged, and an empty component level removes the override.
+func (s *System) SetLogLevel(req *LogLevels) (*LogLevels, error) {
+	var resp LogLevels
+	err := s.client.put("/v1/system/loglevel", req, &resp, nil)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
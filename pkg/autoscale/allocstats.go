@@ -0,0 +1,150 @@
+package autoscale
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/chaos"
+	"github.com/jrasell/sherpa/pkg/helper"
+)
+
+// allocStatsFunc reads the resource usage of an allocation from the Nomad client running it.
+type allocStatsFunc func(alloc *nomad.Allocation) (*nomad.AllocResourceUsage, error)
+
+// allocStatsCache holds the recent resource usage of the allocations read by job evaluations. The
+// tracked allocations are refreshed in the background, so that evaluations read their stats from
+// memory rather than calling the Nomad client of each allocation. Allocations which have not been
+// read by an evaluation within the idle period are no longer refreshed.
+type allocStatsCache struct {
+	ttl   time.Duration
+	idle  time.Duration
+	fetch allocStatsFunc
+
+	lock    sync.Mutex
+	entries map[string]*allocStatsEntry
+}
+
+type allocStatsEntry struct {
+	alloc    *nomad.Allocation
+	stats    *nomad.AllocResourceUsage
+	updated  time.Time
+	lastRead time.Time
+}
+
+// newAllocStatsCache returns a cache which returns stats up to ttl old, and stops tracking
+// allocations which have not been read within idle.
+func newAllocStatsCache(ttl, idle time.Duration, fetch allocStatsFunc) *allocStatsCache {
+	return &allocStatsCache{
+		ttl:     ttl,
+		idle:    idle,
+		fetch:   fetch,
+		entries: make(map[string]*allocStatsEntry),
+	}
+}
+
+// get returns the cached stats of the allocation if they were updated within the TTL. It is safe
+// to call on a nil cache, which never returns stats.
+func (c *allocStatsCache) get(alloc *nomad.Allocation, now time.Time) (*nomad.AllocResourceUsage, bool) {
+	if c == nil {
+		return nil, false
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	e, ok := c.entries[alloc.ID]
+	if ok {
+		e.lastRead = now
+	}
+	if !ok || e.stats == nil || now.Sub(e.updated) > c.ttl {
+		sendMetrics.IncrCounter([]string{"autoscale", "alloc_stats_cache", "miss"}, 1)
+		return nil, false
+	}
+
+	sendMetrics.IncrCounter([]string{"autoscale", "alloc_stats_cache", "hit"}, 1)
+	return e.stats, true
+}
+
+// set stores the stats of the allocation, tracking the allocation for background refreshes. It
+// is safe to call on a nil cache.
+func (c *allocStatsCache) set(alloc *nomad.Allocation, stats *nomad.AllocResourceUsage, now time.Time) {
+	if c == nil {
+		return
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	c.entries[alloc.ID] = &allocStatsEntry{alloc: alloc, stats: stats, updated: now, lastRead: now}
+}
+
+// refresh removes the allocations which have not been read within the idle period, and fetches
+// the stats of the remaining allocations. Allocations whose stats cannot be read are removed, as
+// they have most likely stopped.
+func (c *allocStatsCache) refresh(now time.Time) {
+	c.lock.Lock()
+
+	var refresh []*nomad.Allocation // nolint:prealloc
+
+	for id, e := range c.entries {
+		if now.Sub(e.lastRead) > c.idle {
+			delete(c.entries, id)
+			continue
+		}
+		refresh = append(refresh, e.alloc)
+	}
+	sendMetrics.SetGauge([]string{"autoscale", "alloc_stats_cache", "size"}, float32(len(c.entries)))
+	c.lock.Unlock()
+
+	for _, alloc := range refresh {
+		stats, err := c.fetch(alloc)
+
+		c.lock.Lock()
+		if e, ok := c.entries[alloc.ID]; ok {
+			if err != nil {
+				delete(c.entries, alloc.ID)
+			} else {
+				e.stats, e.updated = stats, time.Now()
+			}
+		}
+		c.lock.Unlock()
+	}
+}
+
+// run refreshes the cached stats at half the TTL, so the stats read by evaluations are within the
+// TTL, until stopCh is closed.
+func (c *allocStatsCache) run(stopCh <-chan struct{}) {
+	t := time.NewTicker(c.ttl / 2)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			c.refresh(time.Now())
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// fetchAllocStats reads the resource usage of the allocation, bounded by the Nomad API timeout.
+// It is used to refresh the allocation stats cache outside of a job evaluation.
+func (a *AutoScale) fetchAllocStats(alloc *nomad.Allocation) (*nomad.AllocResourceUsage, error) {
+	ctx, cancel := helper.ContextWithTimeout(context.Background(), time.Duration(a.cfg.NomadTimeout)*time.Second)
+	defer cancel()
+
+	if err := a.faults.Inject(ctx, chaos.TargetNomad); err != nil {
+		return nil, err
+	}
+
+	var stats *nomad.AllocResourceUsage
+
+	err := helper.CallWithContext(ctx, func() (err error) {
+		stats, err = a.nomad.Client().Allocations().Stats(alloc, nil)
+		return err
+	})
+	return stats, err
+}
@@ -0,0 +1,57 @@
+package autoscale
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_allocStatsCache(t *testing.T) {
+	now := time.Now()
+	alloc := &nomad.Allocation{ID: "alloc-1"}
+	stats := &nomad.AllocResourceUsage{Timestamp: 1}
+
+	var nilCache *allocStatsCache
+	_, ok := nilCache.get(alloc, now)
+	assert.False(t, ok)
+	nilCache.set(alloc, stats, now)
+
+	c := newAllocStatsCache(10*time.Second, time.Minute, nil)
+
+	_, ok = c.get(alloc, now)
+	assert.False(t, ok)
+
+	c.set(alloc, stats, now)
+	out, ok := c.get(alloc, now.Add(5*time.Second))
+	assert.True(t, ok)
+	assert.Equal(t, stats, out)
+
+	_, ok = c.get(alloc, now.Add(11*time.Second))
+	assert.False(t, ok)
+}
+
+func Test_allocStatsCache_refresh(t *testing.T) {
+	now := time.Now()
+	fresh := &nomad.AllocResourceUsage{Timestamp: 2}
+
+	c := newAllocStatsCache(10*time.Second, time.Minute, func(alloc *nomad.Allocation) (*nomad.AllocResourceUsage, error) {
+		if alloc.ID == "stopped" {
+			return nil, errors.New("unknown allocation")
+		}
+		return fresh, nil
+	})
+
+	c.set(&nomad.Allocation{ID: "running"}, &nomad.AllocResourceUsage{Timestamp: 1}, now)
+	c.set(&nomad.Allocation{ID: "stopped"}, &nomad.AllocResourceUsage{Timestamp: 1}, now)
+	c.set(&nomad.Allocation{ID: "idle"}, &nomad.AllocResourceUsage{Timestamp: 1}, now.Add(-2*time.Minute))
+
+	c.refresh(now)
+
+	assert.Len(t, c.entries, 1)
+	out, ok := c.get(&nomad.Allocation{ID: "running"}, time.Now())
+	assert.True(t, ok)
+	assert.Equal(t, fresh, out)
+}
@@ -1,23 +1,102 @@
 package autoscale
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"sort"
+	"sync"
 	"time"
 
 	sendMetrics "github.com/armon/go-metrics"
+	"github.com/gofrs/uuid"
 	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/autoscale/evallog"
 	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/chaos"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/filter"
+	"github.com/jrasell/sherpa/pkg/helper"
 	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/retry"
 	"github.com/jrasell/sherpa/pkg/scale"
 	"github.com/jrasell/sherpa/pkg/state"
 	"github.com/rs/zerolog"
 )
 
 type autoscaleEvaluation struct {
+	// id uniquely identifies this evaluation run, and is included in all log lines and records.
+	id uuid.UUID
+
+	// tuner is updated with the Nomad API latency observed during the evaluation.
+	tuner *poolTuner
+
+	// ctx bounds the evaluation, and is cancelled if the evaluation timeout is reached. Each
+	// Nomad API call and metric provider query is further bounded by nomadTimeout and
+	// queryTimeout respectively.
+	ctx          context.Context
+	nomadTimeout time.Duration
+	queryTimeout time.Duration
+
+	// faults is the optional fault injector applied to Nomad API calls for resilience testing.
+	faults *chaos.Injector
+
+	// jobFilter restricts the jobs which are evaluated. Filter options which require the Nomad
+	// job are checked at the start of the evaluation.
+	jobFilter *filter.JobFilter
+
+	// dryRun causes the scaling decisions of the evaluation to be logged and recorded, without
+	// scaling being triggered.
+	dryRun bool
+
+	// shadowWindow is the window, ending at the evaluation, within which the scaling actions of a
+	// co-deployed Nomad Autoscaler are compared with the decisions of the evaluation. Shadow mode
+	// is disabled when this is zero.
+	shadowWindow time.Duration
+
+	// boundsEnforcement is the action taken when a job group count is outside its policy bounds.
+	boundsEnforcement server.BoundsEnforcement
+
+	// minStatsCoverage is the minimum percentage of a group's allocations whose resource usage
+	// must be gathered for Nomad checks to be performed on the group.
+	minStatsCoverage float64
+
+	// drainAwareScaleIn reduces scale in decisions by the number of group allocations placed on
+	// draining or scheduling ineligible nodes.
+	drainAwareScaleIn bool
+
+	// groupConcurrency is the maximum number of job groups evaluated, and allocations whose stats
+	// are gathered, concurrently during the evaluation.
+	groupConcurrency int
+
+	// lock guards the lazily populated job data and the evaluation record, as the groups of the
+	// job are evaluated concurrently.
+	lock sync.Mutex
+
 	nomad          *nomad.Client
 	metricProvider map[policy.MetricsProvider]metrics.Provider
 	scaler         scale.Scale
 
+	// overrides are the active metric overrides, whose values are used in place of the real
+	// metric values of the matching group checks.
+	overrides *metricOverrides
+
+	// allocStats is the optional cache of allocation resource usage, which is read before calling
+	// the Nomad client of an allocation.
+	allocStats *allocStatsCache
+
+	// orphans tracks the jobs which have been found stopped or purged, so that repeated detection
+	// of the same orphaned job is not reported every evaluation.
+	orphans *orphanTracker
+
+	// decisions tracks the latest scaling decision made for each job group.
+	decisions *decisionTracker
+
+	// promEndpoints are the named Prometheus-compatible endpoint providers which external checks
+	// can reference.
+	promEndpoints map[string]metrics.Provider
+
 	// policies are the job group policies that will be evaluated during this run.
 	policies map[string]*policy.GroupScalingPolicy
 
@@ -27,8 +106,34 @@ type autoscaleEvaluation struct {
 	// time is the unix nano time indicating when this scaling evaluation was triggered.
 	time int64
 
-	// log has the jobID context to save repeating this effort.
+	// log has the jobID and evaluation ID context to save repeating this effort.
 	log zerolog.Logger
+
+	// nomadMetricData is the Nomad resource data gathered for the job during this evaluation. It
+	// will be nil if no groups have Nomad checks configured, or if gathering the data failed.
+	nomadMetricData *nomadGatheredMetrics
+
+	// nomadMetricErr is the error returned when gathering the Nomad resource data, ensuring the
+	// collection is not attempted multiple times during a single evaluation.
+	nomadMetricErr error
+
+	// evalLog is the optional writer for the evaluation log, and record is the record of this
+	// evaluation which will be written to it. Both are nil if the evaluation log is disabled.
+	evalLog *evallog.Writer
+	record  *evallog.Record
+
+	// groupCounts is the current count of each job group. It is lazily populated when a check
+	// first requires it, so that jobs which do not need the data avoid the API call.
+	groupCounts map[string]int
+
+	// namespace is the Nomad namespace of the job, and jobStopped whether the job has been
+	// stopped, populated alongside the group counts.
+	namespace  string
+	jobStopped bool
+
+	// snapshots are the metric values gathered for each group during the evaluation, which are
+	// stored alongside the resulting scaling events.
+	snapshots map[string]*state.ResourceSnapshot
 }
 
 func (ae *autoscaleEvaluation) evaluateJob() {
@@ -36,65 +141,117 @@ func (ae *autoscaleEvaluation) evaluateJob() {
 
 	defer sendMetrics.MeasureSince([]string{"autoscale", ae.jobID, "evaluation"}, time.Now())
 
+	if !ae.matchJobFilter() || ae.checkOrphaned() {
+		return
+	}
+
+	if ae.evalLog != nil {
+		ae.record = evallog.NewRecord(ae.id.String(), ae.jobID, time.Unix(0, ae.time))
+		ae.record.DryRun = ae.dryRun
+		ae.recordPolicies()
+	}
+
 	externalDecision := make(map[string]*scalingDecision)
 	nomadDecision := make(map[string]*scalingDecision)
 
 	// We need to check to see whether the the job policies contain a group which is using Nomad
-	// checks. This dictates whether we run the initial gatherNomadMetrics function and then
-	// trigger the Nomad evaluation.
+	// resource metrics, either via the Nomad checks or external checks. This dictates whether we
+	// run the initial gatherNomadMetrics function.
 	var nomadCheck bool
 	for _, p := range ae.policies {
-		if p.NomadChecksEnabled() {
+		if p.NomadResourcesRequired() {
 			nomadCheck = true
 			break
 		}
 	}
 
-	var (
-		nomadMetricData *nomadGatheredMetrics
-		err             error
-	)
-
 	// If the job policy contains groups which rely on Nomad data, we should collect this now. It
 	// is most efficient to collect this data on a per job basis rather than per group. If we get
 	// an error when performing this, log it and continue. It is possible external checks are also
 	// in place and working; we can nil check the nomadMetricData to skip Nomad checks during this
 	// evaluation.
 	if nomadCheck {
-		nomadMetricData, err = ae.gatherNomadMetrics()
-		if err != nil {
-			ae.log.Error().Err(err).Msg("failed to collect Nomad metrics, skipping Nomad based checks")
+		ae.nomadMetricData, ae.nomadMetricErr = ae.gatherNomadMetrics()
+		if ae.nomadMetricErr != nil {
+			ae.log.Error().Err(ae.nomadMetricErr).Msg("failed to collect Nomad metrics, skipping Nomad based checks")
+			ae.recordNomadMetricsError(ae.nomadMetricErr)
 		}
 	}
 
-	// Iterate over the group policies for the job currently under evaluation.
-	for group, p := range ae.policies {
+	// Evaluate the groups concurrently, as large jobs with many groups would otherwise serialize
+	// the metric provider queries for each group.
+	groups := make([]string, 0, len(ae.policies))
+	for group := range ae.policies {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	nomadDecs := make([]*scalingDecision, len(groups))
+	externalDecs := make([]*scalingDecision, len(groups))
 
-		// Setup a start time so we can measure how long an individual job group evaluation takes.
-		start := time.Now()
-		ae.log.Debug().Str("group", group).Msg("triggering autoscaling job group evaluation")
+	forEachBounded(len(groups), ae.groupConcurrency, func(i int) {
+		nomadDecs[i], externalDecs[i] = ae.evaluateGroup(groups[i], ae.policies[groups[i]])
+	})
 
-		// If the group policy has Nomad checks enabled, and we managed to successfully get the
-		// Nomad metric data, perform the evaluation.
-		if p.NomadChecksEnabled() && nomadMetricData != nil {
-			if nomadDec := ae.evaluateNomadJobMetrics(group, p, nomadMetricData); nomadDec != nil {
-				nomadDecision[group] = nomadDec
-			}
+	for i, group := range groups {
+		if nomadDecs[i] != nil {
+			nomadDecision[group] = nomadDecs[i]
+		}
+		if externalDecs[i] != nil {
+			externalDecision[group] = externalDecs[i]
 		}
+	}
+
+	ae.evaluateDecisions(nomadDecision, externalDecision)
+}
+
+// evaluateGroup performs the checks of a single job group, returning the Nomad and external
+// decisions for the group. Either decision is nil if it does not require scaling.
+func (ae *autoscaleEvaluation) evaluateGroup(group string, p *policy.GroupScalingPolicy) (nomadDec, externalDec *scalingDecision) {
+
+	// Setup a start time so we can measure how long an individual job group evaluation takes.
+	start := time.Now()
+	ae.log.Debug().Str("group", group).Msg("triggering autoscaling job group evaluation")
+
+	// This iteration has ended, so record the Sherpa metric.
+	defer sendMetrics.MeasureSince([]string{"autoscale", ae.jobID, group, "evaluation"}, start)
 
-		// If the group has external checks, perform these and ensure the decision if not nil,
-		// before adding this to the decision tree.
-		if p.ExternalChecks != nil {
-			if extDec := ae.calculateExternalScalingDecision(group, p); extDec != nil {
-				externalDecision[group] = extDec
-			}
+	// Track whether any of the metric sources configured for the group were available, so that
+	// the fallback behaviour can be performed if they all failed.
+	var metricsAvailable bool
+
+	// If the group policy has Nomad checks enabled, and we managed to successfully get the Nomad
+	// metric data, perform the evaluation.
+	if p.NomadChecksEnabled() && ae.hasNomadMetrics(group) {
+		metricsAvailable = true
+		nomadDec = ae.evaluateNomadJobMetrics(group, p, ae.nomadMetricData)
+	}
+
+	// If the group has external checks, perform these. The decision will be nil if no scaling is
+	// required.
+	if p.ExternalChecks != nil {
+		var ok bool
+		externalDec, ok = ae.calculateExternalScalingDecision(group, p)
+		if ok {
+			metricsAvailable = true
 		}
+	}
 
-		// This iteration has ended, so record the Sherpa metric.
-		sendMetrics.MeasureSince([]string{"autoscale", ae.jobID, group, "evaluation"}, start)
+	// If the group has metric sources configured but none of them were available, perform the
+	// fallback. The group has no other decision, so any fallback decision is handled alongside
+	// the external decisions.
+	if !metricsAvailable && (p.NomadChecksEnabled() || p.ExternalChecksEnabled()) {
+		if fallbackDec := ae.evaluateMetricsFallback(group, p); fallbackDec != nil {
+			externalDec = fallbackDec
+		}
 	}
 
-	ae.evaluateDecisions(nomadDecision, externalDecision)
+	// A group count outside the policy bounds overrides any metric based decision when bounds
+	// enforcement is set to correct, so that the group is returned within its bounds.
+	if boundsDec := ae.evaluateBounds(group, p); boundsDec != nil {
+		nomadDec, externalDec = nil, boundsDec
+	}
+	return nomadDec, externalDec
 }
 
 func (ae *autoscaleEvaluation) evaluateDecisions(nomadDecision, externalDecision map[string]*scalingDecision) {
@@ -102,6 +259,8 @@ func (ae *autoscaleEvaluation) evaluateDecisions(nomadDecision, externalDecision
 	// Exit quickly if there are now scaling decisions to process.
 	if len(nomadDecision) == 0 && len(externalDecision) == 0 {
 		ae.log.Info().Msg("scaling evaluation completed and no scaling required")
+		ae.compareShadowDecisions(nil)
+		ae.writeEvaluationRecord(nil, nil)
 		return
 	}
 	var finalDecision map[string]*scalingDecision
@@ -127,6 +286,21 @@ func (ae *autoscaleEvaluation) evaluateDecisions(nomadDecision, externalDecision
 		finalDecision = ae.buildSingleDecision(nomadDecision, externalDecision)
 	}
 
+	// Groups which request GPUs can only scale out as far as the healthy GPU capacity of the
+	// cluster allows.
+	ae.enforceGPUCapacity(finalDecision)
+
+	// Groups losing allocations to node drains already have less capacity than their count, so
+	// scaling in is reduced by the number of allocations on draining nodes.
+	ae.enforceDrainAwareScaleIn(finalDecision)
+
+	// Decisions which would change the group count by less than the policy minimum scale delta
+	// are not acted upon, to reduce churn from small adjustments.
+	ae.enforceMinScaleDelta(finalDecision)
+	ae.recordDecisions(finalDecision)
+	ae.decisions.record(ae.jobID, finalDecision, time.Unix(0, ae.time))
+	ae.compareShadowDecisions(finalDecision)
+
 	// Build the scaling request to send to the scaler backend.
 	scaleReq := ae.buildScalingReq(finalDecision)
 
@@ -134,15 +308,70 @@ func (ae *autoscaleEvaluation) evaluateDecisions(nomadDecision, externalDecision
 	// checked, trigger a scaling event. Run this in a routine as from this point there is nothing
 	// we can do.
 	if len(scaleReq) > 0 {
+		if ae.dryRun {
+			ae.logDryRunScaling(scaleReq)
+			ae.writeEvaluationRecord(nil, nil)
+			return
+		}
+		ae.addPlacementPressureMeta(scaleReq)
 		go ae.triggerScaling(scaleReq)
+		return
 	}
+	ae.writeEvaluationRecord(nil, nil)
 }
 
 // triggerScaling is used to trigger the scaling of a job based on one or more group changes as
-// as result of the scaling evaluation.
+// as result of the scaling evaluation. When the group policies define a scale order, the groups
+// are scaled in ordered steps, optionally waiting for each step to become healthy before the next
+// is triggered. A failed step stops the remaining steps from being triggered.
 func (ae *autoscaleEvaluation) triggerScaling(req []*scale.GroupReq) {
-	resp, _, err := ae.scaler.Trigger(ae.jobID, req, state.SourceInternalAutoscaler)
-	if err != nil {
+	var (
+		resp *scale.ScalingResponse
+		err  error
+	)
+
+	steps := buildScalingSteps(req)
+
+	for i, step := range steps {
+		if resp, err = ae.triggerScalingStep(step); err != nil {
+			break
+		}
+
+		timeout := getStepWaitTimeout(step)
+		if i == len(steps)-1 || timeout == 0 {
+			continue
+		}
+
+		if err = ae.waitForGroupsHealthy(step, timeout); err != nil {
+			ae.log.Error().Err(err).Int("remaining-steps", len(steps)-i-1).
+				Msg("stopping ordered scaling of job")
+			break
+		}
+	}
+	ae.writeEvaluationRecord(resp, err)
+}
+
+// logDryRunScaling logs the group scaling requests which would have been triggered if the
+// autoscaler was not running in dry-run mode.
+func (ae *autoscaleEvaluation) logDryRunScaling(req []*scale.GroupReq) {
+	for _, groupReq := range req {
+		ae.log.Info().
+			Object("scaling-req", groupReq).
+			Msg("dry-run mode enabled, skipping triggering of autoscaling")
+	}
+	sendDryRunMetrics(ae.jobID)
+}
+
+// triggerScalingStep triggers the scaling of the groups within a single scaling step.
+func (ae *autoscaleEvaluation) triggerScalingStep(req []*scale.GroupReq) (*scale.ScalingResponse, error) {
+	// Scaling is triggered once the evaluation has completed, so is not bound by the evaluation
+	// context. The scaler applies its own timeout to the Nomad API calls it makes.
+	resp, code, err := ae.scaler.Trigger(context.Background(), ae.jobID, req, state.SourceInternalAutoscaler)
+	switch {
+	case code == http.StatusNotFound:
+		// The job was purged between the evaluation reading it and scaling being triggered.
+		ae.handleOrphanedJob(orphanPurged)
+	case err != nil:
 		ae.log.Error().Err(err).Msg("failed to trigger scaling of job")
 		sendTriggerErrorMetrics(ae.jobID)
 	}
@@ -154,6 +383,7 @@ func (ae *autoscaleEvaluation) triggerScaling(req []*scale.GroupReq) {
 			Msg("successfully triggered autoscaling of job")
 		sendTriggerSuccessMetrics(ae.jobID)
 	}
+	return resp, err
 }
 
 // buildScalingReq takes the scaling decisions for the job under evaluation, and creates a list of
@@ -161,7 +391,16 @@ func (ae *autoscaleEvaluation) triggerScaling(req []*scale.GroupReq) {
 func (ae *autoscaleEvaluation) buildScalingReq(dec map[string]*scalingDecision) []*scale.GroupReq {
 	var scaleReq []*scale.GroupReq // nolint:prealloc
 
-	for group, decision := range dec {
+	// Iterate the groups in name order so the requests, and therefore the logs and Nomad job
+	// submissions, are consistent between evaluations.
+	groups := make([]string, 0, len(dec))
+	for group := range dec {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		decision := dec[group]
 
 		// Iterate over the resource metrics which have broken their thresholds and ensure these
 		// are added to the submission meta.
@@ -170,6 +409,7 @@ func (ae *autoscaleEvaluation) buildScalingReq(dec map[string]*scalingDecision)
 		for name, metric := range decision.metrics {
 			updateAutoscaleMeta(name, metric.value, metric.threshold, meta)
 		}
+		ae.addStatsCoverageMeta(group, decision, meta)
 
 		// Build the job group scaling request.
 		req := &scale.GroupReq{
@@ -178,7 +418,9 @@ func (ae *autoscaleEvaluation) buildScalingReq(dec map[string]*scalingDecision)
 			GroupName:          group,
 			GroupScalingPolicy: ae.policies[group],
 			Time:               ae.time,
+			Reason:             decision.getReason(),
 			Meta:               meta,
+			Snapshot:           ae.snapshots[group],
 		}
 		scaleReq = append(scaleReq, req)
 
@@ -247,3 +489,34 @@ func updateAutoscaleMeta(metricType string, value, threshold float64, meta map[s
 	meta[key+"-value"] = fmt.Sprintf("%.2f", value)
 	meta[key+"-threshold"] = fmt.Sprintf("%.2f", threshold)
 }
+
+// context returns the context of the evaluation, defaulting to the background context when one
+// has not been set.
+func (ae *autoscaleEvaluation) context() context.Context {
+	if ae.ctx == nil {
+		return context.Background()
+	}
+	return ae.ctx
+}
+
+// callNomad runs the Nomad API call f, returning early with an error if the evaluation context is
+// cancelled or the Nomad timeout is reached. The Nomad client does not support contexts, so a
+// call which is abandoned will continue to run in the background until it completes.
+func (ae *autoscaleEvaluation) callNomad(f func() error) error {
+	return ae.callNomadWithContext(ae.context(), f)
+}
+
+// callNomadWithContext runs the Nomad API call f in the same manner as callNomad, but bound by the
+// passed context. This is used for calls made once the evaluation has completed. Failed calls are
+// retried within the Nomad timeout.
+func (ae *autoscaleEvaluation) callNomadWithContext(ctx context.Context, f func() error) error {
+	ctx, cancel := helper.ContextWithTimeout(ctx, ae.nomadTimeout)
+	defer cancel()
+
+	return retry.Do(ctx, retry.TargetNomad, retry.Default, func() error {
+		if err := ae.faults.Inject(ctx, chaos.TargetNomad); err != nil {
+			return err
+		}
+		return client.RetryableNomadError(helper.CallWithContext(ctx, f))
+	})
+}
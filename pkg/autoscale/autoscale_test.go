@@ -5,6 +5,7 @@ import (
 
 	"github.com/jrasell/sherpa/pkg/policy"
 	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -37,6 +38,7 @@ func Test_autoscaleEvaluation_buildScalingReq(t *testing.T) {
 					GroupName:          "test-group",
 					GroupScalingPolicy: &policy.GroupScalingPolicy{Cooldown: 3000},
 					Time:               1313131313,
+					Reason:             state.ReasonThresholdCPUIn,
 					Meta: map[string]string{
 						"datadog-cpu-threshold": "90.00",
 						"datadog-cpu-value":     "99.00",
@@ -79,6 +81,7 @@ func Test_autoscaleEvaluation_buildScalingReq(t *testing.T) {
 					GroupName:          "test-group",
 					GroupScalingPolicy: &policy.GroupScalingPolicy{Cooldown: 3000},
 					Time:               1313131313,
+					Reason:             state.ReasonThresholdCPUIn,
 					Meta: map[string]string{
 						"datadog-cpu-threshold": "90.00",
 						"datadog-cpu-value":     "99.00",
@@ -92,6 +95,7 @@ func Test_autoscaleEvaluation_buildScalingReq(t *testing.T) {
 					GroupName:          "test-group-1",
 					GroupScalingPolicy: &policy.GroupScalingPolicy{Cooldown: 3001},
 					Time:               1313131313,
+					Reason:             state.ReasonExternalCheckOut,
 					Meta: map[string]string{
 						"prometheus-cpu-threshold": "90.00",
 						"prometheus-cpu-value":     "99.00",
@@ -0,0 +1,85 @@
+package autoscale
+
+import (
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
+)
+
+// boundsCountMetricName is the metric name used within scaling meta when a group is scaled back
+// within its policy bounds.
+const boundsCountMetricName = "bounds-count"
+
+// evaluateBounds checks the current count of the group against the min and max counts of the
+// policy when bounds enforcement is enabled. Groups outside their bounds are reported, and when
+// the enforcement mode is correct, a decision to scale to the nearest bound is returned.
+func (ae *autoscaleEvaluation) evaluateBounds(group string, pol *policy.GroupScalingPolicy) *scalingDecision {
+	if ae.boundsEnforcement != server.BoundsEnforcementAlert && ae.boundsEnforcement != server.BoundsEnforcementCorrect {
+		return nil
+	}
+
+	count, err := ae.getGroupCount(group)
+	if err != nil {
+		ae.log.Error().Err(err).Str("group", group).Msg("failed to get job group count for bounds enforcement")
+		return nil
+	}
+
+	dec := calculateBoundsDecision(count, pol)
+	if dec == nil {
+		return nil
+	}
+
+	sendMetrics.IncrCounterWithLabels([]string{"autoscale", "bounds_violation"}, 1, []sendMetrics.Label{
+		{Name: "job", Value: ae.jobID},
+		{Name: "group", Value: group},
+		{Name: "mode", Value: ae.boundsEnforcement.String()},
+	})
+
+	log := ae.log.Warn().
+		Str("group", group).
+		Int("count", count).
+		Int("min-count", pol.MinCount).
+		Int("max-count", pol.MaxCount)
+
+	if ae.boundsEnforcement == server.BoundsEnforcementAlert {
+		log.Msg("job group count is outside policy bounds")
+		return nil
+	}
+
+	log.Msg("job group count is outside policy bounds, scaling to nearest bound")
+	return dec
+}
+
+// calculateBoundsDecision produces the decision required to move the group from its current count
+// to the nearest policy bound. Nil is returned if the count is already within bounds.
+func calculateBoundsDecision(count int, pol *policy.GroupScalingPolicy) *scalingDecision {
+	var (
+		direction scale.Direction
+		bound     int
+	)
+
+	switch {
+	case count < pol.MinCount:
+		direction, bound = scale.DirectionOut, pol.MinCount
+	case count > pol.MaxCount:
+		direction, bound = scale.DirectionIn, pol.MaxCount
+	default:
+		return nil
+	}
+
+	diff := bound - count
+	if diff < 0 {
+		diff = -diff
+	}
+
+	return &scalingDecision{
+		direction: direction,
+		count:     diff,
+		reason:    state.ReasonBoundsEnforcement,
+		metrics: map[string]*scalingMetricDecision{
+			boundsCountMetricName: {value: float64(count), threshold: float64(bound)},
+		},
+	}
+}
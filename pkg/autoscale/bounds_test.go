@@ -0,0 +1,68 @@
+package autoscale
+
+import (
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_calculateBoundsDecision(t *testing.T) {
+	pol := &policy.GroupScalingPolicy{MinCount: 2, MaxCount: 10}
+
+	testCases := []struct {
+		name             string
+		currentCount     int
+		expectedDecision *scalingDecision
+	}{
+		{
+			name:         "count below minimum",
+			currentCount: 0,
+			expectedDecision: &scalingDecision{
+				direction: scale.DirectionOut,
+				count:     2,
+				reason:    state.ReasonBoundsEnforcement,
+				metrics:   map[string]*scalingMetricDecision{boundsCountMetricName: {value: 0, threshold: 2}},
+			},
+		},
+		{
+			name:         "count above maximum",
+			currentCount: 13,
+			expectedDecision: &scalingDecision{
+				direction: scale.DirectionIn,
+				count:     3,
+				reason:    state.ReasonBoundsEnforcement,
+				metrics:   map[string]*scalingMetricDecision{boundsCountMetricName: {value: 13, threshold: 10}},
+			},
+		},
+		{
+			name:             "count within bounds",
+			currentCount:     10,
+			expectedDecision: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedDecision, calculateBoundsDecision(tc.currentCount, pol), tc.name)
+	}
+}
+
+func Test_autoscaleEvaluation_evaluateBounds(t *testing.T) {
+	pol := &policy.GroupScalingPolicy{MinCount: 1, MaxCount: 3}
+	ae := &autoscaleEvaluation{groupCounts: map[string]int{"worker": 5}}
+
+	for _, mode := range []server.BoundsEnforcement{"", server.BoundsEnforcementDisabled, server.BoundsEnforcementAlert} {
+		ae.boundsEnforcement = mode
+		assert.Nil(t, ae.evaluateBounds("worker", pol), mode.String())
+	}
+
+	ae.boundsEnforcement = server.BoundsEnforcementCorrect
+	dec := ae.evaluateBounds("worker", pol)
+	assert.NotNil(t, dec)
+	assert.Equal(t, scale.DirectionIn, dec.direction)
+	assert.Equal(t, 2, dec.count)
+	assert.Equal(t, state.ReasonBoundsEnforcement, dec.getReason())
+}
@@ -1,28 +1,112 @@
 package autoscale
 
 import (
-	"github.com/hashicorp/nomad/api"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/jrasell/sherpa/pkg/chaos"
+	"github.com/jrasell/sherpa/pkg/client"
 	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/filter"
 	policyBackend "github.com/jrasell/sherpa/pkg/policy/backend"
 	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/secret"
 	"github.com/rs/zerolog"
 )
 
 type SetupConfig struct {
 	ScalingInterval   int
 	ScalingThreads    int
+	ScalingThreadsMin int
+	ScalingThreadsMax int
 	StrictChecking    bool
 	MetricProviderCfg *server.MetricProviderConfig
+	EvaluationLogPath string
+
+	// NomadLatencyThreshold is the Nomad API latency in milliseconds above which the auto-tuned
+	// worker pool is shrunk.
+	NomadLatencyThreshold int
+
+	// MaxStaleness is the time in seconds a job group can go without evaluation before its
+	// evaluation will no longer be deferred when the worker pool is saturated.
+	MaxStaleness int
+
+	// EvaluationTimeout is the time in seconds a single job evaluation can take, and NomadTimeout
+	// the time in seconds a single Nomad API call can take, before they are cancelled.
+	EvaluationTimeout int
+	NomadTimeout      int
+
+	// DryRun causes the autoscaler to evaluate jobs and record its decisions, without triggering
+	// any scaling.
+	DryRun bool
+
+	// ShadowMode causes the autoscaler to compare its decisions with the scaling actions of a
+	// co-deployed Nomad Autoscaler, reporting any divergence. It should be used alongside DryRun.
+	ShadowMode bool
+
+	// BoundsEnforcement is the action taken when a job group count is outside its policy bounds.
+	BoundsEnforcement server.BoundsEnforcement
+
+	// MinStatsCoverage is the minimum percentage of a group's allocations whose resource usage
+	// must be gathered for the group to be evaluated using Nomad checks. When below 100, groups
+	// are evaluated using the subset of allocations on reachable nodes.
+	MinStatsCoverage float64
+
+	// GroupConcurrency is the maximum number of job groups evaluated, and allocation stats
+	// gathered, concurrently within a single job evaluation.
+	GroupConcurrency int
+
+	// AllocStatsCacheTTL is the time in seconds cached allocation resource usage is used by job
+	// evaluations. The allocation stats cache is disabled when this is zero.
+	AllocStatsCacheTTL int
+
+	// DrainAwareScaleIn reduces scale in decisions by the number of group allocations placed on
+	// draining or scheduling ineligible nodes, as these allocations are about to be lost.
+	DrainAwareScaleIn bool
+
+	// OrphanPolicyGC is the time in seconds the job of a scaling policy can be stopped or purged
+	// before the policy is deleted. Orphaned policies are not deleted when this is zero.
+	OrphanPolicyGC int
 
 	Logger        zerolog.Logger
 	PolicyBackend policyBackend.PolicyBackend
 	Scale         scale.Scale
-	Nomad         *api.Client
+	Cooldowns     CooldownStore
+	Nomad         *client.NomadPool
+	Consul        *consul.Client
+
+	// FaultInjector is the optional fault injector applied to Nomad API calls and metric provider
+	// queries for resilience testing.
+	FaultInjector *chaos.Injector
+
+	// Secrets is used to resolve metric provider credentials which are set as secret references.
+	// If nil, credentials are used as plain values.
+	Secrets *secret.Resolver
+
+	// JobFilter is the optional filter restricting the jobs which are evaluated.
+	JobFilter *filter.JobFilter
+
+	// Shard is the optional owner check used when evaluations are sharded across the cluster
+	// members, so that each job is only evaluated by the member which owns it.
+	Shard JobOwner
+}
+
+// JobOwner determines whether this Sherpa server is responsible for evaluating a job.
+type JobOwner interface {
+	OwnsJob(job string) bool
 }
 
 type Config struct {
 	ScalingInterval   int
 	ScalingThreads    int
 	StrictChecking    bool
+	MaxStaleness      int
+	EvaluationTimeout int
+	NomadTimeout      int
+	DryRun            bool
+	ShadowMode        bool
+	BoundsEnforcement server.BoundsEnforcement
+	MinStatsCoverage  float64
+	GroupConcurrency  int
+	DrainAwareScaleIn bool
+	OrphanPolicyGC    int
 	MetricProviderCfg *server.MetricProviderConfig
 }
@@ -1,8 +1,14 @@
 package autoscale
 
 import (
+	"sort"
+
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
 	"github.com/jrasell/sherpa/pkg/policy"
 	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
 
@@ -10,6 +16,10 @@ type scalingDecision struct {
 	direction scale.Direction
 	count     int
 	metrics   map[string]*scalingMetricDecision
+
+	// reason overrides the reason code derived from the decision metrics. This is used where the
+	// metrics alone do not describe why the decision was made, such as the metrics fallback.
+	reason state.Reason
 }
 
 // scalingMetricDecision describes the metric value and threshold which resulted in the decision to
@@ -34,6 +44,60 @@ func (sd *scalingDecision) MarshalZerologObject(e *zerolog.Event) {
 	e.Dict("resources", dict)
 }
 
+// usesNomadMetrics returns whether the decision was made using Nomad resource metrics.
+func (sd *scalingDecision) usesNomadMetrics() bool {
+	for name := range sd.metrics {
+		switch name {
+		case nomadCPUMetricName, nomadMemoryMetricName, nomadDiskMetricName, nomadGPUMetricName, nomadCompositeMetricName:
+			return true
+		}
+	}
+	return false
+}
+
+// getReason returns the reason code for the decision. Unless explicitly set, the reason is derived
+// from the metrics which broke their thresholds. Nomad resources take precedence over external
+// checks, and are picked in name order so the reason is consistent between evaluations.
+func (sd *scalingDecision) getReason() state.Reason {
+	if sd.reason != "" {
+		return sd.reason
+	}
+	if len(sd.metrics) == 0 {
+		return state.ReasonUnknown
+	}
+
+	names := make([]string, 0, len(sd.metrics))
+	for name := range sd.metrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := sd.direction == scale.DirectionOut
+
+	for _, name := range names {
+		switch name {
+		case nomadCPUMetricName:
+			return pickReason(out, state.ReasonThresholdCPUOut, state.ReasonThresholdCPUIn)
+		case nomadMemoryMetricName:
+			return pickReason(out, state.ReasonThresholdMemoryOut, state.ReasonThresholdMemoryIn)
+		case nomadDiskMetricName:
+			return pickReason(out, state.ReasonThresholdDiskOut, state.ReasonThresholdDiskIn)
+		case nomadGPUMetricName:
+			return pickReason(out, state.ReasonThresholdGPUOut, state.ReasonThresholdGPUIn)
+		case nomadCompositeMetricName:
+			return pickReason(out, state.ReasonThresholdCompositeOut, state.ReasonThresholdCompositeIn)
+		}
+	}
+	return pickReason(out, state.ReasonExternalCheckOut, state.ReasonExternalCheckIn)
+}
+
+func pickReason(out bool, outReason, inReason state.Reason) state.Reason {
+	if out {
+		return outReason
+	}
+	return inReason
+}
+
 // calculateNomadScalingDecision is used to figure out the scaling decision for the group based on
 // configured Nomad metric checks.
 func (ae *autoscaleEvaluation) calculateNomadScalingDecision(group string, use *nomadResources, pol *policy.GroupScalingPolicy) *scalingDecision {
@@ -67,13 +131,78 @@ func (ae *autoscaleEvaluation) calculateNomadScalingDecision(group string, use *
 		updateDecisionMap(memInDec, nomadMemoryMetricName, decisions)
 	}
 
+	// If the policy has a disk scale out threshold, run this check.
+	if pol.ScaleOutDiskPercentageThreshold != nil {
+		diskOutDec := performGreaterThanCheck(use.disk, *pol.ScaleOutDiskPercentageThreshold,
+			nomadDiskMetricName, policy.ActionScaleOut)
+		updateDecisionMap(diskOutDec, nomadDiskMetricName, decisions)
+	}
+
+	// If the policy has a disk scale in threshold, run this check.
+	if pol.ScaleInDiskPercentageThreshold != nil {
+		diskInDec := performLessThanCheck(use.disk, *pol.ScaleInDiskPercentageThreshold,
+			nomadDiskMetricName, policy.ActionScaleIn)
+		updateDecisionMap(diskInDec, nomadDiskMetricName, decisions)
+	}
+
+	// If the policy has a GPU scale out threshold, run this check.
+	if pol.ScaleOutGPUPercentageThreshold != nil {
+		gpuOutDec := performGreaterThanCheck(use.gpu, *pol.ScaleOutGPUPercentageThreshold,
+			nomadGPUMetricName, policy.ActionScaleOut)
+		updateDecisionMap(gpuOutDec, nomadGPUMetricName, decisions)
+	}
+
+	// If the policy has a GPU scale in threshold, run this check.
+	if pol.ScaleInGPUPercentageThreshold != nil {
+		gpuInDec := performLessThanCheck(use.gpu, *pol.ScaleInGPUPercentageThreshold,
+			nomadGPUMetricName, policy.ActionScaleIn)
+		updateDecisionMap(gpuInDec, nomadGPUMetricName, decisions)
+	}
+
+	// If the policy has a composite check, calculate the weighted score and run the configured
+	// threshold checks against it.
+	if pol.CompositeCheck != nil {
+		ae.performCompositeChecks(group, use, pol.CompositeCheck, decisions)
+	}
+
 	return ae.choseCorrectDecision(group, decisions)
 }
 
+// performCompositeChecks calculates the weighted composite score of the group resources and
+// compares this against the configured thresholds, updating the decision map as required.
+func (ae *autoscaleEvaluation) performCompositeChecks(group string, use *nomadResources, check *policy.CompositeCheck,
+	decisions map[scale.Direction]*scalingDecision) {
+
+	score := check.Score(map[policy.NomadResource]float64{
+		policy.NomadResourceCPU:    use.cpu,
+		policy.NomadResourceMemory: use.mem,
+		policy.NomadResourceDisk:   use.disk,
+		policy.NomadResourceGPU:    use.gpu,
+	})
+	ae.log.Debug().
+		Str("group", group).
+		Float64("composite-value-percentage", score).
+		Msg("Nomad composite resource score calculation")
+
+	if check.ScaleOutPercentageThreshold != nil {
+		compOutDec := performGreaterThanCheck(score, *check.ScaleOutPercentageThreshold,
+			nomadCompositeMetricName, policy.ActionScaleOut)
+		updateDecisionMap(compOutDec, nomadCompositeMetricName, decisions)
+	}
+
+	if check.ScaleInPercentageThreshold != nil {
+		compInDec := performLessThanCheck(score, *check.ScaleInPercentageThreshold,
+			nomadCompositeMetricName, policy.ActionScaleIn)
+		updateDecisionMap(compInDec, nomadCompositeMetricName, decisions)
+	}
+}
+
 // calculateExternalScalingDecision is used to perform the scaling decision for the group based on
-// configured external metric checks.
-func (ae *autoscaleEvaluation) calculateExternalScalingDecision(group string, pol *policy.GroupScalingPolicy) *scalingDecision {
+// configured external metric checks. The returned bool indicates whether the metric value of at
+// least one check was successfully obtained.
+func (ae *autoscaleEvaluation) calculateExternalScalingDecision(group string, pol *policy.GroupScalingPolicy) (*scalingDecision, bool) {
 	decisions := make(map[scale.Direction]*scalingDecision)
+	var available bool
 
 	// Iterate each external check configured within the job group scaling policy.
 	for name, check := range pol.ExternalChecks {
@@ -83,53 +212,141 @@ func (ae *autoscaleEvaluation) calculateExternalScalingDecision(group string, po
 			continue
 		}
 
-		if checkDecision := ae.evaluateExternalMetric(name, check); checkDecision != nil {
+		checkDecision, ok := ae.evaluateExternalMetric(group, name, check)
+		if ok {
+			available = true
+		}
+		if checkDecision != nil {
 			updateDecisionMap(checkDecision, name, decisions)
 		}
 	}
-	return ae.choseCorrectDecision(group, decisions)
+	return ae.choseCorrectDecision(group, decisions), available
 }
 
 // evaluateExternalMetric is used to trigger the evaluation on a named external check. The function
 // handles getting the metric value, and comparing it against the configured policy check params.
-func (ae *autoscaleEvaluation) evaluateExternalMetric(name string, check *policy.ExternalCheck) *scalingDecision {
+// The returned bool indicates whether the provider was able to return a metric value.
+func (ae *autoscaleEvaluation) evaluateExternalMetric(group, name string, check *policy.ExternalCheck) (*scalingDecision, bool) {
+
+	// An active metric override replaces the provider query entirely, so that the override
+	// can be used even when the provider is unavailable. The override is the value compared
+	// against the check threshold, so is not divided for per allocation checks.
+	if override, ok := ae.metricOverride(group, name); ok {
+		ae.recordExternalCheck(group, name, check, check.Query, &override, nil)
+		ae.snapshotExternalValue(group, name, &override)
+		return compareExternalMetric(override, name, check), true
+	}
 
 	// Check that the provider is available and properly configured for use.
-	if _, ok := ae.metricProvider[check.Provider]; !ok {
+	provider, ok := ae.getMetricProvider(check)
+	if !ok {
 		ae.log.Warn().
 			Str("metric-query", check.Query).
-			Str("metric-provider", check.Provider.String()).
+			Str("metric-provider", check.GetProvider().String()).
+			Str("metric-endpoint", check.Endpoint).
 			Msg("provider not found configured within autoscaler")
-		return nil
+		return nil, false
 	}
 
-	// Perform the query to gather the metric value.
-	value, err := ae.metricProvider[check.Provider].GetValue(check.Query)
+	query, err := ae.renderCheckQuery(group, check)
 	if err != nil {
 		ae.log.Error().
 			Err(err).
-			Str("metric-provider", check.Provider.String()).
+			Str("metric-provider", check.GetProvider().String()).
 			Str("metric-query", check.Query).
+			Msg("failed to render external check query")
+		return nil, false
+	}
+
+	// Perform the query to gather the metric value.
+	ctx, cancel := helper.ContextWithTimeout(ae.context(), ae.queryTimeout)
+	value, err := provider.GetValue(ctx, query)
+	cancel()
+	ae.recordExternalCheck(group, name, check, query, value, err)
+	if err == nil {
+		ae.snapshotExternalValue(group, name, value)
+	}
+	if err != nil {
+		// Providers which are awaiting a further sample in order to calculate the value are
+		// reachable, so should not be treated as unavailable.
+		if errors.Cause(err) == metrics.ErrInsufficientData {
+			ae.log.Debug().
+				Str("metric-provider", check.GetProvider().String()).
+				Str("metric-query", query).
+				Msg(err.Error())
+			return nil, true
+		}
+		ae.log.Error().
+			Err(err).
+			Str("metric-provider", check.GetProvider().String()).
+			Str("metric-query", query).
 			Msg("failed to query external provider for metric value")
-		return nil
+		return nil, false
 	}
 	ae.log.Info().
 		Err(err).
-		Str("metric-provider", check.Provider.String()).
-		Str("metric-query", check.Query).
+		Str("metric-provider", check.GetProvider().String()).
+		Str("metric-query", query).
 		Float64("metric-value", *value).
 		Msg("successfully queried external provider for metric value")
 
+	// If the check describes a per allocation ratio, divide the value by the current group count.
+	if check.PerAllocation {
+		count, err := ae.getGroupCount(group)
+		if err != nil {
+			ae.log.Error().Err(err).Str("group", group).Msg("failed to get job group count")
+			return nil, false
+		}
+		if count < 1 {
+			count = 1
+		}
+		value = helper.Float64ToPointer(*value / float64(count))
+	}
+	return compareExternalMetric(*value, name, check), true
+}
+
+// renderCheckQuery returns the check query with its template variables replaced. The job namespace
+// is only read from Nomad when the query is a template.
+func (ae *autoscaleEvaluation) renderCheckQuery(group string, check *policy.ExternalCheck) (string, error) {
+	if !check.IsQueryTemplate() {
+		return check.Query, nil
+	}
+
+	ns, err := ae.getJobNamespace()
+	if err != nil {
+		return "", errors.Wrap(err, "failed to get job namespace")
+	}
+	return check.RenderQuery(policy.QueryVars{Job: ae.jobID, Group: group, Namespace: ns})
+}
+
+// compareExternalMetric compares the metric value against the check threshold using the check
+// comparison operator.
+func compareExternalMetric(value float64, name string, check *policy.ExternalCheck) *scalingDecision {
 	switch check.ComparisonOperator {
 	case policy.ComparisonGreaterThan:
-		return performGreaterThanCheck(*value, check.ComparisonValue, name, check.Action)
+		return performGreaterThanCheck(value, check.ComparisonValue, name, check.Action)
 	case policy.ComparisonLessThan:
-		return performLessThanCheck(*value, check.ComparisonValue, name, check.Action)
+		return performLessThanCheck(value, check.ComparisonValue, name, check.Action)
 	default:
 		return nil
 	}
 }
 
+// getMetricProvider returns the provider which should run the check query. Checks which reference
+// a named endpoint use that endpoint, otherwise the registered provider of the check is used. The
+// Nomad resources provider reads the metrics gathered for the job under evaluation.
+func (ae *autoscaleEvaluation) getMetricProvider(check *policy.ExternalCheck) (metrics.Provider, bool) {
+	if check.Endpoint != "" {
+		provider, ok := ae.promEndpoints[check.Endpoint]
+		return provider, ok
+	}
+	if check.GetProvider() == policy.ProviderNomadResources {
+		return ae.nomadResourcesProvider(ae.nomadMetricData), true
+	}
+	provider, ok := ae.metricProvider[check.GetProvider()]
+	return provider, ok
+}
+
 // choseCorrectDecision takes a set of decisions made about the scaling direction of the group,
 // and produces a single correct answer. This is mostly in place to ensure safety in situations
 // where two different metric checks produce an out and an in decision.
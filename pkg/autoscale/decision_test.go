@@ -1,11 +1,15 @@
 package autoscale
 
 import (
+	"context"
 	"testing"
 
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
 	"github.com/jrasell/sherpa/pkg/helper"
 	"github.com/jrasell/sherpa/pkg/policy"
 	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -39,6 +43,41 @@ func Test_autoscaleEvaluation_calculateNomadScalingDecision(t *testing.T) {
 			expectedOutput: nil,
 			name:           "all Nomad checks scaling not required",
 		},
+		{
+			inputPolicy: &policy.GroupScalingPolicy{
+				ScaleOutCount: 2,
+				CompositeCheck: &policy.CompositeCheck{
+					Weights: map[policy.NomadResource]float64{
+						policy.NomadResourceCPU:    0.7,
+						policy.NomadResourceMemory: 0.3,
+					},
+					ScaleOutPercentageThreshold: helper.Float64ToPointer(70),
+					ScaleInPercentageThreshold:  helper.Float64ToPointer(20),
+				},
+			},
+			inputResource: &nomadResources{cpu: 90, mem: 40},
+			inputGroup:    "test-group",
+			expectedOutput: &scalingDecision{
+				direction: scale.DirectionOut,
+				count:     2,
+				metrics:   map[string]*scalingMetricDecision{"nomad-composite": {value: 75, threshold: 70}},
+			},
+			name: "composite check scale out required",
+		},
+		{
+			inputPolicy: &policy.GroupScalingPolicy{
+				ScaleInCount:                   3,
+				ScaleInDiskPercentageThreshold: helper.Float64ToPointer(10),
+			},
+			inputResource: &nomadResources{cpu: 50, mem: 50, disk: 5},
+			inputGroup:    "test-group",
+			expectedOutput: &scalingDecision{
+				direction: scale.DirectionIn,
+				count:     3,
+				metrics:   map[string]*scalingMetricDecision{"nomad-disk": {value: 5, threshold: 10}},
+			},
+			name: "disk check scale in required",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -190,6 +229,68 @@ func Test_updateDecisionMap(t *testing.T) {
 	}
 }
 
+func Test_scalingDecision_getReason(t *testing.T) {
+	testCases := []struct {
+		inputDecision  *scalingDecision
+		expectedOutput state.Reason
+		name           string
+	}{
+		{
+			inputDecision: &scalingDecision{
+				direction: scale.DirectionOut,
+				metrics:   map[string]*scalingMetricDecision{"nomad-cpu": {value: 99, threshold: 90}},
+			},
+			expectedOutput: state.ReasonThresholdCPUOut,
+			name:           "nomad cpu scale out",
+		},
+		{
+			inputDecision: &scalingDecision{
+				direction: scale.DirectionIn,
+				metrics:   map[string]*scalingMetricDecision{"nomad-memory": {value: 10, threshold: 30}},
+			},
+			expectedOutput: state.ReasonThresholdMemoryIn,
+			name:           "nomad memory scale in",
+		},
+		{
+			inputDecision: &scalingDecision{
+				direction: scale.DirectionOut,
+				metrics: map[string]*scalingMetricDecision{
+					"prometheus-queue": {value: 99, threshold: 90},
+					"nomad-memory":     {value: 99, threshold: 90},
+				},
+			},
+			expectedOutput: state.ReasonThresholdMemoryOut,
+			name:           "nomad and external metrics scale out",
+		},
+		{
+			inputDecision: &scalingDecision{
+				direction: scale.DirectionIn,
+				metrics:   map[string]*scalingMetricDecision{"prometheus-queue": {value: 1, threshold: 10}},
+			},
+			expectedOutput: state.ReasonExternalCheckIn,
+			name:           "external check scale in",
+		},
+		{
+			inputDecision: &scalingDecision{
+				direction: scale.DirectionOut,
+				metrics:   map[string]*scalingMetricDecision{"nomad-cpu": {value: 99, threshold: 90}},
+				reason:    state.ReasonMetricsFallback,
+			},
+			expectedOutput: state.ReasonMetricsFallback,
+			name:           "explicit reason",
+		},
+		{
+			inputDecision:  &scalingDecision{direction: scale.DirectionOut},
+			expectedOutput: state.ReasonUnknown,
+			name:           "no metrics",
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedOutput, tc.inputDecision.getReason(), tc.name)
+	}
+}
+
 func Test_performGreaterThanCheck(t *testing.T) {
 	testCases := []struct {
 		inputValue     float64
@@ -289,3 +390,151 @@ func Test_performLessThanCheck(t *testing.T) {
 		assert.Equal(t, tc.expectedOutput, actualOutput, tc.name)
 	}
 }
+
+type fakeProvider struct {
+	value float64
+}
+
+func (f *fakeProvider) Name() string { return "fake" }
+
+func (f *fakeProvider) GetValue(_ context.Context, _ string) (*float64, error) {
+	return helper.Float64ToPointer(f.value), nil
+}
+
+func Test_autoscaleEvaluation_getMetricProvider(t *testing.T) {
+	defaultProm := &fakeProvider{value: 1}
+	thanos := &fakeProvider{value: 2}
+
+	ae := autoscaleEvaluation{
+		metricProvider: map[policy.MetricsProvider]metrics.Provider{policy.ProviderPrometheus: defaultProm},
+		promEndpoints:  map[string]metrics.Provider{"thanos": thanos},
+	}
+
+	testCases := []struct {
+		name             string
+		check            *policy.ExternalCheck
+		expectedProvider metrics.Provider
+		expectedOK       bool
+	}{
+		{
+			name:             "default provider",
+			check:            &policy.ExternalCheck{Provider: policy.ProviderPrometheus},
+			expectedProvider: defaultProm,
+			expectedOK:       true,
+		},
+		{
+			name:             "named endpoint",
+			check:            &policy.ExternalCheck{Provider: policy.ProviderPrometheus, Endpoint: "thanos"},
+			expectedProvider: thanos,
+			expectedOK:       true,
+		},
+		{
+			name:       "unknown endpoint",
+			check:      &policy.ExternalCheck{Provider: policy.ProviderPrometheus, Endpoint: "mimir"},
+			expectedOK: false,
+		},
+		{
+			name:       "unconfigured provider",
+			check:      &policy.ExternalCheck{Provider: policy.ProviderGraphite},
+			expectedOK: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			provider, ok := ae.getMetricProvider(tc.check)
+			assert.Equal(t, tc.expectedOK, ok)
+			if tc.expectedOK {
+				assert.Equal(t, tc.expectedProvider, provider)
+			}
+		})
+	}
+}
+
+type failingProvider struct {
+	err error
+}
+
+func (f *failingProvider) Name() string { return "failing" }
+
+func (f *failingProvider) GetValue(_ context.Context, _ string) (*float64, error) { return nil, f.err }
+
+func Test_autoscaleEvaluation_calculateExternalScalingDecision(t *testing.T) {
+	ae := autoscaleEvaluation{
+		metricProvider: map[policy.MetricsProvider]metrics.Provider{
+			policy.ProviderPrometheus: &fakeProvider{value: 90},
+			policy.ProviderGraphite:   &failingProvider{err: errors.New("connection refused")},
+			policy.ProviderTraefik:    &failingProvider{err: metrics.ErrInsufficientData},
+		},
+		policies: map[string]*policy.GroupScalingPolicy{"worker": {ScaleOutCount: 1, ScaleInCount: 1}},
+	}
+
+	testCases := []struct {
+		name              string
+		providers         []policy.MetricsProvider
+		expectedDirection scale.Direction
+		expectedAvailable bool
+	}{
+		{
+			name:              "all checks available",
+			providers:         []policy.MetricsProvider{policy.ProviderPrometheus},
+			expectedDirection: scale.DirectionOut,
+			expectedAvailable: true,
+		},
+		{
+			name:              "some checks failed",
+			providers:         []policy.MetricsProvider{policy.ProviderPrometheus, policy.ProviderGraphite},
+			expectedDirection: scale.DirectionOut,
+			expectedAvailable: true,
+		},
+		{
+			name:              "all checks failed",
+			providers:         []policy.MetricsProvider{policy.ProviderGraphite, policy.ProviderNATS},
+			expectedAvailable: false,
+		},
+		{
+			name:              "provider awaiting samples",
+			providers:         []policy.MetricsProvider{policy.ProviderTraefik},
+			expectedAvailable: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pol := &policy.GroupScalingPolicy{ExternalChecks: make(map[string]*policy.ExternalCheck)}
+			for _, provider := range tc.providers {
+				pol.ExternalChecks[provider.String()] = &policy.ExternalCheck{
+					Enabled:            true,
+					Provider:           provider,
+					ComparisonOperator: policy.ComparisonGreaterThan,
+					ComparisonValue:    80,
+					Action:             policy.ActionScaleOut,
+				}
+			}
+
+			dec, available := ae.calculateExternalScalingDecision("worker", pol)
+			assert.Equal(t, tc.expectedAvailable, available)
+			if tc.expectedDirection == "" {
+				assert.Nil(t, dec)
+			} else {
+				assert.Equal(t, tc.expectedDirection, dec.direction)
+			}
+		})
+	}
+}
+
+func Test_autoscaleEvaluation_renderCheckQuery(t *testing.T) {
+	ae := &autoscaleEvaluation{jobID: "example", groupCounts: map[string]int{"cache": 2}, namespace: "platform"}
+
+	query, err := ae.renderCheckQuery("cache", &policy.ExternalCheck{
+		Query: `queue_depth{namespace="{{.Namespace}}",job="{{.Job}}",group="{{.Group}}"}`,
+	})
+	assert.Nil(t, err)
+	assert.Equal(t, `queue_depth{namespace="platform",job="example",group="cache"}`, query)
+
+	// Queries which are not templates do not require the job to be read.
+	ae = &autoscaleEvaluation{jobID: "example"}
+	query, err = ae.renderCheckQuery("cache", &policy.ExternalCheck{Query: "queue_depth"})
+	assert.Nil(t, err)
+	assert.Equal(t, "queue_depth", query)
+}
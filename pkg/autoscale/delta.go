@@ -0,0 +1,67 @@
+package autoscale
+
+import (
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/state"
+)
+
+// enforceMinScaleDelta removes the decisions which would change the count of the job group by less
+// than the minimum scale delta of its policy. Decisions which return a group to within its policy
+// bounds are always kept.
+func (ae *autoscaleEvaluation) enforceMinScaleDelta(dec map[string]*scalingDecision) {
+	for group, decision := range dec {
+		pol := ae.policies[group]
+		if pol == nil || decision.reason == state.ReasonBoundsEnforcement {
+			continue
+		}
+		if pol.MinScaleDelta == 0 && pol.MinScaleDeltaPercentage == 0 {
+			continue
+		}
+
+		// The current count is only required by the percentage check, so avoid the potential
+		// Nomad API call if it is not configured.
+		var count int
+
+		if pol.MinScaleDeltaPercentage > 0 {
+			c, err := ae.getGroupCount(group)
+			if err != nil {
+				ae.log.Error().Err(err).Str("group", group).
+					Msg("failed to get job group count, skipping minimum scale delta check")
+				continue
+			}
+			count = c
+		}
+
+		if !belowMinScaleDelta(count, decision.count, pol) {
+			continue
+		}
+
+		ae.log.Info().
+			Str("group", group).
+			Str("direction", decision.direction.String()).
+			Int("count", count).
+			Int("change", decision.count).
+			Int("min-scale-delta", pol.MinScaleDelta).
+			Float64("min-scale-delta-percentage", pol.MinScaleDeltaPercentage).
+			Msg("scaling change is below the minimum scale delta, skipping job group scaling")
+
+		sendMetrics.IncrCounterWithLabels([]string{"autoscale", "min_scale_delta_skipped"}, 1, []sendMetrics.Label{
+			{Name: "job", Value: ae.jobID},
+			{Name: "group", Value: group},
+		})
+		delete(dec, group)
+	}
+}
+
+// belowMinScaleDelta returns whether changing the count of the group by change is smaller than the
+// minimum scale delta of the policy. Any change from a count of zero satisfies the percentage.
+func belowMinScaleDelta(count, change int, pol *policy.GroupScalingPolicy) bool {
+	if pol.MinScaleDelta > 0 && change < pol.MinScaleDelta {
+		return true
+	}
+	if pol.MinScaleDeltaPercentage > 0 && count > 0 {
+		return float64(change)*100/float64(count) < pol.MinScaleDeltaPercentage
+	}
+	return false
+}
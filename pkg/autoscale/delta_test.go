@@ -0,0 +1,99 @@
+package autoscale
+
+import (
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_belowMinScaleDelta(t *testing.T) {
+	testCases := []struct {
+		count          int
+		change         int
+		policy         *policy.GroupScalingPolicy
+		expectedOutput bool
+		name           string
+	}{
+		{
+			count:          10,
+			change:         1,
+			policy:         &policy.GroupScalingPolicy{},
+			expectedOutput: false,
+			name:           "no minimum scale delta",
+		},
+		{
+			count:          10,
+			change:         1,
+			policy:         &policy.GroupScalingPolicy{MinScaleDelta: 2},
+			expectedOutput: true,
+			name:           "change below absolute delta",
+		},
+		{
+			count:          10,
+			change:         2,
+			policy:         &policy.GroupScalingPolicy{MinScaleDelta: 2},
+			expectedOutput: false,
+			name:           "change equal to absolute delta",
+		},
+		{
+			count:          100,
+			change:         4,
+			policy:         &policy.GroupScalingPolicy{MinScaleDeltaPercentage: 5},
+			expectedOutput: true,
+			name:           "change below percentage delta",
+		},
+		{
+			count:          100,
+			change:         5,
+			policy:         &policy.GroupScalingPolicy{MinScaleDeltaPercentage: 5},
+			expectedOutput: false,
+			name:           "change equal to percentage delta",
+		},
+		{
+			count:          100,
+			change:         5,
+			policy:         &policy.GroupScalingPolicy{MinScaleDelta: 1, MinScaleDeltaPercentage: 10},
+			expectedOutput: true,
+			name:           "change passes absolute but not percentage delta",
+		},
+		{
+			count:          0,
+			change:         1,
+			policy:         &policy.GroupScalingPolicy{MinScaleDeltaPercentage: 50},
+			expectedOutput: false,
+			name:           "change from zero count",
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedOutput, belowMinScaleDelta(tc.count, tc.change, tc.policy), tc.name)
+	}
+}
+
+func TestAutoscaleEvaluation_enforceMinScaleDelta(t *testing.T) {
+	ae := &autoscaleEvaluation{
+		jobID: "example",
+		policies: map[string]*policy.GroupScalingPolicy{
+			"cache":  {MinScaleDelta: 2},
+			"proxy":  {MinScaleDelta: 2},
+			"worker": {MinScaleDeltaPercentage: 20},
+			"web":    {},
+		},
+		groupCounts: map[string]int{"worker": 20},
+	}
+
+	dec := map[string]*scalingDecision{
+		"cache":  {direction: scale.DirectionOut, count: 1},
+		"proxy":  {direction: scale.DirectionIn, count: 1, reason: state.ReasonBoundsEnforcement},
+		"worker": {direction: scale.DirectionOut, count: 2},
+		"web":    {direction: scale.DirectionOut, count: 1},
+	}
+	ae.enforceMinScaleDelta(dec)
+
+	assert.Len(t, dec, 2)
+	assert.Contains(t, dec, "proxy")
+	assert.Contains(t, dec, "web")
+}
@@ -0,0 +1,116 @@
+package autoscale
+
+import (
+	sendMetrics "github.com/armon/go-metrics"
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
+)
+
+// enforceDrainAwareScaleIn reduces the scale in decisions of groups which have allocations on
+// draining or scheduling ineligible nodes. These allocations are about to be lost to the drain, so
+// the effective capacity of the group is already lower than its count; scaling in as well would
+// remove more capacity than the decision intended. Decisions which return a group to within its
+// policy bounds are not adjusted.
+func (ae *autoscaleEvaluation) enforceDrainAwareScaleIn(dec map[string]*scalingDecision) {
+	if !ae.drainAwareScaleIn || !hasScaleInDecision(dec) {
+		return
+	}
+
+	draining, err := ae.getDrainingAllocCounts()
+	if err != nil {
+		ae.log.Error().Err(err).Msg("failed to determine allocations on draining nodes, skipping drain aware scale in")
+		return
+	}
+
+	for group, decision := range dec {
+		if decision.direction != scale.DirectionIn || decision.reason == state.ReasonBoundsEnforcement {
+			continue
+		}
+
+		count := draining[group]
+		if count == 0 {
+			continue
+		}
+
+		sendMetrics.IncrCounterWithLabels([]string{"autoscale", "drain_scale_in_adjusted"}, 1, []sendMetrics.Label{
+			{Name: "job", Value: ae.jobID},
+			{Name: "group", Value: group},
+		})
+
+		if decision.count <= count {
+			ae.log.Info().
+				Str("group", group).
+				Int("draining-allocations", count).
+				Int("count", decision.count).
+				Msg("job group is losing allocations to node drain, skipping scale in")
+			delete(dec, group)
+			continue
+		}
+
+		ae.log.Info().
+			Str("group", group).
+			Int("draining-allocations", count).
+			Int("original-count", decision.count).
+			Int("adjusted-count", decision.count-count).
+			Msg("reducing scale in count to account for allocations on draining nodes")
+		decision.count -= count
+	}
+}
+
+// getDrainingAllocCounts returns the number of running or pending allocations of each group of
+// the job which are placed on nodes that are draining or ineligible for scheduling.
+func (ae *autoscaleEvaluation) getDrainingAllocCounts() (map[string]int, error) {
+	var allocs []*nomad.AllocationListStub
+
+	err := ae.callNomad(func() (err error) {
+		allocs, _, err = ae.nomad.Jobs().Allocations(ae.jobID, false, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var nodes []*nomad.NodeListStub
+
+	err = ae.callNomad(func() (err error) {
+		nodes, _, err = ae.nomad.Nodes().List(nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return countDrainingAllocs(allocs, nodes), nil
+}
+
+// countDrainingAllocs returns the number of running or pending allocations of each group which
+// are placed on nodes that are draining or ineligible for scheduling.
+func countDrainingAllocs(allocs []*nomad.AllocationListStub, nodes []*nomad.NodeListStub) map[string]int {
+	draining := make(map[string]bool)
+	for i := range nodes {
+		if nodes[i].Drain || nodes[i].SchedulingEligibility == nomad.NodeSchedulingIneligible {
+			draining[nodes[i].ID] = true
+		}
+	}
+
+	out := make(map[string]int)
+
+	for i := range allocs {
+		if allocs[i].ClientStatus != nomad.AllocClientStatusRunning && allocs[i].ClientStatus != nomad.AllocClientStatusPending {
+			continue
+		}
+		if draining[allocs[i].NodeID] {
+			out[allocs[i].TaskGroup]++
+		}
+	}
+	return out
+}
+
+func hasScaleInDecision(dec map[string]*scalingDecision) bool {
+	for _, decision := range dec {
+		if decision.direction == scale.DirectionIn {
+			return true
+		}
+	}
+	return false
+}
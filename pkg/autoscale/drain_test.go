@@ -0,0 +1,36 @@
+package autoscale
+
+import (
+	"testing"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_countDrainingAllocs(t *testing.T) {
+	nodes := []*nomad.NodeListStub{
+		{ID: "node-1", SchedulingEligibility: nomad.NodeSchedulingEligible},
+		{ID: "node-2", SchedulingEligibility: nomad.NodeSchedulingIneligible, Drain: true},
+		{ID: "node-3", SchedulingEligibility: nomad.NodeSchedulingIneligible},
+	}
+	allocs := []*nomad.AllocationListStub{
+		{TaskGroup: "worker", NodeID: "node-1", ClientStatus: nomad.AllocClientStatusRunning},
+		{TaskGroup: "worker", NodeID: "node-2", ClientStatus: nomad.AllocClientStatusRunning},
+		{TaskGroup: "worker", NodeID: "node-3", ClientStatus: nomad.AllocClientStatusPending},
+		{TaskGroup: "worker", NodeID: "node-3", ClientStatus: nomad.AllocClientStatusComplete},
+		{TaskGroup: "cache", NodeID: "node-1", ClientStatus: nomad.AllocClientStatusRunning},
+		{TaskGroup: "cache", NodeID: "node-2", ClientStatus: nomad.AllocClientStatusFailed},
+	}
+
+	assert.Equal(t, map[string]int{"worker": 2}, countDrainingAllocs(allocs, nodes))
+}
+
+func Test_hasScaleInDecision(t *testing.T) {
+	assert.False(t, hasScaleInDecision(nil))
+	assert.False(t, hasScaleInDecision(map[string]*scalingDecision{"worker": {direction: scale.DirectionOut}}))
+	assert.True(t, hasScaleInDecision(map[string]*scalingDecision{
+		"worker": {direction: scale.DirectionOut},
+		"cache":  {direction: scale.DirectionIn},
+	}))
+}
@@ -0,0 +1,136 @@
+package evallog
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+)
+
+// Record describes a single autoscaling evaluation of a job, including the inputs used by each job
+// group and the resulting decisions. Each record is written as a single JSON line.
+type Record struct {
+	ID    string    `json:"ID"`
+	JobID string    `json:"JobID"`
+	Time  time.Time `json:"Time"`
+
+	// NomadMetricsError is the error returned when gathering Nomad resource metrics for the job.
+	NomadMetricsError string `json:"NomadMetricsError,omitempty"`
+
+	// Groups contains the evaluation detail of each job group, keyed by the group name.
+	Groups map[string]*GroupRecord `json:"Groups"`
+
+	// ScalingID and NomadEvaluationID are populated when the evaluation resulted in a successful
+	// scaling request. ScalingError is populated when the scaling request failed.
+	ScalingID         string `json:"ScalingID,omitempty"`
+	NomadEvaluationID string `json:"NomadEvaluationID,omitempty"`
+	ScalingError      string `json:"ScalingError,omitempty"`
+
+	// DryRun indicates the autoscaler was running in dry-run mode, so any scaling decisions were
+	// not triggered.
+	DryRun bool `json:"DryRun,omitempty"`
+}
+
+// GroupRecord describes the evaluation of a single job group.
+type GroupRecord struct {
+	Policy          *policy.GroupScalingPolicy `json:"Policy"`
+	NomadResources  *NomadResources            `json:"NomadResources,omitempty"`
+	ExternalChecks  map[string]*CheckRecord    `json:"ExternalChecks,omitempty"`
+	MetricsFallback bool                       `json:"MetricsFallback"`
+	Decision        *Decision                  `json:"Decision,omitempty"`
+	Shadow          *ShadowComparison          `json:"Shadow,omitempty"`
+}
+
+// ShadowComparison compares the scaling direction decided for the job group with the latest
+// scaling action of a co-deployed Nomad Autoscaler, when running in shadow mode.
+type ShadowComparison struct {
+	NomadAutoscalerDirection string `json:"NomadAutoscalerDirection"`
+	Diverged                 bool   `json:"Diverged"`
+}
+
+// NomadResources are the resource utilisation percentages of the job group obtained from Nomad.
+type NomadResources struct {
+	CPU    float64 `json:"CPU"`
+	Memory float64 `json:"Memory"`
+	Disk   float64 `json:"Disk"`
+	GPU    float64 `json:"GPU"`
+
+	// StatsCoverage is the percentage of the group allocations whose resource usage was gathered.
+	// A value below 100 indicates the utilisation was calculated from a subset of allocations.
+	StatsCoverage float64 `json:"StatsCoverage,omitempty"`
+}
+
+// CheckRecord describes the result of running an external check query.
+type CheckRecord struct {
+	Provider policy.MetricsProvider `json:"Provider"`
+	Endpoint string                 `json:"Endpoint,omitempty"`
+	Query    string                 `json:"Query"`
+	Value    *float64               `json:"Value"`
+	Error    string                 `json:"Error,omitempty"`
+}
+
+// Decision is the final scaling decision made for the job group.
+type Decision struct {
+	Direction string                     `json:"Direction"`
+	Count     int                        `json:"Count"`
+	Reason    string                     `json:"Reason"`
+	Metrics   map[string]*DecisionMetric `json:"Metrics"`
+}
+
+// DecisionMetric is the metric value and threshold which resulted in the scaling decision.
+type DecisionMetric struct {
+	Value     float64 `json:"Value"`
+	Threshold float64 `json:"Threshold"`
+}
+
+// NewRecord creates a record for the evaluation, identified by the passed ID, of the job triggered
+// at the passed time.
+func NewRecord(id, jobID string, t time.Time) *Record {
+	return &Record{ID: id, JobID: jobID, Time: t, Groups: make(map[string]*GroupRecord)}
+}
+
+// Group returns the record for the named job group, creating it if it does not exist.
+func (r *Record) Group(name string) *GroupRecord {
+	if _, ok := r.Groups[name]; !ok {
+		r.Groups[name] = &GroupRecord{}
+	}
+	return r.Groups[name]
+}
+
+// Writer writes evaluation records as JSON lines. It is safe for concurrent use by the autoscaler
+// workers.
+type Writer struct {
+	mu sync.Mutex
+	w  io.Writer
+}
+
+// NewWriter returns a Writer which writes records to w.
+func NewWriter(w io.Writer) *Writer { return &Writer{w: w} }
+
+// NewFileWriter returns a Writer which appends records to the file at path, creating it if
+// required.
+func NewFileWriter(path string) (*Writer, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open evaluation log file")
+	}
+	return NewWriter(f), nil
+}
+
+// Write marshals the record and writes it as a single line.
+func (w *Writer) Write(r *Record) error {
+	out, err := json.Marshal(r)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal evaluation record")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	_, err = w.w.Write(append(out, '\n'))
+	return err
+}
@@ -0,0 +1,47 @@
+package evallog
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriter_Write(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	first := NewRecord("c8e0b1a4-0d4b-4a4f-9b83-2b4f3c0fb7a1", "example", time.Unix(1580000000, 0).UTC())
+	first.Group("cache").ExternalChecks = map[string]*CheckRecord{
+		"memory": {Provider: policy.ProviderPrometheus, Query: "sum(redis_memory)", Value: helper.Float64ToPointer(42)},
+	}
+	first.Group("cache").Decision = &Decision{
+		Direction: "out",
+		Count:     1,
+		Metrics:   map[string]*DecisionMetric{"memory": {Value: 42, Threshold: 40}},
+	}
+	assert.Nil(t, w.Write(first))
+	assert.Nil(t, w.Write(NewRecord("5d2f6f0e-8a3e-4b8c-a0d6-3f0b7e9c1d22", "worker", time.Unix(1580000060, 0).UTC())))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	assert.Len(t, lines, 2)
+
+	var actual Record
+	assert.Nil(t, json.Unmarshal([]byte(lines[0]), &actual))
+	assert.Equal(t, first, &actual)
+}
+
+func TestRecord_Group(t *testing.T) {
+	r := NewRecord("c8e0b1a4-0d4b-4a4f-9b83-2b4f3c0fb7a1", "example", time.Now())
+
+	group := r.Group("cache")
+	group.MetricsFallback = true
+
+	assert.Equal(t, group, r.Group("cache"))
+	assert.Len(t, r.Groups, 1)
+}
@@ -0,0 +1,93 @@
+package autoscale
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotRunning is returned when an evaluation is requested while the autoscaler is not
+	// running, such as on a server which is not the cluster leader.
+	ErrNotRunning = errors.New("autoscaler is not running")
+
+	// ErrPolicyNotFound is returned when an evaluation is requested for a job or group which does
+	// not have a scaling policy.
+	ErrPolicyNotFound = errors.New("scaling policy not found")
+
+	// ErrNoEligibleGroups is returned when an evaluation is requested, but all the requested job
+	// groups are disabled, in deployment or in scaling cooldown.
+	ErrNoEligibleGroups = errors.New("no job groups are eligible for evaluation")
+
+	// ErrJobFiltered is returned when an evaluation is requested for a job which is excluded by
+	// the job filter of this Sherpa server.
+	ErrJobFiltered = errors.New("job is excluded by the job filter")
+)
+
+// EvaluateJob triggers an immediate evaluation of the job outside of the autoscaling interval. If
+// the group is not empty, only that group is evaluated. The evaluation runs asynchronously, and
+// its ID is returned so that it can be correlated with the server logs and evaluation log.
+func (a *AutoScale) EvaluateJob(job, group string) (uuid.UUID, error) {
+	if !a.IsRunning() {
+		return uuid.Nil, ErrNotRunning
+	}
+
+	if !a.jobFilter.MatchID(job) {
+		return uuid.Nil, ErrJobFiltered
+	}
+
+	ctx, cancel := helper.ContextWithTimeout(context.Background(), time.Duration(a.cfg.NomadTimeout)*time.Second)
+	defer cancel()
+
+	policies, err := a.policyBackend.GetJobPolicy(ctx, job)
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, "failed to get job scaling policy")
+	}
+
+	policies, err = filterGroupPolicy(policies, group)
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	t := time.Now().UTC()
+
+	eligible := a.eligibleGroups(job, policies, t)
+	if len(eligible) == 0 {
+		return uuid.Nil, ErrNoEligibleGroups
+	}
+
+	evalID, err := uuid.NewV4()
+	if err != nil {
+		return uuid.Nil, errors.Wrap(err, "failed to generate evaluation ID")
+	}
+
+	a.logger.Info().
+		Str("job", job).
+		Str("evaluation-id", evalID.String()).
+		Msg("triggering out-of-band autoscaling job evaluation")
+
+	go a.runEvaluation(evalID, &workerPayload{jobID: job, policy: eligible, time: t})
+
+	return evalID, nil
+}
+
+// filterGroupPolicy returns only the policy of the named group from the job policies. If the
+// group is empty, all the job policies are returned.
+func filterGroupPolicy(policies map[string]*policy.GroupScalingPolicy, group string) (map[string]*policy.GroupScalingPolicy, error) {
+	if len(policies) == 0 {
+		return nil, ErrPolicyNotFound
+	}
+	if group == "" {
+		return policies, nil
+	}
+
+	pol, ok := policies[group]
+	if !ok || pol == nil {
+		return nil, ErrPolicyNotFound
+	}
+	return map[string]*policy.GroupScalingPolicy{group: pol}, nil
+}
@@ -0,0 +1,58 @@
+package autoscale
+
+import (
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_filterGroupPolicy(t *testing.T) {
+	cache := &policy.GroupScalingPolicy{Enabled: true, MaxCount: 5}
+	proxy := &policy.GroupScalingPolicy{Enabled: true, MaxCount: 3}
+	policies := map[string]*policy.GroupScalingPolicy{"cache": cache, "proxy": proxy}
+
+	testCases := []struct {
+		policies       map[string]*policy.GroupScalingPolicy
+		group          string
+		expectedOutput map[string]*policy.GroupScalingPolicy
+		expectedError  error
+		name           string
+	}{
+		{
+			policies:       policies,
+			group:          "",
+			expectedOutput: policies,
+			name:           "all job groups",
+		},
+		{
+			policies:       policies,
+			group:          "proxy",
+			expectedOutput: map[string]*policy.GroupScalingPolicy{"proxy": proxy},
+			name:           "single job group",
+		},
+		{
+			policies:      policies,
+			group:         "web",
+			expectedError: ErrPolicyNotFound,
+			name:          "group without policy",
+		},
+		{
+			policies:      nil,
+			group:         "",
+			expectedError: ErrPolicyNotFound,
+			name:          "job without policy",
+		},
+	}
+
+	for _, tc := range testCases {
+		actualOutput, err := filterGroupPolicy(tc.policies, tc.group)
+		assert.Equal(t, tc.expectedError, err, tc.name)
+		assert.Equal(t, tc.expectedOutput, actualOutput, tc.name)
+	}
+}
+
+func TestAutoScale_EvaluateJob_notRunning(t *testing.T) {
+	_, err := (&AutoScale{}).EvaluateJob("example", "")
+	assert.Equal(t, ErrNotRunning, err)
+}
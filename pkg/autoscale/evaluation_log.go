@@ -0,0 +1,121 @@
+package autoscale
+
+import (
+	"github.com/jrasell/sherpa/pkg/autoscale/evallog"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+)
+
+// The record functions populate the evaluation record when the evaluation log is enabled, and are
+// no-ops otherwise. They are safe to call from concurrent group evaluations.
+
+func (ae *autoscaleEvaluation) recordPolicies() {
+	if ae.record == nil {
+		return
+	}
+	ae.lock.Lock()
+	defer ae.lock.Unlock()
+
+	for group, pol := range ae.policies {
+		ae.record.Group(group).Policy = pol
+	}
+}
+
+func (ae *autoscaleEvaluation) recordNomadMetricsError(err error) {
+	if ae.record == nil || err == nil {
+		return
+	}
+	ae.lock.Lock()
+	defer ae.lock.Unlock()
+
+	ae.record.NomadMetricsError = err.Error()
+}
+
+func (ae *autoscaleEvaluation) recordNomadResources(group string, use *nomadResources, coverage float64) {
+	if ae.record == nil {
+		return
+	}
+	ae.lock.Lock()
+	defer ae.lock.Unlock()
+
+	ae.record.Group(group).NomadResources = &evallog.NomadResources{
+		CPU: use.cpu, Memory: use.mem, Disk: use.disk, GPU: use.gpu, StatsCoverage: coverage,
+	}
+}
+
+func (ae *autoscaleEvaluation) recordExternalCheck(group, name string, check *policy.ExternalCheck, query string, value *float64, err error) {
+	if ae.record == nil {
+		return
+	}
+
+	rec := &evallog.CheckRecord{
+		Provider: check.GetProvider(),
+		Endpoint: check.Endpoint,
+		Query:    query,
+		Value:    value,
+	}
+	if err != nil {
+		rec.Error = err.Error()
+	}
+
+	ae.lock.Lock()
+	defer ae.lock.Unlock()
+
+	g := ae.record.Group(group)
+	if g.ExternalChecks == nil {
+		g.ExternalChecks = make(map[string]*evallog.CheckRecord)
+	}
+	g.ExternalChecks[name] = rec
+}
+
+func (ae *autoscaleEvaluation) recordMetricsFallback(group string) {
+	if ae.record == nil {
+		return
+	}
+	ae.lock.Lock()
+	defer ae.lock.Unlock()
+
+	ae.record.Group(group).MetricsFallback = true
+}
+
+func (ae *autoscaleEvaluation) recordDecisions(dec map[string]*scalingDecision) {
+	if ae.record == nil {
+		return
+	}
+	ae.lock.Lock()
+	defer ae.lock.Unlock()
+
+	for group, d := range dec {
+		if d == nil {
+			continue
+		}
+
+		metrics := make(map[string]*evallog.DecisionMetric)
+		for name, m := range d.metrics {
+			metrics[name] = &evallog.DecisionMetric{Value: m.value, Threshold: m.threshold}
+		}
+		ae.record.Group(group).Decision = &evallog.Decision{
+			Direction: d.direction.String(), Count: d.count, Reason: d.getReason().String(), Metrics: metrics,
+		}
+	}
+}
+
+// writeEvaluationRecord completes the evaluation record using the result of the scaling request,
+// if one was made, and writes it to the evaluation log.
+func (ae *autoscaleEvaluation) writeEvaluationRecord(resp *scale.ScalingResponse, err error) {
+	if ae.record == nil {
+		return
+	}
+
+	if resp != nil {
+		ae.record.ScalingID = resp.ID.String()
+		ae.record.NomadEvaluationID = resp.EvaluationID
+	}
+	if err != nil {
+		ae.record.ScalingError = err.Error()
+	}
+
+	if err := ae.evalLog.Write(ae.record); err != nil {
+		ae.log.Error().Err(err).Msg("failed to write evaluation record")
+	}
+}
@@ -0,0 +1,87 @@
+package autoscale
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/autoscale/evallog"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_autoscaleEvaluation_writeEvaluationRecord(t *testing.T) {
+	var buf bytes.Buffer
+
+	pol := &policy.GroupScalingPolicy{Enabled: true, MinCount: 1, MaxCount: 10, ScaleOutCount: 2}
+	check := &policy.ExternalCheck{Provider: policy.ProviderPrometheus, Query: "sum(queue_depth)"}
+
+	ae := &autoscaleEvaluation{
+		jobID:    "example",
+		policies: map[string]*policy.GroupScalingPolicy{"worker": pol},
+		evalLog:  evallog.NewWriter(&buf),
+	}
+	ae.record = evallog.NewRecord(ae.id.String(), ae.jobID, time.Unix(1580000000, 0))
+	ae.recordPolicies()
+	ae.recordExternalCheck("worker", "queue", check, check.Query, helper.Float64ToPointer(120), nil)
+	ae.recordNomadResources("worker", &nomadResources{cpu: 75, mem: 40}, 50)
+	ae.recordDecisions(map[string]*scalingDecision{"worker": {
+		direction: scale.DirectionOut,
+		count:     2,
+		metrics:   map[string]*scalingMetricDecision{"queue": {value: 120, threshold: 100}},
+	}})
+	ae.writeEvaluationRecord(nil, errors.New("job group not found on Nomad cluster"))
+
+	var actual evallog.Record
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &actual))
+	assert.Equal(t, "example", actual.JobID)
+	assert.Equal(t, "job group not found on Nomad cluster", actual.ScalingError)
+	assert.Equal(t, pol, actual.Groups["worker"].Policy)
+	assert.Equal(t, float64(120), *actual.Groups["worker"].ExternalChecks["queue"].Value)
+	assert.Equal(t, float64(75), actual.Groups["worker"].NomadResources.CPU)
+	assert.Equal(t, float64(50), actual.Groups["worker"].NomadResources.StatsCoverage)
+	assert.Equal(t, &evallog.Decision{
+		Direction: "out",
+		Count:     2,
+		Reason:    "external-check-out",
+		Metrics:   map[string]*evallog.DecisionMetric{"queue": {Value: 120, Threshold: 100}},
+	}, actual.Groups["worker"].Decision)
+}
+
+func Test_autoscaleEvaluation_recordDisabled(t *testing.T) {
+	ae := &autoscaleEvaluation{}
+
+	// Without an evaluation log the record functions should be safe no-ops.
+	ae.recordPolicies()
+	ae.recordMetricsFallback("worker")
+	ae.recordNomadMetricsError(errors.New("no allocations found"))
+	ae.writeEvaluationRecord(nil, nil)
+	assert.Nil(t, ae.record)
+}
+
+func Test_autoscaleEvaluation_dryRun(t *testing.T) {
+	var buf bytes.Buffer
+
+	pol := &policy.GroupScalingPolicy{Enabled: true, MinCount: 1, MaxCount: 10, ScaleOutCount: 2}
+
+	// The scaler is nil, so the evaluation would panic if scaling was triggered.
+	ae := &autoscaleEvaluation{
+		jobID:    "example",
+		dryRun:   true,
+		policies: map[string]*policy.GroupScalingPolicy{"worker": pol},
+		evalLog:  evallog.NewWriter(&buf),
+	}
+	ae.record = evallog.NewRecord(ae.id.String(), ae.jobID, time.Unix(1580000000, 0))
+	ae.record.DryRun = ae.dryRun
+	ae.evaluateDecisions(nil, map[string]*scalingDecision{"worker": {direction: scale.DirectionOut, count: 2}})
+
+	var actual evallog.Record
+	assert.Nil(t, json.Unmarshal(buf.Bytes(), &actual))
+	assert.True(t, actual.DryRun)
+	assert.Equal(t, "", actual.ScalingID)
+	assert.Equal(t, "out", actual.Groups["worker"].Decision.Direction)
+}
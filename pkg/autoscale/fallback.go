@@ -0,0 +1,111 @@
+package autoscale
+
+import (
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
+)
+
+// fallbackSafeCountMetricName is the metric name used within scaling meta when a group is scaled
+// to its safe count.
+const fallbackSafeCountMetricName = "fallback-safe-count"
+
+// evaluateMetricsFallback is called when all the metric sources configured for the group failed
+// during the evaluation. It performs the fallback action configured within the group policy.
+func (ae *autoscaleEvaluation) evaluateMetricsFallback(group string, pol *policy.GroupScalingPolicy) *scalingDecision {
+	sendMetrics.IncrCounter([]string{"autoscale", ae.jobID, group, "metrics_unavailable"}, 1)
+	ae.recordMetricsFallback(group)
+
+	if pol.MetricsFallback == nil {
+		ae.log.Warn().Str("group", group).Msg("all metric sources failed, skipping job group scaling")
+		return nil
+	}
+
+	ae.log.Warn().
+		Str("group", group).
+		Str("fallback-action", pol.MetricsFallback.Action.String()).
+		Msg("all metric sources failed, performing metrics fallback action")
+
+	var dec *scalingDecision
+
+	switch pol.MetricsFallback.Action {
+	case policy.FallbackSafeCount:
+		dec = ae.calculateSafeCountDecision(group, pol)
+	case policy.FallbackNomadChecks:
+		dec = ae.calculateFallbackNomadDecision(group, pol)
+	}
+
+	if dec != nil {
+		dec.reason = state.ReasonMetricsFallback
+	}
+	return dec
+}
+
+// calculateSafeCountDecision produces the decision required to move the group from its current
+// count to the safe count. The safe count is kept within the group minimum and maximum so that the
+// scaling request passes the scaler threshold checks.
+func (ae *autoscaleEvaluation) calculateSafeCountDecision(group string, pol *policy.GroupScalingPolicy) *scalingDecision {
+	count, err := ae.getGroupCount(group)
+	if err != nil {
+		ae.log.Error().Err(err).Str("group", group).Msg("failed to get job group count")
+		return nil
+	}
+
+	safe := pol.MetricsFallback.SafeCount
+	if safe < pol.MinCount {
+		safe = pol.MinCount
+	}
+	if safe > pol.MaxCount {
+		safe = pol.MaxCount
+	}
+
+	dec := &scalingDecision{metrics: map[string]*scalingMetricDecision{
+		fallbackSafeCountMetricName: {value: float64(count), threshold: float64(safe)},
+	}}
+
+	switch {
+	case safe > count:
+		dec.direction, dec.count = scale.DirectionOut, safe-count
+	case safe < count:
+		dec.direction, dec.count = scale.DirectionIn, count-safe
+	default:
+		return nil
+	}
+	return dec
+}
+
+// calculateFallbackNomadDecision performs CPU and memory checks on the group using Nomad resource
+// metrics and the fallback thresholds. The Nomad metrics are gathered if this has not already
+// been attempted during the evaluation.
+func (ae *autoscaleEvaluation) calculateFallbackNomadDecision(group string, pol *policy.GroupScalingPolicy) *scalingDecision {
+	ae.lock.Lock()
+	var gathered bool
+	if ae.nomadMetricData == nil && ae.nomadMetricErr == nil {
+		ae.nomadMetricData, ae.nomadMetricErr = ae.gatherNomadMetrics()
+		gathered = true
+	}
+	ae.lock.Unlock()
+
+	if gathered {
+		ae.recordNomadMetricsError(ae.nomadMetricErr)
+	}
+	if ae.nomadMetricData == nil {
+		ae.log.Error().Err(ae.nomadMetricErr).Str("group", group).Msg("failed to collect Nomad metrics for metrics fallback")
+		return nil
+	}
+	if !ae.hasNomadMetrics(group) {
+		return nil
+	}
+
+	out, in := pol.MetricsFallback.NomadThresholds()
+
+	fallbackPol := &policy.GroupScalingPolicy{
+		ResourceTasks:                     pol.ResourceTasks,
+		ScaleOutCPUPercentageThreshold:    &out,
+		ScaleOutMemoryPercentageThreshold: &out,
+		ScaleInCPUPercentageThreshold:     &in,
+		ScaleInMemoryPercentageThreshold:  &in,
+	}
+	return ae.evaluateNomadJobMetrics(group, fallbackPol, ae.nomadMetricData)
+}
@@ -0,0 +1,86 @@
+package autoscale
+
+import (
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_autoscaleEvaluation_calculateSafeCountDecision(t *testing.T) {
+	testCases := []struct {
+		name             string
+		currentCount     int
+		safeCount        int
+		expectedDecision *scalingDecision
+	}{
+		{
+			name:         "scale out to safe count",
+			currentCount: 2,
+			safeCount:    5,
+			expectedDecision: &scalingDecision{
+				direction: scale.DirectionOut,
+				count:     3,
+				metrics:   map[string]*scalingMetricDecision{fallbackSafeCountMetricName: {value: 2, threshold: 5}},
+			},
+		},
+		{
+			name:         "scale in to safe count",
+			currentCount: 8,
+			safeCount:    5,
+			expectedDecision: &scalingDecision{
+				direction: scale.DirectionIn,
+				count:     3,
+				metrics:   map[string]*scalingMetricDecision{fallbackSafeCountMetricName: {value: 8, threshold: 5}},
+			},
+		},
+		{
+			name:         "safe count above group maximum",
+			currentCount: 8,
+			safeCount:    50,
+			expectedDecision: &scalingDecision{
+				direction: scale.DirectionOut,
+				count:     2,
+				metrics:   map[string]*scalingMetricDecision{fallbackSafeCountMetricName: {value: 8, threshold: 10}},
+			},
+		},
+		{
+			name:             "already at safe count",
+			currentCount:     5,
+			safeCount:        5,
+			expectedDecision: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			ae := &autoscaleEvaluation{groupCounts: map[string]int{"worker": tc.currentCount}}
+			pol := &policy.GroupScalingPolicy{
+				MinCount:        1,
+				MaxCount:        10,
+				MetricsFallback: &policy.MetricsFallback{Action: policy.FallbackSafeCount, SafeCount: tc.safeCount},
+			}
+			assert.Equal(t, tc.expectedDecision, ae.calculateSafeCountDecision("worker", pol))
+		})
+	}
+}
+
+func Test_autoscaleEvaluation_evaluateMetricsFallback(t *testing.T) {
+	ae := &autoscaleEvaluation{groupCounts: map[string]int{"worker": 2}}
+
+	// Groups without a fallback, or with the hold action, should not be scaled.
+	assert.Nil(t, ae.evaluateMetricsFallback("worker", &policy.GroupScalingPolicy{MaxCount: 10}))
+	assert.Nil(t, ae.evaluateMetricsFallback("worker", &policy.GroupScalingPolicy{
+		MaxCount:        10,
+		MetricsFallback: &policy.MetricsFallback{Action: policy.FallbackHold},
+	}))
+
+	dec := ae.evaluateMetricsFallback("worker", &policy.GroupScalingPolicy{
+		MaxCount:        10,
+		MetricsFallback: &policy.MetricsFallback{Action: policy.FallbackSafeCount, SafeCount: 4},
+	})
+	assert.NotNil(t, dec)
+	assert.Equal(t, scale.DirectionOut, dec.direction)
+	assert.Equal(t, 2, dec.count)
+}
@@ -0,0 +1,234 @@
+package autoscale
+
+import (
+	"sort"
+	"strings"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/scale"
+)
+
+const (
+	// gpuDeviceType is the Nomad device type used by GPU device plugins.
+	gpuDeviceType = "gpu"
+
+	// gpuUtilizationStatAttr is the device stat attribute which the Nomad GPU device plugins use to
+	// report the percentage utilisation of a GPU instance.
+	gpuUtilizationStatAttr = "GPU utilization"
+)
+
+// isGPUDeviceName determines whether a requested device name refers to a GPU. Nomad device names
+// can be in the form <type>, <vendor>/<type> or <vendor>/<type>/<name>.
+func isGPUDeviceName(name string) bool {
+	parts := strings.Split(name, "/")
+
+	switch len(parts) {
+	case 1:
+		return parts[0] == gpuDeviceType
+	default:
+		return parts[1] == gpuDeviceType
+	}
+}
+
+// getRequestedGPUs returns the total number of GPU instances requested within the task resources.
+func getRequestedGPUs(res *nomad.Resources) float64 {
+	var count float64
+
+	if res == nil {
+		return count
+	}
+
+	for i := range res.Devices {
+		if res.Devices[i].Count != nil && isGPUDeviceName(res.Devices[i].Name) {
+			count += float64(*res.Devices[i].Count)
+		}
+	}
+	return count
+}
+
+// getGPUUtilization sums the utilisation percentage of each GPU instance found within the device
+// stats. The returned value should be divided by the number of GPU instances to find the average.
+func getGPUUtilization(stats []*nomad.DeviceGroupStats) float64 {
+	var total float64
+
+	for i := range stats {
+		if stats[i] == nil || stats[i].Type != gpuDeviceType {
+			continue
+		}
+
+		for _, instance := range stats[i].InstanceStats {
+			if instance == nil || instance.Stats == nil {
+				continue
+			}
+			if val, ok := instance.Stats.Attributes[gpuUtilizationStatAttr]; ok {
+				total += statValueToFloat64(val)
+			}
+		}
+	}
+	return total
+}
+
+// statValueToFloat64 converts the numeric representation of a Nomad StatValue into a float64.
+func statValueToFloat64(val *nomad.StatValue) float64 {
+	if val == nil {
+		return 0
+	}
+	if val.FloatNumeratorVal != nil {
+		return *val.FloatNumeratorVal
+	}
+	if val.IntNumeratorVal != nil {
+		return float64(*val.IntNumeratorVal)
+	}
+	return 0
+}
+
+// getClusterGPUCapacity returns the number of healthy GPU instances on ready and eligible Nomad
+// client nodes which are not already allocated to running or pending allocations.
+func (ae *autoscaleEvaluation) getClusterGPUCapacity() (int, error) {
+	var nodes []*nomad.NodeListStub
+
+	err := ae.callNomad(func() (err error) {
+		nodes, _, err = ae.nomad.Nodes().List(nil)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var capacity int
+
+	for i := range nodes {
+		if nodes[i].Status != "ready" || nodes[i].Drain || nodes[i].SchedulingEligibility != "eligible" {
+			continue
+		}
+
+		var info *nomad.Node
+
+		err := ae.callNomad(func() (err error) {
+			info, _, err = ae.nomad.Nodes().Info(nodes[i].ID, nil)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+		if info.NodeResources == nil {
+			continue
+		}
+
+		var healthy int
+
+		for _, dev := range info.NodeResources.Devices {
+			if dev.Type != gpuDeviceType {
+				continue
+			}
+			for _, instance := range dev.Instances {
+				if instance.Healthy {
+					healthy++
+				}
+			}
+		}
+		if healthy == 0 {
+			continue
+		}
+
+		var allocs []*nomad.Allocation
+
+		err = ae.callNomad(func() (err error) {
+			allocs, _, err = ae.nomad.Nodes().Allocations(nodes[i].ID, nil)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		// GPUs allocated to instances which have since become unhealthy do not reduce the free
+		// capacity of the node below zero.
+		if free := healthy - getAllocatedGPUs(allocs); free > 0 {
+			capacity += free
+		}
+	}
+	return capacity, nil
+}
+
+// getAllocatedGPUs returns the number of GPU instances requested by the running or pending
+// allocations.
+func getAllocatedGPUs(allocs []*nomad.Allocation) int {
+	var count float64
+
+	for i := range allocs {
+		if !(allocs[i].ClientStatus == nomad.AllocClientStatusRunning || allocs[i].ClientStatus == nomad.AllocClientStatusPending) {
+			continue
+		}
+		for _, res := range allocs[i].TaskResources {
+			count += getRequestedGPUs(res)
+		}
+	}
+	return int(count)
+}
+
+// enforceGPUCapacity ensures scale out decisions for groups which request GPUs do not exceed the
+// number of allocations the free GPUs within the cluster could support. The decision count is
+// reduced where required, and the decision removed if no further capacity exists. Groups are
+// handled in name order, with each scale out reducing the capacity left for the next group.
+func (ae *autoscaleEvaluation) enforceGPUCapacity(dec map[string]*scalingDecision) {
+	if ae.nomadMetricData == nil {
+		return
+	}
+
+	var (
+		capacity int
+		gathered bool
+	)
+
+	groups := make([]string, 0, len(dec))
+	for group := range dec {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		decision := dec[group]
+		if decision.direction != scale.DirectionOut {
+			continue
+		}
+
+		info, ok := ae.nomadMetricData.resourceInfo[group]
+		if !ok || info.gpu == 0 || ae.nomadMetricData.allocCount[group] == 0 {
+			continue
+		}
+
+		// The cluster capacity is only needed once per evaluation, and only if a GPU group wishes
+		// to scale out.
+		if !gathered {
+			c, err := ae.getClusterGPUCapacity()
+			if err != nil {
+				ae.log.Error().Err(err).Msg("failed to determine cluster GPU capacity, skipping GPU bounds check")
+				return
+			}
+			capacity, gathered = c, true
+		}
+
+		perAlloc := info.gpu / float64(ae.nomadMetricData.allocCount[group])
+		maxCount := int(float64(capacity) / perAlloc)
+
+		if decision.count > maxCount {
+			if maxCount <= 0 {
+				ae.log.Info().
+					Str("group", group).
+					Int("gpu-capacity", capacity).
+					Msg("insufficient GPU capacity available to scale out job group")
+				delete(dec, group)
+				continue
+			}
+
+			ae.log.Info().
+				Str("group", group).
+				Int("gpu-capacity", capacity).
+				Int("original-count", decision.count).
+				Int("bounded-count", maxCount).
+				Msg("reducing scale out count to match available GPU capacity")
+			decision.count = maxCount
+		}
+		capacity -= int(float64(decision.count) * perAlloc)
+	}
+}
@@ -0,0 +1,207 @@
+package autoscale
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_isGPUDeviceName(t *testing.T) {
+	testCases := []struct {
+		input          string
+		expectedOutput bool
+	}{
+		{input: "gpu", expectedOutput: true},
+		{input: "nvidia/gpu", expectedOutput: true},
+		{input: "nvidia/gpu/1080ti", expectedOutput: true},
+		{input: "fpga", expectedOutput: false},
+		{input: "intel/fpga/arria10", expectedOutput: false},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedOutput, isGPUDeviceName(tc.input), tc.input)
+	}
+}
+
+func Test_getRequestedGPUs(t *testing.T) {
+	two, one := uint64(2), uint64(1)
+
+	res := &nomad.Resources{Devices: []*nomad.RequestedDevice{
+		{Name: "nvidia/gpu", Count: &two},
+		{Name: "gpu", Count: &one},
+		{Name: "fpga", Count: &one},
+	}}
+	assert.Equal(t, float64(3), getRequestedGPUs(res))
+	assert.Equal(t, float64(0), getRequestedGPUs(nil))
+}
+
+func Test_getGPUUtilization(t *testing.T) {
+	intVal := int64(40)
+
+	stats := []*nomad.DeviceGroupStats{
+		{
+			Type: "gpu",
+			InstanceStats: map[string]*nomad.DeviceStats{
+				"gpu-1": {Stats: &nomad.StatObject{Attributes: map[string]*nomad.StatValue{
+					gpuUtilizationStatAttr: {FloatNumeratorVal: helper.Float64ToPointer(80)},
+				}}},
+				"gpu-2": {Stats: &nomad.StatObject{Attributes: map[string]*nomad.StatValue{
+					gpuUtilizationStatAttr: {IntNumeratorVal: &intVal},
+				}}},
+				"gpu-3": {},
+			},
+		},
+		{
+			Type: "fpga",
+			InstanceStats: map[string]*nomad.DeviceStats{
+				"fpga-1": {Stats: &nomad.StatObject{Attributes: map[string]*nomad.StatValue{
+					gpuUtilizationStatAttr: {FloatNumeratorVal: helper.Float64ToPointer(99)},
+				}}},
+			},
+		},
+	}
+	assert.Equal(t, float64(120), getGPUUtilization(stats))
+}
+
+func Test_getAllocatedGPUs(t *testing.T) {
+	two := uint64(2)
+	gpus := map[string]*nomad.Resources{"app": {Devices: []*nomad.RequestedDevice{{Name: "nvidia/gpu", Count: &two}}}}
+
+	allocs := []*nomad.Allocation{
+		{ClientStatus: nomad.AllocClientStatusRunning, TaskResources: gpus},
+		{ClientStatus: nomad.AllocClientStatusPending, TaskResources: gpus},
+		{ClientStatus: nomad.AllocClientStatusComplete, TaskResources: gpus},
+		{ClientStatus: nomad.AllocClientStatusRunning},
+	}
+	assert.Equal(t, 4, getAllocatedGPUs(allocs))
+	assert.Equal(t, 0, getAllocatedGPUs(nil))
+}
+
+// newGPUTestNomad returns a Nomad API server with a ready node holding 2 free GPUs, a ready node
+// whose healthy GPU is allocated, and a draining node.
+func newGPUTestNomad(t *testing.T) *httptest.Server {
+	two := uint64(2)
+	gpuDevice := func(healthy ...bool) []*nomad.NodeDeviceResource {
+		dev := &nomad.NodeDeviceResource{Type: gpuDeviceType}
+		for _, h := range healthy {
+			dev.Instances = append(dev.Instances, &nomad.NodeDevice{Healthy: h})
+		}
+		return []*nomad.NodeDeviceResource{dev}
+	}
+	gpuAlloc := func(status string) *nomad.Allocation {
+		return &nomad.Allocation{ClientStatus: status, TaskResources: map[string]*nomad.Resources{
+			"app": {Devices: []*nomad.RequestedDevice{{Name: "gpu", Count: &two}}},
+		}}
+	}
+
+	responses := map[string]interface{}{
+		"/v1/nodes": []*nomad.NodeListStub{
+			{ID: "node-1", Status: "ready", SchedulingEligibility: "eligible"},
+			{ID: "node-2", Status: "ready", SchedulingEligibility: "eligible"},
+			{ID: "node-3", Status: "ready", SchedulingEligibility: "ineligible", Drain: true},
+		},
+		"/v1/node/node-1": &nomad.Node{NodeResources: &nomad.NodeResources{Devices: gpuDevice(true, true, true, true)}},
+		"/v1/node/node-1/allocations": []*nomad.Allocation{
+			gpuAlloc(nomad.AllocClientStatusRunning), gpuAlloc(nomad.AllocClientStatusComplete),
+		},
+		"/v1/node/node-2":             &nomad.Node{NodeResources: &nomad.NodeResources{Devices: gpuDevice(true, false)}},
+		"/v1/node/node-2/allocations": []*nomad.Allocation{gpuAlloc(nomad.AllocClientStatusRunning)},
+	}
+
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		resp, ok := responses[r.URL.Path]
+		if !assert.True(t, ok, r.URL.Path) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		assert.Nil(t, json.NewEncoder(w).Encode(resp))
+	}))
+}
+
+func Test_autoscaleEvaluation_enforceGPUCapacity(t *testing.T) {
+	srv := newGPUTestNomad(t)
+	defer srv.Close()
+
+	nomadClient, err := nomad.NewClient(&nomad.Config{Address: srv.URL})
+	assert.Nil(t, err)
+
+	// Each allocation of the worker group uses 1 GPU, and each of the trainer group uses 4.
+	metrics := &nomadGatheredMetrics{
+		resourceInfo: map[string]*nomadResources{
+			"worker":  {gpu: 2},
+			"worker2": {gpu: 1},
+			"trainer": {gpu: 4},
+			"web":     {cpu: 100},
+		},
+		allocCount: map[string]int{"worker": 2, "worker2": 1, "trainer": 1, "web": 2},
+	}
+
+	testCases := []struct {
+		name              string
+		metrics           *nomadGatheredMetrics
+		inputDecisions    map[string]*scalingDecision
+		expectedDecisions map[string]*scalingDecision
+	}{
+		{
+			name:              "scale out within free GPUs",
+			metrics:           metrics,
+			inputDecisions:    map[string]*scalingDecision{"worker": {direction: scale.DirectionOut, count: 2}},
+			expectedDecisions: map[string]*scalingDecision{"worker": {direction: scale.DirectionOut, count: 2}},
+		},
+		{
+			name:              "scale out reduced to free GPUs",
+			metrics:           metrics,
+			inputDecisions:    map[string]*scalingDecision{"worker": {direction: scale.DirectionOut, count: 5}},
+			expectedDecisions: map[string]*scalingDecision{"worker": {direction: scale.DirectionOut, count: 2}},
+		},
+		{
+			name:              "scale out removed without enough free GPUs",
+			metrics:           metrics,
+			inputDecisions:    map[string]*scalingDecision{"trainer": {direction: scale.DirectionOut, count: 1}},
+			expectedDecisions: map[string]*scalingDecision{},
+		},
+		{
+			name:    "free GPUs shared between groups in name order",
+			metrics: metrics,
+			inputDecisions: map[string]*scalingDecision{
+				"worker":  {direction: scale.DirectionOut, count: 1},
+				"worker2": {direction: scale.DirectionOut, count: 2},
+			},
+			expectedDecisions: map[string]*scalingDecision{
+				"worker":  {direction: scale.DirectionOut, count: 1},
+				"worker2": {direction: scale.DirectionOut, count: 1},
+			},
+		},
+		{
+			name:    "scale in and groups without GPUs are not bounded",
+			metrics: metrics,
+			inputDecisions: map[string]*scalingDecision{
+				"trainer": {direction: scale.DirectionIn, count: 1},
+				"web":     {direction: scale.DirectionOut, count: 10},
+			},
+			expectedDecisions: map[string]*scalingDecision{
+				"trainer": {direction: scale.DirectionIn, count: 1},
+				"web":     {direction: scale.DirectionOut, count: 10},
+			},
+		},
+		{
+			name:              "no Nomad metrics",
+			metrics:           nil,
+			inputDecisions:    map[string]*scalingDecision{"trainer": {direction: scale.DirectionOut, count: 1}},
+			expectedDecisions: map[string]*scalingDecision{"trainer": {direction: scale.DirectionOut, count: 1}},
+		},
+	}
+
+	for _, tc := range testCases {
+		ae := &autoscaleEvaluation{nomad: nomadClient, log: zerolog.Nop(), nomadMetricData: tc.metrics}
+		ae.enforceGPUCapacity(tc.inputDecisions)
+		assert.Equal(t, tc.expectedDecisions, tc.inputDecisions, tc.name)
+	}
+}
@@ -1,16 +1,39 @@
 package autoscale
 
 import (
+	"context"
+	"sort"
+	"sync"
 	"time"
 
-	"github.com/jrasell/sherpa/pkg/helper"
-
-	nomad "github.com/hashicorp/nomad/api"
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/gofrs/uuid"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/jrasell/sherpa/pkg/autoscale/evallog"
 	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/datadog"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/elasticsearch"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/envoy"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/graphite"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/haproxy"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/influxdb"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/ingress"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/nats"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/newrelic"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/nomad"
 	"github.com/jrasell/sherpa/pkg/autoscale/metrics/prometheus"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/rabbitmq"
+	"github.com/jrasell/sherpa/pkg/chaos"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/filter"
+	"github.com/jrasell/sherpa/pkg/helper"
 	"github.com/jrasell/sherpa/pkg/policy"
 	policyBackend "github.com/jrasell/sherpa/pkg/policy/backend"
+	"github.com/jrasell/sherpa/pkg/retry"
 	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/jrasell/sherpa/pkg/state"
 	ants "github.com/panjf2000/ants/v2"
 	"github.com/rs/zerolog"
 )
@@ -18,15 +41,67 @@ import (
 type AutoScale struct {
 	cfg    *Config
 	logger zerolog.Logger
-	nomad  *nomad.Client
+	nomad  *client.NomadPool
+	consul *consul.Client
 	scaler scale.Scale
 
+	// cooldowns is the store of job group cooldown timestamps, which are included in the
+	// internal state of the autoscaler.
+	cooldowns CooldownStore
+
 	policyBackend policyBackend.PolicyBackend
 	pool          *ants.PoolWithFunc
 
+	// tuner adapts the size of the worker pool, and is nil when auto-tuning is disabled.
+	tuner *poolTuner
+
+	// staleness tracks when job groups were last evaluated, so evaluations can be prioritised.
+	staleness *stalenessTracker
+
+	// orphans tracks the jobs of scaling policies which have been stopped or purged from Nomad.
+	orphans *orphanTracker
+
+	// decisions tracks the latest scaling decision made for each job group.
+	decisions *decisionTracker
+
 	// metricProvider
 	metricProvider map[policy.MetricsProvider]metrics.Provider
 
+	// prometheusEndpoints are the named Prometheus-compatible endpoint providers, keyed by the
+	// name which policy checks use to reference them.
+	prometheusEndpoints map[string]metrics.Provider
+
+	// faults is the optional fault injector used for resilience testing.
+	faults *chaos.Injector
+
+	// secrets resolves metric provider credentials which are referenced indirectly, such as via
+	// Vault, rather than configured as plain values.
+	secrets *secret.Resolver
+
+	// jobFilter restricts the jobs which are evaluated, and is nil if all jobs are evaluated.
+	jobFilter *filter.JobFilter
+
+	// shard determines the jobs owned by this server when evaluations are sharded across the
+	// cluster members, and is nil when sharding is disabled.
+	shard JobOwner
+
+	// breakers are the circuit breaker wrapped metric providers, keyed by the provider name.
+	breakers map[string]*metrics.BreakerProvider
+
+	// caches are the query cache wrapped metric providers, which are reset at the start of each
+	// scaling run. This is empty when provider caching is disabled.
+	caches []*metrics.CachingProvider
+
+	// evalLog writes a record of each job evaluation when the evaluation log is enabled.
+	evalLog *evallog.Writer
+
+	// overrides are the active metric overrides set via the API for game-day testing.
+	overrides *metricOverrides
+
+	// allocStats caches the resource usage of allocations read by job evaluations, and is nil
+	// when the allocation stats cache is disabled.
+	allocStats *allocStatsCache
+
 	// isRunning is used to track whether the autoscaler loop is being run. This helps determine
 	// whether stop should be called.
 	isRunning bool
@@ -42,6 +117,9 @@ type workerPayload struct {
 	time   time.Time
 	jobID  string
 	policy map[string]*policy.GroupScalingPolicy
+
+	// wg tracks the in-flight evaluations of the autoscaling run which dispatched the payload.
+	wg *sync.WaitGroup
 }
 
 func NewAutoScaleServer(cfg *SetupConfig) (*AutoScale, error) {
@@ -50,17 +128,58 @@ func NewAutoScaleServer(cfg *SetupConfig) (*AutoScale, error) {
 			ScalingInterval:   cfg.ScalingInterval,
 			ScalingThreads:    cfg.ScalingThreads,
 			StrictChecking:    cfg.StrictChecking,
+			MaxStaleness:      cfg.MaxStaleness,
+			EvaluationTimeout: cfg.EvaluationTimeout,
+			NomadTimeout:      cfg.NomadTimeout,
+			DryRun:            cfg.DryRun,
+			ShadowMode:        cfg.ShadowMode,
+			BoundsEnforcement: cfg.BoundsEnforcement,
+			MinStatsCoverage:  cfg.MinStatsCoverage,
+			GroupConcurrency:  cfg.GroupConcurrency,
+			DrainAwareScaleIn: cfg.DrainAwareScaleIn,
+			OrphanPolicyGC:    cfg.OrphanPolicyGC,
 			MetricProviderCfg: cfg.MetricProviderCfg,
 		},
 		logger:        cfg.Logger,
 		nomad:         cfg.Nomad,
+		consul:        cfg.Consul,
+		faults:        cfg.FaultInjector,
+		secrets:       cfg.Secrets,
+		jobFilter:     cfg.JobFilter,
+		shard:         cfg.Shard,
 		policyBackend: cfg.PolicyBackend,
 		scaler:        cfg.Scale,
+		cooldowns:     cfg.Cooldowns,
+		decisions:     newDecisionTracker(),
+		staleness:     newStalenessTracker(),
+		orphans:       newOrphanTracker(),
+		overrides:     newMetricOverrides(),
 		doneChan:      make(chan struct{}),
 	}
 
 	as.setupMetricProviders()
 
+	// Cached allocations are tracked while they are read by evaluations, which occurs at least once
+	// per evaluation interval for jobs which are not deferred or in cooldown.
+	if cfg.AllocStatsCacheTTL > 0 {
+		as.allocStats = newAllocStatsCache(time.Duration(cfg.AllocStatsCacheTTL)*time.Second,
+			2*time.Duration(cfg.ScalingInterval)*time.Second, as.fetchAllocStats)
+	}
+
+	if cfg.EvaluationLogPath != "" {
+		evalLog, err := evallog.NewFileWriter(cfg.EvaluationLogPath)
+		if err != nil {
+			return nil, err
+		}
+		as.evalLog = evalLog
+	}
+
+	tuner, err := newPoolTuner(cfg.ScalingThreadsMin, cfg.ScalingThreadsMax, cfg.NomadLatencyThreshold)
+	if err != nil {
+		return nil, err
+	}
+	as.tuner = tuner
+
 	pool, err := as.createWorkerPool()
 	if err != nil {
 		return nil, err
@@ -82,9 +201,189 @@ func (a *AutoScale) setupMetricProviders() {
 		if err != nil {
 			a.logger.Error().Err(err).Msg("failed to setup Prometheus metric provider client")
 		} else {
-			a.metricProvider[policy.ProviderPrometheus] = promClient
+			a.registerMetricProvider(promClient)
 		}
 	}
+
+	a.setupPrometheusEndpoints()
+
+	// If the Envoy provider is enabled, set this up using the Consul client for proxy discovery.
+	if a.cfg.MetricProviderCfg.EnvoyEnabled && a.consul != nil {
+		a.registerMetricProvider(envoy.NewClient(a.consul, a.logger))
+	}
+
+	// Setup the ingress providers which have a metrics endpoint configured.
+	if a.cfg.MetricProviderCfg.Traefik != nil {
+		a.registerMetricProvider(ingress.NewTraefikClient(a.cfg.MetricProviderCfg.Traefik.Addr, a.logger))
+	}
+	if a.cfg.MetricProviderCfg.NGINX != nil {
+		a.registerMetricProvider(ingress.NewNGINXClient(a.cfg.MetricProviderCfg.NGINX.Addr, a.logger))
+	}
+
+	// Setup the HAProxy provider if a runtime API socket or stats page is configured.
+	if a.cfg.MetricProviderCfg.HAProxy != nil {
+		a.registerMetricProvider(haproxy.NewClient(a.cfg.MetricProviderCfg.HAProxy.Addr, a.logger))
+	}
+
+	// Setup the RabbitMQ provider if a management API address is configured.
+	if a.cfg.MetricProviderCfg.RabbitMQ != nil {
+		a.registerMetricProvider(rabbitmq.NewClient(a.cfg.MetricProviderCfg.RabbitMQ.Addr, a.logger))
+	}
+
+	// Setup the NATS JetStream provider if a server monitoring address is configured.
+	if a.cfg.MetricProviderCfg.NATS != nil {
+		a.registerMetricProvider(nats.NewClient(a.cfg.MetricProviderCfg.NATS.Addr, a.logger))
+	}
+
+	// Setup the InfluxDB provider if an API address is configured.
+	if a.cfg.MetricProviderCfg.InfluxDB != nil {
+		a.registerMetricProvider(influxdb.NewClient(a.cfg.MetricProviderCfg.InfluxDB.Addr,
+			a.cfg.MetricProviderCfg.InfluxDB.Org, a.secrets.Value(a.cfg.MetricProviderCfg.InfluxDB.Token), a.logger))
+	}
+
+	// Setup the Graphite provider if a render API address is configured.
+	if a.cfg.MetricProviderCfg.Graphite != nil {
+		a.registerMetricProvider(graphite.NewClient(a.cfg.MetricProviderCfg.Graphite.Addr, a.logger))
+	}
+
+	// Setup the Elasticsearch provider if a cluster address is configured.
+	if a.cfg.MetricProviderCfg.Elasticsearch != nil {
+		a.registerMetricProvider(elasticsearch.NewClient(a.cfg.MetricProviderCfg.Elasticsearch.Addr, a.logger))
+	}
+
+	// Setup the New Relic provider if an API key is configured.
+	if nr := a.cfg.MetricProviderCfg.NewRelic; nr != nil {
+		a.registerMetricProvider(newrelic.NewClient(nr.Addr, a.secrets.Value(nr.APIKey), nr.AccountID, a.logger))
+	}
+
+	// Setup the Datadog provider if an API key is configured.
+	if dd := a.cfg.MetricProviderCfg.Datadog; dd != nil {
+		a.registerMetricProvider(datadog.NewClient(dd.Addr, a.secrets.Value(dd.APIKey), a.secrets.Value(dd.AppKey), a.logger))
+	}
+
+	// The Nomad provider uses the server Nomad client so requires no further config.
+	if a.nomad != nil {
+		a.registerMetricProvider(nomad.NewClient(a.nomad, a.logger))
+	}
+
+	a.setupProviderFaults()
+	a.setupProviderBreakers()
+	a.setupProviderCaches()
+}
+
+// registerMetricProvider adds the provider to the autoscaler, keyed by its name so that it is used
+// by policy external checks which reference the name. New metric sources only need to implement
+// the metrics.Provider interface and be registered here to be queried by the autoscaler.
+func (a *AutoScale) registerMetricProvider(p metrics.Provider) {
+	a.metricProvider[policy.MetricsProvider(p.Name())] = p
+}
+
+// setupProviderFaults wraps each configured metric provider with the fault injector, if one is
+// configured. This is performed before the circuit breakers are setup, so that injected faults
+// exercise the breakers.
+func (a *AutoScale) setupProviderFaults() {
+	if a.faults == nil {
+		return
+	}
+
+	for name, p := range a.metricProvider {
+		a.metricProvider[name] = chaos.NewMetricsProvider(a.faults, p)
+	}
+	for name, p := range a.prometheusEndpoints {
+		a.prometheusEndpoints[name] = chaos.NewMetricsProvider(a.faults, p)
+	}
+}
+
+// setupProviderBreakers wraps each configured metric provider with a circuit breaker which tracks
+// provider health and stops querying providers which are consistently failing. Failed queries are
+// retried before being tracked by the breaker.
+func (a *AutoScale) setupProviderBreakers() {
+	a.breakers = make(map[string]*metrics.BreakerProvider)
+
+	cfg := &metrics.BreakerConfig{
+		ErrorThreshold: a.cfg.MetricProviderCfg.BreakerErrorThreshold,
+		Window:         a.cfg.MetricProviderCfg.BreakerWindow,
+		Cooldown:       time.Duration(a.cfg.MetricProviderCfg.BreakerCooldown) * time.Second,
+		Retry:          retry.Default,
+	}
+
+	for name, p := range a.metricProvider {
+		b := metrics.NewBreakerProvider(name.String(), p, cfg)
+		a.metricProvider[name] = b
+		a.breakers[name.String()] = b
+	}
+
+	for name, p := range a.prometheusEndpoints {
+		b := metrics.NewBreakerProvider(policy.ProviderPrometheus.String()+"/"+name, p, cfg)
+		a.prometheusEndpoints[name] = b
+		a.breakers[policy.ProviderPrometheus.String()+"/"+name] = b
+	}
+}
+
+// setupProviderCaches wraps each configured metric provider with a query cache, if enabled. This
+// is performed after the circuit breakers are setup, so that cached results are not tracked as
+// provider queries.
+func (a *AutoScale) setupProviderCaches() {
+	if !a.cfg.MetricProviderCfg.CacheEnabled {
+		return
+	}
+
+	for name, p := range a.metricProvider {
+		c := metrics.NewCachingProvider(name.String(), p)
+		a.metricProvider[name] = c
+		a.caches = append(a.caches, c)
+	}
+
+	for name, p := range a.prometheusEndpoints {
+		c := metrics.NewCachingProvider(policy.ProviderPrometheus.String()+"/"+name, p)
+		a.prometheusEndpoints[name] = c
+		a.caches = append(a.caches, c)
+	}
+}
+
+// resetProviderCaches removes the cached query results of each provider, so that the following
+// scaling run uses fresh metric values.
+func (a *AutoScale) resetProviderCaches() {
+	for _, c := range a.caches {
+		c.Reset()
+	}
+}
+
+// ProviderStatus returns the status of each configured metric provider, sorted by name.
+func (a *AutoScale) ProviderStatus() []metrics.ProviderStatus {
+	out := make([]metrics.ProviderStatus, 0, len(a.breakers))
+
+	for _, b := range a.breakers {
+		out = append(out, b.Status())
+	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// setupPrometheusEndpoints sets up a provider for each named Prometheus-compatible endpoint found
+// in the configured endpoints file.
+func (a *AutoScale) setupPrometheusEndpoints() {
+	a.prometheusEndpoints = make(map[string]metrics.Provider)
+
+	if a.cfg.MetricProviderCfg.PrometheusEndpointsFile == "" {
+		return
+	}
+
+	endpoints, err := server.LoadPrometheusEndpoints(a.cfg.MetricProviderCfg.PrometheusEndpointsFile)
+	if err != nil {
+		a.logger.Error().Err(err).Msg("failed to load Prometheus endpoints")
+		return
+	}
+
+	for _, ep := range endpoints {
+		client, err := prometheus.NewEndpointClient(ep, a.logger)
+		if err != nil {
+			a.logger.Error().Err(err).Str("endpoint", ep.Name).Msg("failed to setup Prometheus endpoint client")
+			continue
+		}
+		a.prometheusEndpoints[ep.Name] = client
+	}
 }
 
 // IsRunning is used to determine if the autoscaler loop is running.
@@ -92,103 +391,44 @@ func (a *AutoScale) IsRunning() bool {
 	return a.isRunning
 }
 
-// Run starts the autoscaler ticker loop and only stops when Stop() is called.
+// Run starts the autoscaler loop and only stops when Stop() is called.
 func (a *AutoScale) Run() {
 	a.logger.Info().Msg("starting Sherpa internal auto-scaling engine")
 
 	// Track that the autoscaler is actively running.
 	a.isRunning = true
 
-	t := time.NewTicker(time.Second * time.Duration(a.cfg.ScalingInterval))
+	interval := time.Second * time.Duration(a.cfg.ScalingInterval)
+
+	t := time.NewTimer(interval)
 	defer t.Stop()
 
+	go a.runPoolMonitor()
+
+	if a.allocStats != nil {
+		go a.allocStats.run(a.doneChan)
+	}
+
 	for {
 		select {
 		case <-t.C:
-			// Check whether a previous scaling loop is in progress, and if it is we should skip
-			// this round. This avoids putting more pressure on a system which may be under load
-			// causing slow API responses.
-			if a.inProgress {
-				a.logger.Info().Msg("scaling run in progress, skipping new assessment")
-				break
+			start := time.Now()
+			a.runScalingLoop()
+
+			// The run returns once all of its evaluations have completed, so the elapsed time
+			// includes them. If the run took longer than the evaluation interval, start the next
+			// run immediately rather than waiting for the next tick, so evaluations do not drift
+			// further behind.
+			elapsed := time.Since(start)
+			delay, missed := nextRunDelay(elapsed, interval)
+			if missed > 0 {
+				a.logger.Warn().
+					Dur("duration", elapsed).
+					Int("missed-intervals", missed).
+					Msg("autoscaling run overran evaluation interval, starting next run immediately")
+				sendMetrics.IncrCounter([]string{"autoscale", "interval_overrun"}, float32(missed))
 			}
-			a.setScalingInProgressTrue()
-
-			allPolicies, err := a.policyBackend.GetPolicies()
-			if err != nil {
-				a.logger.Error().Err(err).Msg("autoscaler unable to get scaling policies")
-				a.setScalingInProgressFalse()
-				break
-			}
-			totalPolicyCount := len(allPolicies)
-
-			if totalPolicyCount == 0 {
-				a.logger.Debug().Msg("no scaling policies found in storage backend")
-				a.setScalingInProgressFalse()
-				break
-			}
-
-			for job := range allPolicies {
-
-				// Generate a timestamp for the occurrence of this autoscaling attempt.
-				t := time.Now().UTC()
-
-				// Create a new policy object to track groups that are not considered to be in
-				// deployment or in cooldown.
-				safeScale := make(map[string]*policy.GroupScalingPolicy)
-
-				// Iterate the group policies, and check whether they are in deployment or in
-				// cooldown.
-				for group := range allPolicies[job] {
-
-					// If the group policy is disabled, continue with the loop and ignore the
-					// policy.
-					if !allPolicies[job][group].Enabled {
-						continue
-					}
-
-					// Deployment check.
-					if a.scaler.JobGroupIsDeploying(job, group) {
-						a.logger.Debug().
-							Str("job", job).
-							Str("group", group).
-							Msg("job group is currently in deployment, skipping autoscaler evaluation")
-						continue
-					}
-
-					// Cooldown check.
-					cool, err := a.scaler.JobGroupIsInCooldown(job, group, allPolicies[job][group].Cooldown, t.UnixNano())
-					if err != nil {
-						a.logger.Error().
-							Err(err).
-							Str("job", job).
-							Str("group", group).
-							Msg("failed to determine if job group is in cooldown")
-						continue
-					}
-					if cool {
-						a.logger.Debug().
-							Err(err).
-							Str("job", job).
-							Str("group", group).
-							Msg("job group is currently in scaling cooldown, skipping autoscaler evaluation")
-						continue
-					}
-
-					// At this point the initial checks have passed, therefore we can add the group
-					// to the map indicating we can continue within the evaluation.
-					safeScale[group] = allPolicies[job][group]
-				}
-
-				// If we have groups within the job that are not deploying, we can trigger a
-				// scaling event.
-				if len(safeScale) > 0 {
-					if err := a.pool.Invoke(&workerPayload{jobID: job, policy: allPolicies[job], time: t}); err != nil {
-						a.logger.Error().Err(err).Msg("failed to invoke autoscaling worker thread")
-					}
-				}
-			}
-			a.setScalingInProgressFalse()
+			t.Reset(delay)
 
 		case <-a.doneChan:
 			a.isRunning = false
@@ -197,6 +437,136 @@ func (a *AutoScale) Run() {
 	}
 }
 
+// runScalingLoop performs a single autoscaling run, evaluating all eligible jobs. It returns once
+// all the evaluations dispatched to the worker pool have completed.
+func (a *AutoScale) runScalingLoop() {
+	// Check whether a previous scaling loop is in progress, and if it is we should skip
+	// this round. This avoids putting more pressure on a system which may be under load
+	// causing slow API responses.
+	if a.inProgress {
+		a.logger.Info().Msg("scaling run in progress, skipping new assessment")
+		return
+	}
+	a.setScalingInProgressTrue()
+	a.resetProviderCaches()
+
+	allPolicies, err := a.getPolicies()
+	if err != nil {
+		a.logger.Error().Err(err).Msg("autoscaler unable to get scaling policies")
+		a.setScalingInProgressFalse()
+		return
+	}
+	totalPolicyCount := len(allPolicies)
+
+	if totalPolicyCount == 0 {
+		a.logger.Debug().Msg("no scaling policies found in storage backend")
+		a.setScalingInProgressFalse()
+		return
+	}
+
+	// Remove the evaluation times of any policies which have been deleted, and track the
+	// jobs which have groups eligible for evaluation during this run.
+	a.staleness.prune(allPolicies)
+	a.orphans.prune(allPolicies)
+	a.decisions.prune(allPolicies)
+	deleted := a.gcOrphanedPolicies(time.Now().UTC())
+
+	var candidates []*evaluationCandidate
+
+	for job := range allPolicies {
+
+		// Jobs excluded by the job filter are the responsibility of another Sherpa
+		// instance, or not autoscaled at all.
+		if !a.jobFilter.MatchID(job) {
+			continue
+		}
+
+		// Policies of orphaned jobs which have just been deleted are not evaluated.
+		if deleted[job] {
+			continue
+		}
+
+		// When sharding is enabled, jobs owned by other cluster members are skipped.
+		if a.shard != nil && !a.shard.OwnsJob(job) {
+			continue
+		}
+
+		// Generate a timestamp used to check whether the job groups are in cooldown, and
+		// track the groups that are not considered to be in deployment or in cooldown.
+		safeScale := a.eligibleGroups(job, allPolicies[job], time.Now().UTC())
+
+		// If we have groups within the job that are not deploying, the job is a candidate
+		// for evaluation.
+		if len(safeScale) > 0 {
+			candidates = append(candidates, &evaluationCandidate{
+				job:      job,
+				groups:   safeScale,
+				lastEval: a.staleness.lastEvaluated(job, safeScale),
+			})
+		}
+	}
+
+	var wg sync.WaitGroup
+	a.dispatchEvaluations(candidates, allPolicies, &wg)
+	wg.Wait()
+
+	a.setScalingInProgressFalse()
+}
+
+// eligibleGroups returns the enabled group policies of the job which are not currently in
+// deployment or in scaling cooldown, and are therefore eligible for evaluation.
+func (a *AutoScale) eligibleGroups(job string, policies map[string]*policy.GroupScalingPolicy, t time.Time) map[string]*policy.GroupScalingPolicy {
+	// Create a new policy object to track groups that are not considered to be in
+	// deployment or in cooldown.
+	safeScale := make(map[string]*policy.GroupScalingPolicy)
+
+	// Iterate the group policies, and check whether they are in deployment or in
+	// cooldown.
+	for group := range policies {
+
+		// If the group policy is disabled, continue with the loop and ignore the
+		// policy.
+		if !policies[group].Enabled {
+			continue
+		}
+
+		// Deployment check.
+		if a.scaler.JobGroupIsDeploying(job, group) {
+			a.logger.Debug().
+				Str("job", job).
+				Str("group", group).
+				Msg("job group is currently in deployment, skipping autoscaler evaluation")
+			sendSkippedMetrics(job, group, state.ReasonDeploymentSkip)
+			continue
+		}
+
+		// Cooldown check.
+		cool, err := a.scaler.JobGroupIsInCooldown(job, group, policies[group].Cooldown, t.UnixNano())
+		if err != nil {
+			a.logger.Error().
+				Err(err).
+				Str("job", job).
+				Str("group", group).
+				Msg("failed to determine if job group is in cooldown")
+			continue
+		}
+		if cool {
+			a.logger.Debug().
+				Err(err).
+				Str("job", job).
+				Str("group", group).
+				Msg("job group is currently in scaling cooldown, skipping autoscaler evaluation")
+			sendSkippedMetrics(job, group, state.ReasonCooldownSkip)
+			continue
+		}
+
+		// At this point the initial checks have passed, therefore we can add the group
+		// to the map indicating we can continue within the evaluation.
+		safeScale[group] = policies[group]
+	}
+	return safeScale
+}
+
 // Stop is used to gracefully stop the autoscaling workers.
 func (a *AutoScale) Stop() {
 
@@ -214,6 +584,43 @@ func (a *AutoScale) Stop() {
 	}
 }
 
+// dispatchEvaluations submits the candidate jobs to the worker pool, prioritising those which have
+// gone longest without evaluation. When the pool is saturated, candidates within the max staleness
+// bound are deferred until the next autoscaling run. The wait group is incremented for each
+// dispatched evaluation, and marked done once the evaluation completes.
+func (a *AutoScale) dispatchEvaluations(candidates []*evaluationCandidate, allPolicies map[string]map[string]*policy.GroupScalingPolicy, wg *sync.WaitGroup) {
+	sortCandidates(candidates)
+
+	maxStaleness := time.Duration(a.cfg.MaxStaleness) * time.Second
+
+	for _, c := range candidates {
+
+		// Generate a timestamp for the occurrence of this autoscaling attempt.
+		t := time.Now().UTC()
+
+		if shouldDefer(c, a.pool.Free(), maxStaleness, t) {
+			a.logger.Debug().
+				Str("job", c.job).
+				Msg("autoscaler worker pool saturated, deferring job evaluation")
+			sendMetrics.IncrCounter([]string{"autoscale", "evaluation", "deferred"}, 1)
+			a.tuner.incrDeferred()
+			continue
+		}
+
+		if !c.lastEval.IsZero() {
+			sendMetrics.AddSample([]string{"autoscale", "evaluation", "staleness"}, float32(t.Sub(c.lastEval).Seconds()))
+		}
+
+		a.tuner.incrBacklog()
+		wg.Add(1)
+		if err := a.pool.Invoke(&workerPayload{jobID: c.job, policy: allPolicies[c.job], time: t, wg: wg}); err != nil {
+			a.tuner.decrBacklog()
+			wg.Done()
+			a.logger.Error().Err(err).Msg("failed to invoke autoscaling worker thread")
+		}
+	}
+}
+
 func (a *AutoScale) setScalingInProgressTrue() {
 	a.inProgress = true
 }
@@ -223,13 +630,26 @@ func (a *AutoScale) setScalingInProgressFalse() {
 }
 
 // createWorkerPool is responsible for building the ants goroutine worker pool with the number of
-// threads controlled by the operator configured value.
+// threads controlled by the operator configured value. When auto-tuning is enabled, this value is
+// the initial size of the pool.
 func (a *AutoScale) createWorkerPool() (*ants.PoolWithFunc, error) {
-	return ants.NewPoolWithFunc(a.cfg.ScalingThreads, a.workerPoolFunc(), ants.WithExpiryDuration(60*time.Second))
+	return ants.NewPoolWithFunc(a.tuner.initialSize(a.cfg.ScalingThreads), a.workerPoolFunc(), ants.WithExpiryDuration(60*time.Second))
 }
 
 func (a *AutoScale) workerPoolFunc() func(payload interface{}) {
 	return func(payload interface{}) {
+		a.tuner.decrBacklog()
+		a.tuner.startEvaluation()
+		defer a.tuner.finishEvaluation()
+
+		req, ok := payload.(*workerPayload)
+		if !ok {
+			a.logger.Error().Msg("autoscaler worker pool received unexpected payload type")
+			return
+		}
+		if req.wg != nil {
+			defer req.wg.Done()
+		}
 
 		// If this thread starts after the autoscaler has been asked to shutdown, exit. Otherwise
 		// perform the work.
@@ -240,21 +660,87 @@ func (a *AutoScale) workerPoolFunc() func(payload interface{}) {
 		default:
 		}
 
-		req, ok := payload.(*workerPayload)
-		if !ok {
-			a.logger.Error().Msg("autoscaler worker pool received unexpected payload type")
-			return
+		// The evaluation ID is used to correlate the log lines and records of a single run. A
+		// failure to generate one is not fatal to the evaluation.
+		evalID, err := uuid.NewV4()
+		if err != nil {
+			a.logger.Error().Err(err).Str("job", req.jobID).Msg("failed to generate evaluation ID")
 		}
+		a.runEvaluation(evalID, req)
+	}
+}
 
-		newEval := autoscaleEvaluation{
-			nomad:          a.nomad,
-			metricProvider: a.metricProvider,
-			scaler:         a.scaler,
-			log:            helper.LoggerWithJobContext(a.logger, req.jobID),
-			jobID:          req.jobID,
-			policies:       req.policy,
-			time:           req.time.UnixNano(),
-		}
-		newEval.evaluateJob()
+// runEvaluation performs the evaluation of the job group policies within the payload.
+func (a *AutoScale) runEvaluation(evalID uuid.UUID, req *workerPayload) {
+	a.staleness.markEvaluated(req.jobID, req.policy, time.Now().UTC())
+
+	// Bound the evaluation so that a hung Nomad or metric provider call cannot block this
+	// worker indefinitely.
+	ctx, cancel := helper.ContextWithTimeout(context.Background(), time.Duration(a.cfg.EvaluationTimeout)*time.Second)
+	defer cancel()
+
+	newEval := autoscaleEvaluation{
+		id:                evalID,
+		ctx:               ctx,
+		nomadTimeout:      time.Duration(a.cfg.NomadTimeout) * time.Second,
+		queryTimeout:      a.queryTimeout(),
+		faults:            a.faults,
+		jobFilter:         a.jobFilter,
+		dryRun:            a.cfg.DryRun,
+		shadowWindow:      a.shadowWindow(),
+		boundsEnforcement: a.cfg.BoundsEnforcement,
+		minStatsCoverage:  a.cfg.MinStatsCoverage,
+		groupConcurrency:  a.cfg.GroupConcurrency,
+		drainAwareScaleIn: a.cfg.DrainAwareScaleIn,
+		tuner:             a.tuner,
+		nomad:             a.nomad.Client(),
+		metricProvider:    a.metricProvider,
+		promEndpoints:     a.prometheusEndpoints,
+		scaler:            a.scaler,
+		evalLog:           a.evalLog,
+		overrides:         a.overrides,
+		allocStats:        a.allocStats,
+		orphans:           a.orphans,
+		decisions:         a.decisions,
+		log:               helper.LoggerWithEvaluationContext(a.logger, req.jobID, evalID.String()),
+		jobID:             req.jobID,
+		policies:          req.policy,
+		time:              req.time.UnixNano(),
 	}
+	newEval.evaluateJob()
+}
+
+// getPolicies reads all the scaling policies from the policy backend, bounding the call using the
+// Nomad API timeout as the backend may be the Nomad job meta.
+func (a *AutoScale) getPolicies() (map[string]map[string]*policy.GroupScalingPolicy, error) {
+	ctx, cancel := helper.ContextWithTimeout(context.Background(), time.Duration(a.cfg.NomadTimeout)*time.Second)
+	defer cancel()
+	return a.policyBackend.GetPolicies(ctx)
+}
+
+// shadowWindow returns the window within which Nomad Autoscaler actions are compared with the
+// decisions of an evaluation. This is zero if shadow mode is disabled.
+func (a *AutoScale) shadowWindow() time.Duration {
+	if !a.cfg.ShadowMode {
+		return 0
+	}
+	return time.Duration(a.cfg.ScalingInterval) * time.Second
+}
+
+// queryTimeout returns the timeout applied to each metric provider query.
+func (a *AutoScale) queryTimeout() time.Duration {
+	if a.cfg.MetricProviderCfg == nil {
+		return 0
+	}
+	return time.Duration(a.cfg.MetricProviderCfg.QueryTimeout) * time.Second
+}
+
+// sendSkippedMetrics tracks the job groups which were not evaluated, labelled with the reason code
+// so skips can be aggregated alongside scaling events.
+func sendSkippedMetrics(job, group string, reason state.Reason) {
+	sendMetrics.IncrCounterWithLabels([]string{"autoscale", "evaluation", "skipped"}, 1, []sendMetrics.Label{
+		{Name: "job", Value: job},
+		{Name: "group", Value: group},
+		{Name: "reason", Value: reason.String()},
+	})
 }
@@ -1,8 +1,21 @@
 package autoscale
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/ingress"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/policy"
+	policyMemory "github.com/jrasell/sherpa/pkg/policy/backend/memory"
+	"github.com/jrasell/sherpa/pkg/scale"
+	stateMemory "github.com/jrasell/sherpa/pkg/state/scale/memory"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -26,3 +39,63 @@ func TestAutoScale_createWorkerPool(t *testing.T) {
 		assert.Equal(t, tc.expectedThreads, pool.Cap(), tc.testName)
 	}
 }
+
+func TestAutoScale_registerMetricProvider(t *testing.T) {
+	a := &AutoScale{metricProvider: make(map[policy.MetricsProvider]metrics.Provider)}
+
+	traefik := ingress.NewTraefikClient("http://127.0.0.1:8080", zerolog.Nop())
+	nginx := ingress.NewNGINXClient("http://127.0.0.1:10254", zerolog.Nop())
+	a.registerMetricProvider(traefik)
+	a.registerMetricProvider(nginx)
+
+	assert.Equal(t, map[policy.MetricsProvider]metrics.Provider{
+		policy.ProviderTraefik: traefik,
+		policy.ProviderNGINX:   nginx,
+	}, a.metricProvider)
+}
+
+func TestAutoScale_runScalingLoopWaitsForEvaluations(t *testing.T) {
+	evalDelay := 200 * time.Millisecond
+
+	// Each evaluation reads the job from Nomad, which is slow to respond.
+	var completed int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		time.Sleep(evalDelay)
+		atomic.AddInt32(&completed, 1)
+		http.Error(w, "job not found", http.StatusNotFound)
+	}))
+	defer srv.Close()
+
+	nomadPool, err := client.NewNomadPool(zerolog.Nop(), []string{srv.URL}, 0)
+	assert.Nil(t, err)
+
+	policies := policyMemory.NewJobScalingPolicies()
+	assert.Nil(t, policies.PutJobPolicy(context.Background(), "example", map[string]*policy.GroupScalingPolicy{"cache": {Enabled: true}}))
+	assert.Nil(t, policies.PutJobPolicy(context.Background(), "batch", map[string]*policy.GroupScalingPolicy{"cache": {Enabled: true}}))
+
+	a := &AutoScale{
+		cfg:           &Config{ScalingThreads: 2, EvaluationTimeout: 5, NomadTimeout: 5},
+		logger:        zerolog.Nop(),
+		nomad:         nomadPool,
+		policyBackend: policies,
+		scaler:        scale.NewScaler(nil, zerolog.Nop(), stateMemory.NewStateBackend(), false, 0, nil),
+		decisions:     newDecisionTracker(),
+		staleness:     newStalenessTracker(),
+		orphans:       newOrphanTracker(),
+		overrides:     newMetricOverrides(),
+		doneChan:      make(chan struct{}),
+	}
+
+	a.pool, err = a.createWorkerPool()
+	assert.Nil(t, err)
+	defer a.pool.Release()
+
+	// The run only returns once the dispatched evaluations have completed, so that the elapsed
+	// time of the run used to schedule the next includes them.
+	start := time.Now()
+	a.runScalingLoop()
+
+	assert.True(t, time.Since(start) >= evalDelay)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&completed))
+	assert.False(t, a.inProgress)
+}
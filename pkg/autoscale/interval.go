@@ -0,0 +1,17 @@
+package autoscale
+
+import "time"
+
+// nextRunDelay returns the time to wait before starting the next autoscaling run, given how long
+// the previous run took. If the run overran the interval, the next run starts immediately rather
+// than waiting for the next aligned tick, and the number of interval ticks missed by the overrun
+// is returned.
+func nextRunDelay(elapsed, interval time.Duration) (time.Duration, int) {
+	if interval <= 0 {
+		return 0, 0
+	}
+	if elapsed < interval {
+		return interval - elapsed, 0
+	}
+	return 0, int(elapsed / interval)
+}
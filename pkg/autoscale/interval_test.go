@@ -0,0 +1,53 @@
+package autoscale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_nextRunDelay(t *testing.T) {
+	testCases := []struct {
+		name           string
+		elapsed        time.Duration
+		interval       time.Duration
+		expectedDelay  time.Duration
+		expectedMissed int
+	}{
+		{
+			name:           "run within interval",
+			elapsed:        10 * time.Second,
+			interval:       60 * time.Second,
+			expectedDelay:  50 * time.Second,
+			expectedMissed: 0,
+		},
+		{
+			name:           "run equal to interval",
+			elapsed:        60 * time.Second,
+			interval:       60 * time.Second,
+			expectedDelay:  0,
+			expectedMissed: 1,
+		},
+		{
+			name:           "run overran multiple intervals",
+			elapsed:        150 * time.Second,
+			interval:       60 * time.Second,
+			expectedDelay:  0,
+			expectedMissed: 2,
+		},
+		{
+			name:           "zero interval",
+			elapsed:        time.Second,
+			interval:       0,
+			expectedDelay:  0,
+			expectedMissed: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		delay, missed := nextRunDelay(tc.elapsed, tc.interval)
+		assert.Equal(t, tc.expectedDelay, delay, tc.name)
+		assert.Equal(t, tc.expectedMissed, missed, tc.name)
+	}
+}
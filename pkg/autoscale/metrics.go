@@ -15,3 +15,10 @@ func sendTriggerSuccessMetrics(job string) {
 	metrics.IncrCounter([]string{"autoscale", "trigger", "success"}, 1)
 	metrics.IncrCounter([]string{"autoscale", job, "trigger", "success"}, 1)
 }
+
+// sendDryRunMetrics is a helper to track autoscaling scale triggers which were skipped as the
+// autoscaler is running in dry-run mode.
+func sendDryRunMetrics(job string) {
+	metrics.IncrCounter([]string{"autoscale", "trigger", "dry_run"}, 1)
+	metrics.IncrCounter([]string{"autoscale", job, "trigger", "dry_run"}, 1)
+}
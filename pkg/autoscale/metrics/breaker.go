@@ -0,0 +1,200 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/retry"
+	"github.com/pkg/errors"
+)
+
+// ErrInsufficientData is returned by providers which calculate values across multiple samples, and
+// have not yet gathered enough to do so. It is not considered a provider failure.
+var ErrInsufficientData = errors.New("awaiting further samples to calculate value")
+
+// ErrCircuitOpen is returned when a provider is called while its circuit breaker is open.
+var ErrCircuitOpen = errors.New("provider circuit breaker is open")
+
+// ProviderState describes the circuit breaker state of a metric provider.
+type ProviderState string
+
+const (
+	// ProviderStateClosed indicates the provider is healthy and queries are being performed.
+	ProviderStateClosed ProviderState = "closed"
+
+	// ProviderStateOpen indicates the provider error rate exceeded the threshold and queries are
+	// not being performed.
+	ProviderStateOpen ProviderState = "open"
+
+	// ProviderStateHalfOpen indicates the cooldown has passed and the next query will determine
+	// whether the breaker closes or reopens.
+	ProviderStateHalfOpen ProviderState = "half-open"
+)
+
+// BreakerConfig controls when the circuit breaker of a provider opens and closes.
+type BreakerConfig struct {
+
+	// ErrorThreshold is the percentage of failed queries within the window which opens the
+	// breaker. A value of zero disables the breaker.
+	ErrorThreshold float64
+
+	// Window is the number of most recent queries used to calculate the error rate. The breaker
+	// will not open until the window is full.
+	Window int
+
+	// Cooldown is the time the breaker remains open before allowing a trial query.
+	Cooldown time.Duration
+
+	// Retry is the policy used to retry failed queries. Only the result of the final attempt is
+	// tracked by the breaker.
+	Retry retry.Policy
+}
+
+// ProviderStatus details the recent health of a metric provider.
+type ProviderStatus struct {
+	Name            string
+	State           ProviderState
+	Reachable       bool
+	ErrorRate       float64
+	Queries         int
+	Errors          int
+	LastError       string `json:",omitempty"`
+	LastErrorTime   int64  `json:",omitempty"`
+	LastSuccessTime int64  `json:",omitempty"`
+	OpenedTime      int64  `json:",omitempty"`
+}
+
+// BreakerProvider wraps a Provider, tracking the results of queries in order to report provider
+// health and stop querying providers which are consistently failing.
+type BreakerProvider struct {
+	provider Provider
+	cfg      *BreakerConfig
+	now      func() time.Time
+
+	lock    sync.Mutex
+	results []bool
+	status  ProviderStatus
+}
+
+// NewBreakerProvider wraps the provider using the passed breaker config.
+func NewBreakerProvider(name string, p Provider, cfg *BreakerConfig) *BreakerProvider {
+	return &BreakerProvider{
+		provider: p,
+		cfg:      cfg,
+		now:      time.Now,
+		status:   ProviderStatus{Name: name, State: ProviderStateClosed},
+	}
+}
+
+// Name satisfies the Name function of the Provider interface, returning the name the breaker
+// reports the provider status under.
+func (b *BreakerProvider) Name() string { return b.status.Name }
+
+// GetValue satisfies the GetValue function of the Provider interface.
+func (b *BreakerProvider) GetValue(ctx context.Context, query string) (*float64, error) {
+	if !b.allow() {
+		return nil, ErrCircuitOpen
+	}
+
+	var value *float64
+
+	err := retry.Do(ctx, retry.TargetMetrics, b.cfg.Retry, func() (err error) {
+		value, err = b.provider.GetValue(ctx, query)
+		if errors.Cause(err) == ErrInsufficientData {
+			return retry.Permanent(err)
+		}
+		return err
+	})
+	b.record(err)
+	return value, err
+}
+
+// Status returns the current status of the provider.
+func (b *BreakerProvider) Status() ProviderStatus {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	return b.status
+}
+
+// allow determines whether a query should be performed, moving an open breaker to half-open once
+// the cooldown has passed.
+func (b *BreakerProvider) allow() bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	if b.status.State != ProviderStateOpen {
+		return true
+	}
+
+	if b.now().Sub(time.Unix(0, b.status.OpenedTime)) < b.cfg.Cooldown {
+		return false
+	}
+	b.status.State = ProviderStateHalfOpen
+	return true
+}
+
+// record updates the provider status and breaker state using the result of a query.
+func (b *BreakerProvider) record(err error) {
+
+	// Providers awaiting further samples have not failed, so this result is not tracked.
+	if errors.Cause(err) == ErrInsufficientData {
+		return
+	}
+
+	b.lock.Lock()
+	defer b.lock.Unlock()
+
+	now := b.now().UnixNano()
+
+	if err != nil {
+		b.status.LastError = err.Error()
+		b.status.LastErrorTime = now
+	} else {
+		b.status.LastSuccessTime = now
+	}
+	b.status.Reachable = err == nil
+
+	// A half-open breaker closes with fresh results on success, and reopens on failure.
+	if b.status.State == ProviderStateHalfOpen {
+		if err != nil {
+			b.open(now)
+			return
+		}
+		b.status.State = ProviderStateClosed
+		b.status.OpenedTime = 0
+		b.results = nil
+	}
+
+	b.results = append(b.results, err == nil)
+	if b.cfg.Window > 0 && len(b.results) > b.cfg.Window {
+		b.results = b.results[len(b.results)-b.cfg.Window:]
+	}
+	b.updateErrorRate()
+
+	if b.cfg.ErrorThreshold > 0 && len(b.results) >= b.cfg.Window && b.status.ErrorRate >= b.cfg.ErrorThreshold {
+		b.open(now)
+	}
+}
+
+func (b *BreakerProvider) open(now int64) {
+	b.status.State = ProviderStateOpen
+	b.status.OpenedTime = now
+}
+
+func (b *BreakerProvider) updateErrorRate() {
+	var failed int
+
+	for _, ok := range b.results {
+		if !ok {
+			failed++
+		}
+	}
+
+	b.status.Queries = len(b.results)
+	b.status.Errors = failed
+	b.status.ErrorRate = 0
+	if len(b.results) > 0 {
+		b.status.ErrorRate = float64(failed) / float64(len(b.results)) * 100
+	}
+}
@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/retry"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeProvider struct {
+	err   error
+	calls int
+}
+
+func (f *fakeProvider) Name() string { return "fake" }
+
+func (f *fakeProvider) GetValue(_ context.Context, _ string) (*float64, error) {
+	f.calls++
+	if f.err != nil {
+		return nil, f.err
+	}
+	val := float64(1)
+	return &val, nil
+}
+
+func TestBreakerProvider(t *testing.T) {
+	now := time.Unix(1000, 0)
+	fake := &fakeProvider{}
+
+	b := NewBreakerProvider("prometheus", fake, &BreakerConfig{ErrorThreshold: 50, Window: 4, Cooldown: time.Minute})
+	b.now = func() time.Time { return now }
+
+	// Insufficient data errors are not tracked against the provider.
+	fake.err = ErrInsufficientData
+	_, _ = b.GetValue(context.Background(), "q")
+	assert.Equal(t, 0, b.Status().Queries)
+
+	// A healthy provider with a partially failing window remains closed.
+	fake.err = nil
+	_, _ = b.GetValue(context.Background(), "q")
+	_, _ = b.GetValue(context.Background(), "q")
+	fake.err = errors.New("connection refused")
+	_, _ = b.GetValue(context.Background(), "q")
+
+	status := b.Status()
+	assert.Equal(t, ProviderStateClosed, status.State)
+	assert.Equal(t, 3, status.Queries)
+	assert.False(t, status.Reachable)
+	assert.Equal(t, "connection refused", status.LastError)
+
+	// Once the window is full and the error rate reaches the threshold, the breaker opens and
+	// queries are not performed.
+	_, _ = b.GetValue(context.Background(), "q")
+	status = b.Status()
+	assert.Equal(t, ProviderStateOpen, status.State)
+	assert.Equal(t, float64(50), status.ErrorRate)
+
+	_, err := b.GetValue(context.Background(), "q")
+	assert.Equal(t, ErrCircuitOpen, err)
+
+	// After the cooldown, a failed trial query reopens the breaker.
+	now = now.Add(2 * time.Minute)
+	_, err = b.GetValue(context.Background(), "q")
+	assert.EqualError(t, err, "connection refused")
+	assert.Equal(t, ProviderStateOpen, b.Status().State)
+
+	// A successful trial query closes the breaker and resets the window.
+	now = now.Add(2 * time.Minute)
+	fake.err = nil
+	_, err = b.GetValue(context.Background(), "q")
+	assert.Nil(t, err)
+
+	status = b.Status()
+	assert.Equal(t, ProviderStateClosed, status.State)
+	assert.Equal(t, 1, status.Queries)
+	assert.Equal(t, float64(0), status.ErrorRate)
+	assert.True(t, status.Reachable)
+}
+
+func TestBreakerProvider_disabled(t *testing.T) {
+	b := NewBreakerProvider("prometheus", &fakeProvider{err: errors.New("timeout")}, &BreakerConfig{Window: 2})
+
+	for i := 0; i < 5; i++ {
+		_, _ = b.GetValue(context.Background(), "q")
+	}
+
+	status := b.Status()
+	assert.Equal(t, ProviderStateClosed, status.State)
+	assert.Equal(t, float64(100), status.ErrorRate)
+	assert.Equal(t, 2, status.Queries)
+}
+
+func TestBreakerProvider_retry(t *testing.T) {
+	fake := &fakeProvider{err: errors.New("timeout")}
+	cfg := &BreakerConfig{Window: 2, Retry: retry.Policy{Attempts: 3, BaseDelay: time.Millisecond}}
+	b := NewBreakerProvider("prometheus", fake, cfg)
+
+	// Only the final attempt of the retried query is tracked by the breaker.
+	_, err := b.GetValue(context.Background(), "q")
+	assert.NotNil(t, err)
+	assert.Equal(t, 3, fake.calls)
+	assert.Equal(t, 1, b.Status().Queries)
+
+	// Providers awaiting further samples are not retried.
+	fake.calls, fake.err = 0, ErrInsufficientData
+	_, err = b.GetValue(context.Background(), "q")
+	assert.Equal(t, ErrInsufficientData, err)
+	assert.Equal(t, 1, fake.calls)
+}
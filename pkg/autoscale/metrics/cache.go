@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+
+	sendMetrics "github.com/armon/go-metrics"
+)
+
+// CachingProvider wraps a Provider, caching the result of each query until the cache is reset.
+// The autoscaler resets the cache at the start of each scaling run, so that policies which share a
+// query only cause a single provider request per run. Concurrent calls for the same query wait on
+// the single in-flight request rather than performing their own.
+type CachingProvider struct {
+	name     string
+	provider Provider
+
+	lock    sync.Mutex
+	entries map[string]*cacheEntry
+}
+
+// cacheEntry is the result of a single query. The done channel is closed once the value and error
+// have been set.
+type cacheEntry struct {
+	done  chan struct{}
+	value *float64
+	err   error
+}
+
+// NewCachingProvider wraps the provider with a query cache.
+func NewCachingProvider(name string, p Provider) *CachingProvider {
+	return &CachingProvider{
+		name:     name,
+		provider: p,
+		entries:  make(map[string]*cacheEntry),
+	}
+}
+
+// Name satisfies the Name function of the Provider interface.
+func (c *CachingProvider) Name() string { return c.name }
+
+// GetValue satisfies the GetValue function of the Provider interface. Failed queries are shared
+// with the calls waiting on them, but are not cached so that later calls perform the query again.
+func (c *CachingProvider) GetValue(ctx context.Context, query string) (*float64, error) {
+	c.lock.Lock()
+
+	if e, ok := c.entries[query]; ok {
+		c.lock.Unlock()
+
+		select {
+		case <-e.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+
+		sendMetrics.IncrCounterWithLabels([]string{"autoscale", "provider_cache", "hit"}, 1,
+			[]sendMetrics.Label{{Name: "provider", Value: c.name}})
+		return copyValue(e.value), e.err
+	}
+
+	e := &cacheEntry{done: make(chan struct{})}
+	c.entries[query] = e
+	c.lock.Unlock()
+
+	sendMetrics.IncrCounterWithLabels([]string{"autoscale", "provider_cache", "miss"}, 1,
+		[]sendMetrics.Label{{Name: "provider", Value: c.name}})
+
+	e.value, e.err = c.provider.GetValue(ctx, query)
+
+	if e.err != nil {
+		c.lock.Lock()
+		if c.entries[query] == e {
+			delete(c.entries, query)
+		}
+		c.lock.Unlock()
+	}
+	close(e.done)
+
+	return copyValue(e.value), e.err
+}
+
+// Reset removes all cached query results. Queries in-flight during the reset complete as normal,
+// but their results are not available to subsequent calls.
+func (c *CachingProvider) Reset() {
+	c.lock.Lock()
+	c.entries = make(map[string]*cacheEntry)
+	c.lock.Unlock()
+}
+
+// copyValue returns a copy of the value, so callers sharing a cached result cannot modify it.
+func copyValue(v *float64) *float64 {
+	if v == nil {
+		return nil
+	}
+	out := *v
+	return &out
+}
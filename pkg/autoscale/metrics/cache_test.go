@@ -0,0 +1,97 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCachingProvider(t *testing.T) {
+	fake := &fakeProvider{}
+	c := NewCachingProvider("prometheus", fake)
+
+	// Repeated queries within a run are only performed once.
+	for i := 0; i < 3; i++ {
+		val, err := c.GetValue(context.Background(), "q")
+		assert.Nil(t, err)
+		assert.Equal(t, float64(1), *val)
+	}
+	assert.Equal(t, 1, fake.calls)
+
+	// A different query is performed separately.
+	_, _ = c.GetValue(context.Background(), "other")
+	assert.Equal(t, 2, fake.calls)
+
+	// Resetting the cache causes the query to be performed again.
+	c.Reset()
+	_, _ = c.GetValue(context.Background(), "q")
+	assert.Equal(t, 3, fake.calls)
+
+	// Failed queries are not cached.
+	c.Reset()
+	fake.err = errors.New("boom")
+	_, err := c.GetValue(context.Background(), "q")
+	assert.NotNil(t, err)
+	_, err = c.GetValue(context.Background(), "q")
+	assert.NotNil(t, err)
+	assert.Equal(t, 5, fake.calls)
+}
+
+type blockingProvider struct {
+	lock    sync.Mutex
+	calls   int
+	release chan struct{}
+}
+
+func (b *blockingProvider) Name() string { return "blocking" }
+
+func (b *blockingProvider) GetValue(_ context.Context, _ string) (*float64, error) {
+	b.lock.Lock()
+	b.calls++
+	b.lock.Unlock()
+
+	<-b.release
+	val := float64(2)
+	return &val, nil
+}
+
+func TestCachingProvider_concurrent(t *testing.T) {
+	p := &blockingProvider{release: make(chan struct{})}
+	c := NewCachingProvider("prometheus", p)
+
+	var wg sync.WaitGroup
+	results := make([]*float64, 5)
+
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			results[i], _ = c.GetValue(context.Background(), "q")
+		}(i)
+	}
+
+	time.Sleep(50 * time.Millisecond)
+	close(p.release)
+	wg.Wait()
+
+	assert.Equal(t, 1, p.calls)
+	for _, r := range results {
+		assert.Equal(t, float64(2), *r)
+	}
+
+	// Callers waiting on an in-flight query return once their context is cancelled.
+	c.Reset()
+	p.release = make(chan struct{})
+	go func() { _, _ = c.GetValue(context.Background(), "q") }()
+	time.Sleep(50 * time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err := c.GetValue(ctx, "q")
+	assert.Equal(t, context.Canceled, err)
+	close(p.release)
+}
@@ -0,0 +1,141 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// queryEndpoint is the Datadog API endpoint used to query timeseries metric values.
+	queryEndpoint = "/api/v1/query"
+
+	// queryWindow is how far back from the current time each query reads points. The latest point
+	// within the window is used as the metric value.
+	queryWindow = 5 * time.Minute
+)
+
+// queryResponse is the subset of the Datadog timeseries query response used by the provider.
+type queryResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error"`
+	Series []struct {
+		Pointlist [][]*float64 `json:"pointlist"`
+	} `json:"series"`
+}
+
+// Client is a Datadog metrics backend which runs metric queries using the timeseries query API.
+type Client struct {
+	addr       string
+	apiKey     *secret.Value
+	appKey     *secret.Value
+	httpClient *http.Client
+	logger     zerolog.Logger
+}
+
+// NewClient builds the Datadog metric provider. The API and application keys are resolved before
+// each query, so that rotated keys are used.
+func NewClient(addr string, apiKey, appKey *secret.Value, log zerolog.Logger) metrics.Provider {
+	return &Client{
+		addr:       addr,
+		apiKey:     apiKey,
+		appKey:     appKey,
+		httpClient: cleanhttp.DefaultClient(),
+		logger:     log.With().Str("metric-provider", policy.ProviderDatadog.String()).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return policy.ProviderDatadog.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
+	defer sendMetrics.MeasureSince([]string{"autoscale", "datadog", "get_value"}, time.Now())
+
+	value, err := c.getValue(ctx, query, time.Now())
+	if err != nil {
+		sendMetrics.IncrCounter([]string{"autoscale", "datadog", "error"}, 1)
+	} else {
+		sendMetrics.IncrCounter([]string{"autoscale", "datadog", "success"}, 1)
+	}
+	return value, err
+}
+
+func (c *Client) getValue(ctx context.Context, query string, now time.Time) (*float64, error) {
+	apiKey, err := c.apiKey.Get(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve Datadog API key")
+	}
+
+	appKey, err := c.appKey.Get(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve Datadog application key")
+	}
+
+	params := url.Values{}
+	params.Set("query", query)
+	params.Set("from", strconv.FormatInt(now.Add(-queryWindow).Unix(), 10))
+	params.Set("to", strconv.FormatInt(now.Unix(), 10))
+
+	req, err := http.NewRequest(http.MethodGet, c.addr+queryEndpoint+"?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("DD-API-KEY", apiKey)
+	req.Header.Set("DD-APPLICATION-KEY", appKey)
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response code %v from Datadog query API", resp.StatusCode)
+	}
+
+	var result queryResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "failed to decode Datadog query response")
+	}
+
+	value, err := result.value()
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug().Str("query", query).Msg("successfully ran Datadog metric query")
+
+	return helper.Float64ToPointer(value), nil
+}
+
+// value returns the latest non-null point of the single series returned by the query. Queries
+// should therefore aggregate to a single series, such as by using sum:, avg: or max: without a
+// by{} clause.
+func (r *queryResponse) value() (float64, error) {
+	if r.Status == "error" {
+		return 0, errors.Errorf("Datadog metric query failed: %s", r.Error)
+	}
+	if len(r.Series) != 1 {
+		return 0, errors.Errorf("Datadog metric query returned %v series, expected 1", len(r.Series))
+	}
+
+	points := r.Series[0].Pointlist
+	for i := len(points) - 1; i >= 0; i-- {
+		if len(points[i]) == 2 && points[i][1] != nil {
+			return *points[i][1], nil
+		}
+	}
+	return 0, errors.New("Datadog metric query returned no points")
+}
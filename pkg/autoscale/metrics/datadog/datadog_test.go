@@ -0,0 +1,89 @@
+package datadog
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_queryResponseValue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedValue float64
+		expectError   bool
+	}{
+		{
+			name:          "latest point",
+			input:         `{"status":"ok","series":[{"pointlist":[[1580032740000,10],[1580032800000,12.5]]}]}`,
+			expectedValue: 12.5,
+		},
+		{
+			name:          "trailing null point",
+			input:         `{"status":"ok","series":[{"pointlist":[[1580032740000,10],[1580032800000,null]]}]}`,
+			expectedValue: 10,
+		},
+		{
+			name:        "query error",
+			input:       `{"status":"error","error":"Rule 'scope_expr' didn't match"}`,
+			expectError: true,
+		},
+		{
+			name:        "no series",
+			input:       `{"status":"ok","series":[]}`,
+			expectError: true,
+		},
+		{
+			name:        "multiple series",
+			input:       `{"status":"ok","series":[{"pointlist":[[1,1]]},{"pointlist":[[1,2]]}]}`,
+			expectError: true,
+		},
+		{
+			name:        "no points",
+			input:       `{"status":"ok","series":[{"pointlist":[[1580032800000,null]]}]}`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp queryResponse
+			assert.Nil(t, json.Unmarshal([]byte(tc.input), &resp))
+
+			value, err := resp.value()
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
+
+func TestClient_getValue(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/query", r.URL.Path)
+		assert.Equal(t, "api-key", r.Header.Get("DD-API-KEY"))
+		assert.Equal(t, "app-key", r.Header.Get("DD-APPLICATION-KEY"))
+		assert.Equal(t, "avg:trace.http.request.duration{service:web}", r.URL.Query().Get("query"))
+		assert.Equal(t, "1580032500", r.URL.Query().Get("from"))
+		assert.Equal(t, "1580032800", r.URL.Query().Get("to"))
+		_, _ = w.Write([]byte(`{"status":"ok","series":[{"pointlist":[[1580032800000,0.25]]}]}`))
+	}))
+	defer srv.Close()
+
+	resolver := secret.NewResolver(secret.Config{}, zerolog.Nop())
+	c := NewClient(srv.URL, resolver.Value("api-key"), resolver.Value("app-key"), zerolog.Nop()).(*Client)
+
+	value, err := c.getValue(context.Background(), "avg:trace.http.request.duration{service:web}", time.Unix(1580032800, 0))
+	assert.Nil(t, err)
+	assert.Equal(t, 0.25, *value)
+}
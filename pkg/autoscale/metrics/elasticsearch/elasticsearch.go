@@ -0,0 +1,146 @@
+package elasticsearch
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// valueAggregation is the name of the aggregation whose value is returned by the provider. If the
+// search does not include this aggregation, the total number of hits is used.
+const valueAggregation = "value"
+
+// searchResponse is the subset of the search API response used by the provider.
+type searchResponse struct {
+	Hits struct {
+		Total json.RawMessage `json:"total"`
+	} `json:"hits"`
+	Aggregations map[string]struct {
+		Value *float64 `json:"value"`
+	} `json:"aggregations"`
+}
+
+// Client is an Elasticsearch metrics backend which runs searches, using either the total hits or
+// the result of a metric aggregation as the metric value.
+type Client struct {
+	addr       string
+	httpClient *http.Client
+	logger     zerolog.Logger
+}
+
+// NewClient builds the Elasticsearch metric provider using the cluster at the passed address.
+// Credentials can be supplied within the address userinfo.
+func NewClient(addr string, log zerolog.Logger) metrics.Provider {
+	return &Client{
+		addr:       strings.TrimSuffix(addr, "/"),
+		httpClient: cleanhttp.DefaultClient(),
+		logger:     log.With().Str("metric-provider", policy.ProviderElasticsearch.String()).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return policy.ProviderElasticsearch.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
+	defer sendMetrics.MeasureSince([]string{"autoscale", "elasticsearch", "get_value"}, time.Now())
+
+	value, err := c.getValue(ctx, query)
+	if err != nil {
+		sendMetrics.IncrCounter([]string{"autoscale", "elasticsearch", "error"}, 1)
+	} else {
+		sendMetrics.IncrCounter([]string{"autoscale", "elasticsearch", "success"}, 1)
+	}
+	return value, err
+}
+
+func (c *Client) getValue(ctx context.Context, query string) (*float64, error) {
+	index, body, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.addr+"/"+url.PathEscape(index)+"/_search", bytes.NewBufferString(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response code %v from Elasticsearch search API", resp.StatusCode)
+	}
+
+	var result searchResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "failed to decode Elasticsearch search response")
+	}
+
+	value, err := result.value()
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug().Str("index", index).Msg("successfully ran Elasticsearch search")
+
+	return helper.Float64ToPointer(value), nil
+}
+
+// value returns the value of the named aggregation if present, otherwise the total hits. The hits
+// total is a number prior to Elasticsearch 7, and an object containing the value after.
+func (s *searchResponse) value() (float64, error) {
+	if agg, ok := s.Aggregations[valueAggregation]; ok {
+		if agg.Value == nil {
+			return 0, errors.Errorf("Elasticsearch aggregation %s returned a null value", valueAggregation)
+		}
+		return *agg.Value, nil
+	}
+
+	if len(s.Hits.Total) == 0 {
+		return 0, errors.New("Elasticsearch search response does not include hits total")
+	}
+
+	var total float64
+	if err := json.Unmarshal(s.Hits.Total, &total); err == nil {
+		return total, nil
+	}
+
+	var totalObj struct {
+		Value float64 `json:"value"`
+	}
+	if err := json.Unmarshal(s.Hits.Total, &totalObj); err != nil {
+		return 0, errors.Wrap(err, "failed to decode Elasticsearch hits total")
+	}
+	return totalObj.Value, nil
+}
+
+// parseQuery splits the query into the index and the search request body. The query takes the form
+// <index>/<body> where the body is the JSON search request.
+func parseQuery(query string) (string, string, error) {
+	idx := strings.Index(query, "/{")
+	if idx < 1 {
+		return "", "", errors.Errorf("invalid Elasticsearch query %q, expected <index>/<body>", query)
+	}
+
+	body := query[idx+1:]
+	if !json.Valid([]byte(body)) {
+		return "", "", errors.New("Elasticsearch query body is not valid JSON")
+	}
+	return query[:idx], body, nil
+}
@@ -0,0 +1,91 @@
+package elasticsearch
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expectedIndex string
+		expectedBody  string
+		expectError   bool
+	}{
+		{
+			name:          "index pattern",
+			query:         `logs-*/{"size":0,"query":{"match":{"level":"error"}}}`,
+			expectedIndex: "logs-*",
+			expectedBody:  `{"size":0,"query":{"match":{"level":"error"}}}`,
+		},
+		{name: "missing index", query: `/{"size":0}`, expectError: true},
+		{name: "missing body", query: "logs-*", expectError: true},
+		{name: "invalid body", query: `logs-*/{"size":`, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			index, body, err := parseQuery(tc.query)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedIndex, index)
+			assert.Equal(t, tc.expectedBody, body)
+		})
+	}
+}
+
+func Test_searchResponseValue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedValue float64
+		expectError   bool
+	}{
+		{
+			name:          "aggregation value",
+			input:         `{"hits":{"total":{"value":500}},"aggregations":{"value":{"value":123.4}}}`,
+			expectedValue: 123.4,
+		},
+		{
+			name:        "null aggregation value",
+			input:       `{"hits":{"total":{"value":0}},"aggregations":{"value":{"value":null}}}`,
+			expectError: true,
+		},
+		{
+			name:          "hits total object",
+			input:         `{"hits":{"total":{"value":42,"relation":"eq"}}}`,
+			expectedValue: 42,
+		},
+		{
+			name:          "hits total number",
+			input:         `{"hits":{"total":17}}`,
+			expectedValue: 17,
+		},
+		{
+			name:        "missing hits total",
+			input:       `{"hits":{}}`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp searchResponse
+			assert.Nil(t, json.Unmarshal([]byte(tc.input), &resp))
+
+			value, err := resp.value()
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
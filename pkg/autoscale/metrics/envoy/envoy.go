@@ -0,0 +1,192 @@
+package envoy
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	consul "github.com/hashicorp/consul/api"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/scrape"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// proxyConfigPrometheusBindAddr is the Consul Connect proxy config key which configures the
+	// Envoy Prometheus metrics listener.
+	proxyConfigPrometheusBindAddr = "envoy_prometheus_bind_addr"
+
+	// publicListenerPrefix is the prefix of the Envoy HTTP connection manager stats for the inbound
+	// public listener which Consul configures on each sidecar proxy.
+	publicListenerPrefix = "public_listener"
+
+	// localAppCluster is the Envoy cluster name Consul uses to route inbound traffic to the local
+	// application.
+	localAppCluster = "local_app"
+)
+
+// Supported Envoy query metrics.
+const (
+	metricRequestRate     = "request-rate"
+	metricActiveRequests  = "active-requests"
+	metricUpstreamLatency = "upstream-latency"
+)
+
+// Client is an Envoy metrics backend which discovers Consul Connect sidecar proxies for a service
+// and scrapes their Prometheus metrics endpoint.
+type Client struct {
+	consul  *consul.Client
+	scraper *scrape.Scraper
+	samples *scrape.SampleStore
+	logger  zerolog.Logger
+}
+
+// NewClient builds the Envoy metric provider which uses the passed Consul client for proxy
+// discovery.
+func NewClient(c *consul.Client, log zerolog.Logger) metrics.Provider {
+	return &Client{
+		consul:  c,
+		scraper: scrape.NewScraper(),
+		samples: scrape.NewSampleStore(),
+		logger:  log.With().Str("metric-provider", policy.ProviderEnvoy.String()).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return policy.ProviderEnvoy.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
+	defer sendMetrics.MeasureSince([]string{"autoscale", "envoy", "get_value"}, time.Now())
+
+	value, err := c.getValue(ctx, query)
+	if err != nil {
+		sendMetrics.IncrCounter([]string{"autoscale", "envoy", "error"}, 1)
+	} else {
+		sendMetrics.IncrCounter([]string{"autoscale", "envoy", "success"}, 1)
+	}
+	return value, err
+}
+
+func (c *Client) getValue(ctx context.Context, query string) (*float64, error) {
+	service, metric, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	families, err := c.scrapeService(ctx, service)
+	if err != nil {
+		return nil, err
+	}
+
+	var value float64
+
+	switch metric {
+	case metricActiveRequests:
+		value = scrape.SumGauges(scrape.Filter(families, "envoy_http_downstream_rq_active",
+			"envoy_http_conn_manager_prefix", isPublicListener))
+	case metricRequestRate:
+		total := scrape.SumCounters(scrape.Filter(families, "envoy_http_downstream_rq_total",
+			"envoy_http_conn_manager_prefix", isPublicListener))
+		value, err = c.samples.Rate(query, &scrape.Sample{Time: time.Now(), Sum: total})
+	default:
+		sum, count := sumUpstreamRequestTime(families)
+		value, err = c.samples.Average(query, &scrape.Sample{Time: time.Now(), Sum: sum, Count: count})
+	}
+
+	if err != nil {
+		return nil, err
+	}
+	return helper.Float64ToPointer(value), nil
+}
+
+// parseQuery splits the query into the Consul service name and the Envoy metric. The query takes
+// the form <service>/<metric>.
+func parseQuery(query string) (string, string, error) {
+	parts := strings.Split(query, "/")
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", errors.Errorf("invalid Envoy query %q, expected <service>/<metric>", query)
+	}
+
+	switch parts[1] {
+	case metricRequestRate, metricActiveRequests, metricUpstreamLatency:
+		return parts[0], parts[1], nil
+	default:
+		return "", "", errors.Errorf("unsupported Envoy metric %q", parts[1])
+	}
+}
+
+// scrapeService discovers the healthy Connect proxies for the service and scrapes each, returning
+// the metric families from all proxies.
+func (c *Client) scrapeService(ctx context.Context, service string) ([]scrape.Families, error) {
+	entries, _, err := c.consul.Health().Connect(service, "", true, (&consul.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to discover Connect proxies")
+	}
+
+	var out []scrape.Families // nolint:prealloc
+
+	for _, entry := range entries {
+		addr, err := proxyMetricsAddr(entry)
+		if err != nil {
+			c.logger.Warn().Err(err).Str("service", service).Msg("skipping Connect proxy")
+			continue
+		}
+
+		families, err := c.scraper.Scrape(ctx, addr)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, families)
+	}
+
+	if len(out) == 0 {
+		return nil, errors.Errorf("no Connect proxies with metrics found for service %s", service)
+	}
+	return out, nil
+}
+
+// proxyMetricsAddr builds the address of the Envoy Prometheus listener for the proxy.
+func proxyMetricsAddr(entry *consul.ServiceEntry) (string, error) {
+	if entry.Service == nil || entry.Service.Proxy == nil {
+		return "", errors.New("service entry has no proxy configuration")
+	}
+
+	bind, ok := entry.Service.Proxy.Config[proxyConfigPrometheusBindAddr].(string)
+	if !ok || bind == "" {
+		return "", errors.Errorf("proxy config does not include %s", proxyConfigPrometheusBindAddr)
+	}
+
+	_, port, err := net.SplitHostPort(bind)
+	if err != nil {
+		return "", err
+	}
+
+	host := entry.Service.Address
+	if host == "" && entry.Node != nil {
+		host = entry.Node.Address
+	}
+	return fmt.Sprintf("http://%s/metrics", net.JoinHostPort(host, port)), nil
+}
+
+// isPublicListener matches the Envoy HTTP connection manager of the inbound public listener.
+func isPublicListener(prefix string) bool { return strings.HasPrefix(prefix, publicListenerPrefix) }
+
+// isLocalApp matches the Envoy cluster which routes inbound traffic to the local application.
+func isLocalApp(cluster string) bool { return cluster == localAppCluster }
+
+func sumUpstreamRequestTime(families []scrape.Families) (float64, float64) {
+	var sum, count float64
+
+	for _, m := range scrape.Filter(families, "envoy_cluster_upstream_rq_time", "envoy_cluster_name", isLocalApp) {
+		sum += m.GetHistogram().GetSampleSum()
+		count += float64(m.GetHistogram().GetSampleCount())
+	}
+	return sum, count
+}
@@ -0,0 +1,85 @@
+package envoy
+
+import (
+	"strings"
+	"testing"
+
+	consul "github.com/hashicorp/consul/api"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/scrape"
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+)
+
+const testScrape = `# TYPE envoy_http_downstream_rq_total counter
+envoy_http_downstream_rq_total{envoy_http_conn_manager_prefix="public_listener_http"} 120
+envoy_http_downstream_rq_total{envoy_http_conn_manager_prefix="upstream_db_http"} 999
+# TYPE envoy_http_downstream_rq_active gauge
+envoy_http_downstream_rq_active{envoy_http_conn_manager_prefix="public_listener_http"} 7
+# TYPE envoy_cluster_upstream_rq_time histogram
+envoy_cluster_upstream_rq_time_bucket{envoy_cluster_name="local_app",le="+Inf"} 10
+envoy_cluster_upstream_rq_time_sum{envoy_cluster_name="local_app"} 250
+envoy_cluster_upstream_rq_time_count{envoy_cluster_name="local_app"} 10
+`
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		query           string
+		expectedService string
+		expectedMetric  string
+		expectError     bool
+	}{
+		{query: "web/request-rate", expectedService: "web", expectedMetric: metricRequestRate},
+		{query: "web/upstream-latency", expectedService: "web", expectedMetric: metricUpstreamLatency},
+		{query: "web/unknown", expectError: true},
+		{query: "/active-requests", expectError: true},
+		{query: "web", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		service, metric, err := parseQuery(tc.query)
+		if tc.expectError {
+			assert.NotNil(t, err, tc.query)
+			continue
+		}
+		assert.Nil(t, err, tc.query)
+		assert.Equal(t, tc.expectedService, service, tc.query)
+		assert.Equal(t, tc.expectedMetric, metric, tc.query)
+	}
+}
+
+func Test_metricAggregation(t *testing.T) {
+	var parser expfmt.TextParser
+
+	families, err := parser.TextToMetricFamilies(strings.NewReader(testScrape))
+	assert.Nil(t, err)
+
+	scrapes := []scrape.Families{families, families}
+
+	assert.Equal(t, float64(240), scrape.SumCounters(scrape.Filter(scrapes,
+		"envoy_http_downstream_rq_total", "envoy_http_conn_manager_prefix", isPublicListener)))
+	assert.Equal(t, float64(14), scrape.SumGauges(scrape.Filter(scrapes,
+		"envoy_http_downstream_rq_active", "envoy_http_conn_manager_prefix", isPublicListener)))
+
+	sum, count := sumUpstreamRequestTime(scrapes)
+	assert.Equal(t, float64(500), sum)
+	assert.Equal(t, float64(20), count)
+}
+
+func Test_proxyMetricsAddr(t *testing.T) {
+	entry := &consul.ServiceEntry{
+		Node: &consul.Node{Address: "10.0.0.2"},
+		Service: &consul.AgentService{
+			Proxy: &consul.AgentServiceConnectProxyConfig{
+				Config: map[string]interface{}{proxyConfigPrometheusBindAddr: "0.0.0.0:9102"},
+			},
+		},
+	}
+
+	addr, err := proxyMetricsAddr(entry)
+	assert.Nil(t, err)
+	assert.Equal(t, "http://10.0.0.2:9102/metrics", addr)
+
+	entry.Service.Proxy.Config = nil
+	_, err = proxyMetricsAddr(entry)
+	assert.NotNil(t, err)
+}
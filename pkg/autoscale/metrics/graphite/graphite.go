@@ -0,0 +1,136 @@
+package graphite
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// defaultFrom is the start of the render window used when the query does not specify one.
+const defaultFrom = "-5min"
+
+// series is a single series returned by the render API in JSON format. Each datapoint is a pair of
+// the value, which may be null, and the timestamp.
+type series struct {
+	Target     string        `json:"target"`
+	Datapoints [][2]*float64 `json:"datapoints"`
+}
+
+// Client is a Graphite metrics backend which evaluates target expressions using the render API.
+type Client struct {
+	addr       string
+	httpClient *http.Client
+	logger     zerolog.Logger
+}
+
+// NewClient builds the Graphite metric provider using the render API at the passed address.
+func NewClient(addr string, log zerolog.Logger) metrics.Provider {
+	return &Client{
+		addr:       strings.TrimSuffix(addr, "/"),
+		httpClient: cleanhttp.DefaultClient(),
+		logger:     log.With().Str("metric-provider", policy.ProviderGraphite.String()).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return policy.ProviderGraphite.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
+	defer sendMetrics.MeasureSince([]string{"autoscale", "graphite", "get_value"}, time.Now())
+
+	value, err := c.getValue(ctx, query)
+	if err != nil {
+		sendMetrics.IncrCounter([]string{"autoscale", "graphite", "error"}, 1)
+	} else {
+		sendMetrics.IncrCounter([]string{"autoscale", "graphite", "success"}, 1)
+	}
+	return value, err
+}
+
+func (c *Client) getValue(ctx context.Context, query string) (*float64, error) {
+	params, err := buildRenderParams(query)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.addr+"/render?"+params.Encode(), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response code %v from Graphite render API", resp.StatusCode)
+	}
+
+	var result []*series
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "failed to decode Graphite render response")
+	}
+
+	value, err := lastValue(result)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug().Str("target", params.Get("target")).Msg("successfully rendered Graphite target")
+
+	return helper.Float64ToPointer(value), nil
+}
+
+// buildRenderParams builds the render API parameters from the query. The query can either be a
+// target expression, or render API parameters such as target=<expr>&from=-10min which allow the
+// window to be controlled.
+func buildRenderParams(query string) (url.Values, error) {
+	params := url.Values{}
+
+	if strings.HasPrefix(query, "target=") {
+		var err error
+		if params, err = url.ParseQuery(query); err != nil {
+			return nil, errors.Wrap(err, "failed to parse Graphite query parameters")
+		}
+	} else {
+		params.Set("target", query)
+	}
+
+	if len(params["target"]) != 1 || params.Get("target") == "" {
+		return nil, errors.Errorf("invalid Graphite query %q, a single target is required", query)
+	}
+	if params.Get("from") == "" {
+		params.Set("from", defaultFrom)
+	}
+	params.Set("format", "json")
+
+	return params, nil
+}
+
+// lastValue returns the most recent non-null datapoint of the single series returned.
+func lastValue(result []*series) (float64, error) {
+	if len(result) != 1 {
+		return 0, errors.Errorf("Graphite target returned %v series, expected 1", len(result))
+	}
+
+	points := result[0].Datapoints
+	for i := len(points) - 1; i >= 0; i-- {
+		if points[i][0] != nil {
+			return *points[i][0], nil
+		}
+	}
+	return 0, errors.Errorf("Graphite series %s has no datapoints within the window", result[0].Target)
+}
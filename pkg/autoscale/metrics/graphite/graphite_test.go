@@ -0,0 +1,91 @@
+package graphite
+
+import (
+	"encoding/json"
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_buildRenderParams(t *testing.T) {
+	testCases := []struct {
+		name           string
+		query          string
+		expectedParams url.Values
+		expectError    bool
+	}{
+		{
+			name:  "target expression",
+			query: "sumSeries(app.web.*.requests)",
+			expectedParams: url.Values{
+				"target": []string{"sumSeries(app.web.*.requests)"},
+				"from":   []string{defaultFrom},
+				"format": []string{"json"},
+			},
+		},
+		{
+			name:  "render parameters",
+			query: "target=averageSeries(app.web.*.latency)&from=-10min",
+			expectedParams: url.Values{
+				"target": []string{"averageSeries(app.web.*.latency)"},
+				"from":   []string{"-10min"},
+				"format": []string{"json"},
+			},
+		},
+		{name: "multiple targets", query: "target=a&target=b", expectError: true},
+		{name: "empty target", query: "target=&from=-1h", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			params, err := buildRenderParams(tc.query)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedParams, params)
+		})
+	}
+}
+
+func Test_lastValue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedValue float64
+		expectError   bool
+	}{
+		{
+			name:          "trailing null datapoint",
+			input:         `[{"target":"web","datapoints":[[10,1557478800],[12.5,1557478860],[null,1557478920]]}]`,
+			expectedValue: 12.5,
+		},
+		{
+			name:        "all null datapoints",
+			input:       `[{"target":"web","datapoints":[[null,1557478800]]}]`,
+			expectError: true,
+		},
+		{
+			name:        "multiple series",
+			input:       `[{"target":"a","datapoints":[[1,1]]},{"target":"b","datapoints":[[1,1]]}]`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var result []*series
+			assert.Nil(t, json.Unmarshal([]byte(tc.input), &result))
+
+			value, err := lastValue(result)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
@@ -0,0 +1,200 @@
+package haproxy
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// unixSocketPrefix identifies an address as the path to the HAProxy runtime API socket.
+	unixSocketPrefix = "unix://"
+
+	// backendServerName is the svname HAProxy uses for the aggregate row of each backend.
+	backendServerName = "BACKEND"
+
+	// socketTimeout is the deadline applied to runtime API socket requests.
+	socketTimeout = 10 * time.Second
+)
+
+// queryMetrics maps the supported query metrics to the HAProxy stats CSV field which provides it.
+var queryMetrics = map[string]string{
+	"current-sessions": "scur",
+	"session-rate":     "rate",
+	"queue":            "qcur",
+}
+
+// Client is a HAProxy metrics backend which reads backend statistics from either the runtime API
+// socket or the HTTP stats page.
+type Client struct {
+	addr       string
+	httpClient *http.Client
+	logger     zerolog.Logger
+}
+
+// NewClient builds the HAProxy metric provider. The address can either be the runtime API socket
+// in the form unix://<path>, or the HTTP stats page URL.
+func NewClient(addr string, log zerolog.Logger) metrics.Provider {
+	return &Client{
+		addr:       addr,
+		httpClient: cleanhttp.DefaultClient(),
+		logger:     log.With().Str("metric-provider", policy.ProviderHAProxy.String()).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return policy.ProviderHAProxy.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
+	defer sendMetrics.MeasureSince([]string{"autoscale", "haproxy", "get_value"}, time.Now())
+
+	value, err := c.getValue(ctx, query)
+	if err != nil {
+		sendMetrics.IncrCounter([]string{"autoscale", "haproxy", "error"}, 1)
+	} else {
+		sendMetrics.IncrCounter([]string{"autoscale", "haproxy", "success"}, 1)
+	}
+	return value, err
+}
+
+func (c *Client) getValue(ctx context.Context, query string) (*float64, error) {
+	backend, field, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := c.readStats(ctx)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug().Str("backend", backend).Str("field", field).Msg("successfully read HAProxy stats")
+
+	value, err := backendStat(stats, backend, field)
+	if err != nil {
+		return nil, err
+	}
+	return helper.Float64ToPointer(value), nil
+}
+
+// readStats reads the stats CSV from the configured runtime API socket or HTTP stats page.
+func (c *Client) readStats(ctx context.Context) ([]byte, error) {
+	if strings.HasPrefix(c.addr, unixSocketPrefix) {
+		return readSocketStats(ctx, strings.TrimPrefix(c.addr, unixSocketPrefix))
+	}
+
+	addr := c.addr
+	if !strings.HasSuffix(addr, ";csv") {
+		addr += ";csv"
+	}
+
+	req, err := http.NewRequest(http.MethodGet, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response code %v from %s", resp.StatusCode, c.addr)
+	}
+	return ioutil.ReadAll(resp.Body)
+}
+
+// readSocketStats runs the show stat command against the runtime API socket. The socket deadline
+// is the earliest of the socket timeout and the context deadline.
+func readSocketStats(ctx context.Context, path string) ([]byte, error) {
+	dialer := net.Dialer{Timeout: socketTimeout}
+
+	conn, err := dialer.DialContext(ctx, "unix", path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to connect to HAProxy runtime API socket")
+	}
+	defer conn.Close()
+
+	deadline := time.Now().Add(socketTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+
+	if err := conn.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	if _, err := io.WriteString(conn, "show stat\n"); err != nil {
+		return nil, errors.Wrap(err, "failed to write to HAProxy runtime API socket")
+	}
+	return ioutil.ReadAll(conn)
+}
+
+// backendStat parses the stats CSV and returns the named field from the aggregate row of the
+// backend.
+func backendStat(stats []byte, backend, field string) (float64, error) {
+	r := csv.NewReader(strings.NewReader(strings.TrimPrefix(string(stats), "# ")))
+	r.FieldsPerRecord = -1
+
+	records, err := r.ReadAll()
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse HAProxy stats")
+	}
+	if len(records) == 0 {
+		return 0, errors.New("HAProxy stats response is empty")
+	}
+
+	idx := -1
+	for i, name := range records[0] {
+		if name == field {
+			idx = i
+			break
+		}
+	}
+	if idx < 0 {
+		return 0, errors.Errorf("HAProxy stats do not include field %s", field)
+	}
+
+	for _, rec := range records[1:] {
+		if len(rec) <= idx || rec[0] != backend || rec[1] != backendServerName {
+			continue
+		}
+
+		// HAProxy leaves fields which do not apply to the row empty.
+		if rec[idx] == "" {
+			return 0, nil
+		}
+		return strconv.ParseFloat(rec[idx], 64)
+	}
+	return 0, errors.Errorf("HAProxy backend %s not found", backend)
+}
+
+// parseQuery splits the query into the HAProxy backend name and the stats field. The query takes
+// the form <backend>/<metric>.
+func parseQuery(query string) (string, string, error) {
+	parts := strings.Split(query, "/")
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", errors.Errorf("invalid HAProxy query %q, expected <backend>/<metric>", query)
+	}
+
+	field, ok := queryMetrics[parts[1]]
+	if !ok {
+		return "", "", errors.Errorf("unsupported HAProxy metric %q", parts[1])
+	}
+	return parts[0], field, nil
+}
@@ -0,0 +1,73 @@
+package haproxy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testStats = `# pxname,svname,qcur,qmax,scur,smax,slim,stot,rate
+stats,FRONTEND,,,1,2,2000,20,0
+web,web-1,0,0,4,10,,100,3
+web,web-2,1,2,5,10,,120,4
+web,BACKEND,3,5,9,20,200,220,7
+api,BACKEND,,,0,0,200,0,0
+`
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name            string
+		query           string
+		expectedBackend string
+		expectedField   string
+		expectError     bool
+	}{
+		{name: "current sessions", query: "web/current-sessions", expectedBackend: "web", expectedField: "scur"},
+		{name: "session rate", query: "web/session-rate", expectedBackend: "web", expectedField: "rate"},
+		{name: "queue", query: "web/queue", expectedBackend: "web", expectedField: "qcur"},
+		{name: "unsupported metric", query: "web/bytes", expectError: true},
+		{name: "missing backend", query: "/queue", expectError: true},
+		{name: "missing metric", query: "web", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			backend, field, err := parseQuery(tc.query)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedBackend, backend)
+			assert.Equal(t, tc.expectedField, field)
+		})
+	}
+}
+
+func Test_backendStat(t *testing.T) {
+	testCases := []struct {
+		name          string
+		backend       string
+		field         string
+		expectedValue float64
+		expectError   bool
+	}{
+		{name: "backend sessions", backend: "web", field: "scur", expectedValue: 9},
+		{name: "backend queue", backend: "web", field: "qcur", expectedValue: 3},
+		{name: "empty field", backend: "api", field: "qcur", expectedValue: 0},
+		{name: "unknown backend", backend: "db", field: "scur", expectError: true},
+		{name: "unknown field", backend: "web", field: "bin", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := backendStat([]byte(testStats), tc.backend, tc.field)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
@@ -0,0 +1,149 @@
+package influxdb
+
+import (
+	"context"
+	"encoding/csv"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// valueColumn is the Flux result column which holds the value of each record.
+const valueColumn = "_value"
+
+// Client is an InfluxDB v2 metrics backend which runs Flux queries using the query API.
+type Client struct {
+	addr       string
+	org        string
+	token      *secret.Value
+	httpClient *http.Client
+	logger     zerolog.Logger
+}
+
+// NewClient builds the InfluxDB metric provider. The org and token are used to authenticate and
+// scope Flux queries run against the query API found at the passed address. The token is resolved
+// before each query, so that rotated tokens are used.
+func NewClient(addr, org string, token *secret.Value, log zerolog.Logger) metrics.Provider {
+	return &Client{
+		addr:       strings.TrimSuffix(addr, "/"),
+		org:        org,
+		token:      token,
+		httpClient: cleanhttp.DefaultClient(),
+		logger:     log.With().Str("metric-provider", policy.ProviderInfluxDB.String()).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return policy.ProviderInfluxDB.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
+	defer sendMetrics.MeasureSince([]string{"autoscale", "influxdb", "get_value"}, time.Now())
+
+	value, err := c.getValue(ctx, query)
+	if err != nil {
+		sendMetrics.IncrCounter([]string{"autoscale", "influxdb", "error"}, 1)
+	} else {
+		sendMetrics.IncrCounter([]string{"autoscale", "influxdb", "success"}, 1)
+	}
+	return value, err
+}
+
+func (c *Client) getValue(ctx context.Context, query string) (*float64, error) {
+	token, err := c.token.Get(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve InfluxDB token")
+	}
+
+	req, err := http.NewRequest(http.MethodPost,
+		c.addr+"/api/v2/query?org="+url.QueryEscape(c.org), strings.NewReader(query))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/vnd.flux")
+	req.Header.Set("Accept", "application/csv")
+	if token != "" {
+		req.Header.Set("Authorization", "Token "+token)
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response code %v from InfluxDB query API", resp.StatusCode)
+	}
+
+	value, err := parseResult(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug().Str("query", query).Msg("successfully ran InfluxDB Flux query")
+
+	return helper.Float64ToPointer(value), nil
+}
+
+// parseResult reads the annotated CSV Flux response and returns the value of the single record
+// returned. Queries should therefore reduce their result using functions such as last() or mean().
+func parseResult(r io.Reader) (float64, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.Comment = '#'
+
+	var (
+		idx    = -1
+		values []string
+	)
+
+	for {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, errors.Wrap(err, "failed to parse InfluxDB query result")
+		}
+
+		// Each table within the result is preceded by a header row, and separated by a blank
+		// line which the CSV reader skips.
+		if idx < 0 || (len(rec) > idx && rec[idx] == valueColumn) {
+			idx = indexOf(rec, valueColumn)
+			continue
+		}
+		if len(rec) > idx {
+			values = append(values, rec[idx])
+		}
+	}
+
+	switch len(values) {
+	case 0:
+		return 0, errors.New("InfluxDB query returned no results")
+	case 1:
+		return strconv.ParseFloat(values[0], 64)
+	default:
+		return 0, errors.Errorf("InfluxDB query returned %v results, expected 1", len(values))
+	}
+}
+
+func indexOf(rec []string, col string) int {
+	for i := range rec {
+		if rec[i] == col {
+			return i
+		}
+	}
+	return -1
+}
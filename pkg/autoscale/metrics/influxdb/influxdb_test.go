@@ -0,0 +1,63 @@
+package influxdb
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseResult(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedValue float64
+		expectError   bool
+	}{
+		{
+			name: "single record",
+			input: `#datatype,string,long,dateTime:RFC3339,double,string
+#group,false,false,false,false,true
+#default,_result,,,,
+,result,table,_time,_value,_field
+,,0,2019-05-10T09:00:00Z,72.5,usage_user
+`,
+			expectedValue: 72.5,
+		},
+		{
+			name: "multiple records",
+			input: `,result,table,_time,_value
+,,0,2019-05-10T09:00:00Z,72.5
+,,0,2019-05-10T09:00:10Z,70.1
+`,
+			expectError: true,
+		},
+		{
+			name: "multiple tables",
+			input: `,result,table,_value
+,,0,1
+
+,result,table,_value
+,,1,2
+`,
+			expectError: true,
+		},
+		{
+			name:        "no records",
+			input:       "",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			value, err := parseResult(strings.NewReader(tc.input))
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
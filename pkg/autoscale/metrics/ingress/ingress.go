@@ -0,0 +1,168 @@
+package ingress
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics/scrape"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Supported ingress query metrics.
+const (
+	metricRequestRate       = "request-rate"
+	metricActiveConnections = "active-connections"
+)
+
+// family identifies a metric family exposed by an ingress, and the label which holds the name of
+// the backend service.
+type family struct {
+	name  string
+	label string
+}
+
+// families maps each supported query metric to the metric families which can provide it. Multiple
+// families allow different ingress versions and exporters to be supported; the values of all
+// families found are summed.
+type families map[string][]family
+
+// traefikFamilies are the Traefik v2 service metrics, and the Traefik v1 backend equivalents.
+var traefikFamilies = families{
+	metricRequestRate: {
+		{name: "traefik_service_requests_total", label: "service"},
+		{name: "traefik_backend_requests_total", label: "backend"},
+	},
+	metricActiveConnections: {
+		{name: "traefik_service_open_connections", label: "service"},
+		{name: "traefik_backend_open_connections", label: "backend"},
+	},
+}
+
+// nginxFamilies are the ingress-nginx controller metrics, and the NGINX Plus Prometheus exporter
+// upstream equivalents.
+var nginxFamilies = families{
+	metricRequestRate: {
+		{name: "nginx_ingress_controller_requests", label: "service"},
+		{name: "nginxplus_upstream_server_requests", label: "upstream"},
+	},
+	metricActiveConnections: {
+		{name: "nginxplus_upstream_server_active", label: "upstream"},
+	},
+}
+
+// Client is an ingress metrics backend which scrapes the Prometheus endpoint of a Traefik or NGINX
+// ingress, returning request and connection metrics for a named backend service.
+type Client struct {
+	addr     string
+	provider policy.MetricsProvider
+	families families
+	scraper  *scrape.Scraper
+	samples  *scrape.SampleStore
+	logger   zerolog.Logger
+}
+
+// NewTraefikClient builds a metric provider which scrapes the Traefik metrics endpoint found at
+// the passed address.
+func NewTraefikClient(addr string, log zerolog.Logger) metrics.Provider {
+	return newClient(addr, policy.ProviderTraefik, traefikFamilies, log)
+}
+
+// NewNGINXClient builds a metric provider which scrapes the NGINX metrics endpoint found at the
+// passed address.
+func NewNGINXClient(addr string, log zerolog.Logger) metrics.Provider {
+	return newClient(addr, policy.ProviderNGINX, nginxFamilies, log)
+}
+
+func newClient(addr string, provider policy.MetricsProvider, f families, log zerolog.Logger) *Client {
+	return &Client{
+		addr:     addr,
+		provider: provider,
+		families: f,
+		scraper:  scrape.NewScraper(),
+		samples:  scrape.NewSampleStore(),
+		logger:   log.With().Str("metric-provider", provider.String()).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return c.provider.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
+	defer sendMetrics.MeasureSince([]string{"autoscale", c.provider.String(), "get_value"}, time.Now())
+
+	value, err := c.getValue(ctx, query)
+	if err != nil {
+		sendMetrics.IncrCounter([]string{"autoscale", c.provider.String(), "error"}, 1)
+	} else {
+		sendMetrics.IncrCounter([]string{"autoscale", c.provider.String(), "success"}, 1)
+	}
+	return value, err
+}
+
+func (c *Client) getValue(ctx context.Context, query string) (*float64, error) {
+	service, metric, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	scraped, err := c.scraper.Scrape(ctx, c.addr)
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug().Str("service", service).Str("metric", metric).Msg("successfully scraped ingress metrics")
+
+	total := c.families.sum(scraped, metric, service)
+
+	switch metric {
+	case metricActiveConnections:
+		return helper.Float64ToPointer(total), nil
+	default:
+		rate, err := c.samples.Rate(query, &scrape.Sample{Time: time.Now(), Sum: total})
+		if err != nil {
+			return nil, err
+		}
+		return helper.Float64ToPointer(rate), nil
+	}
+}
+
+// sum totals the value of the metric for the service across all the families which provide it.
+func (f families) sum(scraped scrape.Families, metric, service string) float64 {
+	var total float64
+
+	for _, fam := range f[metric] {
+		matched := scrape.Filter([]scrape.Families{scraped}, fam.name, fam.label, serviceMatcher(service))
+		total += scrape.SumCounters(matched) + scrape.SumGauges(matched)
+	}
+	return total
+}
+
+// serviceMatcher matches label values to the service. Traefik v2 suffixes service names with the
+// provider which created them, such as web@consulcatalog, so this suffix is ignored.
+func serviceMatcher(service string) func(string) bool {
+	return func(val string) bool {
+		return val == service || strings.HasPrefix(val, service+"@")
+	}
+}
+
+// parseQuery splits the query into the service name and the metric. The query takes the form
+// <service>/<metric>.
+func parseQuery(query string) (string, string, error) {
+	idx := strings.LastIndex(query, "/")
+	if idx < 1 {
+		return "", "", errors.Errorf("invalid ingress query %q, expected <service>/<metric>", query)
+	}
+
+	switch metric := query[idx+1:]; metric {
+	case metricRequestRate, metricActiveConnections:
+		return query[:idx], metric, nil
+	default:
+		return "", "", errors.Errorf("unsupported ingress metric %q", metric)
+	}
+}
@@ -0,0 +1,72 @@
+package ingress
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/prometheus/common/expfmt"
+	"github.com/stretchr/testify/assert"
+)
+
+const testTraefikScrape = `# TYPE traefik_service_requests_total counter
+traefik_service_requests_total{code="200",method="GET",protocol="http",service="web@consulcatalog"} 100
+traefik_service_requests_total{code="500",method="GET",protocol="http",service="web@consulcatalog"} 5
+traefik_service_requests_total{code="200",method="GET",protocol="http",service="webapp@consulcatalog"} 999
+# TYPE traefik_service_open_connections gauge
+traefik_service_open_connections{method="GET",protocol="http",service="web@consulcatalog"} 12
+`
+
+const testNGINXScrape = `# TYPE nginx_ingress_controller_requests counter
+nginx_ingress_controller_requests{ingress="web",service="web",status="200"} 40
+nginx_ingress_controller_requests{ingress="web",service="web",status="404"} 2
+# TYPE nginxplus_upstream_server_active gauge
+nginxplus_upstream_server_active{server="10.0.0.2:8080",upstream="web"} 3
+nginxplus_upstream_server_active{server="10.0.0.3:8080",upstream="web"} 4
+`
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name            string
+		query           string
+		expectedService string
+		expectedMetric  string
+		expectError     bool
+	}{
+		{name: "request rate", query: "web/request-rate", expectedService: "web", expectedMetric: metricRequestRate},
+		{name: "active connections", query: "web/active-connections", expectedService: "web", expectedMetric: metricActiveConnections},
+		{name: "namespaced service", query: "default/web/request-rate", expectedService: "default/web", expectedMetric: metricRequestRate},
+		{name: "unsupported metric", query: "web/latency", expectError: true},
+		{name: "missing service", query: "/request-rate", expectError: true},
+		{name: "missing metric", query: "web", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			service, metric, err := parseQuery(tc.query)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedService, service)
+			assert.Equal(t, tc.expectedMetric, metric)
+		})
+	}
+}
+
+func Test_familiesSum(t *testing.T) {
+	var parser expfmt.TextParser
+
+	traefik, err := parser.TextToMetricFamilies(strings.NewReader(testTraefikScrape))
+	assert.Nil(t, err)
+
+	nginx, err := parser.TextToMetricFamilies(strings.NewReader(testNGINXScrape))
+	assert.Nil(t, err)
+
+	assert.Equal(t, float64(105), traefikFamilies.sum(traefik, metricRequestRate, "web"))
+	assert.Equal(t, float64(12), traefikFamilies.sum(traefik, metricActiveConnections, "web"))
+	assert.Equal(t, float64(0), traefikFamilies.sum(traefik, metricRequestRate, "api"))
+
+	assert.Equal(t, float64(42), nginxFamilies.sum(nginx, metricRequestRate, "web"))
+	assert.Equal(t, float64(7), nginxFamilies.sum(nginx, metricActiveConnections, "web"))
+}
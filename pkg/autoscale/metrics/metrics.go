@@ -1,10 +1,17 @@
 package metrics
 
+import "context"
+
 // Provider is the interface which all external metric providers must implement.
 type Provider interface {
 
+	// Name returns the name of the provider, which is the provider name used within scaling
+	// policy external checks and is used to register the provider with the autoscaler.
+	Name() string
+
 	// GetValue takes a query string and returns the resulting metric value as a float64 along with
 	// an error if one was encountered. When implementing this interface function, it should handle
-	// sending any Sherpa telemetry which directly reference to implementation name.
-	GetValue(query string) (*float64, error)
+	// sending any Sherpa telemetry which directly reference to implementation name. The context
+	// should be used to cancel any outstanding requests once its deadline is exceeded.
+	GetValue(ctx context.Context, query string) (*float64, error)
 }
@@ -0,0 +1,160 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// jszPath is the NATS server monitoring endpoint which details JetStream streams and consumers.
+const jszPath = "/jsz?accounts=true&streams=true&consumers=true"
+
+// Supported NATS JetStream query metrics.
+const (
+	metricPending    = "pending"
+	metricAckPending = "ack-pending"
+	metricLag        = "lag"
+)
+
+// jsz is the subset of the JetStream monitoring response used by the provider.
+type jsz struct {
+	AccountDetails []struct {
+		Name    string `json:"name"`
+		Streams []struct {
+			Name      string      `json:"name"`
+			Consumers []*consumer `json:"consumer_detail"`
+		} `json:"stream_detail"`
+	} `json:"account_details"`
+}
+
+// consumer is the subset of the JetStream consumer info used by the provider.
+type consumer struct {
+	Name          string  `json:"name"`
+	NumPending    float64 `json:"num_pending"`
+	NumAckPending float64 `json:"num_ack_pending"`
+}
+
+// value returns the consumer value for the query metric. The lag is the total of messages not yet
+// delivered and those delivered but awaiting acknowledgement.
+func (c *consumer) value(metric string) float64 {
+	switch metric {
+	case metricPending:
+		return c.NumPending
+	case metricAckPending:
+		return c.NumAckPending
+	default:
+		return c.NumPending + c.NumAckPending
+	}
+}
+
+// Client is a NATS JetStream metrics backend which reads consumer state from the NATS server
+// monitoring endpoint.
+type Client struct {
+	addr       string
+	httpClient *http.Client
+	logger     zerolog.Logger
+}
+
+// NewClient builds the NATS JetStream metric provider using the server monitoring endpoint at the
+// passed address.
+func NewClient(addr string, log zerolog.Logger) metrics.Provider {
+	return &Client{
+		addr:       strings.TrimSuffix(addr, "/"),
+		httpClient: cleanhttp.DefaultClient(),
+		logger:     log.With().Str("metric-provider", policy.ProviderNATS.String()).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return policy.ProviderNATS.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
+	defer sendMetrics.MeasureSince([]string{"autoscale", "nats", "get_value"}, time.Now())
+
+	value, err := c.getValue(ctx, query)
+	if err != nil {
+		sendMetrics.IncrCounter([]string{"autoscale", "nats", "error"}, 1)
+	} else {
+		sendMetrics.IncrCounter([]string{"autoscale", "nats", "success"}, 1)
+	}
+	return value, err
+}
+
+func (c *Client) getValue(ctx context.Context, query string) (*float64, error) {
+	stream, consumerName, metric, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodGet, c.addr+jszPath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response code %v from NATS monitoring endpoint", resp.StatusCode)
+	}
+
+	var info jsz
+	if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+		return nil, errors.Wrap(err, "failed to decode NATS JetStream info")
+	}
+
+	con := info.findConsumer(stream, consumerName)
+	if con == nil {
+		return nil, errors.Errorf("NATS JetStream consumer %s not found on stream %s", consumerName, stream)
+	}
+	c.logger.Debug().Str("stream", stream).Str("consumer", consumerName).Msg("successfully read NATS JetStream consumer")
+
+	return helper.Float64ToPointer(con.value(metric)), nil
+}
+
+// findConsumer returns the named consumer of the stream, searching all accounts.
+func (j *jsz) findConsumer(stream, name string) *consumer {
+	for _, acc := range j.AccountDetails {
+		for _, s := range acc.Streams {
+			if s.Name != stream {
+				continue
+			}
+			for _, con := range s.Consumers {
+				if con.Name == name {
+					return con
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// parseQuery splits the query into the stream, consumer and metric. The query takes the form
+// <stream>/<consumer>/<metric>.
+func parseQuery(query string) (string, string, string, error) {
+	parts := strings.Split(query, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", errors.Errorf("invalid NATS query %q, expected <stream>/<consumer>/<metric>", query)
+	}
+
+	switch parts[2] {
+	case metricPending, metricAckPending, metricLag:
+		return parts[0], parts[1], parts[2], nil
+	default:
+		return "", "", "", errors.Errorf("unsupported NATS metric %q", parts[2])
+	}
+}
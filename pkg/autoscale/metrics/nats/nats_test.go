@@ -0,0 +1,70 @@
+package nats
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testJSZ = `{
+  "account_details": [
+    {
+      "name": "$G",
+      "stream_detail": [
+        {
+          "name": "ORDERS",
+          "consumer_detail": [
+            {"name": "processor", "num_pending": 120, "num_ack_pending": 8},
+            {"name": "audit", "num_pending": 3, "num_ack_pending": 0}
+          ]
+        }
+      ]
+    }
+  ]
+}`
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name             string
+		query            string
+		expectedStream   string
+		expectedConsumer string
+		expectedMetric   string
+		expectError      bool
+	}{
+		{name: "pending", query: "ORDERS/processor/pending", expectedStream: "ORDERS", expectedConsumer: "processor", expectedMetric: metricPending},
+		{name: "lag", query: "ORDERS/processor/lag", expectedStream: "ORDERS", expectedConsumer: "processor", expectedMetric: metricLag},
+		{name: "unsupported metric", query: "ORDERS/processor/redelivered", expectError: true},
+		{name: "missing consumer", query: "ORDERS//pending", expectError: true},
+		{name: "missing parts", query: "ORDERS/pending", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			stream, con, metric, err := parseQuery(tc.query)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedStream, stream)
+			assert.Equal(t, tc.expectedConsumer, con)
+			assert.Equal(t, tc.expectedMetric, metric)
+		})
+	}
+}
+
+func Test_jszFindConsumer(t *testing.T) {
+	var info jsz
+	assert.Nil(t, json.Unmarshal([]byte(testJSZ), &info))
+
+	con := info.findConsumer("ORDERS", "processor")
+	assert.NotNil(t, con)
+	assert.Equal(t, float64(120), con.value(metricPending))
+	assert.Equal(t, float64(8), con.value(metricAckPending))
+	assert.Equal(t, float64(128), con.value(metricLag))
+
+	assert.Nil(t, info.findConsumer("ORDERS", "billing"))
+	assert.Nil(t, info.findConsumer("EVENTS", "processor"))
+}
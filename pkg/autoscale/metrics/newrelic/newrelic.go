@@ -0,0 +1,182 @@
+package newrelic
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// nrqlGraphQL is the NerdGraph query used to run NRQL within an account. The account ID and NRQL
+// query are passed as variables to avoid the need to escape the NRQL.
+const nrqlGraphQL = `query($accountId: Int!, $nrql: Nrql!) {
+  actor { account(id: $accountId) { nrql(query: $nrql) { results } } }
+}`
+
+// graphQLRequest is the NerdGraph request body.
+type graphQLRequest struct {
+	Query     string                 `json:"query"`
+	Variables map[string]interface{} `json:"variables"`
+}
+
+// graphQLResponse is the subset of the NerdGraph NRQL response used by the provider.
+type graphQLResponse struct {
+	Data struct {
+		Actor struct {
+			Account struct {
+				NRQL struct {
+					Results []map[string]interface{} `json:"results"`
+				} `json:"nrql"`
+			} `json:"account"`
+		} `json:"actor"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Client is a New Relic metrics backend which runs NRQL queries using the NerdGraph API.
+type Client struct {
+	addr       string
+	apiKey     *secret.Value
+	accountID  int
+	httpClient *http.Client
+	logger     zerolog.Logger
+}
+
+// NewClient builds the New Relic metric provider. The account ID is used for queries which do not
+// specify their own, and can be zero if all queries do. The API key is resolved before each query,
+// so that rotated keys are used.
+func NewClient(addr string, apiKey *secret.Value, accountID int, log zerolog.Logger) metrics.Provider {
+	return &Client{
+		addr:       addr,
+		apiKey:     apiKey,
+		accountID:  accountID,
+		httpClient: cleanhttp.DefaultClient(),
+		logger:     log.With().Str("metric-provider", policy.ProviderNewRelic.String()).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return policy.ProviderNewRelic.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
+	defer sendMetrics.MeasureSince([]string{"autoscale", "newrelic", "get_value"}, time.Now())
+
+	value, err := c.getValue(ctx, query)
+	if err != nil {
+		sendMetrics.IncrCounter([]string{"autoscale", "newrelic", "error"}, 1)
+	} else {
+		sendMetrics.IncrCounter([]string{"autoscale", "newrelic", "success"}, 1)
+	}
+	return value, err
+}
+
+func (c *Client) getValue(ctx context.Context, query string) (*float64, error) {
+	accountID, nrql, err := parseQuery(query, c.accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	apiKey, err := c.apiKey.Get(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve New Relic API key")
+	}
+
+	body, err := json.Marshal(&graphQLRequest{
+		Query:     nrqlGraphQL,
+		Variables: map[string]interface{}{"accountId": accountID, "nrql": nrql},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, c.addr, bytes.NewBuffer(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("API-Key", apiKey)
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response code %v from New Relic NerdGraph API", resp.StatusCode)
+	}
+
+	var result graphQLResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, errors.Wrap(err, "failed to decode New Relic NerdGraph response")
+	}
+
+	value, err := result.value()
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug().Int("account-id", accountID).Str("nrql", nrql).Msg("successfully ran New Relic NRQL query")
+
+	return helper.Float64ToPointer(value), nil
+}
+
+// value returns the single numeric value from the NRQL results. Queries should therefore return a
+// single row containing a single aggregate function, and not use TIMESERIES or FACET clauses.
+func (r *graphQLResponse) value() (float64, error) {
+	if len(r.Errors) > 0 {
+		return 0, errors.Errorf("New Relic NRQL query failed: %s", r.Errors[0].Message)
+	}
+
+	results := r.Data.Actor.Account.NRQL.Results
+	if len(results) != 1 {
+		return 0, errors.Errorf("New Relic NRQL query returned %v results, expected 1", len(results))
+	}
+
+	var values []float64 // nolint:prealloc
+	for _, v := range results[0] {
+		if f, ok := v.(float64); ok {
+			values = append(values, f)
+		}
+	}
+
+	if len(values) != 1 {
+		return 0, errors.Errorf("New Relic NRQL query returned %v numeric values, expected 1", len(values))
+	}
+	return values[0], nil
+}
+
+// parseQuery splits the query into the account ID and the NRQL query. The query takes the form
+// [<account-id>/]<nrql> and uses the default account ID when no account is specified.
+func parseQuery(query string, defaultAccountID int) (int, string, error) {
+	accountID := defaultAccountID
+	nrql := query
+
+	if idx := strings.Index(query, "/"); idx > 0 {
+		if id, err := strconv.Atoi(query[:idx]); err == nil {
+			accountID, nrql = id, query[idx+1:]
+		}
+	}
+
+	if accountID < 1 {
+		return 0, "", errors.Errorf("New Relic query %q does not specify an account ID", query)
+	}
+	if strings.TrimSpace(nrql) == "" {
+		return 0, "", errors.New("New Relic query does not include NRQL")
+	}
+	return accountID, nrql, nil
+}
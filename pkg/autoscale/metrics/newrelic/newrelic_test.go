@@ -0,0 +1,94 @@
+package newrelic
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name              string
+		query             string
+		defaultAccountID  int
+		expectedAccountID int
+		expectedNRQL      string
+		expectError       bool
+	}{
+		{
+			name:              "query account ID",
+			query:             "12345/SELECT count(*) FROM Transaction SINCE 5 minutes ago",
+			defaultAccountID:  1,
+			expectedAccountID: 12345,
+			expectedNRQL:      "SELECT count(*) FROM Transaction SINCE 5 minutes ago",
+		},
+		{
+			name:              "default account ID",
+			query:             "SELECT average(duration) FROM Transaction WHERE appName = 'web/api'",
+			defaultAccountID:  678,
+			expectedAccountID: 678,
+			expectedNRQL:      "SELECT average(duration) FROM Transaction WHERE appName = 'web/api'",
+		},
+		{name: "no account ID", query: "SELECT count(*) FROM Transaction", expectError: true},
+		{name: "no NRQL", query: "12345/", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			accountID, nrql, err := parseQuery(tc.query, tc.defaultAccountID)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedAccountID, accountID)
+			assert.Equal(t, tc.expectedNRQL, nrql)
+		})
+	}
+}
+
+func Test_graphQLResponseValue(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedValue float64
+		expectError   bool
+	}{
+		{
+			name:          "single value",
+			input:         `{"data":{"actor":{"account":{"nrql":{"results":[{"average.duration":0.25}]}}}}}`,
+			expectedValue: 0.25,
+		},
+		{
+			name:        "query error",
+			input:       `{"errors":[{"message":"NRQL Syntax Error"}]}`,
+			expectError: true,
+		},
+		{
+			name:        "timeseries results",
+			input:       `{"data":{"actor":{"account":{"nrql":{"results":[{"count":1},{"count":2}]}}}}}`,
+			expectError: true,
+		},
+		{
+			name:        "multiple values",
+			input:       `{"data":{"actor":{"account":{"nrql":{"results":[{"count":1,"sum":2}]}}}}}`,
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var resp graphQLResponse
+			assert.Nil(t, json.Unmarshal([]byte(tc.input), &resp))
+
+			value, err := resp.value()
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedValue, value)
+		})
+	}
+}
@@ -0,0 +1,329 @@
+package nomad
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Supported Nomad query metrics.
+const (
+	metricQueuedAllocations  = "queued-allocations"
+	metricBlockedEvaluations = "blocked-evaluations"
+
+	// Child metrics aggregate the jobs dispatched from a parameterized job, or launched by a
+	// periodic job, as evaluating the parent alone yields no allocations.
+	metricChildJobs        = "child-jobs"
+	metricChildAllocations = "child-allocations"
+	metricChildCPU         = "child-cpu"
+	metricChildMemory      = "child-memory"
+)
+
+// evalStatusBlocked is the status of a Nomad evaluation which is waiting for cluster resources in
+// order to place its allocations.
+const evalStatusBlocked = "blocked"
+
+// jobStatusDead is the status of a Nomad job which has completed or been stopped.
+const jobStatusDead = "dead"
+
+// Client is a Nomad metrics backend which reads the placement pressure of a job from the Nomad
+// API, allowing checks to respond to allocations which cannot be placed on the cluster. It also
+// aggregates the child jobs of parameterized and periodic jobs.
+type Client struct {
+	nomad  *client.NomadPool
+	logger zerolog.Logger
+}
+
+// NewClient builds the Nomad metric provider using the Nomad client pool.
+func NewClient(nomad *client.NomadPool, log zerolog.Logger) metrics.Provider {
+	return &Client{
+		nomad:  nomad,
+		logger: log.With().Str("metric-provider", policy.ProviderNomad.String()).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return policy.ProviderNomad.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
+	defer sendMetrics.MeasureSince([]string{"autoscale", "nomad", "get_value"}, time.Now())
+
+	value, err := c.getValue(ctx, query)
+	if err != nil {
+		sendMetrics.IncrCounter([]string{"autoscale", "nomad", "error"}, 1)
+	} else {
+		sendMetrics.IncrCounter([]string{"autoscale", "nomad", "success"}, 1)
+	}
+	return value, err
+}
+
+func (c *Client) getValue(ctx context.Context, query string) (*float64, error) {
+	job, group, metric, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var value float64
+
+	switch metric {
+	case metricQueuedAllocations:
+		value, err = c.getQueuedAllocations(ctx, job, group)
+	case metricBlockedEvaluations:
+		value, err = c.getBlockedEvaluations(ctx, job)
+	case metricChildJobs, metricChildAllocations:
+		value, err = c.getChildCount(ctx, job, group, metric)
+	default:
+		value, err = c.getChildUtilization(ctx, job, group, metric)
+	}
+	if err != nil {
+		return nil, err
+	}
+	c.logger.Debug().Str("job", job).Str("metric", metric).Float64("value", value).Msg("successfully read Nomad job metric")
+
+	return helper.Float64ToPointer(value), nil
+}
+
+func (c *Client) getQueuedAllocations(ctx context.Context, job, group string) (float64, error) {
+	var summary *api.JobSummary
+
+	err := c.nomad.Call(ctx, func() (err error) {
+		summary, _, err = c.nomad.Client().Jobs().Summary(job, nil)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	queued, err := queuedAllocations(summary, group)
+	return float64(queued), err
+}
+
+func (c *Client) getBlockedEvaluations(ctx context.Context, job string) (float64, error) {
+	var evals []*api.Evaluation
+
+	err := c.nomad.Call(ctx, func() (err error) {
+		evals, _, err = c.nomad.Client().Jobs().Evaluations(job, nil)
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return float64(blockedEvaluations(evals)), nil
+}
+
+// getChildJobs lists the child jobs of the parent which have not yet completed. Nomad prefixes the
+// ID of child jobs with the parent ID, so a prefix list is used and filtered by parent.
+func (c *Client) getChildJobs(ctx context.Context, parent string) ([]*api.JobListStub, error) {
+	var jobs []*api.JobListStub
+
+	err := c.nomad.Call(ctx, func() (err error) {
+		jobs, _, err = c.nomad.Client().Jobs().PrefixList(parent + "/")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return activeChildJobs(jobs, parent), nil
+}
+
+func (c *Client) getChildCount(ctx context.Context, parent, group, metric string) (float64, error) {
+	children, err := c.getChildJobs(ctx, parent)
+	if err != nil {
+		return 0, err
+	}
+
+	if metric == metricChildJobs {
+		return float64(len(children)), nil
+	}
+	return float64(childAllocations(children, group)), nil
+}
+
+// getChildUtilization calculates the CPU or memory utilisation percentage across all the running
+// allocations of the child jobs, optionally filtered by group.
+func (c *Client) getChildUtilization(ctx context.Context, parent, group, metric string) (float64, error) {
+	children, err := c.getChildJobs(ctx, parent)
+	if err != nil {
+		return 0, err
+	}
+
+	var allocated, used float64
+
+	for _, child := range children {
+		var allocs []*api.AllocationListStub
+
+		err := c.nomad.Call(ctx, func() (err error) {
+			allocs, _, err = c.nomad.Client().Jobs().Allocations(child.ID, false, nil)
+			return err
+		})
+		if err != nil {
+			return 0, err
+		}
+
+		for _, stub := range allocs {
+			if stub.ClientStatus != api.AllocClientStatusRunning || (group != "" && stub.TaskGroup != group) {
+				continue
+			}
+
+			var (
+				alloc *api.Allocation
+				stats *api.AllocResourceUsage
+			)
+
+			err := c.nomad.Call(ctx, func() (err error) {
+				if alloc, _, err = c.nomad.Client().Allocations().Info(stub.ID, nil); err != nil {
+					return err
+				}
+				stats, err = c.nomad.Client().Allocations().Stats(alloc, nil)
+				return err
+			})
+			if err != nil {
+				return 0, err
+			}
+
+			a, u := allocUtilization(alloc, stats, metric)
+			allocated += a
+			used += u
+		}
+	}
+
+	if allocated == 0 {
+		return 0, nil
+	}
+	return used / allocated * 100, nil
+}
+
+// queuedAllocations returns the number of allocations which are queued awaiting placement for the
+// job group, or for all groups of the job if the group is empty.
+func queuedAllocations(summary *api.JobSummary, group string) (int, error) {
+	if group != "" {
+		tg, ok := summary.Summary[group]
+		if !ok {
+			return 0, errors.Errorf("job group %s not found in job summary", group)
+		}
+		return tg.Queued, nil
+	}
+
+	var queued int
+
+	for _, tg := range summary.Summary {
+		queued += tg.Queued
+	}
+	return queued, nil
+}
+
+// blockedEvaluations returns the number of evaluations which are blocked awaiting cluster
+// resources.
+func blockedEvaluations(evals []*api.Evaluation) int {
+	var blocked int
+
+	for _, eval := range evals {
+		if eval.Status == evalStatusBlocked {
+			blocked++
+		}
+	}
+	return blocked
+}
+
+// activeChildJobs filters the jobs to the children of the parent which have not completed.
+func activeChildJobs(jobs []*api.JobListStub, parent string) []*api.JobListStub {
+	var out []*api.JobListStub // nolint:prealloc
+
+	for _, job := range jobs {
+		if job.ParentID != parent || job.Status == jobStatusDead {
+			continue
+		}
+		out = append(out, job)
+	}
+	return out
+}
+
+// childAllocations returns the number of running and starting allocations across the child jobs,
+// or of a single group if the group is not empty.
+func childAllocations(children []*api.JobListStub, group string) int {
+	var count int
+
+	for _, child := range children {
+		if child.JobSummary == nil {
+			continue
+		}
+		for name, tg := range child.JobSummary.Summary {
+			if group != "" && name != group {
+				continue
+			}
+			count += tg.Running + tg.Starting
+		}
+	}
+	return count
+}
+
+// allocUtilization returns the allocated and used amount of the resource described by the metric,
+// using MHz for CPU and MB for memory.
+func allocUtilization(alloc *api.Allocation, stats *api.AllocResourceUsage, metric string) (float64, float64) {
+	var allocated, used float64
+
+	if alloc.Resources != nil {
+		if metric == metricChildCPU && alloc.Resources.CPU != nil {
+			allocated = float64(*alloc.Resources.CPU)
+		}
+		if metric == metricChildMemory && alloc.Resources.MemoryMB != nil {
+			allocated = float64(*alloc.Resources.MemoryMB)
+		}
+	}
+
+	if stats != nil && stats.ResourceUsage != nil {
+		if metric == metricChildCPU && stats.ResourceUsage.CpuStats != nil {
+			used = stats.ResourceUsage.CpuStats.TotalTicks
+		}
+		if metric == metricChildMemory && stats.ResourceUsage.MemoryStats != nil {
+			used = float64(stats.ResourceUsage.MemoryStats.RSS / 1024 / 1024)
+		}
+	}
+	return allocated, used
+}
+
+// parseQuery splits the query into the job, optional group and metric. The query takes the form
+// <job>/<metric> or <job>/<group>/<metric>. Blocked evaluations are created per job, and child jobs
+// are counted per parent, so the blocked-evaluations and child-jobs metrics do not support
+// specifying a group.
+func parseQuery(query string) (string, string, string, error) {
+	parts := strings.Split(query, "/")
+
+	var job, group, metric string
+
+	switch len(parts) {
+	case 2:
+		job, metric = parts[0], parts[1]
+	case 3:
+		job, group, metric = parts[0], parts[1], parts[2]
+		if group == "" {
+			return "", "", "", errors.Errorf("invalid Nomad query %q, group must not be empty", query)
+		}
+	default:
+		return "", "", "", errors.Errorf("invalid Nomad query %q, expected <job>/[<group>/]<metric>", query)
+	}
+
+	if job == "" {
+		return "", "", "", errors.Errorf("invalid Nomad query %q, job must not be empty", query)
+	}
+
+	switch metric {
+	case metricQueuedAllocations, metricChildAllocations, metricChildCPU, metricChildMemory:
+	case metricBlockedEvaluations, metricChildJobs:
+		if group != "" {
+			return "", "", "", errors.Errorf("Nomad metric %q does not support a group", metric)
+		}
+	default:
+		return "", "", "", errors.Errorf("unsupported Nomad metric %q", metric)
+	}
+	return job, group, metric, nil
+}
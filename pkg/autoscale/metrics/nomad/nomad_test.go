@@ -0,0 +1,121 @@
+package nomad
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name           string
+		query          string
+		expectedJob    string
+		expectedGroup  string
+		expectedMetric string
+		expectError    bool
+	}{
+		{name: "job queued allocations", query: "batch/queued-allocations", expectedJob: "batch", expectedMetric: metricQueuedAllocations},
+		{name: "group queued allocations", query: "batch/worker/queued-allocations", expectedJob: "batch", expectedGroup: "worker", expectedMetric: metricQueuedAllocations},
+		{name: "job blocked evaluations", query: "batch/blocked-evaluations", expectedJob: "batch", expectedMetric: metricBlockedEvaluations},
+		{name: "group blocked evaluations", query: "batch/worker/blocked-evaluations", expectError: true},
+		{name: "child jobs", query: "render/child-jobs", expectedJob: "render", expectedMetric: metricChildJobs},
+		{name: "group child jobs", query: "render/frame/child-jobs", expectError: true},
+		{name: "group child cpu", query: "render/frame/child-cpu", expectedJob: "render", expectedGroup: "frame", expectedMetric: metricChildCPU},
+		{name: "unsupported metric", query: "batch/running", expectError: true},
+		{name: "missing job", query: "/queued-allocations", expectError: true},
+		{name: "empty group", query: "batch//queued-allocations", expectError: true},
+		{name: "missing metric", query: "batch", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			job, group, metric, err := parseQuery(tc.query)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedJob, job)
+			assert.Equal(t, tc.expectedGroup, group)
+			assert.Equal(t, tc.expectedMetric, metric)
+		})
+	}
+}
+
+func Test_queuedAllocations(t *testing.T) {
+	summary := &api.JobSummary{
+		Summary: map[string]api.TaskGroupSummary{
+			"worker": {Queued: 3, Running: 2},
+			"cache":  {Queued: 1, Running: 1},
+		},
+	}
+
+	queued, err := queuedAllocations(summary, "worker")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, queued)
+
+	queued, err = queuedAllocations(summary, "")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, queued)
+
+	_, err = queuedAllocations(summary, "api")
+	assert.NotNil(t, err)
+}
+
+func Test_blockedEvaluations(t *testing.T) {
+	evals := []*api.Evaluation{
+		{Status: "complete"},
+		{Status: evalStatusBlocked},
+		{Status: "pending"},
+	}
+	assert.Equal(t, 1, blockedEvaluations(evals))
+	assert.Equal(t, 0, blockedEvaluations(nil))
+}
+
+func Test_activeChildJobs(t *testing.T) {
+	jobs := []*api.JobListStub{
+		{ID: "render/dispatch-1", ParentID: "render", Status: "running"},
+		{ID: "render/dispatch-2", ParentID: "render", Status: jobStatusDead},
+		{ID: "render/dispatch-3", ParentID: "render", Status: "pending"},
+		{ID: "render/other", ParentID: "", Status: "running"},
+	}
+
+	actual := activeChildJobs(jobs, "render")
+	assert.Len(t, actual, 2)
+	assert.Equal(t, "render/dispatch-1", actual[0].ID)
+	assert.Equal(t, "render/dispatch-3", actual[1].ID)
+}
+
+func Test_childAllocations(t *testing.T) {
+	children := []*api.JobListStub{
+		{JobSummary: &api.JobSummary{Summary: map[string]api.TaskGroupSummary{
+			"frame":  {Running: 2, Starting: 1, Queued: 4},
+			"upload": {Running: 1},
+		}}},
+		{JobSummary: &api.JobSummary{Summary: map[string]api.TaskGroupSummary{
+			"frame": {Running: 1},
+		}}},
+		{},
+	}
+	assert.Equal(t, 4, childAllocations(children, "frame"))
+	assert.Equal(t, 5, childAllocations(children, ""))
+}
+
+func Test_allocUtilization(t *testing.T) {
+	cpu, mem := 500, 256
+	alloc := &api.Allocation{Resources: &api.Resources{CPU: &cpu, MemoryMB: &mem}}
+	stats := &api.AllocResourceUsage{ResourceUsage: &api.ResourceUsage{
+		CpuStats:    &api.CpuStats{TotalTicks: 250},
+		MemoryStats: &api.MemoryStats{RSS: 64 * 1024 * 1024},
+	}}
+
+	allocated, used := allocUtilization(alloc, stats, metricChildCPU)
+	assert.Equal(t, float64(500), allocated)
+	assert.Equal(t, float64(250), used)
+
+	allocated, used = allocUtilization(alloc, stats, metricChildMemory)
+	assert.Equal(t, float64(256), allocated)
+	assert.Equal(t, float64(64), used)
+}
@@ -0,0 +1,83 @@
+package prometheus
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"io/ioutil"
+	"net/http"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/pkg/errors"
+)
+
+// endpointRoundTripper adds the configured authentication and headers to each request sent to a
+// named Prometheus endpoint.
+type endpointRoundTripper struct {
+	cfg  *server.PrometheusEndpoint
+	next http.RoundTripper
+}
+
+// RoundTrip satisfies the http.RoundTripper interface.
+func (e *endpointRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+
+	// The RoundTripper interface does not permit modification of the original request, so the
+	// request is copied along with its headers.
+	r2 := *req
+	r2.Header = make(http.Header, len(req.Header))
+	for k, v := range req.Header {
+		r2.Header[k] = append([]string(nil), v...)
+	}
+	req = &r2
+
+	for k, v := range e.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+
+	switch {
+	case e.cfg.BearerToken != "":
+		req.Header.Set("Authorization", "Bearer "+e.cfg.BearerToken)
+	case e.cfg.Username != "":
+		req.SetBasicAuth(e.cfg.Username, e.cfg.Password)
+	}
+	return e.next.RoundTrip(req)
+}
+
+func newEndpointRoundTripper(cfg *server.PrometheusEndpoint) (http.RoundTripper, error) {
+	transport := cleanhttp.DefaultPooledTransport()
+
+	if cfg.TLS != nil {
+		tlsCfg, err := newEndpointTLSConfig(cfg.TLS)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to setup endpoint %s TLS", cfg.Name)
+		}
+		transport.TLSClientConfig = tlsCfg
+	}
+	return &endpointRoundTripper{cfg: cfg, next: transport}, nil
+}
+
+func newEndpointTLSConfig(cfg *server.PrometheusEndpointTLS) (*tls.Config, error) {
+	tlsCfg := &tls.Config{InsecureSkipVerify: cfg.Insecure} // nolint:gosec
+
+	if cfg.CACert != "" {
+		pem, err := ioutil.ReadFile(cfg.CACert)
+		if err != nil {
+			return nil, err
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("failed to parse CA certificate")
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if cfg.ClientCert != "" {
+		cert, err := tls.LoadX509KeyPair(cfg.ClientCert, cfg.ClientKey)
+		if err != nil {
+			return nil, err
+		}
+		tlsCfg.Certificates = []tls.Certificate{cert}
+	}
+	return tlsCfg, nil
+}
@@ -0,0 +1,55 @@
+package prometheus
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/stretchr/testify/assert"
+)
+
+type recordingRoundTripper struct {
+	req *http.Request
+}
+
+func (r *recordingRoundTripper) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.req = req
+	return &http.Response{StatusCode: http.StatusOK}, nil
+}
+
+func Test_endpointRoundTripper(t *testing.T) {
+	testCases := []struct {
+		name            string
+		cfg             *server.PrometheusEndpoint
+		expectedHeaders map[string]string
+	}{
+		{
+			name:            "bearer token and headers",
+			cfg:             &server.PrometheusEndpoint{BearerToken: "secret", Headers: map[string]string{"X-Scope-OrgID": "team-a"}},
+			expectedHeaders: map[string]string{"Authorization": "Bearer secret", "X-Scope-OrgID": "team-a"},
+		},
+		{
+			name:            "basic auth",
+			cfg:             &server.PrometheusEndpoint{Username: "sherpa", Password: "pass"},
+			expectedHeaders: map[string]string{"Authorization": "Basic c2hlcnBhOnBhc3M="},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			next := &recordingRoundTripper{}
+			rt := &endpointRoundTripper{cfg: tc.cfg, next: next}
+
+			req, err := http.NewRequest(http.MethodGet, "http://thanos:9090/api/v1/query", nil)
+			assert.Nil(t, err)
+
+			_, err = rt.RoundTrip(req)
+			assert.Nil(t, err)
+
+			for k, v := range tc.expectedHeaders {
+				assert.Equal(t, v, next.req.Header.Get(k))
+			}
+			assert.Empty(t, req.Header.Get("Authorization"))
+		})
+	}
+}
@@ -10,6 +10,7 @@ import (
 
 	sendMetrics "github.com/armon/go-metrics"
 	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/config/server"
 	"github.com/jrasell/sherpa/pkg/helper"
 	"github.com/jrasell/sherpa/pkg/policy"
 	"github.com/pkg/errors"
@@ -42,6 +43,10 @@ type Client struct {
 	logger           zerolog.Logger
 	prometheusClient api.Client
 	queryAddr        string
+
+	// timeout is the duration after which queries are cancelled. A zero value means queries are
+	// not subject to a timeout.
+	timeout time.Duration
 }
 
 // NewClient takes the base Prometheus API address and build the client for use in retrieving
@@ -58,13 +63,43 @@ func NewClient(addr string, log zerolog.Logger) (metrics.Provider, error) {
 	}, nil
 }
 
+// NewEndpointClient builds a client for the named Prometheus-compatible endpoint, configuring the
+// endpoint authentication, TLS and timeout settings.
+func NewEndpointClient(cfg *server.PrometheusEndpoint, log zerolog.Logger) (metrics.Provider, error) {
+	timeout, err := cfg.TimeoutDuration()
+	if err != nil {
+		return nil, err
+	}
+
+	rt, err := newEndpointRoundTripper(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := api.NewClient(api.Config{Address: cfg.Addr, RoundTripper: rt})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{
+		logger: log.With().
+			Str("metric-provider", policy.ProviderPrometheus.String()).
+			Str("endpoint", cfg.Name).Logger(),
+		prometheusClient: client,
+		queryAddr:        cfg.Addr + queryEndpoint,
+		timeout:          timeout,
+	}, nil
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return policy.ProviderPrometheus.String() }
+
 // GetValue satisfies the GetValue function of the metrics.Provider interface.
-func (c *Client) GetValue(query string) (*float64, error) {
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
 	defer sendMetrics.MeasureSince([]string{"autoscale", "prometheus", "get_value"}, time.Now())
 
 	// Gather the value and any error returned from attempting to call Prometheus; handling the
 	// error result via Sherpa telemetry.
-	value, err := c.getValue(query)
+	value, err := c.getValue(ctx, query)
 	if err != nil {
 		sendMetrics.IncrCounter([]string{"autoscale", "prometheus", "error"}, 1)
 	} else {
@@ -75,8 +110,12 @@ func (c *Client) GetValue(query string) (*float64, error) {
 
 // getValue performs the Prometheus query work, allowing the interface implementation to handle end
 // state activities.
-func (c *Client) getValue(query string) (*float64, error) {
-	ctx := context.Background()
+func (c *Client) getValue(ctx context.Context, query string) (*float64, error) {
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
 
 	parsedURL, err := url.Parse(c.queryAddr + url.QueryEscape(query))
 	if err != nil {
@@ -111,7 +150,18 @@ func (c *Client) getValueFromResp(resp *queryResp) (*float64, error) {
 		return nil, errors.New("received incorrect length result list from Prometheus")
 	}
 
-	floatVal, err := strconv.ParseFloat(resp.Data.Result[0].Value[1].(string), 64)
+	// Instant vector samples are a [timestamp, "value"] pair; anything else indicates the query
+	// returned an unsupported result type.
+	sample := resp.Data.Result[0].Value
+	if len(sample) != 2 {
+		return nil, errors.Errorf("received unsupported %q result from Prometheus", resp.Data.ResultType)
+	}
+	strVal, ok := sample[1].(string)
+	if !ok {
+		return nil, errors.New("received non-string metric value from Prometheus")
+	}
+
+	floatVal, err := strconv.ParseFloat(strVal, 64)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to convert Prometheus metric value to float64")
 	}
@@ -0,0 +1,75 @@
+package prometheus
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClient_GetValue(t *testing.T) {
+	testCases := []struct {
+		body          string
+		expectedValue float64
+		expectError   bool
+		name          string
+	}{
+		{
+			body:          `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1580032800,"42.5"]}]}}`,
+			expectedValue: 42.5,
+			name:          "single vector sample",
+		},
+		{
+			body:        `{"status":"success","data":{"resultType":"vector","result":[]}}`,
+			expectError: true,
+			name:        "empty result",
+		},
+		{
+			body: `{"status":"success","data":{"resultType":"vector","result":[` +
+				`{"metric":{"a":"1"},"value":[1580032800,"1"]},{"metric":{"a":"2"},"value":[1580032800,"2"]}]}}`,
+			expectError: true,
+			name:        "multiple series",
+		},
+		{
+			body:        `{"status":"success","data":{"resultType":"matrix","result":[{"metric":{},"values":[[1580032800,"1"]]}]}}`,
+			expectError: true,
+			name:        "matrix result",
+		},
+		{
+			body:        `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1580032800,7]}]}}`,
+			expectError: true,
+			name:        "non-string value",
+		},
+		{
+			body:        `{"status":"success","data":{"resultType":"vector","result":[{"metric":{},"value":[1580032800,"NaN-ish"]}]}}`,
+			expectError: true,
+			name:        "unparsable value",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/v1/query", r.URL.Path)
+				assert.Equal(t, "sum(queue_depth)", r.URL.Query().Get("query"))
+				_, _ = w.Write([]byte(tc.body))
+			}))
+			defer srv.Close()
+
+			client, err := NewClient(srv.URL, zerolog.Nop())
+			assert.Nil(t, err)
+
+			value, err := client.GetValue(context.Background(), "sum(queue_depth)")
+			if tc.expectError {
+				assert.NotNil(t, err)
+				assert.Nil(t, value)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedValue, *value)
+		})
+	}
+}
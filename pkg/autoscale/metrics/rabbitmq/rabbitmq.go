@@ -0,0 +1,163 @@
+package rabbitmq
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// defaultVHost is the RabbitMQ virtual host used when the query does not specify one.
+const defaultVHost = "/"
+
+// Supported RabbitMQ query metrics.
+const (
+	metricMessages        = "messages"
+	metricMessagesReady   = "messages-ready"
+	metricMessagesUnacked = "messages-unacked"
+)
+
+// queue is the subset of the management API queue object used by the provider.
+type queue struct {
+	Messages               float64 `json:"messages"`
+	MessagesReady          float64 `json:"messages_ready"`
+	MessagesUnacknowledged float64 `json:"messages_unacknowledged"`
+}
+
+// value returns the queue value for the query metric.
+func (q *queue) value(metric string) float64 {
+	switch metric {
+	case metricMessagesReady:
+		return q.MessagesReady
+	case metricMessagesUnacked:
+		return q.MessagesUnacknowledged
+	default:
+		return q.Messages
+	}
+}
+
+// Client is a RabbitMQ metrics backend which reads queue depths from the management HTTP API.
+type Client struct {
+	addr       string
+	httpClient *http.Client
+	logger     zerolog.Logger
+}
+
+// NewClient builds the RabbitMQ metric provider using the management API at the passed address.
+// Credentials can be supplied within the address userinfo.
+func NewClient(addr string, log zerolog.Logger) metrics.Provider {
+	return &Client{
+		addr:       strings.TrimSuffix(addr, "/"),
+		httpClient: cleanhttp.DefaultClient(),
+		logger:     log.With().Str("metric-provider", policy.ProviderRabbitMQ.String()).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (c *Client) Name() string { return policy.ProviderRabbitMQ.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (c *Client) GetValue(ctx context.Context, query string) (*float64, error) {
+	defer sendMetrics.MeasureSince([]string{"autoscale", "rabbitmq", "get_value"}, time.Now())
+
+	value, err := c.getValue(ctx, query)
+	if err != nil {
+		sendMetrics.IncrCounter([]string{"autoscale", "rabbitmq", "error"}, 1)
+	} else {
+		sendMetrics.IncrCounter([]string{"autoscale", "rabbitmq", "success"}, 1)
+	}
+	return value, err
+}
+
+func (c *Client) getValue(ctx context.Context, query string) (*float64, error) {
+	vhost, queues, metric, err := parseQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	var total float64
+
+	// Sum the metric across each of the named queues.
+	for _, name := range queues {
+		q, err := c.getQueue(ctx, vhost, name)
+		if err != nil {
+			return nil, err
+		}
+		total += q.value(metric)
+	}
+	c.logger.Debug().Str("vhost", vhost).Strs("queues", queues).Msg("successfully read RabbitMQ queue stats")
+
+	return helper.Float64ToPointer(total), nil
+}
+
+func (c *Client) getQueue(ctx context.Context, vhost, name string) (*queue, error) {
+	addr := fmt.Sprintf("%s/api/queues/%s/%s", c.addr, url.PathEscape(vhost), url.PathEscape(name))
+
+	req, err := http.NewRequest(http.MethodGet, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := c.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response code %v for RabbitMQ queue %s", resp.StatusCode, name)
+	}
+
+	var q queue
+	if err := json.NewDecoder(resp.Body).Decode(&q); err != nil {
+		return nil, errors.Wrap(err, "failed to decode RabbitMQ queue")
+	}
+	return &q, nil
+}
+
+// parseQuery splits the query into the virtual host, queue names and metric. The query takes the
+// form <vhost>/<queue>[,<queue>]/<metric> where an empty vhost refers to the default vhost.
+func parseQuery(query string) (string, []string, string, error) {
+	metricIdx := strings.LastIndex(query, "/")
+	if metricIdx < 0 {
+		return "", nil, "", errors.Errorf("invalid RabbitMQ query %q, expected <vhost>/<queue>/<metric>", query)
+	}
+
+	metric := query[metricIdx+1:]
+	switch metric {
+	case metricMessages, metricMessagesReady, metricMessagesUnacked:
+	default:
+		return "", nil, "", errors.Errorf("unsupported RabbitMQ metric %q", metric)
+	}
+
+	rest := query[:metricIdx]
+	queueIdx := strings.LastIndex(rest, "/")
+	if queueIdx < 0 || queueIdx == len(rest)-1 {
+		return "", nil, "", errors.Errorf("invalid RabbitMQ query %q, expected <vhost>/<queue>/<metric>", query)
+	}
+
+	var queues []string // nolint:prealloc
+	for _, q := range strings.Split(rest[queueIdx+1:], ",") {
+		if q = strings.TrimSpace(q); q == "" {
+			return "", nil, "", errors.Errorf("invalid RabbitMQ query %q, empty queue name", query)
+		}
+		queues = append(queues, q)
+	}
+
+	vhost := rest[:queueIdx]
+	if vhost == "" {
+		vhost = defaultVHost
+	}
+	return vhost, queues, metric, nil
+}
@@ -0,0 +1,66 @@
+package rabbitmq
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseQuery(t *testing.T) {
+	testCases := []struct {
+		name           string
+		query          string
+		expectedVHost  string
+		expectedQueues []string
+		expectedMetric string
+		expectError    bool
+	}{
+		{
+			name:           "default vhost",
+			query:          "/jobs/messages-ready",
+			expectedVHost:  "/",
+			expectedQueues: []string{"jobs"},
+			expectedMetric: metricMessagesReady,
+		},
+		{
+			name:           "named vhost multiple queues",
+			query:          "prod/jobs,retries/messages",
+			expectedVHost:  "prod",
+			expectedQueues: []string{"jobs", "retries"},
+			expectedMetric: metricMessages,
+		},
+		{
+			name:           "root vhost written explicitly",
+			query:          "//jobs/messages-unacked",
+			expectedVHost:  "/",
+			expectedQueues: []string{"jobs"},
+			expectedMetric: metricMessagesUnacked,
+		},
+		{name: "unsupported metric", query: "/jobs/consumers", expectError: true},
+		{name: "missing queue", query: "prod//messages", expectError: true},
+		{name: "empty queue in list", query: "prod/jobs,/messages", expectError: true},
+		{name: "missing vhost separator", query: "jobs", expectError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			vhost, queues, metric, err := parseQuery(tc.query)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedVHost, vhost)
+			assert.Equal(t, tc.expectedQueues, queues)
+			assert.Equal(t, tc.expectedMetric, metric)
+		})
+	}
+}
+
+func Test_queueValue(t *testing.T) {
+	q := &queue{Messages: 15, MessagesReady: 10, MessagesUnacknowledged: 5}
+
+	assert.Equal(t, float64(15), q.value(metricMessages))
+	assert.Equal(t, float64(10), q.value(metricMessagesReady))
+	assert.Equal(t, float64(5), q.value(metricMessagesUnacked))
+}
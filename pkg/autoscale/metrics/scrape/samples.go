@@ -0,0 +1,59 @@
+package scrape
+
+import (
+	"sync"
+
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+)
+
+// SampleStore stores the previous counter sample for each key, allowing rates and averages to be
+// calculated between autoscaling evaluations.
+type SampleStore struct {
+	samples map[string]*Sample
+	lock    sync.Mutex
+}
+
+// NewSampleStore builds a new, empty, SampleStore.
+func NewSampleStore() *SampleStore {
+	return &SampleStore{samples: make(map[string]*Sample)}
+}
+
+// Rate compares the current sample with the previous sample for the key, returning the per second
+// rate of change. An error is returned if there is no usable previous sample.
+func (s *SampleStore) Rate(key string, cur *Sample) (float64, error) {
+	prev := s.swap(key, cur)
+	if prev == nil || cur.Sum < prev.Sum {
+		return 0, metrics.ErrInsufficientData
+	}
+
+	seconds := cur.Time.Sub(prev.Time).Seconds()
+	if seconds <= 0 {
+		return 0, metrics.ErrInsufficientData
+	}
+	return (cur.Sum - prev.Sum) / seconds, nil
+}
+
+// Average compares the current sample with the previous sample for the key, returning the average
+// value of the observations made between the two. An error is returned if there is no usable
+// previous sample.
+func (s *SampleStore) Average(key string, cur *Sample) (float64, error) {
+	prev := s.swap(key, cur)
+	if prev == nil || cur.Count < prev.Count {
+		return 0, metrics.ErrInsufficientData
+	}
+
+	if cur.Count == prev.Count {
+		return 0, nil
+	}
+	return (cur.Sum - prev.Sum) / (cur.Count - prev.Count), nil
+}
+
+// swap stores the current sample for the key, returning the previous sample.
+func (s *SampleStore) swap(key string, cur *Sample) *Sample {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	prev := s.samples[key]
+	s.samples[key] = cur
+	return prev
+}
@@ -0,0 +1,40 @@
+package scrape
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSampleStore_Rate(t *testing.T) {
+	s := NewSampleStore()
+	now := time.Now()
+
+	_, err := s.Rate("web/request-rate", &Sample{Time: now, Sum: 100})
+	assert.NotNil(t, err)
+
+	val, err := s.Rate("web/request-rate", &Sample{Time: now.Add(10 * time.Second), Sum: 150})
+	assert.Nil(t, err)
+	assert.Equal(t, float64(5), val)
+
+	// A counter reset should not produce a negative rate.
+	_, err = s.Rate("web/request-rate", &Sample{Time: now.Add(20 * time.Second), Sum: 10})
+	assert.NotNil(t, err)
+}
+
+func TestSampleStore_Average(t *testing.T) {
+	s := NewSampleStore()
+	now := time.Now()
+
+	_, err := s.Average("web/upstream-latency", &Sample{Time: now, Sum: 100, Count: 10})
+	assert.NotNil(t, err)
+
+	val, err := s.Average("web/upstream-latency", &Sample{Time: now, Sum: 400, Count: 20})
+	assert.Nil(t, err)
+	assert.Equal(t, float64(30), val)
+
+	val, err = s.Average("web/upstream-latency", &Sample{Time: now, Sum: 400, Count: 20})
+	assert.Nil(t, err)
+	assert.Equal(t, float64(0), val)
+}
@@ -0,0 +1,98 @@
+package scrape
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/pkg/errors"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/prometheus/common/expfmt"
+)
+
+// Families is the collection of metric families returned from a single scrape, keyed by the
+// metric family name.
+type Families map[string]*dto.MetricFamily
+
+// Scraper performs HTTP scrapes of Prometheus text formatted metric endpoints. It is used by
+// metric providers which read metrics directly from a target rather than a metrics store.
+type Scraper struct {
+	httpClient *http.Client
+}
+
+// NewScraper builds a new Scraper using a clean HTTP client.
+func NewScraper() *Scraper {
+	return &Scraper{httpClient: cleanhttp.DefaultClient()}
+}
+
+// Scrape performs a GET request against the address and parses the Prometheus text formatted
+// response body.
+func (s *Scraper) Scrape(ctx context.Context, addr string) (Families, error) {
+	req, err := http.NewRequest(http.MethodGet, addr, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := s.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.Errorf("unexpected response code %v from %s", resp.StatusCode, addr)
+	}
+
+	var parser expfmt.TextParser
+	return parser.TextToMetricFamilies(resp.Body)
+}
+
+// Filter returns the metrics of the named family, across all scrapes, which have the label and
+// whose value satisfies the match function.
+func Filter(families []Families, name, label string, match func(string) bool) []*dto.Metric {
+	var out []*dto.Metric
+
+	for i := range families {
+		family, ok := families[i][name]
+		if !ok {
+			continue
+		}
+
+		for _, m := range family.GetMetric() {
+			for _, l := range m.GetLabel() {
+				if l.GetName() == label && match(l.GetValue()) {
+					out = append(out, m)
+					break
+				}
+			}
+		}
+	}
+	return out
+}
+
+// SumCounters returns the total value of all counter metrics.
+func SumCounters(metrics []*dto.Metric) float64 {
+	var total float64
+	for i := range metrics {
+		total += metrics[i].GetCounter().GetValue()
+	}
+	return total
+}
+
+// SumGauges returns the total value of all gauge metrics.
+func SumGauges(metrics []*dto.Metric) float64 {
+	var total float64
+	for i := range metrics {
+		total += metrics[i].GetGauge().GetValue()
+	}
+	return total
+}
+
+// Sample is a point in time reading of one or more counters. Count is only used when calculating
+// averages from histogram or summary metrics.
+type Sample struct {
+	Time  time.Time
+	Sum   float64
+	Count float64
+}
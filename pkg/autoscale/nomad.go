@@ -1,31 +1,70 @@
 package autoscale
 
 import (
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
 	nomad "github.com/hashicorp/nomad/api"
 	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
 	"github.com/pkg/errors"
 )
 
+// metaKeyQueuedAllocations is the scaling request meta key which details the number of allocations
+// of the group which were queued awaiting placement when the scaling was triggered.
+const metaKeyQueuedAllocations = "queued-allocations"
+
+// metaKeyStatsCoverage is the scaling request meta key which details the percentage of the group
+// allocations whose resource usage was gathered, when the decision was made using only a subset.
+const metaKeyStatsCoverage = "stats-coverage"
+
+// maxAllocDirDepth is the number of directory levels below each ephemeral disk directory which
+// are walked when calculating the disk usage of an allocation.
+const maxAllocDirDepth = 4
+
 type nomadGatheredMetrics struct {
 	resourceInfo  map[string]*nomadResources
 	resourceUsage map[string]*nomadResources
+
+	// allocCount tracks the number of running or pending allocations per job group whose
+	// resource usage was gathered.
+	allocCount map[string]int
+
+	// coverage is the percentage of the running or pending allocations of each job group whose
+	// resource usage was gathered. Allocations on unreachable nodes reduce the coverage.
+	coverage map[string]float64
+
+	// unknownTasks tracks the policy resource tasks of each job group which were not found within
+	// the tasks of the group allocations.
+	unknownTasks map[string][]string
 }
 
 type nomadResources struct {
-	cpu float64
-	mem float64
+	cpu  float64
+	mem  float64
+	disk float64
+	gpu  float64
 }
 
 const (
 	nomadCPUMetricName    = "nomad-cpu"
 	nomadMemoryMetricName = "nomad-memory"
+	nomadDiskMetricName   = "nomad-disk"
+	nomadGPUMetricName    = "nomad-gpu"
+
+	// nomadCompositeMetricName is the metric name used when reporting the weighted composite
+	// score of multiple Nomad resources.
+	nomadCompositeMetricName = "nomad-composite"
 )
 
 // gatherNomadMetrics queries Nomad to produce Nomad resource allocation metrics for the job
 // currently under evaluation. This only needs to be called once per job, and will provide stats
 // for use across all groups.
 func (ae *autoscaleEvaluation) gatherNomadMetrics() (*nomadGatheredMetrics, error) {
-	resourceInfo, allocs, err := ae.getJobAllocations()
+	allocs, err := ae.getJobAllocations()
 	if err != nil {
 		return nil, err
 	}
@@ -37,19 +76,125 @@ func (ae *autoscaleEvaluation) gatherNomadMetrics() (*nomadGatheredMetrics, erro
 		return nil, errors.New("no allocations found to match task group with scaling policy")
 	}
 
-	resourceUsage, err := ae.getJobResourceUsage(allocs)
+	resourceUsage, reachable, err := ae.getJobResourceUsage(allocs)
 	if err != nil {
 		return nil, err
 	}
 
+	// The allocated resources are only tracked for the allocations whose usage was gathered, so
+	// that the utilisation of a partially covered group is calculated from the same subset.
+	resourceInfo := make(map[string]*nomadResources)
+	allocCount := make(map[string]int)
+	unknownTasks := make(map[string][]string)
+
+	for i := range reachable {
+		tasks := ae.policies[reachable[i].TaskGroup].ResourceTasks
+
+		updateResourceTracker(reachable[i].TaskGroup, getAllocResourceInfo(reachable[i], tasks), resourceInfo)
+		allocCount[reachable[i].TaskGroup]++
+
+		if unknown := getUnknownResourceTasks(reachable[i], tasks); len(unknown) > 0 {
+			unknownTasks[reachable[i].TaskGroup] = unknown
+		}
+	}
+
 	return &nomadGatheredMetrics{
 		resourceInfo:  resourceInfo,
 		resourceUsage: resourceUsage,
+		allocCount:    allocCount,
+		coverage:      calculateStatsCoverage(allocs, allocCount),
+		unknownTasks:  unknownTasks,
 	}, nil
 }
 
+// getUnknownResourceTasks returns the named resource tasks which are not tasks of the allocation.
+func getUnknownResourceTasks(alloc *nomad.Allocation, tasks []string) []string {
+	var out []string
+
+	for _, task := range tasks {
+		if _, ok := alloc.TaskResources[task]; !ok {
+			out = append(out, task)
+		}
+	}
+	return out
+}
+
+// calculateStatsCoverage returns the percentage of the allocations of each group which are
+// included within the reachable counts.
+func calculateStatsCoverage(allocs []*nomad.Allocation, reachable map[string]int) map[string]float64 {
+	total := make(map[string]int)
+	for i := range allocs {
+		total[allocs[i].TaskGroup]++
+	}
+
+	out := make(map[string]float64, len(total))
+	for group, count := range total {
+		out[group] = float64(reachable[group]) * 100 / float64(count)
+	}
+	return out
+}
+
+// hasNomadMetrics returns whether Nomad resource metrics are available to evaluate the group. The
+// metrics of groups whose allocation stats coverage is below the minimum are not used.
+func (ae *autoscaleEvaluation) hasNomadMetrics(group string) bool {
+	if ae.nomadMetricData == nil {
+		return false
+	}
+
+	cov, ok := ae.nomadMetricData.coverage[group]
+	if !ok || cov >= ae.requiredStatsCoverage() {
+		return true
+	}
+
+	ae.log.Warn().
+		Str("group", group).
+		Float64("stats-coverage", cov).
+		Float64("min-stats-coverage", ae.requiredStatsCoverage()).
+		Msg("insufficient job group allocation stats gathered, skipping Nomad based checks")
+	return false
+}
+
+// requiredStatsCoverage returns the minimum percentage of group allocations whose stats must be
+// gathered. If unset, the stats of all allocations are required.
+func (ae *autoscaleEvaluation) requiredStatsCoverage() float64 {
+	if ae.minStatsCoverage <= 0 {
+		return 100
+	}
+	return ae.minStatsCoverage
+}
+
+// addStatsCoverageMeta records the allocation stats coverage of the group within the scaling meta
+// when the decision used Nomad metrics gathered from only a subset of the group allocations, so
+// that the reduced confidence of the decision is visible within the scaling event.
+func (ae *autoscaleEvaluation) addStatsCoverageMeta(group string, dec *scalingDecision, meta map[string]string) {
+	if ae.nomadMetricData == nil || !dec.usesNomadMetrics() {
+		return
+	}
+
+	if cov, ok := ae.nomadMetricData.coverage[group]; ok && cov < 100 {
+		meta[metaKeyStatsCoverage] = strconv.FormatFloat(cov, 'f', 1, 64)
+	}
+}
+
 func (ae *autoscaleEvaluation) evaluateNomadJobMetrics(group string, pol *policy.GroupScalingPolicy, resources *nomadGatheredMetrics) *scalingDecision {
 
+	// Groups whose allocation stats coverage is below the minimum are reported by hasNomadMetrics
+	// and cannot be evaluated. Groups above the minimum but missing the stats of some allocations
+	// are evaluated, with the decision having reduced confidence.
+	if cov, ok := resources.coverage[group]; ok && cov < 100 {
+		if cov < ae.requiredStatsCoverage() {
+			return nil
+		}
+		ae.log.Warn().
+			Str("group", group).
+			Float64("stats-coverage", cov).
+			Msg("evaluating job group using partial allocation stats, decision has reduced confidence")
+		sendMetrics.IncrCounterWithLabels([]string{"autoscale", "partial_stats"}, 1, []sendMetrics.Label{
+			{Name: "job", Value: ae.jobID},
+			{Name: "group", Value: group},
+		})
+	}
+
 	// It is possible a scaling policy is configured for a job group, but the actual running
 	// Nomad job doesn't have this job group configured. If this is the case, we should warn
 	// the user in the logs and break the current loop.
@@ -58,29 +203,75 @@ func (ae *autoscaleEvaluation) evaluateNomadJobMetrics(group string, pol *policy
 		return nil
 	}
 
-	// Maths. Find the current CPU and memory utilisation in percentage based on the total
-	// available resources to the group, compared to their configured maximum based on the
-	// resource stanza.
-	cpuUsage := resources.resourceUsage[group].cpu * 100 / resources.resourceInfo[group].cpu
-	memUsage := resources.resourceUsage[group].mem * 100 / resources.resourceInfo[group].mem
+	// If none of the policy resource tasks are tasks of the group, no resources are allocated and
+	// the utilisation cannot be calculated, so the checks are skipped rather than never firing.
+	if unknown, ok := resources.unknownTasks[group]; ok {
+		if len(unknown) == len(pol.ResourceTasks) {
+			ae.log.Warn().
+				Str("group", group).
+				Strs("resource-tasks", pol.ResourceTasks).
+				Msg("job group policy resource tasks not found in Nomad job, skipping Nomad based checks")
+			return nil
+		}
+		ae.log.Warn().
+			Str("group", group).
+			Strs("unknown-tasks", unknown).
+			Msg("job group policy resource tasks not found in Nomad job, ignoring unknown tasks")
+	}
+
+	// The utilisation of each resource is read using the Nomad resources provider, in the same
+	// manner as the metrics of external checks.
+	var use nomadResources
+
+	provider := ae.nomadResourcesProvider(resources)
+
+	for _, r := range []struct {
+		resource policy.NomadResource
+		value    *float64
+	}{
+		{resource: policy.NomadResourceCPU, value: &use.cpu},
+		{resource: policy.NomadResourceMemory, value: &use.mem},
+		{resource: policy.NomadResourceDisk, value: &use.disk},
+		{resource: policy.NomadResourceGPU, value: &use.gpu},
+	} {
+		value, err := provider.GetValue(ae.context(), nomadResourcesQuery(ae.jobID, group, r.resource))
+		if err != nil {
+			ae.log.Error().Err(err).Str("group", group).Msg("failed to read Nomad resource utilisation")
+			return nil
+		}
+		*r.value = *value
+	}
+
+	ae.applyNomadMetricOverrides(group, &use)
+
 	ae.log.Info().
 		Str("group", group).
-		Float64("mem-value-percentage", memUsage).
-		Float64("cpu-value-percentage", cpuUsage).
+		Float64("mem-value-percentage", use.mem).
+		Float64("cpu-value-percentage", use.cpu).
+		Float64("disk-value-percentage", use.disk).
+		Float64("gpu-value-percentage", use.gpu).
 		Msg("Nomad resource utilisation calculation")
 
-	return ae.calculateNomadScalingDecision(group, &nomadResources{cpu: cpuUsage, mem: memUsage}, pol)
+	ae.recordNomadResources(group, &use, resources.coverage[group])
+	ae.snapshotNomadResources(group, &use, resources.coverage[group])
+	return ae.calculateNomadScalingDecision(group, &use, pol)
 }
 
-func (ae *autoscaleEvaluation) getJobAllocations() (map[string]*nomadResources, []*nomad.Allocation, error) {
-	out := make(map[string]*nomadResources)
-	var allocList []*nomad.Allocation // nolint:prealloc
+func (ae *autoscaleEvaluation) getJobAllocations() ([]*nomad.Allocation, error) {
+	start := time.Now()
+	var allocs []*nomad.AllocationListStub
 
-	allocs, _, err := ae.nomad.Jobs().Allocations(ae.jobID, false, nil)
+	err := ae.callNomad(func() (err error) {
+		allocs, _, err = ae.nomad.Jobs().Allocations(ae.jobID, false, nil)
+		return err
+	})
+	ae.tuner.observeLatency(time.Since(start))
 	if err != nil {
-		return out, nil, err
+		return nil, err
 	}
 
+	var stubs []*nomad.AllocationListStub // nolint:prealloc
+
 	for i := range allocs {
 
 		// GH-70: jobs can have a mix of groups with scaling policies, and groups without. We need
@@ -96,42 +287,360 @@ func (ae *autoscaleEvaluation) getJobAllocations() (map[string]*nomadResources,
 		if !(allocs[i].ClientStatus == nomad.AllocClientStatusRunning || allocs[i].ClientStatus == nomad.AllocClientStatusPending) {
 			continue
 		}
+		stubs = append(stubs, allocs[i])
+	}
 
-		allocInfo, _, err := ae.nomad.Allocations().Info(allocs[i].ID, nil)
-		if err != nil {
-			return out, nil, err
-		}
-		allocList = append(allocList, allocInfo)
+	// Read the allocations concurrently, preserving the order of the allocation list.
+	allocList := make([]*nomad.Allocation, len(stubs))
+	errs := make([]error, len(stubs))
 
-		updateResourceTracker(allocInfo.TaskGroup, float64(*allocInfo.Resources.CPU), float64(*allocInfo.Resources.MemoryMB), out)
+	forEachBounded(len(stubs), ae.groupConcurrency, func(i int) {
+		errs[i] = ae.callNomad(func() (err error) {
+			allocList[i], _, err = ae.nomad.Allocations().Info(stubs[i].ID, nil)
+			return err
+		})
+	})
+
+	for i := range errs {
+		if errs[i] != nil {
+			return nil, errs[i]
+		}
 	}
-	return out, allocList, err
+	return allocList, nil
 }
 
-func (ae *autoscaleEvaluation) getJobResourceUsage(allocs []*nomad.Allocation) (map[string]*nomadResources, error) {
+// getJobResourceUsage gathers the resource usage of the allocations, returning the usage of each
+// group along with the allocations whose usage was gathered. Unless partial stats coverage is
+// allowed, failing to gather the usage of any allocation fails the whole job.
+func (ae *autoscaleEvaluation) getJobResourceUsage(allocs []*nomad.Allocation) (map[string]*nomadResources, []*nomad.Allocation, error) {
 	out := make(map[string]*nomadResources)
+	reachable := make([]*nomad.Allocation, 0, len(allocs))
+
+	// Gather the usage of the allocations concurrently, as each requires a call to the Nomad
+	// client running the allocation. The results are then handled in allocation order.
+	usages := make([]*nomadResources, len(allocs))
+	errs := make([]error, len(allocs))
+
+	forEachBounded(len(allocs), ae.groupConcurrency, func(i int) {
+		usages[i], errs[i] = ae.getAllocUsage(allocs[i])
+	})
 
 	for i := range allocs {
-		stats, err := ae.nomad.Allocations().Stats(allocs[i], nil)
+		usage, err := usages[i], errs[i]
+		if err != nil {
+			if ae.requiredStatsCoverage() >= 100 {
+				return out, nil, err
+			}
+			ae.log.Warn().
+				Err(err).
+				Str("group", allocs[i].TaskGroup).
+				Str("alloc-id", allocs[i].ID).
+				Str("node-id", allocs[i].NodeID).
+				Msg("failed to gather allocation resource usage, excluding allocation from evaluation")
+			continue
+		}
+
+		reachable = append(reachable, allocs[i])
+		updateResourceTracker(allocs[i].TaskGroup, usage, out)
+	}
+	return out, reachable, nil
+}
+
+// getAllocUsage gathers the resource usage of a single allocation from the allocation stats cache
+// when enabled, or otherwise the Nomad client running the allocation.
+func (ae *autoscaleEvaluation) getAllocUsage(alloc *nomad.Allocation) (*nomadResources, error) {
+	stats, ok := ae.allocStats.get(alloc, time.Now())
+	if !ok {
+		err := ae.callNomad(func() (err error) {
+			stats, err = ae.nomad.Allocations().Stats(alloc, nil)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		ae.allocStats.set(alloc, stats, time.Now())
+	}
+
+	pol := ae.policies[alloc.TaskGroup]
+	usage := getAllocResourceUsage(stats, pol.ResourceTasks)
+
+	// The allocation stats endpoint does not include disk usage, so this needs to be calculated
+	// from the allocation filesystem when required. Ephemeral disk is shared by all tasks within
+	// the allocation, so is not subject to task filtering.
+	if pol.NomadDiskChecksEnabled() {
+		diskBytes, err := ae.getAllocDiskUsage(alloc)
+		if err != nil {
+			return nil, err
+		}
+		usage.disk = float64(diskBytes / 1024 / 1024)
+	}
+	return usage, nil
+}
+
+// getAllocResourceInfo returns the resources allocated to the allocation. If tasks is not empty,
+// only the CPU, memory and GPU resources of the named tasks are included.
+func getAllocResourceInfo(alloc *nomad.Allocation, tasks []string) *nomadResources {
+	info := &nomadResources{}
+
+	if alloc.Resources != nil && alloc.Resources.DiskMB != nil {
+		info.disk = float64(*alloc.Resources.DiskMB)
+	}
+
+	if len(tasks) == 0 {
+		info.cpu = float64(*alloc.Resources.CPU)
+		info.mem = float64(*alloc.Resources.MemoryMB)
+
+		for _, res := range alloc.TaskResources {
+			info.gpu += getRequestedGPUs(res)
+		}
+		return info
+	}
+
+	for _, task := range tasks {
+		res, ok := alloc.TaskResources[task]
+		if !ok || res == nil {
+			continue
+		}
+		if res.CPU != nil {
+			info.cpu += float64(*res.CPU)
+		}
+		if res.MemoryMB != nil {
+			info.mem += float64(*res.MemoryMB)
+		}
+		info.gpu += getRequestedGPUs(res)
+	}
+	return info
+}
+
+// getAllocResourceUsage returns the resource usage of the allocation. If tasks is not empty, only
+// the usage of the named tasks is included.
+func getAllocResourceUsage(stats *nomad.AllocResourceUsage, tasks []string) *nomadResources {
+	if len(tasks) == 0 {
+		return resourceUsageToNomadResources(stats.ResourceUsage)
+	}
+
+	usage := &nomadResources{}
+
+	for _, task := range tasks {
+		taskStats, ok := stats.Tasks[task]
+		if !ok || taskStats == nil {
+			continue
+		}
+		taskUsage := resourceUsageToNomadResources(taskStats.ResourceUsage)
+		usage.cpu += taskUsage.cpu
+		usage.mem += taskUsage.mem
+		usage.gpu += taskUsage.gpu
+	}
+	return usage
+}
+
+// resourceUsageToNomadResources converts the Nomad resource usage stats into the internal
+// resource representation.
+func resourceUsageToNomadResources(usage *nomad.ResourceUsage) *nomadResources {
+	out := &nomadResources{}
+
+	if usage == nil {
+		return out
+	}
+	if usage.CpuStats != nil {
+		out.cpu = usage.CpuStats.TotalTicks
+	}
+	if usage.MemoryStats != nil {
+		out.mem = float64(usage.MemoryStats.RSS / 1024 / 1024)
+	}
+	out.gpu = getGPUUtilization(usage.DeviceStats)
+	return out
+}
+
+// getAllocDiskUsage returns the total size in bytes of the files within the ephemeral disk
+// directories of the allocation, which are the shared alloc/data directory and the local
+// directory of each task. The task secrets and shared log directories are not included.
+func (ae *autoscaleEvaluation) getAllocDiskUsage(alloc *nomad.Allocation) (int64, error) {
+	paths := []string{"/alloc/data"}
+	for task := range alloc.TaskResources {
+		paths = append(paths, "/"+task+"/local")
+	}
+	sort.Strings(paths)
+
+	var size int64
+
+	for _, path := range paths {
+		dirSize, err := ae.getAllocDirSize(alloc, path, maxAllocDirDepth)
 		if err != nil {
-			return out, err
+			return 0, err
 		}
+		size += dirSize
+	}
+	return size, nil
+}
+
+// getAllocDirSize walks the allocation filesystem from the passed path, returning the total size
+// in bytes of all files found. Directories are walked to the passed depth, with files below it
+// not included, which bounds the number of Nomad API calls made for deep directory trees.
+func (ae *autoscaleEvaluation) getAllocDirSize(alloc *nomad.Allocation, path string, depth int) (int64, error) {
+	var files []*nomad.AllocFileInfo
 
-		updateResourceTracker(allocs[i].TaskGroup,
-			stats.ResourceUsage.CpuStats.TotalTicks,
-			float64(stats.ResourceUsage.MemoryStats.RSS/1024/1024),
-			out)
+	err := ae.callNomad(func() (err error) {
+		files, _, err = ae.nomad.AllocFS().List(alloc, path, nil)
+		return err
+	})
+	if err != nil {
+		return 0, err
 	}
-	return out, nil
+
+	var size int64
+
+	for i := range files {
+		if !files[i].IsDir {
+			size += files[i].Size
+			continue
+		}
+		if depth <= 0 {
+			continue
+		}
+
+		dirSize, err := ae.getAllocDirSize(alloc, strings.TrimSuffix(path, "/")+"/"+files[i].Name, depth-1)
+		if err != nil {
+			return 0, err
+		}
+		size += dirSize
+	}
+	return size, nil
 }
 
 // updateResourceTracker is responsible for updating the current resource tracking of a job, making
 // sure nothing is overwritten where values already exists.
-func updateResourceTracker(group string, cpu, mem float64, tracker map[string]*nomadResources) {
+func updateResourceTracker(group string, res *nomadResources, tracker map[string]*nomadResources) {
 	if _, ok := tracker[group]; ok {
-		tracker[group].mem += mem
-		tracker[group].cpu += cpu
+		tracker[group].mem += res.mem
+		tracker[group].cpu += res.cpu
+		tracker[group].disk += res.disk
+		tracker[group].gpu += res.gpu
+		return
+	}
+	tracker[group] = &nomadResources{cpu: res.cpu, mem: res.mem, disk: res.disk, gpu: res.gpu}
+}
+
+// getGroupCount returns the current count of the job group. The job is read from Nomad the first
+// time a count is required during the evaluation, with the counts of all groups stored for reuse.
+func (ae *autoscaleEvaluation) getGroupCount(group string) (int, error) {
+	ae.lock.Lock()
+	defer ae.lock.Unlock()
+
+	if ae.groupCounts == nil {
+		job, err := ae.getJob()
+		if err != nil {
+			return 0, err
+		}
+		ae.setGroupCounts(job)
+	}
+
+	count, ok := ae.groupCounts[group]
+	if !ok {
+		return 0, errors.Errorf("job group %s not found", group)
+	}
+	return count, nil
+}
+
+// matchJobFilter returns whether the job under evaluation matches the job filter. If the filter
+// requires the Nomad job, the job is read and the group counts stored for reuse. A failure to read
+// the job results in the evaluation being skipped, as the job may not be the responsibility of
+// this Sherpa server.
+func (ae *autoscaleEvaluation) matchJobFilter() bool {
+	if !ae.jobFilter.MatchID(ae.jobID) {
+		return false
+	}
+	if !ae.jobFilter.RequiresJob() {
+		return true
+	}
+
+	job, err := ae.getJob()
+	if err != nil {
+		if isJobNotFound(err) {
+			ae.handleOrphanedJob(orphanPurged)
+			return false
+		}
+		ae.log.Error().Err(err).Msg("failed to read job to check job filter, skipping evaluation")
+		return false
+	}
+	ae.setGroupCounts(job)
+
+	if !ae.jobFilter.MatchJob(job) {
+		ae.log.Debug().Msg("job does not match job filter, skipping evaluation")
+		return false
+	}
+	return true
+}
+
+func (ae *autoscaleEvaluation) getJob() (*nomad.Job, error) {
+	var job *nomad.Job
+
+	err := ae.callNomad(func() (err error) {
+		job, _, err = ae.nomad.Jobs().Info(ae.jobID, nil)
+		return err
+	})
+	return job, err
+}
+
+// getJobNamespace returns the namespace of the job, reading the job from Nomad if it has not
+// already been read during the evaluation.
+func (ae *autoscaleEvaluation) getJobNamespace() (string, error) {
+	ae.lock.Lock()
+	defer ae.lock.Unlock()
+
+	if ae.groupCounts == nil {
+		job, err := ae.getJob()
+		if err != nil {
+			return "", err
+		}
+		ae.setGroupCounts(job)
+	}
+	return ae.namespace, nil
+}
+
+func (ae *autoscaleEvaluation) setGroupCounts(job *nomad.Job) {
+	ae.namespace = nomad.DefaultNamespace
+	if job.Namespace != nil && *job.Namespace != "" {
+		ae.namespace = *job.Namespace
+	}
+	ae.jobStopped = job.Stop != nil && *job.Stop
+
+	ae.groupCounts = make(map[string]int)
+	for _, tg := range job.TaskGroups {
+		if tg.Name != nil && tg.Count != nil {
+			ae.groupCounts[*tg.Name] = *tg.Count
+		}
+	}
+}
+
+// addPlacementPressureMeta adds the number of queued allocations of each group to the scaling
+// request meta, surfacing cluster placement pressure within the resulting scaling events. Failing
+// to read the job summary does not prevent the scaling from being triggered.
+func (ae *autoscaleEvaluation) addPlacementPressureMeta(reqs []*scale.GroupReq) {
+	var summary *nomad.JobSummary
+
+	err := ae.callNomad(func() (err error) {
+		summary, _, err = ae.nomad.Jobs().Summary(ae.jobID, nil)
+		return err
+	})
+	if err != nil {
+		ae.log.Warn().Err(err).Msg("failed to read job summary, unable to add placement pressure meta")
 		return
 	}
-	tracker[group] = &nomadResources{cpu: cpu, mem: mem}
+	updatePlacementPressureMeta(summary, reqs)
+}
+
+// updatePlacementPressureMeta adds the number of queued allocations to the meta of each request
+// whose group has allocations awaiting placement.
+func updatePlacementPressureMeta(summary *nomad.JobSummary, reqs []*scale.GroupReq) {
+	for _, req := range reqs {
+		tg, ok := summary.Summary[req.GroupName]
+		if !ok || tg.Queued == 0 {
+			continue
+		}
+		if req.Meta == nil {
+			req.Meta = make(map[string]string)
+		}
+		req.Meta[metaKeyQueuedAllocations] = strconv.Itoa(tg.Queued)
+	}
 }
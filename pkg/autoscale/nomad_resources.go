@@ -0,0 +1,103 @@
+package autoscale
+
+import (
+	"context"
+	"strings"
+
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+)
+
+var _ metrics.Provider = (*nomadResourcesProvider)(nil)
+
+// nomadResourcesProvider is the metric provider of the Nomad resource utilisation of the job
+// groups under evaluation, and is used by both the Nomad resource checks and external checks. The
+// allocation resource usage is gathered from Nomad once per job evaluation, so the provider reads
+// the gathered metrics rather than querying Nomad for each group and resource.
+//
+// Queries take the form <job>/<group>/<resource>, where the resource is cpu, memory, disk or gpu.
+// The job must be the job under evaluation.
+type nomadResourcesProvider struct {
+	jobID       string
+	resources   *nomadGatheredMetrics
+	minCoverage float64
+}
+
+// nomadResourcesProvider returns the Nomad resources provider of the job under evaluation, using
+// the passed gathered metrics.
+func (ae *autoscaleEvaluation) nomadResourcesProvider(resources *nomadGatheredMetrics) *nomadResourcesProvider {
+	return &nomadResourcesProvider{jobID: ae.jobID, resources: resources, minCoverage: ae.requiredStatsCoverage()}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface.
+func (p *nomadResourcesProvider) Name() string { return policy.ProviderNomadResources.String() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface. The returned value is
+// the percentage of the resource allocated to the group, via the resource stanza, which is in use.
+func (p *nomadResourcesProvider) GetValue(_ context.Context, query string) (*float64, error) {
+	job, group, resource, err := parseNomadResourcesQuery(query)
+	if err != nil {
+		return nil, err
+	}
+
+	if job != p.jobID {
+		return nil, errors.Errorf("Nomad resource usage is only available for the job under evaluation, not job %s", job)
+	}
+	if p.resources == nil {
+		return nil, errors.New("Nomad resource usage has not been gathered")
+	}
+	if cov, ok := p.resources.coverage[group]; ok && cov < p.minCoverage {
+		return nil, errors.Errorf("insufficient allocation stats gathered for job group %s", group)
+	}
+
+	info, ok := p.resources.resourceInfo[group]
+	usage, usageOK := p.resources.resourceUsage[group]
+	if !ok || !usageOK {
+		return nil, errors.Errorf("job group %s not found in Nomad job", group)
+	}
+	return helper.Float64ToPointer(resourceUtilisation(info, usage, resource)), nil
+}
+
+// resourceUtilisation returns the percentage of the allocated resource which is in use.
+func resourceUtilisation(info, usage *nomadResources, resource policy.NomadResource) float64 {
+	switch resource {
+	case policy.NomadResourceCPU:
+		return usage.cpu * 100 / info.cpu
+	case policy.NomadResourceMemory:
+		return usage.mem * 100 / info.mem
+	case policy.NomadResourceDisk:
+		// Disk usage is only gathered when the policy requires it, and a group may not have any
+		// ephemeral disk configured, so protect against dividing by zero.
+		if info.disk > 0 {
+			return usage.disk * 100 / info.disk
+		}
+	case policy.NomadResourceGPU:
+		// GPU utilisation is reported by Nomad as a percentage per GPU instance, therefore the
+		// group utilisation is the average across all the instances requested by the group.
+		if info.gpu > 0 {
+			return usage.gpu / info.gpu
+		}
+	}
+	return 0
+}
+
+// nomadResourcesQuery returns the Nomad resources provider query of the job group resource.
+func nomadResourcesQuery(job, group string, resource policy.NomadResource) string {
+	return job + "/" + group + "/" + resource.String()
+}
+
+// parseNomadResourcesQuery splits the query into the job, group and resource.
+func parseNomadResourcesQuery(query string) (string, string, policy.NomadResource, error) {
+	parts := strings.Split(query, "/")
+	if len(parts) != 3 || parts[0] == "" || parts[1] == "" {
+		return "", "", "", errors.Errorf("invalid Nomad resources query %q, expected <job>/<group>/<resource>", query)
+	}
+
+	resource := policy.NomadResource(parts[2])
+	if err := resource.Validate(); err != nil {
+		return "", "", "", errors.Wrapf(err, "invalid Nomad resources query %q", query)
+	}
+	return parts[0], parts[1], resource, nil
+}
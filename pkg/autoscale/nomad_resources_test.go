@@ -0,0 +1,88 @@
+package autoscale
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_nomadResourcesProvider_GetValue(t *testing.T) {
+	p := &nomadResourcesProvider{
+		jobID: "example",
+		resources: &nomadGatheredMetrics{
+			resourceInfo: map[string]*nomadResources{
+				"cache":   {cpu: 500, mem: 256, disk: 300, gpu: 2},
+				"partial": {cpu: 500, mem: 256},
+			},
+			resourceUsage: map[string]*nomadResources{
+				"cache":   {cpu: 250, mem: 64, disk: 30, gpu: 150},
+				"partial": {cpu: 250, mem: 64},
+			},
+			coverage: map[string]float64{"cache": 100, "partial": 50},
+		},
+		minCoverage: 100,
+	}
+
+	testCases := []struct {
+		query         string
+		expectedValue float64
+		expectedErr   bool
+		name          string
+	}{
+		{query: "example/cache/cpu", expectedValue: 50, name: "cpu"},
+		{query: "example/cache/memory", expectedValue: 25, name: "memory"},
+		{query: "example/cache/disk", expectedValue: 10, name: "disk"},
+		{query: "example/cache/gpu", expectedValue: 75, name: "gpu"},
+		{query: "example/partial/cpu", expectedErr: true, name: "insufficient coverage"},
+		{query: "example/missing/cpu", expectedErr: true, name: "unknown group"},
+		{query: "other/cache/cpu", expectedErr: true, name: "different job"},
+		{query: "example/cache/network", expectedErr: true, name: "unsupported resource"},
+		{query: "example/cpu", expectedErr: true, name: "missing group"},
+	}
+
+	for _, tc := range testCases {
+		value, err := p.GetValue(context.Background(), tc.query)
+		if tc.expectedErr {
+			assert.NotNil(t, err, tc.name)
+			continue
+		}
+		if assert.Nil(t, err, tc.name) {
+			assert.Equal(t, tc.expectedValue, *value, tc.name)
+		}
+	}
+
+	// Without gathered metrics no value can be returned.
+	_, err := (&nomadResourcesProvider{jobID: "example"}).GetValue(context.Background(), "example/cache/cpu")
+	assert.NotNil(t, err)
+}
+
+func Test_autoscaleEvaluation_evaluateExternalMetricNomadResources(t *testing.T) {
+	ae := &autoscaleEvaluation{
+		jobID: "example",
+		log:   zerolog.Nop(),
+		nomadMetricData: &nomadGatheredMetrics{
+			resourceInfo:  map[string]*nomadResources{"cache": {cpu: 500, mem: 256}},
+			resourceUsage: map[string]*nomadResources{"cache": {cpu: 450, mem: 64}},
+		},
+	}
+
+	// External checks which do not set a provider use the Nomad resources provider.
+	check := &policy.ExternalCheck{
+		Enabled:            true,
+		Query:              "example/cache/cpu",
+		ComparisonOperator: policy.ComparisonGreaterThan,
+		ComparisonValue:    80,
+		Action:             policy.ActionScaleOut,
+	}
+
+	dec, ok := ae.evaluateExternalMetric("cache", "cpu", check)
+	assert.True(t, ok)
+	if assert.NotNil(t, dec) {
+		assert.Equal(t, scale.DirectionOut, dec.direction)
+		assert.Equal(t, float64(90), dec.metrics["cpu"].value)
+	}
+}
@@ -1,37 +1,233 @@
 package autoscale
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
 
 func Test_updateResourceTracker(t *testing.T) {
 	testCases := []struct {
 		group    string
-		cpu      float64
-		mem      float64
+		res      *nomadResources
 		tracker  map[string]*nomadResources
 		expected map[string]*nomadResources
 	}{
 		{
 			group:    "cache",
-			cpu:      100,
-			mem:      100,
+			res:      &nomadResources{cpu: 100, mem: 100},
 			tracker:  map[string]*nomadResources{"cache": {cpu: 100, mem: 100}},
 			expected: map[string]*nomadResources{"cache": {cpu: 200, mem: 200}},
 		},
 		{
 			group:    "cache",
-			cpu:      200,
-			mem:      200,
+			res:      &nomadResources{cpu: 200, mem: 200},
 			tracker:  map[string]*nomadResources{},
 			expected: map[string]*nomadResources{"cache": {cpu: 200, mem: 200}},
 		},
+		{
+			group:    "cache",
+			res:      &nomadResources{cpu: 200, mem: 200, disk: 300},
+			tracker:  map[string]*nomadResources{"cache": {cpu: 100, mem: 100, disk: 150}},
+			expected: map[string]*nomadResources{"cache": {cpu: 300, mem: 300, disk: 450}},
+		},
 	}
 
 	for _, tc := range testCases {
-		updateResourceTracker(tc.group, tc.cpu, tc.mem, tc.tracker)
+		updateResourceTracker(tc.group, tc.res, tc.tracker)
 		assert.Equal(t, tc.expected, tc.tracker)
 	}
 }
+
+func Test_getAllocResourceInfo(t *testing.T) {
+	cpu, mem, disk := 600, 768, 300
+	appCPU, appMem := 500, 512
+
+	alloc := &nomad.Allocation{
+		Resources: &nomad.Resources{CPU: &cpu, MemoryMB: &mem, DiskMB: &disk},
+		TaskResources: map[string]*nomad.Resources{
+			"app":   {CPU: &appCPU, MemoryMB: &appMem},
+			"envoy": {CPU: helper.IntToPointer(100), MemoryMB: helper.IntToPointer(256)},
+		},
+	}
+
+	assert.Equal(t, &nomadResources{cpu: 600, mem: 768, disk: 300}, getAllocResourceInfo(alloc, nil))
+	assert.Equal(t, &nomadResources{cpu: 500, mem: 512, disk: 300}, getAllocResourceInfo(alloc, []string{"app"}))
+	assert.Equal(t, &nomadResources{disk: 300}, getAllocResourceInfo(alloc, []string{"missing"}))
+}
+
+func Test_getUnknownResourceTasks(t *testing.T) {
+	alloc := &nomad.Allocation{
+		TaskResources: map[string]*nomad.Resources{"app": {}, "envoy": {}},
+	}
+
+	assert.Nil(t, getUnknownResourceTasks(alloc, nil))
+	assert.Nil(t, getUnknownResourceTasks(alloc, []string{"app", "envoy"}))
+	assert.Equal(t, []string{"missing"}, getUnknownResourceTasks(alloc, []string{"app", "missing"}))
+}
+
+func Test_autoscaleEvaluation_evaluateNomadJobMetricsUnknownTasks(t *testing.T) {
+	pol := &policy.GroupScalingPolicy{
+		ScaleOutCPUPercentageThreshold: helper.Float64ToPointer(80),
+		ScaleOutCount:                  1,
+		ResourceTasks:                  []string{"missing"},
+	}
+	ae := &autoscaleEvaluation{jobID: "example", policies: map[string]*policy.GroupScalingPolicy{"worker": pol}}
+	resources := &nomadGatheredMetrics{
+		resourceInfo:  map[string]*nomadResources{"worker": {}},
+		resourceUsage: map[string]*nomadResources{"worker": {}},
+		coverage:      map[string]float64{"worker": 100},
+		unknownTasks:  map[string][]string{"worker": {"missing"}},
+	}
+
+	// With no allocated resources the utilisation is not a number, so the checks are skipped.
+	assert.Nil(t, ae.evaluateNomadJobMetrics("worker", pol, resources))
+
+	// Unknown tasks are ignored when other resource tasks were found.
+	pol.ResourceTasks = []string{"app", "missing"}
+	resources.resourceInfo["worker"] = &nomadResources{cpu: 100, mem: 100}
+	resources.resourceUsage["worker"] = &nomadResources{cpu: 90, mem: 10}
+
+	dec := ae.evaluateNomadJobMetrics("worker", pol, resources)
+	if assert.NotNil(t, dec) {
+		assert.Equal(t, scale.DirectionOut, dec.direction)
+	}
+}
+
+func Test_autoscaleEvaluation_getAllocDiskUsage(t *testing.T) {
+	dirs := map[string]string{
+		"/alloc/data":          `[{"Name":"db","IsDir":true},{"Name":"index","Size":100}]`,
+		"/alloc/data/db":       `[{"Name":"1","IsDir":true},{"Name":"wal","Size":10}]`,
+		"/alloc/data/db/1":     `[{"Name":"2","IsDir":true}]`,
+		"/alloc/data/db/1/2":   `[{"Name":"3","IsDir":true},{"Name":"page","Size":1}]`,
+		"/alloc/data/db/1/2/3": `[{"Name":"4","IsDir":true},{"Name":"page","Size":1}]`,
+		"/app/local":           `[{"Name":"cache","Size":1000}]`,
+	}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/client/fs/ls/example", r.URL.Path)
+
+		// Only the ephemeral disk directories are listed, and the walk stops at the maximum
+		// depth, so the 4 directory and the alloc logs and task secrets are never listed.
+		body, ok := dirs[r.URL.Query().Get("path")]
+		if !assert.True(t, ok, r.URL.Query().Get("path")) {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(body))
+	}))
+	defer srv.Close()
+
+	nomadClient, err := nomad.NewClient(&nomad.Config{Address: srv.URL})
+	assert.Nil(t, err)
+
+	ae := &autoscaleEvaluation{nomad: nomadClient, log: zerolog.Nop()}
+	alloc := &nomad.Allocation{ID: "example", TaskResources: map[string]*nomad.Resources{"app": {}}}
+
+	size, err := ae.getAllocDiskUsage(alloc)
+	assert.Nil(t, err)
+	assert.Equal(t, int64(1112), size)
+}
+
+func Test_getAllocResourceUsage(t *testing.T) {
+	stats := &nomad.AllocResourceUsage{
+		ResourceUsage: &nomad.ResourceUsage{
+			CpuStats:    &nomad.CpuStats{TotalTicks: 300},
+			MemoryStats: &nomad.MemoryStats{RSS: 300 * 1024 * 1024},
+		},
+		Tasks: map[string]*nomad.TaskResourceUsage{
+			"app": {ResourceUsage: &nomad.ResourceUsage{
+				CpuStats:    &nomad.CpuStats{TotalTicks: 250},
+				MemoryStats: &nomad.MemoryStats{RSS: 200 * 1024 * 1024},
+			}},
+			"envoy": {ResourceUsage: &nomad.ResourceUsage{
+				CpuStats:    &nomad.CpuStats{TotalTicks: 50},
+				MemoryStats: &nomad.MemoryStats{RSS: 100 * 1024 * 1024},
+			}},
+		},
+	}
+
+	assert.Equal(t, &nomadResources{cpu: 300, mem: 300}, getAllocResourceUsage(stats, nil))
+	assert.Equal(t, &nomadResources{cpu: 250, mem: 200}, getAllocResourceUsage(stats, []string{"app"}))
+}
+
+func Test_autoscaleEvaluation_getGroupCount(t *testing.T) {
+	ae := &autoscaleEvaluation{groupCounts: map[string]int{"worker": 4}}
+
+	count, err := ae.getGroupCount("worker")
+	assert.Nil(t, err)
+	assert.Equal(t, 4, count)
+
+	_, err = ae.getGroupCount("cache")
+	assert.NotNil(t, err)
+}
+
+func Test_updatePlacementPressureMeta(t *testing.T) {
+	summary := &nomad.JobSummary{
+		Summary: map[string]nomad.TaskGroupSummary{
+			"worker": {Queued: 2, Running: 3},
+			"cache":  {Running: 1},
+		},
+	}
+	reqs := []*scale.GroupReq{
+		{GroupName: "worker", Meta: map[string]string{"cpu-value": "90.00"}},
+		{GroupName: "cache", Meta: map[string]string{}},
+		{GroupName: "api"},
+	}
+
+	updatePlacementPressureMeta(summary, reqs)
+	assert.Equal(t, map[string]string{"cpu-value": "90.00", metaKeyQueuedAllocations: "2"}, reqs[0].Meta)
+	assert.Equal(t, map[string]string{}, reqs[1].Meta)
+	assert.Nil(t, reqs[2].Meta)
+}
+
+func Test_calculateStatsCoverage(t *testing.T) {
+	allocs := []*nomad.Allocation{
+		{TaskGroup: "worker"}, {TaskGroup: "worker"}, {TaskGroup: "worker"}, {TaskGroup: "worker"},
+		{TaskGroup: "cache"}, {TaskGroup: "cache"},
+	}
+
+	actual := calculateStatsCoverage(allocs, map[string]int{"worker": 3})
+	assert.Equal(t, map[string]float64{"worker": 75, "cache": 0}, actual)
+}
+
+func Test_autoscaleEvaluation_hasNomadMetrics(t *testing.T) {
+	ae := &autoscaleEvaluation{}
+	assert.False(t, ae.hasNomadMetrics("worker"))
+
+	ae.nomadMetricData = &nomadGatheredMetrics{coverage: map[string]float64{"worker": 75, "cache": 100}}
+
+	// Without a configured minimum, the stats of all allocations are required.
+	assert.False(t, ae.hasNomadMetrics("worker"))
+	assert.True(t, ae.hasNomadMetrics("cache"))
+
+	ae.minStatsCoverage = 50
+	assert.True(t, ae.hasNomadMetrics("worker"))
+
+	ae.minStatsCoverage = 80
+	assert.False(t, ae.hasNomadMetrics("worker"))
+}
+
+func Test_autoscaleEvaluation_addStatsCoverageMeta(t *testing.T) {
+	ae := &autoscaleEvaluation{
+		nomadMetricData: &nomadGatheredMetrics{coverage: map[string]float64{"worker": 75, "cache": 100}},
+	}
+	nomadDec := &scalingDecision{metrics: map[string]*scalingMetricDecision{nomadCPUMetricName: {}}}
+	externalDec := &scalingDecision{metrics: map[string]*scalingMetricDecision{"prometheus": {}}}
+
+	meta := make(map[string]string)
+	ae.addStatsCoverageMeta("worker", nomadDec, meta)
+	assert.Equal(t, map[string]string{metaKeyStatsCoverage: "75.0"}, meta)
+
+	meta = make(map[string]string)
+	ae.addStatsCoverageMeta("worker", externalDec, meta)
+	ae.addStatsCoverageMeta("cache", nomadDec, meta)
+	assert.Empty(t, meta)
+}
@@ -0,0 +1,139 @@
+package autoscale
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/pkg/errors"
+)
+
+// groupHealthPollInterval is the interval at which Nomad is queried when waiting for an ordered
+// scaling step to become healthy.
+const groupHealthPollInterval = 5 * time.Second
+
+// buildScalingSteps splits the group requests into the ordered steps in which they should be
+// triggered, using the ScaleOrder of each group policy. Groups sharing an order are placed in the
+// same step, and the relative order of the requests within a step is preserved.
+func buildScalingSteps(reqs []*scale.GroupReq) [][]*scale.GroupReq {
+	sorted := make([]*scale.GroupReq, len(reqs))
+	copy(sorted, reqs)
+
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return getScaleOrder(sorted[i]) < getScaleOrder(sorted[j])
+	})
+
+	var steps [][]*scale.GroupReq // nolint:prealloc
+
+	for i, req := range sorted {
+		if i == 0 || getScaleOrder(sorted[i-1]) != getScaleOrder(req) {
+			steps = append(steps, []*scale.GroupReq{})
+		}
+		steps[len(steps)-1] = append(steps[len(steps)-1], req)
+	}
+	return steps
+}
+
+func getScaleOrder(req *scale.GroupReq) int {
+	if req.GroupScalingPolicy == nil {
+		return 0
+	}
+	return req.GroupScalingPolicy.ScaleOrder
+}
+
+// getStepWaitTimeout returns the time to wait for the groups of a scaling step to become healthy,
+// using the largest WaitForHealthyTimeout configured on the groups within the step.
+func getStepWaitTimeout(step []*scale.GroupReq) time.Duration {
+	var timeout int
+
+	for _, req := range step {
+		if req.GroupScalingPolicy != nil && req.GroupScalingPolicy.WaitForHealthyTimeout > timeout {
+			timeout = req.GroupScalingPolicy.WaitForHealthyTimeout
+		}
+	}
+	return time.Duration(timeout) * time.Second
+}
+
+// waitForGroupsHealthy polls Nomad until all the groups of the scaling step have reached their
+// desired count with all allocations running, or the timeout is reached.
+func (ae *autoscaleEvaluation) waitForGroupsHealthy(step []*scale.GroupReq, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	ticker := time.NewTicker(groupHealthPollInterval)
+	defer ticker.Stop()
+
+	for {
+		healthy, err := ae.groupsHealthy(ctx, step)
+		if err != nil {
+			ae.log.Warn().Err(err).Msg("failed to check health of scaled job groups")
+		}
+		if healthy {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return errors.New("timed out waiting for scaled job groups to become healthy")
+		case <-ticker.C:
+		}
+	}
+}
+
+// groupsHealthy checks whether the groups of the scaling step have reached their desired count
+// with all allocations running.
+func (ae *autoscaleEvaluation) groupsHealthy(ctx context.Context, step []*scale.GroupReq) (bool, error) {
+	var (
+		job     *nomad.Job
+		summary *nomad.JobSummary
+	)
+
+	err := ae.callNomadWithContext(ctx, func() (err error) {
+		job, _, err = ae.nomad.Jobs().Info(ae.jobID, nil)
+		return err
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to call Nomad API for job information")
+	}
+
+	err = ae.callNomadWithContext(ctx, func() (err error) {
+		summary, _, err = ae.nomad.Jobs().Summary(ae.jobID, nil)
+		return err
+	})
+	if err != nil {
+		return false, errors.Wrap(err, "failed to call Nomad API for job summary")
+	}
+
+	return groupsReachedCount(job, summary, step), nil
+}
+
+// groupsReachedCount checks the job summary to identify whether each group within the step has
+// the desired number of running allocations, with none starting or queued.
+func groupsReachedCount(job *nomad.Job, summary *nomad.JobSummary, step []*scale.GroupReq) bool {
+	desired := make(map[string]int, len(job.TaskGroups))
+
+	for _, tg := range job.TaskGroups {
+		if tg.Name != nil && tg.Count != nil {
+			desired[*tg.Name] = *tg.Count
+		}
+	}
+
+	for _, req := range step {
+		count, ok := desired[req.GroupName]
+		if !ok {
+			return false
+		}
+
+		tgSummary, ok := summary.Summary[req.GroupName]
+		if !ok {
+			return false
+		}
+
+		if tgSummary.Running < count || tgSummary.Starting > 0 || tgSummary.Queued > 0 {
+			return false
+		}
+	}
+	return true
+}
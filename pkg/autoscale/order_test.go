@@ -0,0 +1,94 @@
+package autoscale
+
+import (
+	"testing"
+	"time"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_buildScalingSteps(t *testing.T) {
+	proxy := &scale.GroupReq{GroupName: "proxy", GroupScalingPolicy: &policy.GroupScalingPolicy{ScaleOrder: 1}}
+	cache := &scale.GroupReq{GroupName: "cache", GroupScalingPolicy: &policy.GroupScalingPolicy{ScaleOrder: 2}}
+	app := &scale.GroupReq{GroupName: "app", GroupScalingPolicy: &policy.GroupScalingPolicy{ScaleOrder: 2}}
+	unordered := &scale.GroupReq{GroupName: "unordered"}
+
+	testCases := []struct {
+		input          []*scale.GroupReq
+		expectedOutput [][]*scale.GroupReq
+		name           string
+	}{
+		{
+			input:          []*scale.GroupReq{unordered},
+			expectedOutput: [][]*scale.GroupReq{{unordered}},
+			name:           "single group without policy",
+		},
+		{
+			input:          []*scale.GroupReq{app, cache, proxy},
+			expectedOutput: [][]*scale.GroupReq{{proxy}, {app, cache}},
+			name:           "groups sharing order are scaled together",
+		},
+		{
+			input:          []*scale.GroupReq{app, proxy, unordered},
+			expectedOutput: [][]*scale.GroupReq{{unordered}, {proxy}, {app}},
+			name:           "groups without order are scaled first",
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedOutput, buildScalingSteps(tc.input), tc.name)
+	}
+}
+
+func Test_getStepWaitTimeout(t *testing.T) {
+	step := []*scale.GroupReq{
+		{GroupName: "proxy"},
+		{GroupName: "cache", GroupScalingPolicy: &policy.GroupScalingPolicy{WaitForHealthyTimeout: 30}},
+		{GroupName: "app", GroupScalingPolicy: &policy.GroupScalingPolicy{WaitForHealthyTimeout: 90}},
+	}
+	assert.Equal(t, 90*time.Second, getStepWaitTimeout(step))
+	assert.Equal(t, time.Duration(0), getStepWaitTimeout(step[:1]))
+}
+
+func Test_groupsReachedCount(t *testing.T) {
+	job := &nomad.Job{TaskGroups: []*nomad.TaskGroup{
+		{Name: helper.StringToPointer("proxy"), Count: helper.IntToPointer(3)},
+	}}
+	step := []*scale.GroupReq{{GroupName: "proxy"}}
+
+	testCases := []struct {
+		summary        map[string]nomad.TaskGroupSummary
+		expectedOutput bool
+		name           string
+	}{
+		{
+			summary:        map[string]nomad.TaskGroupSummary{"proxy": {Running: 3}},
+			expectedOutput: true,
+			name:           "all allocations running",
+		},
+		{
+			summary:        map[string]nomad.TaskGroupSummary{"proxy": {Running: 2, Starting: 1}},
+			expectedOutput: false,
+			name:           "allocations starting",
+		},
+		{
+			summary:        map[string]nomad.TaskGroupSummary{"proxy": {Running: 3, Queued: 1}},
+			expectedOutput: false,
+			name:           "allocations queued",
+		},
+		{
+			summary:        map[string]nomad.TaskGroupSummary{},
+			expectedOutput: false,
+			name:           "group missing from summary",
+		},
+	}
+
+	for _, tc := range testCases {
+		actualOutput := groupsReachedCount(job, &nomad.JobSummary{Summary: tc.summary}, step)
+		assert.Equal(t, tc.expectedOutput, actualOutput, tc.name)
+	}
+}
@@ -0,0 +1,210 @@
+package autoscale
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/state"
+)
+
+const (
+	// orphanPurged and orphanStopped describe why the job of a scaling policy is no longer
+	// running on the Nomad cluster.
+	orphanPurged  = "purged"
+	orphanStopped = "stopped"
+
+	// metaKeyJobState is the scaling event meta key which details whether the job of an orphaned
+	// policy was stopped or purged.
+	metaKeyJobState = "job-state"
+)
+
+// orphanTracker records when the jobs of scaling policies were first found to be stopped or
+// purged, so that each orphaned job is only reported once and its policy can be removed after a
+// period of time.
+type orphanTracker struct {
+	lock  sync.Mutex
+	since map[string]time.Time
+}
+
+func newOrphanTracker() *orphanTracker {
+	return &orphanTracker{since: make(map[string]time.Time)}
+}
+
+// mark records the job as orphaned at the passed time, returning true if the job was not
+// already tracked as orphaned.
+func (ot *orphanTracker) mark(job string, t time.Time) bool {
+	ot.lock.Lock()
+	defer ot.lock.Unlock()
+
+	if _, ok := ot.since[job]; ok {
+		return false
+	}
+	ot.since[job] = t
+	return true
+}
+
+// clear removes the job from the tracker, as it is running or its policy has been removed.
+func (ot *orphanTracker) clear(job string) {
+	ot.lock.Lock()
+	delete(ot.since, job)
+	ot.lock.Unlock()
+}
+
+// expired returns the jobs, sorted by name, which have been orphaned for at least the passed
+// duration.
+func (ot *orphanTracker) expired(d time.Duration, t time.Time) []string {
+	ot.lock.Lock()
+	defer ot.lock.Unlock()
+
+	var jobs []string
+
+	for job, since := range ot.since {
+		if t.Sub(since) >= d {
+			jobs = append(jobs, job)
+		}
+	}
+	sort.Strings(jobs)
+	return jobs
+}
+
+// prune removes the tracked jobs which no longer have a scaling policy.
+func (ot *orphanTracker) prune(policies map[string]map[string]*policy.GroupScalingPolicy) {
+	ot.lock.Lock()
+	defer ot.lock.Unlock()
+
+	for job := range ot.since {
+		if _, ok := policies[job]; !ok {
+			delete(ot.since, job)
+		}
+	}
+}
+
+// snapshot returns a copy of the tracked orphaned jobs.
+func (ot *orphanTracker) snapshot() map[string]time.Time {
+	ot.lock.Lock()
+	defer ot.lock.Unlock()
+
+	out := make(map[string]time.Time, len(ot.since))
+	for job, t := range ot.since {
+		out[job] = t
+	}
+	return out
+}
+
+// restore tracks the passed orphaned jobs, keeping the earlier time of jobs already tracked.
+func (ot *orphanTracker) restore(since map[string]time.Time) {
+	ot.lock.Lock()
+	defer ot.lock.Unlock()
+
+	for job, t := range since {
+		if existing, ok := ot.since[job]; !ok || t.Before(existing) {
+			ot.since[job] = t
+		}
+	}
+}
+
+// isJobNotFound identifies whether the Nomad API error was a result of the job not being found.
+func isJobNotFound(err error) bool {
+	return strings.Contains(err.Error(), "404")
+}
+
+// checkOrphaned returns true if the job under evaluation has been stopped or purged, in which
+// case the evaluation is skipped. The job read is stored for reuse by the evaluation checks. A
+// failure to read the job for any other reason is left for the checks which require the job to
+// handle.
+func (ae *autoscaleEvaluation) checkOrphaned() bool {
+	ae.lock.Lock()
+	defer ae.lock.Unlock()
+
+	if ae.groupCounts == nil {
+		job, err := ae.getJob()
+		if err != nil {
+			if !isJobNotFound(err) {
+				return false
+			}
+			ae.handleOrphanedJob(orphanPurged)
+			return true
+		}
+		ae.setGroupCounts(job)
+	}
+
+	if ae.jobStopped {
+		ae.handleOrphanedJob(orphanStopped)
+		return true
+	}
+
+	ae.orphans.clear(ae.jobID)
+	return false
+}
+
+// handleOrphanedJob skips the enabled groups of the job under evaluation, as the job is no longer
+// running. The first detection of the orphaned job is logged and recorded as a scaling event for
+// each group, with later detections only logged at debug level until the job runs again.
+func (ae *autoscaleEvaluation) handleOrphanedJob(kind string) {
+	groups := make([]string, 0, len(ae.policies))
+	for group, pol := range ae.policies {
+		if pol.Enabled {
+			groups = append(groups, group)
+		}
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		sendSkippedMetrics(ae.jobID, group, state.ReasonJobOrphaned)
+	}
+
+	if !ae.orphans.mark(ae.jobID, time.Unix(0, ae.time)) {
+		ae.log.Debug().Str("job-state", kind).Msg("job remains orphaned, skipping evaluation")
+		return
+	}
+
+	ae.log.Warn().
+		Str("job-state", kind).
+		Msg("job of scaling policy is no longer running, skipping evaluation")
+	sendMetrics.IncrCounterWithLabels([]string{"autoscale", "orphaned_job"}, 1, []sendMetrics.Label{
+		{Name: "job", Value: ae.jobID},
+		{Name: "state", Value: kind},
+	})
+
+	ae.scaler.RecordSkip(ae.jobID, groups, state.ReasonJobOrphaned, state.SourceInternalAutoscaler,
+		map[string]string{metaKeyJobState: kind})
+}
+
+// gcOrphanedPolicies deletes the scaling policies of jobs which have been orphaned for longer
+// than the configured period, returning the jobs whose policies were deleted. Policies are not
+// deleted when the autoscaler is in dry-run mode.
+func (a *AutoScale) gcOrphanedPolicies(t time.Time) map[string]bool {
+	deleted := make(map[string]bool)
+
+	if a.cfg.OrphanPolicyGC == 0 || a.cfg.DryRun {
+		return deleted
+	}
+
+	for _, job := range a.orphans.expired(time.Duration(a.cfg.OrphanPolicyGC)*time.Second, t) {
+		if err := a.deleteJobPolicy(job); err != nil {
+			a.logger.Error().Str("job", job).Err(err).Msg("failed to delete scaling policy of orphaned job")
+			continue
+		}
+
+		a.orphans.clear(job)
+		deleted[job] = true
+
+		a.logger.Info().Str("job", job).Msg("deleted scaling policy of orphaned job")
+		sendMetrics.IncrCounter([]string{"autoscale", "orphaned_job", "policy_gc"}, 1)
+	}
+	return deleted
+}
+
+// deleteJobPolicy deletes the scaling policy of the job, bounding the call using the Nomad API
+// timeout as the backend may be the Nomad job meta.
+func (a *AutoScale) deleteJobPolicy(job string) error {
+	ctx, cancel := helper.ContextWithTimeout(context.Background(), time.Duration(a.cfg.NomadTimeout)*time.Second)
+	defer cancel()
+	return a.policyBackend.DeleteJobPolicy(ctx, job)
+}
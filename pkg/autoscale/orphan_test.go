@@ -0,0 +1,115 @@
+package autoscale
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	policyMemory "github.com/jrasell/sherpa/pkg/policy/backend/memory"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
+	stateMemory "github.com/jrasell/sherpa/pkg/state/scale/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_orphanTracker(t *testing.T) {
+	ot := newOrphanTracker()
+	now := time.Unix(1580000000, 0)
+
+	assert.True(t, ot.mark("example", now))
+	assert.False(t, ot.mark("example", now.Add(time.Minute)))
+	assert.True(t, ot.mark("batch", now.Add(time.Minute)))
+
+	assert.Equal(t, []string{"batch", "example"}, ot.expired(time.Minute, now.Add(2*time.Minute)))
+	assert.Equal(t, []string{"example"}, ot.expired(90*time.Second, now.Add(2*time.Minute)))
+
+	// Jobs which run again are reported as newly orphaned if stopped once more.
+	ot.clear("example")
+	assert.True(t, ot.mark("example", now.Add(2*time.Minute)))
+
+	ot.prune(map[string]map[string]*policy.GroupScalingPolicy{"example": {}})
+	assert.NotContains(t, ot.since, "batch")
+	assert.Contains(t, ot.since, "example")
+}
+
+func Test_isJobNotFound(t *testing.T) {
+	assert.True(t, isJobNotFound(errors.New("Unexpected response code: 404 (job not found)")))
+	assert.False(t, isJobNotFound(errors.New("Unexpected response code: 500 (rpc error)")))
+}
+
+func TestAutoscaleEvaluation_checkOrphaned(t *testing.T) {
+	backend := stateMemory.NewStateBackend()
+
+	ae := &autoscaleEvaluation{
+		jobID:       "example",
+		time:        time.Unix(1580000000, 0).UnixNano(),
+		log:         zerolog.Nop(),
+		scaler:      scale.NewScaler(nil, zerolog.Nop(), backend, false, 0, nil),
+		orphans:     newOrphanTracker(),
+		groupCounts: map[string]int{"cache": 0},
+		jobStopped:  true,
+		policies: map[string]*policy.GroupScalingPolicy{
+			"cache": {Enabled: true},
+			"batch": {Enabled: false},
+		},
+	}
+
+	// The first detection records a skip event for the enabled groups.
+	assert.True(t, ae.checkOrphaned())
+
+	events, err := backend.GetScalingEvents(nil)
+	assert.Nil(t, err)
+	assert.Len(t, events, 1)
+
+	latest, err := backend.GetLatestScalingEvent("example", "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, state.ReasonJobOrphaned, latest.Reason)
+	assert.Equal(t, map[string]string{metaKeyJobState: orphanStopped}, latest.Meta)
+
+	// Later detections do not record further events.
+	assert.True(t, ae.checkOrphaned())
+	events, err = backend.GetScalingEvents(nil)
+	assert.Nil(t, err)
+	assert.Len(t, events, 1)
+
+	// Once the job is running the orphan is cleared.
+	ae.jobStopped = false
+	assert.False(t, ae.checkOrphaned())
+	assert.NotContains(t, ae.orphans.since, "example")
+}
+
+func TestAutoScale_gcOrphanedPolicies(t *testing.T) {
+	policies := policyMemory.NewJobScalingPolicies()
+	assert.Nil(t, policies.PutJobPolicy(context.Background(), "example", map[string]*policy.GroupScalingPolicy{"cache": {Enabled: true}}))
+	assert.Nil(t, policies.PutJobPolicy(context.Background(), "batch", map[string]*policy.GroupScalingPolicy{"cache": {Enabled: true}}))
+
+	now := time.Unix(1580000000, 0)
+
+	a := &AutoScale{
+		cfg:           &Config{},
+		logger:        zerolog.Nop(),
+		policyBackend: policies,
+		orphans:       newOrphanTracker(),
+	}
+	a.orphans.mark("example", now)
+	a.orphans.mark("batch", now.Add(time.Minute))
+
+	// Policies are not deleted when the GC is disabled.
+	assert.Len(t, a.gcOrphanedPolicies(now.Add(time.Hour)), 0)
+
+	a.cfg.OrphanPolicyGC = 90
+	assert.Equal(t, map[string]bool{"example": true}, a.gcOrphanedPolicies(now.Add(2*time.Minute)))
+
+	remaining, err := policies.GetPolicies(context.Background())
+	assert.Nil(t, err)
+	assert.NotContains(t, remaining, "example")
+	assert.Contains(t, remaining, "batch")
+	assert.NotContains(t, a.orphans.since, "example")
+
+	// Dry-run mode never deletes policies.
+	a.cfg.DryRun = true
+	assert.Len(t, a.gcOrphanedPolicies(now.Add(time.Hour)), 0)
+}
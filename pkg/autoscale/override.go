@@ -0,0 +1,217 @@
+package autoscale
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+)
+
+// maxMetricOverrideTTL bounds how long a metric override can be active, so that a forgotten
+// override cannot control the scaling of a group indefinitely.
+const maxMetricOverrideTTL = 24 * time.Hour
+
+var (
+	// ErrCheckNotFound is returned when a metric override is requested for a check which is not
+	// configured within the group scaling policy.
+	ErrCheckNotFound = errors.New("scaling policy check not found")
+
+	// ErrOverrideNotFound is returned when deleting a metric override which does not exist.
+	ErrOverrideNotFound = errors.New("metric override not found")
+
+	// ErrInvalidOverrideTTL is returned when a metric override TTL is not positive, or exceeds the
+	// maximum TTL.
+	ErrInvalidOverrideTTL = errors.Errorf("metric override TTL must be greater than 0 and no more than %s", maxMetricOverrideTTL)
+)
+
+// MetricOverride is a fake metric value which is used in place of the real value of a group check
+// until it expires. This allows operators to rehearse scaling responses without generating load.
+type MetricOverride struct {
+	JobID   string
+	Group   string
+	Check   string
+	Value   float64
+	Expires time.Time
+}
+
+type metricOverrideKey struct {
+	job, group, check string
+}
+
+// metricOverrides stores the active metric overrides. Overrides are held in memory by the server
+// which set them, and are not persisted to the storage backend.
+type metricOverrides struct {
+	lock      sync.RWMutex
+	overrides map[metricOverrideKey]*MetricOverride
+}
+
+func newMetricOverrides() *metricOverrides {
+	return &metricOverrides{overrides: make(map[metricOverrideKey]*MetricOverride)}
+}
+
+func (m *metricOverrides) set(o *MetricOverride) {
+	m.lock.Lock()
+	m.overrides[metricOverrideKey{job: o.JobID, group: o.Group, check: o.Check}] = o
+	m.lock.Unlock()
+}
+
+func (m *metricOverrides) delete(job, group, check string) bool {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := metricOverrideKey{job: job, group: group, check: check}
+	if _, ok := m.overrides[key]; !ok {
+		return false
+	}
+	delete(m.overrides, key)
+	return true
+}
+
+// get returns the value of the override for the group check if one exists and has not expired.
+// Expired overrides are removed.
+func (m *metricOverrides) get(job, group, check string, now time.Time) (float64, bool) {
+	if m == nil {
+		return 0, false
+	}
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := metricOverrideKey{job: job, group: group, check: check}
+
+	o, ok := m.overrides[key]
+	if !ok {
+		return 0, false
+	}
+	if !now.Before(o.Expires) {
+		delete(m.overrides, key)
+		return 0, false
+	}
+	return o.Value, true
+}
+
+// list returns the active overrides, sorted by job, group and check.
+func (m *metricOverrides) list(now time.Time) []*MetricOverride {
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	out := []*MetricOverride{}
+
+	for _, o := range m.overrides {
+		if now.Before(o.Expires) {
+			out = append(out, o)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].JobID != out[j].JobID {
+			return out[i].JobID < out[j].JobID
+		}
+		if out[i].Group != out[j].Group {
+			return out[i].Group < out[j].Group
+		}
+		return out[i].Check < out[j].Check
+	})
+	return out
+}
+
+// SetMetricOverride sets a fake value for the named check of the job group for the TTL. The check
+// is either the name of an external check, or a Nomad resource metric such as nomad-cpu.
+func (a *AutoScale) SetMetricOverride(job, group, check string, value float64, ttl time.Duration) (*MetricOverride, error) {
+	if ttl <= 0 || ttl > maxMetricOverrideTTL {
+		return nil, ErrInvalidOverrideTTL
+	}
+
+	ctx, cancel := helper.ContextWithTimeout(context.Background(), time.Duration(a.cfg.NomadTimeout)*time.Second)
+	defer cancel()
+
+	pol, err := a.policyBackend.GetJobGroupPolicy(ctx, job, group)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get job group scaling policy")
+	}
+	if pol == nil {
+		return nil, ErrPolicyNotFound
+	}
+	if !hasOverridableCheck(pol, check) {
+		return nil, ErrCheckNotFound
+	}
+
+	o := &MetricOverride{JobID: job, Group: group, Check: check, Value: value, Expires: time.Now().UTC().Add(ttl)}
+	a.overrides.set(o)
+
+	a.logger.Warn().
+		Str("job", job).
+		Str("group", group).
+		Str("check", check).
+		Float64("value", value).
+		Time("expires", o.Expires).
+		Msg("metric override set, real metric values will be ignored until it expires")
+
+	return o, nil
+}
+
+// DeleteMetricOverride removes the override of the named check of the job group.
+func (a *AutoScale) DeleteMetricOverride(job, group, check string) error {
+	if !a.overrides.delete(job, group, check) {
+		return ErrOverrideNotFound
+	}
+	return nil
+}
+
+// MetricOverrides returns the currently active metric overrides.
+func (a *AutoScale) MetricOverrides() []*MetricOverride {
+	return a.overrides.list(time.Now().UTC())
+}
+
+// hasOverridableCheck identifies whether the check name refers to an external check or Nomad
+// resource metric which can be overridden within the policy.
+func hasOverridableCheck(pol *policy.GroupScalingPolicy, check string) bool {
+	switch check {
+	case nomadCPUMetricName, nomadMemoryMetricName, nomadDiskMetricName, nomadGPUMetricName:
+		return pol.NomadChecksEnabled()
+	}
+	_, ok := pol.ExternalChecks[check]
+	return ok
+}
+
+// metricOverride returns the override value of the group check if one is active, logging and
+// tracking its use so that overridden decisions are clearly identifiable.
+func (ae *autoscaleEvaluation) metricOverride(group, check string) (float64, bool) {
+	value, ok := ae.overrides.get(ae.jobID, group, check, time.Now().UTC())
+	if !ok {
+		return 0, false
+	}
+
+	ae.log.Warn().
+		Str("group", group).
+		Str("check", check).
+		Float64("metric-value", value).
+		Msg("using metric override in place of real metric value")
+
+	sendMetrics.IncrCounterWithLabels([]string{"autoscale", "metric_override"}, 1, []sendMetrics.Label{
+		{Name: "job", Value: ae.jobID},
+		{Name: "group", Value: group},
+		{Name: "check", Value: check},
+	})
+	return value, true
+}
+
+// applyNomadMetricOverrides replaces the Nomad resource utilisation of the group with any active
+// metric overrides.
+func (ae *autoscaleEvaluation) applyNomadMetricOverrides(group string, use *nomadResources) {
+	for name, value := range map[string]*float64{
+		nomadCPUMetricName:    &use.cpu,
+		nomadMemoryMetricName: &use.mem,
+		nomadDiskMetricName:   &use.disk,
+		nomadGPUMetricName:    &use.gpu,
+	} {
+		if override, ok := ae.metricOverride(group, name); ok {
+			*value = override
+		}
+	}
+}
@@ -0,0 +1,63 @@
+package autoscale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_metricOverrides(t *testing.T) {
+	now := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+	overrides := newMetricOverrides()
+
+	overrides.set(&MetricOverride{JobID: "example", Group: "cache", Check: "queue", Value: 200, Expires: now.Add(time.Minute)})
+	overrides.set(&MetricOverride{JobID: "example", Group: "cache", Check: "nomad-cpu", Value: 95, Expires: now.Add(time.Hour)})
+
+	value, ok := overrides.get("example", "cache", "nomad-cpu", now)
+	assert.True(t, ok)
+	assert.Equal(t, float64(95), value)
+
+	_, ok = overrides.get("example", "proxy", "nomad-cpu", now)
+	assert.False(t, ok)
+
+	list := overrides.list(now)
+	assert.Len(t, list, 2)
+	assert.Equal(t, "nomad-cpu", list[0].Check)
+
+	// Expired overrides are not used, and are removed once checked.
+	_, ok = overrides.get("example", "cache", "queue", now.Add(2*time.Minute))
+	assert.False(t, ok)
+	assert.Len(t, overrides.list(now), 1)
+
+	assert.True(t, overrides.delete("example", "cache", "nomad-cpu"))
+	assert.False(t, overrides.delete("example", "cache", "nomad-cpu"))
+
+	var nilOverrides *metricOverrides
+	_, ok = nilOverrides.get("example", "cache", "nomad-cpu", now)
+	assert.False(t, ok)
+}
+
+func Test_hasOverridableCheck(t *testing.T) {
+	pol := &policy.GroupScalingPolicy{
+		ScaleOutCPUPercentageThreshold: helper.Float64ToPointer(80),
+		ExternalChecks:                 map[string]*policy.ExternalCheck{"queue": {Enabled: true}},
+	}
+
+	assert.True(t, hasOverridableCheck(pol, nomadCPUMetricName))
+	assert.True(t, hasOverridableCheck(pol, "queue"))
+	assert.False(t, hasOverridableCheck(pol, "latency"))
+	assert.False(t, hasOverridableCheck(&policy.GroupScalingPolicy{}, nomadMemoryMetricName))
+}
+
+func TestAutoScale_SetMetricOverride_invalidTTL(t *testing.T) {
+	a := &AutoScale{overrides: newMetricOverrides()}
+
+	_, err := a.SetMetricOverride("example", "cache", "queue", 200, 0)
+	assert.Equal(t, ErrInvalidOverrideTTL, err)
+
+	_, err = a.SetMetricOverride("example", "cache", "queue", 200, 48*time.Hour)
+	assert.Equal(t, ErrInvalidOverrideTTL, err)
+}
@@ -0,0 +1,32 @@
+package autoscale
+
+import "sync"
+
+// forEachBounded calls f for each index in [0, n), running at most limit calls concurrently, and
+// returns once all calls have completed. When limit is less than 2, the calls are made serially in
+// index order.
+func forEachBounded(n, limit int, f func(i int)) {
+	if limit < 2 || n < 2 {
+		for i := 0; i < n; i++ {
+			f(i)
+		}
+		return
+	}
+
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+
+	for i := 0; i < n; i++ {
+		sem <- struct{}{}
+		wg.Add(1)
+
+		go func(i int) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			f(i)
+		}(i)
+	}
+	wg.Wait()
+}
@@ -0,0 +1,53 @@
+package autoscale
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_forEachBounded(t *testing.T) {
+	testCases := []struct {
+		name          string
+		n             int
+		limit         int
+		expectedLimit int
+	}{
+		{name: "serial", n: 5, limit: 1, expectedLimit: 1},
+		{name: "zero limit is serial", n: 5, limit: 0, expectedLimit: 1},
+		{name: "bounded", n: 10, limit: 3, expectedLimit: 3},
+		{name: "limit above count", n: 2, limit: 8, expectedLimit: 2},
+	}
+
+	for _, tc := range testCases {
+		var (
+			lock              sync.Mutex
+			active, maxActive int
+		)
+		called := make([]bool, tc.n)
+
+		forEachBounded(tc.n, tc.limit, func(i int) {
+			lock.Lock()
+			active++
+			if active > maxActive {
+				maxActive = active
+			}
+			called[i] = true
+			lock.Unlock()
+
+			time.Sleep(10 * time.Millisecond)
+
+			lock.Lock()
+			active--
+			lock.Unlock()
+		})
+
+		for i := range called {
+			assert.True(t, called[i], tc.name)
+		}
+		assert.True(t, maxActive <= tc.expectedLimit, tc.name)
+		assert.Equal(t, 0, active, tc.name)
+	}
+}
@@ -0,0 +1,154 @@
+package autoscale
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/jrasell/sherpa/pkg/autoscale/evallog"
+	"github.com/jrasell/sherpa/pkg/scale"
+)
+
+// nomadAutoscalerMetaPrefix prefixes the meta keys the Nomad Autoscaler adds to the scaling events
+// it submits using the Nomad job scale API, and is used to identify its scaling actions.
+const nomadAutoscalerMetaPrefix = "nomad_autoscaler."
+
+// jobScaleStatus is the response of the Nomad job scale status API. The vendored Nomad API client
+// predates this endpoint, so it is queried using the raw client.
+type jobScaleStatus struct {
+	TaskGroups map[string]*taskGroupScaleStatus
+}
+
+type taskGroupScaleStatus struct {
+	Events []*nomadScalingEvent
+}
+
+// nomadScalingEvent is a scaling event stored by Nomad when a job group is scaled using the job
+// scale API. Time is a UnixNano timestamp.
+type nomadScalingEvent struct {
+	Count         *int64
+	PreviousCount int64
+	Error         bool
+	Meta          map[string]interface{}
+	Time          uint64
+}
+
+// compareShadowDecisions compares the scaling direction decided for each job group with the latest
+// scaling action the Nomad Autoscaler took for the group within the shadow window. Divergences are
+// logged, and all comparisons are reported using metrics and the evaluation record.
+func (ae *autoscaleEvaluation) compareShadowDecisions(dec map[string]*scalingDecision) {
+	if ae.shadowWindow <= 0 {
+		return
+	}
+
+	status, err := ae.getJobScaleStatus()
+	if err != nil {
+		ae.log.Error().Err(err).Msg("failed to read Nomad job scale status for shadow comparison")
+		sendMetrics.IncrCounterWithLabels([]string{"autoscale", "shadow", "error"}, 1,
+			[]sendMetrics.Label{{Name: "job", Value: ae.jobID}})
+		return
+	}
+
+	since := ae.time - ae.shadowWindow.Nanoseconds()
+
+	groups := make([]string, 0, len(ae.policies))
+	for group := range ae.policies {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+
+	for _, group := range groups {
+		sherpa := scale.DirectionNone
+		if d := dec[group]; d != nil {
+			sherpa = d.direction
+		}
+		nomad := nomadAutoscalerDirection(status.TaskGroups[group], since)
+		diverged := sherpa != nomad
+
+		if diverged {
+			ae.log.Warn().
+				Str("group", group).
+				Str("sherpa-direction", sherpa.String()).
+				Str("nomad-autoscaler-direction", nomad.String()).
+				Msg("scaling decision diverged from Nomad Autoscaler")
+		}
+
+		if ae.record != nil {
+			ae.record.Group(group).Shadow = &evallog.ShadowComparison{
+				NomadAutoscalerDirection: nomad.String(),
+				Diverged:                 diverged,
+			}
+		}
+		sendShadowMetrics(ae.jobID, group, sherpa, nomad, diverged)
+	}
+}
+
+func (ae *autoscaleEvaluation) getJobScaleStatus() (*jobScaleStatus, error) {
+	var status jobScaleStatus
+
+	err := ae.callNomad(func() error {
+		_, err := ae.nomad.Raw().Query("/v1/job/"+url.PathEscape(ae.jobID)+"/scale", &status, nil)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// nomadAutoscalerDirection returns the direction of the latest successful scaling action the Nomad
+// Autoscaler took for the group after the since UnixNano timestamp.
+func nomadAutoscalerDirection(status *taskGroupScaleStatus, since int64) scale.Direction {
+	if status == nil {
+		return scale.DirectionNone
+	}
+
+	var latest *nomadScalingEvent
+
+	for _, e := range status.Events {
+		if e == nil || e.Error || e.Count == nil || int64(e.Time) <= since || !isNomadAutoscalerEvent(e) {
+			continue
+		}
+		if latest == nil || e.Time > latest.Time {
+			latest = e
+		}
+	}
+
+	switch {
+	case latest == nil:
+		return scale.DirectionNone
+	case *latest.Count > latest.PreviousCount:
+		return scale.DirectionOut
+	case *latest.Count < latest.PreviousCount:
+		return scale.DirectionIn
+	default:
+		return scale.DirectionNone
+	}
+}
+
+func isNomadAutoscalerEvent(e *nomadScalingEvent) bool {
+	for k := range e.Meta {
+		if strings.HasPrefix(k, nomadAutoscalerMetaPrefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// sendShadowMetrics tracks each shadow comparison, labelled by the direction decided by Sherpa and
+// the Nomad Autoscaler, so that the rate of divergence can be calculated.
+func sendShadowMetrics(job, group string, sherpa, nomad scale.Direction, diverged bool) {
+	result := "agreed"
+	if diverged {
+		result = "diverged"
+	}
+
+	sendMetrics.IncrCounterWithLabels([]string{"autoscale", "shadow", "comparison"}, 1, []sendMetrics.Label{
+		{Name: "job", Value: job},
+		{Name: "group", Value: group},
+		{Name: "sherpa_direction", Value: sherpa.String()},
+		{Name: "nomad_autoscaler_direction", Value: nomad.String()},
+		{Name: "result", Value: result},
+	})
+}
@@ -0,0 +1,107 @@
+package autoscale
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/autoscale/evallog"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_nomadAutoscalerDirection(t *testing.T) {
+	count := func(c int64) *int64 { return &c }
+	autoscalerMeta := map[string]interface{}{"nomad_autoscaler.count.original": 3}
+
+	testCases := []struct {
+		status         *taskGroupScaleStatus
+		expectedOutput scale.Direction
+		name           string
+	}{
+		{
+			status:         nil,
+			expectedOutput: scale.DirectionNone,
+			name:           "group without scale status",
+		},
+		{
+			status: &taskGroupScaleStatus{Events: []*nomadScalingEvent{
+				{Count: count(3), PreviousCount: 2, Meta: autoscalerMeta, Time: 150},
+			}},
+			expectedOutput: scale.DirectionOut,
+			name:           "scale out within window",
+		},
+		{
+			status: &taskGroupScaleStatus{Events: []*nomadScalingEvent{
+				{Count: count(3), PreviousCount: 2, Meta: autoscalerMeta, Time: 150},
+				{Count: count(2), PreviousCount: 3, Meta: autoscalerMeta, Time: 180},
+			}},
+			expectedOutput: scale.DirectionIn,
+			name:           "latest action is used",
+		},
+		{
+			status: &taskGroupScaleStatus{Events: []*nomadScalingEvent{
+				{Count: count(3), PreviousCount: 2, Meta: autoscalerMeta, Time: 50},
+			}},
+			expectedOutput: scale.DirectionNone,
+			name:           "action before window",
+		},
+		{
+			status: &taskGroupScaleStatus{Events: []*nomadScalingEvent{
+				{Count: count(3), PreviousCount: 2, Meta: map[string]interface{}{"source": "operator"}, Time: 150},
+			}},
+			expectedOutput: scale.DirectionNone,
+			name:           "action not taken by Nomad Autoscaler",
+		},
+		{
+			status: &taskGroupScaleStatus{Events: []*nomadScalingEvent{
+				{Count: count(3), PreviousCount: 2, Meta: autoscalerMeta, Time: 150, Error: true},
+				{PreviousCount: 2, Meta: autoscalerMeta, Time: 160},
+			}},
+			expectedOutput: scale.DirectionNone,
+			name:           "failed and count-less events are ignored",
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedOutput, nomadAutoscalerDirection(tc.status, 100), tc.name)
+	}
+}
+
+func Test_autoscaleEvaluation_compareShadowDecisions(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/job/example/scale", r.URL.Path)
+		_, _ = w.Write([]byte(`{"TaskGroups":{"cache":{"Events":[` +
+			`{"Count":4,"PreviousCount":3,"Time":1580000000000000000,"Meta":{"nomad_autoscaler.count.original":4}}]}}}`))
+	}))
+	defer srv.Close()
+
+	nomadClient, err := nomad.NewClient(&nomad.Config{Address: srv.URL})
+	assert.Nil(t, err)
+
+	ae := autoscaleEvaluation{
+		nomad:        nomadClient,
+		log:          zerolog.Nop(),
+		jobID:        "example",
+		time:         1580000010000000000,
+		shadowWindow: time.Minute,
+		policies:     map[string]*policy.GroupScalingPolicy{"cache": {}, "proxy": {}, "db": {}},
+		record:       evallog.NewRecord("eval", "example", time.Unix(0, 1580000010000000000)),
+	}
+
+	ae.compareShadowDecisions(map[string]*scalingDecision{
+		"cache": {direction: scale.DirectionOut},
+		"proxy": {direction: scale.DirectionIn},
+	})
+
+	assert.Equal(t, &evallog.ShadowComparison{NomadAutoscalerDirection: "out", Diverged: false},
+		ae.record.Groups["cache"].Shadow)
+	assert.Equal(t, &evallog.ShadowComparison{NomadAutoscalerDirection: "none", Diverged: true},
+		ae.record.Groups["proxy"].Shadow)
+	assert.Equal(t, &evallog.ShadowComparison{NomadAutoscalerDirection: "none", Diverged: false},
+		ae.record.Groups["db"].Shadow)
+}
@@ -0,0 +1,43 @@
+package autoscale
+
+import "github.com/jrasell/sherpa/pkg/state"
+
+// The snapshot functions track the metric values gathered for each group during the evaluation,
+// which are stored alongside any resulting scaling event. They are safe to call from concurrent
+// group evaluations.
+
+func (ae *autoscaleEvaluation) snapshotNomadResources(group string, use *nomadResources, coverage float64) {
+	ae.lock.Lock()
+	defer ae.lock.Unlock()
+
+	ae.groupSnapshot(group).Nomad = &state.NomadResourceSnapshot{
+		CPU: use.cpu, Memory: use.mem, Disk: use.disk, GPU: use.gpu, StatsCoverage: coverage,
+	}
+}
+
+func (ae *autoscaleEvaluation) snapshotExternalValue(group, name string, value *float64) {
+	if value == nil {
+		return
+	}
+
+	ae.lock.Lock()
+	defer ae.lock.Unlock()
+
+	snap := ae.groupSnapshot(group)
+	if snap.External == nil {
+		snap.External = make(map[string]float64)
+	}
+	snap.External[name] = *value
+}
+
+// groupSnapshot returns the snapshot of the group, creating it if required. The caller must hold
+// the evaluation lock.
+func (ae *autoscaleEvaluation) groupSnapshot(group string) *state.ResourceSnapshot {
+	if ae.snapshots == nil {
+		ae.snapshots = make(map[string]*state.ResourceSnapshot)
+	}
+	if _, ok := ae.snapshots[group]; !ok {
+		ae.snapshots[group] = &state.ResourceSnapshot{}
+	}
+	return ae.snapshots[group]
+}
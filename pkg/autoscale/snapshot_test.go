@@ -0,0 +1,34 @@
+package autoscale
+
+import (
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_autoscaleEvaluation_snapshots(t *testing.T) {
+	ae := &autoscaleEvaluation{
+		policies: map[string]*policy.GroupScalingPolicy{"worker": {Enabled: true}, "cache": {Enabled: true}},
+	}
+
+	ae.snapshotNomadResources("worker", &nomadResources{cpu: 82.5, mem: 40}, 100)
+	ae.snapshotExternalValue("worker", "queue", helper.Float64ToPointer(120))
+	ae.snapshotExternalValue("worker", "failed", nil)
+
+	reqs := ae.buildScalingReq(map[string]*scalingDecision{
+		"worker": {direction: scale.DirectionOut, count: 1},
+		"cache":  {direction: scale.DirectionIn, count: 1},
+	})
+
+	assert.Len(t, reqs, 2)
+	assert.Equal(t, "cache", reqs[0].GroupName)
+	assert.Nil(t, reqs[0].Snapshot)
+	assert.Equal(t, &state.ResourceSnapshot{
+		Nomad:    &state.NomadResourceSnapshot{CPU: 82.5, Memory: 40, StatsCoverage: 100},
+		External: map[string]float64{"queue": 120},
+	}, reqs[1].Snapshot)
+}
@@ -0,0 +1,135 @@
+package autoscale
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+)
+
+// stalenessTracker records the last time each job group was evaluated, allowing evaluations to be
+// prioritised by the groups which have gone longest without evaluation.
+type stalenessTracker struct {
+	lock sync.RWMutex
+	last map[string]map[string]time.Time
+}
+
+// evaluationCandidate is a job which has groups eligible for evaluation during an autoscaling run.
+type evaluationCandidate struct {
+	job      string
+	groups   map[string]*policy.GroupScalingPolicy
+	lastEval time.Time
+}
+
+func newStalenessTracker() *stalenessTracker {
+	return &stalenessTracker{last: make(map[string]map[string]time.Time)}
+}
+
+// markEvaluated records the time at which the enabled groups within the job policy were
+// evaluated.
+func (st *stalenessTracker) markEvaluated(job string, policies map[string]*policy.GroupScalingPolicy, t time.Time) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	if _, ok := st.last[job]; !ok {
+		st.last[job] = make(map[string]time.Time)
+	}
+
+	for group, pol := range policies {
+		if pol.Enabled {
+			st.last[job][group] = t
+		}
+	}
+}
+
+// lastEvaluated returns the oldest last evaluation time of the passed job groups. A zero time is
+// returned if any of the groups have never been evaluated.
+func (st *stalenessTracker) lastEvaluated(job string, groups map[string]*policy.GroupScalingPolicy) time.Time {
+	st.lock.RLock()
+	defer st.lock.RUnlock()
+
+	var oldest time.Time
+
+	for group := range groups {
+		t, ok := st.last[job][group]
+		if !ok {
+			return time.Time{}
+		}
+		if oldest.IsZero() || t.Before(oldest) {
+			oldest = t
+		}
+	}
+	return oldest
+}
+
+// prune removes the tracked evaluation times of job groups which no longer have a policy.
+func (st *stalenessTracker) prune(policies map[string]map[string]*policy.GroupScalingPolicy) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	for job := range st.last {
+		for group := range st.last[job] {
+			if _, ok := policies[job][group]; !ok {
+				delete(st.last[job], group)
+			}
+		}
+		if len(st.last[job]) == 0 {
+			delete(st.last, job)
+		}
+	}
+}
+
+// snapshot returns a copy of the tracked evaluation times.
+func (st *stalenessTracker) snapshot() map[string]map[string]time.Time {
+	st.lock.RLock()
+	defer st.lock.RUnlock()
+
+	out := make(map[string]map[string]time.Time, len(st.last))
+	for job, groups := range st.last {
+		out[job] = make(map[string]time.Time, len(groups))
+		for group, t := range groups {
+			out[job][group] = t
+		}
+	}
+	return out
+}
+
+// restore stores the passed evaluation times, keeping any later times already tracked.
+func (st *stalenessTracker) restore(last map[string]map[string]time.Time) {
+	st.lock.Lock()
+	defer st.lock.Unlock()
+
+	for job, groups := range last {
+		if _, ok := st.last[job]; !ok {
+			st.last[job] = make(map[string]time.Time)
+		}
+		for group, t := range groups {
+			if t.After(st.last[job][group]) {
+				st.last[job][group] = t
+			}
+		}
+	}
+}
+
+// sortCandidates orders the candidates so those which have gone longest without evaluation are
+// first. The job name is used to provide a stable order for candidates evaluated at the same time.
+func sortCandidates(candidates []*evaluationCandidate) {
+	sort.Slice(candidates, func(i, j int) bool {
+		if !candidates[i].lastEval.Equal(candidates[j].lastEval) {
+			return candidates[i].lastEval.Before(candidates[j].lastEval)
+		}
+		return candidates[i].job < candidates[j].job
+	})
+}
+
+// shouldDefer determines whether the evaluation of the candidate can be deferred until the next
+// autoscaling run, as the worker pool is saturated. Candidates which have never been evaluated, or
+// would exceed the max staleness bound, are never deferred. A zero max staleness disables
+// deferral.
+func shouldDefer(c *evaluationCandidate, free int, maxStaleness time.Duration, now time.Time) bool {
+	if maxStaleness == 0 || free > 0 || c.lastEval.IsZero() {
+		return false
+	}
+	return now.Sub(c.lastEval) < maxStaleness
+}
@@ -0,0 +1,106 @@
+package autoscale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_stalenessTracker(t *testing.T) {
+	st := newStalenessTracker()
+	now := time.Unix(1580000000, 0)
+
+	groups := map[string]*policy.GroupScalingPolicy{
+		"cache":  {Enabled: true},
+		"worker": {Enabled: true},
+		"batch":  {Enabled: false},
+	}
+
+	// Groups which have never been evaluated return a zero time.
+	assert.True(t, st.lastEvaluated("example", groups).IsZero())
+
+	st.markEvaluated("example", map[string]*policy.GroupScalingPolicy{"cache": groups["cache"]}, now)
+	assert.True(t, st.lastEvaluated("example", groups).IsZero())
+
+	st.markEvaluated("example", groups, now.Add(time.Minute))
+	st.markEvaluated("example", map[string]*policy.GroupScalingPolicy{"cache": groups["cache"]}, now.Add(2*time.Minute))
+	enabled := map[string]*policy.GroupScalingPolicy{"cache": groups["cache"], "worker": groups["worker"]}
+	assert.Equal(t, now.Add(time.Minute), st.lastEvaluated("example", enabled))
+	assert.NotContains(t, st.last["example"], "batch")
+
+	// Pruning should remove groups and jobs without policies.
+	st.markEvaluated("deleted", groups, now)
+	st.prune(map[string]map[string]*policy.GroupScalingPolicy{"example": {"cache": groups["cache"]}})
+	assert.NotContains(t, st.last, "deleted")
+	assert.Equal(t, map[string]time.Time{"cache": now.Add(2 * time.Minute)}, st.last["example"])
+}
+
+func Test_sortCandidates(t *testing.T) {
+	now := time.Unix(1580000000, 0)
+
+	candidates := []*evaluationCandidate{
+		{job: "recent", lastEval: now},
+		{job: "stale", lastEval: now.Add(-time.Hour)},
+		{job: "new-b"},
+		{job: "new-a"},
+	}
+	sortCandidates(candidates)
+
+	var order []string
+	for _, c := range candidates {
+		order = append(order, c.job)
+	}
+	assert.Equal(t, []string{"new-a", "new-b", "stale", "recent"}, order)
+}
+
+func Test_shouldDefer(t *testing.T) {
+	now := time.Unix(1580000000, 0)
+
+	testCases := []struct {
+		name           string
+		lastEval       time.Time
+		free           int
+		maxStaleness   time.Duration
+		expectedResult bool
+	}{
+		{
+			name:           "deferral disabled",
+			lastEval:       now.Add(-time.Second),
+			maxStaleness:   0,
+			expectedResult: false,
+		},
+		{
+			name:           "pool has free workers",
+			lastEval:       now.Add(-time.Second),
+			free:           1,
+			maxStaleness:   time.Minute,
+			expectedResult: false,
+		},
+		{
+			name:           "never evaluated",
+			maxStaleness:   time.Minute,
+			expectedResult: false,
+		},
+		{
+			name:           "within staleness bound",
+			lastEval:       now.Add(-30 * time.Second),
+			maxStaleness:   time.Minute,
+			expectedResult: true,
+		},
+		{
+			name:           "exceeds staleness bound",
+			lastEval:       now.Add(-2 * time.Minute),
+			maxStaleness:   time.Minute,
+			expectedResult: false,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := &evaluationCandidate{job: "example", lastEval: tc.lastEval}
+			assert.Equal(t, tc.expectedResult, shouldDefer(c, tc.free, tc.maxStaleness, now))
+		})
+	}
+}
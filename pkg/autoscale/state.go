@@ -0,0 +1,209 @@
+package autoscale
+
+import (
+	"sync"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/pkg/errors"
+)
+
+// CooldownStore is used to read and write the cooldown timestamps of job groups, which are held
+// by the scaling state backend.
+type CooldownStore interface {
+	GetCooldown(job, group string) (int64, error)
+	PutCooldown(job, group string, t int64) error
+}
+
+// InternalState is a point in time copy of the state the autoscaler uses to make its decisions.
+// It is used to aid debugging, and to carry state between Sherpa servers during upgrades. Maps
+// are keyed by the job, and then by the group.
+type InternalState struct {
+	// Time is when the state was captured.
+	Time time.Time
+
+	// Cooldowns are the UnixNano times from which the scaling cooldown of each group with a
+	// policy is measured.
+	Cooldowns map[string]map[string]int64
+
+	// LastEvaluated is when each group was last evaluated by the autoscaler.
+	LastEvaluated map[string]map[string]time.Time
+
+	// LastDecisions is the latest scaling decision made for each group by the autoscaler.
+	LastDecisions map[string]map[string]*GroupDecision
+
+	// Overrides are the active metric overrides.
+	Overrides []*MetricOverride
+
+	// OrphanedJobs is when the jobs with policies were first found to be stopped or purged.
+	OrphanedJobs map[string]time.Time
+}
+
+// GroupDecision is a scaling decision made by the autoscaler for a job group.
+type GroupDecision struct {
+	Direction string
+	Count     int
+	Reason    state.Reason
+	Time      time.Time
+}
+
+// decisionTracker records the latest scaling decision made for each job group.
+type decisionTracker struct {
+	lock sync.RWMutex
+	last map[string]map[string]*GroupDecision
+}
+
+func newDecisionTracker() *decisionTracker {
+	return &decisionTracker{last: make(map[string]map[string]*GroupDecision)}
+}
+
+// record stores the decisions made for the groups of the job at the passed time.
+func (dt *decisionTracker) record(job string, dec map[string]*scalingDecision, t time.Time) {
+	if dt == nil {
+		return
+	}
+
+	dt.lock.Lock()
+	defer dt.lock.Unlock()
+
+	for group, d := range dec {
+		if d == nil {
+			continue
+		}
+		dt.put(job, group, &GroupDecision{Direction: d.direction.String(), Count: d.count, Reason: d.getReason(), Time: t})
+	}
+}
+
+// put stores the decision for the job group, unless a later decision is already held. The caller
+// must hold the lock.
+func (dt *decisionTracker) put(job, group string, d *GroupDecision) {
+	if existing, ok := dt.last[job][group]; ok && existing.Time.After(d.Time) {
+		return
+	}
+	if _, ok := dt.last[job]; !ok {
+		dt.last[job] = make(map[string]*GroupDecision)
+	}
+	dt.last[job][group] = d
+}
+
+// snapshot returns a copy of the tracked decisions.
+func (dt *decisionTracker) snapshot() map[string]map[string]*GroupDecision {
+	dt.lock.RLock()
+	defer dt.lock.RUnlock()
+
+	out := make(map[string]map[string]*GroupDecision, len(dt.last))
+	for job, groups := range dt.last {
+		out[job] = make(map[string]*GroupDecision, len(groups))
+		for group, d := range groups {
+			c := *d
+			out[job][group] = &c
+		}
+	}
+	return out
+}
+
+// restore stores the passed decisions, keeping any later decisions already tracked.
+func (dt *decisionTracker) restore(decisions map[string]map[string]*GroupDecision) {
+	dt.lock.Lock()
+	defer dt.lock.Unlock()
+
+	for job, groups := range decisions {
+		for group, d := range groups {
+			if d != nil {
+				c := *d
+				dt.put(job, group, &c)
+			}
+		}
+	}
+}
+
+// prune removes the tracked decisions of job groups which no longer have a policy.
+func (dt *decisionTracker) prune(policies map[string]map[string]*policy.GroupScalingPolicy) {
+	dt.lock.Lock()
+	defer dt.lock.Unlock()
+
+	for job := range dt.last {
+		for group := range dt.last[job] {
+			if _, ok := policies[job][group]; !ok {
+				delete(dt.last[job], group)
+			}
+		}
+		if len(dt.last[job]) == 0 {
+			delete(dt.last, job)
+		}
+	}
+}
+
+// InternalState returns a copy of the current internal state of the autoscaler. The cooldown of
+// each group with a scaling policy is read from the state backend.
+func (a *AutoScale) InternalState() (*InternalState, error) {
+	policies, err := a.getPolicies()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get scaling policies")
+	}
+
+	now := time.Now().UTC()
+
+	out := InternalState{
+		Time:          now,
+		Cooldowns:     make(map[string]map[string]int64),
+		LastEvaluated: a.staleness.snapshot(),
+		LastDecisions: a.decisions.snapshot(),
+		Overrides:     a.overrides.list(now),
+		OrphanedJobs:  a.orphans.snapshot(),
+	}
+
+	for job, groups := range policies {
+		for group := range groups {
+			t, err := a.cooldowns.GetCooldown(job, group)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get cooldown of job %s group %s", job, group)
+			}
+			if t == 0 {
+				continue
+			}
+			if _, ok := out.Cooldowns[job]; !ok {
+				out.Cooldowns[job] = make(map[string]int64)
+			}
+			out.Cooldowns[job][group] = t
+		}
+	}
+	return &out, nil
+}
+
+// RestoreInternalState loads the passed internal state into the autoscaler. Restored times never
+// replace later times already held, so that an active cooldown cannot be shortened, and expired
+// metric overrides are ignored.
+func (a *AutoScale) RestoreInternalState(s *InternalState) error {
+	now := time.Now().UTC()
+
+	for job, groups := range s.Cooldowns {
+		for group, t := range groups {
+			current, err := a.cooldowns.GetCooldown(job, group)
+			if err != nil {
+				return errors.Wrapf(err, "failed to get cooldown of job %s group %s", job, group)
+			}
+			if t <= current {
+				continue
+			}
+			if err := a.cooldowns.PutCooldown(job, group, t); err != nil {
+				return errors.Wrapf(err, "failed to put cooldown of job %s group %s", job, group)
+			}
+		}
+	}
+
+	a.staleness.restore(s.LastEvaluated)
+	a.decisions.restore(s.LastDecisions)
+	a.orphans.restore(s.OrphanedJobs)
+
+	for _, o := range s.Overrides {
+		if o != nil && now.Before(o.Expires) {
+			c := *o
+			a.overrides.set(&c)
+		}
+	}
+
+	a.logger.Info().Time("state-time", s.Time).Msg("restored autoscaler internal state")
+	return nil
+}
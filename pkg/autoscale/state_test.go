@@ -0,0 +1,96 @@
+package autoscale
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	policyMemory "github.com/jrasell/sherpa/pkg/policy/backend/memory"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
+	stateMemory "github.com/jrasell/sherpa/pkg/state/scale/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_decisionTracker(t *testing.T) {
+	dt := newDecisionTracker()
+	now := time.Unix(1580000000, 0)
+
+	dt.record("example", map[string]*scalingDecision{
+		"cache": {direction: scale.DirectionOut, count: 2, reason: state.ReasonMetricsFallback},
+		"proxy": nil,
+	}, now)
+
+	expected := &GroupDecision{Direction: "out", Count: 2, Reason: state.ReasonMetricsFallback, Time: now}
+	assert.Equal(t, map[string]map[string]*GroupDecision{"example": {"cache": expected}}, dt.snapshot())
+
+	// Restored decisions do not replace later decisions.
+	dt.restore(map[string]map[string]*GroupDecision{
+		"example": {"cache": {Direction: "in", Count: 1, Time: now.Add(-time.Minute)}},
+		"batch":   {"worker": {Direction: "in", Count: 1, Time: now}},
+	})
+	assert.Equal(t, expected, dt.snapshot()["example"]["cache"])
+	assert.Contains(t, dt.snapshot(), "batch")
+
+	dt.prune(map[string]map[string]*policy.GroupScalingPolicy{"example": {"cache": {}}})
+	assert.NotContains(t, dt.snapshot(), "batch")
+}
+
+func TestAutoScale_InternalState(t *testing.T) {
+	policies := policyMemory.NewJobScalingPolicies()
+	assert.Nil(t, policies.PutJobPolicy(context.Background(), "example", map[string]*policy.GroupScalingPolicy{
+		"cache": {Enabled: true},
+		"proxy": {Enabled: true},
+	}))
+
+	now := time.Now().UTC()
+	cooldowns := stateMemory.NewStateBackend()
+	assert.Nil(t, cooldowns.PutCooldown("example", "cache", now.UnixNano()))
+
+	newAutoScale := func() *AutoScale {
+		return &AutoScale{
+			cfg:           &Config{},
+			logger:        zerolog.Nop(),
+			policyBackend: policies,
+			cooldowns:     cooldowns,
+			staleness:     newStalenessTracker(),
+			orphans:       newOrphanTracker(),
+			decisions:     newDecisionTracker(),
+			overrides:     newMetricOverrides(),
+		}
+	}
+
+	src := newAutoScale()
+	src.staleness.markEvaluated("example", map[string]*policy.GroupScalingPolicy{"cache": {Enabled: true}}, now)
+	src.orphans.mark("batch", now)
+	src.overrides.set(&MetricOverride{JobID: "example", Group: "cache", Check: "queue", Value: 10, Expires: now.Add(time.Hour)})
+	src.overrides.set(&MetricOverride{JobID: "example", Group: "proxy", Check: "queue", Value: 10, Expires: now.Add(-time.Hour)})
+
+	snapshot, err := src.InternalState()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]map[string]int64{"example": {"cache": now.UnixNano()}}, snapshot.Cooldowns)
+	assert.Equal(t, now, snapshot.LastEvaluated["example"]["cache"])
+	assert.Equal(t, map[string]time.Time{"batch": now}, snapshot.OrphanedJobs)
+	assert.Len(t, snapshot.Overrides, 1)
+
+	// Restoring never shortens an active cooldown.
+	snapshot.Cooldowns["example"]["cache"] = now.Add(-time.Minute).UnixNano()
+	snapshot.Cooldowns["example"]["proxy"] = now.UnixNano()
+
+	dst := newAutoScale()
+	assert.Nil(t, dst.RestoreInternalState(snapshot))
+
+	cooldown, err := cooldowns.GetCooldown("example", "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, now.UnixNano(), cooldown)
+
+	cooldown, err = cooldowns.GetCooldown("example", "proxy")
+	assert.Nil(t, err)
+	assert.Equal(t, now.UnixNano(), cooldown)
+
+	assert.Equal(t, snapshot.LastEvaluated, dst.staleness.snapshot())
+	assert.Equal(t, snapshot.OrphanedJobs, dst.orphans.snapshot())
+	assert.Equal(t, snapshot.Overrides, dst.MetricOverrides())
+}
@@ -0,0 +1,245 @@
+package autoscale
+
+import (
+	"math"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/pkg/errors"
+)
+
+const (
+	// poolMonitorInterval is the period between emitting worker pool metrics and, if enabled,
+	// tuning the pool size.
+	poolMonitorInterval = 10 * time.Second
+
+	// latencyWeight is the weight given to each new Nomad API latency observation when
+	// calculating the moving average.
+	latencyWeight = 0.2
+)
+
+// poolTuner tracks the autoscaler worker pool backlog, utilisation and Nomad API latency, which
+// are used to adapt the size of the worker pool within the configured bounds.
+type poolTuner struct {
+	min, max         int
+	latencyThreshold time.Duration
+
+	// backlog is the number of evaluations which have been submitted to the pool but have not yet
+	// started, and running is the number of evaluations being performed.
+	backlog int64
+	running int64
+
+	// peakBacklog and peakRunning are the highest backlog and running evaluations, and deferred
+	// the number of evaluations deferred due to a saturated pool, since the pool was last tuned.
+	// Evaluations run in bursts, so the peaks rather than the values at the time of tuning are
+	// used. evaluated records whether any evaluation started since the pool was last tuned.
+	peakBacklog int64
+	peakRunning int64
+	deferred    int64
+	evaluated   int32
+
+	latencyLock sync.Mutex
+	latency     float64
+}
+
+// newPoolTuner builds a tuner bounding the worker pool between the passed sizes. A max of zero
+// disables auto-tuning and nil is returned.
+func newPoolTuner(min, max, latencyThresholdMS int) (*poolTuner, error) {
+	if max == 0 {
+		return nil, nil
+	}
+	if min < 1 {
+		return nil, errors.New("autoscaler minimum threads must be at least 1")
+	}
+	if min > max {
+		return nil, errors.New("autoscaler minimum threads must not be greater than maximum threads")
+	}
+	return &poolTuner{
+		min:              min,
+		max:              max,
+		latencyThreshold: time.Duration(latencyThresholdMS) * time.Millisecond,
+	}, nil
+}
+
+// initialSize clamps the configured number of threads to the tuner bounds.
+func (pt *poolTuner) initialSize(size int) int {
+	if pt == nil {
+		return size
+	}
+	return pt.clamp(size)
+}
+
+func (pt *poolTuner) clamp(size int) int {
+	if size < pt.min {
+		return pt.min
+	}
+	if size > pt.max {
+		return pt.max
+	}
+	return size
+}
+
+func (pt *poolTuner) incrBacklog() {
+	if pt != nil {
+		storeMax(&pt.peakBacklog, atomic.AddInt64(&pt.backlog, 1))
+	}
+}
+
+func (pt *poolTuner) decrBacklog() {
+	if pt != nil {
+		atomic.AddInt64(&pt.backlog, -1)
+	}
+}
+
+// startEvaluation records that a worker has started an evaluation, and finishEvaluation that it
+// has completed.
+func (pt *poolTuner) startEvaluation() {
+	if pt != nil {
+		storeMax(&pt.peakRunning, atomic.AddInt64(&pt.running, 1))
+		atomic.StoreInt32(&pt.evaluated, 1)
+	}
+}
+
+func (pt *poolTuner) finishEvaluation() {
+	if pt != nil {
+		atomic.AddInt64(&pt.running, -1)
+	}
+}
+
+// incrDeferred records that an evaluation was deferred as the pool was saturated.
+func (pt *poolTuner) incrDeferred() {
+	if pt != nil {
+		atomic.AddInt64(&pt.deferred, 1)
+	}
+}
+
+// storeMax sets addr to val if val is greater than its current value.
+func storeMax(addr *int64, val int64) {
+	for {
+		cur := atomic.LoadInt64(addr)
+		if val <= cur || atomic.CompareAndSwapInt64(addr, cur, val) {
+			return
+		}
+	}
+}
+
+// poolSample is the activity of the worker pool since it was last tuned.
+type poolSample struct {
+	evaluated   bool
+	peakBacklog int
+	peakRunning int
+	deferred    int
+}
+
+// sample returns the activity of the pool since the previous sample, resetting the peaks to the
+// current values.
+func (pt *poolTuner) sample() poolSample {
+	running := atomic.LoadInt64(&pt.running)
+
+	evaluated := running > 0
+	if atomic.SwapInt32(&pt.evaluated, 0) == 1 {
+		evaluated = true
+	}
+	return poolSample{
+		evaluated:   evaluated,
+		peakBacklog: int(atomic.SwapInt64(&pt.peakBacklog, atomic.LoadInt64(&pt.backlog))),
+		peakRunning: int(atomic.SwapInt64(&pt.peakRunning, running)),
+		deferred:    int(atomic.SwapInt64(&pt.deferred, 0)),
+	}
+}
+
+func (pt *poolTuner) getBacklog() int {
+	if pt == nil {
+		return 0
+	}
+	return int(atomic.LoadInt64(&pt.backlog))
+}
+
+// observeLatency updates the moving average Nomad API latency with the passed observation.
+func (pt *poolTuner) observeLatency(d time.Duration) {
+	if pt == nil {
+		return
+	}
+
+	pt.latencyLock.Lock()
+	defer pt.latencyLock.Unlock()
+
+	if pt.latency == 0 {
+		pt.latency = float64(d)
+		return
+	}
+	pt.latency = latencyWeight*float64(d) + (1-latencyWeight)*pt.latency
+}
+
+func (pt *poolTuner) getLatency() time.Duration {
+	if pt == nil {
+		return 0
+	}
+
+	pt.latencyLock.Lock()
+	defer pt.latencyLock.Unlock()
+	return time.Duration(pt.latency)
+}
+
+// desiredSize calculates the worker pool size based on the pool activity and Nomad API latency
+// since the pool was last tuned. The size is left unchanged if no evaluations ran, as the pool is
+// idle between autoscaling runs. When Nomad is slow to respond the pool is shrunk to reduce load
+// on the Nomad servers, otherwise the pool grows to clear any backlog or deferred evaluations,
+// and shrinks slowly when at most half of it was used at its peak.
+func (pt *poolTuner) desiredSize(current int) int {
+	s := pt.sample()
+
+	switch {
+	case !s.evaluated:
+		return current
+	case pt.latencyThreshold > 0 && pt.getLatency() > pt.latencyThreshold:
+		return pt.clamp(current - 1)
+	case s.peakBacklog+s.deferred > 0:
+		return pt.clamp(current + s.peakBacklog + s.deferred)
+	case s.peakRunning < int(math.Ceil(float64(current)/2)):
+		return pt.clamp(current - 1)
+	default:
+		return pt.clamp(current)
+	}
+}
+
+// runPoolMonitor periodically emits worker pool metrics and, when auto-tuning is enabled, adapts
+// the size of the pool. It exits when the autoscaler is stopped.
+func (a *AutoScale) runPoolMonitor() {
+	t := time.NewTicker(poolMonitorInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			a.monitorPool()
+		case <-a.doneChan:
+			return
+		}
+	}
+}
+
+func (a *AutoScale) monitorPool() {
+	current, running := a.pool.Cap(), a.pool.Running()
+
+	sendMetrics.SetGauge([]string{"autoscale", "pool", "capacity"}, float32(current))
+	sendMetrics.SetGauge([]string{"autoscale", "pool", "running"}, float32(running))
+
+	if a.tuner == nil {
+		return
+	}
+
+	sendMetrics.SetGauge([]string{"autoscale", "pool", "backlog"}, float32(a.tuner.getBacklog()))
+	sendMetrics.SetGauge([]string{"autoscale", "pool", "nomad_latency"},
+		float32(a.tuner.getLatency())/float32(time.Millisecond))
+
+	if desired := a.tuner.desiredSize(current); desired != current {
+		a.logger.Debug().
+			Int("current-size", current).
+			Int("new-size", desired).
+			Msg("tuning autoscaler worker pool size")
+		a.pool.Tune(desired)
+	}
+}
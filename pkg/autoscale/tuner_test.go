@@ -0,0 +1,164 @@
+package autoscale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newPoolTuner(t *testing.T) {
+	tuner, err := newPoolTuner(1, 0, 1000)
+	assert.Nil(t, err)
+	assert.Nil(t, tuner)
+	assert.Equal(t, 5, tuner.initialSize(5))
+
+	_, err = newPoolTuner(0, 10, 1000)
+	assert.NotNil(t, err)
+
+	_, err = newPoolTuner(11, 10, 1000)
+	assert.NotNil(t, err)
+
+	tuner, err = newPoolTuner(2, 10, 1000)
+	assert.Nil(t, err)
+	assert.Equal(t, 2, tuner.initialSize(1))
+	assert.Equal(t, 10, tuner.initialSize(20))
+}
+
+func Test_poolTuner_desiredSize(t *testing.T) {
+	testCases := []struct {
+		name         string
+		backlog      int
+		deferred     int
+		latency      time.Duration
+		current      int
+		running      int
+		expectedSize int
+	}{
+		{
+			name:         "grow to clear backlog",
+			backlog:      3,
+			current:      4,
+			running:      4,
+			expectedSize: 7,
+		},
+		{
+			name:         "grow bounded by max",
+			backlog:      20,
+			current:      4,
+			running:      4,
+			expectedSize: 10,
+		},
+		{
+			name:         "grow on deferred evaluations",
+			deferred:     2,
+			current:      4,
+			running:      4,
+			expectedSize: 6,
+		},
+		{
+			name:         "shrink on high Nomad latency",
+			backlog:      3,
+			latency:      2 * time.Second,
+			current:      4,
+			running:      4,
+			expectedSize: 3,
+		},
+		{
+			name:         "shrink when mostly idle",
+			current:      4,
+			running:      1,
+			expectedSize: 3,
+		},
+		{
+			name:         "shrink bounded by min",
+			latency:      2 * time.Second,
+			current:      2,
+			running:      2,
+			expectedSize: 2,
+		},
+		{
+			name:         "steady state",
+			current:      4,
+			running:      3,
+			expectedSize: 4,
+		},
+		{
+			name:         "no evaluations",
+			latency:      2 * time.Second,
+			current:      4,
+			expectedSize: 4,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			tuner, err := newPoolTuner(2, 10, 1000)
+			assert.Nil(t, err)
+
+			for i := 0; i < tc.backlog; i++ {
+				tuner.incrBacklog()
+			}
+			for i := 0; i < tc.deferred; i++ {
+				tuner.incrDeferred()
+			}
+			if tc.latency > 0 {
+				tuner.observeLatency(tc.latency)
+			}
+
+			// Run the evaluations to completion, so that only the peak utilisation is observed.
+			for i := 0; i < tc.running; i++ {
+				tuner.startEvaluation()
+			}
+			for i := 0; i < tc.running; i++ {
+				tuner.finishEvaluation()
+			}
+			assert.Equal(t, tc.expectedSize, tuner.desiredSize(tc.current))
+		})
+	}
+}
+
+func Test_poolTuner_desiredSizeIdleBetweenRuns(t *testing.T) {
+	tuner, err := newPoolTuner(2, 10, 1000)
+	assert.Nil(t, err)
+
+	// A run which saturates the pool.
+	for i := 0; i < 4; i++ {
+		tuner.startEvaluation()
+	}
+	for i := 0; i < 4; i++ {
+		tuner.finishEvaluation()
+	}
+	assert.Equal(t, 4, tuner.desiredSize(4))
+
+	// The pool is left at its size while idle until the next run.
+	for i := 0; i < 10; i++ {
+		assert.Equal(t, 4, tuner.desiredSize(4))
+	}
+
+	// A run which only uses one worker shrinks the pool.
+	tuner.startEvaluation()
+	tuner.finishEvaluation()
+	assert.Equal(t, 3, tuner.desiredSize(4))
+	assert.Equal(t, 3, tuner.desiredSize(3))
+
+	// An evaluation spanning a tuning interval counts towards both intervals.
+	tuner.startEvaluation()
+	tuner.startEvaluation()
+	assert.Equal(t, 3, tuner.desiredSize(3))
+	tuner.finishEvaluation()
+	tuner.finishEvaluation()
+	assert.Equal(t, 3, tuner.desiredSize(3))
+	assert.Equal(t, 3, tuner.desiredSize(3))
+}
+
+func Test_poolTuner_observeLatency(t *testing.T) {
+	tuner, err := newPoolTuner(1, 10, 1000)
+	assert.Nil(t, err)
+
+	tuner.observeLatency(100 * time.Millisecond)
+	assert.Equal(t, 100*time.Millisecond, tuner.getLatency())
+
+	tuner.observeLatency(600 * time.Millisecond)
+	assert.Equal(t, 200*time.Millisecond, tuner.getLatency())
+}
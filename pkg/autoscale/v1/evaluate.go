@@ -0,0 +1,64 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/jrasell/sherpa/pkg/autoscale"
+	"github.com/rs/zerolog"
+)
+
+// JobEvaluator is the interface used to trigger an out-of-band evaluation of a job.
+type JobEvaluator interface {
+	EvaluateJob(job, group string) (uuid.UUID, error)
+}
+
+// EvaluateResp is the response returned when an out-of-band evaluation is triggered.
+type EvaluateResp struct {
+	EvaluationID string
+}
+
+type Evaluate struct {
+	logger    zerolog.Logger
+	autoscale JobEvaluator
+}
+
+func NewEvaluateServer(l zerolog.Logger, as JobEvaluator) *Evaluate {
+	return &Evaluate{logger: l, autoscale: as}
+}
+
+// EvaluateJob triggers an immediate evaluation of the job, or a single job group if the group is
+// included in the path, and returns the ID of the evaluation.
+func (e *Evaluate) EvaluateJob(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	evalID, err := e.autoscale.EvaluateJob(vars["job_id"], vars["group"])
+	if err != nil {
+		e.logger.Error().Err(err).Str("job", vars["job_id"]).Msg("failed to trigger job evaluation")
+		http.Error(w, err.Error(), evaluateErrorStatusCode(err))
+		return
+	}
+
+	bytes, err := json.Marshal(&EvaluateResp{EvaluationID: evalID.String()})
+	if err != nil {
+		e.logger.Error().Err(err).Msg("failed to marshal evaluation response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+func evaluateErrorStatusCode(err error) int {
+	switch err {
+	case autoscale.ErrNotRunning:
+		return http.StatusServiceUnavailable
+	case autoscale.ErrPolicyNotFound:
+		return http.StatusNotFound
+	case autoscale.ErrNoEligibleGroups, autoscale.ErrJobFiltered:
+		return http.StatusConflict
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/gorilla/mux"
+	"github.com/jrasell/sherpa/pkg/autoscale"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeJobEvaluator struct {
+	job, group string
+	err        error
+}
+
+func (f *fakeJobEvaluator) EvaluateJob(job, group string) (uuid.UUID, error) {
+	f.job, f.group = job, group
+	if f.err != nil {
+		return uuid.Nil, f.err
+	}
+	return uuid.FromStringOrNil("5f1b8d3c-8a6e-4a6b-9f4e-2c1d0b7a9e11"), nil
+}
+
+func TestEvaluate_EvaluateJob(t *testing.T) {
+	testCases := []struct {
+		path               string
+		err                error
+		expectedStatusCode int
+		expectedGroup      string
+		name               string
+	}{
+		{
+			path:               "/v1/autoscaler/evaluate/example",
+			expectedStatusCode: http.StatusOK,
+			name:               "job evaluation",
+		},
+		{
+			path:               "/v1/autoscaler/evaluate/example/cache",
+			expectedStatusCode: http.StatusOK,
+			expectedGroup:      "cache",
+			name:               "job group evaluation",
+		},
+		{
+			path:               "/v1/autoscaler/evaluate/example",
+			err:                autoscale.ErrPolicyNotFound,
+			expectedStatusCode: http.StatusNotFound,
+			name:               "job without policy",
+		},
+		{
+			path:               "/v1/autoscaler/evaluate/example",
+			err:                autoscale.ErrNoEligibleGroups,
+			expectedStatusCode: http.StatusConflict,
+			name:               "job groups in cooldown",
+		},
+		{
+			path:               "/v1/autoscaler/evaluate/example",
+			err:                autoscale.ErrJobFiltered,
+			expectedStatusCode: http.StatusConflict,
+			name:               "job excluded by filter",
+		},
+	}
+
+	for _, tc := range testCases {
+		evaluator := &fakeJobEvaluator{err: tc.err}
+		srv := NewEvaluateServer(zerolog.Nop(), evaluator)
+
+		r := mux.NewRouter()
+		r.HandleFunc("/v1/autoscaler/evaluate/{job_id}", srv.EvaluateJob)
+		r.HandleFunc("/v1/autoscaler/evaluate/{job_id}/{group}", srv.EvaluateJob)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPost, tc.path, nil))
+
+		assert.Equal(t, tc.expectedStatusCode, w.Code, tc.name)
+		assert.Equal(t, "example", evaluator.job, tc.name)
+		assert.Equal(t, tc.expectedGroup, evaluator.group, tc.name)
+
+		if tc.err == nil {
+			assert.JSONEq(t, `{"EvaluationID":"5f1b8d3c-8a6e-4a6b-9f4e-2c1d0b7a9e11"}`, w.Body.String(), tc.name)
+		}
+	}
+}
@@ -0,0 +1,103 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jrasell/sherpa/pkg/autoscale"
+	"github.com/rs/zerolog"
+)
+
+// MetricOverrider is the interface used to manage the autoscaler metric overrides.
+type MetricOverrider interface {
+	SetMetricOverride(job, group, check string, value float64, ttl time.Duration) (*autoscale.MetricOverride, error)
+	DeleteMetricOverride(job, group, check string) error
+	MetricOverrides() []*autoscale.MetricOverride
+}
+
+// OverrideReq is the request body used to set a metric override. The TTL is a duration string,
+// such as "10m".
+type OverrideReq struct {
+	Value *float64
+	TTL   string
+}
+
+type Overrides struct {
+	logger    zerolog.Logger
+	autoscale MetricOverrider
+}
+
+func NewOverridesServer(l zerolog.Logger, as MetricOverrider) *Overrides {
+	return &Overrides{logger: l, autoscale: as}
+}
+
+// GetOverrides returns the currently active metric overrides.
+func (o *Overrides) GetOverrides(w http.ResponseWriter, r *http.Request) {
+	bytes, err := json.Marshal(o.autoscale.MetricOverrides())
+	if err != nil {
+		o.logger.Error().Err(err).Msg("failed to marshal metric overrides response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// PutOverride sets the metric override for the job group check.
+func (o *Overrides) PutOverride(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req OverrideReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "failed to decode request body", http.StatusUnprocessableEntity)
+		return
+	}
+	if req.Value == nil {
+		http.Error(w, "metric override value is required", http.StatusUnprocessableEntity)
+		return
+	}
+
+	ttl, err := time.ParseDuration(req.TTL)
+	if err != nil {
+		http.Error(w, "failed to parse metric override TTL as duration", http.StatusUnprocessableEntity)
+		return
+	}
+
+	override, err := o.autoscale.SetMetricOverride(vars["job_id"], vars["group"], vars["check"], *req.Value, ttl)
+	if err != nil {
+		o.logger.Error().Err(err).Str("job", vars["job_id"]).Msg("failed to set metric override")
+		http.Error(w, err.Error(), overrideErrorStatusCode(err))
+		return
+	}
+
+	bytes, err := json.Marshal(override)
+	if err != nil {
+		o.logger.Error().Err(err).Msg("failed to marshal metric override response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// DeleteOverride removes the metric override for the job group check.
+func (o *Overrides) DeleteOverride(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := o.autoscale.DeleteMetricOverride(vars["job_id"], vars["group"], vars["check"]); err != nil {
+		http.Error(w, err.Error(), overrideErrorStatusCode(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func overrideErrorStatusCode(err error) int {
+	switch err {
+	case autoscale.ErrPolicyNotFound, autoscale.ErrCheckNotFound, autoscale.ErrOverrideNotFound:
+		return http.StatusNotFound
+	case autoscale.ErrInvalidOverrideTTL:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
@@ -0,0 +1,94 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jrasell/sherpa/pkg/autoscale"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeMetricOverrider struct {
+	overrides []*autoscale.MetricOverride
+	err       error
+}
+
+func (f *fakeMetricOverrider) SetMetricOverride(job, group, check string, value float64, ttl time.Duration) (*autoscale.MetricOverride, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	o := &autoscale.MetricOverride{JobID: job, Group: group, Check: check, Value: value,
+		Expires: time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC).Add(ttl)}
+	f.overrides = append(f.overrides, o)
+	return o, nil
+}
+
+func (f *fakeMetricOverrider) DeleteMetricOverride(_, _, _ string) error { return f.err }
+
+func (f *fakeMetricOverrider) MetricOverrides() []*autoscale.MetricOverride { return f.overrides }
+
+func TestOverrides_PutOverride(t *testing.T) {
+	testCases := []struct {
+		body               string
+		err                error
+		expectedStatusCode int
+		expectedBody       string
+		name               string
+	}{
+		{
+			body:               `{"Value": 95, "TTL": "10m"}`,
+			expectedStatusCode: http.StatusOK,
+			expectedBody:       `{"JobID":"example","Group":"cache","Check":"nomad-cpu","Value":95,"Expires":"2020-01-26T10:10:00Z"}`,
+			name:               "valid override",
+		},
+		{
+			body:               `{"TTL": "10m"}`,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			name:               "missing value",
+		},
+		{
+			body:               `{"Value": 95, "TTL": "ten"}`,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			name:               "invalid TTL",
+		},
+		{
+			body:               `{"Value": 95, "TTL": "10m"}`,
+			err:                autoscale.ErrCheckNotFound,
+			expectedStatusCode: http.StatusNotFound,
+			name:               "check not found",
+		},
+	}
+
+	for _, tc := range testCases {
+		srv := NewOverridesServer(zerolog.Nop(), &fakeMetricOverrider{err: tc.err})
+
+		r := mux.NewRouter()
+		r.HandleFunc("/v1/autoscaler/override/{job_id}/{group}/{check}", srv.PutOverride)
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/v1/autoscaler/override/example/cache/nomad-cpu", strings.NewReader(tc.body)))
+
+		assert.Equal(t, tc.expectedStatusCode, w.Code, tc.name)
+		if tc.expectedBody != "" {
+			assert.JSONEq(t, tc.expectedBody, w.Body.String(), tc.name)
+		}
+	}
+}
+
+func TestOverrides_DeleteOverride(t *testing.T) {
+	for err, expectedStatusCode := range map[error]int{
+		nil:                           http.StatusNoContent,
+		autoscale.ErrOverrideNotFound: http.StatusNotFound,
+	} {
+		srv := NewOverridesServer(zerolog.Nop(), &fakeMetricOverrider{err: err})
+
+		w := httptest.NewRecorder()
+		srv.DeleteOverride(w, httptest.NewRequest(http.MethodDelete, "/v1/autoscaler/override/example/cache/queue", nil))
+		assert.Equal(t, expectedStatusCode, w.Code)
+	}
+}
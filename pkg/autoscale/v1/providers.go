@@ -0,0 +1,44 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// ProviderStatusGetter is the interface used to retrieve the status of the autoscaler metric
+// providers.
+type ProviderStatusGetter interface {
+	ProviderStatus() []metrics.ProviderStatus
+}
+
+type Providers struct {
+	logger    zerolog.Logger
+	autoscale ProviderStatusGetter
+}
+
+func NewProvidersServer(l zerolog.Logger, as ProviderStatusGetter) *Providers {
+	return &Providers{logger: l, autoscale: as}
+}
+
+// GetStatus returns the health and circuit breaker state of each configured metric provider.
+func (p *Providers) GetStatus(w http.ResponseWriter, r *http.Request) {
+	bytes, err := json.Marshal(p.autoscale.ProviderStatus())
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to marshal provider status response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+func writeJSONResponse(w http.ResponseWriter, bytes []byte, statusCode int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(bytes); err != nil {
+		log.Error().Err(err).Msg("failed to write JSON response")
+	}
+}
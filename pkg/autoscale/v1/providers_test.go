@@ -0,0 +1,33 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeStatusGetter struct {
+	status []metrics.ProviderStatus
+}
+
+func (f *fakeStatusGetter) ProviderStatus() []metrics.ProviderStatus { return f.status }
+
+func TestProviders_GetStatus(t *testing.T) {
+	getter := &fakeStatusGetter{status: []metrics.ProviderStatus{
+		{Name: "prometheus", State: metrics.ProviderStateOpen, Queries: 10, Errors: 6, ErrorRate: 60},
+	}}
+	srv := NewProvidersServer(zerolog.Nop(), getter)
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/providers/status", nil)
+	w := httptest.NewRecorder()
+	srv.GetStatus(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t,
+		`[{"Name":"prometheus","State":"open","Reachable":false,"ErrorRate":60,"Queries":10,"Errors":6}]`,
+		w.Body.String())
+}
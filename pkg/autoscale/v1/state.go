@@ -0,0 +1,59 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jrasell/sherpa/pkg/autoscale"
+	"github.com/rs/zerolog"
+)
+
+// InternalStater is the interface used to read and restore the internal state of the autoscaler.
+type InternalStater interface {
+	InternalState() (*autoscale.InternalState, error)
+	RestoreInternalState(*autoscale.InternalState) error
+}
+
+type State struct {
+	logger    zerolog.Logger
+	autoscale InternalStater
+}
+
+func NewStateServer(l zerolog.Logger, as InternalStater) *State {
+	return &State{logger: l, autoscale: as}
+}
+
+// GetState returns the internal state of the autoscaler.
+func (s *State) GetState(w http.ResponseWriter, r *http.Request) {
+	internal, err := s.autoscale.InternalState()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to read autoscaler internal state")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(internal)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to marshal autoscaler internal state response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// PutState restores the internal state of the autoscaler using the state within the request body,
+// which is in the format returned by GetState.
+func (s *State) PutState(w http.ResponseWriter, r *http.Request) {
+	var internal autoscale.InternalState
+	if err := json.NewDecoder(r.Body).Decode(&internal); err != nil {
+		http.Error(w, "failed to decode request body", http.StatusUnprocessableEntity)
+		return
+	}
+
+	if err := s.autoscale.RestoreInternalState(&internal); err != nil {
+		s.logger.Error().Err(err).Msg("failed to restore autoscaler internal state")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
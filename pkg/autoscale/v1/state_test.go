@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/autoscale"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeInternalStater struct {
+	state    *autoscale.InternalState
+	restored *autoscale.InternalState
+	err      error
+}
+
+func (f *fakeInternalStater) InternalState() (*autoscale.InternalState, error) { return f.state, f.err }
+
+func (f *fakeInternalStater) RestoreInternalState(s *autoscale.InternalState) error {
+	if f.err != nil {
+		return f.err
+	}
+	f.restored = s
+	return nil
+}
+
+func TestState_GetState(t *testing.T) {
+	fake := &fakeInternalStater{state: &autoscale.InternalState{
+		Time:      time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC),
+		Cooldowns: map[string]map[string]int64{"example": {"cache": 1580032800000000000}},
+	}}
+
+	w := httptest.NewRecorder()
+	NewStateServer(zerolog.Nop(), fake).GetState(w, httptest.NewRequest(http.MethodGet, "/v1/autoscaler/state", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"Time":"2020-01-26T10:00:00Z","Cooldowns":{"example":{"cache":1580032800000000000}},
+		"LastEvaluated":null,"LastDecisions":null,"Overrides":null,"OrphanedJobs":null}`, w.Body.String())
+
+	fake.err = errors.New("backend unavailable")
+	w = httptest.NewRecorder()
+	NewStateServer(zerolog.Nop(), fake).GetState(w, httptest.NewRequest(http.MethodGet, "/v1/autoscaler/state", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestState_PutState(t *testing.T) {
+	testCases := []struct {
+		body               string
+		err                error
+		expectedStatusCode int
+		name               string
+	}{
+		{
+			body:               `{"Cooldowns": {"example": {"cache": 1580032800000000000}}}`,
+			expectedStatusCode: http.StatusNoContent,
+			name:               "valid state",
+		},
+		{
+			body:               `{"Cooldowns": []}`,
+			expectedStatusCode: http.StatusUnprocessableEntity,
+			name:               "invalid state",
+		},
+		{
+			body:               `{}`,
+			err:                errors.New("backend unavailable"),
+			expectedStatusCode: http.StatusInternalServerError,
+			name:               "restore failure",
+		},
+	}
+
+	for _, tc := range testCases {
+		fake := &fakeInternalStater{err: tc.err}
+
+		w := httptest.NewRecorder()
+		NewStateServer(zerolog.Nop(), fake).PutState(w, httptest.NewRequest(http.MethodPut, "/v1/autoscaler/state", strings.NewReader(tc.body)))
+		assert.Equal(t, tc.expectedStatusCode, w.Code, tc.name)
+
+		if tc.expectedStatusCode == http.StatusNoContent {
+			assert.Equal(t, int64(1580032800000000000), fake.restored.Cooldowns["example"]["cache"], tc.name)
+		}
+	}
+}
@@ -3,6 +3,7 @@ package build
 import (
 	"encoding/hex"
 	"fmt"
+	"runtime"
 	"strconv"
 	"time"
 )
@@ -54,3 +55,24 @@ func GetVersion() string {
 	return fmt.Sprintf("%s\n\tDate:   %s\n\tCommit: %s\n\tBranch: %s\n\tState:  %s",
 		version, GitDate, gitCommit, GitBranch, GitState)
 }
+
+// Info describes the build of the running binary.
+type Info struct {
+	Version   string
+	GitCommit string
+	GitBranch string
+	GitState  string
+	GoVersion string
+}
+
+// GetInfo returns the build information of the running binary. Fields which were not set at build
+// time are empty.
+func GetInfo() Info {
+	return Info{
+		Version:   Version,
+		GitCommit: GitCommit,
+		GitBranch: GitBranch,
+		GitState:  GitState,
+		GoVersion: runtime.Version(),
+	}
+}
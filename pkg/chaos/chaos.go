@@ -0,0 +1,96 @@
+// Package chaos provides fault injection for resilience testing. When enabled, calls to Nomad, the
+// policy backend and metric providers are randomly delayed and failed, allowing operators and CI
+// to validate Sherpa behaviour during partial outages.
+package chaos
+
+import (
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// ErrInjectedFault is the error returned by calls which the injector has chosen to fail.
+var ErrInjectedFault = errors.New("chaos injected fault")
+
+// Fault targets used to identify the type of call being injected.
+const (
+	TargetNomad          = "nomad"
+	TargetPolicyBackend  = "policy-backend"
+	TargetMetricProvider = "metric-provider"
+)
+
+// Injector randomly delays and fails calls. A nil Injector is valid and never injects faults, so
+// that callers do not need to check whether fault injection is enabled.
+type Injector struct {
+	logger      zerolog.Logger
+	failureRate float64
+	maxDelay    time.Duration
+
+	lock sync.Mutex
+	rand *rand.Rand
+}
+
+// NewInjector returns a fault injector which fails calls with the probability failureRate, and
+// delays calls by up to maxDelay.
+func NewInjector(l zerolog.Logger, failureRate float64, maxDelay time.Duration) *Injector {
+	return &Injector{
+		logger:      l,
+		failureRate: failureRate,
+		maxDelay:    maxDelay,
+		rand:        rand.New(rand.NewSource(time.Now().UnixNano())), // nolint:gosec
+	}
+}
+
+// Inject is called before performing a call to the target. It randomly delays, returning early if
+// the context is cancelled, and then randomly returns ErrInjectedFault.
+func (i *Injector) Inject(ctx context.Context, target string) error {
+	if i == nil {
+		return nil
+	}
+
+	delay, fail := i.roll()
+
+	if delay > 0 {
+		sendFaultMetrics(target, "delay")
+
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return ctx.Err()
+		case <-t.C:
+		}
+	}
+
+	if fail {
+		sendFaultMetrics(target, "failure")
+		i.logger.Debug().Str("target", target).Msg("injecting fault into call")
+		return errors.Wrap(ErrInjectedFault, target)
+	}
+	return nil
+}
+
+// roll decides the delay and whether the call should fail. The random source is not safe for
+// concurrent use so is protected by the lock.
+func (i *Injector) roll() (time.Duration, bool) {
+	i.lock.Lock()
+	defer i.lock.Unlock()
+
+	var delay time.Duration
+	if i.maxDelay > 0 {
+		delay = time.Duration(i.rand.Int63n(int64(i.maxDelay)))
+	}
+	return delay, i.rand.Float64() < i.failureRate
+}
+
+func sendFaultMetrics(target, fault string) {
+	sendMetrics.IncrCounterWithLabels([]string{"chaos", "fault"}, 1, []sendMetrics.Label{
+		{Name: "target", Value: target},
+		{Name: "fault", Value: fault},
+	})
+}
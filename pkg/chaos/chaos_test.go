@@ -0,0 +1,46 @@
+package chaos
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	policyMemory "github.com/jrasell/sherpa/pkg/policy/backend/memory"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInjector_Inject(t *testing.T) {
+	var nilInjector *Injector
+	assert.Nil(t, nilInjector.Inject(context.Background(), TargetNomad))
+
+	alwaysFail := NewInjector(zerolog.Nop(), 1, 0)
+	err := alwaysFail.Inject(context.Background(), TargetNomad)
+	assert.Equal(t, ErrInjectedFault, errors.Cause(err))
+
+	neverFail := NewInjector(zerolog.Nop(), 0, 0)
+	assert.Nil(t, neverFail.Inject(context.Background(), TargetNomad))
+
+	// A cancelled context should end an injected delay early.
+	delayed := NewInjector(zerolog.Nop(), 0, time.Hour)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.Equal(t, context.Canceled, delayed.Inject(ctx, TargetNomad))
+}
+
+func TestPolicyBackend(t *testing.T) {
+	pol := &policy.GroupScalingPolicy{Enabled: true, MinCount: 1, MaxCount: 3}
+
+	b := NewPolicyBackend(NewInjector(zerolog.Nop(), 0, 0), policyMemory.NewJobScalingPolicies())
+	assert.Nil(t, b.PutJobGroupPolicy(context.Background(), "example", "cache", pol))
+
+	actual, err := b.GetJobGroupPolicy(context.Background(), "example", "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, pol, actual)
+
+	failing := NewPolicyBackend(NewInjector(zerolog.Nop(), 1, 0), policyMemory.NewJobScalingPolicies())
+	_, err = failing.GetPolicies(context.Background())
+	assert.Equal(t, ErrInjectedFault, errors.Cause(err))
+}
@@ -0,0 +1,100 @@
+package chaos
+
+import (
+	"context"
+
+	"github.com/jrasell/sherpa/pkg/autoscale/metrics"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/policy/backend"
+)
+
+// PolicyBackend wraps a policy backend, injecting faults into each call.
+type PolicyBackend struct {
+	injector *Injector
+	backend  backend.PolicyBackend
+}
+
+// NewPolicyBackend wraps the passed policy backend with the fault injector.
+func NewPolicyBackend(i *Injector, b backend.PolicyBackend) backend.PolicyBackend {
+	return &PolicyBackend{injector: i, backend: b}
+}
+
+// PutJobPolicy satisfies the PutJobPolicy function of the backend.PolicyBackend interface.
+func (p *PolicyBackend) PutJobPolicy(ctx context.Context, job string, pol map[string]*policy.GroupScalingPolicy) error {
+	if err := p.injector.Inject(ctx, TargetPolicyBackend); err != nil {
+		return err
+	}
+	return p.backend.PutJobPolicy(ctx, job, pol)
+}
+
+// PutJobGroupPolicy satisfies the PutJobGroupPolicy function of the backend.PolicyBackend interface.
+func (p *PolicyBackend) PutJobGroupPolicy(ctx context.Context, job, group string, pol *policy.GroupScalingPolicy) error {
+	if err := p.injector.Inject(ctx, TargetPolicyBackend); err != nil {
+		return err
+	}
+	return p.backend.PutJobGroupPolicy(ctx, job, group, pol)
+}
+
+// GetPolicies satisfies the GetPolicies function of the backend.PolicyBackend interface.
+func (p *PolicyBackend) GetPolicies(ctx context.Context) (map[string]map[string]*policy.GroupScalingPolicy, error) {
+	if err := p.injector.Inject(ctx, TargetPolicyBackend); err != nil {
+		return nil, err
+	}
+	return p.backend.GetPolicies(ctx)
+}
+
+// GetJobPolicy satisfies the GetJobPolicy function of the backend.PolicyBackend interface.
+func (p *PolicyBackend) GetJobPolicy(ctx context.Context, job string) (map[string]*policy.GroupScalingPolicy, error) {
+	if err := p.injector.Inject(ctx, TargetPolicyBackend); err != nil {
+		return nil, err
+	}
+	return p.backend.GetJobPolicy(ctx, job)
+}
+
+// GetJobGroupPolicy satisfies the GetJobGroupPolicy function of the backend.PolicyBackend interface.
+func (p *PolicyBackend) GetJobGroupPolicy(ctx context.Context, job, group string) (*policy.GroupScalingPolicy, error) {
+	if err := p.injector.Inject(ctx, TargetPolicyBackend); err != nil {
+		return nil, err
+	}
+	return p.backend.GetJobGroupPolicy(ctx, job, group)
+}
+
+// DeleteJobPolicy satisfies the DeleteJobPolicy function of the backend.PolicyBackend interface.
+func (p *PolicyBackend) DeleteJobPolicy(ctx context.Context, job string) error {
+	if err := p.injector.Inject(ctx, TargetPolicyBackend); err != nil {
+		return err
+	}
+	return p.backend.DeleteJobPolicy(ctx, job)
+}
+
+// DeleteJobGroupPolicy satisfies the DeleteJobGroupPolicy function of the backend.PolicyBackend
+// interface.
+func (p *PolicyBackend) DeleteJobGroupPolicy(ctx context.Context, job, group string) error {
+	if err := p.injector.Inject(ctx, TargetPolicyBackend); err != nil {
+		return err
+	}
+	return p.backend.DeleteJobGroupPolicy(ctx, job, group)
+}
+
+// MetricsProvider wraps a metric provider, injecting faults into each query.
+type MetricsProvider struct {
+	injector *Injector
+	provider metrics.Provider
+}
+
+// NewMetricsProvider wraps the passed metric provider with the fault injector.
+func NewMetricsProvider(i *Injector, p metrics.Provider) metrics.Provider {
+	return &MetricsProvider{injector: i, provider: p}
+}
+
+// Name satisfies the Name function of the metrics.Provider interface, returning the name of the
+// wrapped provider.
+func (m *MetricsProvider) Name() string { return m.provider.Name() }
+
+// GetValue satisfies the GetValue function of the metrics.Provider interface.
+func (m *MetricsProvider) GetValue(ctx context.Context, query string) (*float64, error) {
+	if err := m.injector.Inject(ctx, TargetMetricProvider); err != nil {
+		return nil, err
+	}
+	return m.provider.GetValue(ctx, query)
+}
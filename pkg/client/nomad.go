@@ -1,12 +1,62 @@
 package client
 
 import (
+	"crypto/tls"
+	"net/http"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
 	nomadAPI "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/helper"
 )
 
 // NewNomadClient is responsible for generating a reusable Nomad client using the HashiCorp Nomad
 // SDK and the default config. This default config pulls Nomad client configuration from env vars
 // which can therefore be customized by the user.
 func NewNomadClient() (*nomadAPI.Client, error) {
-	return nomadAPI.NewClient(nomadAPI.DefaultConfig())
+	cfg, err := newNomadConfig()
+	if err != nil {
+		return nil, err
+	}
+	return nomadAPI.NewClient(cfg)
+}
+
+// newNomadConfig returns the default Nomad client config, using an HTTP client which sends the
+// request ID carried by the context of each Nomad API request.
+func newNomadConfig() (*nomadAPI.Config, error) {
+	cfg := nomadAPI.DefaultConfig()
+
+	// The HTTP client matches the default of the Nomad SDK, which is ignored when a client is
+	// supplied, so TLS must be configured before the transport is wrapped.
+	httpClient := cleanhttp.DefaultClient()
+	transport := httpClient.Transport.(*http.Transport)
+	transport.TLSHandshakeTimeout = 10 * time.Second
+	transport.TLSClientConfig = &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if err := nomadAPI.ConfigureTLS(httpClient, cfg.TLSConfig); err != nil {
+		return nil, err
+	}
+
+	httpClient.Transport = &requestIDTransport{next: transport}
+	cfg.HttpClient = httpClient
+	return cfg, nil
+}
+
+// requestIDTransport is a http.RoundTripper which sets the X-Request-ID header of requests whose
+// context carries a request ID, so that API requests can be traced into the Nomad server logs.
+type requestIDTransport struct {
+	next http.RoundTripper
+}
+
+// RoundTrip satisfies the http.RoundTripper interface. The request is cloned before the header is
+// set, as a RoundTripper must not modify the request it is passed.
+func (t *requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := helper.RequestIDFromContext(req.Context())
+	if id == "" {
+		return t.next.RoundTrip(req)
+	}
+
+	r := req.Clone(req.Context())
+	r.Header.Set(helper.RequestIDHeader, id)
+	return t.next.RoundTrip(r)
 }
@@ -1,9 +1,15 @@
 package client
 
 import (
+	"context"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"testing"
 
+	nomadAPI "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,3 +25,39 @@ func Test_NewNomadClient(t *testing.T) {
 	nAddr := client.Address()
 	assert.Equal(t, addr, nAddr)
 }
+
+func Test_requestIDTransport(t *testing.T) {
+	var header string
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get(helper.RequestIDHeader)
+		_, _ = w.Write([]byte(`{"ID":"example"}`))
+	}))
+	defer srv.Close()
+
+	pool, err := NewNomadPool(zerolog.Nop(), []string{srv.URL}, 0)
+	assert.Nil(t, err)
+
+	testCases := []struct {
+		ctx            context.Context
+		expectedHeader string
+		name           string
+	}{
+		{
+			ctx:            helper.ContextWithRequestID(context.Background(), "c0ffee"),
+			expectedHeader: "c0ffee",
+			name:           "request ID in context",
+		},
+		{
+			ctx:            context.Background(),
+			expectedHeader: "",
+			name:           "no request ID in context",
+		},
+	}
+
+	for _, tc := range testCases {
+		_, _, err := pool.Client().Jobs().Info("example", (&nomadAPI.QueryOptions{}).WithContext(tc.ctx))
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.expectedHeader, header, tc.name)
+	}
+}
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	nomadAPI "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/chaos"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/retry"
+	"github.com/rs/zerolog"
+)
+
+// probeTimeout is the time a Nomad server has to respond to a health probe.
+const probeTimeout = 5 * time.Second
+
+// NomadPool maintains a Nomad API client for each configured Nomad server address. A single
+// client is active at any one time, and the pool fails over to the next healthy server when the
+// active server becomes unreachable. All addresses should be servers within the same region.
+type NomadPool struct {
+	logger   zerolog.Logger
+	clients  []*nomadAPI.Client
+	interval time.Duration
+
+	// probe is used to check the health of a Nomad server, and is configurable for testing.
+	probe func(*nomadAPI.Client) error
+
+	// faults is the optional fault injector used by Call for resilience testing.
+	faults *chaos.Injector
+
+	// retry is the policy used by Call to retry failed Nomad API calls.
+	retry retry.Policy
+
+	lock   sync.RWMutex
+	active int
+}
+
+// NewNomadPool builds a Nomad client for each of the passed addresses, with the first address
+// being active. All other client configuration is pulled from the default config env vars. If no
+// addresses are passed, the pool contains a single client using the default config.
+func NewNomadPool(l zerolog.Logger, addrs []string, interval time.Duration) (*NomadPool, error) {
+	p := NomadPool{
+		logger:   l,
+		interval: interval,
+		probe:    probeNomadServer,
+		retry:    retry.Default,
+	}
+
+	if len(addrs) == 0 {
+		nc, err := NewNomadClient()
+		if err != nil {
+			return nil, err
+		}
+		p.clients = append(p.clients, nc)
+		return &p, nil
+	}
+
+	for _, addr := range addrs {
+		cfg, err := newNomadConfig()
+		if err != nil {
+			return nil, err
+		}
+		cfg.Address = addr
+
+		nc, err := nomadAPI.NewClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		p.clients = append(p.clients, nc)
+	}
+	return &p, nil
+}
+
+// Client returns the active Nomad API client. Callers should call Client for each operation
+// rather than storing the result, so that operations use the newly active client after a
+// failover.
+func (p *NomadPool) Client() *nomadAPI.Client {
+	if p == nil {
+		return nil
+	}
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.clients[p.active]
+}
+
+// SetFaultInjector configures the fault injector used to randomly delay and fail calls made using
+// Call. It should only be used for resilience testing.
+func (p *NomadPool) SetFaultInjector(i *chaos.Injector) { p.faults = i }
+
+// Call runs the Nomad API call f, returning early with an error if the context is cancelled. Failed
+// calls are retried with backoff until the context is done, unless the error indicates a retry
+// will not succeed. If a fault injector is configured, each attempt may be delayed or failed
+// before it is made.
+func (p *NomadPool) Call(ctx context.Context, f func() error) error {
+	var policy retry.Policy
+	if p != nil {
+		policy = p.retry
+	}
+
+	return retry.Do(ctx, retry.TargetNomad, policy, func() error {
+		if p != nil {
+			if err := p.faults.Inject(ctx, chaos.TargetNomad); err != nil {
+				return err
+			}
+		}
+		return RetryableNomadError(helper.CallWithContext(ctx, f))
+	})
+}
+
+// RetryableNomadError marks the Nomad API error as permanent if retrying the call will not produce
+// a different result. This includes client errors, such as the job not being found, and job
+// registrations rejected due to the job modify index changing.
+func RetryableNomadError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	msg := err.Error()
+	if strings.Contains(msg, "Unexpected response code: 4") || strings.Contains(msg, "conflicting job modify index") {
+		return retry.Permanent(err)
+	}
+	return err
+}
+
+// Run periodically probes the active Nomad server, failing over when it is unhealthy, until the
+// stop channel is closed. It returns immediately if the pool only contains a single server.
+func (p *NomadPool) Run(stopCh <-chan struct{}) {
+	if len(p.clients) < 2 || p.interval <= 0 {
+		return
+	}
+	p.logger.Info().Int("servers", len(p.clients)).Msg("starting Nomad client pool health probes")
+
+	t := time.NewTicker(p.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			p.checkActive()
+		case <-stopCh:
+			p.logger.Info().Msg("shutting down Nomad client pool health probes")
+			return
+		}
+	}
+}
+
+// checkActive probes the active Nomad server, and if it is unhealthy fails over to the next
+// healthy server in the order the addresses were configured.
+func (p *NomadPool) checkActive() {
+	p.lock.RLock()
+	active := p.active
+	p.lock.RUnlock()
+
+	err := p.probe(p.clients[active])
+	if err == nil {
+		return
+	}
+	p.logger.Warn().
+		Err(err).
+		Str("nomad-addr", p.clients[active].Address()).
+		Msg("active Nomad server failed health probe")
+
+	for i := 1; i < len(p.clients); i++ {
+		next := (active + i) % len(p.clients)
+
+		if err := p.probe(p.clients[next]); err != nil {
+			p.logger.Debug().
+				Err(err).
+				Str("nomad-addr", p.clients[next].Address()).
+				Msg("Nomad server failed health probe")
+			continue
+		}
+
+		p.lock.Lock()
+		p.active = next
+		p.lock.Unlock()
+
+		p.logger.Info().
+			Str("old-nomad-addr", p.clients[active].Address()).
+			Str("new-nomad-addr", p.clients[next].Address()).
+			Msg("failed over Nomad client to healthy server")
+		sendMetrics.IncrCounter([]string{"nomad", "client", "failover"}, 1)
+		return
+	}
+	p.logger.Error().Msg("no healthy Nomad servers found, unable to fail over Nomad client")
+}
+
+// probeNomadServer checks that the Nomad server is reachable and has a cluster leader.
+func probeNomadServer(c *nomadAPI.Client) error {
+	ctx, cancel := context.WithTimeout(context.Background(), probeTimeout)
+	defer cancel()
+
+	return helper.CallWithContext(ctx, func() error {
+		_, err := c.Status().Leader()
+		return err
+	})
+}
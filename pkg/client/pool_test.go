@@ -0,0 +1,106 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	nomadAPI "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/chaos"
+	"github.com/jrasell/sherpa/pkg/retry"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NewNomadPool(t *testing.T) {
+	addrs := []string{"http://nomad-1.jrasell.system:4646", "http://nomad-2.jrasell.system:4646"}
+
+	pool, err := NewNomadPool(zerolog.Nop(), addrs, 0)
+	assert.Nil(t, err)
+	assert.Len(t, pool.clients, 2)
+	assert.Equal(t, addrs[0], pool.Client().Address())
+
+	var nilPool *NomadPool
+	assert.Nil(t, nilPool.Client())
+}
+
+func TestNomadPool_checkActive(t *testing.T) {
+	testCases := []struct {
+		name           string
+		unhealthy      map[string]bool
+		expectedActive string
+	}{
+		{
+			name:           "active server healthy",
+			unhealthy:      map[string]bool{"http://nomad-2:4646": true},
+			expectedActive: "http://nomad-1:4646",
+		},
+		{
+			name:           "fail over to next healthy server",
+			unhealthy:      map[string]bool{"http://nomad-1:4646": true, "http://nomad-2:4646": true},
+			expectedActive: "http://nomad-3:4646",
+		},
+		{
+			name: "no healthy servers",
+			unhealthy: map[string]bool{
+				"http://nomad-1:4646": true, "http://nomad-2:4646": true, "http://nomad-3:4646": true,
+			},
+			expectedActive: "http://nomad-1:4646",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			pool, err := NewNomadPool(zerolog.Nop(),
+				[]string{"http://nomad-1:4646", "http://nomad-2:4646", "http://nomad-3:4646"}, 0)
+			assert.Nil(t, err)
+
+			pool.probe = func(c *nomadAPI.Client) error {
+				if tc.unhealthy[c.Address()] {
+					return errors.New("connection refused")
+				}
+				return nil
+			}
+			pool.checkActive()
+			assert.Equal(t, tc.expectedActive, pool.Client().Address())
+		})
+	}
+}
+
+func TestNomadPool_Call(t *testing.T) {
+	pool, err := NewNomadPool(zerolog.Nop(), nil, 0)
+	assert.Nil(t, err)
+
+	var called bool
+	assert.Nil(t, pool.Call(context.Background(), func() error { called = true; return nil }))
+	assert.True(t, called)
+
+	// When the fault injector fails the call, the Nomad API call should not be made.
+	called = false
+	pool.retry = retry.Policy{}
+	pool.SetFaultInjector(chaos.NewInjector(zerolog.Nop(), 1, 0))
+	assert.NotNil(t, pool.Call(context.Background(), func() error { called = true; return nil }))
+	assert.False(t, called)
+}
+
+func TestNomadPool_Call_Retry(t *testing.T) {
+	pool, err := NewNomadPool(zerolog.Nop(), nil, 0)
+	assert.Nil(t, err)
+	pool.retry = retry.Policy{Attempts: 3, BaseDelay: time.Millisecond}
+
+	calls := 0
+	assert.NotNil(t, pool.Call(context.Background(), func() error {
+		calls++
+		return errors.New("Unexpected response code: 500 (rpc error: No cluster leader)")
+	}))
+	assert.Equal(t, 3, calls)
+
+	// Client errors will not succeed if retried, so should only be called once.
+	calls = 0
+	assert.EqualError(t, pool.Call(context.Background(), func() error {
+		calls++
+		return errors.New("Unexpected response code: 404 (job not found)")
+	}), "Unexpected response code: 404 (job not found)")
+	assert.Equal(t, 1, calls)
+}
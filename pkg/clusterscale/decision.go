@@ -0,0 +1,201 @@
+package clusterscale
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/scale"
+)
+
+// ClassDecision is the scaling decision made for a node class by the latest evaluation.
+type ClassDecision struct {
+	Class string
+
+	// Direction is the direction the class should be scaled in, and TargetNodes is the node count
+	// the class should be scaled to. The direction is none if the class is at its target, or if
+	// scaling is blocked by a cooldown or the stabilization window.
+	Direction   scale.Direction
+	TargetNodes int
+
+	// Nodes is the current number of eligible nodes of the class, and DesiredNodes is the node
+	// count desired by the class policy before the stabilization window is applied.
+	Nodes        int
+	DesiredNodes int
+
+	// Reason details why the class is not being scaled, such as an active cooldown.
+	Reason string `json:",omitempty"`
+
+	Time time.Time
+}
+
+// observation is the desired node count of a class at a point in time.
+type observation struct {
+	time    time.Time
+	desired int
+}
+
+// classHistory is the scaling history of a node class, used to apply cooldowns and the
+// stabilization window.
+type classHistory struct {
+	// nodes is the node count observed by the previous evaluation, or -1 if the class has not
+	// been evaluated.
+	nodes int
+
+	lastScaleOut time.Time
+	lastScaleIn  time.Time
+
+	observations []observation
+}
+
+// decider makes the scaling decision of each node class, tracking the history of each so that
+// cooldowns and the stabilization window can be applied.
+type decider struct {
+	scaleOutCooldown time.Duration
+	scaleInCooldown  time.Duration
+	window           time.Duration
+
+	lock    sync.Mutex
+	history map[string]*classHistory
+	latest  map[string]*ClassDecision
+}
+
+func newDecider(scaleOutCooldown, scaleInCooldown, window time.Duration) *decider {
+	return &decider{
+		scaleOutCooldown: scaleOutCooldown,
+		scaleInCooldown:  scaleInCooldown,
+		window:           window,
+		history:          make(map[string]*classHistory),
+		latest:           make(map[string]*ClassDecision),
+	}
+}
+
+// decide returns the scaling decision of the class at the passed time. A change in the node count
+// of the class since the previous decision is treated as a scaling action, starting the cooldown
+// of its direction, so that nodes added or removed outside of Sherpa are also respected.
+//
+// Scale out is performed as soon as the desired node count rises above the current count, unless
+// the class is within its scale out cooldown. Scale in uses the highest desired node count observed
+// within the stabilization window, so that a brief drop in utilization does not terminate nodes
+// which are needed again shortly after, and is blocked within the scale in cooldown of any scaling
+// action.
+func (d *decider) decide(st *ClassStatus, now time.Time) *ClassDecision {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	h, ok := d.history[st.Class]
+	if !ok {
+		h = &classHistory{nodes: -1}
+		d.history[st.Class] = h
+	}
+
+	if h.nodes >= 0 {
+		switch {
+		case st.Nodes > h.nodes:
+			h.lastScaleOut = now
+		case st.Nodes < h.nodes:
+			h.lastScaleIn = now
+		}
+	}
+	h.nodes = st.Nodes
+	h.record(st.DesiredNodes, now, d.window)
+
+	dec := ClassDecision{
+		Class:        st.Class,
+		Direction:    scale.DirectionNone,
+		TargetNodes:  st.Nodes,
+		Nodes:        st.Nodes,
+		DesiredNodes: st.DesiredNodes,
+		Time:         now,
+	}
+
+	switch {
+	case st.DesiredNodes > st.Nodes:
+		if until := h.lastScaleOut.Add(d.scaleOutCooldown); now.Before(until) {
+			dec.Reason = fmt.Sprintf("scale out cooldown active until %s", until.Format(time.RFC3339))
+			break
+		}
+		dec.Direction, dec.TargetNodes = scale.DirectionOut, st.DesiredNodes
+
+	case st.DesiredNodes < st.Nodes:
+		stabilized := h.maxDesired()
+		if stabilized >= st.Nodes {
+			dec.Reason = fmt.Sprintf("stabilization window desires %d nodes", stabilized)
+			break
+		}
+		if until := h.lastAction().Add(d.scaleInCooldown); now.Before(until) {
+			dec.Reason = fmt.Sprintf("scale in cooldown active until %s", until.Format(time.RFC3339))
+			break
+		}
+		dec.Direction, dec.TargetNodes = scale.DirectionIn, stabilized
+	}
+
+	d.latest[st.Class] = &dec
+	return &dec
+}
+
+// actioned records that the class was scaled in the direction at the passed time, starting the
+// cooldown of the direction before the change in node count is observed.
+func (d *decider) actioned(class string, direction scale.Direction, t time.Time) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	h, ok := d.history[class]
+	if !ok {
+		h = &classHistory{nodes: -1}
+		d.history[class] = h
+	}
+
+	switch direction {
+	case scale.DirectionOut:
+		h.lastScaleOut = t
+	case scale.DirectionIn:
+		h.lastScaleIn = t
+	}
+}
+
+// decisions returns the latest decision of each class, sorted by class.
+func (d *decider) decisions() []*ClassDecision {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	out := make([]*ClassDecision, 0, len(d.latest))
+	for _, dec := range d.latest {
+		c := *dec
+		out = append(out, &c)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Class < out[j].Class })
+	return out
+}
+
+// record adds the desired node count observation, discarding observations which have fallen out
+// of the stabilization window.
+func (h *classHistory) record(desired int, now time.Time, window time.Duration) {
+	kept := h.observations[:0]
+	for _, o := range h.observations {
+		if now.Sub(o.time) < window {
+			kept = append(kept, o)
+		}
+	}
+	h.observations = append(kept, observation{time: now, desired: desired})
+}
+
+// maxDesired returns the highest desired node count within the stabilization window.
+func (h *classHistory) maxDesired() int {
+	var max int
+	for _, o := range h.observations {
+		if o.desired > max {
+			max = o.desired
+		}
+	}
+	return max
+}
+
+// lastAction returns the time of the most recent scaling action of the class in either direction.
+func (h *classHistory) lastAction() time.Time {
+	if h.lastScaleOut.After(h.lastScaleIn) {
+		return h.lastScaleOut
+	}
+	return h.lastScaleIn
+}
@@ -0,0 +1,71 @@
+package clusterscale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_decider_scaleOutCooldown(t *testing.T) {
+	d := newDecider(5*time.Minute, 10*time.Minute, 10*time.Minute)
+	start := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+
+	dec := d.decide(&ClassStatus{Class: "batch", Nodes: 3, DesiredNodes: 5}, start)
+	assert.Equal(t, scale.DirectionOut, dec.Direction)
+	assert.Equal(t, 5, dec.TargetNodes)
+
+	// The class has been scaled out, so a further scale out is blocked by the cooldown.
+	dec = d.decide(&ClassStatus{Class: "batch", Nodes: 5, DesiredNodes: 6}, start.Add(time.Minute))
+	assert.Equal(t, scale.DirectionNone, dec.Direction)
+	assert.Equal(t, 5, dec.TargetNodes)
+	assert.Equal(t, "scale out cooldown active until 2020-01-26T10:06:00Z", dec.Reason)
+
+	dec = d.decide(&ClassStatus{Class: "batch", Nodes: 5, DesiredNodes: 6}, start.Add(6*time.Minute))
+	assert.Equal(t, scale.DirectionOut, dec.Direction)
+	assert.Equal(t, 6, dec.TargetNodes)
+	assert.Empty(t, dec.Reason)
+}
+
+func Test_decider_stabilizationWindow(t *testing.T) {
+	d := newDecider(0, 0, 10*time.Minute)
+	start := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+
+	dec := d.decide(&ClassStatus{Class: "batch", Nodes: 6, DesiredNodes: 6}, start)
+	assert.Equal(t, scale.DirectionNone, dec.Direction)
+	assert.Empty(t, dec.Reason)
+
+	dec = d.decide(&ClassStatus{Class: "batch", Nodes: 6, DesiredNodes: 2}, start.Add(5*time.Minute))
+	assert.Equal(t, scale.DirectionNone, dec.Direction)
+	assert.Equal(t, "stabilization window desires 6 nodes", dec.Reason)
+
+	dec = d.decide(&ClassStatus{Class: "batch", Nodes: 6, DesiredNodes: 4}, start.Add(8*time.Minute))
+	assert.Equal(t, scale.DirectionNone, dec.Direction)
+
+	// The observation of 6 nodes has left the window, so the class is scaled in to the highest
+	// remaining desired count rather than the latest.
+	dec = d.decide(&ClassStatus{Class: "batch", Nodes: 6, DesiredNodes: 2}, start.Add(11*time.Minute))
+	assert.Equal(t, scale.DirectionIn, dec.Direction)
+	assert.Equal(t, 4, dec.TargetNodes)
+	assert.Equal(t, 2, dec.DesiredNodes)
+}
+
+func Test_decider_scaleInCooldown(t *testing.T) {
+	d := newDecider(0, 10*time.Minute, 0)
+	start := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+
+	dec := d.decide(&ClassStatus{Class: "batch", Nodes: 4, DesiredNodes: 4}, start)
+	assert.Equal(t, scale.DirectionNone, dec.Direction)
+
+	// Nodes added outside of Sherpa start the scale in cooldown.
+	dec = d.decide(&ClassStatus{Class: "batch", Nodes: 6, DesiredNodes: 3}, start.Add(time.Minute))
+	assert.Equal(t, scale.DirectionNone, dec.Direction)
+	assert.Equal(t, "scale in cooldown active until 2020-01-26T10:11:00Z", dec.Reason)
+
+	dec = d.decide(&ClassStatus{Class: "batch", Nodes: 6, DesiredNodes: 3}, start.Add(11*time.Minute))
+	assert.Equal(t, scale.DirectionIn, dec.Direction)
+	assert.Equal(t, 3, dec.TargetNodes)
+
+	assert.Equal(t, []*ClassDecision{dec}, d.decisions())
+}
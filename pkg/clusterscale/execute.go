@@ -0,0 +1,407 @@
+package clusterscale
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/clusterscale/target"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/pkg/errors"
+)
+
+// drainPollInterval is the interval at which the drains of nodes being removed are checked.
+const drainPollInterval = 10 * time.Second
+
+// actionTracker records the node classes which have a scale in in progress, so that further
+// actions are not taken until the nodes have been removed.
+type actionTracker struct {
+	lock   sync.Mutex
+	active map[string]bool
+}
+
+func newActionTracker() *actionTracker {
+	return &actionTracker{active: make(map[string]bool)}
+}
+
+// start marks the class as having an action in progress, returning false if one already is.
+func (at *actionTracker) start(class string) bool {
+	at.lock.Lock()
+	defer at.lock.Unlock()
+
+	if at.active[class] {
+		return false
+	}
+	at.active[class] = true
+	return true
+}
+
+func (at *actionTracker) finish(class string) {
+	at.lock.Lock()
+	delete(at.active, class)
+	at.lock.Unlock()
+}
+
+func (at *actionTracker) inProgress(class string) bool {
+	at.lock.Lock()
+	defer at.lock.Unlock()
+	return at.active[class]
+}
+
+// execute performs the scaling decision using the node target provider. Scale out requests the
+// new nodes from the provider, while scale in is performed asynchronously as the selected nodes
+// must be drained before their instances are terminated. When soft scale in is enabled, scale out
+// first returns parked nodes to service, and scale in parks the selected nodes instead.
+func (s *Scaler) execute(ctx context.Context, dec *ClassDecision) {
+	log := s.logger.With().Str("node-class", dec.Class).Str("node-target-provider", s.targetName()).Logger()
+
+	switch dec.Direction {
+	case scale.DirectionOut:
+		count := dec.TargetNodes - dec.Nodes
+
+		if s.softScaleIn {
+			resumed, err := s.unparkNodes(ctx, dec.Class, count)
+			if resumed > 0 {
+				s.decider.actioned(dec.Class, scale.DirectionOut, dec.Time)
+				log.Info().Int("count", resumed).Msg("returned parked nodes of node class to service")
+			}
+			if err != nil {
+				log.Error().Err(err).Msg("failed to return parked nodes of node class to service")
+				return
+			}
+
+			count -= resumed
+			if count == 0 {
+				return
+			}
+			if s.target == nil {
+				log.Warn().Int("count", count).Msg("node class has no parked nodes left to return to service")
+				return
+			}
+		}
+
+		if err := s.target.ScaleOut(ctx, dec.Class, count); err != nil {
+			log.Error().Err(err).Msg("failed to scale out node class")
+			return
+		}
+		s.decider.actioned(dec.Class, scale.DirectionOut, dec.Time)
+		log.Info().Int("count", count).Msg("scaled out node class")
+
+	case scale.DirectionIn:
+		preview, err := s.PreviewScaleIn(ctx, dec.Class, dec.Nodes-dec.TargetNodes)
+		if err != nil {
+			log.Error().Err(err).Msg("failed to select nodes to scale in")
+			return
+		}
+		if len(preview.Selected) == 0 {
+			log.Debug().Msg("no nodes of the class can be removed")
+			return
+		}
+		if !s.actions.start(dec.Class) {
+			return
+		}
+		s.decider.actioned(dec.Class, scale.DirectionIn, dec.Time)
+
+		go func() {
+			defer s.actions.finish(dec.Class)
+			if s.softScaleIn {
+				s.parkNodes(ctx, dec.Class, preview.Selected)
+				return
+			}
+			s.scaleIn(ctx, dec.Class, preview.Selected)
+		}()
+	}
+}
+
+// targetName returns the name of the node target provider, which is none if soft scale in is
+// being used without a provider.
+func (s *Scaler) targetName() string {
+	if s.target == nil {
+		return "none"
+	}
+	return s.target.Name()
+}
+
+// scaleIn drains the nodes, and terminates the instances of those whose drain completes. Nodes
+// which fail to drain are left ineligible for scheduling for an operator to investigate.
+func (s *Scaler) scaleIn(ctx context.Context, class string, nodeIDs []string) {
+	log := s.logger.With().Str("node-class", class).Str("node-target-provider", s.target.Name()).Logger()
+
+	instances := make(map[string]string, len(nodeIDs))
+
+	for _, id := range nodeIDs {
+		instanceID, err := s.drainNode(ctx, id)
+		if err != nil {
+			log.Error().Err(err).Str("node-id", id).Msg("failed to drain node for scale in")
+			continue
+		}
+		instances[id] = instanceID
+	}
+
+	var terminate []string
+
+	for id, instanceID := range instances {
+		if err := s.waitForDrain(ctx, id); err != nil {
+			log.Error().Err(err).Str("node-id", id).Msg("node drain did not complete, not terminating its instance")
+			continue
+		}
+		terminate = append(terminate, instanceID)
+	}
+	if len(terminate) == 0 {
+		return
+	}
+
+	if err := s.target.Terminate(ctx, class, terminate); err != nil {
+		log.Error().Err(err).Strs("instance-ids", terminate).Msg("failed to terminate instances of drained nodes")
+		return
+	}
+	log.Info().Strs("instance-ids", terminate).Msg("scaled in node class")
+}
+
+// drainNode identifies the instance running the node using the provider, and then drains the
+// node using the configured deadline, returning the instance ID.
+func (s *Scaler) drainNode(ctx context.Context, nodeID string) (string, error) {
+	var info *nomad.Node
+
+	err := s.call(ctx, func() (err error) {
+		info, _, err = s.nomad.Client().Nodes().Info(nodeID, nil)
+		return err
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read Nomad node")
+	}
+
+	instanceID, err := s.target.InstanceID(ctx, &target.Node{
+		ID:         info.ID,
+		Name:       info.Name,
+		NodeClass:  info.NodeClass,
+		Attributes: info.Attributes,
+	})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to identify instance of node")
+	}
+
+	if err := s.drain(ctx, nodeID); err != nil {
+		return "", err
+	}
+	return instanceID, nil
+}
+
+// drain starts the drain of the node using the configured deadline. Nomad marks the node
+// ineligible for scheduling, and it remains so once the drain completes.
+func (s *Scaler) drain(ctx context.Context, nodeID string) error {
+	err := s.call(ctx, func() error {
+		_, err := s.nomad.Client().Nodes().UpdateDrain(nodeID, &nomad.DrainSpec{Deadline: s.drainDeadline}, false, nil)
+		return err
+	})
+	if err != nil {
+		return errors.Wrap(err, "failed to drain Nomad node")
+	}
+	return nil
+}
+
+// parkNodes drains the nodes, leaving them ineligible for scheduling once their drain completes
+// so that they can be returned to service by a later scale out. Their instances are not
+// terminated.
+func (s *Scaler) parkNodes(ctx context.Context, class string, nodeIDs []string) {
+	log := s.logger.With().Str("node-class", class).Logger()
+
+	var draining, parked []string
+
+	for _, id := range nodeIDs {
+		if err := s.drain(ctx, id); err != nil {
+			log.Error().Err(err).Str("node-id", id).Msg("failed to drain node for soft scale in")
+			continue
+		}
+		draining = append(draining, id)
+	}
+
+	for _, id := range draining {
+		if err := s.waitForDrain(ctx, id); err != nil {
+			log.Error().Err(err).Str("node-id", id).Msg("node drain did not complete for soft scale in")
+			continue
+		}
+		parked = append(parked, id)
+	}
+	if len(parked) == 0 {
+		return
+	}
+
+	if err := s.updateParked(parked, nil); err != nil {
+		log.Error().Err(err).Strs("node-ids", parked).Msg("failed to record parked nodes, they will not be returned to service")
+		return
+	}
+	log.Info().Strs("node-ids", parked).Msg("parked nodes of node class")
+}
+
+// unparkNodes makes up to count parked nodes of the class eligible for scheduling, ordered by
+// name, and returns the number of nodes returned to service.
+func (s *Scaler) unparkNodes(ctx context.Context, class string, count int) (int, error) {
+	parked, err := s.parkedNodes(ctx, class)
+	if err != nil {
+		return 0, err
+	}
+	sort.Slice(parked, func(i, j int) bool { return parked[i].Name < parked[j].Name })
+
+	var resumed []string
+
+	for _, n := range parked {
+		if len(resumed) == count {
+			break
+		}
+
+		err = s.call(ctx, func() error {
+			_, err := s.nomad.Client().Nodes().ToggleEligibility(n.ID, true, nil)
+			return err
+		})
+		if err != nil {
+			err = errors.Wrapf(err, "failed to mark Nomad node %s eligible", n.ID)
+			break
+		}
+		resumed = append(resumed, n.ID)
+	}
+
+	if len(resumed) > 0 {
+		if updateErr := s.updateParked(nil, resumed); updateErr != nil && err == nil {
+			err = updateErr
+		}
+	}
+	return len(resumed), err
+}
+
+// parkedNodes lists the parked nodes of the class, or of all classes if the class is empty. Only
+// nodes recorded as parked by soft scale in are included, and nodes of instances which have
+// received a spot interruption are skipped. Records of nodes which have left the cluster, or have
+// been made eligible by an operator, are removed.
+func (s *Scaler) parkedNodes(ctx context.Context, class string) ([]*nomad.NodeListStub, error) {
+	ids, err := s.getParked()
+	if err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var stubs []*nomad.NodeListStub
+
+	err = s.call(ctx, func() (err error) {
+		stubs, _, err = s.nomad.Client().Nodes().List(nil)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list Nomad nodes")
+	}
+
+	recorded := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		recorded[id] = true
+	}
+
+	var (
+		out   []*nomad.NodeListStub
+		stale []string
+	)
+
+	for _, stub := range stubs {
+		if !recorded[stub.ID] {
+			continue
+		}
+		delete(recorded, stub.ID)
+
+		if stub.SchedulingEligibility == nomad.NodeSchedulingEligible {
+			stale = append(stale, stub.ID)
+			continue
+		}
+		if nodeParked(stub, class) && !s.interruptions.hasNode(stub.ID) {
+			out = append(out, stub)
+		}
+	}
+	for id := range recorded {
+		stale = append(stale, id)
+	}
+
+	if len(stale) > 0 {
+		if err := s.updateParked(nil, stale); err != nil {
+			s.logger.Warn().Err(err).Strs("node-ids", stale).Msg("failed to remove stale parked node records")
+		}
+	}
+	return out, nil
+}
+
+// getParked returns the IDs of the nodes recorded as parked.
+func (s *Scaler) getParked() ([]string, error) {
+	s.parkedLock.Lock()
+	defer s.parkedLock.Unlock()
+
+	ids, err := s.parked.GetParkedNodes()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read parked nodes")
+	}
+	return ids, nil
+}
+
+// updateParked adds the nodes to, and removes the nodes from, the parked node records.
+func (s *Scaler) updateParked(add, remove []string) error {
+	s.parkedLock.Lock()
+	defer s.parkedLock.Unlock()
+
+	ids, err := s.parked.GetParkedNodes()
+	if err != nil {
+		return errors.Wrap(err, "failed to read parked nodes")
+	}
+
+	set := make(map[string]bool, len(ids)+len(add))
+	for _, id := range ids {
+		set[id] = true
+	}
+	for _, id := range add {
+		set[id] = true
+	}
+	for _, id := range remove {
+		delete(set, id)
+	}
+
+	out := make([]string, 0, len(set))
+	for id := range set {
+		out = append(out, id)
+	}
+	sort.Strings(out)
+
+	if err := s.parked.PutParkedNodes(out); err != nil {
+		return errors.Wrap(err, "failed to write parked nodes")
+	}
+	return nil
+}
+
+// waitForDrain blocks until the drain of the node has completed. Nomad forces the drain to
+// complete at its deadline, so the wait is bounded shortly after it.
+func (s *Scaler) waitForDrain(ctx context.Context, nodeID string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.drainDeadline+2*drainPollInterval)
+	defer cancel()
+
+	t := time.NewTicker(drainPollInterval)
+	defer t.Stop()
+
+	for {
+		var info *nomad.Node
+
+		err := s.call(ctx, func() (err error) {
+			info, _, err = s.nomad.Client().Nodes().Info(nodeID, nil)
+			return err
+		})
+		if err == nil && info.DrainStrategy == nil {
+			return nil
+		}
+
+		select {
+		case <-t.C:
+		case <-ctx.Done():
+			if err != nil {
+				return errors.Wrap(err, "failed to read Nomad node")
+			}
+			return errors.New("timed out waiting for node drain")
+		}
+	}
+}
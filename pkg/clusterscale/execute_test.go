@@ -0,0 +1,202 @@
+package clusterscale
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/clusterscale/target"
+	"github.com/jrasell/sherpa/pkg/scale"
+	clusterMemory "github.com/jrasell/sherpa/pkg/state/cluster/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeTarget struct {
+	class string
+	count int
+	err   error
+}
+
+func (f *fakeTarget) Name() string { return "fake" }
+
+func (f *fakeTarget) ScaleOut(_ context.Context, class string, count int) error {
+	f.class, f.count = class, count
+	return f.err
+}
+
+func (f *fakeTarget) InstanceID(_ context.Context, node *target.Node) (string, error) {
+	return node.ID, nil
+}
+
+func (f *fakeTarget) Terminate(_ context.Context, _ string, _ []string) error { return f.err }
+
+func TestScaler_executeScaleOut(t *testing.T) {
+	now := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+	dec := &ClassDecision{Class: "batch", Direction: scale.DirectionOut, Nodes: 3, TargetNodes: 5, Time: now}
+
+	failing := &fakeTarget{err: errors.New("quota exceeded")}
+	s := NewScaler(&Config{Target: failing, ScaleOutCooldown: 5 * time.Minute})
+	s.execute(context.Background(), dec)
+	assert.Equal(t, 2, failing.count)
+	assert.Nil(t, s.decider.history["batch"])
+
+	ft := &fakeTarget{}
+	s = NewScaler(&Config{Target: ft, ScaleOutCooldown: 5 * time.Minute})
+	s.execute(context.Background(), dec)
+	assert.Equal(t, "batch", ft.class)
+	assert.Equal(t, 2, ft.count)
+
+	// The scale out cooldown starts once the nodes are requested, before they join the cluster.
+	next := s.decider.decide(&ClassStatus{Class: "batch", Nodes: 3, DesiredNodes: 5}, now.Add(time.Minute))
+	assert.Equal(t, scale.DirectionNone, next.Direction)
+	assert.Equal(t, "scale out cooldown active until 2020-01-26T10:05:00Z", next.Reason)
+}
+
+// fakeNomadNodes is a Nomad node API which records the drain and eligibility updates of nodes.
+// Drains complete as soon as they are started.
+type fakeNomadNodes struct {
+	lock  sync.Mutex
+	nodes []*nomad.NodeListStub
+	calls []string
+}
+
+func newFakeNomadNodes(t *testing.T, nodes []*nomad.NodeListStub) (*fakeNomadNodes, *client.NomadPool, func()) {
+	f := &fakeNomadNodes{nodes: nodes}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		switch {
+		case r.URL.Path == "/v1/nodes":
+			_ = json.NewEncoder(w).Encode(f.nodes)
+
+		case strings.HasSuffix(r.URL.Path, "/drain"), strings.HasSuffix(r.URL.Path, "/eligibility"):
+			parts := strings.Split(r.URL.Path, "/")
+			f.calls = append(f.calls, parts[3]+" "+parts[4])
+			_, _ = w.Write([]byte(`{}`))
+
+		case strings.HasPrefix(r.URL.Path, "/v1/node/"):
+			_, _ = w.Write([]byte(`{"ID": "` + strings.TrimPrefix(r.URL.Path, "/v1/node/") + `"}`))
+
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	pool, err := client.NewNomadPool(zerolog.Nop(), []string{srv.URL}, 0)
+	assert.Nil(t, err)
+	return f, pool, srv.Close
+}
+
+func TestScaler_executeSoftScaleOut(t *testing.T) {
+	parked := func(id string) *nomad.NodeListStub {
+		return &nomad.NodeListStub{ID: id, Name: id, NodeClass: "batch", Status: nomad.NodeStatusReady,
+			SchedulingEligibility: nomad.NodeSchedulingIneligible}
+	}
+
+	fake, pool, stop := newFakeNomadNodes(t, []*nomad.NodeListStub{
+		parked("batch-3"), parked("batch-1"), parked("batch-2"),
+		{ID: "web-1", NodeClass: "web", Status: nomad.NodeStatusReady, SchedulingEligibility: nomad.NodeSchedulingIneligible},
+	})
+	defer stop()
+
+	now := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+	store := clusterMemory.NewStateBackend()
+	assert.Nil(t, store.PutParkedNodes([]string{"batch-1", "batch-2", "batch-3", "web-1"}))
+
+	// Parked nodes are returned to service in name order, and no target is needed.
+	s := NewScaler(&Config{Nomad: pool, Logger: zerolog.Nop(), SoftScaleIn: true, ParkedNodes: store, ScaleOutCooldown: 5 * time.Minute})
+	s.execute(context.Background(), &ClassDecision{Class: "batch", Direction: scale.DirectionOut, Nodes: 2, TargetNodes: 4, Time: now})
+	assert.Equal(t, []string{"batch-1 eligibility", "batch-2 eligibility"}, fake.calls)
+	assert.Equal(t, now, s.decider.history["batch"].lastScaleOut)
+
+	ids, err := store.GetParkedNodes()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"batch-3", "web-1"}, ids)
+
+	// Nodes which cannot be returned to service are requested from the target.
+	fake.calls = nil
+	ft := &fakeTarget{}
+	s = NewScaler(&Config{Nomad: pool, Logger: zerolog.Nop(), SoftScaleIn: true, ParkedNodes: store, Target: ft})
+	s.execute(context.Background(), &ClassDecision{Class: "batch", Direction: scale.DirectionOut, Nodes: 2, TargetNodes: 7, Time: now})
+	assert.Equal(t, []string{"batch-3 eligibility"}, fake.calls)
+	assert.Equal(t, 4, ft.count)
+}
+
+func TestScaler_parkedNodes(t *testing.T) {
+	node := func(id, eligibility string, drain bool) *nomad.NodeListStub {
+		return &nomad.NodeListStub{ID: id, Name: id, NodeClass: "batch", Status: nomad.NodeStatusReady,
+			SchedulingEligibility: eligibility, Drain: drain}
+	}
+
+	_, pool, stop := newFakeNomadNodes(t, []*nomad.NodeListStub{
+		node("parked", nomad.NodeSchedulingIneligible, false),
+		node("cordoned", nomad.NodeSchedulingIneligible, false),
+		node("failed-drain", nomad.NodeSchedulingIneligible, false),
+		node("interrupted", nomad.NodeSchedulingIneligible, false),
+		node("draining", nomad.NodeSchedulingIneligible, true),
+		node("resumed", nomad.NodeSchedulingEligible, false),
+	})
+	defer stop()
+
+	store := clusterMemory.NewStateBackend()
+	assert.Nil(t, store.PutParkedNodes([]string{"draining", "gone", "interrupted", "parked", "resumed"}))
+
+	s := NewScaler(&Config{Nomad: pool, Logger: zerolog.Nop(), SoftScaleIn: true, ParkedNodes: store})
+	s.interruptions.record(&Interruption{InstanceID: "i-1", NodeID: "interrupted", Drained: true}, time.Now())
+
+	// Ineligible nodes which were not parked by the scaler, such as those cordoned by an operator
+	// or whose drain failed, and interrupted nodes are not treated as parked.
+	parked, err := s.parkedNodes(context.Background(), "batch")
+	assert.Nil(t, err)
+	if assert.Len(t, parked, 1) {
+		assert.Equal(t, "parked", parked[0].ID)
+	}
+
+	// Records of nodes which left the cluster or were made eligible are removed.
+	ids, err := store.GetParkedNodes()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"draining", "interrupted", "parked"}, ids)
+}
+
+func TestScaler_parkNodes(t *testing.T) {
+	fake, pool, stop := newFakeNomadNodes(t, nil)
+	defer stop()
+
+	store := clusterMemory.NewStateBackend()
+	assert.Nil(t, store.PutParkedNodes([]string{"batch-0"}))
+
+	s := NewScaler(&Config{Nomad: pool, Logger: zerolog.Nop(), SoftScaleIn: true, ParkedNodes: store, DrainDeadline: time.Minute})
+	s.parkNodes(context.Background(), "batch", []string{"batch-1", "batch-2"})
+
+	// The nodes are drained, and are neither made eligible again nor terminated.
+	assert.Equal(t, []string{"batch-1 drain", "batch-2 drain"}, fake.calls)
+
+	// The nodes are recorded as parked so that they can be returned to service.
+	ids, err := store.GetParkedNodes()
+	assert.Nil(t, err)
+	assert.Equal(t, []string{"batch-0", "batch-1", "batch-2"}, ids)
+}
+
+func Test_actionTracker(t *testing.T) {
+	at := newActionTracker()
+
+	assert.True(t, at.start("batch"))
+	assert.False(t, at.start("batch"))
+	assert.True(t, at.inProgress("batch"))
+	assert.False(t, at.inProgress("web"))
+
+	at.finish("batch")
+	assert.False(t, at.inProgress("batch"))
+	assert.True(t, at.start("batch"))
+}
@@ -0,0 +1,277 @@
+package clusterscale
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+	"sync"
+	"time"
+
+	nomad "github.com/hashicorp/nomad/api"
+	serverCfg "github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/pkg/errors"
+)
+
+const (
+	// spotInterruptionNotice is the time between AWS issuing a spot interruption warning and the
+	// instance being interrupted.
+	spotInterruptionNotice = 2 * time.Minute
+
+	// interruptionRetention is the time after its deadline that an interruption is retained, so
+	// that it can be inspected using the API.
+	interruptionRetention = time.Hour
+
+	// eventDetailTypeSpotInterruption is the EventBridge detail type of spot interruption warnings.
+	eventDetailTypeSpotInterruption = "EC2 Spot Instance Interruption Warning"
+
+	// attrAWSInstanceID is the Nomad node attribute which holds the EC2 instance ID of the node.
+	attrAWSInstanceID = "unique.platform.aws.instance-id"
+)
+
+// ErrInterruptionNodeNotFound is returned when an interrupted instance is not a Nomad client of
+// the cluster.
+var ErrInterruptionNodeNotFound = errors.New("interrupted instance is not a Nomad client node")
+
+// Interruption is a notice that the cloud provider will reclaim a spot instance which is running a
+// Nomad client, and the action taken in response.
+type Interruption struct {
+	// InstanceID is the ID of the interrupted instance, and Action is whether it will be
+	// terminated, stopped or hibernated.
+	InstanceID string
+	Action     string
+
+	// NodeID and NodeClass identify the Nomad client running on the instance.
+	NodeID    string
+	NodeClass string
+
+	// NoticeTime is when the interruption notice was issued, and Deadline is when the instance
+	// will be interrupted.
+	NoticeTime time.Time
+	Deadline   time.Time
+
+	// Drained is whether the node was successfully marked for draining, and Error details why it
+	// was not.
+	Drained bool
+	Error   string `json:",omitempty"`
+}
+
+// spotInterruptionEvent is an AWS EventBridge EC2 Spot Instance Interruption Warning event.
+type spotInterruptionEvent struct {
+	DetailType string    `json:"detail-type"`
+	Time       time.Time `json:"time"`
+	Detail     struct {
+		InstanceID     string `json:"instance-id"`
+		InstanceAction string `json:"instance-action"`
+	} `json:"detail"`
+}
+
+// ParseSpotInterruption parses an AWS EventBridge EC2 Spot Instance Interruption Warning event.
+func ParseSpotInterruption(data []byte) (*Interruption, error) {
+	var event spotInterruptionEvent
+
+	if err := json.Unmarshal(data, &event); err != nil {
+		return nil, errors.Wrap(err, "failed to decode interruption event")
+	}
+	if event.DetailType != eventDetailTypeSpotInterruption {
+		return nil, errors.Errorf("unsupported event detail type %q", event.DetailType)
+	}
+	if event.Detail.InstanceID == "" {
+		return nil, errors.New("interruption event does not include an instance ID")
+	}
+	if event.Time.IsZero() {
+		return nil, errors.New("interruption event does not include a time")
+	}
+
+	return &Interruption{
+		InstanceID: event.Detail.InstanceID,
+		Action:     event.Detail.InstanceAction,
+		NoticeTime: event.Time.UTC(),
+		Deadline:   event.Time.UTC().Add(spotInterruptionNotice),
+	}, nil
+}
+
+// interruptionTracker records the interruptions received, keyed by the instance ID.
+type interruptionTracker struct {
+	lock      sync.Mutex
+	instances map[string]*Interruption
+}
+
+func newInterruptionTracker() *interruptionTracker {
+	return &interruptionTracker{instances: make(map[string]*Interruption)}
+}
+
+// get returns a copy of the interruption of the instance, or nil if there is none.
+func (it *interruptionTracker) get(instanceID string) *Interruption {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+
+	in, ok := it.instances[instanceID]
+	if !ok {
+		return nil
+	}
+	c := *in
+	return &c
+}
+
+// record stores the interruption, removing interruptions which are past their retention.
+func (it *interruptionTracker) record(in *Interruption, now time.Time) {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+
+	for id, existing := range it.instances {
+		if now.Sub(existing.Deadline) > interruptionRetention {
+			delete(it.instances, id)
+		}
+	}
+	c := *in
+	it.instances[in.InstanceID] = &c
+}
+
+// hasNode returns whether an interruption has been received for the instance running the node.
+func (it *interruptionTracker) hasNode(nodeID string) bool {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+
+	for _, in := range it.instances {
+		if in.NodeID == nodeID {
+			return true
+		}
+	}
+	return false
+}
+
+// list returns a copy of the tracked interruptions, sorted by notice time and instance ID.
+func (it *interruptionTracker) list() []*Interruption {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+
+	out := make([]*Interruption, 0, len(it.instances))
+	for _, in := range it.instances {
+		c := *in
+		out = append(out, &c)
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].NoticeTime.Equal(out[j].NoticeTime) {
+			return out[i].NoticeTime.Before(out[j].NoticeTime)
+		}
+		return out[i].InstanceID < out[j].InstanceID
+	})
+	return out
+}
+
+// pending returns the number of drained nodes of each class whose interruption deadline has not
+// passed.
+func (it *interruptionTracker) pending(now time.Time) map[string]int {
+	it.lock.Lock()
+	defer it.lock.Unlock()
+
+	out := make(map[string]int)
+	for _, in := range it.instances {
+		if in.Drained && now.Before(in.Deadline) {
+			out[in.NodeClass]++
+		}
+	}
+	return out
+}
+
+// Interruptions returns the spot interruptions received, sorted by notice time.
+func (s *Scaler) Interruptions() []*Interruption { return s.interruptions.list() }
+
+// HandleInterruption drains the Nomad client running on the interrupted instance, so that its
+// allocations are migrated before the instance is reclaimed. System jobs are left running until
+// the deadline. Repeated notices for an instance which has been drained are ignored.
+func (s *Scaler) HandleInterruption(ctx context.Context, in *Interruption) (*Interruption, error) {
+	return s.handleInterruption(ctx, in, time.Now().UTC())
+}
+
+func (s *Scaler) handleInterruption(ctx context.Context, in *Interruption, now time.Time) (*Interruption, error) {
+	if existing := s.interruptions.get(in.InstanceID); existing != nil && existing.Drained {
+		return existing, nil
+	}
+
+	out := *in
+	defer func() { s.interruptions.record(&out, now) }()
+
+	node, err := s.instanceNode(ctx, in.InstanceID)
+	if err != nil {
+		out.Error = err.Error()
+		return &out, err
+	}
+	out.NodeID, out.NodeClass = node.ID, node.NodeClass
+
+	err = s.call(ctx, func() error {
+		_, err := s.nomad.Client().Nodes().UpdateDrain(node.ID, &nomad.DrainSpec{
+			Deadline:         drainDeadline(in.Deadline, now),
+			IgnoreSystemJobs: true,
+		}, false, nil)
+		return err
+	})
+	if err != nil {
+		out.Error = err.Error()
+		return &out, errors.Wrapf(err, "failed to drain Nomad node %s", node.ID)
+	}
+	out.Drained = true
+
+	s.logger.Warn().
+		Str("instance-id", in.InstanceID).
+		Str("node-id", node.ID).
+		Str("node-class", node.NodeClass).
+		Time("deadline", in.Deadline).
+		Msg("draining Nomad node of interrupted spot instance")
+	return &out, nil
+}
+
+// instanceNode returns the Nomad node running on the instance.
+func (s *Scaler) instanceNode(ctx context.Context, instanceID string) (*nomad.Node, error) {
+	var stubs []*nomad.NodeListStub
+
+	err := s.call(ctx, func() (err error) {
+		stubs, _, err = s.nomad.Client().Nodes().List(nil)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list Nomad nodes")
+	}
+
+	for _, stub := range stubs {
+		if stub.Status == nomad.NodeStatusDown {
+			continue
+		}
+
+		var info *nomad.Node
+
+		err := s.call(ctx, func() (err error) {
+			info, _, err = s.nomad.Client().Nodes().Info(stub.ID, nil)
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read Nomad node %s", stub.ID)
+		}
+		if info.Attributes[attrAWSInstanceID] == instanceID {
+			return info, nil
+		}
+	}
+	return nil, ErrInterruptionNodeNotFound
+}
+
+// drainDeadline returns the Nomad drain deadline which stops the remaining allocations of the node
+// by the interruption deadline. A negative deadline forces the drain to complete immediately.
+func drainDeadline(deadline, now time.Time) time.Duration {
+	if d := deadline.Sub(now); d > 0 {
+		return d
+	}
+	return -1
+}
+
+// withReplacements raises the desired node count of a class to the required count, which
+// includes the nodes being drained due to an interruption, so that replacements are launched
+// before the interrupted nodes are reclaimed. The count remains bounded by the class maximum.
+func withReplacements(desired, required int, pol *serverCfg.NodeClassPolicy) int {
+	if required <= desired {
+		return desired
+	}
+	if pol != nil && pol.MaxNodes > 0 && required > pol.MaxNodes {
+		return pol.MaxNodes
+	}
+	return required
+}
@@ -0,0 +1,78 @@
+package clusterscale
+
+import (
+	"testing"
+	"time"
+
+	serverCfg "github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSpotInterruption(t *testing.T) {
+	testCases := []struct {
+		name        string
+		data        string
+		expected    *Interruption
+		expectError bool
+	}{
+		{
+			name: "interruption warning",
+			data: `{"version":"0","detail-type":"EC2 Spot Instance Interruption Warning","source":"aws.ec2",
+				"time":"2020-01-26T10:00:00Z","detail":{"instance-id":"i-1234567890abcdef0","instance-action":"terminate"}}`,
+			expected: &Interruption{
+				InstanceID: "i-1234567890abcdef0",
+				Action:     "terminate",
+				NoticeTime: time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC),
+				Deadline:   time.Date(2020, 1, 26, 10, 2, 0, 0, time.UTC),
+			},
+		},
+		{name: "other event", data: `{"detail-type":"EC2 Instance Rebalance Recommendation","time":"2020-01-26T10:00:00Z"}`, expectError: true},
+		{name: "missing instance", data: `{"detail-type":"EC2 Spot Instance Interruption Warning","time":"2020-01-26T10:00:00Z"}`, expectError: true},
+		{name: "missing time", data: `{"detail-type":"EC2 Spot Instance Interruption Warning","detail":{"instance-id":"i-1"}}`, expectError: true},
+		{name: "invalid json", data: `{`, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		actual, err := ParseSpotInterruption([]byte(tc.data))
+		if tc.expectError {
+			assert.NotNil(t, err, tc.name)
+			continue
+		}
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.expected, actual, tc.name)
+	}
+}
+
+func Test_interruptionTracker(t *testing.T) {
+	it := newInterruptionTracker()
+	now := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+
+	it.record(&Interruption{InstanceID: "i-2", NodeClass: "batch", NoticeTime: now, Deadline: now.Add(2 * time.Minute), Drained: true}, now)
+	it.record(&Interruption{InstanceID: "i-1", NodeClass: "batch", NoticeTime: now, Deadline: now.Add(2 * time.Minute), Drained: true}, now)
+	it.record(&Interruption{InstanceID: "i-3", NodeClass: "web", NoticeTime: now, Deadline: now.Add(2 * time.Minute), Error: "not found"}, now)
+
+	assert.Equal(t, map[string]int{"batch": 2}, it.pending(now.Add(time.Minute)))
+	assert.Equal(t, map[string]int{}, it.pending(now.Add(2*time.Minute)))
+
+	list := it.list()
+	assert.Len(t, list, 3)
+	assert.Equal(t, "i-1", list[0].InstanceID)
+
+	// Interruptions past their retention are removed when the next is recorded.
+	later := now.Add(2 * time.Hour)
+	it.record(&Interruption{InstanceID: "i-4", NoticeTime: later, Deadline: later.Add(2 * time.Minute)}, later)
+	assert.Len(t, it.list(), 1)
+	assert.Nil(t, it.get("i-1"))
+}
+
+func Test_drainDeadline(t *testing.T) {
+	now := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+	assert.Equal(t, 90*time.Second, drainDeadline(now.Add(90*time.Second), now))
+	assert.Equal(t, time.Duration(-1), drainDeadline(now.Add(-time.Second), now))
+}
+
+func Test_withReplacements(t *testing.T) {
+	assert.Equal(t, 5, withReplacements(5, 4, nil))
+	assert.Equal(t, 6, withReplacements(4, 6, nil))
+	assert.Equal(t, 5, withReplacements(4, 6, &serverCfg.NodeClassPolicy{MaxNodes: 5}))
+}
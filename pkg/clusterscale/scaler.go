@@ -0,0 +1,520 @@
+package clusterscale
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/clusterscale/target"
+	serverCfg "github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// Config is the configuration of the cluster scaler.
+type Config struct {
+	Logger       zerolog.Logger
+	Nomad        *client.NomadPool
+	NomadTimeout time.Duration
+
+	// Weights are used to score the nodes which are candidates for removal during scale in.
+	Weights ScoreWeights
+
+	// Classes are the policies of the node classes. Classes without a policy are scaled without
+	// node count bounds or a utilization target.
+	Classes []*serverCfg.NodeClassPolicy
+
+	// ExcludedJobs are the IDs of jobs whose nodes, of any class, are never selected for removal.
+	ExcludedJobs []string
+
+	// EvaluationInterval is the time between evaluations of the node classes.
+	EvaluationInterval time.Duration
+
+	// ScaleOutCooldown and ScaleInCooldown are the times after a scaling action during which a
+	// class is not scaled out, or in, again. StabilizationWindow is the time over which the
+	// highest desired node count of a class is used for scale in decisions.
+	ScaleOutCooldown    time.Duration
+	ScaleInCooldown     time.Duration
+	StabilizationWindow time.Duration
+
+	// ReplaceInterrupted raises the desired node count of a class by the number of its nodes being
+	// drained due to a spot interruption, until the interruption deadline.
+	ReplaceInterrupted bool
+
+	// Target is the provider used to add and remove the nodes of each class. If nil, scaling
+	// decisions are made but not performed.
+	Target target.Provider
+
+	// DrainDeadline is the deadline of the drain of nodes selected for scale in, after which
+	// their remaining allocations are stopped.
+	DrainDeadline time.Duration
+
+	// SoftScaleIn parks the nodes selected for scale in, leaving them drained and ineligible for
+	// scheduling rather than terminating them. Scale out makes parked nodes eligible again before
+	// requesting new nodes from the target, which is not required in this mode.
+	SoftScaleIn bool
+
+	// ParkedNodes stores the IDs of the nodes parked by soft scale in, and must be set when it is
+	// enabled. Only the recorded nodes are returned to service, so that nodes made ineligible by
+	// an operator, or left ineligible by a failed drain, are not made eligible by the scaler.
+	ParkedNodes ParkedNodeStore
+}
+
+// ParkedNodeStore stores the IDs of the nodes parked by soft scale in. It is satisfied by the
+// cluster state backend, so that the parked nodes are known to the next leader.
+type ParkedNodeStore interface {
+	GetParkedNodes() ([]string, error)
+	PutParkedNodes(ids []string) error
+}
+
+// Scaler selects the Nomad client nodes to act on when scaling the cluster. Each node class is
+// scaled independently, using its own policy.
+type Scaler struct {
+	logger  zerolog.Logger
+	nomad   *client.NomadPool
+	weights ScoreWeights
+	timeout time.Duration
+
+	classes  map[string]*serverCfg.NodeClassPolicy
+	excluded map[string]bool
+
+	interval time.Duration
+	decider  *decider
+
+	interruptions      *interruptionTracker
+	replaceInterrupted bool
+
+	target        target.Provider
+	drainDeadline time.Duration
+	softScaleIn   bool
+	actions       *actionTracker
+
+	parked     ParkedNodeStore
+	parkedLock sync.Mutex
+}
+
+// Preview details the nodes which would be removed by a scale in, without performing it.
+type Preview struct {
+	// Count is the number of nodes requested to be removed, and Selected are the IDs of the nodes
+	// which would be removed. Fewer nodes than requested are selected if not enough are eligible,
+	// or if removing them would take their class below its minimum node count.
+	Count    int
+	Selected []string
+
+	// Candidates are all the eligible nodes, ordered from the best to the worst candidate.
+	Candidates []*Candidate
+}
+
+// ClassStatus is the current state of the eligible nodes of a node class.
+type ClassStatus struct {
+	Class string
+
+	// Nodes is the number of eligible nodes of the class, and Utilization is the mean utilization
+	// of those nodes.
+	Nodes       int
+	Utilization float64
+
+	// DesiredNodes is the node count which brings the class utilization to its target, bounded by
+	// the class node count limits. It is equal to Nodes if the class has no target.
+	DesiredNodes int
+
+	// Interrupted is the number of nodes of the class which are being drained due to a spot
+	// interruption whose deadline has not passed. These nodes are not included in Nodes.
+	Interrupted int
+
+	// Parked is the number of nodes of the class which were parked by soft scale in and can be
+	// returned to service on scale out. It is only set when soft scale in is enabled.
+	Parked int `json:",omitempty"`
+
+	// Policy is the policy of the class, which is nil if the class does not have one.
+	Policy *serverCfg.NodeClassPolicy
+}
+
+// NewScaler returns a cluster scaler using the passed config.
+func NewScaler(cfg *Config) *Scaler {
+	s := Scaler{
+		logger:   cfg.Logger,
+		nomad:    cfg.Nomad,
+		weights:  cfg.Weights,
+		timeout:  cfg.NomadTimeout,
+		classes:  make(map[string]*serverCfg.NodeClassPolicy, len(cfg.Classes)),
+		excluded: make(map[string]bool, len(cfg.ExcludedJobs)),
+		interval: cfg.EvaluationInterval,
+		decider:  newDecider(cfg.ScaleOutCooldown, cfg.ScaleInCooldown, cfg.StabilizationWindow),
+
+		interruptions:      newInterruptionTracker(),
+		replaceInterrupted: cfg.ReplaceInterrupted,
+
+		target:        cfg.Target,
+		drainDeadline: cfg.DrainDeadline,
+		softScaleIn:   cfg.SoftScaleIn,
+		actions:       newActionTracker(),
+		parked:        cfg.ParkedNodes,
+	}
+
+	for _, pol := range cfg.Classes {
+		s.classes[pol.Class] = pol
+	}
+	for _, job := range cfg.ExcludedJobs {
+		s.excluded[job] = true
+	}
+	return &s
+}
+
+// Run periodically evaluates the node classes until the stop channel is closed. It should only be
+// run by the cluster leader.
+func (s *Scaler) Run(stopCh <-chan struct{}) {
+	s.logger.Info().Dur("interval", s.interval).Msg("starting cluster scaler")
+
+	// The context is cancelled on stop, so that in progress scale ins are abandoned when
+	// leadership is lost.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	t := time.NewTicker(s.interval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			if err := s.evaluate(ctx, time.Now().UTC()); err != nil {
+				s.logger.Error().Err(err).Msg("failed to evaluate cluster node classes")
+			}
+		case <-stopCh:
+			s.logger.Info().Msg("shutting down cluster scaler")
+			return
+		}
+	}
+}
+
+// Decisions returns the latest scaling decision of each node class, sorted by class.
+func (s *Scaler) Decisions() []*ClassDecision { return s.decider.decisions() }
+
+// evaluate makes the scaling decision of each node class using its current status, performing
+// the decisions if a node target provider is configured. Classes with a scale in in progress are
+// not evaluated until it has completed.
+func (s *Scaler) evaluate(ctx context.Context, now time.Time) error {
+	status, err := s.ClassStatus(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, st := range status {
+		if s.actions.inProgress(st.Class) {
+			s.logger.Debug().Str("node-class", st.Class).Msg("node class scale in in progress, skipping evaluation")
+			continue
+		}
+
+		dec := s.decider.decide(st, now)
+		if dec.Direction == scale.DirectionNone {
+			if dec.Reason != "" {
+				s.logger.Debug().Str("node-class", dec.Class).Str("reason", dec.Reason).Msg("node class scaling blocked")
+			}
+			continue
+		}
+		s.logger.Info().
+			Str("node-class", dec.Class).
+			Str("direction", dec.Direction.String()).
+			Int("nodes", dec.Nodes).
+			Int("target-nodes", dec.TargetNodes).
+			Msg("node class requires scaling")
+
+		if s.target != nil || s.softScaleIn {
+			s.execute(ctx, dec)
+		}
+	}
+	return nil
+}
+
+// PreviewScaleIn scores the eligible nodes of the node class, or of all classes if the class is
+// empty, and returns the count nodes which would be selected for removal.
+func (s *Scaler) PreviewScaleIn(ctx context.Context, class string, count int) (*Preview, error) {
+	nodes, err := s.eligibleNodes(ctx, class)
+	if err != nil {
+		return nil, err
+	}
+
+	out := Preview{Count: count, Candidates: Rank(nodes, s.weights)}
+	out.Selected = s.selectScaleIn(out.Candidates, classNodeCounts(nodes), count)
+	return &out, nil
+}
+
+// ClassStatus returns the status of each node class with eligible nodes or a policy, sorted by
+// class.
+func (s *Scaler) ClassStatus(ctx context.Context) ([]*ClassStatus, error) {
+	nodes, err := s.eligibleNodes(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	byClass := make(map[string]*ClassStatus)
+
+	for class, pol := range s.classes {
+		byClass[class] = &ClassStatus{Class: class, Policy: pol}
+	}
+	for _, n := range nodes {
+		st, ok := byClass[n.NodeClass]
+		if !ok {
+			st = &ClassStatus{Class: n.NodeClass}
+			byClass[n.NodeClass] = st
+		}
+		st.Nodes++
+		st.Utilization += n.Utilization
+	}
+
+	pending := s.interruptions.pending(time.Now())
+	for class := range pending {
+		if _, ok := byClass[class]; !ok {
+			byClass[class] = &ClassStatus{Class: class}
+		}
+	}
+
+	if s.softScaleIn {
+		parked, err := s.parkedNodes(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, n := range parked {
+			st, ok := byClass[n.NodeClass]
+			if !ok {
+				st = &ClassStatus{Class: n.NodeClass}
+				byClass[n.NodeClass] = st
+			}
+			st.Parked++
+		}
+	}
+
+	out := make([]*ClassStatus, 0, len(byClass))
+
+	for _, st := range byClass {
+		if st.Nodes > 0 {
+			st.Utilization /= float64(st.Nodes)
+		}
+		st.Interrupted = pending[st.Class]
+		st.DesiredNodes = desiredNodes(st.Nodes, st.Utilization, st.Policy)
+		if s.replaceInterrupted {
+			st.DesiredNodes = withReplacements(st.DesiredNodes, st.Nodes+st.Interrupted, st.Policy)
+		}
+		out = append(out, st)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Class < out[j].Class })
+	return out, nil
+}
+
+// desiredNodes returns the node count which brings the utilization of the nodes to the target of
+// the policy, bounded by the policy node count limits.
+func desiredNodes(nodes int, util float64, pol *serverCfg.NodeClassPolicy) int {
+	if pol == nil {
+		return nodes
+	}
+
+	desired := nodes
+	if pol.TargetUtilization > 0 && nodes > 0 {
+		desired = int(math.Ceil(float64(nodes) * util / pol.TargetUtilization))
+	}
+	if desired < pol.MinNodes {
+		desired = pol.MinNodes
+	}
+	if pol.MaxNodes > 0 && desired > pol.MaxNodes {
+		desired = pol.MaxNodes
+	}
+	return desired
+}
+
+// selectScaleIn returns the IDs of up to count of the ranked candidates which can be removed.
+// Protected candidates are skipped, as are candidates whose removal would take their class below
+// its minimum node count; these are marked as protected with the reason. The passed class node
+// counts are modified.
+func (s *Scaler) selectScaleIn(candidates []*Candidate, counts map[string]int, count int) []string {
+	selected := []string{}
+
+	for _, c := range candidates {
+		if len(selected) == count {
+			break
+		}
+		if c.Protected != "" {
+			continue
+		}
+		if pol, ok := s.classes[c.NodeClass]; ok && counts[c.NodeClass] <= pol.MinNodes {
+			c.Protected = fmt.Sprintf("node class at minimum of %d nodes", pol.MinNodes)
+			continue
+		}
+		counts[c.NodeClass]--
+		selected = append(selected, c.NodeID)
+	}
+	return selected
+}
+
+// eligibleNodes reads the nodes of the class which are eligible for removal, along with the
+// allocations placed on each.
+func (s *Scaler) eligibleNodes(ctx context.Context, class string) ([]*Node, error) {
+	var stubs []*nomad.NodeListStub
+
+	err := s.call(ctx, func() (err error) {
+		stubs, _, err = s.nomad.Client().Nodes().List(nil)
+		return err
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list Nomad nodes")
+	}
+
+	var out []*Node
+
+	for _, stub := range stubs {
+		if !nodeEligible(stub, class) {
+			continue
+		}
+
+		var (
+			info   *nomad.Node
+			allocs []*nomad.Allocation
+		)
+
+		err := s.call(ctx, func() (err error) {
+			info, _, err = s.nomad.Client().Nodes().Info(stub.ID, nil)
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read Nomad node %s", stub.ID)
+		}
+
+		err = s.call(ctx, func() (err error) {
+			allocs, _, err = s.nomad.Client().Nodes().Allocations(stub.ID, nil)
+			return err
+		})
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to read allocations of Nomad node %s", stub.ID)
+		}
+
+		count, util := allocatedUtilization(info, allocs)
+
+		out = append(out, &Node{
+			ID:          stub.ID,
+			Name:        stub.Name,
+			Datacenter:  stub.Datacenter,
+			NodeClass:   stub.NodeClass,
+			Allocations: count,
+			Utilization: util,
+			CreateIndex: stub.CreateIndex,
+			ExcludedJob: s.excludedJob(stub.NodeClass, allocs),
+		})
+	}
+
+	s.logger.Debug().Str("node-class", class).Int("eligible-nodes", len(out)).Msg("read scale in candidate nodes")
+	return out, nil
+}
+
+// excludedJob returns the ID of the first server wide or class excluded job with a running or
+// pending allocation, or an empty string if there are none.
+func (s *Scaler) excludedJob(class string, allocs []*nomad.Allocation) string {
+	var classExcluded []string
+	if pol, ok := s.classes[class]; ok {
+		classExcluded = pol.ExcludedJobs
+	}
+
+	for _, a := range allocs {
+		if a.ClientStatus != nomad.AllocClientStatusRunning && a.ClientStatus != nomad.AllocClientStatusPending {
+			continue
+		}
+		if s.excluded[a.JobID] {
+			return a.JobID
+		}
+		for _, job := range classExcluded {
+			if a.JobID == job {
+				return a.JobID
+			}
+		}
+	}
+	return ""
+}
+
+func (s *Scaler) call(ctx context.Context, f func() error) error {
+	ctx, cancel := helper.ContextWithTimeout(ctx, s.timeout)
+	defer cancel()
+	return s.nomad.Call(ctx, f)
+}
+
+// classNodeCounts returns the number of nodes of each class.
+func classNodeCounts(nodes []*Node) map[string]int {
+	out := make(map[string]int)
+	for _, n := range nodes {
+		out[n.NodeClass]++
+	}
+	return out
+}
+
+// nodeEligible returns whether the node is a candidate for removal. Only ready nodes which are
+// eligible for scheduling and not draining are considered, as other nodes are either already being
+// removed or are being managed by an operator.
+func nodeEligible(n *nomad.NodeListStub, class string) bool {
+	if class != "" && n.NodeClass != class {
+		return false
+	}
+	return n.Status == nomad.NodeStatusReady && !n.Drain && n.SchedulingEligibility == nomad.NodeSchedulingEligible
+}
+
+// nodeParked returns whether the node is in the parked state, being ready but ineligible for
+// scheduling without a drain in progress. This is the state a node is left in once its drain
+// completes, so nodes are only treated as parked if they were also recorded by soft scale in.
+func nodeParked(n *nomad.NodeListStub, class string) bool {
+	if class != "" && n.NodeClass != class {
+		return false
+	}
+	return n.Status == nomad.NodeStatusReady && !n.Drain && n.SchedulingEligibility == nomad.NodeSchedulingIneligible
+}
+
+// allocatedUtilization returns the number of running or pending allocations on the node, and the
+// fraction of the node CPU or memory allocated to them, whichever is higher. Resources reserved on
+// the node are excluded from its capacity.
+func allocatedUtilization(n *nomad.Node, allocs []*nomad.Allocation) (int, float64) {
+	var (
+		count         int
+		cpuMHz, memMB int64
+	)
+
+	for _, a := range allocs {
+		if a.ClientStatus != nomad.AllocClientStatusRunning && a.ClientStatus != nomad.AllocClientStatusPending {
+			continue
+		}
+		count++
+
+		if a.AllocatedResources == nil {
+			continue
+		}
+		for _, task := range a.AllocatedResources.Tasks {
+			if task == nil {
+				continue
+			}
+			cpuMHz += task.Cpu.CpuShares
+			memMB += task.Memory.MemoryMB
+		}
+	}
+
+	if n == nil || n.NodeResources == nil {
+		return count, 0
+	}
+
+	cpuCap, memCap := n.NodeResources.Cpu.CpuShares, n.NodeResources.Memory.MemoryMB
+	if n.ReservedResources != nil {
+		cpuCap -= int64(n.ReservedResources.Cpu.CpuShares)
+		memCap -= int64(n.ReservedResources.Memory.MemoryMB)
+	}
+
+	var util float64
+	if cpuCap > 0 {
+		util = float64(cpuMHz) / float64(cpuCap)
+	}
+	if memCap > 0 {
+		if mem := float64(memMB) / float64(memCap); mem > util {
+			util = mem
+		}
+	}
+	return count, clamp(util)
+}
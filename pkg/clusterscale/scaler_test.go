@@ -0,0 +1,140 @@
+package clusterscale
+
+import (
+	"testing"
+
+	nomad "github.com/hashicorp/nomad/api"
+	serverCfg "github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaler_selectScaleIn(t *testing.T) {
+	s := NewScaler(&Config{Classes: []*serverCfg.NodeClassPolicy{{Class: "batch", MinNodes: 2}}})
+
+	candidates := []*Candidate{
+		{NodeID: "batch-1", NodeClass: "batch"},
+		{NodeID: "web-1", NodeClass: "web", Protected: "running excluded job fluentd"},
+		{NodeID: "batch-2", NodeClass: "batch"},
+		{NodeID: "web-2", NodeClass: "web"},
+	}
+
+	selected := s.selectScaleIn(candidates, map[string]int{"batch": 3, "web": 2}, 3)
+	assert.Equal(t, []string{"batch-1", "web-2"}, selected)
+	assert.Equal(t, "node class at minimum of 2 nodes", candidates[2].Protected)
+
+	for _, c := range candidates {
+		c.Protected = ""
+	}
+	assert.Equal(t, []string{"batch-1"}, s.selectScaleIn(candidates, map[string]int{"batch": 3, "web": 2}, 1))
+}
+
+func Test_desiredNodes(t *testing.T) {
+	testCases := []struct {
+		nodes    int
+		util     float64
+		pol      *serverCfg.NodeClassPolicy
+		expected int
+	}{
+		{nodes: 4, util: 0.3, pol: nil, expected: 4},
+		{nodes: 4, util: 0.35, pol: &serverCfg.NodeClassPolicy{TargetUtilization: 0.7}, expected: 2},
+		{nodes: 4, util: 0.9, pol: &serverCfg.NodeClassPolicy{TargetUtilization: 0.6}, expected: 6},
+		{nodes: 4, util: 0.1, pol: &serverCfg.NodeClassPolicy{MinNodes: 3, TargetUtilization: 0.7}, expected: 3},
+		{nodes: 4, util: 0.9, pol: &serverCfg.NodeClassPolicy{MaxNodes: 5, TargetUtilization: 0.5}, expected: 5},
+		{nodes: 1, util: 0, pol: &serverCfg.NodeClassPolicy{MinNodes: 2}, expected: 2},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, desiredNodes(tc.nodes, tc.util, tc.pol))
+	}
+}
+
+func TestScaler_excludedJob(t *testing.T) {
+	s := NewScaler(&Config{
+		Classes:      []*serverCfg.NodeClassPolicy{{Class: "batch", ExcludedJobs: []string{"gpu-exporter"}}},
+		ExcludedJobs: []string{"fluentd"},
+	})
+
+	allocs := []*nomad.Allocation{
+		{JobID: "example", ClientStatus: nomad.AllocClientStatusRunning},
+		{JobID: "gpu-exporter", ClientStatus: nomad.AllocClientStatusRunning},
+	}
+	assert.Equal(t, "gpu-exporter", s.excludedJob("batch", allocs))
+	assert.Equal(t, "", s.excludedJob("web", allocs))
+
+	allocs = append(allocs, &nomad.Allocation{JobID: "fluentd", ClientStatus: nomad.AllocClientStatusComplete})
+	assert.Equal(t, "", s.excludedJob("web", allocs))
+
+	allocs[2].ClientStatus = nomad.AllocClientStatusPending
+	assert.Equal(t, "fluentd", s.excludedJob("web", allocs))
+}
+
+func Test_nodeEligible(t *testing.T) {
+	ready := &nomad.NodeListStub{NodeClass: "batch", Status: nomad.NodeStatusReady, SchedulingEligibility: nomad.NodeSchedulingEligible}
+	assert.True(t, nodeEligible(ready, ""))
+	assert.True(t, nodeEligible(ready, "batch"))
+	assert.False(t, nodeEligible(ready, "web"))
+
+	draining := *ready
+	draining.Drain = true
+	assert.False(t, nodeEligible(&draining, ""))
+
+	ineligible := *ready
+	ineligible.SchedulingEligibility = nomad.NodeSchedulingIneligible
+	assert.False(t, nodeEligible(&ineligible, ""))
+
+	down := *ready
+	down.Status = nomad.NodeStatusDown
+	assert.False(t, nodeEligible(&down, ""))
+}
+
+func Test_nodeParked(t *testing.T) {
+	parked := &nomad.NodeListStub{NodeClass: "batch", Status: nomad.NodeStatusReady, SchedulingEligibility: nomad.NodeSchedulingIneligible}
+	assert.True(t, nodeParked(parked, ""))
+	assert.True(t, nodeParked(parked, "batch"))
+	assert.False(t, nodeParked(parked, "web"))
+
+	draining := *parked
+	draining.Drain = true
+	assert.False(t, nodeParked(&draining, ""))
+
+	eligible := *parked
+	eligible.SchedulingEligibility = nomad.NodeSchedulingEligible
+	assert.False(t, nodeParked(&eligible, ""))
+
+	down := *parked
+	down.Status = nomad.NodeStatusDown
+	assert.False(t, nodeParked(&down, ""))
+}
+
+func Test_allocatedUtilization(t *testing.T) {
+	node := &nomad.Node{
+		NodeResources: &nomad.NodeResources{
+			Cpu:    nomad.NodeCpuResources{CpuShares: 2100},
+			Memory: nomad.NodeMemoryResources{MemoryMB: 4096},
+		},
+		ReservedResources: &nomad.NodeReservedResources{
+			Cpu: nomad.NodeReservedCpuResources{CpuShares: 100},
+		},
+	}
+
+	alloc := func(status string, cpu, mem int64) *nomad.Allocation {
+		return &nomad.Allocation{
+			ClientStatus: status,
+			AllocatedResources: &nomad.AllocatedResources{Tasks: map[string]*nomad.AllocatedTaskResources{
+				"task": {Cpu: nomad.AllocatedCpuResources{CpuShares: cpu}, Memory: nomad.AllocatedMemoryResources{MemoryMB: mem}},
+			}},
+		}
+	}
+
+	count, util := allocatedUtilization(node, []*nomad.Allocation{
+		alloc(nomad.AllocClientStatusRunning, 500, 512),
+		alloc(nomad.AllocClientStatusPending, 500, 512),
+		alloc(nomad.AllocClientStatusComplete, 1000, 2048),
+	})
+	assert.Equal(t, 2, count)
+	assert.Equal(t, 0.5, util)
+
+	count, util = allocatedUtilization(node, nil)
+	assert.Equal(t, 0, count)
+	assert.Equal(t, 0.0, util)
+}
@@ -0,0 +1,145 @@
+// Package clusterscale provides the experimental scaling of the Nomad client nodes of the cluster,
+// which is enabled using the cluster-scaling feature flag.
+package clusterscale
+
+import "sort"
+
+// ScoreWeights are the weights of the factors used to score the nodes which are candidates for
+// removal during scale in. A weight of 0 disables the factor.
+type ScoreWeights struct {
+	Empty       float64
+	Allocations float64
+	Utilization float64
+	Age         float64
+}
+
+// Node is a Nomad client node which is eligible for removal during scale in.
+type Node struct {
+	ID         string
+	Name       string
+	Datacenter string
+	NodeClass  string
+
+	// Allocations is the number of running or pending allocations placed on the node.
+	Allocations int
+
+	// Utilization is the fraction, between 0 and 1, of the node CPU or memory allocated to its
+	// running and pending allocations, whichever is higher.
+	Utilization float64
+
+	// CreateIndex is the Nomad raft index at which the node registered, used to order the nodes
+	// by age.
+	CreateIndex uint64
+
+	// ExcludedJob is the ID of an excluded job with an allocation on the node, which protects the
+	// node from removal. It is empty if the node runs no excluded jobs.
+	ExcludedJob string
+}
+
+// Factors are the normalised scores, between 0 and 1, of each scale in factor for a node. A higher
+// value makes the node a better candidate for removal.
+type Factors struct {
+	Empty       float64
+	Allocations float64
+	Utilization float64
+	Age         float64
+}
+
+// Candidate is a node scored for removal during scale in.
+type Candidate struct {
+	NodeID      string
+	Name        string
+	Datacenter  string
+	NodeClass   string
+	Allocations int
+	Utilization float64
+	Factors     Factors
+
+	// Score is the weighted mean of the factors, between 0 and 1.
+	Score float64
+
+	// Protected is the reason the node cannot be selected for removal, such as running an
+	// excluded job. It is empty if the node can be selected.
+	Protected string `json:",omitempty"`
+}
+
+// Rank scores the nodes using the passed weights, returning the candidates ordered from the best
+// to the worst candidate for removal. Empty nodes, nodes with fewer allocations, nodes with lower
+// utilization and older nodes score higher. Nodes with equal scores are ordered by ID so that the
+// selection is stable.
+func Rank(nodes []*Node, w ScoreWeights) []*Candidate {
+	if len(nodes) == 0 {
+		return nil
+	}
+
+	maxAllocs := 0
+	minIndex, maxIndex := nodes[0].CreateIndex, nodes[0].CreateIndex
+
+	for _, n := range nodes {
+		if n.Allocations > maxAllocs {
+			maxAllocs = n.Allocations
+		}
+		if n.CreateIndex < minIndex {
+			minIndex = n.CreateIndex
+		}
+		if n.CreateIndex > maxIndex {
+			maxIndex = n.CreateIndex
+		}
+	}
+
+	total := w.Empty + w.Allocations + w.Utilization + w.Age
+
+	out := make([]*Candidate, 0, len(nodes))
+
+	for _, n := range nodes {
+		f := Factors{
+			Allocations: 1,
+			Utilization: 1 - clamp(n.Utilization),
+			Age:         1,
+		}
+		if n.Allocations == 0 {
+			f.Empty = 1
+		}
+		if maxAllocs > 0 {
+			f.Allocations = 1 - float64(n.Allocations)/float64(maxAllocs)
+		}
+		if maxIndex > minIndex {
+			f.Age = float64(maxIndex-n.CreateIndex) / float64(maxIndex-minIndex)
+		}
+
+		c := Candidate{
+			NodeID:      n.ID,
+			Name:        n.Name,
+			Datacenter:  n.Datacenter,
+			NodeClass:   n.NodeClass,
+			Allocations: n.Allocations,
+			Utilization: n.Utilization,
+			Factors:     f,
+		}
+		if n.ExcludedJob != "" {
+			c.Protected = "running excluded job " + n.ExcludedJob
+		}
+		if total > 0 {
+			c.Score = (w.Empty*f.Empty + w.Allocations*f.Allocations + w.Utilization*f.Utilization + w.Age*f.Age) / total
+		}
+		out = append(out, &c)
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Score != out[j].Score {
+			return out[i].Score > out[j].Score
+		}
+		return out[i].NodeID < out[j].NodeID
+	})
+	return out
+}
+
+func clamp(v float64) float64 {
+	if v < 0 {
+		return 0
+	}
+	if v > 1 {
+		return 1
+	}
+	return v
+}
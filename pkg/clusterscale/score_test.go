@@ -0,0 +1,49 @@
+package clusterscale
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRank(t *testing.T) {
+	nodes := []*Node{
+		{ID: "busy", Allocations: 4, Utilization: 0.8, CreateIndex: 10},
+		{ID: "empty", Allocations: 0, Utilization: 0, CreateIndex: 30},
+		{ID: "quiet", Allocations: 1, Utilization: 0.2, CreateIndex: 20},
+	}
+
+	out := Rank(nodes, ScoreWeights{Empty: 4, Allocations: 2, Utilization: 2, Age: 1})
+	assert.Len(t, out, 3)
+	assert.Equal(t, "empty", out[0].NodeID)
+	assert.Equal(t, "quiet", out[1].NodeID)
+	assert.Equal(t, "busy", out[2].NodeID)
+
+	assert.Equal(t, Factors{Empty: 1, Allocations: 1, Utilization: 1, Age: 0}, out[0].Factors)
+	assert.Equal(t, Factors{Empty: 0, Allocations: 0, Utilization: 0.19999999999999996, Age: 1}, out[2].Factors)
+
+	// Only weighting age selects the oldest node.
+	out = Rank(nodes, ScoreWeights{Age: 1})
+	assert.Equal(t, "busy", out[0].NodeID)
+	assert.Equal(t, 1.0, out[0].Score)
+}
+
+func TestRank_Ties(t *testing.T) {
+	nodes := []*Node{
+		{ID: "b", CreateIndex: 5},
+		{ID: "a", CreateIndex: 5},
+	}
+
+	out := Rank(nodes, ScoreWeights{Empty: 1, Age: 1})
+	assert.Equal(t, "a", out[0].NodeID)
+	assert.Equal(t, "b", out[1].NodeID)
+	assert.Equal(t, 1.0, out[0].Score)
+
+	assert.Nil(t, Rank(nil, ScoreWeights{Empty: 1}))
+}
+
+func TestRank_Protected(t *testing.T) {
+	out := Rank([]*Node{{ID: "a"}, {ID: "b", ExcludedJob: "fluentd"}}, ScoreWeights{Empty: 1})
+	assert.Equal(t, "", out[0].Protected)
+	assert.Equal(t, "running excluded job fluentd", out[1].Protected)
+}
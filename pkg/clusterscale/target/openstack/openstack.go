@@ -0,0 +1,406 @@
+package openstack
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/jrasell/sherpa/pkg/clusterscale/target"
+	serverCfg "github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// providerName is the name of the provider used in logging and telemetry.
+	providerName = "openstack"
+
+	// metadataNodeClass is the server metadata key which records the node class of the servers
+	// launched by Sherpa.
+	metadataNodeClass = "sherpa_node_class"
+
+	// tokenExpiryMargin is the time before a Keystone token expires at which it is renewed, so a
+	// token does not expire while a request is in flight.
+	tokenExpiryMargin = time.Minute
+
+	headerAuthToken    = "X-Auth-Token"
+	headerSubjectToken = "X-Subject-Token"
+)
+
+// Config is the configuration of the OpenStack node target provider.
+type Config struct {
+	// AuthURL is the Keystone v3 endpoint, and Username, Password, UserDomain and ProjectID are
+	// the credentials and scope of the token used to manage the servers.
+	AuthURL    string
+	Username   string
+	Password   *secret.Value
+	UserDomain string
+	ProjectID  string
+
+	// Region selects the compute endpoint from the service catalog when it contains endpoints in
+	// more than one region.
+	Region string
+
+	// Classes are the node class policies, which hold the parameters of the servers launched
+	// for each class.
+	Classes []*serverCfg.NodeClassPolicy
+
+	Logger zerolog.Logger
+}
+
+// Client is a node target provider which launches and deletes Nova servers, allowing private
+// OpenStack clouds to be scaled without an external plugin.
+type Client struct {
+	cfg     *Config
+	classes map[string]*serverCfg.OpenStackNodeClass
+	client  *http.Client
+	logger  zerolog.Logger
+
+	lock    sync.Mutex
+	token   string
+	compute string
+	expiry  time.Time
+}
+
+// NewClient builds the OpenStack node target provider.
+func NewClient(cfg *Config) target.Provider {
+	classes := make(map[string]*serverCfg.OpenStackNodeClass)
+	for _, pol := range cfg.Classes {
+		if pol.OpenStack != nil {
+			classes[pol.Class] = pol.OpenStack
+		}
+	}
+
+	return &Client{
+		cfg:     cfg,
+		classes: classes,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		logger:  cfg.Logger.With().Str("node-target-provider", providerName).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the target.Provider interface.
+func (c *Client) Name() string { return providerName }
+
+// ScaleOut satisfies the ScaleOut function of the target.Provider interface. Each server is named
+// after the node class with a random suffix, which becomes the hostname and so the Nomad node
+// name.
+func (c *Client) ScaleOut(ctx context.Context, class string, count int) error {
+	params, ok := c.classes[class]
+	if !ok {
+		return errors.Errorf("node class %s has no OpenStack launch params", class)
+	}
+
+	for i := 0; i < count; i++ {
+		name, err := serverName(class)
+		if err != nil {
+			return err
+		}
+		if err := c.call(ctx, "scale-out", http.MethodPost, "/servers", newServerRequest(name, class, params), nil); err != nil {
+			return errors.Wrapf(err, "failed to launch server %d of %d", i+1, count)
+		}
+		c.logger.Info().Str("node-class", class).Str("server", name).Msg("launched OpenStack server")
+	}
+	return nil
+}
+
+// InstanceID satisfies the InstanceID function of the target.Provider interface. The server is
+// identified by its name, which matches the node name as Nomad uses the hostname by default.
+func (c *Client) InstanceID(ctx context.Context, node *target.Node) (string, error) {
+	var resp struct {
+		Servers []struct {
+			ID   string `json:"id"`
+			Name string `json:"name"`
+		} `json:"servers"`
+	}
+
+	// The Nova name filter is a regular expression, so must be anchored and escaped to match only
+	// the server with the node name.
+	path := "/servers?name=" + url.QueryEscape("^"+regexp.QuoteMeta(node.Name)+"$")
+
+	if err := c.call(ctx, "instance-id", http.MethodGet, path, nil, &resp); err != nil {
+		return "", err
+	}
+
+	switch len(resp.Servers) {
+	case 0:
+		return "", errors.Errorf("no OpenStack server found with name %s", node.Name)
+	case 1:
+		return resp.Servers[0].ID, nil
+	default:
+		return "", errors.Errorf("found %d OpenStack servers with name %s", len(resp.Servers), node.Name)
+	}
+}
+
+// Terminate satisfies the Terminate function of the target.Provider interface. Servers which have
+// already been deleted are ignored.
+func (c *Client) Terminate(ctx context.Context, class string, instanceIDs []string) error {
+	for _, id := range instanceIDs {
+		err := c.call(ctx, "terminate", http.MethodDelete, "/servers/"+url.PathEscape(id), nil, nil)
+		if err != nil && !isNotFound(err) {
+			return errors.Wrapf(err, "failed to delete server %s", id)
+		}
+		c.logger.Info().Str("node-class", class).Str("server-id", id).Msg("deleted OpenStack server")
+	}
+	return nil
+}
+
+// serverRequest is the Nova create server request body.
+type serverRequest struct {
+	Server struct {
+		Name           string              `json:"name"`
+		ImageRef       string              `json:"imageRef"`
+		FlavorRef      string              `json:"flavorRef"`
+		Networks       []map[string]string `json:"networks,omitempty"`
+		SecurityGroups []map[string]string `json:"security_groups,omitempty"`
+		KeyName        string              `json:"key_name,omitempty"`
+		UserData       string              `json:"user_data,omitempty"`
+		Metadata       map[string]string   `json:"metadata"`
+	} `json:"server"`
+}
+
+func newServerRequest(name, class string, params *serverCfg.OpenStackNodeClass) *serverRequest {
+	var req serverRequest
+
+	req.Server.Name = name
+	req.Server.ImageRef = params.ImageID
+	req.Server.FlavorRef = params.FlavorID
+	req.Server.KeyName = params.KeyName
+	req.Server.Metadata = map[string]string{metadataNodeClass: class}
+
+	for _, n := range params.Networks {
+		req.Server.Networks = append(req.Server.Networks, map[string]string{"uuid": n})
+	}
+	for _, sg := range params.SecurityGroups {
+		req.Server.SecurityGroups = append(req.Server.SecurityGroups, map[string]string{"name": sg})
+	}
+	if params.UserData != "" {
+		req.Server.UserData = base64.StdEncoding.EncodeToString([]byte(params.UserData))
+	}
+	return &req
+}
+
+// serverName returns a unique name for a new server of the node class.
+func serverName(class string) (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate server name")
+	}
+	return class + "-" + hex.EncodeToString(b), nil
+}
+
+// statusError is returned when the compute API responds with an unexpected status code.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return "unexpected response code " + http.StatusText(e.code) + ": " + e.body
+}
+
+func isNotFound(err error) bool {
+	se, ok := errors.Cause(err).(*statusError)
+	return ok && se.code == http.StatusNotFound
+}
+
+// call performs the compute API request, emitting telemetry and logging failures.
+func (c *Client) call(ctx context.Context, op, method, path string, body, out interface{}) error {
+	defer sendMetrics.MeasureSinceWithLabels([]string{"cluster_scale", providerName, "call"}, time.Now(),
+		[]sendMetrics.Label{{Name: "operation", Value: op}})
+
+	err := c.do(ctx, method, path, body, out)
+	if err != nil && !isNotFound(err) {
+		sendMetrics.IncrCounterWithLabels([]string{"cluster_scale", providerName, "error"}, 1,
+			[]sendMetrics.Label{{Name: "operation", Value: op}})
+		c.logger.Error().Err(err).Str("operation", op).Msg("OpenStack API call failed")
+	}
+	return err
+}
+
+// do performs the compute API request. If the token has been revoked or expired early, it is
+// renewed and the request retried once.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	err := c.doOnce(ctx, method, path, body, out)
+	if se, ok := errors.Cause(err).(*statusError); ok && se.code == http.StatusUnauthorized {
+		c.lock.Lock()
+		c.token = ""
+		c.lock.Unlock()
+		err = c.doOnce(ctx, method, path, body, out)
+	}
+	return err
+}
+
+func (c *Client) doOnce(ctx context.Context, method, path string, body, out interface{}) error {
+	token, compute, err := c.authenticate(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, compute+path, reader)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request")
+	}
+	req.Header.Set(headerAuthToken, token)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "failed to call compute API")
+	}
+	defer resp.Body.Close()
+
+	return decodeResponse(resp, out)
+}
+
+// authenticate returns a valid token and the compute endpoint, requesting a new token from
+// Keystone if the current one is missing or about to expire.
+func (c *Client) authenticate(ctx context.Context) (string, string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.token != "" && time.Now().Add(tokenExpiryMargin).Before(c.expiry) {
+		return c.token, c.compute, nil
+	}
+
+	password, err := c.cfg.Password.Get(ctx)
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to resolve OpenStack password")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.cfg.AuthURL, "/")+"/auth/tokens",
+		bytes.NewReader(newAuthRequest(c.cfg.Username, password, c.cfg.UserDomain, c.cfg.ProjectID)))
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to build Keystone request")
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", "", errors.Wrap(err, "failed to call Keystone")
+	}
+	defer resp.Body.Close()
+
+	var auth authResponse
+
+	if err := decodeResponse(resp, &auth); err != nil {
+		return "", "", errors.Wrap(err, "failed to authenticate with Keystone")
+	}
+
+	token := resp.Header.Get(headerSubjectToken)
+	if token == "" {
+		return "", "", errors.New("Keystone response does not include a token")
+	}
+
+	compute, err := auth.computeEndpoint(c.cfg.Region)
+	if err != nil {
+		return "", "", err
+	}
+
+	c.token, c.compute, c.expiry = token, compute, auth.Token.ExpiresAt
+	return c.token, c.compute, nil
+}
+
+// newAuthRequest returns the Keystone v3 password authentication request body, scoped to the
+// project.
+func newAuthRequest(username, password, domain, project string) []byte {
+	var req struct {
+		Auth struct {
+			Identity struct {
+				Methods  []string `json:"methods"`
+				Password struct {
+					User struct {
+						Name     string `json:"name"`
+						Password string `json:"password"`
+						Domain   struct {
+							Name string `json:"name"`
+						} `json:"domain"`
+					} `json:"user"`
+				} `json:"password"`
+			} `json:"identity"`
+			Scope struct {
+				Project struct {
+					ID string `json:"id"`
+				} `json:"project"`
+			} `json:"scope"`
+		} `json:"auth"`
+	}
+
+	req.Auth.Identity.Methods = []string{"password"}
+	req.Auth.Identity.Password.User.Name = username
+	req.Auth.Identity.Password.User.Password = password
+	req.Auth.Identity.Password.User.Domain.Name = domain
+	req.Auth.Scope.Project.ID = project
+
+	// The request only contains strings, so cannot fail to marshal.
+	payload, _ := json.Marshal(&req)
+	return payload
+}
+
+// authResponse is the subset of the Keystone token response used by the provider.
+type authResponse struct {
+	Token struct {
+		ExpiresAt time.Time `json:"expires_at"`
+		Catalog   []struct {
+			Type      string `json:"type"`
+			Endpoints []struct {
+				Interface string `json:"interface"`
+				Region    string `json:"region"`
+				URL       string `json:"url"`
+			} `json:"endpoints"`
+		} `json:"catalog"`
+	} `json:"token"`
+}
+
+// computeEndpoint returns the public compute endpoint of the region from the service catalog. If
+// the region is empty, the first public compute endpoint is used.
+func (a *authResponse) computeEndpoint(region string) (string, error) {
+	for _, svc := range a.Token.Catalog {
+		if svc.Type != "compute" {
+			continue
+		}
+		for _, ep := range svc.Endpoints {
+			if ep.Interface == "public" && (region == "" || ep.Region == region) {
+				return strings.TrimSuffix(ep.URL, "/"), nil
+			}
+		}
+	}
+	return "", errors.Errorf("no public compute endpoint found in the service catalog for region %q", region)
+}
+
+// decodeResponse checks the response status, decoding the body into out if it is not nil.
+func decodeResponse(resp *http.Response, out interface{}) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package openstack
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/clusterscale/target"
+	serverCfg "github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeOpenStack is a Keystone and Nova API which stores servers in memory.
+type fakeOpenStack struct {
+	lock    sync.Mutex
+	auths   int
+	revoked bool
+	servers map[string]*serverRequest
+}
+
+func newFakeOpenStack(t *testing.T) (*fakeOpenStack, *httptest.Server) {
+	f := &fakeOpenStack{servers: make(map[string]*serverRequest)}
+
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		if r.URL.Path == "/v3/auth/tokens" {
+			f.auths++
+			f.revoked = false
+			w.Header().Set(headerSubjectToken, fmt.Sprintf("token-%d", f.auths))
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `{"token": {"expires_at": %q, "catalog": [{"type": "compute", "endpoints": [
+				{"interface": "internal", "region": "RegionOne", "url": "http://internal"},
+				{"interface": "public", "region": "RegionOne", "url": "%s/compute/"}]}]}}`,
+				time.Now().Add(time.Hour).Format(time.RFC3339), srv.URL)
+			return
+		}
+
+		if f.revoked || r.Header.Get(headerAuthToken) == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/compute/servers":
+			var req serverRequest
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+			f.servers["id-"+req.Server.Name] = &req
+			w.WriteHeader(http.StatusAccepted)
+			_, _ = w.Write([]byte(`{"server": {"id": "id-` + req.Server.Name + `"}}`))
+
+		case r.Method == http.MethodGet && r.URL.Path == "/compute/servers":
+			name := strings.TrimSuffix(strings.TrimPrefix(r.URL.Query().Get("name"), "^"), "$")
+			var out []string
+			for id, s := range f.servers {
+				if s.Server.Name == name {
+					out = append(out, fmt.Sprintf(`{"id": %q, "name": %q}`, id, name))
+				}
+			}
+			_, _ = w.Write([]byte(`{"servers": [` + strings.Join(out, ",") + `]}`))
+
+		case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, "/compute/servers/"):
+			id := strings.TrimPrefix(r.URL.Path, "/compute/servers/")
+			if _, ok := f.servers[id]; !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+			delete(f.servers, id)
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	return f, srv
+}
+
+func newTestClient(addr string) target.Provider {
+	return NewClient(&Config{
+		AuthURL:    addr + "/v3/",
+		Username:   "sherpa",
+		Password:   secret.NewResolver(secret.Config{}, zerolog.Nop()).Value("password"),
+		UserDomain: "Default",
+		ProjectID:  "project",
+		Region:     "RegionOne",
+		Classes: []*serverCfg.NodeClassPolicy{
+			{Class: "batch", OpenStack: &serverCfg.OpenStackNodeClass{ImageID: "image", FlavorID: "m1.large",
+				Networks: []string{"private"}, UserData: "#cloud-config"}},
+			{Class: "web"},
+		},
+		Logger: zerolog.Nop(),
+	})
+}
+
+func TestClient(t *testing.T) {
+	fake, srv := newFakeOpenStack(t)
+	defer srv.Close()
+
+	c := newTestClient(srv.URL)
+	ctx := context.Background()
+
+	assert.Nil(t, c.ScaleOut(ctx, "batch", 2))
+	assert.EqualError(t, c.ScaleOut(ctx, "web", 1), "node class web has no OpenStack launch params")
+	assert.Len(t, fake.servers, 2)
+	assert.Equal(t, 1, fake.auths)
+
+	var name string
+	for _, s := range fake.servers {
+		name = s.Server.Name
+		assert.True(t, strings.HasPrefix(name, "batch-"))
+		assert.Equal(t, "m1.large", s.Server.FlavorRef)
+		assert.Equal(t, []map[string]string{{"uuid": "private"}}, s.Server.Networks)
+		assert.Equal(t, "I2Nsb3VkLWNvbmZpZw==", s.Server.UserData)
+		assert.Equal(t, map[string]string{metadataNodeClass: "batch"}, s.Server.Metadata)
+	}
+
+	// A revoked token is renewed and the request retried.
+	fake.revoked = true
+
+	id, err := c.InstanceID(ctx, &target.Node{ID: "node-1", Name: name, NodeClass: "batch"})
+	assert.Nil(t, err)
+	assert.Equal(t, "id-"+name, id)
+	assert.Equal(t, 2, fake.auths)
+
+	_, err = c.InstanceID(ctx, &target.Node{ID: "node-2", Name: "unknown", NodeClass: "batch"})
+	assert.EqualError(t, err, "no OpenStack server found with name unknown")
+
+	// Servers which have already been deleted are ignored.
+	assert.Nil(t, c.Terminate(ctx, "batch", []string{id, "id-deleted"}))
+	assert.Len(t, fake.servers, 1)
+}
+
+func Test_authResponse_computeEndpoint(t *testing.T) {
+	var auth authResponse
+	assert.Nil(t, json.Unmarshal([]byte(`{"token": {"catalog": [
+		{"type": "identity", "endpoints": [{"interface": "public", "region": "RegionOne", "url": "http://keystone"}]},
+		{"type": "compute", "endpoints": [
+			{"interface": "public", "region": "RegionOne", "url": "http://nova-one/v2.1/"},
+			{"interface": "public", "region": "RegionTwo", "url": "http://nova-two/v2.1"}]}]}}`), &auth))
+
+	ep, err := auth.computeEndpoint("")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://nova-one/v2.1", ep)
+
+	ep, err = auth.computeEndpoint("RegionTwo")
+	assert.Nil(t, err)
+	assert.Equal(t, "http://nova-two/v2.1", ep)
+
+	_, err = auth.computeEndpoint("RegionThree")
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,195 @@
+// Package plugin implements a node target provider which runs an external plugin command.
+//
+// The plugin protocol deliberately does not use hashicorp/go-plugin. Node target operations are
+// infrequent, and a command run per operation, exchanging JSON over stdin and stdout, can be
+// written as a shell script around existing node lifecycle tooling, without a long running plugin
+// process to supervise or an RPC library to build against. In place of the go-plugin handshake,
+// the protocol is versioned: each command is run with the magic cookie and protocol version env
+// vars set, and a handshake operation is performed before the first operation to confirm the
+// plugin supports the protocol version.
+package plugin
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/jrasell/sherpa/pkg/clusterscale/target"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// ProtocolVersion is the version of the plugin protocol. It must be incremented whenever a change
+// is made to the protocol which existing plugins cannot handle.
+const ProtocolVersion = 1
+
+// The env vars set when running the plugin command. The magic cookie allows a plugin to detect it
+// has been run by Sherpa, rather than directly by a user.
+const (
+	EnvMagicCookie     = "SHERPA_PLUGIN_MAGIC_COOKIE"
+	EnvProtocolVersion = "SHERPA_PLUGIN_PROTOCOL_VERSION"
+	MagicCookie        = "d3a4d4c1e2b5f0e8a1c6b7f9e0d2c3a5"
+)
+
+// The operations which a plugin is called to perform.
+const (
+	OperationHandshake  = "handshake"
+	OperationScaleOut   = "scale-out"
+	OperationInstanceID = "instance-id"
+	OperationTerminate  = "terminate"
+)
+
+// Request is the JSON payload passed to the plugin on stdin. Only the fields used by the
+// operation are set.
+type Request struct {
+	Operation       string
+	ProtocolVersion int
+	NodeClass       string       `json:",omitempty"`
+	Count           int          `json:",omitempty"`
+	Node            *target.Node `json:",omitempty"`
+	InstanceIDs     []string     `json:",omitempty"`
+}
+
+// Response is the JSON payload the plugin writes to stdout. A plugin may write nothing for
+// operations which do not return a value. A non-empty Error, or a non-zero exit code, fails the
+// operation.
+type Response struct {
+	ProtocolVersion int    `json:",omitempty"`
+	InstanceID      string `json:",omitempty"`
+	Error           string `json:",omitempty"`
+}
+
+// Client is a node target provider which runs an external plugin command for each operation,
+// allowing operators to integrate their own node lifecycle automation without changes to Sherpa.
+type Client struct {
+	command string
+	args    []string
+	logger  zerolog.Logger
+
+	// handshakeLock guards handshakeDone, which is set once the plugin has confirmed it supports
+	// the protocol version. A failed handshake is attempted again by the next operation.
+	handshakeLock sync.Mutex
+	handshakeDone bool
+}
+
+// NewClient builds a node target provider which runs the plugin command with the passed args.
+func NewClient(command string, args []string, log zerolog.Logger) target.Provider {
+	return &Client{
+		command: command,
+		args:    args,
+		logger:  log.With().Str("node-target-provider", command).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the target.Provider interface.
+func (c *Client) Name() string { return c.command }
+
+// ScaleOut satisfies the ScaleOut function of the target.Provider interface.
+func (c *Client) ScaleOut(ctx context.Context, class string, count int) error {
+	_, err := c.call(ctx, &Request{Operation: OperationScaleOut, NodeClass: class, Count: count})
+	return err
+}
+
+// InstanceID satisfies the InstanceID function of the target.Provider interface.
+func (c *Client) InstanceID(ctx context.Context, node *target.Node) (string, error) {
+	resp, err := c.call(ctx, &Request{Operation: OperationInstanceID, NodeClass: node.NodeClass, Node: node})
+	if err != nil {
+		return "", err
+	}
+	if resp.InstanceID == "" {
+		return "", errors.Errorf("plugin returned no instance ID for node %s", node.ID)
+	}
+	return resp.InstanceID, nil
+}
+
+// Terminate satisfies the Terminate function of the target.Provider interface.
+func (c *Client) Terminate(ctx context.Context, class string, instanceIDs []string) error {
+	_, err := c.call(ctx, &Request{Operation: OperationTerminate, NodeClass: class, InstanceIDs: instanceIDs})
+	return err
+}
+
+// handshake confirms the plugin supports the protocol version, if this has not already been done.
+func (c *Client) handshake(ctx context.Context) error {
+	c.handshakeLock.Lock()
+	defer c.handshakeLock.Unlock()
+
+	if c.handshakeDone {
+		return nil
+	}
+
+	resp, err := c.run(ctx, &Request{Operation: OperationHandshake})
+	if err != nil {
+		return err
+	}
+	if resp.ProtocolVersion != ProtocolVersion {
+		return errors.Errorf("plugin supports protocol version %v, but version %v is required",
+			resp.ProtocolVersion, ProtocolVersion)
+	}
+
+	c.handshakeDone = true
+	c.logger.Debug().Int("protocol-version", ProtocolVersion).Msg("completed node target plugin handshake")
+	return nil
+}
+
+// call runs the plugin command for the request, returning the decoded response. The handshake is
+// performed before the first operation.
+func (c *Client) call(ctx context.Context, req *Request) (*Response, error) {
+	defer sendMetrics.MeasureSinceWithLabels([]string{"cluster_scale", "plugin", "call"}, time.Now(),
+		[]sendMetrics.Label{{Name: "operation", Value: req.Operation}})
+
+	err := c.handshake(ctx)
+
+	var resp *Response
+	if err == nil {
+		resp, err = c.run(ctx, req)
+	}
+	if err != nil {
+		sendMetrics.IncrCounterWithLabels([]string{"cluster_scale", "plugin", "error"}, 1,
+			[]sendMetrics.Label{{Name: "operation", Value: req.Operation}})
+		c.logger.Error().Err(err).Str("operation", req.Operation).Msg("node target plugin call failed")
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *Client) run(ctx context.Context, req *Request) (*Response, error) {
+	req.ProtocolVersion = ProtocolVersion
+
+	payload, err := json.Marshal(req)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal plugin request")
+	}
+
+	var stdout, stderr bytes.Buffer
+
+	cmd := exec.CommandContext(ctx, c.command, c.args...) // nolint:gosec
+	cmd.Env = append(os.Environ(),
+		EnvMagicCookie+"="+MagicCookie,
+		EnvProtocolVersion+"="+strconv.Itoa(ProtocolVersion))
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, errors.Wrapf(err, "plugin %s failed: %s", req.Operation, strings.TrimSpace(stderr.String()))
+	}
+
+	var resp Response
+
+	if out := bytes.TrimSpace(stdout.Bytes()); len(out) > 0 {
+		if err := json.Unmarshal(out, &resp); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode plugin %s response", req.Operation)
+		}
+	}
+	if resp.Error != "" {
+		return nil, errors.Errorf("plugin %s failed: %s", req.Operation, resp.Error)
+	}
+	return &resp, nil
+}
@@ -0,0 +1,110 @@
+package plugin
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/clusterscale/target"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// pluginArgs returns the sh args of a plugin which completes the handshake, and runs the script for
+// all other operations with the request on stdin.
+func pluginArgs(script string) []string {
+	return []string{"-c", `req=$(cat)
+case "$req" in
+  *'"Operation":"handshake"'*) echo '{"ProtocolVersion":1}' ;;
+  *) echo "$req" | { ` + script + `; } ;;
+esac`}
+}
+
+func TestClient_handshake(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sherpa-plugin")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	calls := filepath.Join(dir, "calls")
+
+	testCases := []struct {
+		name        string
+		script      string
+		expectError bool
+	}{
+		{
+			name: "supported protocol version",
+			script: `[ "$SHERPA_PLUGIN_MAGIC_COOKIE" = "` + MagicCookie + `" ] || exit 1
+[ "$SHERPA_PLUGIN_PROTOCOL_VERSION" = "1" ] || exit 1
+grep -o '"Operation":"[a-z-]*","ProtocolVersion":1' >> ` + calls + `
+echo '{"ProtocolVersion":1}'`,
+		},
+		{name: "unsupported protocol version", script: `echo '{"ProtocolVersion":2}'`, expectError: true},
+		{name: "no handshake response", script: `true`, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		c := NewClient("sh", []string{"-c", tc.script}, zerolog.Nop())
+		err := c.ScaleOut(context.Background(), "batch", 1)
+		if tc.expectError {
+			assert.NotNil(t, err, tc.name)
+			continue
+		}
+		assert.Nil(t, err, tc.name)
+		assert.Nil(t, c.Terminate(context.Background(), "batch", []string{"vm-1"}), tc.name)
+	}
+
+	// The handshake is only performed before the first operation of the client.
+	out, err := ioutil.ReadFile(calls)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{
+		`"Operation":"handshake","ProtocolVersion":1`,
+		`"Operation":"scale-out","ProtocolVersion":1`,
+		`"Operation":"terminate","ProtocolVersion":1`,
+	}, strings.Fields(string(out)))
+}
+
+func TestClient_ScaleOut(t *testing.T) {
+	c := NewClient("sh", pluginArgs(`grep -q '"Operation":"scale-out","ProtocolVersion":1,"NodeClass":"batch","Count":2'`), zerolog.Nop())
+	assert.Nil(t, c.ScaleOut(context.Background(), "batch", 2))
+	assert.NotNil(t, c.ScaleOut(context.Background(), "batch", 3))
+}
+
+func TestClient_InstanceID(t *testing.T) {
+	testCases := []struct {
+		name        string
+		script      string
+		expectedID  string
+		expectError bool
+	}{
+		{
+			name:       "instance found",
+			script:     `grep -q '"unique.platform.vsphere.uuid":"4221"' && echo '{"InstanceID":"vm-4221"}'`,
+			expectedID: "vm-4221",
+		},
+		{name: "plugin error", script: `echo '{"Error":"node not managed"}'`, expectError: true},
+		{name: "no instance", script: `true`, expectError: true},
+		{name: "invalid response", script: `echo 'vm-4221'`, expectError: true},
+		{name: "non-zero exit", script: `echo 'connection refused' >&2; exit 1`, expectError: true},
+	}
+
+	node := &target.Node{ID: "node-1", NodeClass: "batch", Attributes: map[string]string{"unique.platform.vsphere.uuid": "4221"}}
+
+	for _, tc := range testCases {
+		id, err := NewClient("sh", pluginArgs(tc.script), zerolog.Nop()).InstanceID(context.Background(), node)
+		if tc.expectError {
+			assert.NotNil(t, err, tc.name)
+			continue
+		}
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.expectedID, id, tc.name)
+	}
+}
+
+func TestClient_Terminate(t *testing.T) {
+	c := NewClient("sh", pluginArgs(`grep -q '"Operation":"terminate","ProtocolVersion":1,"NodeClass":"batch","InstanceIDs":\["vm-1","vm-2"\]'`), zerolog.Nop())
+	assert.Nil(t, c.Terminate(context.Background(), "batch", []string{"vm-1", "vm-2"}))
+}
@@ -0,0 +1,35 @@
+package target
+
+import "context"
+
+// Node is a Nomad client node managed by a node target provider.
+type Node struct {
+	ID        string
+	Name      string
+	NodeClass string
+
+	// Attributes are the fingerprinted attributes of the node, which providers can use to
+	// identify the instance running the node, such as unique.platform.aws.instance-id.
+	Attributes map[string]string
+}
+
+// Provider is the interface which all node target providers must implement. A node target
+// provider manages the lifecycle of the instances which run the Nomad clients of each node class,
+// such as a cloud autoscaling group or an on-premise provisioning system. The context should be
+// used to cancel any outstanding requests once its deadline is exceeded.
+type Provider interface {
+
+	// Name returns the name of the provider, used in logging and telemetry.
+	Name() string
+
+	// ScaleOut launches count new nodes of the node class. It should return once the nodes have
+	// been requested, rather than once they have joined the Nomad cluster.
+	ScaleOut(ctx context.Context, class string, count int) error
+
+	// InstanceID returns the ID used by the provider to identify the instance running the node.
+	InstanceID(ctx context.Context, node *Node) (string, error)
+
+	// Terminate removes the instances of the node class. The nodes running on the instances have
+	// been drained before it is called.
+	Terminate(ctx context.Context, class string, instanceIDs []string) error
+}
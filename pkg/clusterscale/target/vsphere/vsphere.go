@@ -0,0 +1,332 @@
+package vsphere
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/clusterscale/target"
+	serverCfg "github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// providerName is the name of the provider used in logging and telemetry.
+	providerName = "vsphere"
+
+	headerSessionID = "vmware-api-session-id"
+)
+
+// Config is the configuration of the vSphere node target provider.
+type Config struct {
+	// URL is the address of vCenter, and Username and Password are the credentials used to
+	// create API sessions. Insecure skips verification of the vCenter TLS certificate.
+	URL      string
+	Username string
+	Password *secret.Value
+	Insecure bool
+
+	// Classes are the node class policies, which hold the template and placement of the virtual
+	// machines cloned for each class.
+	Classes []*serverCfg.NodeClassPolicy
+
+	Logger zerolog.Logger
+}
+
+// Client is a node target provider which clones and deletes virtual machines using the vCenter
+// REST API, allowing private vSphere clusters to be scaled without an external plugin.
+//
+// The provider calls the REST API directly rather than using govmomi. It only needs to create a
+// session and clone, find, power off and delete virtual machines, which the REST API provides
+// with a handful of JSON calls, whereas govmomi would vendor the full vSphere SOAP bindings. The
+// /api endpoints used were introduced in vCenter 7.0 Update 2, so earlier versions, including
+// 6.5 and 6.7 which only provide the /rest endpoints, are not supported.
+type Client struct {
+	cfg     *Config
+	classes map[string]*serverCfg.VSphereNodeClass
+	client  *http.Client
+	logger  zerolog.Logger
+
+	lock    sync.Mutex
+	session string
+}
+
+// NewClient builds the vSphere node target provider.
+func NewClient(cfg *Config) target.Provider {
+	classes := make(map[string]*serverCfg.VSphereNodeClass)
+	for _, pol := range cfg.Classes {
+		if pol.VSphere != nil {
+			classes[pol.Class] = pol.VSphere
+		}
+	}
+
+	transport := cleanhttp.DefaultPooledTransport()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: cfg.Insecure} // nolint:gosec
+
+	return &Client{
+		cfg:     cfg,
+		classes: classes,
+		client:  &http.Client{Timeout: 30 * time.Second, Transport: transport},
+		logger:  cfg.Logger.With().Str("node-target-provider", providerName).Logger(),
+	}
+}
+
+// Name satisfies the Name function of the target.Provider interface.
+func (c *Client) Name() string { return providerName }
+
+// ScaleOut satisfies the ScaleOut function of the target.Provider interface. Each virtual machine
+// is cloned from the class template and powered on, and is named after the node class with a
+// random suffix which the guest customization of the template should use as the hostname.
+func (c *Client) ScaleOut(ctx context.Context, class string, count int) error {
+	params, ok := c.classes[class]
+	if !ok {
+		return errors.Errorf("node class %s has no vSphere clone params", class)
+	}
+
+	source, err := c.vmID(ctx, "scale-out", params.Template)
+	if err != nil {
+		return errors.Wrapf(err, "failed to find template %s", params.Template)
+	}
+
+	for i := 0; i < count; i++ {
+		name, err := vmName(class)
+		if err != nil {
+			return err
+		}
+
+		var id string
+
+		if err := c.call(ctx, "scale-out", http.MethodPost, "/api/vcenter/vm?action=clone", newCloneRequest(name, source, params), &id); err != nil {
+			return errors.Wrapf(err, "failed to clone virtual machine %d of %d", i+1, count)
+		}
+		c.logger.Info().Str("node-class", class).Str("vm", name).Str("vm-id", id).Msg("cloned vSphere virtual machine")
+	}
+	return nil
+}
+
+// InstanceID satisfies the InstanceID function of the target.Provider interface. The virtual
+// machine is identified by its name, which matches the node name as Nomad uses the hostname by
+// default.
+func (c *Client) InstanceID(ctx context.Context, node *target.Node) (string, error) {
+	return c.vmID(ctx, "instance-id", node.Name)
+}
+
+// Terminate satisfies the Terminate function of the target.Provider interface. Each virtual
+// machine is powered off and then deleted. Virtual machines which have already been deleted are
+// ignored.
+func (c *Client) Terminate(ctx context.Context, class string, instanceIDs []string) error {
+	for _, id := range instanceIDs {
+		path := "/api/vcenter/vm/" + url.PathEscape(id)
+
+		// Stopping a virtual machine which is already powered off fails with a bad request, which
+		// is ignored as the delete reports any real problem.
+		err := c.call(ctx, "terminate", http.MethodPost, path+"/power?action=stop", nil, nil)
+		if isStatus(err, http.StatusNotFound) {
+			continue
+		}
+		if err != nil && !isStatus(err, http.StatusBadRequest) {
+			return errors.Wrapf(err, "failed to power off virtual machine %s", id)
+		}
+
+		if err := c.call(ctx, "terminate", http.MethodDelete, path, nil, nil); err != nil && !isStatus(err, http.StatusNotFound) {
+			return errors.Wrapf(err, "failed to delete virtual machine %s", id)
+		}
+		c.logger.Info().Str("node-class", class).Str("vm-id", id).Msg("deleted vSphere virtual machine")
+	}
+	return nil
+}
+
+// vmID returns the ID of the virtual machine with the name.
+func (c *Client) vmID(ctx context.Context, op, name string) (string, error) {
+	var vms []struct {
+		VM   string `json:"vm"`
+		Name string `json:"name"`
+	}
+
+	if err := c.call(ctx, op, http.MethodGet, "/api/vcenter/vm?names="+url.QueryEscape(name), nil, &vms); err != nil {
+		return "", err
+	}
+
+	switch len(vms) {
+	case 0:
+		return "", errors.Errorf("no vSphere virtual machine found with name %s", name)
+	case 1:
+		return vms[0].VM, nil
+	default:
+		return "", errors.Errorf("found %d vSphere virtual machines with name %s", len(vms), name)
+	}
+}
+
+// cloneRequest is the vCenter clone virtual machine request body.
+type cloneRequest struct {
+	Name      string            `json:"name"`
+	Source    string            `json:"source"`
+	PowerOn   bool              `json:"power_on"`
+	Placement map[string]string `json:"placement,omitempty"`
+}
+
+func newCloneRequest(name, source string, params *serverCfg.VSphereNodeClass) *cloneRequest {
+	req := cloneRequest{Name: name, Source: source, PowerOn: true, Placement: make(map[string]string)}
+
+	for key, val := range map[string]string{
+		"folder":        params.Folder,
+		"resource_pool": params.ResourcePool,
+		"datastore":     params.Datastore,
+	} {
+		if val != "" {
+			req.Placement[key] = val
+		}
+	}
+	return &req
+}
+
+// vmName returns a unique name for a new virtual machine of the node class.
+func vmName(class string) (string, error) {
+	b := make([]byte, 4)
+	if _, err := rand.Read(b); err != nil {
+		return "", errors.Wrap(err, "failed to generate virtual machine name")
+	}
+	return class + "-" + hex.EncodeToString(b), nil
+}
+
+// statusError is returned when vCenter responds with an unexpected status code.
+type statusError struct {
+	code int
+	body string
+}
+
+func (e *statusError) Error() string {
+	return "unexpected response code " + http.StatusText(e.code) + ": " + e.body
+}
+
+func isStatus(err error, code int) bool {
+	se, ok := errors.Cause(err).(*statusError)
+	return ok && se.code == code
+}
+
+// call performs the vCenter API request, emitting telemetry and logging failures. Not found and
+// bad request responses are left to the caller, as they are expected during terminate.
+func (c *Client) call(ctx context.Context, op, method, path string, body, out interface{}) error {
+	defer sendMetrics.MeasureSinceWithLabels([]string{"cluster_scale", providerName, "call"}, time.Now(),
+		[]sendMetrics.Label{{Name: "operation", Value: op}})
+
+	err := c.do(ctx, method, path, body, out)
+	if err != nil && !isStatus(err, http.StatusNotFound) && !isStatus(err, http.StatusBadRequest) {
+		sendMetrics.IncrCounterWithLabels([]string{"cluster_scale", providerName, "error"}, 1,
+			[]sendMetrics.Label{{Name: "operation", Value: op}})
+		c.logger.Error().Err(err).Str("operation", op).Msg("vSphere API call failed")
+	}
+	return err
+}
+
+// do performs the vCenter API request. If the session has expired, a new session is created and
+// the request retried once.
+func (c *Client) do(ctx context.Context, method, path string, body, out interface{}) error {
+	err := c.doOnce(ctx, method, path, body, out)
+	if isStatus(err, http.StatusUnauthorized) {
+		c.lock.Lock()
+		c.session = ""
+		c.lock.Unlock()
+		err = c.doOnce(ctx, method, path, body, out)
+	}
+	return err
+}
+
+func (c *Client) doOnce(ctx context.Context, method, path string, body, out interface{}) error {
+	session, err := c.login(ctx)
+	if err != nil {
+		return err
+	}
+
+	var reader io.Reader
+	if body != nil {
+		payload, err := json.Marshal(body)
+		if err != nil {
+			return errors.Wrap(err, "failed to marshal request")
+		}
+		reader = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(c.cfg.URL, "/")+path, reader)
+	if err != nil {
+		return errors.Wrap(err, "failed to build request")
+	}
+	req.Header.Set(headerSessionID, session)
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return errors.Wrap(err, "failed to call vCenter API")
+	}
+	defer resp.Body.Close()
+
+	return decodeResponse(resp, out)
+}
+
+// login returns the current API session, creating one if there is none.
+func (c *Client) login(ctx context.Context) (string, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	if c.session != "" {
+		return c.session, nil
+	}
+
+	password, err := c.cfg.Password.Get(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to resolve vSphere password")
+	}
+
+	req, err := http.NewRequest(http.MethodPost, strings.TrimSuffix(c.cfg.URL, "/")+"/api/session", nil)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to build session request")
+	}
+	req.SetBasicAuth(c.cfg.Username, password)
+
+	resp, err := c.client.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to call vCenter API")
+	}
+	defer resp.Body.Close()
+
+	var session string
+
+	if err := decodeResponse(resp, &session); err != nil {
+		return "", errors.Wrap(err, "failed to create vCenter session")
+	}
+	if session == "" {
+		return "", errors.New("vCenter response does not include a session")
+	}
+
+	c.session = session
+	return c.session, nil
+}
+
+// decodeResponse checks the response status, decoding the body into out if it is not nil.
+func decodeResponse(resp *http.Response, out interface{}) error {
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &statusError{code: resp.StatusCode, body: strings.TrimSpace(string(body))}
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.Wrap(err, "failed to decode response")
+	}
+	return nil
+}
@@ -0,0 +1,150 @@
+package vsphere
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/clusterscale/target"
+	serverCfg "github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeVM struct {
+	name      string
+	poweredOn bool
+	clone     *cloneRequest
+}
+
+// fakeVCenter is a vCenter REST API which stores virtual machines in memory.
+type fakeVCenter struct {
+	lock     sync.Mutex
+	sessions int
+	expired  bool
+	vms      map[string]*fakeVM
+}
+
+func newFakeVCenter(t *testing.T) (*fakeVCenter, *httptest.Server) {
+	f := &fakeVCenter{vms: map[string]*fakeVM{"vm-1": {name: "nomad-client"}}}
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.lock.Lock()
+		defer f.lock.Unlock()
+
+		if r.URL.Path == "/api/session" {
+			if user, pass, ok := r.BasicAuth(); !ok || user != "sherpa" || pass != "password" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			f.sessions++
+			f.expired = false
+			w.WriteHeader(http.StatusCreated)
+			fmt.Fprintf(w, `"session-%d"`, f.sessions)
+			return
+		}
+
+		if f.expired || r.Header.Get(headerSessionID) == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		id := strings.TrimPrefix(strings.TrimSuffix(r.URL.Path, "/power"), "/api/vcenter/vm/")
+
+		switch {
+		case r.Method == http.MethodGet && r.URL.Path == "/api/vcenter/vm":
+			var out []string
+			for id, vm := range f.vms {
+				if vm.name == r.URL.Query().Get("names") {
+					out = append(out, fmt.Sprintf(`{"vm": %q, "name": %q}`, id, vm.name))
+				}
+			}
+			_, _ = w.Write([]byte(`[` + strings.Join(out, ",") + `]`))
+
+		case r.Method == http.MethodPost && r.URL.Path == "/api/vcenter/vm" && r.URL.Query().Get("action") == "clone":
+			var req cloneRequest
+			assert.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+			id := fmt.Sprintf("vm-%d", len(f.vms)+1)
+			f.vms[id] = &fakeVM{name: req.Name, poweredOn: req.PowerOn, clone: &req}
+			fmt.Fprintf(w, "%q", id)
+
+		case f.vms[id] == nil:
+			w.WriteHeader(http.StatusNotFound)
+
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/power") && r.URL.Query().Get("action") == "stop":
+			if !f.vms[id].poweredOn {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"error_type": "ALREADY_IN_DESIRED_STATE"}`))
+				return
+			}
+			f.vms[id].poweredOn = false
+			w.WriteHeader(http.StatusNoContent)
+
+		case r.Method == http.MethodDelete:
+			if f.vms[id].poweredOn {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			delete(f.vms, id)
+			w.WriteHeader(http.StatusNoContent)
+
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	return f, srv
+}
+
+func TestClient(t *testing.T) {
+	fake, srv := newFakeVCenter(t)
+	defer srv.Close()
+
+	c := NewClient(&Config{
+		URL:      srv.URL,
+		Username: "sherpa",
+		Password: secret.NewResolver(secret.Config{}, zerolog.Nop()).Value("password"),
+		Classes: []*serverCfg.NodeClassPolicy{
+			{Class: "batch", VSphere: &serverCfg.VSphereNodeClass{Template: "nomad-client", Folder: "group-v3"}},
+			{Class: "web", VSphere: &serverCfg.VSphereNodeClass{Template: "missing"}},
+		},
+		Logger: zerolog.Nop(),
+	})
+	ctx := context.Background()
+
+	assert.Nil(t, c.ScaleOut(ctx, "batch", 2))
+	assert.NotNil(t, c.ScaleOut(ctx, "web", 1))
+	assert.EqualError(t, c.ScaleOut(ctx, "gpu", 1), "node class gpu has no vSphere clone params")
+	assert.Len(t, fake.vms, 3)
+	assert.Equal(t, 1, fake.sessions)
+
+	var name string
+	for id, vm := range fake.vms {
+		if id == "vm-1" {
+			continue
+		}
+		name = vm.name
+		assert.True(t, strings.HasPrefix(name, "batch-"))
+		assert.Equal(t, &cloneRequest{Name: name, Source: "vm-1", PowerOn: true,
+			Placement: map[string]string{"folder": "group-v3"}}, vm.clone)
+	}
+
+	// An expired session is replaced and the request retried.
+	fake.expired = true
+
+	id, err := c.InstanceID(ctx, &target.Node{ID: "node-1", Name: name, NodeClass: "batch"})
+	assert.Nil(t, err)
+	assert.Equal(t, 2, fake.sessions)
+
+	_, err = c.InstanceID(ctx, &target.Node{ID: "node-2", Name: "unknown", NodeClass: "batch"})
+	assert.EqualError(t, err, "no vSphere virtual machine found with name unknown")
+
+	// Powered off and deleted virtual machines are both handled.
+	assert.Nil(t, c.Terminate(ctx, "batch", []string{id, "vm-1", "vm-99"}))
+	assert.Len(t, fake.vms, 1)
+}
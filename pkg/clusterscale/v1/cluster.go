@@ -0,0 +1,145 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+
+	"github.com/jrasell/sherpa/pkg/clusterscale"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Scaler is the interface used to read the state and scaling decisions of the cluster node
+// classes, preview the nodes selected by a cluster scale in, and handle spot interruptions.
+type Scaler interface {
+	ClassStatus(ctx context.Context) ([]*clusterscale.ClassStatus, error)
+	Decisions() []*clusterscale.ClassDecision
+	HandleInterruption(ctx context.Context, in *clusterscale.Interruption) (*clusterscale.Interruption, error)
+	Interruptions() []*clusterscale.Interruption
+	PreviewScaleIn(ctx context.Context, class string, count int) (*clusterscale.Preview, error)
+}
+
+type ClusterScale struct {
+	logger zerolog.Logger
+	scaler Scaler
+}
+
+func NewClusterScaleServer(l zerolog.Logger, s Scaler) *ClusterScale {
+	return &ClusterScale{logger: l, scaler: s}
+}
+
+// GetClasses returns the status of each node class, including its policy.
+func (c *ClusterScale) GetClasses(w http.ResponseWriter, r *http.Request) {
+	status, err := c.scaler.ClassStatus(r.Context())
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to read cluster node class status")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(status)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to marshal cluster node class status response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// GetDecisions returns the latest scaling decision of each node class.
+func (c *ClusterScale) GetDecisions(w http.ResponseWriter, r *http.Request) {
+	bytes, err := json.Marshal(c.scaler.Decisions())
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to marshal cluster scaling decisions response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// GetInterruptions returns the spot interruptions received, and the action taken for each.
+func (c *ClusterScale) GetInterruptions(w http.ResponseWriter, r *http.Request) {
+	bytes, err := json.Marshal(c.scaler.Interruptions())
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to marshal cluster interruptions response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// PostInterruption drains the Nomad node of the instance within the AWS EventBridge spot
+// interruption warning in the request body.
+func (c *ClusterScale) PostInterruption(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "failed to read request body", http.StatusBadRequest)
+		return
+	}
+
+	in, err := clusterscale.ParseSpotInterruption(body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	out, err := c.scaler.HandleInterruption(r.Context(), in)
+	switch {
+	case err == clusterscale.ErrInterruptionNodeNotFound:
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	case err != nil:
+		c.logger.Error().Err(err).Str("instance-id", in.InstanceID).Msg("failed to handle spot interruption")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(out)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to marshal cluster interruption response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// PreviewScaleIn returns the scored scale in candidates, and the nodes which would be selected to
+// remove the requested count of nodes. The count defaults to 1, and the candidates can be limited
+// to a node class using the class query param.
+func (c *ClusterScale) PreviewScaleIn(w http.ResponseWriter, r *http.Request) {
+	count := 1
+
+	if v := r.URL.Query().Get("count"); v != "" {
+		var err error
+		if count, err = strconv.Atoi(v); err != nil || count < 1 {
+			http.Error(w, "count query param must be a positive integer", http.StatusBadRequest)
+			return
+		}
+	}
+
+	preview, err := c.scaler.PreviewScaleIn(r.Context(), r.URL.Query().Get("class"), count)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to preview cluster scale in")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(preview)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("failed to marshal cluster scale in preview response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+func writeJSONResponse(w http.ResponseWriter, bytes []byte, statusCode int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(bytes); err != nil {
+		log.Error().Err(err).Msg("failed to write JSON response")
+	}
+}
@@ -0,0 +1,156 @@
+package v1
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/clusterscale"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeScaler struct {
+	class string
+	count int
+	err   error
+}
+
+func (f *fakeScaler) ClassStatus(_ context.Context) ([]*clusterscale.ClassStatus, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return []*clusterscale.ClassStatus{{Class: "batch", Nodes: 3, Utilization: 0.5, DesiredNodes: 2}}, nil
+}
+
+func (f *fakeScaler) Decisions() []*clusterscale.ClassDecision {
+	return []*clusterscale.ClassDecision{{Class: "batch", Direction: scale.DirectionNone, TargetNodes: 3, Nodes: 3,
+		DesiredNodes: 2, Reason: "stabilization window desires 3 nodes", Time: time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)}}
+}
+
+func (f *fakeScaler) HandleInterruption(_ context.Context, in *clusterscale.Interruption) (*clusterscale.Interruption, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	out := *in
+	out.NodeID, out.NodeClass, out.Drained = "node-1", "batch", true
+	return &out, nil
+}
+
+func (f *fakeScaler) Interruptions() []*clusterscale.Interruption { return nil }
+
+func (f *fakeScaler) PreviewScaleIn(_ context.Context, class string, count int) (*clusterscale.Preview, error) {
+	f.class, f.count = class, count
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &clusterscale.Preview{Count: count, Selected: []string{"node-1"}}, nil
+}
+
+func TestClusterScale_GetClasses(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewClusterScaleServer(zerolog.Nop(), &fakeScaler{}).GetClasses(w, httptest.NewRequest(http.MethodGet, "/v1/cluster/classes", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"Class":"batch","Nodes":3,"Utilization":0.5,"DesiredNodes":2,"Interrupted":0,"Policy":null}]`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	NewClusterScaleServer(zerolog.Nop(), &fakeScaler{err: errors.New("nomad unavailable")}).GetClasses(w, httptest.NewRequest(http.MethodGet, "/v1/cluster/classes", nil))
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestClusterScale_GetDecisions(t *testing.T) {
+	w := httptest.NewRecorder()
+	NewClusterScaleServer(zerolog.Nop(), &fakeScaler{}).GetDecisions(w, httptest.NewRequest(http.MethodGet, "/v1/cluster/decisions", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `[{"Class":"batch","Direction":"none","TargetNodes":3,"Nodes":3,"DesiredNodes":2,
+		"Reason":"stabilization window desires 3 nodes","Time":"2020-01-26T10:00:00Z"}]`, w.Body.String())
+}
+
+func TestClusterScale_PreviewScaleIn(t *testing.T) {
+	testCases := []struct {
+		url                string
+		err                error
+		expectedStatusCode int
+		expectedClass      string
+		expectedCount      int
+		name               string
+	}{
+		{
+			url:                "/v1/cluster/scale-in/preview",
+			expectedStatusCode: http.StatusOK,
+			expectedCount:      1,
+			name:               "default count",
+		},
+		{
+			url:                "/v1/cluster/scale-in/preview?count=3&class=batch",
+			expectedStatusCode: http.StatusOK,
+			expectedClass:      "batch",
+			expectedCount:      3,
+			name:               "count and class",
+		},
+		{
+			url:                "/v1/cluster/scale-in/preview?count=0",
+			expectedStatusCode: http.StatusBadRequest,
+			name:               "invalid count",
+		},
+		{
+			url:                "/v1/cluster/scale-in/preview",
+			err:                errors.New("nomad unavailable"),
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedCount:      1,
+			name:               "scaler error",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			fake := &fakeScaler{err: tc.err}
+
+			w := httptest.NewRecorder()
+			NewClusterScaleServer(zerolog.Nop(), fake).PreviewScaleIn(w, httptest.NewRequest(http.MethodGet, tc.url, nil))
+			assert.Equal(t, tc.expectedStatusCode, w.Code)
+			assert.Equal(t, tc.expectedClass, fake.class)
+			assert.Equal(t, tc.expectedCount, fake.count)
+		})
+	}
+}
+
+func TestClusterScale_PostInterruption(t *testing.T) {
+	event := `{"detail-type":"EC2 Spot Instance Interruption Warning","time":"2020-01-26T10:00:00Z",
+		"detail":{"instance-id":"i-1234567890abcdef0","instance-action":"terminate"}}`
+
+	testCases := []struct {
+		name         string
+		body         string
+		err          error
+		expectedCode int
+		expectedBody string
+	}{
+		{
+			name:         "drained",
+			body:         event,
+			expectedCode: http.StatusOK,
+			expectedBody: `{"InstanceID":"i-1234567890abcdef0","Action":"terminate","NodeID":"node-1","NodeClass":"batch",
+				"NoticeTime":"2020-01-26T10:00:00Z","Deadline":"2020-01-26T10:02:00Z","Drained":true}`,
+		},
+		{name: "unsupported event", body: `{"detail-type":"EC2 Instance State-change Notification"}`, expectedCode: http.StatusBadRequest},
+		{name: "unknown instance", body: event, err: clusterscale.ErrInterruptionNodeNotFound, expectedCode: http.StatusNotFound},
+		{name: "drain failure", body: event, err: errors.New("permission denied"), expectedCode: http.StatusInternalServerError},
+	}
+
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		NewClusterScaleServer(zerolog.Nop(), &fakeScaler{err: tc.err}).PostInterruption(w,
+			httptest.NewRequest(http.MethodPost, "/v1/cluster/interruptions", strings.NewReader(tc.body)))
+
+		assert.Equal(t, tc.expectedCode, w.Code, tc.name)
+		if tc.expectedBody != "" {
+			assert.JSONEq(t, tc.expectedBody, w.Body.String(), tc.name)
+		}
+	}
+}
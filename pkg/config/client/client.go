@@ -12,6 +12,9 @@ const (
 	configKeySherpaClientCertPath    = "client-cert-path"
 	configKeySherpaClientCertKeyPath = "client-cert-key-path"
 	configKeySherpaCAPath            = "client-ca-path"
+
+	configKeySherpaTimeFormat        = "time-format"
+	configKeySherpaTimeFormatDefault = "utc"
 )
 
 type Config struct {
@@ -19,6 +22,7 @@ type Config struct {
 	CertPath    string
 	CertKeyPath string
 	CAPath      string
+	TimeFormat  string
 }
 
 func GetConfig() Config {
@@ -27,6 +31,7 @@ func GetConfig() Config {
 		CertPath:    viper.GetString(configKeySherpaClientCertPath),
 		CertKeyPath: viper.GetString(configKeySherpaClientCertKeyPath),
 		CAPath:      viper.GetString(configKeySherpaCAPath),
+		TimeFormat:  viper.GetString(configKeySherpaTimeFormat),
 	}
 }
 
@@ -84,4 +89,17 @@ func RegisterConfig(cmd *cobra.Command) {
 		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
 		viper.SetDefault(key, defaultValue)
 	}
+
+	{
+		const (
+			key          = configKeySherpaTimeFormat
+			longOpt      = "time-format"
+			defaultValue = configKeySherpaTimeFormatDefault
+			description  = "The format of timestamps within command output; one of utc, rfc3339, local or relative"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
 }
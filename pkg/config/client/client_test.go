@@ -11,4 +11,5 @@ func Test_SherpaConfig(t *testing.T) {
 	fakeCMD := &cobra.Command{}
 	RegisterConfig(fakeCMD)
 	assert.Equal(t, configKeySherpaAddrDefault, GetConfig().Addr)
+	assert.Equal(t, configKeySherpaTimeFormatDefault, GetConfig().TimeFormat)
 }
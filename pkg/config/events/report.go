@@ -0,0 +1,69 @@
+package events
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	configKeyEventsReportFrom          = "from"
+	configKeyEventsReportTo            = "to"
+	configKeyEventsReportFlapThreshold = "flap-threshold"
+)
+
+type ReportConfig struct {
+	From          string
+	To            string
+	FlapThreshold int
+}
+
+func GetEventsReportConfig() *ReportConfig {
+	return &ReportConfig{
+		From:          viper.GetString(configKeyEventsReportFrom),
+		To:            viper.GetString(configKeyEventsReportTo),
+		FlapThreshold: viper.GetInt(configKeyEventsReportFlapThreshold),
+	}
+}
+
+func RegisterEventsReportConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key          = configKeyEventsReportFrom
+			longOpt      = "from"
+			defaultValue = ""
+			description  = "The RFC3339 start time of the report range, defaults to 24 hours before the end"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyEventsReportTo
+			longOpt      = "to"
+			defaultValue = ""
+			description  = "The RFC3339 end time of the report range, defaults to now"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyEventsReportFlapThreshold
+			longOpt      = "flap-threshold"
+			defaultValue = 0
+			description  = "The number of scaling direction changes at which a group is marked as flapping, 0 uses the server default"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
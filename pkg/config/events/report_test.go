@@ -0,0 +1,18 @@
+package events
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_EventsReportConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterEventsReportConfig(fakeCMD)
+
+	cfg := GetEventsReportConfig()
+	assert.Equal(t, "", cfg.From)
+	assert.Equal(t, "", cfg.To)
+	assert.Equal(t, 0, cfg.FlapThreshold)
+}
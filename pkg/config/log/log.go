@@ -13,13 +13,39 @@ type Config struct {
 	LogFormat string
 	EnableDev bool
 	UseColor  bool
+
+	// File is the path of a file which logs are also written to. FileMaxSize is the size in MB,
+	// and FileMaxAge the age in hours, at which the file is rotated. FileMaxBackups is the number
+	// of rotated files to keep.
+	File           string
+	FileMaxSize    int
+	FileMaxAge     int
+	FileMaxBackups int
+
+	// SyslogEnabled configures logs to also be written to the local syslog daemon, using the
+	// SyslogFacility and SyslogTag.
+	SyslogEnabled  bool
+	SyslogFacility string
+	SyslogTag      string
+
+	// StaticFields are additional key=value pairs, separated by commas, which are added to every
+	// log line such as to identify the cluster or region of the server.
+	StaticFields string
 }
 
 const (
-	configKeyLogLevel     = "log-level"
-	configKeyLogFormat    = "log-format"
-	configKeyLogEnableDev = "log-enable-dev"
-	configKeyUseColor     = "log-use-color"
+	configKeyLogLevel          = "log-level"
+	configKeyLogFormat         = "log-format"
+	configKeyLogEnableDev      = "log-enable-dev"
+	configKeyUseColor          = "log-use-color"
+	configKeyLogFile           = "log-file"
+	configKeyLogFileMaxSize    = "log-file-max-size"
+	configKeyLogFileMaxAge     = "log-file-max-age"
+	configKeyLogFileMaxBackups = "log-file-max-backups"
+	configKeySyslogEnabled     = "log-syslog-enabled"
+	configKeySyslogFacility    = "log-syslog-facility"
+	configKeySyslogTag         = "log-syslog-tag"
+	configKeyStaticFields      = "log-static-fields"
 )
 
 func GetConfig() Config {
@@ -28,6 +54,15 @@ func GetConfig() Config {
 		LogFormat: viper.GetString(configKeyLogFormat),
 		EnableDev: viper.GetBool(configKeyLogEnableDev),
 		UseColor:  viper.GetBool(configKeyUseColor),
+
+		File:           viper.GetString(configKeyLogFile),
+		FileMaxSize:    viper.GetInt(configKeyLogFileMaxSize),
+		FileMaxAge:     viper.GetInt(configKeyLogFileMaxAge),
+		FileMaxBackups: viper.GetInt(configKeyLogFileMaxBackups),
+		SyslogEnabled:  viper.GetBool(configKeySyslogEnabled),
+		SyslogFacility: viper.GetString(configKeySyslogFacility),
+		SyslogTag:      viper.GetString(configKeySyslogTag),
+		StaticFields:   viper.GetString(configKeyStaticFields),
 	}
 }
 
@@ -52,7 +87,7 @@ func RegisterConfig(cmd *cobra.Command) {
 			key          = configKeyLogFormat
 			longOpt      = "log-format"
 			defaultValue = "auto"
-			description  = `Specify the log format ("auto", "zerolog" or "human")`
+			description  = `Specify the log format ("auto", "json" or "console")`
 		)
 
 		flags.String(longOpt, defaultValue, description)
@@ -87,4 +122,108 @@ func RegisterConfig(cmd *cobra.Command) {
 		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
 		viper.SetDefault(key, defaultValue)
 	}
+
+	{
+		const (
+			key          = configKeyLogFile
+			longOpt      = "log-file"
+			defaultValue = ""
+			description  = "The path of a file to write logs to in addition to stderr"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyLogFileMaxSize
+			longOpt      = "log-file-max-size"
+			defaultValue = 100
+			description  = "The size in MB at which the log file is rotated, 0 disables size based rotation"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyLogFileMaxAge
+			longOpt      = "log-file-max-age"
+			defaultValue = 24
+			description  = "The age in hours at which the log file is rotated, 0 disables age based rotation"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyLogFileMaxBackups
+			longOpt      = "log-file-max-backups"
+			defaultValue = 5
+			description  = "The number of rotated log files to keep, 0 keeps all files"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeySyslogEnabled
+			longOpt      = "log-syslog-enabled"
+			defaultValue = false
+			description  = "Write logs to the local syslog daemon in addition to stderr"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeySyslogFacility
+			longOpt      = "log-syslog-facility"
+			defaultValue = "LOCAL0"
+			description  = "The syslog facility to write logs with"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeySyslogTag
+			longOpt      = "log-syslog-tag"
+			defaultValue = "sherpa"
+			description  = "The tag to write syslog messages with"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyStaticFields
+			longOpt      = "log-static-fields"
+			defaultValue = ""
+			description  = "Comma separated key=value fields to add to all log lines, such as cluster=prod"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
 }
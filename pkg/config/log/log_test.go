@@ -16,4 +16,12 @@ func Test_ServerConfig(t *testing.T) {
 	assert.Equal(t, "auto", cfg.LogFormat)
 	assert.Equal(t, false, cfg.EnableDev)
 	assert.Equal(t, false, cfg.UseColor)
+	assert.Equal(t, "", cfg.File)
+	assert.Equal(t, 100, cfg.FileMaxSize)
+	assert.Equal(t, 24, cfg.FileMaxAge)
+	assert.Equal(t, 5, cfg.FileMaxBackups)
+	assert.Equal(t, false, cfg.SyslogEnabled)
+	assert.Equal(t, "LOCAL0", cfg.SyslogFacility)
+	assert.Equal(t, "sherpa", cfg.SyslogTag)
+	assert.Equal(t, "", cfg.StaticFields)
 }
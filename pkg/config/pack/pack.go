@@ -0,0 +1,52 @@
+package pack
+
+import (
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	configKeyPackApplyVar    = "pack-var"
+	configKeyPackApplyDryRun = "pack-dry-run"
+)
+
+type ApplyConfig struct {
+	Vars   []string
+	DryRun bool
+}
+
+func GetApplyConfig() *ApplyConfig {
+	return &ApplyConfig{
+		Vars:   viper.GetStringSlice(configKeyPackApplyVar),
+		DryRun: viper.GetBool(configKeyPackApplyDryRun),
+	}
+}
+
+func RegisterApplyConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key         = configKeyPackApplyVar
+			longOpt     = "var"
+			description = "Set a pack variable, in the form key=value; can be specified multiple times"
+		)
+
+		flags.StringSlice(longOpt, []string{}, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, []string{})
+	}
+
+	{
+		const (
+			key          = configKeyPackApplyDryRun
+			longOpt      = "dry-run"
+			defaultValue = false
+			description  = "Print the rendered policies, without writing them"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
@@ -0,0 +1,17 @@
+package pack
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PackApplyConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterApplyConfig(fakeCMD)
+
+	cfg := GetApplyConfig()
+	assert.Equal(t, []string{}, cfg.Vars)
+	assert.False(t, cfg.DryRun)
+}
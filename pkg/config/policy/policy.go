@@ -35,3 +35,143 @@ func RegisterConfig(cmd *cobra.Command) {
 		viper.SetDefault(key, defaultValue)
 	}
 }
+
+const (
+	configKeyPolicyBulkDeleteJobPrefix = "job-prefix"
+	configKeyPolicyBulkDeleteLabel     = "label"
+	configKeyPolicyBulkDeleteDryRun    = "dry-run"
+)
+
+type BulkDeleteConfig struct {
+	JobPrefix string
+	Labels    []string
+	DryRun    bool
+}
+
+func GetBulkDeleteConfig() *BulkDeleteConfig {
+	return &BulkDeleteConfig{
+		JobPrefix: viper.GetString(configKeyPolicyBulkDeleteJobPrefix),
+		Labels:    viper.GetStringSlice(configKeyPolicyBulkDeleteLabel),
+		DryRun:    viper.GetBool(configKeyPolicyBulkDeleteDryRun),
+	}
+}
+
+func RegisterBulkDeleteConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key          = configKeyPolicyBulkDeleteJobPrefix
+			longOpt      = "job-prefix"
+			defaultValue = ""
+			description  = "Delete the policies of jobs whose ID starts with the prefix"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key         = configKeyPolicyBulkDeleteLabel
+			longOpt     = "label"
+			description = "Delete the policies with the label, in the form key=value; can be specified multiple times"
+		)
+
+		flags.StringSlice(longOpt, []string{}, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, []string{})
+	}
+
+	{
+		const (
+			key          = configKeyPolicyBulkDeleteDryRun
+			longOpt      = "dry-run"
+			defaultValue = false
+			description  = "List the policies which would be deleted, without deleting them"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
+
+const (
+	configKeyPolicyWriteCanaryPercentage = "canary-percentage"
+)
+
+type WriteConfig struct {
+	CanaryPercentage int
+}
+
+func GetWriteConfig() *WriteConfig {
+	return &WriteConfig{
+		CanaryPercentage: viper.GetInt(configKeyPolicyWriteCanaryPercentage),
+	}
+}
+
+func RegisterWriteConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key          = configKeyPolicyWriteCanaryPercentage
+			longOpt      = "canary-percentage"
+			defaultValue = 0
+			description  = "Apply the job policy to this percentage of the changed groups, until the rollout is promoted"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
+
+const (
+	configKeyPolicyRolloutPromote = "promote"
+	configKeyPolicyRolloutAbort   = "abort"
+)
+
+type RolloutConfig struct {
+	Promote bool
+	Abort   bool
+}
+
+func GetRolloutConfig() *RolloutConfig {
+	return &RolloutConfig{
+		Promote: viper.GetBool(configKeyPolicyRolloutPromote),
+		Abort:   viper.GetBool(configKeyPolicyRolloutAbort),
+	}
+}
+
+func RegisterRolloutConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key          = configKeyPolicyRolloutPromote
+			longOpt      = "promote"
+			defaultValue = false
+			description  = "Apply the new policy of the job rollout to all of its groups"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyPolicyRolloutAbort
+			longOpt      = "abort"
+			defaultValue = false
+			description  = "Abort the job rollout, restoring the previous policy of the canary groups"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
@@ -14,3 +14,30 @@ func Test_PolicyConfig(t *testing.T) {
 	cfg := GetConfig()
 	assert.Equal(t, "", cfg.GroupName)
 }
+
+func Test_PolicyBulkDeleteConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterBulkDeleteConfig(fakeCMD)
+
+	cfg := GetBulkDeleteConfig()
+	assert.Equal(t, "", cfg.JobPrefix)
+	assert.Equal(t, []string{}, cfg.Labels)
+	assert.False(t, cfg.DryRun)
+}
+
+func Test_PolicyWriteConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterWriteConfig(fakeCMD)
+
+	cfg := GetWriteConfig()
+	assert.Equal(t, 0, cfg.CanaryPercentage)
+}
+
+func Test_PolicyRolloutConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterRolloutConfig(fakeCMD)
+
+	cfg := GetRolloutConfig()
+	assert.False(t, cfg.Promote)
+	assert.False(t, cfg.Abort)
+}
@@ -8,6 +8,7 @@ import (
 )
 
 const (
+	configKeyScaleCooldown        = "cooldown"
 	configKeyScaleCount           = "count"
 	configKeyScaleGroupName       = "group-name"
 	configKeyScaleMeta            = "meta"
@@ -16,6 +17,7 @@ const (
 )
 
 type Config struct {
+	Cooldown  string
 	Count     int
 	GroupName string
 	Meta      map[string]string
@@ -23,6 +25,7 @@ type Config struct {
 
 func GetScaleConfig() Config {
 	return Config{
+		Cooldown:  viper.GetString(configKeyScaleCooldown),
 		Count:     viper.GetInt(configKeyScaleCount),
 		GroupName: viper.GetString(configKeyScaleGroupName),
 		Meta:      parseMetaMap(viper.GetString(configKeyScaleMeta)),
@@ -48,6 +51,19 @@ func parseMetaMap(raw string) map[string]string {
 func RegisterScaleConfig(cmd *cobra.Command) {
 	flags := cmd.PersistentFlags()
 
+	{
+		const (
+			key          = configKeyScaleCooldown
+			longOpt      = "cooldown"
+			defaultValue = ""
+			description  = "Override an active job group scaling cooldown, either bypass or reset"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
 	{
 		const (
 			key          = configKeyScaleCount
@@ -87,3 +103,34 @@ func RegisterScaleConfig(cmd *cobra.Command) {
 		viper.SetDefault(key, defaultValue)
 	}
 }
+
+const (
+	configKeyScaleToForce = "force"
+)
+
+type ToConfig struct {
+	Force bool
+}
+
+func GetScaleToConfig() ToConfig {
+	return ToConfig{
+		Force: viper.GetBool(configKeyScaleToForce),
+	}
+}
+
+func RegisterScaleToConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key          = configKeyScaleToForce
+			longOpt      = "force"
+			defaultValue = false
+			description  = "Scale the job group to the count even if it is outside of the policy bounds"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
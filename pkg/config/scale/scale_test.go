@@ -12,6 +12,15 @@ func Test_ScaleConfig(t *testing.T) {
 	RegisterScaleConfig(fakeCMD)
 
 	cfg := GetScaleConfig()
+	assert.Equal(t, "", cfg.Cooldown)
 	assert.Equal(t, 0, cfg.Count)
 	assert.Equal(t, "", cfg.GroupName)
 }
+
+func Test_ScaleToConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterScaleToConfig(fakeCMD)
+
+	cfg := GetScaleToConfig()
+	assert.False(t, cfg.Force)
+}
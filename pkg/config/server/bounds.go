@@ -0,0 +1,33 @@
+package server
+
+import "github.com/pkg/errors"
+
+// BoundsEnforcement is the action taken by the autoscaler when a job group count is found to be
+// outside the min and max counts of its scaling policy, such as after a manual job update.
+type BoundsEnforcement string
+
+const (
+	// BoundsEnforcementDisabled means group counts are only checked against the policy bounds
+	// when scaling is triggered by a metric threshold breach.
+	BoundsEnforcementDisabled BoundsEnforcement = "disabled"
+
+	// BoundsEnforcementAlert means group counts outside the policy bounds are logged and reported
+	// via telemetry, but not corrected.
+	BoundsEnforcementAlert BoundsEnforcement = "alert"
+
+	// BoundsEnforcementCorrect means group counts outside the policy bounds are scaled back to the
+	// nearest bound.
+	BoundsEnforcementCorrect BoundsEnforcement = "correct"
+)
+
+func (b BoundsEnforcement) String() string { return string(b) }
+
+// Validate checks the bounds enforcement mode is a supported value.
+func (b BoundsEnforcement) Validate() error {
+	switch b {
+	case BoundsEnforcementDisabled, BoundsEnforcementAlert, BoundsEnforcementCorrect:
+		return nil
+	default:
+		return errors.Errorf("unsupported autoscaler bounds enforcement mode %q", b)
+	}
+}
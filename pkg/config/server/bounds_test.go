@@ -0,0 +1,14 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundsEnforcement_Validate(t *testing.T) {
+	assert.Nil(t, BoundsEnforcementDisabled.Validate())
+	assert.Nil(t, BoundsEnforcementAlert.Validate())
+	assert.Nil(t, BoundsEnforcementCorrect.Validate())
+	assert.EqualError(t, BoundsEnforcement("fix").Validate(), `unsupported autoscaler bounds enforcement mode "fix"`)
+}
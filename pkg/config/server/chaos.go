@@ -0,0 +1,91 @@
+package server
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	configKeyChaosEnabled     = "chaos-enabled"
+	configKeyChaosFailureRate = "chaos-failure-rate"
+	configKeyChaosMaxDelay    = "chaos-max-delay"
+)
+
+// ChaosConfig is the server fault injection configuration struct. Fault injection is intended for
+// resilience testing only, and should never be enabled on production servers.
+type ChaosConfig struct {
+	// Enabled indicates whether faults should be injected into Nomad, policy backend and metric
+	// provider calls.
+	Enabled bool
+
+	// FailureRate is the probability, between 0 and 1, of a call failing.
+	FailureRate float64
+
+	// MaxDelay is the maximum time in milliseconds a call will be randomly delayed by.
+	MaxDelay int
+}
+
+// MarshalZerologObject is the Zerolog marshaller which allow us to log the object.
+func (c *ChaosConfig) MarshalZerologObject(e *zerolog.Event) {
+	e.Bool(configKeyChaosEnabled, c.Enabled).
+		Float64(configKeyChaosFailureRate, c.FailureRate).
+		Int(configKeyChaosMaxDelay, c.MaxDelay)
+}
+
+// GetChaosConfig hydrates the chaos config struct.
+func GetChaosConfig() ChaosConfig {
+	return ChaosConfig{
+		Enabled:     viper.GetBool(configKeyChaosEnabled),
+		FailureRate: viper.GetFloat64(configKeyChaosFailureRate),
+		MaxDelay:    viper.GetInt(configKeyChaosMaxDelay),
+	}
+}
+
+// RegisterChaosConfig is used by a Cobra command to register the fault injection CLI flags. The
+// flags are hidden from the command help as they are only intended for resilience testing.
+func RegisterChaosConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key          = configKeyChaosEnabled
+			longOpt      = "chaos-enabled"
+			defaultValue = false
+			description  = "Enable fault injection into Nomad, policy backend and metric provider calls for resilience testing"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = flags.MarkHidden(longOpt)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyChaosFailureRate
+			longOpt      = "chaos-failure-rate"
+			defaultValue = 0.1
+			description  = "The probability, between 0 and 1, of an injected call failing"
+		)
+
+		flags.Float64(longOpt, defaultValue, description)
+		_ = flags.MarkHidden(longOpt)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyChaosMaxDelay
+			longOpt      = "chaos-max-delay"
+			defaultValue = 1000
+			description  = "The maximum time in milliseconds an injected call is randomly delayed by"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = flags.MarkHidden(longOpt)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
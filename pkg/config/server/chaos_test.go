@@ -0,0 +1,18 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ChaosConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterChaosConfig(fakeCMD)
+
+	cfg := GetChaosConfig()
+	assert.False(t, cfg.Enabled)
+	assert.Equal(t, 0.1, cfg.FailureRate)
+	assert.Equal(t, 1000, cfg.MaxDelay)
+}
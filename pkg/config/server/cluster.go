@@ -10,24 +10,62 @@ const (
 	configKeyClusterAdvertiseAddrDefault = "http://127.0.0.1:8000"
 	configKeyClusterAdvertiseAddr        = "cluster-advertise-addr"
 	configKeyClusterName                 = "cluster-name"
+	configKeyClusterShardingEnabled      = "cluster-sharding-enabled"
+	configKeyClusterGossipEnabled        = "cluster-gossip-enabled"
+	configKeyClusterGossipBindAddr       = "cluster-gossip-bind-addr"
+	configKeyClusterGossipPort           = "cluster-gossip-port"
+	configKeyClusterGossipJoin           = "cluster-gossip-join"
+	configKeyClusterGossipKey            = "cluster-gossip-key"
+	configKeyClusterHandover             = "cluster-handover"
 )
 
 type ClusterConfig struct {
 	Addr string
 	Name string
+
+	// Sharding enables the sharding of autoscaling evaluations across all healthy cluster
+	// members, rather than the leader performing all evaluations.
+	Sharding bool
+
+	// Gossip enables the gossip layer used by servers to discover each other and share their
+	// health. GossipBindAddr and GossipPort are the address and port gossip listens on,
+	// GossipJoin are the gossip addresses of the servers contacted to join the cluster, and
+	// GossipKey is the optional shared key used to encrypt gossip.
+	Gossip         bool
+	GossipBindAddr string
+	GossipPort     int
+	GossipJoin     []string
+	GossipKey      string
+
+	// Handover requests that the cluster leader hands over leadership to this server once it has
+	// started, allowing rolling upgrades without a gap in autoscaling evaluation.
+	Handover bool
 }
 
 // MarshalZerologObject is the Zerolog marshaller which allow us to log the
-// object.
+// object. The gossip key is not logged.
 func (c *ClusterConfig) MarshalZerologObject(e *zerolog.Event) {
 	e.Str(configKeyClusterAdvertiseAddr, c.Addr).
-		Str(configKeyClusterName, c.Name)
+		Str(configKeyClusterName, c.Name).
+		Bool(configKeyClusterShardingEnabled, c.Sharding).
+		Bool(configKeyClusterGossipEnabled, c.Gossip).
+		Str(configKeyClusterGossipBindAddr, c.GossipBindAddr).
+		Int(configKeyClusterGossipPort, c.GossipPort).
+		Strs(configKeyClusterGossipJoin, c.GossipJoin).
+		Bool(configKeyClusterHandover, c.Handover)
 }
 
 func GetClusterConfig() ClusterConfig {
 	return ClusterConfig{
-		Addr: viper.GetString(configKeyClusterAdvertiseAddr),
-		Name: viper.GetString(configKeyClusterName),
+		Addr:           viper.GetString(configKeyClusterAdvertiseAddr),
+		Name:           viper.GetString(configKeyClusterName),
+		Sharding:       viper.GetBool(configKeyClusterShardingEnabled),
+		Gossip:         viper.GetBool(configKeyClusterGossipEnabled),
+		GossipBindAddr: viper.GetString(configKeyClusterGossipBindAddr),
+		GossipPort:     viper.GetInt(configKeyClusterGossipPort),
+		GossipJoin:     splitList(viper.GetString(configKeyClusterGossipJoin)),
+		GossipKey:      viper.GetString(configKeyClusterGossipKey),
+		Handover:       viper.GetBool(configKeyClusterHandover),
 	}
 }
 
@@ -59,4 +97,95 @@ func RegisterClusterConfig(cmd *cobra.Command) {
 		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
 		viper.SetDefault(key, defaultValue)
 	}
+
+	{
+		const (
+			key          = configKeyClusterShardingEnabled
+			longOpt      = "cluster-sharding-enabled"
+			defaultValue = false
+			description  = "Shard autoscaling evaluations across all healthy cluster members"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterGossipEnabled
+			longOpt      = "cluster-gossip-enabled"
+			defaultValue = false
+			description  = "Enable the gossip layer used by servers to discover each other and share health"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterGossipBindAddr
+			longOpt      = "cluster-gossip-bind-addr"
+			defaultValue = "0.0.0.0"
+			description  = "The address the gossip layer listens on"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterGossipPort
+			longOpt      = "cluster-gossip-port"
+			defaultValue = 7946
+			description  = "The TCP and UDP port the gossip layer listens on"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterGossipJoin
+			longOpt      = "cluster-gossip-join"
+			defaultValue = ""
+			description  = "Comma separated gossip addresses of Sherpa servers to contact when joining the cluster"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterGossipKey
+			longOpt      = "cluster-gossip-key"
+			defaultValue = ""
+			description  = "The shared key used to encrypt gossip between servers"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterHandover
+			longOpt      = "cluster-handover"
+			defaultValue = false
+			description  = "Request the cluster leader hands over leadership to this server once started"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
 }
@@ -14,4 +14,11 @@ func Test_ClusterConfig(t *testing.T) {
 	cfg := GetClusterConfig()
 	assert.Equal(t, configKeyClusterAdvertiseAddrDefault, cfg.Addr)
 	assert.Equal(t, "", cfg.Name)
+	assert.False(t, cfg.Sharding)
+	assert.False(t, cfg.Gossip)
+	assert.Equal(t, "0.0.0.0", cfg.GossipBindAddr)
+	assert.Equal(t, 7946, cfg.GossipPort)
+	assert.Nil(t, cfg.GossipJoin)
+	assert.Equal(t, "", cfg.GossipKey)
+	assert.False(t, cfg.Handover)
 }
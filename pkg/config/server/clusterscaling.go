@@ -0,0 +1,551 @@
+package server
+
+import (
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	configKeyClusterScalingScaleInWeightEmpty       = "cluster-scaling-scale-in-weight-empty"
+	configKeyClusterScalingScaleInWeightAllocations = "cluster-scaling-scale-in-weight-allocations"
+	configKeyClusterScalingScaleInWeightUtilization = "cluster-scaling-scale-in-weight-utilization"
+	configKeyClusterScalingScaleInWeightAge         = "cluster-scaling-scale-in-weight-age"
+	configKeyClusterScalingNodeClassesFile          = "cluster-scaling-node-classes-file"
+	configKeyClusterScalingExcludedJobs             = "cluster-scaling-excluded-jobs"
+	configKeyClusterScalingEvaluationInterval       = "cluster-scaling-evaluation-interval"
+	configKeyClusterScalingScaleOutCooldown         = "cluster-scaling-scale-out-cooldown"
+	configKeyClusterScalingScaleInCooldown          = "cluster-scaling-scale-in-cooldown"
+	configKeyClusterScalingStabilizationWindow      = "cluster-scaling-stabilization-window"
+	configKeyClusterScalingSpotReplace              = "cluster-scaling-spot-replace"
+	configKeyClusterScalingSoftScaleIn              = "cluster-scaling-soft-scale-in"
+	configKeyClusterScalingTargetPlugin             = "cluster-scaling-target-plugin"
+	configKeyClusterScalingTargetPluginArgs         = "cluster-scaling-target-plugin-args"
+	configKeyClusterScalingDrainDeadline            = "cluster-scaling-drain-deadline"
+	configKeyClusterScalingOpenStackAuthURL         = "cluster-scaling-openstack-auth-url"
+	configKeyClusterScalingOpenStackUsername        = "cluster-scaling-openstack-username"
+	configKeyClusterScalingOpenStackPassword        = "cluster-scaling-openstack-password"
+	configKeyClusterScalingOpenStackProjectID       = "cluster-scaling-openstack-project-id"
+	configKeyClusterScalingOpenStackUserDomain      = "cluster-scaling-openstack-user-domain"
+	configKeyClusterScalingOpenStackRegion          = "cluster-scaling-openstack-region"
+	configKeyClusterScalingVSphereURL               = "cluster-scaling-vsphere-url"
+	configKeyClusterScalingVSphereUsername          = "cluster-scaling-vsphere-username"
+	configKeyClusterScalingVSpherePassword          = "cluster-scaling-vsphere-password"
+	configKeyClusterScalingVSphereInsecure          = "cluster-scaling-vsphere-insecure"
+)
+
+// ClusterScalingConfig is the configuration of the experimental scaling of the Nomad client nodes
+// of the cluster, which is enabled using the cluster-scaling feature flag.
+type ClusterScalingConfig struct {
+	// ScaleInWeightEmpty, ScaleInWeightAllocations, ScaleInWeightUtilization and ScaleInWeightAge
+	// are the weights of each factor used to score the nodes which are candidates for removal
+	// during scale in. A weight of 0 disables the factor.
+	ScaleInWeightEmpty       float64
+	ScaleInWeightAllocations float64
+	ScaleInWeightUtilization float64
+	ScaleInWeightAge         float64
+
+	// NodeClassesFile is the path to a JSON file containing the policy of each node class. Classes
+	// without a policy are scaled without node count bounds or a utilization target.
+	NodeClassesFile string
+
+	// ExcludedJobs are the IDs of jobs, such as system jobs critical to every node, whose nodes
+	// are never selected for removal.
+	ExcludedJobs []string
+
+	// EvaluationInterval is the time in seconds between evaluations of the node classes.
+	EvaluationInterval int
+
+	// ScaleOutCooldown and ScaleInCooldown are the times in seconds after a scaling action during
+	// which a node class is not scaled out, or in, again.
+	ScaleOutCooldown int
+	ScaleInCooldown  int
+
+	// StabilizationWindow is the time in seconds over which the highest desired node count of a
+	// class is used when deciding to scale in.
+	StabilizationWindow int
+
+	// SpotReplace raises the desired node count of a class by the number of its nodes being
+	// drained due to a spot interruption, so replacements are launched before the nodes are lost.
+	SpotReplace bool
+
+	// SoftScaleIn parks the nodes selected for scale in by draining them and leaving them
+	// ineligible for scheduling, rather than terminating them. Parked nodes are made eligible
+	// again before new nodes are requested when scaling out.
+	SoftScaleIn bool
+
+	// TargetPlugin is the command of the external node target plugin used to add and remove
+	// nodes, and TargetPluginArgs are the args it is run with. If the command is empty, scaling
+	// decisions are not performed.
+	TargetPlugin     string
+	TargetPluginArgs []string
+
+	// DrainDeadline is the time in seconds after which the remaining allocations of nodes being
+	// drained for scale in are stopped.
+	DrainDeadline int
+
+	// OpenStack and VSphere configure the first-party node target providers. Each is only set
+	// when its endpoint is configured, and at most one provider can be used.
+	OpenStack *ClusterScalingOpenStackConfig
+	VSphere   *ClusterScalingVSphereConfig
+}
+
+// ClusterScalingOpenStackConfig is the config of the OpenStack node target provider, which
+// authenticates with Keystone v3 using a password scoped to the project the servers run in.
+type ClusterScalingOpenStackConfig struct {
+	AuthURL    string
+	Username   string
+	Password   string
+	ProjectID  string
+	UserDomain string
+	Region     string
+}
+
+// ClusterScalingVSphereConfig is the config of the vSphere node target provider. Insecure skips
+// verification of the vCenter TLS certificate.
+type ClusterScalingVSphereConfig struct {
+	URL      string
+	Username string
+	Password string
+	Insecure bool
+}
+
+// MarshalZerologObject is the Zerolog marshaller which allow us to log the object.
+func (c *ClusterScalingConfig) MarshalZerologObject(e *zerolog.Event) {
+	e.Float64(configKeyClusterScalingScaleInWeightEmpty, c.ScaleInWeightEmpty).
+		Float64(configKeyClusterScalingScaleInWeightAllocations, c.ScaleInWeightAllocations).
+		Float64(configKeyClusterScalingScaleInWeightUtilization, c.ScaleInWeightUtilization).
+		Float64(configKeyClusterScalingScaleInWeightAge, c.ScaleInWeightAge).
+		Str(configKeyClusterScalingNodeClassesFile, c.NodeClassesFile).
+		Strs(configKeyClusterScalingExcludedJobs, c.ExcludedJobs).
+		Int(configKeyClusterScalingEvaluationInterval, c.EvaluationInterval).
+		Int(configKeyClusterScalingScaleOutCooldown, c.ScaleOutCooldown).
+		Int(configKeyClusterScalingScaleInCooldown, c.ScaleInCooldown).
+		Int(configKeyClusterScalingStabilizationWindow, c.StabilizationWindow).
+		Bool(configKeyClusterScalingSpotReplace, c.SpotReplace).
+		Bool(configKeyClusterScalingSoftScaleIn, c.SoftScaleIn).
+		Str(configKeyClusterScalingTargetPlugin, c.TargetPlugin).
+		Strs(configKeyClusterScalingTargetPluginArgs, c.TargetPluginArgs).
+		Int(configKeyClusterScalingDrainDeadline, c.DrainDeadline)
+
+	if c.OpenStack != nil {
+		e.Str(configKeyClusterScalingOpenStackAuthURL, c.OpenStack.AuthURL).
+			Str(configKeyClusterScalingOpenStackProjectID, c.OpenStack.ProjectID).
+			Str(configKeyClusterScalingOpenStackRegion, c.OpenStack.Region)
+	}
+	if c.VSphere != nil {
+		e.Str(configKeyClusterScalingVSphereURL, c.VSphere.URL)
+	}
+}
+
+// Validate checks that the scale in weights are not negative and at least one is set, that the
+// evaluation interval, cooldowns and stabilization window are valid, and that at most one node
+// target provider is configured.
+func (c *ClusterScalingConfig) Validate() error {
+	if c.EvaluationInterval < 1 {
+		return errors.New("Please specify a cluster scaling evaluation interval of at least 1 second")
+	}
+	if c.ScaleOutCooldown < 0 || c.ScaleInCooldown < 0 || c.StabilizationWindow < 0 {
+		return errors.New("Please specify cluster scaling cooldowns and stabilization window which are not negative")
+	}
+	if c.DrainDeadline < 1 {
+		return errors.New("Please specify a cluster scaling drain deadline of at least 1 second")
+	}
+
+	var providers int
+	for _, set := range []bool{c.TargetPlugin != "", c.OpenStack != nil, c.VSphere != nil} {
+		if set {
+			providers++
+		}
+	}
+	if providers > 1 {
+		return errors.New("Please specify only one of the cluster scaling target plugin, OpenStack or vSphere providers")
+	}
+
+	weights := []float64{c.ScaleInWeightEmpty, c.ScaleInWeightAllocations, c.ScaleInWeightUtilization, c.ScaleInWeightAge}
+
+	var total float64
+	for _, w := range weights {
+		if w < 0 {
+			return errors.New("Please specify cluster scaling scale in weights which are not negative")
+		}
+		total += w
+	}
+	if total == 0 {
+		return errors.New("Please specify at least one non-zero cluster scaling scale in weight")
+	}
+	return nil
+}
+
+// GetClusterScalingConfig hydrates the cluster scaling config struct.
+func GetClusterScalingConfig() ClusterScalingConfig {
+	c := ClusterScalingConfig{
+		ScaleInWeightEmpty:       viper.GetFloat64(configKeyClusterScalingScaleInWeightEmpty),
+		ScaleInWeightAllocations: viper.GetFloat64(configKeyClusterScalingScaleInWeightAllocations),
+		ScaleInWeightUtilization: viper.GetFloat64(configKeyClusterScalingScaleInWeightUtilization),
+		ScaleInWeightAge:         viper.GetFloat64(configKeyClusterScalingScaleInWeightAge),
+		NodeClassesFile:          viper.GetString(configKeyClusterScalingNodeClassesFile),
+		ExcludedJobs:             splitList(viper.GetString(configKeyClusterScalingExcludedJobs)),
+		EvaluationInterval:       viper.GetInt(configKeyClusterScalingEvaluationInterval),
+		ScaleOutCooldown:         viper.GetInt(configKeyClusterScalingScaleOutCooldown),
+		ScaleInCooldown:          viper.GetInt(configKeyClusterScalingScaleInCooldown),
+		StabilizationWindow:      viper.GetInt(configKeyClusterScalingStabilizationWindow),
+		SpotReplace:              viper.GetBool(configKeyClusterScalingSpotReplace),
+		SoftScaleIn:              viper.GetBool(configKeyClusterScalingSoftScaleIn),
+		TargetPlugin:             viper.GetString(configKeyClusterScalingTargetPlugin),
+		TargetPluginArgs:         splitList(viper.GetString(configKeyClusterScalingTargetPluginArgs)),
+		DrainDeadline:            viper.GetInt(configKeyClusterScalingDrainDeadline),
+	}
+
+	if addr := viper.GetString(configKeyClusterScalingOpenStackAuthURL); addr != "" {
+		c.OpenStack = &ClusterScalingOpenStackConfig{
+			AuthURL:    addr,
+			Username:   viper.GetString(configKeyClusterScalingOpenStackUsername),
+			Password:   viper.GetString(configKeyClusterScalingOpenStackPassword),
+			ProjectID:  viper.GetString(configKeyClusterScalingOpenStackProjectID),
+			UserDomain: viper.GetString(configKeyClusterScalingOpenStackUserDomain),
+			Region:     viper.GetString(configKeyClusterScalingOpenStackRegion),
+		}
+	}
+	if addr := viper.GetString(configKeyClusterScalingVSphereURL); addr != "" {
+		c.VSphere = &ClusterScalingVSphereConfig{
+			URL:      addr,
+			Username: viper.GetString(configKeyClusterScalingVSphereUsername),
+			Password: viper.GetString(configKeyClusterScalingVSpherePassword),
+			Insecure: viper.GetBool(configKeyClusterScalingVSphereInsecure),
+		}
+	}
+	return c
+}
+
+// RegisterClusterScalingConfig is used by a Cobra command to register the cluster scaling CLI
+// flags.
+func RegisterClusterScalingConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key          = configKeyClusterScalingScaleInWeightEmpty
+			longOpt      = "cluster-scaling-scale-in-weight-empty"
+			defaultValue = 4.0
+			description  = "The weight given to nodes without allocations when selecting nodes to scale in"
+		)
+
+		flags.Float64(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingScaleInWeightAllocations
+			longOpt      = "cluster-scaling-scale-in-weight-allocations"
+			defaultValue = 2.0
+			description  = "The weight given to nodes with fewer allocations when selecting nodes to scale in"
+		)
+
+		flags.Float64(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingScaleInWeightUtilization
+			longOpt      = "cluster-scaling-scale-in-weight-utilization"
+			defaultValue = 2.0
+			description  = "The weight given to nodes with lower resource utilization when selecting nodes to scale in"
+		)
+
+		flags.Float64(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingScaleInWeightAge
+			longOpt      = "cluster-scaling-scale-in-weight-age"
+			defaultValue = 1.0
+			description  = "The weight given to older nodes when selecting nodes to scale in"
+		)
+
+		flags.Float64(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingNodeClassesFile
+			longOpt      = "cluster-scaling-node-classes-file"
+			defaultValue = ""
+			description  = "The path to a JSON file containing the cluster scaling policy of each node class"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingExcludedJobs
+			longOpt      = "cluster-scaling-excluded-jobs"
+			defaultValue = ""
+			description  = "Comma separated IDs of jobs whose nodes are never selected for removal by the cluster scaler"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingEvaluationInterval
+			longOpt      = "cluster-scaling-evaluation-interval"
+			defaultValue = 60
+			description  = "The time period in seconds between cluster scaling evaluations of the node classes"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingScaleOutCooldown
+			longOpt      = "cluster-scaling-scale-out-cooldown"
+			defaultValue = 300
+			description  = "The time in seconds after a node class is scaled out during which it is not scaled out again"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingScaleInCooldown
+			longOpt      = "cluster-scaling-scale-in-cooldown"
+			defaultValue = 600
+			description  = "The time in seconds after a node class is scaled in either direction during which it is not scaled in"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingStabilizationWindow
+			longOpt      = "cluster-scaling-stabilization-window"
+			defaultValue = 600
+			description  = "The time in seconds over which the highest desired node count of a class is used for scale in decisions"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingSpotReplace
+			longOpt      = "cluster-scaling-spot-replace"
+			defaultValue = false
+			description  = "Scale out node classes to replace nodes drained due to a spot interruption"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingSoftScaleIn
+			longOpt      = "cluster-scaling-soft-scale-in"
+			defaultValue = false
+			description  = "Park nodes by leaving them ineligible for scheduling on scale in, rather than terminating them"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingTargetPlugin
+			longOpt      = "cluster-scaling-target-plugin"
+			defaultValue = ""
+			description  = "The command of the external node target plugin used to add and remove cluster nodes"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingTargetPluginArgs
+			longOpt      = "cluster-scaling-target-plugin-args"
+			defaultValue = ""
+			description  = "Comma separated args passed to the node target plugin command"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingDrainDeadline
+			longOpt      = "cluster-scaling-drain-deadline"
+			defaultValue = 600
+			description  = "The time in seconds after which the remaining allocations of nodes drained for scale in are stopped"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingOpenStackAuthURL
+			longOpt      = "cluster-scaling-openstack-auth-url"
+			defaultValue = ""
+			description  = "The Keystone v3 URL used by the OpenStack node target provider, such as https://keystone:5000/v3"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingOpenStackUsername
+			longOpt      = "cluster-scaling-openstack-username"
+			defaultValue = ""
+			description  = "The username the OpenStack node target provider authenticates as"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingOpenStackPassword
+			longOpt      = "cluster-scaling-openstack-password"
+			defaultValue = ""
+			description  = "The password, or secret reference, the OpenStack node target provider authenticates with"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingOpenStackProjectID
+			longOpt      = "cluster-scaling-openstack-project-id"
+			defaultValue = ""
+			description  = "The ID of the OpenStack project which node servers are launched in"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingOpenStackUserDomain
+			longOpt      = "cluster-scaling-openstack-user-domain"
+			defaultValue = "Default"
+			description  = "The name of the Keystone domain of the OpenStack user"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingOpenStackRegion
+			longOpt      = "cluster-scaling-openstack-region"
+			defaultValue = ""
+			description  = "The OpenStack region whose compute endpoint is used, if the catalog contains more than one"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingVSphereURL
+			longOpt      = "cluster-scaling-vsphere-url"
+			defaultValue = ""
+			description  = "The vCenter URL used by the vSphere node target provider, such as https://vcenter.example.com"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingVSphereUsername
+			longOpt      = "cluster-scaling-vsphere-username"
+			defaultValue = ""
+			description  = "The username the vSphere node target provider authenticates as"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingVSpherePassword
+			longOpt      = "cluster-scaling-vsphere-password"
+			defaultValue = ""
+			description  = "The password, or secret reference, the vSphere node target provider authenticates with"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyClusterScalingVSphereInsecure
+			longOpt      = "cluster-scaling-vsphere-insecure"
+			defaultValue = false
+			description  = "Skip verification of the vCenter TLS certificate"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
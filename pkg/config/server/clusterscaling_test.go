@@ -0,0 +1,56 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ClusterScalingConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterClusterScalingConfig(fakeCMD)
+
+	cfg := GetClusterScalingConfig()
+	assert.Equal(t, 4.0, cfg.ScaleInWeightEmpty)
+	assert.Equal(t, 2.0, cfg.ScaleInWeightAllocations)
+	assert.Equal(t, 2.0, cfg.ScaleInWeightUtilization)
+	assert.Equal(t, 1.0, cfg.ScaleInWeightAge)
+	assert.Equal(t, "", cfg.NodeClassesFile)
+	assert.Nil(t, cfg.ExcludedJobs)
+	assert.Equal(t, 60, cfg.EvaluationInterval)
+	assert.Equal(t, 300, cfg.ScaleOutCooldown)
+	assert.Equal(t, 600, cfg.ScaleInCooldown)
+	assert.Equal(t, 600, cfg.StabilizationWindow)
+	assert.False(t, cfg.SpotReplace)
+	assert.False(t, cfg.SoftScaleIn)
+	assert.Equal(t, "", cfg.TargetPlugin)
+	assert.Nil(t, cfg.TargetPluginArgs)
+	assert.Equal(t, 600, cfg.DrainDeadline)
+	assert.Nil(t, cfg.OpenStack)
+	assert.Nil(t, cfg.VSphere)
+	assert.Nil(t, cfg.Validate())
+}
+
+func TestClusterScalingConfig_Validate(t *testing.T) {
+	testCases := []struct {
+		cfg         ClusterScalingConfig
+		expectError bool
+	}{
+		{cfg: ClusterScalingConfig{ScaleInWeightAge: 1, EvaluationInterval: 60, DrainDeadline: 600}, expectError: false},
+		{cfg: ClusterScalingConfig{EvaluationInterval: 60, DrainDeadline: 600}, expectError: true},
+		{cfg: ClusterScalingConfig{ScaleInWeightEmpty: 2, ScaleInWeightAge: -1, EvaluationInterval: 60, DrainDeadline: 600}, expectError: true},
+		{cfg: ClusterScalingConfig{ScaleInWeightAge: 1, DrainDeadline: 600}, expectError: true},
+		{cfg: ClusterScalingConfig{ScaleInWeightAge: 1, EvaluationInterval: 60}, expectError: true},
+		{cfg: ClusterScalingConfig{ScaleInWeightAge: 1, EvaluationInterval: 60, DrainDeadline: 600,
+			VSphere: &ClusterScalingVSphereConfig{URL: "https://vcenter"}}, expectError: false},
+		{cfg: ClusterScalingConfig{ScaleInWeightAge: 1, EvaluationInterval: 60, DrainDeadline: 600, TargetPlugin: "provision",
+			OpenStack: &ClusterScalingOpenStackConfig{AuthURL: "https://keystone:5000/v3"}}, expectError: true},
+		{cfg: ClusterScalingConfig{ScaleInWeightAge: 1, EvaluationInterval: 60, ScaleInCooldown: -1, DrainDeadline: 600}, expectError: true},
+	}
+
+	for _, tc := range testCases {
+		err := tc.cfg.Validate()
+		assert.Equal(t, tc.expectError, err != nil, tc.cfg)
+	}
+}
@@ -0,0 +1,89 @@
+package server
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	configKeyJobFilterNamespaces = "job-filter-namespaces"
+	configKeyJobFilterNameRegex  = "job-filter-name-regex"
+	configKeyJobFilterMeta       = "job-filter-meta"
+)
+
+// JobFilterConfig is the server job filter configuration struct. The filter restricts the jobs
+// which this Sherpa server evaluates, allowing responsibility for a cluster to be split across
+// multiple Sherpa instances.
+type JobFilterConfig struct {
+	// Namespaces are the Nomad namespaces whose jobs are evaluated. If empty, jobs within all
+	// namespaces are evaluated.
+	Namespaces []string
+
+	// NameRegex is the regular expression job IDs must match in order to be evaluated.
+	NameRegex string
+
+	// Meta is the job meta key, optionally in the form key=value, which jobs must have set in
+	// order to be evaluated.
+	Meta string
+}
+
+// MarshalZerologObject is the Zerolog marshaller which allow us to log the object.
+func (c *JobFilterConfig) MarshalZerologObject(e *zerolog.Event) {
+	e.Strs(configKeyJobFilterNamespaces, c.Namespaces).
+		Str(configKeyJobFilterNameRegex, c.NameRegex).
+		Str(configKeyJobFilterMeta, c.Meta)
+}
+
+// GetJobFilterConfig hydrates the job filter config struct.
+func GetJobFilterConfig() JobFilterConfig {
+	return JobFilterConfig{
+		Namespaces: splitList(viper.GetString(configKeyJobFilterNamespaces)),
+		NameRegex:  viper.GetString(configKeyJobFilterNameRegex),
+		Meta:       viper.GetString(configKeyJobFilterMeta),
+	}
+}
+
+// RegisterJobFilterConfig is used by a Cobra command to register the job filter CLI flags.
+func RegisterJobFilterConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key          = configKeyJobFilterNamespaces
+			longOpt      = "job-filter-namespaces"
+			defaultValue = ""
+			description  = "Comma separated list of Nomad namespaces whose jobs are evaluated"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyJobFilterNameRegex
+			longOpt      = "job-filter-name-regex"
+			defaultValue = ""
+			description  = "The regular expression job IDs must match to be evaluated"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyJobFilterMeta
+			longOpt      = "job-filter-meta"
+			defaultValue = ""
+			description  = "The job meta key, or key=value pair, jobs must have set to be evaluated"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
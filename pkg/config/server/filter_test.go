@@ -0,0 +1,18 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_JobFilterConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterJobFilterConfig(fakeCMD)
+
+	cfg := GetJobFilterConfig()
+	assert.Nil(t, cfg.Namespaces)
+	assert.Equal(t, "", cfg.NameRegex)
+	assert.Equal(t, "", cfg.Meta)
+}
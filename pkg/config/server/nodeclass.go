@@ -0,0 +1,111 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/pkg/errors"
+)
+
+// NodeClassPolicy is the cluster scaling policy of a Nomad node class. Each class is scaled
+// independently of the others, within its own node count bounds.
+type NodeClassPolicy struct {
+	Class string `json:"Class"`
+
+	// MinNodes and MaxNodes bound the number of eligible nodes of the class. A MaxNodes of 0 means
+	// the class has no maximum.
+	MinNodes int `json:"MinNodes"`
+	MaxNodes int `json:"MaxNodes,omitempty"`
+
+	// TargetUtilization is the fraction, between 0 and 1, of the class CPU or memory the cluster
+	// scaler aims to have allocated. A value of 0 means the class has no target.
+	TargetUtilization float64 `json:"TargetUtilization,omitempty"`
+
+	// ExcludedJobs are the IDs of jobs, such as system jobs critical to the class, whose nodes are
+	// never selected for removal. These are in addition to the server wide excluded jobs.
+	ExcludedJobs []string `json:"ExcludedJobs,omitempty"`
+
+	// OpenStack and VSphere are the parameters used to launch new nodes of the class when the
+	// corresponding node target provider is configured.
+	OpenStack *OpenStackNodeClass `json:"OpenStack,omitempty"`
+	VSphere   *VSphereNodeClass   `json:"VSphere,omitempty"`
+}
+
+// OpenStackNodeClass holds the parameters of the Nova servers launched for a node class.
+type OpenStackNodeClass struct {
+	ImageID        string   `json:"ImageID"`
+	FlavorID       string   `json:"FlavorID"`
+	Networks       []string `json:"Networks,omitempty"`
+	SecurityGroups []string `json:"SecurityGroups,omitempty"`
+	KeyName        string   `json:"KeyName,omitempty"`
+
+	// UserData is the cloud-init user data passed to new servers, which should start the Nomad
+	// client with the node class.
+	UserData string `json:"UserData,omitempty"`
+}
+
+// VSphereNodeClass holds the parameters of the virtual machines cloned for a node class. The
+// placement params are vCenter managed object IDs, such as group-v3, and default to those of the
+// template when not set.
+type VSphereNodeClass struct {
+	// Template is the name of the virtual machine which new nodes are cloned from.
+	Template string `json:"Template"`
+
+	Folder       string `json:"Folder,omitempty"`
+	ResourcePool string `json:"ResourcePool,omitempty"`
+	Datastore    string `json:"Datastore,omitempty"`
+}
+
+// Validate checks the node class policy has the required params, and that they are valid.
+func (p *NodeClassPolicy) Validate() error {
+	if p.Class == "" {
+		return errors.New("node class policy class must be set")
+	}
+	if p.MinNodes < 0 {
+		return errors.Errorf("node class %s min nodes must not be negative", p.Class)
+	}
+	if p.MaxNodes != 0 && p.MaxNodes < p.MinNodes {
+		return errors.Errorf("node class %s max nodes must not be less than min nodes", p.Class)
+	}
+	if p.TargetUtilization < 0 || p.TargetUtilization > 1 {
+		return errors.Errorf("node class %s target utilization must be between 0 and 1", p.Class)
+	}
+	if p.OpenStack != nil && (p.OpenStack.ImageID == "" || p.OpenStack.FlavorID == "") {
+		return errors.Errorf("node class %s OpenStack image and flavor must be set", p.Class)
+	}
+	if p.VSphere != nil && p.VSphere.Template == "" {
+		return errors.Errorf("node class %s vSphere template must be set", p.Class)
+	}
+	return nil
+}
+
+// LoadNodeClassPolicies reads the JSON file at the path which contains a list of node class
+// policies, validating each and ensuring classes are unique.
+func LoadNodeClassPolicies(path string) ([]*NodeClassPolicy, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read node class policies file")
+	}
+	return parseNodeClassPolicies(data)
+}
+
+func parseNodeClassPolicies(data []byte) ([]*NodeClassPolicy, error) {
+	var policies []*NodeClassPolicy
+
+	if err := json.Unmarshal(data, &policies); err != nil {
+		return nil, errors.Wrap(err, "failed to decode node class policies")
+	}
+
+	classes := make(map[string]struct{}, len(policies))
+
+	for _, p := range policies {
+		if err := p.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := classes[p.Class]; ok {
+			return nil, errors.Errorf("node class %s has more than one policy", p.Class)
+		}
+		classes[p.Class] = struct{}{}
+	}
+	return policies, nil
+}
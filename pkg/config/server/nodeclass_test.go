@@ -0,0 +1,75 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseNodeClassPolicies(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedLen   int
+		expectedError string
+	}{
+		{
+			name: "valid policies",
+			input: `[
+  {"Class": "batch", "MinNodes": 2, "MaxNodes": 20, "TargetUtilization": 0.7, "ExcludedJobs": ["fluentd"]},
+  {"Class": "web", "MinNodes": 3}
+]`,
+			expectedLen: 2,
+		},
+		{
+			name:          "duplicate classes",
+			input:         `[{"Class": "batch"}, {"Class": "batch"}]`,
+			expectedError: "node class batch has more than one policy",
+		},
+		{
+			name:          "missing class",
+			input:         `[{"MinNodes": 1}]`,
+			expectedError: "node class policy class must be set",
+		},
+		{
+			name:          "max below min",
+			input:         `[{"Class": "batch", "MinNodes": 5, "MaxNodes": 2}]`,
+			expectedError: "node class batch max nodes must not be less than min nodes",
+		},
+		{
+			name:          "target utilization out of range",
+			input:         `[{"Class": "batch", "TargetUtilization": 70}]`,
+			expectedError: "node class batch target utilization must be between 0 and 1",
+		},
+		{
+			name: "valid provider params",
+			input: `[
+  {"Class": "batch", "OpenStack": {"ImageID": "ubuntu-nomad", "FlavorID": "m1.large", "Networks": ["private"]}},
+  {"Class": "web", "VSphere": {"Template": "nomad-client", "Folder": "group-v3"}}
+]`,
+			expectedLen: 2,
+		},
+		{
+			name:          "OpenStack flavor missing",
+			input:         `[{"Class": "batch", "OpenStack": {"ImageID": "ubuntu-nomad"}}]`,
+			expectedError: "node class batch OpenStack image and flavor must be set",
+		},
+		{
+			name:          "vSphere template missing",
+			input:         `[{"Class": "batch", "VSphere": {"Folder": "group-v3"}}]`,
+			expectedError: "node class batch vSphere template must be set",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			policies, err := parseNodeClassPolicies([]byte(tc.input))
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Len(t, policies, tc.expectedLen)
+		})
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	configKeyNomadAddrs         = "nomad-addrs"
+	configKeyNomadProbeInterval = "nomad-health-probe-interval"
+)
+
+// NomadConfig is the server Nomad client configuration struct.
+type NomadConfig struct {
+	// Addrs are the Nomad server addresses the client can fail over between. If empty, the
+	// NOMAD_ADDR env var or default Nomad address is used.
+	Addrs []string
+
+	// ProbeInterval is the time in seconds between health probes of the active Nomad server.
+	ProbeInterval int
+}
+
+// MarshalZerologObject is the Zerolog marshaller which allow us to log the object.
+func (c *NomadConfig) MarshalZerologObject(e *zerolog.Event) {
+	e.Strs(configKeyNomadAddrs, c.Addrs).
+		Int(configKeyNomadProbeInterval, c.ProbeInterval)
+}
+
+// GetNomadConfig hydrates the Nomad config struct.
+func GetNomadConfig() NomadConfig {
+	return NomadConfig{
+		Addrs:         splitList(viper.GetString(configKeyNomadAddrs)),
+		ProbeInterval: viper.GetInt(configKeyNomadProbeInterval),
+	}
+}
+
+// RegisterNomadConfig is used by a Cobra command to register the Nomad client CLI flags.
+func RegisterNomadConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key          = configKeyNomadAddrs
+			longOpt      = "nomad-addrs"
+			defaultValue = ""
+			description  = "Comma separated Nomad server addresses the Nomad client can fail over between"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyNomadProbeInterval
+			longOpt      = "nomad-health-probe-interval"
+			defaultValue = 10
+			description  = "The time in seconds between health probes of the active Nomad server"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
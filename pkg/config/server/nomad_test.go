@@ -0,0 +1,17 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NomadConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterNomadConfig(fakeCMD)
+
+	cfg := GetNomadConfig()
+	assert.Nil(t, cfg.Addrs)
+	assert.Equal(t, 10, cfg.ProbeInterval)
+}
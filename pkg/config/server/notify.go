@@ -0,0 +1,196 @@
+package server
+
+import (
+	"strings"
+
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	configKeyNotifyGrafanaAddr  = "notify-grafana-addr"
+	configKeyNotifyGrafanaToken = "notify-grafana-token"
+	configKeyNotifyGrafanaTags  = "notify-grafana-tags"
+
+	configKeyNotifyTeamsWebhookURL   = "notify-teams-webhook-url"
+	configKeyNotifyDiscordWebhookURL = "notify-discord-webhook-url"
+
+	configKeyNotifyMinSeverity = "notify-min-severity"
+
+	configKeyNotifyQueuePath        = "notify-queue-path"
+	configKeyNotifyQueueMaxAttempts = "notify-queue-max-attempts"
+)
+
+// NotifyConfig is the server scaling event notification configuration struct.
+type NotifyConfig struct {
+	// GrafanaAddr is the address of the Grafana server which scaling annotations are posted to.
+	// If empty, the Grafana notifier is disabled.
+	GrafanaAddr  string
+	GrafanaToken string
+	GrafanaTags  []string
+
+	// TeamsWebhookURL and DiscordWebhookURL are the incoming webhooks which scaling event messages
+	// are posted to. These can be secret references. If empty, the notifier is disabled.
+	TeamsWebhookURL   string
+	DiscordWebhookURL string
+
+	// MinSeverity is the minimum severity of scaling events which are notified; one of info,
+	// warning or critical.
+	MinSeverity string
+
+	// QueuePath is the file which pending notifications are persisted to, so they survive a
+	// restart. If empty, the notification queue is held in memory only.
+	QueuePath        string
+	QueueMaxAttempts int
+}
+
+// MarshalZerologObject is the Zerolog marshaller which allow us to log the object. The Grafana
+// token and webhook URLs are not logged, only whether the notifiers are enabled.
+func (c *NotifyConfig) MarshalZerologObject(e *zerolog.Event) {
+	e.Str(configKeyNotifyGrafanaAddr, c.GrafanaAddr).
+		Strs(configKeyNotifyGrafanaTags, c.GrafanaTags).
+		Bool("notify-teams-enabled", c.TeamsWebhookURL != "").
+		Bool("notify-discord-enabled", c.DiscordWebhookURL != "").
+		Str(configKeyNotifyMinSeverity, c.MinSeverity).
+		Str(configKeyNotifyQueuePath, c.QueuePath).
+		Int(configKeyNotifyQueueMaxAttempts, c.QueueMaxAttempts)
+}
+
+// GetNotifyConfig hydrates the notify config struct.
+func GetNotifyConfig() NotifyConfig {
+	return NotifyConfig{
+		GrafanaAddr:  viper.GetString(configKeyNotifyGrafanaAddr),
+		GrafanaToken: viper.GetString(configKeyNotifyGrafanaToken),
+		GrafanaTags:  splitList(viper.GetString(configKeyNotifyGrafanaTags)),
+
+		TeamsWebhookURL:   viper.GetString(configKeyNotifyTeamsWebhookURL),
+		DiscordWebhookURL: viper.GetString(configKeyNotifyDiscordWebhookURL),
+
+		MinSeverity: viper.GetString(configKeyNotifyMinSeverity),
+
+		QueuePath:        viper.GetString(configKeyNotifyQueuePath),
+		QueueMaxAttempts: viper.GetInt(configKeyNotifyQueueMaxAttempts),
+	}
+}
+
+// splitList splits a comma separated config value, ignoring empty items.
+func splitList(raw string) []string {
+	var items []string
+
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+// RegisterNotifyConfig is used by a Cobra command to register the notify CLI flags.
+func RegisterNotifyConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key          = configKeyNotifyGrafanaAddr
+			longOpt      = "notify-grafana-addr"
+			defaultValue = ""
+			description  = "The address of a Grafana server to post scaling event annotations to"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyNotifyGrafanaToken
+			longOpt      = "notify-grafana-token"
+			defaultValue = ""
+			description  = "The Grafana API token used to post scaling event annotations"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyNotifyGrafanaTags
+			longOpt      = "notify-grafana-tags"
+			defaultValue = ""
+			description  = "Comma separated additional tags to add to Grafana scaling event annotations"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyNotifyTeamsWebhookURL
+			longOpt      = "notify-teams-webhook-url"
+			defaultValue = ""
+			description  = "The Microsoft Teams incoming webhook URL to post scaling event messages to"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyNotifyDiscordWebhookURL
+			longOpt      = "notify-discord-webhook-url"
+			defaultValue = ""
+			description  = "The Discord channel webhook URL to post scaling event messages to"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyNotifyMinSeverity
+			longOpt      = "notify-min-severity"
+			defaultValue = "info"
+			description  = "The minimum severity of scaling events to notify; one of info, warning or critical"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyNotifyQueuePath
+			longOpt      = "notify-queue-path"
+			defaultValue = ""
+			description  = "The file to persist pending scaling event notifications to, allowing them to survive restarts"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyNotifyQueueMaxAttempts
+			longOpt      = "notify-queue-max-attempts"
+			defaultValue = 10
+			description  = "The number of attempts to deliver a scaling event notification before it is dropped"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
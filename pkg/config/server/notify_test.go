@@ -0,0 +1,28 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_NotifyConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterNotifyConfig(fakeCMD)
+
+	cfg := GetNotifyConfig()
+	assert.Equal(t, "", cfg.GrafanaAddr)
+	assert.Equal(t, "", cfg.GrafanaToken)
+	assert.Nil(t, cfg.GrafanaTags)
+	assert.Equal(t, "", cfg.TeamsWebhookURL)
+	assert.Equal(t, "", cfg.DiscordWebhookURL)
+	assert.Equal(t, "info", cfg.MinSeverity)
+	assert.Equal(t, "", cfg.QueuePath)
+	assert.Equal(t, 10, cfg.QueueMaxAttempts)
+}
+
+func Test_splitList(t *testing.T) {
+	assert.Nil(t, splitList(""))
+	assert.Equal(t, []string{"cluster:prod", "sherpa-prod"}, splitList("cluster:prod, ,sherpa-prod"))
+}
@@ -0,0 +1,98 @@
+package server
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// PrometheusEndpoint is a named Prometheus-compatible query endpoint, such as Thanos Query,
+// VictoriaMetrics or Mimir, which policies can reference by name.
+type PrometheusEndpoint struct {
+	Name string `json:"Name"`
+	Addr string `json:"Addr"`
+
+	// Username and Password configure HTTP basic authentication, and BearerToken configures
+	// bearer token authentication. Only one method should be used.
+	Username    string `json:"Username,omitempty"`
+	Password    string `json:"Password,omitempty"`
+	BearerToken string `json:"BearerToken,omitempty"`
+
+	// Headers are additional HTTP headers sent with each query, such as a tenant ID header.
+	Headers map[string]string `json:"Headers,omitempty"`
+
+	TLS *PrometheusEndpointTLS `json:"TLS,omitempty"`
+
+	// Timeout is the duration string after which queries to the endpoint are cancelled. If empty,
+	// queries are not subject to a timeout.
+	Timeout string `json:"Timeout,omitempty"`
+}
+
+// PrometheusEndpointTLS is the TLS configuration used when querying a Prometheus endpoint.
+type PrometheusEndpointTLS struct {
+	CACert     string `json:"CACert,omitempty"`
+	ClientCert string `json:"ClientCert,omitempty"`
+	ClientKey  string `json:"ClientKey,omitempty"`
+	Insecure   bool   `json:"Insecure,omitempty"`
+}
+
+// TimeoutDuration returns the parsed endpoint timeout, or zero if no timeout is configured.
+func (pe *PrometheusEndpoint) TimeoutDuration() (time.Duration, error) {
+	if pe.Timeout == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(pe.Timeout)
+}
+
+// Validate checks the endpoint has the required params, and that they are valid.
+func (pe *PrometheusEndpoint) Validate() error {
+	if pe.Name == "" {
+		return errors.New("endpoint name must be set")
+	}
+	if pe.Addr == "" {
+		return errors.Errorf("endpoint %s address must be set", pe.Name)
+	}
+	if pe.BearerToken != "" && pe.Username != "" {
+		return errors.Errorf("endpoint %s must only use one of basic or bearer token auth", pe.Name)
+	}
+	if _, err := pe.TimeoutDuration(); err != nil {
+		return errors.Wrapf(err, "endpoint %s timeout is invalid", pe.Name)
+	}
+	if pe.TLS != nil && (pe.TLS.ClientCert == "") != (pe.TLS.ClientKey == "") {
+		return errors.Errorf("endpoint %s TLS client cert and key must be set together", pe.Name)
+	}
+	return nil
+}
+
+// LoadPrometheusEndpoints reads the JSON file at the path which contains a list of named
+// Prometheus endpoints, validating each and ensuring names are unique.
+func LoadPrometheusEndpoints(path string) ([]*PrometheusEndpoint, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read Prometheus endpoints file")
+	}
+	return parsePrometheusEndpoints(data)
+}
+
+func parsePrometheusEndpoints(data []byte) ([]*PrometheusEndpoint, error) {
+	var endpoints []*PrometheusEndpoint
+
+	if err := json.Unmarshal(data, &endpoints); err != nil {
+		return nil, errors.Wrap(err, "failed to decode Prometheus endpoints")
+	}
+
+	names := make(map[string]struct{}, len(endpoints))
+
+	for _, ep := range endpoints {
+		if err := ep.Validate(); err != nil {
+			return nil, err
+		}
+		if _, ok := names[ep.Name]; ok {
+			return nil, errors.Errorf("endpoint name %s is not unique", ep.Name)
+		}
+		names[ep.Name] = struct{}{}
+	}
+	return endpoints, nil
+}
@@ -0,0 +1,74 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parsePrometheusEndpoints(t *testing.T) {
+	testCases := []struct {
+		name          string
+		input         string
+		expectedLen   int
+		expectedError string
+	}{
+		{
+			name: "valid endpoints",
+			input: `[
+  {"Name": "thanos", "Addr": "https://thanos:9090", "BearerToken": "abc", "Timeout": "10s"},
+  {"Name": "victoria", "Addr": "http://vm:8428", "Headers": {"X-Scope-OrgID": "team-a"}}
+]`,
+			expectedLen: 2,
+		},
+		{
+			name:          "duplicate names",
+			input:         `[{"Name": "thanos", "Addr": "a"}, {"Name": "thanos", "Addr": "b"}]`,
+			expectedError: "endpoint name thanos is not unique",
+		},
+		{
+			name:          "missing address",
+			input:         `[{"Name": "thanos"}]`,
+			expectedError: "endpoint thanos address must be set",
+		},
+		{
+			name:          "multiple auth methods",
+			input:         `[{"Name": "thanos", "Addr": "a", "Username": "u", "BearerToken": "t"}]`,
+			expectedError: "endpoint thanos must only use one of basic or bearer token auth",
+		},
+		{
+			name:          "client cert without key",
+			input:         `[{"Name": "thanos", "Addr": "a", "TLS": {"ClientCert": "/cert.pem"}}]`,
+			expectedError: "endpoint thanos TLS client cert and key must be set together",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			endpoints, err := parsePrometheusEndpoints([]byte(tc.input))
+			if tc.expectedError != "" {
+				assert.EqualError(t, err, tc.expectedError)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Len(t, endpoints, tc.expectedLen)
+		})
+	}
+}
+
+func TestPrometheusEndpoint_TimeoutDuration(t *testing.T) {
+	ep := &PrometheusEndpoint{Name: "thanos", Addr: "a"}
+
+	d, err := ep.TimeoutDuration()
+	assert.Nil(t, err)
+	assert.Equal(t, time.Duration(0), d)
+
+	ep.Timeout = "30s"
+	d, err = ep.TimeoutDuration()
+	assert.Nil(t, err)
+	assert.Equal(t, 30*time.Second, d)
+
+	ep.Timeout = "thirty"
+	assert.NotNil(t, ep.Validate())
+}
@@ -0,0 +1,29 @@
+package server
+
+import "github.com/pkg/errors"
+
+// PromotionGuard is the action taken by the scaler when a scale in request targets a job group
+// whose deployment has canaries awaiting promotion.
+type PromotionGuard string
+
+const (
+	// PromotionGuardBlock means scale in requests are rejected until the deployment has been
+	// promoted.
+	PromotionGuardBlock PromotionGuard = "block"
+
+	// PromotionGuardDefer means scale in requests are held by the scaler, and submitted once the
+	// deployment has completed successfully.
+	PromotionGuardDefer PromotionGuard = "defer"
+)
+
+func (p PromotionGuard) String() string { return string(p) }
+
+// Validate checks the promotion guard mode is a supported value.
+func (p PromotionGuard) Validate() error {
+	switch p {
+	case PromotionGuardBlock, PromotionGuardDefer:
+		return nil
+	default:
+		return errors.Errorf("unsupported scale in promotion guard mode %q", p)
+	}
+}
@@ -0,0 +1,13 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPromotionGuard_Validate(t *testing.T) {
+	assert.Nil(t, PromotionGuardBlock.Validate())
+	assert.Nil(t, PromotionGuardDefer.Validate())
+	assert.EqualError(t, PromotionGuard("queue").Validate(), `unsupported scale in promotion guard mode "queue"`)
+}
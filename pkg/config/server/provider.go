@@ -7,27 +7,173 @@ import (
 )
 
 const (
-	configKeyMetricProviderPrometheusAddr = "metric-provider-prometheus-addr"
+	configKeyMetricProviderPrometheusAddr          = "metric-provider-prometheus-addr"
+	configKeyMetricProviderEnvoyEnabled            = "metric-provider-envoy-enabled"
+	configKeyMetricProviderTraefikAddr             = "metric-provider-traefik-addr"
+	configKeyMetricProviderNGINXAddr               = "metric-provider-nginx-addr"
+	configKeyMetricProviderHAProxyAddr             = "metric-provider-haproxy-addr"
+	configKeyMetricProviderRabbitMQAddr            = "metric-provider-rabbitmq-addr"
+	configKeyMetricProviderNATSAddr                = "metric-provider-nats-addr"
+	configKeyMetricProviderInfluxDBAddr            = "metric-provider-influxdb-addr"
+	configKeyMetricProviderInfluxDBOrg             = "metric-provider-influxdb-org"
+	configKeyMetricProviderInfluxDBToken           = "metric-provider-influxdb-token"
+	configKeyMetricProviderGraphiteAddr            = "metric-provider-graphite-addr"
+	configKeyMetricProviderElasticsearchAddr       = "metric-provider-elasticsearch-addr"
+	configKeyMetricProviderNewRelicAPIKey          = "metric-provider-newrelic-api-key"
+	configKeyMetricProviderNewRelicAddr            = "metric-provider-newrelic-addr"
+	configKeyMetricProviderNewRelicAccountID       = "metric-provider-newrelic-account-id"
+	configKeyMetricProviderDatadogAPIKey           = "metric-provider-datadog-api-key"
+	configKeyMetricProviderDatadogAppKey           = "metric-provider-datadog-app-key"
+	configKeyMetricProviderDatadogAddr             = "metric-provider-datadog-addr"
+	configKeyMetricProviderPrometheusEndpointsFile = "metric-provider-prometheus-endpoints-file"
+	configKeyMetricProviderBreakerErrorThreshold   = "metric-provider-breaker-error-threshold"
+	configKeyMetricProviderBreakerWindow           = "metric-provider-breaker-window"
+	configKeyMetricProviderBreakerCooldown         = "metric-provider-breaker-cooldown"
+	configKeyMetricProviderQueryTimeout            = "metric-provider-query-timeout"
+	configKeyMetricProviderCacheEnabled            = "metric-provider-cache-enabled"
 )
 
 type MetricProviderConfig struct {
 	Prometheus *MetricProviderPrometheusConfig
+
+	// PrometheusEndpointsFile is the path to a JSON file containing named Prometheus-compatible
+	// endpoints which policies can reference.
+	PrometheusEndpointsFile string
+
+	// EnvoyEnabled indicates whether the Consul Connect Envoy provider should be setup. The
+	// provider uses the server Consul client for proxy discovery so requires no further config.
+	EnvoyEnabled bool
+
+	Traefik       *MetricProviderAddrConfig
+	NGINX         *MetricProviderAddrConfig
+	HAProxy       *MetricProviderAddrConfig
+	RabbitMQ      *MetricProviderAddrConfig
+	NATS          *MetricProviderAddrConfig
+	InfluxDB      *MetricProviderInfluxDBConfig
+	Graphite      *MetricProviderAddrConfig
+	Elasticsearch *MetricProviderAddrConfig
+	NewRelic      *MetricProviderNewRelicConfig
+	Datadog       *MetricProviderDatadogConfig
+
+	// BreakerErrorThreshold, BreakerWindow and BreakerCooldown configure the circuit breaker
+	// applied to each metric provider. A zero threshold disables the breaker.
+	BreakerErrorThreshold float64
+	BreakerWindow         int
+	BreakerCooldown       int
+
+	// QueryTimeout is the time in seconds a single metric provider query can take before it is
+	// cancelled.
+	QueryTimeout int
+
+	// CacheEnabled indicates whether the results of metric provider queries should be cached for
+	// the duration of each autoscaling run, so policies sharing a query only perform it once.
+	CacheEnabled bool
 }
 
 type MetricProviderPrometheusConfig struct {
 	Addr string
 }
 
+// MetricProviderAddrConfig is the config for metric providers which only require the address of
+// the endpoint they read metrics from.
+type MetricProviderAddrConfig struct {
+	Addr string
+}
+
+// MetricProviderInfluxDBConfig is the config for the InfluxDB provider. The org and token scope and
+// authenticate Flux queries.
+type MetricProviderInfluxDBConfig struct {
+	Addr  string
+	Org   string
+	Token string
+}
+
+// MetricProviderNewRelicConfig is the config for the New Relic provider. The account ID is used
+// by queries which do not specify their own.
+type MetricProviderNewRelicConfig struct {
+	Addr      string
+	APIKey    string
+	AccountID int
+}
+
+// MetricProviderDatadogConfig is the config for the Datadog provider. Both the API key and the
+// application key are required to query metrics.
+type MetricProviderDatadogConfig struct {
+	Addr   string
+	APIKey string
+	AppKey string
+}
+
 // MarshalZerologObject is the Zerolog marshaller which allow us to log the object.
 func (mpc *MetricProviderConfig) MarshalZerologObject(e *zerolog.Event) {}
 
 func GetMetricProviderConfig() *MetricProviderConfig {
-	mpc := &MetricProviderConfig{}
+	mpc := &MetricProviderConfig{
+		BreakerErrorThreshold:   viper.GetFloat64(configKeyMetricProviderBreakerErrorThreshold),
+		BreakerWindow:           viper.GetInt(configKeyMetricProviderBreakerWindow),
+		BreakerCooldown:         viper.GetInt(configKeyMetricProviderBreakerCooldown),
+		QueryTimeout:            viper.GetInt(configKeyMetricProviderQueryTimeout),
+		PrometheusEndpointsFile: viper.GetString(configKeyMetricProviderPrometheusEndpointsFile),
+		EnvoyEnabled:            viper.GetBool(configKeyMetricProviderEnvoyEnabled),
+		CacheEnabled:            viper.GetBool(configKeyMetricProviderCacheEnabled),
+	}
 
 	if promAddr := viper.GetString(configKeyMetricProviderPrometheusAddr); promAddr != "" {
 		mpc.Prometheus = &MetricProviderPrometheusConfig{Addr: promAddr}
 	}
 
+	if traefikAddr := viper.GetString(configKeyMetricProviderTraefikAddr); traefikAddr != "" {
+		mpc.Traefik = &MetricProviderAddrConfig{Addr: traefikAddr}
+	}
+
+	if nginxAddr := viper.GetString(configKeyMetricProviderNGINXAddr); nginxAddr != "" {
+		mpc.NGINX = &MetricProviderAddrConfig{Addr: nginxAddr}
+	}
+
+	if haproxyAddr := viper.GetString(configKeyMetricProviderHAProxyAddr); haproxyAddr != "" {
+		mpc.HAProxy = &MetricProviderAddrConfig{Addr: haproxyAddr}
+	}
+
+	if rabbitmqAddr := viper.GetString(configKeyMetricProviderRabbitMQAddr); rabbitmqAddr != "" {
+		mpc.RabbitMQ = &MetricProviderAddrConfig{Addr: rabbitmqAddr}
+	}
+
+	if natsAddr := viper.GetString(configKeyMetricProviderNATSAddr); natsAddr != "" {
+		mpc.NATS = &MetricProviderAddrConfig{Addr: natsAddr}
+	}
+
+	if influxAddr := viper.GetString(configKeyMetricProviderInfluxDBAddr); influxAddr != "" {
+		mpc.InfluxDB = &MetricProviderInfluxDBConfig{
+			Addr:  influxAddr,
+			Org:   viper.GetString(configKeyMetricProviderInfluxDBOrg),
+			Token: viper.GetString(configKeyMetricProviderInfluxDBToken),
+		}
+	}
+
+	if graphiteAddr := viper.GetString(configKeyMetricProviderGraphiteAddr); graphiteAddr != "" {
+		mpc.Graphite = &MetricProviderAddrConfig{Addr: graphiteAddr}
+	}
+
+	if esAddr := viper.GetString(configKeyMetricProviderElasticsearchAddr); esAddr != "" {
+		mpc.Elasticsearch = &MetricProviderAddrConfig{Addr: esAddr}
+	}
+
+	if apiKey := viper.GetString(configKeyMetricProviderNewRelicAPIKey); apiKey != "" {
+		mpc.NewRelic = &MetricProviderNewRelicConfig{
+			Addr:      viper.GetString(configKeyMetricProviderNewRelicAddr),
+			APIKey:    apiKey,
+			AccountID: viper.GetInt(configKeyMetricProviderNewRelicAccountID),
+		}
+	}
+
+	if apiKey := viper.GetString(configKeyMetricProviderDatadogAPIKey); apiKey != "" {
+		mpc.Datadog = &MetricProviderDatadogConfig{
+			Addr:   viper.GetString(configKeyMetricProviderDatadogAddr),
+			APIKey: apiKey,
+			AppKey: viper.GetString(configKeyMetricProviderDatadogAppKey),
+		}
+	}
+
 	return mpc
 }
 
@@ -46,4 +192,303 @@ func RegisterMetricProviderConfig(cmd *cobra.Command) {
 		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
 		viper.SetDefault(key, defaultValue)
 	}
+
+	{
+		const (
+			key          = configKeyMetricProviderEnvoyEnabled
+			longOpt      = "metric-provider-envoy-enabled"
+			defaultValue = false
+			description  = "Enable the Consul Connect Envoy sidecar proxy metric provider"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderTraefikAddr
+			longOpt      = "metric-provider-traefik-addr"
+			defaultValue = ""
+			description  = "The address of the Traefik metrics endpoint in the form <protocol>://<addr>:<port>/<path>"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderNGINXAddr
+			longOpt      = "metric-provider-nginx-addr"
+			defaultValue = ""
+			description  = "The address of the NGINX metrics endpoint in the form <protocol>://<addr>:<port>/<path>"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderHAProxyAddr
+			longOpt      = "metric-provider-haproxy-addr"
+			defaultValue = ""
+			description  = "The address of the HAProxy runtime API socket in the form unix://<path>, or the HTTP stats page URL"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderRabbitMQAddr
+			longOpt      = "metric-provider-rabbitmq-addr"
+			defaultValue = ""
+			description  = "The address of the RabbitMQ management API in the form <protocol>://[<user>:<pass>@]<addr>:<port>"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderNATSAddr
+			longOpt      = "metric-provider-nats-addr"
+			defaultValue = ""
+			description  = "The address of the NATS server monitoring endpoint in the form <protocol>://<addr>:<port>"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderInfluxDBAddr
+			longOpt      = "metric-provider-influxdb-addr"
+			defaultValue = ""
+			description  = "The address of the InfluxDB v2 API in the form <protocol>://<addr>:<port>"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderInfluxDBOrg
+			longOpt      = "metric-provider-influxdb-org"
+			defaultValue = ""
+			description  = "The InfluxDB organization to run Flux queries against"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderInfluxDBToken
+			longOpt      = "metric-provider-influxdb-token"
+			defaultValue = ""
+			description  = "The InfluxDB API token used to authenticate Flux queries"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderGraphiteAddr
+			longOpt      = "metric-provider-graphite-addr"
+			defaultValue = ""
+			description  = "The address of the Graphite render API in the form <protocol>://<addr>:<port>"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderElasticsearchAddr
+			longOpt      = "metric-provider-elasticsearch-addr"
+			defaultValue = ""
+			description  = "The address of the Elasticsearch cluster in the form <protocol>://[<user>:<pass>@]<addr>:<port>"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderNewRelicAPIKey
+			longOpt      = "metric-provider-newrelic-api-key"
+			defaultValue = ""
+			description  = "The New Relic user API key used to run NRQL queries"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderNewRelicAddr
+			longOpt      = "metric-provider-newrelic-addr"
+			defaultValue = "https://api.newrelic.com/graphql"
+			description  = "The address of the New Relic NerdGraph API"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderNewRelicAccountID
+			longOpt      = "metric-provider-newrelic-account-id"
+			defaultValue = 0
+			description  = "The default New Relic account ID used by NRQL queries which do not specify an account"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderDatadogAPIKey
+			longOpt      = "metric-provider-datadog-api-key"
+			defaultValue = ""
+			description  = "The Datadog API key used to run metric queries"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderDatadogAppKey
+			longOpt      = "metric-provider-datadog-app-key"
+			defaultValue = ""
+			description  = "The Datadog application key used to run metric queries"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderDatadogAddr
+			longOpt      = "metric-provider-datadog-addr"
+			defaultValue = "https://api.datadoghq.com"
+			description  = "The address of the Datadog API, which differs for each Datadog site"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderPrometheusEndpointsFile
+			longOpt      = "metric-provider-prometheus-endpoints-file"
+			defaultValue = ""
+			description  = "The path to a JSON file of named Prometheus-compatible endpoints policies can query"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderBreakerErrorThreshold
+			longOpt      = "metric-provider-breaker-error-threshold"
+			defaultValue = 50
+			description  = "The percentage of failed queries which disables a metric provider, 0 disables the circuit breaker"
+		)
+
+		flags.Float64(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderBreakerWindow
+			longOpt      = "metric-provider-breaker-window"
+			defaultValue = 10
+			description  = "The number of recent metric provider queries used to calculate the error rate"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderBreakerCooldown
+			longOpt      = "metric-provider-breaker-cooldown"
+			defaultValue = 60
+			description  = "The time in seconds a disabled metric provider waits before being retried"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderQueryTimeout
+			longOpt      = "metric-provider-query-timeout"
+			defaultValue = 30
+			description  = "The time in seconds a metric provider query can take before it is cancelled"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyMetricProviderCacheEnabled
+			longOpt      = "metric-provider-cache-enabled"
+			defaultValue = false
+			description  = "Cache metric provider query results for the duration of each autoscaling run"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
 }
@@ -13,4 +13,21 @@ func Test_MetricProviderConfig(t *testing.T) {
 
 	cfg := GetMetricProviderConfig()
 	assert.Nil(t, cfg.Prometheus)
+	assert.False(t, cfg.EnvoyEnabled)
+	assert.Nil(t, cfg.Traefik)
+	assert.Nil(t, cfg.NGINX)
+	assert.Nil(t, cfg.HAProxy)
+	assert.Nil(t, cfg.RabbitMQ)
+	assert.Nil(t, cfg.NATS)
+	assert.Nil(t, cfg.InfluxDB)
+	assert.Nil(t, cfg.Graphite)
+	assert.Nil(t, cfg.Elasticsearch)
+	assert.Nil(t, cfg.NewRelic)
+	assert.Nil(t, cfg.Datadog)
+	assert.Empty(t, cfg.PrometheusEndpointsFile)
+	assert.Equal(t, float64(50), cfg.BreakerErrorThreshold)
+	assert.Equal(t, 10, cfg.BreakerWindow)
+	assert.Equal(t, 60, cfg.BreakerCooldown)
+	assert.Equal(t, 30, cfg.QueryTimeout)
+	assert.False(t, cfg.CacheEnabled)
 }
@@ -0,0 +1,85 @@
+package server
+
+import "github.com/jrasell/sherpa/pkg/secret"
+
+// RedactedValue replaces secret config values when the config is exposed, such as via the API.
+const RedactedValue = "<redacted>"
+
+// redact returns the value with any secret replaced. Empty values and secret references are
+// returned unchanged, as these do not contain the secret and show how it is configured.
+func redact(val string) string {
+	if val == "" || secret.IsReference(val) {
+		return val
+	}
+	return RedactedValue
+}
+
+// Redacted returns a copy of the config with the gossip key redacted.
+func (c ClusterConfig) Redacted() ClusterConfig {
+	c.GossipKey = redact(c.GossipKey)
+	return c
+}
+
+// Redacted returns a copy of the config with the Grafana token and webhook URLs redacted.
+func (c NotifyConfig) Redacted() NotifyConfig {
+	c.GrafanaToken = redact(c.GrafanaToken)
+	c.TeamsWebhookURL = redact(c.TeamsWebhookURL)
+	c.DiscordWebhookURL = redact(c.DiscordWebhookURL)
+	return c
+}
+
+// Redacted returns a copy of the config with the metric provider credentials redacted.
+func (c MetricProviderConfig) Redacted() MetricProviderConfig {
+	if c.InfluxDB != nil {
+		influx := *c.InfluxDB
+		influx.Token = redact(influx.Token)
+		c.InfluxDB = &influx
+	}
+	if c.NewRelic != nil {
+		nr := *c.NewRelic
+		nr.APIKey = redact(nr.APIKey)
+		c.NewRelic = &nr
+	}
+	if c.Datadog != nil {
+		dd := *c.Datadog
+		dd.APIKey = redact(dd.APIKey)
+		dd.AppKey = redact(dd.AppKey)
+		c.Datadog = &dd
+	}
+	return c
+}
+
+// Redacted returns a copy of the config with the node target provider passwords redacted.
+func (c ClusterScalingConfig) Redacted() ClusterScalingConfig {
+	if c.OpenStack != nil {
+		stack := *c.OpenStack
+		stack.Password = redact(stack.Password)
+		c.OpenStack = &stack
+	}
+	if c.VSphere != nil {
+		vs := *c.VSphere
+		vs.Password = redact(vs.Password)
+		c.VSphere = &vs
+	}
+	return c
+}
+
+// Redacted returns a copy of the config with the Vault token and encryption keys redacted. The
+// encryption keys are redacted as a whole, as the list can contain both keys and references.
+func (c SecretsConfig) Redacted() SecretsConfig {
+	c.VaultToken = redact(c.VaultToken)
+	if c.EncryptionKeys != "" {
+		c.EncryptionKeys = RedactedValue
+	}
+	return c
+}
+
+// Redacted returns a copy of the config with the scaling hook signing secret, Consul policy token,
+// etcd password and PostgreSQL URL redacted, as the URL can contain the database password.
+func (c Config) Redacted() Config {
+	c.ScalingHooksSigningSecret = redact(c.ScalingHooksSigningSecret)
+	c.ConsulStorageBackendPolicyToken = redact(c.ConsulStorageBackendPolicyToken)
+	c.EtcdStorageBackendPassword = redact(c.EtcdStorageBackendPassword)
+	c.PostgresStorageBackendURL = redact(c.PostgresStorageBackendURL)
+	return c
+}
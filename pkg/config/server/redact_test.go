@@ -0,0 +1,58 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_redact(t *testing.T) {
+	assert.Equal(t, "", redact(""))
+	assert.Equal(t, RedactedValue, redact("s3cr3t"))
+	assert.Equal(t, "vault://secret/sherpa#token", redact("vault://secret/sherpa#token"))
+	assert.Equal(t, "env://GRAFANA_TOKEN", redact("env://GRAFANA_TOKEN"))
+}
+
+func Test_Redacted(t *testing.T) {
+	assert.Equal(t, ClusterConfig{Name: "prod", GossipKey: RedactedValue}, ClusterConfig{Name: "prod", GossipKey: "key"}.Redacted())
+
+	notify := NotifyConfig{GrafanaAddr: "http://grafana:3000", GrafanaToken: "token",
+		TeamsWebhookURL: "https://example.webhook.office.com/abc", DiscordWebhookURL: "file:///etc/sherpa/discord"}
+	assert.Equal(t, NotifyConfig{GrafanaAddr: "http://grafana:3000", GrafanaToken: RedactedValue,
+		TeamsWebhookURL: RedactedValue, DiscordWebhookURL: "file:///etc/sherpa/discord"}, notify.Redacted())
+
+	provider := MetricProviderConfig{
+		InfluxDB: &MetricProviderInfluxDBConfig{Addr: "http://influxdb:8086", Token: "token"},
+		NewRelic: &MetricProviderNewRelicConfig{APIKey: "key", AccountID: 1},
+		Datadog:  &MetricProviderDatadogConfig{Addr: "https://api.datadoghq.eu", APIKey: "api", AppKey: "app"},
+	}
+	redacted := provider.Redacted()
+	assert.Equal(t, RedactedValue, redacted.InfluxDB.Token)
+	assert.Equal(t, "http://influxdb:8086", redacted.InfluxDB.Addr)
+	assert.Equal(t, RedactedValue, redacted.NewRelic.APIKey)
+	assert.Equal(t, MetricProviderDatadogConfig{Addr: "https://api.datadoghq.eu", APIKey: RedactedValue, AppKey: RedactedValue}, *redacted.Datadog)
+
+	// The original config must not be modified.
+	assert.Equal(t, "token", provider.InfluxDB.Token)
+	assert.Equal(t, "key", provider.NewRelic.APIKey)
+	assert.Equal(t, "api", provider.Datadog.APIKey)
+	assert.Equal(t, MetricProviderConfig{}, MetricProviderConfig{}.Redacted())
+
+	scaling := ClusterScalingConfig{
+		OpenStack: &ClusterScalingOpenStackConfig{AuthURL: "https://keystone:5000/v3", Password: "password"},
+		VSphere:   &ClusterScalingVSphereConfig{URL: "https://vcenter", Password: "vault://secret/vsphere#password"},
+	}
+	redactedScaling := scaling.Redacted()
+	assert.Equal(t, RedactedValue, redactedScaling.OpenStack.Password)
+	assert.Equal(t, "https://keystone:5000/v3", redactedScaling.OpenStack.AuthURL)
+	assert.Equal(t, "vault://secret/vsphere#password", redactedScaling.VSphere.Password)
+	assert.Equal(t, "password", scaling.OpenStack.Password)
+
+	assert.Equal(t, SecretsConfig{VaultAddr: "http://vault:8200", VaultToken: RedactedValue, EncryptionKeys: RedactedValue},
+		SecretsConfig{VaultAddr: "http://vault:8200", VaultToken: "token", EncryptionKeys: "k1=env://KEY"}.Redacted())
+
+	assert.Equal(t, Config{Bind: "127.0.0.1", ScalingHooksSigningSecret: RedactedValue, ConsulStorageBackendPolicyToken: RedactedValue,
+		EtcdStorageBackendPassword: RedactedValue, PostgresStorageBackendURL: RedactedValue},
+		Config{Bind: "127.0.0.1", ScalingHooksSigningSecret: "secret", ConsulStorageBackendPolicyToken: "token",
+			EtcdStorageBackendPassword: "password", PostgresStorageBackendURL: "postgres://sherpa:password@db/sherpa"}.Redacted())
+}
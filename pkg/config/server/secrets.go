@@ -0,0 +1,106 @@
+package server
+
+import (
+	"github.com/rs/zerolog"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+const (
+	configKeySecretsVaultAddr       = "secrets-vault-addr"
+	configKeySecretsVaultToken      = "secrets-vault-token"
+	configKeySecretsRefreshInterval = "secrets-refresh-interval"
+	configKeySecretsEncryptionKeys  = "secrets-encryption-keys"
+)
+
+// SecretsConfig is the server secrets configuration struct. It configures how credentials set as
+// secret references, such as vault://secret/data/newrelic#api_key, are resolved.
+type SecretsConfig struct {
+	// VaultAddr and VaultToken are used to read Vault secret references. If empty, the
+	// VAULT_ADDR and VAULT_TOKEN environment variables are used.
+	VaultAddr  string
+	VaultToken string
+
+	// RefreshInterval is the time in seconds after which secrets are re-resolved, so that rotated
+	// secrets are picked up without a restart.
+	RefreshInterval int
+
+	// EncryptionKeys is the comma separated list of <key-id>=<key> pairs used to encrypt sensitive
+	// fields at rest. Each key is a base64 encoded 32 byte key, or a secret reference to one, and
+	// the first key is used to encrypt. If empty, encryption at rest is disabled.
+	EncryptionKeys string
+}
+
+// MarshalZerologObject is the Zerolog marshaller which allow us to log the object. The Vault
+// token and encryption keys are not logged.
+func (c *SecretsConfig) MarshalZerologObject(e *zerolog.Event) {
+	e.Str(configKeySecretsVaultAddr, c.VaultAddr).
+		Int(configKeySecretsRefreshInterval, c.RefreshInterval)
+}
+
+// GetSecretsConfig hydrates the secrets config struct.
+func GetSecretsConfig() SecretsConfig {
+	return SecretsConfig{
+		VaultAddr:       viper.GetString(configKeySecretsVaultAddr),
+		VaultToken:      viper.GetString(configKeySecretsVaultToken),
+		RefreshInterval: viper.GetInt(configKeySecretsRefreshInterval),
+		EncryptionKeys:  viper.GetString(configKeySecretsEncryptionKeys),
+	}
+}
+
+// RegisterSecretsConfig is used by a Cobra command to register the secrets CLI flags.
+func RegisterSecretsConfig(cmd *cobra.Command) {
+	flags := cmd.PersistentFlags()
+
+	{
+		const (
+			key          = configKeySecretsVaultAddr
+			longOpt      = "secrets-vault-addr"
+			defaultValue = ""
+			description  = "The address of the Vault server used to resolve vault:// secret references"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeySecretsVaultToken
+			longOpt      = "secrets-vault-token"
+			defaultValue = ""
+			description  = "The Vault token used to resolve vault:// secret references"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeySecretsRefreshInterval
+			longOpt      = "secrets-refresh-interval"
+			defaultValue = 300
+			description  = "The time in seconds after which secret references are re-resolved"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeySecretsEncryptionKeys
+			longOpt      = "secrets-encryption-keys"
+			defaultValue = ""
+			description  = "Comma separated <key-id>=<key> pairs used to encrypt sensitive fields at rest"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+}
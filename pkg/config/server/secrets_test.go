@@ -0,0 +1,19 @@
+package server
+
+import (
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SecretsConfig(t *testing.T) {
+	fakeCMD := &cobra.Command{}
+	RegisterSecretsConfig(fakeCMD)
+
+	cfg := GetSecretsConfig()
+	assert.Equal(t, "", cfg.VaultAddr)
+	assert.Equal(t, "", cfg.VaultToken)
+	assert.Equal(t, 300, cfg.RefreshInterval)
+	assert.Equal(t, "", cfg.EncryptionKeys)
+}
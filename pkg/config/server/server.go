@@ -10,35 +10,184 @@ const (
 	configKeyBindAddrDefault                     = "127.0.0.1"
 	configKeyBindPortDefault                     = 8000
 	configKeyStorageBackendConsulPathDefault     = "sherpa/"
+	configKeyStorageBackendEtcdPathDefault       = "sherpa/"
 	configKeyAutoscalerEvaluationIntervalDefault = 60
 
 	configKeyBindAddr                          = "bind-addr"
 	configKeyBindPort                          = "bind-port"
 	configKeyAutoscalerEnabled                 = "autoscaler-enabled"
+	configKeyAutoscalerBoundsEnforcement       = "autoscaler-bounds-enforcement"
 	configKeyAutoscalerEvaluationInterval      = "autoscaler-evaluation-interval"
+	configKeyAutoscalerEvaluationLogPath       = "autoscaler-evaluation-log-path"
 	configKeyAutoscalerThreadNumber            = "autoscaler-num-threads"
 	configKeyAutoscalerThreadNumberDefault     = 3
+	configKeyAutoscalerThreadMin               = "autoscaler-min-threads"
+	configKeyAutoscalerThreadMax               = "autoscaler-max-threads"
+	configKeyAutoscalerNomadLatencyThreshold   = "autoscaler-nomad-latency-threshold"
+	configKeyAutoscalerMaxStaleness            = "autoscaler-max-staleness"
+	configKeyAutoscalerMinStatsCoverage        = "autoscaler-min-stats-coverage"
+	configKeyAutoscalerGroupConcurrency        = "autoscaler-group-concurrency"
+	configKeyAutoscalerAllocStatsCacheTTL      = "autoscaler-alloc-stats-cache-ttl"
+	configKeyAutoscalerDrainAwareScaleIn       = "autoscaler-drain-aware-scale-in"
+	configKeyAutoscalerOrphanPolicyGC          = "autoscaler-orphan-policy-gc"
+	configKeyAutoscalerEvaluationTimeout       = "autoscaler-evaluation-timeout"
+	configKeyAutoscalerShadowMode              = "autoscaler-shadow-mode"
+	configKeyFeatureFlags                      = "feature-flags"
+	configKeyNomadAPITimeout                   = "nomad-api-timeout"
 	configKeyPolicyEngineAPIEnabled            = "policy-engine-api-enabled"
+	configKeyPolicyEngineFileDir               = "policy-engine-file-dir"
 	configKeyPolicyEngineNomadMetaEnabled      = "policy-engine-nomad-meta-enabled"
+	configKeyPolicyEngineNomadMetaKeyPrefix    = "policy-engine-nomad-meta-key-prefix"
 	configKeyPolicyEngineStrictCheckingEnabled = "policy-engine-strict-checking-enabled"
+	configKeyReadOnly                          = "read-only"
+	configKeyScalingHooksExecEnabled           = "scaling-hooks-exec-enabled"
+	configKeyScalingHooksSigningSecret         = "scaling-hooks-signing-secret"
+	configKeyScaleForceEnabled                 = "scale-force-enabled"
+	configKeyScaleInPromotionGuard             = "scale-in-promotion-guard"
 	configKeyStorageBackendConsulEnabled       = "storage-consul-enabled"
 	configKeyStorageBackendConsulPath          = "storage-consul-path"
+	configKeyStorageBackendConsulPolicyToken   = "storage-consul-policy-token"
+	configKeyStorageBackendConsulPolicyWatch   = "storage-consul-policy-watch"
+	configKeyStorageBackendEtcdEndpoints       = "storage-etcd-endpoints"
+	configKeyStorageBackendEtcdPath            = "storage-etcd-path"
+	configKeyStorageBackendEtcdUsername        = "storage-etcd-username"
+	configKeyStorageBackendEtcdPassword        = "storage-etcd-password"
+	configKeyStorageBackendEtcdPolicyWatch     = "storage-etcd-policy-watch"
+	configKeyStorageBackendPostgresURL         = "storage-postgres-url"
+	configKeyStorageBackendPostgresMaxOpen     = "storage-postgres-max-open-conns"
+	configKeyStorageBackendPostgresMaxIdle     = "storage-postgres-max-idle-conns"
+	configKeyStorageBackendPostgresMaxLifetime = "storage-postgres-conn-max-lifetime"
+	configKeyPostgresMaxOpenConnsDefault       = 10
+	configKeyPostgresMaxIdleConnsDefault       = 2
+	configKeyPostgresConnMaxLifetimeDefault    = 300
 
 	configKeyUI = "ui"
 )
 
 type Config struct {
-	Bind                         string
-	ConsulStorageBackendPath     string
-	Port                         uint16
-	APIPolicyEngine              bool
-	NomadMetaPolicyEngine        bool
-	StrictPolicyChecking         bool
-	InternalAutoScaler           bool
-	ConsulStorageBackend         bool
-	UI                           bool
-	InternalAutoScalerEvalPeriod int
-	InternalAutoScalerNumThreads int
+	Bind                          string
+	ConsulStorageBackendPath      string
+	Port                          uint16
+	APIPolicyEngine               bool
+	NomadMetaPolicyEngine         bool
+	StrictPolicyChecking          bool
+	InternalAutoScaler            bool
+	ConsulStorageBackend          bool
+	UI                            bool
+	InternalAutoScalerEvalPeriod  int
+	InternalAutoScalerNumThreads  int
+	InternalAutoScalerEvalLogPath string
+
+	// ConsulStorageBackendPolicyToken is the Consul ACL token, or secret reference, used for
+	// policy requests. If empty, the token of the Consul client is used.
+	ConsulStorageBackendPolicyToken string
+
+	// ConsulStorageBackendPolicyWatch enables a cache of the Consul stored policies, refreshed
+	// using blocking queries, which is used to serve policy reads.
+	ConsulStorageBackendPolicyWatch bool
+
+	// EtcdStorageBackendEndpoints are the client URLs of the etcd cluster used to store policies.
+	// If empty, etcd is not used. EtcdStorageBackendPath is the key prefix policies are stored
+	// under.
+	EtcdStorageBackendEndpoints []string
+	EtcdStorageBackendPath      string
+
+	// EtcdStorageBackendUsername and EtcdStorageBackendPassword, which can be a secret reference,
+	// authenticate requests when etcd auth is enabled.
+	EtcdStorageBackendUsername string
+	EtcdStorageBackendPassword string
+
+	// EtcdStorageBackendPolicyWatch enables a cache of the etcd stored policies, invalidated
+	// using a watch, which is used to serve policy reads.
+	EtcdStorageBackendPolicyWatch bool
+
+	// PostgresStorageBackendURL is the connection URL, or secret reference, of the PostgreSQL
+	// database used to store policies and scaling state. If empty, PostgreSQL is not used.
+	PostgresStorageBackendURL string
+
+	// PostgresStorageBackendMaxOpenConns and PostgresStorageBackendMaxIdleConns bound the
+	// connection pool, and PostgresStorageBackendConnMaxLifetime is the time in seconds after
+	// which a connection is replaced. Zero values mean no limit.
+	PostgresStorageBackendMaxOpenConns    int
+	PostgresStorageBackendMaxIdleConns    int
+	PostgresStorageBackendConnMaxLifetime int
+
+	// NomadMetaPolicyEngineKeyPrefix is the prefix of the Nomad meta keys used to discover and
+	// configure scaling policies when the Nomad meta policy engine is enabled.
+	NomadMetaPolicyEngineKeyPrefix string
+
+	// PolicyEngineFileDir is the directory of JSON and HCL policy files served by the file policy
+	// engine. If empty, the file policy engine is disabled.
+	PolicyEngineFileDir string
+
+	// InternalAutoScalerMinThreads and InternalAutoScalerMaxThreads bound the autoscaler worker
+	// pool when auto-tuning is enabled by setting the maximum. The pool shrinks when the Nomad API
+	// latency, in milliseconds, exceeds InternalAutoScalerNomadLatencyThreshold.
+	InternalAutoScalerMinThreads            int
+	InternalAutoScalerMaxThreads            int
+	InternalAutoScalerNomadLatencyThreshold int
+
+	// InternalAutoScalerMaxStaleness is the time in seconds a job group can go without evaluation
+	// before its evaluation is no longer deferred when the worker pool is saturated.
+	InternalAutoScalerMaxStaleness int
+
+	// InternalAutoScalerMinStatsCoverage is the minimum percentage of a job group's allocations
+	// whose resource stats must be gathered for the group to be evaluated using Nomad checks.
+	InternalAutoScalerMinStatsCoverage float64
+
+	// InternalAutoScalerGroupConcurrency is the maximum number of job groups evaluated, and
+	// allocation stats gathered, concurrently within a single job evaluation.
+	InternalAutoScalerGroupConcurrency int
+
+	// InternalAutoScalerAllocStatsCacheTTL is the time in seconds cached allocation resource
+	// usage is used by autoscaler evaluations. Caching is disabled when this is zero.
+	InternalAutoScalerAllocStatsCacheTTL int
+
+	// InternalAutoScalerDrainAwareScaleIn reduces autoscaler scale in decisions by the number of
+	// group allocations placed on draining or scheduling ineligible nodes.
+	InternalAutoScalerDrainAwareScaleIn bool
+
+	// InternalAutoScalerOrphanPolicyGC is the time in seconds the job of a scaling policy can be
+	// stopped or purged before the autoscaler deletes the policy. This is disabled when zero.
+	InternalAutoScalerOrphanPolicyGC int
+
+	// InternalAutoScalerEvalTimeout is the time in seconds a single job evaluation can take before
+	// it is cancelled, and NomadAPITimeout is the time in seconds a single Nomad API call made by
+	// the autoscaler or scaler can take.
+	InternalAutoScalerEvalTimeout int
+	NomadAPITimeout               int
+
+	// InternalAutoScalerBoundsEnforcement controls whether the autoscaler checks job groups are
+	// within their policy min and max counts on each evaluation, even when no metric thresholds
+	// have been breached.
+	InternalAutoScalerBoundsEnforcement BoundsEnforcement
+
+	// ScalingHooksExecEnabled allows policy scaling hooks to execute local commands. This is
+	// disabled by default as policies can be written using the API.
+	ScalingHooksExecEnabled bool
+
+	// ScalingHooksSigningSecret is the secret, or secret reference, used to sign the payloads of
+	// HTTP scaling hooks. If empty, payloads are not signed.
+	ScalingHooksSigningSecret string
+
+	// ScaleForceEnabled allows absolute count scaling API requests to use the force param, which
+	// skips the job group scaling policy minimum and maximum count checks.
+	ScaleForceEnabled bool
+
+	// ScaleInPromotionGuard is the action taken when a scale in request targets a job group whose
+	// deployment has canaries awaiting promotion.
+	ScaleInPromotionGuard PromotionGuard
+
+	// ReadOnly rejects all API requests which mutate policies or trigger scaling, and runs the
+	// internal autoscaler in dry-run mode.
+	ReadOnly bool
+
+	// InternalAutoScalerShadowMode runs the internal autoscaler in dry-run mode, comparing its
+	// decisions with the scaling actions of a co-deployed Nomad Autoscaler.
+	InternalAutoScalerShadowMode bool
+
+	// FeatureFlags are the names of the experimental features enabled on the server.
+	FeatureFlags []string
 }
 
 func (c *Config) MarshalZerologObject(e *zerolog.Event) {
@@ -46,28 +195,90 @@ func (c *Config) MarshalZerologObject(e *zerolog.Event) {
 		Uint16(configKeyBindPort, c.Port).
 		Bool(configKeyPolicyEngineAPIEnabled, c.APIPolicyEngine).
 		Bool(configKeyPolicyEngineNomadMetaEnabled, c.NomadMetaPolicyEngine).
+		Str(configKeyPolicyEngineNomadMetaKeyPrefix, c.NomadMetaPolicyEngineKeyPrefix).
+		Str(configKeyPolicyEngineFileDir, c.PolicyEngineFileDir).
 		Bool(configKeyPolicyEngineStrictCheckingEnabled, c.StrictPolicyChecking).
 		Bool(configKeyAutoscalerEnabled, c.InternalAutoScaler).
 		Int(configKeyAutoscalerEvaluationInterval, c.InternalAutoScalerEvalPeriod).
 		Int(configKeyAutoscalerThreadNumber, c.InternalAutoScalerNumThreads).
+		Int(configKeyAutoscalerThreadMin, c.InternalAutoScalerMinThreads).
+		Int(configKeyAutoscalerThreadMax, c.InternalAutoScalerMaxThreads).
+		Int(configKeyAutoscalerNomadLatencyThreshold, c.InternalAutoScalerNomadLatencyThreshold).
+		Int(configKeyAutoscalerMaxStaleness, c.InternalAutoScalerMaxStaleness).
+		Float64(configKeyAutoscalerMinStatsCoverage, c.InternalAutoScalerMinStatsCoverage).
+		Int(configKeyAutoscalerGroupConcurrency, c.InternalAutoScalerGroupConcurrency).
+		Int(configKeyAutoscalerAllocStatsCacheTTL, c.InternalAutoScalerAllocStatsCacheTTL).
+		Bool(configKeyAutoscalerDrainAwareScaleIn, c.InternalAutoScalerDrainAwareScaleIn).
+		Int(configKeyAutoscalerOrphanPolicyGC, c.InternalAutoScalerOrphanPolicyGC).
+		Int(configKeyAutoscalerEvaluationTimeout, c.InternalAutoScalerEvalTimeout).
+		Int(configKeyNomadAPITimeout, c.NomadAPITimeout).
+		Str(configKeyAutoscalerBoundsEnforcement, c.InternalAutoScalerBoundsEnforcement.String()).
+		Bool(configKeyScalingHooksExecEnabled, c.ScalingHooksExecEnabled).
+		Bool(configKeyScaleForceEnabled, c.ScaleForceEnabled).
+		Str(configKeyScaleInPromotionGuard, c.ScaleInPromotionGuard.String()).
+		Bool(configKeyReadOnly, c.ReadOnly).
+		Bool(configKeyAutoscalerShadowMode, c.InternalAutoScalerShadowMode).
+		Strs(configKeyFeatureFlags, c.FeatureFlags).
+		Str(configKeyAutoscalerEvaluationLogPath, c.InternalAutoScalerEvalLogPath).
 		Bool(configKeyStorageBackendConsulEnabled, c.ConsulStorageBackend).
 		Str(configKeyStorageBackendConsulPath, c.ConsulStorageBackendPath).
+		Bool(configKeyStorageBackendConsulPolicyWatch, c.ConsulStorageBackendPolicyWatch).
+		Strs(configKeyStorageBackendEtcdEndpoints, c.EtcdStorageBackendEndpoints).
+		Str(configKeyStorageBackendEtcdPath, c.EtcdStorageBackendPath).
+		Str(configKeyStorageBackendEtcdUsername, c.EtcdStorageBackendUsername).
+		Bool(configKeyStorageBackendEtcdPolicyWatch, c.EtcdStorageBackendPolicyWatch).
+		Int(configKeyStorageBackendPostgresMaxOpen, c.PostgresStorageBackendMaxOpenConns).
+		Int(configKeyStorageBackendPostgresMaxIdle, c.PostgresStorageBackendMaxIdleConns).
+		Int(configKeyStorageBackendPostgresMaxLifetime, c.PostgresStorageBackendConnMaxLifetime).
 		Bool(configKeyUI, c.UI)
 }
 
 func GetConfig() Config {
 	return Config{
-		Bind:                         viper.GetString(configKeyBindAddr),
-		Port:                         uint16(viper.GetInt(configKeyBindPort)),
-		APIPolicyEngine:              viper.GetBool(configKeyPolicyEngineAPIEnabled),
-		NomadMetaPolicyEngine:        viper.GetBool(configKeyPolicyEngineNomadMetaEnabled),
-		StrictPolicyChecking:         viper.GetBool(configKeyPolicyEngineStrictCheckingEnabled),
-		InternalAutoScaler:           viper.GetBool(configKeyAutoscalerEnabled),
-		InternalAutoScalerEvalPeriod: viper.GetInt(configKeyAutoscalerEvaluationInterval),
-		InternalAutoScalerNumThreads: viper.GetInt(configKeyAutoscalerThreadNumber),
-		ConsulStorageBackend:         viper.GetBool(configKeyStorageBackendConsulEnabled),
-		ConsulStorageBackendPath:     viper.GetString(configKeyStorageBackendConsulPath),
-		UI:                           viper.GetBool(configKeyUI),
+		Bind:                                    viper.GetString(configKeyBindAddr),
+		Port:                                    uint16(viper.GetInt(configKeyBindPort)),
+		APIPolicyEngine:                         viper.GetBool(configKeyPolicyEngineAPIEnabled),
+		NomadMetaPolicyEngine:                   viper.GetBool(configKeyPolicyEngineNomadMetaEnabled),
+		NomadMetaPolicyEngineKeyPrefix:          viper.GetString(configKeyPolicyEngineNomadMetaKeyPrefix),
+		PolicyEngineFileDir:                     viper.GetString(configKeyPolicyEngineFileDir),
+		StrictPolicyChecking:                    viper.GetBool(configKeyPolicyEngineStrictCheckingEnabled),
+		InternalAutoScaler:                      viper.GetBool(configKeyAutoscalerEnabled),
+		InternalAutoScalerEvalPeriod:            viper.GetInt(configKeyAutoscalerEvaluationInterval),
+		InternalAutoScalerNumThreads:            viper.GetInt(configKeyAutoscalerThreadNumber),
+		InternalAutoScalerEvalLogPath:           viper.GetString(configKeyAutoscalerEvaluationLogPath),
+		InternalAutoScalerMinThreads:            viper.GetInt(configKeyAutoscalerThreadMin),
+		InternalAutoScalerMaxThreads:            viper.GetInt(configKeyAutoscalerThreadMax),
+		InternalAutoScalerNomadLatencyThreshold: viper.GetInt(configKeyAutoscalerNomadLatencyThreshold),
+		InternalAutoScalerMaxStaleness:          viper.GetInt(configKeyAutoscalerMaxStaleness),
+		InternalAutoScalerMinStatsCoverage:      viper.GetFloat64(configKeyAutoscalerMinStatsCoverage),
+		InternalAutoScalerGroupConcurrency:      viper.GetInt(configKeyAutoscalerGroupConcurrency),
+		InternalAutoScalerAllocStatsCacheTTL:    viper.GetInt(configKeyAutoscalerAllocStatsCacheTTL),
+		InternalAutoScalerDrainAwareScaleIn:     viper.GetBool(configKeyAutoscalerDrainAwareScaleIn),
+		InternalAutoScalerOrphanPolicyGC:        viper.GetInt(configKeyAutoscalerOrphanPolicyGC),
+		InternalAutoScalerEvalTimeout:           viper.GetInt(configKeyAutoscalerEvaluationTimeout),
+		NomadAPITimeout:                         viper.GetInt(configKeyNomadAPITimeout),
+		InternalAutoScalerBoundsEnforcement:     BoundsEnforcement(viper.GetString(configKeyAutoscalerBoundsEnforcement)),
+		ScalingHooksExecEnabled:                 viper.GetBool(configKeyScalingHooksExecEnabled),
+		ScalingHooksSigningSecret:               viper.GetString(configKeyScalingHooksSigningSecret),
+		ScaleForceEnabled:                       viper.GetBool(configKeyScaleForceEnabled),
+		ScaleInPromotionGuard:                   PromotionGuard(viper.GetString(configKeyScaleInPromotionGuard)),
+		ReadOnly:                                viper.GetBool(configKeyReadOnly),
+		InternalAutoScalerShadowMode:            viper.GetBool(configKeyAutoscalerShadowMode),
+		FeatureFlags:                            splitList(viper.GetString(configKeyFeatureFlags)),
+		ConsulStorageBackend:                    viper.GetBool(configKeyStorageBackendConsulEnabled),
+		ConsulStorageBackendPath:                viper.GetString(configKeyStorageBackendConsulPath),
+		ConsulStorageBackendPolicyToken:         viper.GetString(configKeyStorageBackendConsulPolicyToken),
+		ConsulStorageBackendPolicyWatch:         viper.GetBool(configKeyStorageBackendConsulPolicyWatch),
+		EtcdStorageBackendEndpoints:             splitList(viper.GetString(configKeyStorageBackendEtcdEndpoints)),
+		EtcdStorageBackendPath:                  viper.GetString(configKeyStorageBackendEtcdPath),
+		EtcdStorageBackendUsername:              viper.GetString(configKeyStorageBackendEtcdUsername),
+		EtcdStorageBackendPassword:              viper.GetString(configKeyStorageBackendEtcdPassword),
+		EtcdStorageBackendPolicyWatch:           viper.GetBool(configKeyStorageBackendEtcdPolicyWatch),
+		PostgresStorageBackendURL:               viper.GetString(configKeyStorageBackendPostgresURL),
+		PostgresStorageBackendMaxOpenConns:      viper.GetInt(configKeyStorageBackendPostgresMaxOpen),
+		PostgresStorageBackendMaxIdleConns:      viper.GetInt(configKeyStorageBackendPostgresMaxIdle),
+		PostgresStorageBackendConnMaxLifetime:   viper.GetInt(configKeyStorageBackendPostgresMaxLifetime),
+		UI:                                      viper.GetBool(configKeyUI),
 	}
 }
 
@@ -113,6 +324,19 @@ func RegisterConfig(cmd *cobra.Command) {
 		viper.SetDefault(key, defaultValue)
 	}
 
+	{
+		const (
+			key          = configKeyPolicyEngineFileDir
+			longOpt      = "policy-engine-file-dir"
+			defaultValue = ""
+			description  = "The directory of policy files to load, and reload on change, scaling policies from"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
 	{
 		const (
 			key          = configKeyPolicyEngineNomadMetaEnabled
@@ -126,6 +350,19 @@ func RegisterConfig(cmd *cobra.Command) {
 		viper.SetDefault(key, defaultValue)
 	}
 
+	{
+		const (
+			key          = configKeyPolicyEngineNomadMetaKeyPrefix
+			longOpt      = "policy-engine-nomad-meta-key-prefix"
+			defaultValue = "sherpa_"
+			description  = "The prefix of Nomad meta keys used to configure scaling policies"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
 	{
 		const (
 			key          = configKeyPolicyEngineStrictCheckingEnabled
@@ -178,6 +415,266 @@ func RegisterConfig(cmd *cobra.Command) {
 		viper.SetDefault(key, defaultValue)
 	}
 
+	{
+		const (
+			key          = configKeyAutoscalerThreadMin
+			longOpt      = "autoscaler-min-threads"
+			defaultValue = 1
+			description  = "The minimum number of autoscaler threads when worker pool auto-tuning is enabled"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerThreadMax
+			longOpt      = "autoscaler-max-threads"
+			defaultValue = 0
+			description  = "The maximum number of autoscaler threads, setting this enables worker pool auto-tuning"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerNomadLatencyThreshold
+			longOpt      = "autoscaler-nomad-latency-threshold"
+			defaultValue = 1000
+			description  = "The Nomad API latency in milliseconds above which the auto-tuned worker pool is shrunk"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerMaxStaleness
+			longOpt      = "autoscaler-max-staleness"
+			defaultValue = 0
+			description  = "The time in seconds a job group can go without evaluation before its evaluation is no longer deferred when the worker pool is saturated, 0 disables deferral"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerMinStatsCoverage
+			longOpt      = "autoscaler-min-stats-coverage"
+			defaultValue = 100.0
+			description  = "The minimum percentage of a job group's allocations whose stats must be gathered to evaluate the group using Nomad checks"
+		)
+
+		flags.Float64(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerGroupConcurrency
+			longOpt      = "autoscaler-group-concurrency"
+			defaultValue = 4
+			description  = "The maximum number of job groups evaluated, and allocation stats gathered, concurrently within a single job evaluation"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerAllocStatsCacheTTL
+			longOpt      = "autoscaler-alloc-stats-cache-ttl"
+			defaultValue = 0
+			description  = "The time in seconds cached allocation resource usage is used by autoscaler evaluations, 0 disables the cache"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerDrainAwareScaleIn
+			longOpt      = "autoscaler-drain-aware-scale-in"
+			defaultValue = false
+			description  = "Reduce scale in decisions by the number of group allocations on draining or ineligible nodes"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerOrphanPolicyGC
+			longOpt      = "autoscaler-orphan-policy-gc"
+			defaultValue = 0
+			description  = "The time in seconds a job can be stopped or purged before its scaling policy is deleted, 0 disables deletion"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerEvaluationTimeout
+			longOpt      = "autoscaler-evaluation-timeout"
+			defaultValue = 120
+			description  = "The time in seconds a single job evaluation can take before it is cancelled"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyNomadAPITimeout
+			longOpt      = "nomad-api-timeout"
+			defaultValue = 30
+			description  = "The time in seconds a single Nomad API call made when scaling can take before it is cancelled"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyScalingHooksExecEnabled
+			longOpt      = "scaling-hooks-exec-enabled"
+			defaultValue = false
+			description  = "Allow policy scaling hooks to execute local commands"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyScalingHooksSigningSecret
+			longOpt      = "scaling-hooks-signing-secret"
+			defaultValue = ""
+			description  = "The secret used to sign the payloads of HTTP scaling hooks"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyScaleForceEnabled
+			longOpt      = "scale-force-enabled"
+			defaultValue = false
+			description  = "Allow absolute count scaling requests to force counts outside of the policy bounds"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyScaleInPromotionGuard
+			longOpt      = "scale-in-promotion-guard"
+			defaultValue = string(PromotionGuardBlock)
+			description  = "The action taken on scale in requests while canaries await promotion: block or defer"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyReadOnly
+			longOpt      = "read-only"
+			defaultValue = false
+			description  = "Reject API requests which mutate state and run the autoscaler in dry-run mode"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyFeatureFlags
+			longOpt      = "feature-flags"
+			defaultValue = ""
+			description  = "Comma separated experimental features to enable, such as predictive-scaling"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerShadowMode
+			longOpt      = "autoscaler-shadow-mode"
+			defaultValue = false
+			description  = "Run the autoscaler in dry-run mode, comparing its decisions with a co-deployed Nomad Autoscaler"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerBoundsEnforcement
+			longOpt      = "autoscaler-bounds-enforcement"
+			defaultValue = string(BoundsEnforcementDisabled)
+			description  = "The action taken when a job group count is outside its policy bounds: disabled, alert or correct"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyAutoscalerEvaluationLogPath
+			longOpt      = "autoscaler-evaluation-log-path"
+			defaultValue = ""
+			description  = "The path of a file to write a JSON record of each autoscaling evaluation to"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
 	{
 		const (
 			key          = configKeyStorageBackendConsulEnabled
@@ -204,6 +701,149 @@ func RegisterConfig(cmd *cobra.Command) {
 		viper.SetDefault(key, defaultValue)
 	}
 
+	{
+		const (
+			key          = configKeyStorageBackendConsulPolicyToken
+			longOpt      = "storage-consul-policy-token"
+			defaultValue = ""
+			description  = "The Consul ACL token used for scaling policy requests, overriding the Consul client token"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyStorageBackendConsulPolicyWatch
+			longOpt      = "storage-consul-policy-watch"
+			defaultValue = false
+			description  = "Cache Consul stored scaling policies, refreshing the cache using blocking queries"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyStorageBackendEtcdEndpoints
+			longOpt      = "storage-etcd-endpoints"
+			defaultValue = ""
+			description  = "Comma separated etcd client URLs, which enable etcd as the storage backend for scaling policies"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyStorageBackendEtcdPath
+			longOpt      = "storage-etcd-path"
+			defaultValue = configKeyStorageBackendEtcdPathDefault
+			description  = "The etcd key prefix that will be used to store policies"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyStorageBackendEtcdUsername
+			longOpt      = "storage-etcd-username"
+			defaultValue = ""
+			description  = "The etcd user used to authenticate policy requests when etcd auth is enabled"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyStorageBackendEtcdPassword
+			longOpt      = "storage-etcd-password"
+			defaultValue = ""
+			description  = "The password of the etcd user"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyStorageBackendEtcdPolicyWatch
+			longOpt      = "storage-etcd-policy-watch"
+			defaultValue = false
+			description  = "Cache etcd stored scaling policies, invalidating the cache using a watch"
+		)
+
+		flags.Bool(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyStorageBackendPostgresURL
+			longOpt      = "storage-postgres-url"
+			defaultValue = ""
+			description  = "The PostgreSQL connection URL, which enables PostgreSQL as the storage backend for policies and state"
+		)
+
+		flags.String(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyStorageBackendPostgresMaxOpen
+			longOpt      = "storage-postgres-max-open-conns"
+			defaultValue = configKeyPostgresMaxOpenConnsDefault
+			description  = "The maximum number of open PostgreSQL connections, where 0 means no limit"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyStorageBackendPostgresMaxIdle
+			longOpt      = "storage-postgres-max-idle-conns"
+			defaultValue = configKeyPostgresMaxIdleConnsDefault
+			description  = "The maximum number of idle PostgreSQL connections kept in the pool"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
+	{
+		const (
+			key          = configKeyStorageBackendPostgresMaxLifetime
+			longOpt      = "storage-postgres-conn-max-lifetime"
+			defaultValue = configKeyPostgresConnMaxLifetimeDefault
+			description  = "The time in seconds after which a PostgreSQL connection is replaced, where 0 means no limit"
+		)
+
+		flags.Int(longOpt, defaultValue, description)
+		_ = viper.BindPFlag(key, flags.Lookup(longOpt))
+		viper.SetDefault(key, defaultValue)
+	}
+
 	{
 		const (
 			key          = configKeyUI
@@ -16,9 +16,42 @@ func Test_ServerConfig(t *testing.T) {
 	assert.Equal(t, uint16(configKeyBindPortDefault), cfg.Port)
 	assert.Equal(t, true, cfg.APIPolicyEngine)
 	assert.Equal(t, false, cfg.NomadMetaPolicyEngine)
+	assert.Equal(t, "sherpa_", cfg.NomadMetaPolicyEngineKeyPrefix)
+	assert.Equal(t, "", cfg.PolicyEngineFileDir)
 	assert.Equal(t, true, cfg.StrictPolicyChecking)
 	assert.Equal(t, false, cfg.InternalAutoScaler)
 	assert.Equal(t, configKeyStorageBackendConsulPathDefault, cfg.ConsulStorageBackendPath)
 	assert.Equal(t, configKeyAutoscalerThreadNumberDefault, cfg.InternalAutoScalerNumThreads)
+	assert.Equal(t, "", cfg.InternalAutoScalerEvalLogPath)
+	assert.Equal(t, 1, cfg.InternalAutoScalerMinThreads)
+	assert.Equal(t, 0, cfg.InternalAutoScalerMaxThreads)
+	assert.Equal(t, 1000, cfg.InternalAutoScalerNomadLatencyThreshold)
+	assert.Equal(t, 0, cfg.InternalAutoScalerMaxStaleness)
+	assert.Equal(t, float64(100), cfg.InternalAutoScalerMinStatsCoverage)
+	assert.Equal(t, 4, cfg.InternalAutoScalerGroupConcurrency)
+	assert.Equal(t, 0, cfg.InternalAutoScalerAllocStatsCacheTTL)
+	assert.False(t, cfg.InternalAutoScalerDrainAwareScaleIn)
+	assert.Equal(t, 0, cfg.InternalAutoScalerOrphanPolicyGC)
+	assert.Equal(t, 120, cfg.InternalAutoScalerEvalTimeout)
+	assert.Equal(t, 30, cfg.NomadAPITimeout)
+	assert.Equal(t, BoundsEnforcementDisabled, cfg.InternalAutoScalerBoundsEnforcement)
+	assert.Equal(t, false, cfg.ScalingHooksExecEnabled)
+	assert.Equal(t, "", cfg.ScalingHooksSigningSecret)
+	assert.Equal(t, "", cfg.ConsulStorageBackendPolicyToken)
+	assert.False(t, cfg.ConsulStorageBackendPolicyWatch)
+	assert.Nil(t, cfg.EtcdStorageBackendEndpoints)
+	assert.Equal(t, configKeyStorageBackendEtcdPathDefault, cfg.EtcdStorageBackendPath)
+	assert.Equal(t, "", cfg.EtcdStorageBackendUsername)
+	assert.Equal(t, "", cfg.EtcdStorageBackendPassword)
+	assert.False(t, cfg.EtcdStorageBackendPolicyWatch)
+	assert.Equal(t, "", cfg.PostgresStorageBackendURL)
+	assert.Equal(t, 10, cfg.PostgresStorageBackendMaxOpenConns)
+	assert.Equal(t, 2, cfg.PostgresStorageBackendMaxIdleConns)
+	assert.Equal(t, 300, cfg.PostgresStorageBackendConnMaxLifetime)
+	assert.Equal(t, false, cfg.ScaleForceEnabled)
+	assert.Equal(t, PromotionGuardBlock, cfg.ScaleInPromotionGuard)
+	assert.Equal(t, false, cfg.ReadOnly)
+	assert.Equal(t, false, cfg.InternalAutoScalerShadowMode)
+	assert.Nil(t, cfg.FeatureFlags)
 	assert.Equal(t, false, cfg.UI)
 }
@@ -0,0 +1,157 @@
+// Package encryption provides AES-GCM encryption of sensitive values, such as webhook URLs which
+// contain tokens, before they are written to a storage backend. Each encrypted value records the
+// ID of the key used, allowing keys to be rotated while values encrypted using previous keys can
+// still be read.
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// prefix identifies encrypted values, which take the form enc:v1:<key-id>:<base64 nonce+ciphertext>.
+const prefix = "enc:v1:"
+
+// KeySize is the required size in bytes of each encryption key, selecting AES-256.
+const KeySize = 32
+
+// Keyring holds the keys used to encrypt and decrypt values. The primary key encrypts all new
+// values, while every key in the ring can be used to decrypt.
+type Keyring struct {
+	primary string
+	keys    map[string]cipher.AEAD
+}
+
+// Key is a single named encryption key.
+type Key struct {
+	ID  string
+	Key []byte
+}
+
+// NewKeyring creates a keyring from the keys, using the first as the primary key.
+func NewKeyring(keys []Key) (*Keyring, error) {
+	if len(keys) == 0 {
+		return nil, errors.New("keyring requires at least one key")
+	}
+
+	kr := &Keyring{primary: keys[0].ID, keys: make(map[string]cipher.AEAD, len(keys))}
+
+	for _, k := range keys {
+		if k.ID == "" || strings.Contains(k.ID, ":") {
+			return nil, errors.Errorf("invalid encryption key ID %q, must be non-empty and not contain ':'", k.ID)
+		}
+		if _, ok := kr.keys[k.ID]; ok {
+			return nil, errors.Errorf("duplicate encryption key ID %q", k.ID)
+		}
+		if len(k.Key) != KeySize {
+			return nil, errors.Errorf("encryption key %q must be %v bytes", k.ID, KeySize)
+		}
+
+		block, err := aes.NewCipher(k.Key)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create cipher for encryption key %q", k.ID)
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to create cipher for encryption key %q", k.ID)
+		}
+		kr.keys[k.ID] = aead
+	}
+	return kr, nil
+}
+
+// IsEncrypted returns whether the value was encrypted by a keyring.
+func IsEncrypted(val string) bool {
+	return strings.HasPrefix(val, prefix)
+}
+
+// Encrypt encrypts the value using the primary key. Empty values, and all values when the keyring
+// is nil, are returned unchanged.
+func (kr *Keyring) Encrypt(val string) (string, error) {
+	if kr == nil || val == "" {
+		return val, nil
+	}
+
+	aead := kr.keys[kr.primary]
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", errors.Wrap(err, "failed to generate nonce")
+	}
+
+	// The key ID is used as additional data, so a value cannot be decrypted under a different ID.
+	sealed := aead.Seal(nonce, nonce, []byte(val), []byte(kr.primary))
+	return prefix + kr.primary + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt decrypts the value using the key it was encrypted with. Values which are not encrypted
+// are returned unchanged, allowing values written before encryption was enabled to be read.
+func (kr *Keyring) Decrypt(val string) (string, error) {
+	if !IsEncrypted(val) {
+		return val, nil
+	}
+	if kr == nil {
+		return "", errors.New("value is encrypted but no encryption keys are configured")
+	}
+
+	split := strings.SplitN(strings.TrimPrefix(val, prefix), ":", 2)
+	if len(split) != 2 {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	aead, ok := kr.keys[split[0]]
+	if !ok {
+		return "", errors.Errorf("encryption key %q not found in keyring", split[0])
+	}
+
+	sealed, err := base64.StdEncoding.DecodeString(split[1])
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decode encrypted value")
+	}
+	if len(sealed) < aead.NonceSize() {
+		return "", errors.New("malformed encrypted value")
+	}
+
+	out, err := aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], []byte(split[0]))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decrypt value")
+	}
+	return string(out), nil
+}
+
+// ParseKeys parses a comma separated list of keys in the form <key-id>=<key>, where each key is
+// base64 encoded. The resolve func is called with each key before it is decoded, allowing keys
+// to be sourced from secret references. The first key is the primary key of the keyring.
+func ParseKeys(spec string, resolve func(string) (string, error)) ([]Key, error) {
+	var keys []Key // nolint:prealloc
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		split := strings.SplitN(entry, "=", 2)
+		if len(split) != 2 || split[0] == "" || split[1] == "" {
+			return nil, errors.Errorf("invalid encryption key %q, must be in the form <key-id>=<key>", entry)
+		}
+
+		encoded, err := resolve(split[1])
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve encryption key %q", split[0])
+		}
+
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode encryption key %q", split[0])
+		}
+		keys = append(keys, Key{ID: split[0], Key: key})
+	}
+	return keys, nil
+}
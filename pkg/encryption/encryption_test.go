@@ -0,0 +1,103 @@
+package encryption
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testKey(b byte) []byte { return bytes.Repeat([]byte{b}, KeySize) }
+
+func TestNewKeyring(t *testing.T) {
+	_, err := NewKeyring(nil)
+	assert.NotNil(t, err)
+
+	_, err = NewKeyring([]Key{{ID: "a", Key: []byte("short")}})
+	assert.NotNil(t, err)
+
+	_, err = NewKeyring([]Key{{ID: "a:b", Key: testKey(1)}})
+	assert.NotNil(t, err)
+
+	_, err = NewKeyring([]Key{{ID: "a", Key: testKey(1)}, {ID: "a", Key: testKey(2)}})
+	assert.NotNil(t, err)
+
+	_, err = NewKeyring([]Key{{ID: "a", Key: testKey(1)}, {ID: "b", Key: testKey(2)}})
+	assert.Nil(t, err)
+}
+
+func TestKeyring_EncryptDecrypt(t *testing.T) {
+	old, err := NewKeyring([]Key{{ID: "2023", Key: testKey(1)}})
+	assert.Nil(t, err)
+
+	rotated, err := NewKeyring([]Key{{ID: "2024", Key: testKey(2)}, {ID: "2023", Key: testKey(1)}})
+	assert.Nil(t, err)
+
+	url := "https://hooks.example.com/scale?token=s3cr3t"
+
+	enc, err := old.Encrypt(url)
+	assert.Nil(t, err)
+	assert.True(t, IsEncrypted(enc))
+	assert.True(t, strings.HasPrefix(enc, "enc:v1:2023:"))
+	assert.NotContains(t, enc, "s3cr3t")
+
+	// Values encrypted using a previous key can be decrypted after rotation, while new values
+	// use the primary key.
+	dec, err := rotated.Decrypt(enc)
+	assert.Nil(t, err)
+	assert.Equal(t, url, dec)
+
+	enc, err = rotated.Encrypt(url)
+	assert.Nil(t, err)
+	assert.True(t, strings.HasPrefix(enc, "enc:v1:2024:"))
+
+	_, err = old.Decrypt(enc)
+	assert.NotNil(t, err)
+
+	// Plaintext values are returned unchanged, while encrypted values require a keyring.
+	dec, err = rotated.Decrypt(url)
+	assert.Nil(t, err)
+	assert.Equal(t, url, dec)
+
+	var nilKeyring *Keyring
+	_, err = nilKeyring.Decrypt(enc)
+	assert.NotNil(t, err)
+
+	plain, err := nilKeyring.Encrypt(url)
+	assert.Nil(t, err)
+	assert.Equal(t, url, plain)
+
+	// Tampered values fail to decrypt.
+	_, err = rotated.Decrypt(enc[:len(enc)-4] + "AAAA")
+	assert.NotNil(t, err)
+}
+
+func TestParseKeys(t *testing.T) {
+	encoded := base64.StdEncoding.EncodeToString(testKey(1))
+	resolve := func(ref string) (string, error) {
+		if ref == "env://KEY" {
+			return encoded + "\n", nil
+		}
+		return ref, nil
+	}
+
+	keys, err := ParseKeys("2024=env://KEY, 2023="+encoded, resolve)
+	assert.Nil(t, err)
+	assert.Equal(t, []Key{{ID: "2024", Key: testKey(1)}, {ID: "2023", Key: testKey(1)}}, keys)
+
+	keys, err = ParseKeys("", resolve)
+	assert.Nil(t, err)
+	assert.Nil(t, keys)
+
+	_, err = ParseKeys("2024", resolve)
+	assert.NotNil(t, err)
+
+	_, err = ParseKeys("2024=not-base64!", resolve)
+	assert.NotNil(t, err)
+
+	_, err = ParseKeys("2024=env://MISSING", func(string) (string, error) { return "", errors.New("not set") })
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,103 @@
+// Package feature provides the flags which gate experimental subsystems, allowing them to ship
+// disabled and be enabled per deployment once they are ready to be trialled.
+package feature
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Flag is the name of an experimental feature.
+type Flag string
+
+// The experimental features which can be enabled.
+const (
+	// PredictiveScaling enables scaling job groups ahead of forecast demand.
+	PredictiveScaling Flag = "predictive-scaling"
+
+	// VerticalScaling enables adjusting the resources of job group tasks.
+	VerticalScaling Flag = "vertical-scaling"
+
+	// ClusterScaling enables scaling the Nomad client nodes of the cluster.
+	ClusterScaling Flag = "cluster-scaling"
+)
+
+// known is the set of flags which can be enabled, used to reject unknown names so that typos do
+// not silently leave a feature disabled.
+var known = map[Flag]struct{}{
+	PredictiveScaling: {},
+	VerticalScaling:   {},
+	ClusterScaling:    {},
+}
+
+// Flags holds the experimental features enabled on the server. A nil Flags has all features
+// disabled.
+type Flags struct {
+	enabled map[Flag]bool
+}
+
+// New returns the flags with the named features enabled. An error is returned if any name is not
+// a known feature.
+func New(names []string) (*Flags, error) {
+	f := &Flags{enabled: make(map[Flag]bool)}
+
+	var unknown []string
+
+	for _, name := range names {
+		flag := Flag(strings.ToLower(strings.TrimSpace(name)))
+		if flag == "" {
+			continue
+		}
+		if _, ok := known[flag]; !ok {
+			unknown = append(unknown, name)
+			continue
+		}
+		f.enabled[flag] = true
+	}
+
+	if len(unknown) > 0 {
+		return nil, errors.Errorf("unknown feature flags %s (supported flags: %s)",
+			strings.Join(unknown, ", "), strings.Join(Known(), ", "))
+	}
+	return f, nil
+}
+
+// Enabled returns whether the experimental feature is enabled.
+func (f *Flags) Enabled(flag Flag) bool {
+	if f == nil {
+		return false
+	}
+	return f.enabled[flag]
+}
+
+// EnabledFlags returns the names of the enabled features, sorted by name.
+func (f *Flags) EnabledFlags() []string {
+	out := []string{}
+	for _, name := range Known() {
+		if f.Enabled(Flag(name)) {
+			out = append(out, name)
+		}
+	}
+	return out
+}
+
+// Status returns every known feature and whether it is enabled.
+func (f *Flags) Status() map[string]bool {
+	out := make(map[string]bool, len(known))
+	for flag := range known {
+		out[string(flag)] = f.Enabled(flag)
+	}
+	return out
+}
+
+// Known returns the names of all the experimental features, sorted by name.
+func Known() []string {
+	out := make([]string, 0, len(known))
+	for flag := range known {
+		out = append(out, string(flag))
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,30 @@
+package feature
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNew(t *testing.T) {
+	f, err := New([]string{"predictive-scaling", " Vertical-Scaling ", ""})
+	assert.Nil(t, err)
+	assert.True(t, f.Enabled(PredictiveScaling))
+	assert.True(t, f.Enabled(VerticalScaling))
+	assert.False(t, f.Enabled(ClusterScaling))
+	assert.Equal(t, []string{"predictive-scaling", "vertical-scaling"}, f.EnabledFlags())
+	assert.Equal(t, map[string]bool{"cluster-scaling": false, "predictive-scaling": true, "vertical-scaling": true}, f.Status())
+
+	_, err = New([]string{"predictive-scalling"})
+	assert.EqualError(t, err, "unknown feature flags predictive-scalling (supported flags: cluster-scaling, predictive-scaling, vertical-scaling)")
+
+	f, err = New(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, []string{}, f.EnabledFlags())
+}
+
+func TestFlags_nil(t *testing.T) {
+	var f *Flags
+	assert.False(t, f.Enabled(ClusterScaling))
+	assert.Equal(t, map[string]bool{"cluster-scaling": false, "predictive-scaling": false, "vertical-scaling": false}, f.Status())
+}
@@ -0,0 +1,101 @@
+// Package filter provides the job filter which restricts the Nomad jobs a Sherpa server evaluates,
+// allowing responsibility for a cluster to be sharded across teams or Sherpa instances.
+package filter
+
+import (
+	"regexp"
+	"strings"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/pkg/errors"
+)
+
+// JobFilter determines whether a Nomad job should be evaluated by this Sherpa server. A nil
+// JobFilter matches all jobs.
+type JobFilter struct {
+	namespaces map[string]struct{}
+	name       *regexp.Regexp
+
+	// metaKey is the job meta key which must be set. If metaValue is not empty, the key must also
+	// have this value.
+	metaKey   string
+	metaValue string
+}
+
+// NewJobFilter builds the job filter from the server configuration. If no filter options are
+// configured, nil is returned so that all jobs are matched.
+func NewJobFilter(cfg *server.JobFilterConfig) (*JobFilter, error) {
+	if cfg == nil || (len(cfg.Namespaces) == 0 && cfg.NameRegex == "" && cfg.Meta == "") {
+		return nil, nil
+	}
+
+	f := JobFilter{}
+
+	if len(cfg.Namespaces) > 0 {
+		f.namespaces = make(map[string]struct{}, len(cfg.Namespaces))
+		for _, ns := range cfg.Namespaces {
+			f.namespaces[ns] = struct{}{}
+		}
+	}
+
+	if cfg.NameRegex != "" {
+		re, err := regexp.Compile(cfg.NameRegex)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to compile job filter name regex")
+		}
+		f.name = re
+	}
+
+	if cfg.Meta != "" {
+		split := strings.SplitN(cfg.Meta, "=", 2)
+		if split[0] == "" {
+			return nil, errors.Errorf("invalid job filter meta %q, must be in the form key or key=value", cfg.Meta)
+		}
+		f.metaKey = split[0]
+		if len(split) == 2 {
+			f.metaValue = split[1]
+		}
+	}
+	return &f, nil
+}
+
+// MatchID returns whether the job ID matches the filter name regex. This allows jobs to be
+// filtered without needing to read the job from Nomad.
+func (f *JobFilter) MatchID(id string) bool {
+	return f == nil || f.name == nil || f.name.MatchString(id)
+}
+
+// RequiresJob returns whether the filter includes options which can only be checked against the
+// full Nomad job, and therefore whether MatchJob needs to be called.
+func (f *JobFilter) RequiresJob() bool {
+	return f != nil && (f.namespaces != nil || f.metaKey != "")
+}
+
+// MatchJob returns whether the Nomad job matches all the filter options.
+func (f *JobFilter) MatchJob(job *nomad.Job) bool {
+	if f == nil {
+		return true
+	}
+	if job == nil || job.ID == nil || !f.MatchID(*job.ID) {
+		return false
+	}
+
+	if f.namespaces != nil {
+		ns := nomad.DefaultNamespace
+		if job.Namespace != nil && *job.Namespace != "" {
+			ns = *job.Namespace
+		}
+		if _, ok := f.namespaces[ns]; !ok {
+			return false
+		}
+	}
+
+	if f.metaKey != "" {
+		val, ok := job.Meta[f.metaKey]
+		if !ok || (f.metaValue != "" && val != f.metaValue) {
+			return false
+		}
+	}
+	return true
+}
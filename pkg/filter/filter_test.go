@@ -0,0 +1,107 @@
+package filter
+
+import (
+	"testing"
+
+	nomad "github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewJobFilter(t *testing.T) {
+	f, err := NewJobFilter(&server.JobFilterConfig{})
+	assert.Nil(t, err)
+	assert.Nil(t, f)
+
+	_, err = NewJobFilter(&server.JobFilterConfig{NameRegex: "("})
+	assert.NotNil(t, err)
+
+	_, err = NewJobFilter(&server.JobFilterConfig{Meta: "=team"})
+	assert.NotNil(t, err)
+
+	f, err = NewJobFilter(&server.JobFilterConfig{Meta: "team=platform"})
+	assert.Nil(t, err)
+	assert.Equal(t, "team", f.metaKey)
+	assert.Equal(t, "platform", f.metaValue)
+}
+
+func TestJobFilter_Match(t *testing.T) {
+	job := func(id, ns string, meta map[string]string) *nomad.Job {
+		j := &nomad.Job{ID: &id, Meta: meta}
+		if ns != "" {
+			j.Namespace = &ns
+		}
+		return j
+	}
+
+	var nilFilter *JobFilter
+	assert.True(t, nilFilter.MatchID("example"))
+	assert.True(t, nilFilter.MatchJob(job("example", "", nil)))
+	assert.False(t, nilFilter.RequiresJob())
+
+	testCases := []struct {
+		cfg             server.JobFilterConfig
+		job             *nomad.Job
+		expectedID      bool
+		expectedJob     bool
+		expectedRequire bool
+	}{
+		{
+			cfg:             server.JobFilterConfig{NameRegex: "^platform-"},
+			job:             job("platform-cache", "", nil),
+			expectedID:      true,
+			expectedJob:     true,
+			expectedRequire: false,
+		},
+		{
+			cfg:             server.JobFilterConfig{NameRegex: "^platform-"},
+			job:             job("payments-api", "", nil),
+			expectedID:      false,
+			expectedJob:     false,
+			expectedRequire: false,
+		},
+		{
+			cfg:             server.JobFilterConfig{Namespaces: []string{"default"}},
+			job:             job("example", "", nil),
+			expectedID:      true,
+			expectedJob:     true,
+			expectedRequire: true,
+		},
+		{
+			cfg:             server.JobFilterConfig{Namespaces: []string{"platform", "data"}},
+			job:             job("example", "payments", nil),
+			expectedID:      true,
+			expectedJob:     false,
+			expectedRequire: true,
+		},
+		{
+			cfg:             server.JobFilterConfig{Meta: "sherpa_instance"},
+			job:             job("example", "", map[string]string{"sherpa_instance": "a"}),
+			expectedID:      true,
+			expectedJob:     true,
+			expectedRequire: true,
+		},
+		{
+			cfg:             server.JobFilterConfig{Meta: "sherpa_instance=b"},
+			job:             job("example", "", map[string]string{"sherpa_instance": "a"}),
+			expectedID:      true,
+			expectedJob:     false,
+			expectedRequire: true,
+		},
+		{
+			cfg:             server.JobFilterConfig{NameRegex: "^example$", Meta: "sherpa_instance"},
+			job:             job("example", "", nil),
+			expectedID:      true,
+			expectedJob:     false,
+			expectedRequire: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		f, err := NewJobFilter(&tc.cfg)
+		assert.Nil(t, err)
+		assert.Equal(t, tc.expectedID, f.MatchID(*tc.job.ID), tc.cfg)
+		assert.Equal(t, tc.expectedJob, f.MatchJob(tc.job), tc.cfg)
+		assert.Equal(t, tc.expectedRequire, f.RequiresJob(), tc.cfg)
+	}
+}
@@ -0,0 +1,49 @@
+package helper
+
+import (
+	"context"
+	"time"
+)
+
+// RequestIDHeader is the HTTP header used to pass the request ID between systems.
+const RequestIDHeader = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// ContextWithTimeout returns a copy of the parent context which is cancelled once the timeout has
+// passed. A zero timeout returns a cancellable copy of the parent without a deadline.
+func ContextWithTimeout(parent context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(parent)
+	}
+	return context.WithTimeout(parent, timeout)
+}
+
+// CallWithContext runs the function, returning the context error if the context is done before
+// the function returns. This allows calls using clients which do not support contexts, such as
+// the Nomad API client, to be bounded. The function continues to run in the background until it
+// returns, so it must not write to state read by the caller once the context is done.
+func CallWithContext(ctx context.Context, f func() error) error {
+	errCh := make(chan error, 1)
+
+	go func() { errCh <- f() }()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ContextWithRequestID returns a copy of the parent context which carries the request ID.
+func ContextWithRequestID(parent context.Context, id string) context.Context {
+	return context.WithValue(parent, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by the context, or an empty string if the
+// context does not carry one.
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
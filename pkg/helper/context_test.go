@@ -0,0 +1,47 @@
+package helper
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestContextWithTimeout(t *testing.T) {
+	ctx, cancel := ContextWithTimeout(context.Background(), 0)
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+	cancel()
+	assert.Equal(t, context.Canceled, ctx.Err())
+
+	ctx, cancel = ContextWithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	_, ok = ctx.Deadline()
+	assert.True(t, ok)
+}
+
+func TestCallWithContext(t *testing.T) {
+	expectedErr := errors.New("nomad unavailable")
+	assert.Equal(t, expectedErr, CallWithContext(context.Background(), func() error { return expectedErr }))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	block := make(chan struct{})
+	defer close(block)
+
+	err := CallWithContext(ctx, func() error {
+		<-block
+		return nil
+	})
+	assert.Equal(t, context.DeadlineExceeded, err)
+}
+
+func TestRequestIDFromContext(t *testing.T) {
+	assert.Equal(t, "", RequestIDFromContext(context.Background()))
+
+	ctx := ContextWithRequestID(context.Background(), "5f1b8d3c")
+	assert.Equal(t, "5f1b8d3c", RequestIDFromContext(ctx))
+}
@@ -6,3 +6,9 @@ import "github.com/rs/zerolog"
 func LoggerWithJobContext(logger zerolog.Logger, job string) zerolog.Logger {
 	return logger.With().Str("job", job).Logger()
 }
+
+// LoggerWithEvaluationContext adds the job name and autoscaling evaluation ID to the logger as
+// context.
+func LoggerWithEvaluationContext(logger zerolog.Logger, job, evalID string) zerolog.Logger {
+	return logger.With().Str("job", job).Str("evaluation-id", evalID).Logger()
+}
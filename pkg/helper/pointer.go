@@ -2,3 +2,12 @@ package helper
 
 // Float64Pointer is a helper function to return a pointer to f.
 func Float64ToPointer(f float64) *float64 { return &f }
+
+// IntToPointer is a helper function to return a pointer to i.
+func IntToPointer(i int) *int { return &i }
+
+// StringToPointer is a helper function to return a pointer to s.
+func StringToPointer(s string) *string { return &s }
+
+// BoolToPointer is a helper function to return a pointer to b.
+func BoolToPointer(b bool) *bool { return &b }
@@ -0,0 +1,185 @@
+// Package hook runs the pre-scale and post-scale hooks configured within job group scaling
+// policies, allowing external integrations to form part of the scaling lifecycle.
+package hook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/retry"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/pkg/errors"
+)
+
+// Phase identifies the point within the scaling lifecycle at which a hook is run.
+type Phase string
+
+const (
+	// PhasePreScale hooks are run before the scaling action is submitted to Nomad.
+	PhasePreScale Phase = "pre-scale"
+
+	// PhasePostScale hooks are run once the scaling action has completed or failed.
+	PhasePostScale Phase = "post-scale"
+)
+
+func (p Phase) String() string { return string(p) }
+
+// The headers added to signed HTTP hook requests. The signature is the hex encoded HMAC-SHA256 of
+// the timestamp and request body, prefixed with the algorithm.
+const (
+	HeaderSignature = "X-Sherpa-Signature"
+	HeaderTimestamp = "X-Sherpa-Timestamp"
+
+	signaturePrefix = "sha256="
+)
+
+// ErrExecDisabled is returned when a command hook is run, but the operator has not enabled the
+// execution of local commands.
+var ErrExecDisabled = errors.New("scaling hook command execution is disabled")
+
+// Event is the JSON payload passed to hooks. Pre-scale events do not include the scaling ID,
+// evaluation ID or status as the scaling action has not yet taken place.
+type Event struct {
+	Phase Phase
+	notify.Event
+}
+
+// Runner runs scaling hooks.
+type Runner struct {
+	execEnabled bool
+	httpClient  *http.Client
+	now         func() time.Time
+
+	// signingSecret is used to sign the payloads of HTTP hooks, allowing receivers to verify the
+	// requests came from Sherpa. If nil, or resolving to an empty secret, payloads are not signed.
+	signingSecret *secret.Value
+
+	// retry is the policy used to retry failed HTTP hooks. Command hooks are not retried as they
+	// may not be idempotent.
+	retry retry.Policy
+}
+
+// NewRunner builds a hook runner. If execEnabled is false, command hooks return ErrExecDisabled
+// rather than being executed. If the signing secret is not nil, HTTP hook payloads are signed.
+func NewRunner(execEnabled bool, signingSecret *secret.Value) *Runner {
+	return &Runner{
+		execEnabled:   execEnabled,
+		httpClient:    cleanhttp.DefaultClient(),
+		now:           time.Now,
+		signingSecret: signingSecret,
+		retry:         retry.Default,
+	}
+}
+
+// Sign returns the signature of the payload sent at the timestamp, in the form used by the
+// X-Sherpa-Signature header. The timestamp is included so that receivers can reject replayed
+// requests.
+func Sign(key string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(key))
+	_, _ = mac.Write([]byte(strconv.FormatInt(timestamp, 10) + "."))
+	_, _ = mac.Write(payload)
+	return signaturePrefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Run runs the hook using the event as its payload. The hook is bound by the context and the hook
+// timeout.
+func (r *Runner) Run(ctx context.Context, h *policy.ScalingHook, event *Event) error {
+	ctx, cancel := helper.ContextWithTimeout(ctx, h.GetTimeout())
+	defer cancel()
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal scaling hook event")
+	}
+
+	if h.URL != "" {
+		err = retry.Do(ctx, retry.TargetHook, r.retry, func() error { return r.runHTTP(ctx, h.URL, payload) })
+	} else {
+		err = r.runCommand(ctx, h.Command, h.Args, payload)
+	}
+	sendHookMetrics(event.Phase, err)
+	return err
+}
+
+// runHTTP POSTs the payload to the URL, treating any non-2xx response as a failure. Client error
+// responses are marked as permanent, as retrying the request will not change the response.
+func (r *Runner) runHTTP(ctx context.Context, url string, payload []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	// Each attempt is signed using the current time, so that retries are not rejected as stale.
+	key, err := r.signingSecret.Get(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve scaling hook signing secret")
+	}
+	if key != "" {
+		ts := r.now().Unix()
+		req.Header.Set(HeaderTimestamp, strconv.FormatInt(ts, 10))
+		req.Header.Set(HeaderSignature, Sign(key, ts, payload))
+	}
+
+	if id := helper.RequestIDFromContext(ctx); id != "" {
+		req.Header.Set(helper.RequestIDHeader, id)
+	}
+
+	resp, err := r.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := fmt.Errorf("scaling hook returned non-2xx status code: %d", resp.StatusCode)
+		if resp.StatusCode < 500 {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// runCommand executes the command, passing the payload on stdin. The command output is included
+// in the returned error if the command fails.
+func (r *Runner) runCommand(ctx context.Context, command string, args []string, payload []byte) error {
+	if !r.execEnabled {
+		return ErrExecDisabled
+	}
+
+	cmd := exec.CommandContext(ctx, command, args...) // nolint:gosec
+	cmd.Stdin = bytes.NewReader(payload)
+
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "scaling hook command failed: %s", strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+func sendHookMetrics(phase Phase, err error) {
+	result := "success"
+	if err != nil {
+		result = "failure"
+	}
+
+	sendMetrics.IncrCounterWithLabels([]string{"scale", "hook"}, 1, []sendMetrics.Label{
+		{Name: "phase", Value: phase.String()},
+		{Name: "result", Value: result},
+	})
+}
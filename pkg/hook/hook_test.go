@@ -0,0 +1,110 @@
+package hook
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/retry"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunner_Run_HTTP(t *testing.T) {
+	var (
+		received  Event
+		requestID string
+		requests  int
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		requests++
+		requestID = r.Header.Get(helper.RequestIDHeader)
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&received))
+		switch received.GroupName {
+		case "fail":
+			w.WriteHeader(http.StatusInternalServerError)
+		case "reject":
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer srv.Close()
+
+	r := NewRunner(false, nil)
+	r.retry = retry.Policy{Attempts: 3, BaseDelay: time.Millisecond}
+	h := &policy.ScalingHook{URL: srv.URL}
+
+	event := &Event{Phase: PhasePreScale, Event: notify.Event{JobID: "example", GroupName: "cache", Direction: "out", Count: 1}}
+	assert.Nil(t, r.Run(context.Background(), h, event))
+	assert.Equal(t, *event, received)
+
+	assert.Equal(t, "", requestID)
+
+	assert.Nil(t, r.Run(helper.ContextWithRequestID(context.Background(), "5f1b8d3c"), h, event))
+	assert.Equal(t, "5f1b8d3c", requestID)
+
+	// Server errors are retried, whereas client errors are not.
+	requests = 0
+	event.GroupName = "fail"
+	assert.EqualError(t, r.Run(context.Background(), h, event), "scaling hook returned non-2xx status code: 500")
+	assert.Equal(t, 3, requests)
+
+	requests = 0
+	event.GroupName = "reject"
+	assert.EqualError(t, r.Run(context.Background(), h, event), "scaling hook returned non-2xx status code: 400")
+	assert.Equal(t, 1, requests)
+}
+
+func TestRunner_Run_HTTPSigned(t *testing.T) {
+	var (
+		signature string
+		timestamp string
+		body      []byte
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		signature = r.Header.Get(HeaderSignature)
+		timestamp = r.Header.Get(HeaderTimestamp)
+		body, _ = ioutil.ReadAll(r.Body)
+	}))
+	defer srv.Close()
+
+	event := &Event{Phase: PhasePostScale, Event: notify.Event{JobID: "example", GroupName: "cache"}}
+	h := &policy.ScalingHook{URL: srv.URL}
+
+	// Without a signing secret, requests are not signed.
+	assert.Nil(t, NewRunner(false, nil).Run(context.Background(), h, event))
+	assert.Equal(t, "", signature)
+	assert.Equal(t, "", timestamp)
+
+	var resolver *secret.Resolver
+	r := NewRunner(false, resolver.Value("s3cr3t"))
+	r.now = func() time.Time { return time.Unix(1580000000, 0) }
+
+	assert.Nil(t, r.Run(context.Background(), h, event))
+	assert.Equal(t, "1580000000", timestamp)
+	assert.Equal(t, Sign("s3cr3t", 1580000000, body), signature)
+	assert.True(t, strings.HasPrefix(signature, "sha256="))
+	assert.NotEqual(t, Sign("other", 1580000000, body), signature)
+	assert.NotEqual(t, Sign("s3cr3t", 1580000001, body), signature)
+}
+
+func TestRunner_Run_Command(t *testing.T) {
+	event := &Event{Phase: PhasePostScale, Event: notify.Event{JobID: "example", GroupName: "cache"}}
+	h := &policy.ScalingHook{Command: "sh", Args: []string{"-c", `grep -q '"Phase":"post-scale"'`}}
+
+	assert.Equal(t, ErrExecDisabled, NewRunner(false, nil).Run(context.Background(), h, event))
+	assert.Nil(t, NewRunner(true, nil).Run(context.Background(), h, event))
+
+	event.Phase = PhasePreScale
+	assert.NotNil(t, NewRunner(true, nil).Run(context.Background(), h, event))
+}
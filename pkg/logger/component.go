@@ -0,0 +1,156 @@
+package logger
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+// Components are the Sherpa subsystems which can have their log level changed independently of
+// the server log level.
+const (
+	ComponentAutoscale = "autoscale"
+	ComponentScale     = "scale"
+	ComponentAPI       = "api"
+	ComponentCluster   = "cluster"
+	ComponentPolicy    = "policy"
+	ComponentState     = "state"
+	ComponentWatcher   = "watcher"
+)
+
+var components = []string{
+	ComponentAutoscale, ComponentScale, ComponentAPI, ComponentCluster, ComponentPolicy, ComponentState,
+	ComponentWatcher,
+}
+
+// levels tracks the server log level and any component level overrides.
+var levels = &levelState{server: zerolog.InfoLevel, components: make(map[string]zerolog.Level)}
+
+type levelState struct {
+	sync.RWMutex
+	server     zerolog.Level
+	components map[string]zerolog.Level
+}
+
+// get returns the level of the component, or the server level if the component does not have an
+// override.
+func (ls *levelState) get(component string) zerolog.Level {
+	ls.RLock()
+	defer ls.RUnlock()
+
+	if lvl, ok := ls.components[component]; ok {
+		return lvl
+	}
+	return ls.server
+}
+
+func (ls *levelState) setServer(lvl zerolog.Level) {
+	ls.Lock()
+	defer ls.Unlock()
+
+	ls.server = lvl
+	ls.updateGlobal()
+}
+
+// updateGlobal sets the zerolog global level to the lowest configured level, so that events from
+// components with a lower override are not discarded. Each logger then filters events using its
+// levelSampler. The caller must hold the lock.
+func (ls *levelState) updateGlobal() {
+	lowest := ls.server
+	for _, lvl := range ls.components {
+		if lvl < lowest {
+			lowest = lvl
+		}
+	}
+	zerolog.SetGlobalLevel(lowest)
+}
+
+// levelSampler is a zerolog sampler which only allows events which meet the current level of the
+// component. An empty component uses the server log level.
+type levelSampler struct {
+	component string
+}
+
+// Sample satisfies the Sample function of the zerolog.Sampler interface.
+func (s levelSampler) Sample(lvl zerolog.Level) bool { return lvl >= levels.get(s.component) }
+
+// Component returns a child of the logger which is tagged with the component name, and whose level
+// can be changed using SetLevels.
+func Component(l zerolog.Logger, component string) zerolog.Logger {
+	return l.With().Str("component", component).Logger().Sample(levelSampler{component: component})
+}
+
+// Levels describes the server log level, and the level overrides of each component.
+type Levels struct {
+	Level      string
+	Components map[string]string
+}
+
+// GetLevels returns the current server log level and component overrides.
+func GetLevels() *Levels {
+	levels.RLock()
+	defer levels.RUnlock()
+
+	resp := &Levels{Level: levels.server.String(), Components: make(map[string]string)}
+	for component, lvl := range levels.components {
+		resp.Components[component] = lvl.String()
+	}
+	return resp
+}
+
+// SetLevels changes the server log level and component levels at runtime. An empty server level
+// leaves it unchanged, and an empty component level removes the override so the component uses
+// the server level. All levels are validated before any change is made.
+func SetLevels(level string, componentLevels map[string]string) error {
+	var (
+		server    zerolog.Level
+		err       error
+		overrides = make(map[string]zerolog.Level)
+	)
+
+	if level != "" {
+		if _, server, err = parseLogLevel(level); err != nil {
+			return err
+		}
+	}
+
+	for component, lvl := range componentLevels {
+		if !isComponent(component) {
+			return fmt.Errorf("unsupported log component: %q (supported components: %s)", component,
+				strings.Join(components, " "))
+		}
+		if lvl == "" {
+			continue
+		}
+		if _, overrides[component], err = parseLogLevel(lvl); err != nil {
+			return err
+		}
+	}
+
+	levels.Lock()
+	defer levels.Unlock()
+
+	if level != "" {
+		levels.server = server
+	}
+	for component, lvl := range componentLevels {
+		if lvl == "" {
+			delete(levels.components, component)
+		} else {
+			levels.components[component] = overrides[component]
+		}
+	}
+	levels.updateGlobal()
+	return nil
+}
+
+func isComponent(name string) bool {
+	for _, c := range components {
+		if c == name {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,64 @@
+package logger
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_SetLevels(t *testing.T) {
+	defer func() { _ = SetLevels("info", map[string]string{ComponentAutoscale: "", ComponentScale: ""}) }()
+
+	var buf bytes.Buffer
+	root := zerolog.New(&buf).Sample(levelSampler{})
+	autoscale := Component(root, ComponentAutoscale)
+
+	assert.Nil(t, SetLevels("info", nil))
+	autoscale.Debug().Msg("hidden")
+	assert.Equal(t, "", buf.String())
+
+	// Overriding the component level should only lower the level of the component logger.
+	assert.Nil(t, SetLevels("", map[string]string{ComponentAutoscale: "debug"}))
+	assert.Equal(t, zerolog.DebugLevel, zerolog.GlobalLevel())
+	root.Debug().Msg("hidden")
+	assert.Equal(t, "", buf.String())
+	autoscale.Debug().Msg("shown")
+	assert.Equal(t, "{\"level\":\"debug\",\"component\":\"autoscale\",\"message\":\"shown\"}\n", buf.String())
+	assert.Equal(t, &Levels{Level: "info", Components: map[string]string{ComponentAutoscale: "debug"}}, GetLevels())
+
+	// Removing the override should return the component to the server level.
+	buf.Reset()
+	assert.Nil(t, SetLevels("", map[string]string{ComponentAutoscale: ""}))
+	assert.Equal(t, zerolog.InfoLevel, zerolog.GlobalLevel())
+	autoscale.Debug().Msg("hidden")
+	assert.Equal(t, "", buf.String())
+}
+
+func TestLogger_SetLevelsInvalid(t *testing.T) {
+	testCases := []struct {
+		level         string
+		components    map[string]string
+		expectedError error
+	}{
+		{
+			level:         "nuke",
+			expectedError: fmt.Errorf("unsupported error level: %q (supported levels: %s)", "nuke", strings.Join(logLevelsStr(), " ")),
+		},
+		{
+			level:         "debug",
+			components:    map[string]string{"metrics": "debug"},
+			expectedError: fmt.Errorf("unsupported log component: %q (supported components: %s)", "metrics", strings.Join(components, " ")),
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedError, SetLevels(tc.level, tc.components))
+	}
+
+	// A failed change should not have altered the server level.
+	assert.Equal(t, "info", GetLevels().Level)
+}
@@ -0,0 +1,53 @@
+package logger
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// staticField is a key and value which is added to every log line.
+type staticField struct {
+	key   string
+	value string
+}
+
+// parseStaticFields parses the comma separated key=value pairs configured as static log fields.
+// The returned fields are sorted by key so the order they are written in is consistent.
+func parseStaticFields(s string) ([]staticField, error) {
+	var fields []staticField
+
+	if strings.TrimSpace(s) == "" {
+		return fields, nil
+	}
+
+	seen := make(map[string]bool)
+
+	for _, pair := range strings.Split(s, ",") {
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) != 2 || strings.TrimSpace(kv[0]) == "" {
+			return nil, errors.Errorf("invalid static log field %q, expected key=value", pair)
+		}
+
+		key := strings.TrimSpace(kv[0])
+		if seen[key] {
+			return nil, errors.Errorf("duplicate static log field %q", key)
+		}
+		seen[key] = true
+
+		fields = append(fields, staticField{key: key, value: strings.TrimSpace(kv[1])})
+	}
+
+	sort.Slice(fields, func(i, j int) bool { return fields[i].key < fields[j].key })
+	return fields, nil
+}
+
+// withStaticFields adds the static fields to the logger context.
+func withStaticFields(ctx zerolog.Context, fields []staticField) zerolog.Context {
+	for _, f := range fields {
+		ctx = ctx.Str(f.key, f.value)
+	}
+	return ctx
+}
@@ -0,0 +1,62 @@
+package logger
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseStaticFields(t *testing.T) {
+	testCases := []struct {
+		name           string
+		input          string
+		expectedOutput []staticField
+		expectError    bool
+	}{
+		{
+			name:           "empty",
+			input:          "",
+			expectedOutput: nil,
+		},
+		{
+			name:  "multiple fields sorted",
+			input: "region=eu-west-1, cluster=prod",
+			expectedOutput: []staticField{
+				{key: "cluster", value: "prod"},
+				{key: "region", value: "eu-west-1"},
+			},
+		},
+		{
+			name:           "value containing equals",
+			input:          "tags=a=b",
+			expectedOutput: []staticField{{key: "tags", value: "a=b"}},
+		},
+		{
+			name:        "missing value",
+			input:       "cluster",
+			expectError: true,
+		},
+		{
+			name:        "missing key",
+			input:       "=prod",
+			expectError: true,
+		},
+		{
+			name:        "duplicate key",
+			input:       "cluster=prod,cluster=dev",
+			expectError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actualOutput, err := parseStaticFields(tc.input)
+			if tc.expectError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expectedOutput, actualOutput)
+		})
+	}
+}
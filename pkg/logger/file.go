@@ -0,0 +1,134 @@
+package logger
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// rotatedFileTimeFormat is the timestamp suffix added to rotated log files. It sorts
+// lexicographically, so the oldest files can be found without parsing the names.
+const rotatedFileTimeFormat = "20060102T150405.000"
+
+// rotatingFile is an io.Writer which writes to a log file, rotating it once it reaches the maximum
+// size or age. Rotated files are renamed using a timestamp suffix, and the oldest are removed once
+// the maximum number of backups is exceeded.
+type rotatingFile struct {
+	mu sync.Mutex
+
+	path       string
+	maxSize    int64
+	maxAge     time.Duration
+	maxBackups int
+
+	file   *os.File
+	size   int64
+	opened time.Time
+
+	// now is used to get the current time, and allows tests to control rotation.
+	now func() time.Time
+}
+
+// newRotatingFile opens the log file at path. A maxSize or maxAge of zero disables that rotation
+// trigger, and a maxBackups of zero keeps all rotated files.
+func newRotatingFile(path string, maxSize int64, maxAge time.Duration, maxBackups int) (*rotatingFile, error) {
+	r := &rotatingFile{
+		path:       path,
+		maxSize:    maxSize,
+		maxAge:     maxAge,
+		maxBackups: maxBackups,
+		now:        time.Now,
+	}
+
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// Write satisfies the io.Writer interface, rotating the file before the write if required.
+func (r *rotatingFile) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.shouldRotate(int64(len(p))) {
+		if err := r.rotate(); err != nil {
+			return 0, err
+		}
+	}
+
+	n, err := r.file.Write(p)
+	r.size += int64(n)
+	return n, err
+}
+
+func (r *rotatingFile) shouldRotate(writeLen int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.maxSize > 0 && r.size+writeLen > r.maxSize {
+		return true
+	}
+	return r.maxAge > 0 && r.now().Sub(r.opened) >= r.maxAge
+}
+
+func (r *rotatingFile) open() error {
+	f, err := os.OpenFile(r.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return errors.Wrap(err, "failed to open log file")
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		_ = f.Close()
+		return errors.Wrap(err, "failed to stat log file")
+	}
+
+	r.file, r.size, r.opened = f, info.Size(), r.now()
+	return nil
+}
+
+// rotate closes and renames the current file, before opening a new file and removing any rotated
+// files which exceed the maximum number of backups.
+func (r *rotatingFile) rotate() error {
+	if err := r.file.Close(); err != nil {
+		return errors.Wrap(err, "failed to close log file")
+	}
+
+	rotated := r.path + "." + r.now().Format(rotatedFileTimeFormat)
+	if err := os.Rename(r.path, rotated); err != nil {
+		return errors.Wrap(err, "failed to rotate log file")
+	}
+
+	if err := r.open(); err != nil {
+		return err
+	}
+	return r.removeOldBackups()
+}
+
+func (r *rotatingFile) removeOldBackups() error {
+	if r.maxBackups < 1 {
+		return nil
+	}
+
+	backups, err := filepath.Glob(r.path + ".*")
+	if err != nil {
+		return err
+	}
+	if len(backups) <= r.maxBackups {
+		return nil
+	}
+
+	sort.Strings(backups)
+
+	for _, backup := range backups[:len(backups)-r.maxBackups] {
+		if err := os.Remove(backup); err != nil {
+			return errors.Wrap(err, "failed to remove rotated log file")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+package logger
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_rotatingFile_sizeRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sherpa-log")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "sherpa.log")
+	now := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+
+	r, err := newRotatingFile(path, 10, 0, 2)
+	assert.Nil(t, err)
+	r.now = func() time.Time { return now }
+
+	// Each write which would take the file over the maximum size should trigger a rotation, with
+	// only the newest two rotated files kept.
+	for i := 0; i < 4; i++ {
+		now = now.Add(time.Second)
+		_, err = r.Write([]byte("0123456789"))
+		assert.Nil(t, err)
+	}
+
+	backups, err := filepath.Glob(path + ".*")
+	assert.Nil(t, err)
+	assert.Equal(t, []string{path + ".20200126T100003.000", path + ".20200126T100004.000"}, backups)
+
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "0123456789", string(content))
+}
+
+func Test_rotatingFile_ageRotation(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sherpa-log")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "sherpa.log")
+
+	r, err := newRotatingFile(path, 0, time.Hour, 0)
+	assert.Nil(t, err)
+
+	_, err = r.Write([]byte("first\n"))
+	assert.Nil(t, err)
+	_, err = r.Write([]byte("second\n"))
+	assert.Nil(t, err)
+
+	backups, err := filepath.Glob(path + ".*")
+	assert.Nil(t, err)
+	assert.Len(t, backups, 0)
+
+	// Once the file is older than the maximum age, the next write should rotate it.
+	r.now = func() time.Time { return time.Now().Add(2 * time.Hour) }
+	_, err = r.Write([]byte("third\n"))
+	assert.Nil(t, err)
+
+	backups, err = filepath.Glob(path + ".*")
+	assert.Nil(t, err)
+	assert.Len(t, backups, 1)
+
+	content, err := ioutil.ReadFile(path)
+	assert.Nil(t, err)
+	assert.Equal(t, "third\n", string(content))
+}
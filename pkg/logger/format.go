@@ -35,7 +35,7 @@ func getLogFormat(logFormat string) (Format, error) {
 		return FormatAuto, nil
 	case "json", "zerolog":
 		return FormatZerolog, nil
-	case "human":
+	case "human", "console":
 		return FormatHuman, nil
 	default:
 		return FormatAuto, fmt.Errorf("unsupported log format: %q", logFormat)
@@ -33,6 +33,7 @@ func TestLogger_getLogFormat(t *testing.T) {
 		{format: "json", expectedResponse: FormatZerolog, expectedError: nil},
 		{format: "zerolog", expectedResponse: FormatZerolog, expectedError: nil},
 		{format: "human", expectedResponse: FormatHuman, expectedError: nil},
+		{format: "console", expectedResponse: FormatHuman, expectedError: nil},
 		{format: "robot", expectedResponse: FormatAuto, expectedError: fmt.Errorf("unsupported log format: \"robot\"")},
 	}
 
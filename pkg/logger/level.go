@@ -58,28 +58,29 @@ func logLevelsStr() []string {
 }
 
 func setLogLevel(logLevelString string) (Level, error) {
-	var logLevel Level
+	logLevel, zerologLevel, err := parseLogLevel(logLevelString)
+	if err != nil {
+		return logLevel, err
+	}
+
+	levels.setServer(zerologLevel)
+	return logLevel, nil
+}
 
+func parseLogLevel(logLevelString string) (Level, zerolog.Level, error) {
 	switch strLevel := strings.ToLower(logLevelString); strLevel {
 	case "debug":
-		zerolog.SetGlobalLevel(zerolog.DebugLevel)
-		logLevel = LevelDebug
+		return LevelDebug, zerolog.DebugLevel, nil
 	case "info":
-		zerolog.SetGlobalLevel(zerolog.InfoLevel)
-		logLevel = LevelInfo
+		return LevelInfo, zerolog.InfoLevel, nil
 	case "warn":
-		zerolog.SetGlobalLevel(zerolog.WarnLevel)
-		logLevel = LevelWarn
+		return LevelWarn, zerolog.WarnLevel, nil
 	case "error":
-		zerolog.SetGlobalLevel(zerolog.ErrorLevel)
-		logLevel = LevelError
+		return LevelError, zerolog.ErrorLevel, nil
 	case "fatal":
-		zerolog.SetGlobalLevel(zerolog.FatalLevel)
-		logLevel = LevelFatal
+		return LevelFatal, zerolog.FatalLevel, nil
 	default:
-		return LevelDebug, fmt.Errorf("unsupported error level: %q (supported levels: %s)", logLevelString,
+		return LevelDebug, zerolog.NoLevel, fmt.Errorf("unsupported error level: %q (supported levels: %s)", logLevelString,
 			strings.Join(logLevelsStr(), " "))
 	}
-
-	return logLevel, nil
 }
@@ -53,8 +53,6 @@ func Setup(config logCfg.Config) error {
 		return errors.Wrap(err, "unable to set log level")
 	}
 
-	var logWriter io.Writer = os.Stderr
-
 	logFmt, err := getLogFormat(config.LogFormat)
 	if err != nil {
 		return errors.Wrap(err, "unable to parse log format")
@@ -68,21 +66,20 @@ func Setup(config logCfg.Config) error {
 		}
 	}
 
-	var zlog zerolog.Logger
-	switch logFmt {
-	case FormatZerolog:
-		zlog = zerolog.New(logWriter).With().Timestamp().Logger()
-	case FormatHuman:
-		useColor := config.UseColor
-		w := zerolog.ConsoleWriter{
-			Out:     logWriter,
-			NoColor: !useColor,
-		}
-		zlog = zerolog.New(w).With().Timestamp().Logger()
-	default:
-		return fmt.Errorf("unsupported log format: %q", logFmt)
+	staticFields, err := parseStaticFields(config.StaticFields)
+	if err != nil {
+		return errors.Wrap(err, "unable to parse static log fields")
+	}
+
+	logWriter, err := buildLogWriter(config, logFmt)
+	if err != nil {
+		return err
 	}
 
+	// The sampler filters events using the server log level, allowing the zerolog global level to
+	// be lowered when a component has a lower level override.
+	zlog := withStaticFields(zerolog.New(logWriter).With().Timestamp(), staticFields).Logger().Sample(levelSampler{})
+
 	log.Logger = zlog
 
 	stdlog.SetFlags(0)
@@ -104,3 +101,54 @@ func Setup(config logCfg.Config) error {
 
 	return nil
 }
+
+// buildLogWriter creates the writer for the configured log sinks. Logs are always written to
+// stderr, and optionally to a rotated file and the local syslog daemon.
+func buildLogWriter(config logCfg.Config, logFmt Format) (io.Writer, error) {
+	stderr, err := formatWriter(os.Stderr, logFmt, config.UseColor)
+	if err != nil {
+		return nil, err
+	}
+	writers := []io.Writer{stderr}
+
+	if config.File != "" {
+		f, err := newRotatingFile(config.File, int64(config.FileMaxSize)*1024*1024,
+			time.Duration(config.FileMaxAge)*time.Hour, config.FileMaxBackups)
+		if err != nil {
+			return nil, err
+		}
+
+		// Colors are never written to the file, as the escape codes would make it difficult to
+		// read and parse.
+		fileWriter, err := formatWriter(f, logFmt, false)
+		if err != nil {
+			return nil, err
+		}
+		writers = append(writers, fileWriter)
+	}
+
+	if config.SyslogEnabled {
+		sw, err := newSyslogWriter(config.SyslogFacility, config.SyslogTag)
+		if err != nil {
+			return nil, errors.Wrap(err, "unable to setup syslog")
+		}
+		writers = append(writers, zerolog.SyslogLevelWriter(sw))
+	}
+
+	if len(writers) == 1 {
+		return writers[0], nil
+	}
+	return zerolog.MultiLevelWriter(writers...), nil
+}
+
+// formatWriter wraps the writer so logs are written using the log format.
+func formatWriter(w io.Writer, logFmt Format, useColor bool) (io.Writer, error) {
+	switch logFmt {
+	case FormatZerolog:
+		return w, nil
+	case FormatHuman:
+		return zerolog.ConsoleWriter{Out: w, NoColor: !useColor}, nil
+	default:
+		return nil, fmt.Errorf("unsupported log format: %q", logFmt)
+	}
+}
@@ -0,0 +1,39 @@
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+	"strings"
+)
+
+// syslogFacilities maps the supported facility names to their syslog priority.
+var syslogFacilities = map[string]syslog.Priority{
+	"KERN":   syslog.LOG_KERN,
+	"USER":   syslog.LOG_USER,
+	"DAEMON": syslog.LOG_DAEMON,
+	"SYSLOG": syslog.LOG_SYSLOG,
+	"LOCAL0": syslog.LOG_LOCAL0,
+	"LOCAL1": syslog.LOG_LOCAL1,
+	"LOCAL2": syslog.LOG_LOCAL2,
+	"LOCAL3": syslog.LOG_LOCAL3,
+	"LOCAL4": syslog.LOG_LOCAL4,
+	"LOCAL5": syslog.LOG_LOCAL5,
+	"LOCAL6": syslog.LOG_LOCAL6,
+	"LOCAL7": syslog.LOG_LOCAL7,
+}
+
+func getSyslogFacility(facility string) (syslog.Priority, error) {
+	if p, ok := syslogFacilities[strings.ToUpper(facility)]; ok {
+		return p, nil
+	}
+	return 0, fmt.Errorf("unsupported syslog facility: %q", facility)
+}
+
+// newSyslogWriter connects to the local syslog daemon using the facility and tag.
+func newSyslogWriter(facility, tag string) (*syslog.Writer, error) {
+	p, err := getSyslogFacility(facility)
+	if err != nil {
+		return nil, err
+	}
+	return syslog.New(p|syslog.LOG_INFO, tag)
+}
@@ -0,0 +1,27 @@
+package logger
+
+import (
+	"fmt"
+	"log/syslog"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLogger_getSyslogFacility(t *testing.T) {
+	testCases := []struct {
+		facility         string
+		expectedResponse syslog.Priority
+		expectedError    error
+	}{
+		{facility: "LOCAL0", expectedResponse: syslog.LOG_LOCAL0, expectedError: nil},
+		{facility: "daemon", expectedResponse: syslog.LOG_DAEMON, expectedError: nil},
+		{facility: "LOCAL9", expectedResponse: 0, expectedError: fmt.Errorf("unsupported syslog facility: \"LOCAL9\"")},
+	}
+
+	for _, tc := range testCases {
+		res, err := getSyslogFacility(tc.facility)
+		assert.Equal(t, tc.expectedError, err)
+		assert.Equal(t, tc.expectedResponse, res)
+	}
+}
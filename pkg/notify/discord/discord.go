@@ -0,0 +1,148 @@
+package discord
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/pkg/errors"
+)
+
+const (
+	// requestTimeout is the timeout applied to webhook requests so a slow Discord does not block
+	// publishing of later events.
+	requestTimeout = 10 * time.Second
+
+	// notifierName is the name of the notifier used for logging.
+	notifierName = "discord"
+
+	// username is the name the webhook messages are posted as.
+	username = "Sherpa"
+
+	// The colours of the embed, indicating the outcome of the scaling event.
+	colourScaleOut = 0x2EB886
+	colourScaleIn  = 0x1F6FEB
+	colourFailed   = 0xD93F0B
+	colourWarning  = 0xF2C744
+
+	// maxDescriptionLength is the Discord limit on the length of an embed description.
+	maxDescriptionLength = 4096
+)
+
+var _ notify.Notifier = (*Client)(nil)
+
+// Client is a notifier which posts an embed to a Discord channel webhook for each scaling event.
+type Client struct {
+	webhookURL *secret.Value
+	httpClient *http.Client
+}
+
+// message is the Discord execute webhook request body.
+type message struct {
+	Username string  `json:"username"`
+	Embeds   []embed `json:"embeds"`
+}
+
+type embed struct {
+	Title       string       `json:"title"`
+	Description string       `json:"description,omitempty"`
+	URL         string       `json:"url,omitempty"`
+	Color       int          `json:"color"`
+	Timestamp   string       `json:"timestamp"`
+	Fields      []embedField `json:"fields"`
+}
+
+type embedField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+// NewClient builds a Discord notifier. The webhook URL contains the token used to post to the
+// channel, so can be provided as a secret reference.
+func NewClient(webhookURL *secret.Value) *Client {
+	httpClient := cleanhttp.DefaultClient()
+	httpClient.Timeout = requestTimeout
+
+	return &Client{
+		webhookURL: webhookURL,
+		httpClient: httpClient,
+	}
+}
+
+// Name satisfies the Name function of the notify.Notifier interface.
+func (c *Client) Name() string { return notifierName }
+
+// Notify satisfies the Notify function of the notify.Notifier interface. Failed requests are
+// retried by the notification queue, which drops those failing with a permanent error.
+func (c *Client) Notify(event *notify.Event) error {
+	ctx := context.Background()
+
+	url, err := c.webhookURL.Get(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve Discord webhook URL")
+	}
+
+	body, err := json.Marshal(buildMessage(event))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal Discord message")
+	}
+
+	return notify.PostWebhook(c.httpClient, "Discord", url, body)
+}
+
+// buildMessage creates the webhook message for the event. The embed title links to the runbook of
+// the group if the policy has one, and the policy notes are used as the description.
+func buildMessage(event *notify.Event) *message {
+	fields := []embedField{
+		{Name: "Job", Value: event.JobID, Inline: true},
+		{Name: "Group", Value: event.GroupName, Inline: true},
+		{Name: "Count", Value: strconv.Itoa(event.Count), Inline: true},
+		{Name: "Source", Value: event.Source, Inline: true},
+		{Name: "Status", Value: event.Status, Inline: true},
+	}
+	if event.Severity != "" {
+		fields = append(fields, embedField{Name: "Severity", Value: string(event.Severity), Inline: true})
+	}
+	if event.Reason != "" {
+		fields = append(fields, embedField{Name: "Reason", Value: event.Reason, Inline: true})
+	}
+	fields = append(fields, embedField{Name: "Scaling ID", Value: event.ScalingID})
+
+	desc := event.Notes
+	if len(desc) > maxDescriptionLength {
+		desc = desc[:maxDescriptionLength]
+	}
+
+	return &message{
+		Username: username,
+		Embeds: []embed{{
+			Title:       fmt.Sprintf("Sherpa scaled %s job %s group %s by %v", event.Direction, event.JobID, event.GroupName, event.Count),
+			Description: desc,
+			URL:         event.RunbookURL,
+			Color:       embedColour(event),
+			Timestamp:   time.Unix(0, event.Time).UTC().Format(time.RFC3339),
+			Fields:      fields,
+		}},
+	}
+}
+
+func embedColour(event *notify.Event) int {
+	switch {
+	case !strings.EqualFold(event.Status, "completed"):
+		return colourFailed
+	case event.Severity == notify.SeverityWarning:
+		return colourWarning
+	case event.Direction == "in":
+		return colourScaleIn
+	default:
+		return colourScaleOut
+	}
+}
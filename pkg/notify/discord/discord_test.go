@@ -0,0 +1,74 @@
+package discord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEvent() *notify.Event {
+	return &notify.Event{
+		ScalingID: "0c8e5b8a-7a4a-4d1a-9a3b-4b1f0e2d6c11",
+		JobID:     "example",
+		GroupName: "cache",
+		Direction: "out",
+		Count:     2,
+		Source:    "InternalAutoscaler",
+		Status:    "Completed",
+		Time:      time.Unix(1580000000, 0).UnixNano(),
+	}
+}
+
+func Test_buildMessage(t *testing.T) {
+	msg := buildMessage(testEvent())
+	assert.Equal(t, "Sherpa", msg.Username)
+	assert.Len(t, msg.Embeds, 1)
+	assert.Equal(t, "Sherpa scaled out job example group cache by 2", msg.Embeds[0].Title)
+	assert.Equal(t, colourScaleOut, msg.Embeds[0].Color)
+	assert.Equal(t, "2020-01-26T00:53:20Z", msg.Embeds[0].Timestamp)
+	assert.Equal(t, "", msg.Embeds[0].URL)
+
+	event := testEvent()
+	event.Direction = "in"
+	event.RunbookURL = "https://wiki.jrasell.system/runbooks/cache"
+	event.Notes = strings.Repeat("n", maxDescriptionLength+1)
+	msg = buildMessage(event)
+	assert.Equal(t, colourScaleIn, msg.Embeds[0].Color)
+	assert.Equal(t, "https://wiki.jrasell.system/runbooks/cache", msg.Embeds[0].URL)
+	assert.Len(t, msg.Embeds[0].Description, maxDescriptionLength)
+
+	event.Severity = notify.SeverityWarning
+	assert.Equal(t, colourWarning, buildMessage(event).Embeds[0].Color)
+
+	event.Status = "Failed"
+	assert.Equal(t, colourFailed, buildMessage(event).Embeds[0].Color)
+}
+
+func TestClient_Notify(t *testing.T) {
+	var received message
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	var resolver *secret.Resolver
+
+	assert.Nil(t, NewClient(resolver.Value(srv.URL)).Notify(testEvent()))
+	assert.Equal(t, "cache", received.Embeds[0].Fields[1].Value)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer failing.Close()
+
+	assert.NotNil(t, NewClient(resolver.Value(failing.URL)).Notify(testEvent()))
+}
@@ -0,0 +1,129 @@
+package grafana
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/jrasell/sherpa/pkg/retry"
+	"github.com/pkg/errors"
+)
+
+const (
+	// annotationsPath is the Grafana HTTP API path used to create annotations.
+	annotationsPath = "/api/annotations"
+
+	// requestTimeout is the timeout applied to Grafana API requests so a slow Grafana does not
+	// block publishing of later events.
+	requestTimeout = 10 * time.Second
+
+	// notifierName is the name of the notifier used for logging.
+	notifierName = "grafana"
+)
+
+var _ notify.Notifier = (*Client)(nil)
+
+// Client is a notifier which posts a Grafana annotation for each scaling event, allowing scaling
+// actions to be overlaid on dashboards.
+type Client struct {
+	addr       string
+	token      string
+	tags       []string
+	httpClient *http.Client
+}
+
+// annotation is the Grafana create annotation request body.
+type annotation struct {
+	Time int64    `json:"time"`
+	Tags []string `json:"tags"`
+	Text string   `json:"text"`
+}
+
+// NewClient builds a Grafana annotation notifier. The token is a Grafana API key or service
+// account token, and tags are added to each annotation alongside the event tags.
+func NewClient(addr, token string, tags []string) *Client {
+	httpClient := cleanhttp.DefaultClient()
+	httpClient.Timeout = requestTimeout
+
+	return &Client{
+		addr:       strings.TrimSuffix(addr, "/"),
+		token:      token,
+		tags:       tags,
+		httpClient: httpClient,
+	}
+}
+
+// Name satisfies the Name function of the notify.Notifier interface.
+func (c *Client) Name() string { return notifierName }
+
+// Notify satisfies the Notify function of the notify.Notifier interface. Failed requests are
+// retried by the notification queue, which drops those failing with a permanent error.
+func (c *Client) Notify(event *notify.Event) error {
+	body, err := json.Marshal(c.buildAnnotation(event))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal Grafana annotation")
+	}
+
+	return c.post(body)
+}
+
+// post sends the annotation request body to Grafana. Client error responses are marked as
+// permanent, as retrying the request will not change the response.
+func (c *Client) post(body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, c.addr+annotationsPath, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return errors.Wrap(err, "failed to call Grafana annotations API")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		err := errors.Errorf("unexpected response code %v from Grafana annotations API", resp.StatusCode)
+		if resp.StatusCode < 500 {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
+
+// buildAnnotation creates the annotation for the event. Annotations are created at the
+// organisation level, so dashboards display them using an annotation query filtered by tags.
+func (c *Client) buildAnnotation(event *notify.Event) *annotation {
+	tags := []string{
+		"sherpa",
+		"job:" + event.JobID,
+		"group:" + event.GroupName,
+		"direction:" + event.Direction,
+		"status:" + strings.ToLower(event.Status),
+	}
+	if event.Severity != "" {
+		tags = append(tags, "severity:"+string(event.Severity))
+	}
+
+	text := fmt.Sprintf("Sherpa scaled %s job %s group %s by %v (source: %s, status: %s, scaling ID: %s)",
+		event.Direction, event.JobID, event.GroupName, event.Count, event.Source, event.Status, event.ScalingID)
+	if event.RunbookURL != "" {
+		text += " runbook: " + event.RunbookURL
+	}
+
+	return &annotation{
+		Time: event.Time / int64(time.Millisecond),
+		Tags: append(tags, c.tags...),
+		Text: text,
+	}
+}
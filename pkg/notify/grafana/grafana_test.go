@@ -0,0 +1,67 @@
+package grafana
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEvent() *notify.Event {
+	return &notify.Event{
+		ScalingID: "0c8e5b8a-7a4a-4d1a-9a3b-4b1f0e2d6c11",
+		JobID:     "example",
+		GroupName: "cache",
+		Direction: "out",
+		Count:     2,
+		Source:    "InternalAutoscaler",
+		Status:    "Completed",
+		Time:      time.Unix(1580000000, 0).UnixNano(),
+	}
+}
+
+func TestClient_buildAnnotation(t *testing.T) {
+	c := NewClient("http://grafana:3000/", "", []string{"cluster:prod"})
+	assert.Equal(t, "http://grafana:3000", c.addr)
+
+	a := c.buildAnnotation(testEvent())
+	assert.Equal(t, int64(1580000000000), a.Time)
+	assert.Equal(t, []string{"sherpa", "job:example", "group:cache", "direction:out", "status:completed", "cluster:prod"}, a.Tags)
+	assert.Equal(t, "Sherpa scaled out job example group cache by 2 (source: InternalAutoscaler, status: Completed, scaling ID: 0c8e5b8a-7a4a-4d1a-9a3b-4b1f0e2d6c11)", a.Text)
+
+	event := testEvent()
+	event.Severity = notify.SeverityWarning
+	assert.Contains(t, c.buildAnnotation(event).Tags, "severity:warning")
+
+	event.RunbookURL = "https://wiki.jrasell.system/runbooks/cache"
+	assert.Contains(t, c.buildAnnotation(event).Text, "runbook: https://wiki.jrasell.system/runbooks/cache")
+}
+
+func TestClient_Notify(t *testing.T) {
+	var (
+		authHeader string
+		received   annotation
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, annotationsPath, r.URL.Path)
+		authHeader = r.Header.Get("Authorization")
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer srv.Close()
+
+	assert.Nil(t, NewClient(srv.URL, "secret", nil).Notify(testEvent()))
+	assert.Equal(t, "Bearer secret", authHeader)
+	assert.Equal(t, "group:cache", received.Tags[2])
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+	}))
+	defer failing.Close()
+
+	assert.NotNil(t, NewClient(failing.URL, "", nil).Notify(testEvent()))
+}
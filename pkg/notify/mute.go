@@ -0,0 +1,164 @@
+package notify
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/pkg/errors"
+)
+
+// maxMuteTTL bounds how long notifications can be muted, so that a forgotten mute cannot silence
+// a group indefinitely.
+const maxMuteTTL = 7 * 24 * time.Hour
+
+var (
+	// ErrMuteNotFound is returned when deleting a mute which does not exist.
+	ErrMuteNotFound = errors.New("notification mute not found")
+
+	// ErrInvalidMuteTTL is returned when a mute TTL is not positive, or exceeds the maximum TTL.
+	ErrInvalidMuteTTL = errors.Errorf("notification mute TTL must be greater than 0 and no more than %s", maxMuteTTL)
+)
+
+// Mute silences the notifications of a job, or a single group of the job when Group is set, until
+// it expires. Scaling of the job is not affected.
+type Mute struct {
+	JobID   string
+	Group   string `json:",omitempty"`
+	Reason  string `json:",omitempty"`
+	Expires time.Time
+}
+
+type muteKey struct {
+	job, group string
+}
+
+// Mutes stores the active notification mutes. Mutes are held in memory by the server which set
+// them, and are not persisted to the storage backend.
+type Mutes struct {
+	lock  sync.RWMutex
+	mutes map[muteKey]*Mute
+	now   func() time.Time
+}
+
+// NewMutes returns an empty set of notification mutes.
+func NewMutes() *Mutes {
+	return &Mutes{mutes: make(map[muteKey]*Mute), now: time.Now}
+}
+
+// Set mutes the notifications of the job group for the TTL. An empty group mutes all groups of
+// the job.
+func (m *Mutes) Set(job, group, reason string, ttl time.Duration) (*Mute, error) {
+	if ttl <= 0 || ttl > maxMuteTTL {
+		return nil, ErrInvalidMuteTTL
+	}
+
+	mute := &Mute{JobID: job, Group: group, Reason: reason, Expires: m.now().UTC().Add(ttl)}
+
+	m.lock.Lock()
+	m.mutes[muteKey{job: job, group: group}] = mute
+	m.lock.Unlock()
+
+	return mute, nil
+}
+
+// Delete removes the mute of the job group. An empty group removes the mute of the job, but not
+// the mutes of its individual groups.
+func (m *Mutes) Delete(job, group string) error {
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	key := muteKey{job: job, group: group}
+	if _, ok := m.mutes[key]; !ok {
+		return ErrMuteNotFound
+	}
+	delete(m.mutes, key)
+	return nil
+}
+
+// List returns the active mutes, sorted by job and group.
+func (m *Mutes) List() []*Mute {
+	now := m.now()
+
+	m.lock.RLock()
+	defer m.lock.RUnlock()
+
+	out := []*Mute{}
+
+	for _, mute := range m.mutes {
+		if now.Before(mute.Expires) {
+			out = append(out, mute)
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].JobID != out[j].JobID {
+			return out[i].JobID < out[j].JobID
+		}
+		return out[i].Group < out[j].Group
+	})
+	return out
+}
+
+// Muted returns whether the notifications of the job group are muted, either by a mute of the
+// group or of the whole job. Expired mutes are removed.
+func (m *Mutes) Muted(job, group string) bool {
+	if m == nil {
+		return false
+	}
+
+	now := m.now()
+
+	m.lock.Lock()
+	defer m.lock.Unlock()
+
+	var muted bool
+
+	for _, key := range []muteKey{{job: job}, {job: job, group: group}} {
+		mute, ok := m.mutes[key]
+		if !ok {
+			continue
+		}
+		if !now.Before(mute.Expires) {
+			delete(m.mutes, key)
+			continue
+		}
+		muted = true
+	}
+	return muted
+}
+
+var _ Notifier = (*Filter)(nil)
+
+// Filter is a notifier which drops events below the minimum severity, and events of muted job
+// groups, before passing them to the wrapped notifier. Critical events are never muted.
+type Filter struct {
+	next        Notifier
+	mutes       *Mutes
+	minSeverity Severity
+}
+
+// NewFilter wraps the notifier so only events at or above the minimum severity, which are not
+// muted, are published.
+func NewFilter(next Notifier, mutes *Mutes, minSeverity Severity) *Filter {
+	return &Filter{next: next, mutes: mutes, minSeverity: minSeverity}
+}
+
+// Name satisfies the Name function of the Notifier interface.
+func (f *Filter) Name() string { return f.next.Name() }
+
+// Notify satisfies the Notify function of the Notifier interface.
+func (f *Filter) Notify(event *Event) error {
+	if !event.Severity.AtLeast(f.minSeverity) {
+		return nil
+	}
+
+	if event.Severity != SeverityCritical && f.mutes.Muted(event.JobID, event.GroupName) {
+		sendMetrics.IncrCounterWithLabels([]string{"notify", "muted"}, 1, []sendMetrics.Label{
+			{Name: "job", Value: event.JobID}, {Name: "group", Value: event.GroupName},
+		})
+		return nil
+	}
+	return f.next.Notify(event)
+}
@@ -0,0 +1,88 @@
+package notify
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutes(t *testing.T) {
+	now := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+
+	m := NewMutes()
+	m.now = func() time.Time { return now }
+
+	_, err := m.Set("example", "", "", 0)
+	assert.Equal(t, ErrInvalidMuteTTL, err)
+	_, err = m.Set("example", "", "", maxMuteTTL+time.Second)
+	assert.Equal(t, ErrInvalidMuteTTL, err)
+
+	mute, err := m.Set("example", "cache", "noisy during migration", time.Hour)
+	assert.Nil(t, err)
+	assert.Equal(t, &Mute{JobID: "example", Group: "cache", Reason: "noisy during migration", Expires: now.Add(time.Hour)}, mute)
+
+	_, err = m.Set("batch", "", "", 2*time.Hour)
+	assert.Nil(t, err)
+
+	assert.True(t, m.Muted("example", "cache"))
+	assert.False(t, m.Muted("example", "proxy"))
+	assert.True(t, m.Muted("batch", "worker"))
+
+	list := m.List()
+	assert.Len(t, list, 2)
+	assert.Equal(t, "batch", list[0].JobID)
+
+	// Expired mutes are no longer listed, and are removed when checked.
+	now = now.Add(90 * time.Minute)
+	assert.Len(t, m.List(), 1)
+	assert.False(t, m.Muted("example", "cache"))
+	assert.Equal(t, ErrMuteNotFound, m.Delete("example", "cache"))
+
+	assert.Nil(t, m.Delete("batch", ""))
+	assert.False(t, m.Muted("batch", "worker"))
+
+	var nilMutes *Mutes
+	assert.False(t, nilMutes.Muted("example", "cache"))
+}
+
+func TestFilter_Notify(t *testing.T) {
+	n := &fakeNotifier{name: "fake"}
+	mutes := NewMutes()
+
+	f := NewFilter(n, mutes, SeverityWarning)
+	assert.Equal(t, "fake", f.Name())
+
+	assert.Nil(t, f.Notify(&Event{JobID: "example", GroupName: "cache", Severity: SeverityInfo}))
+	assert.Len(t, n.events, 0)
+
+	assert.Nil(t, f.Notify(&Event{JobID: "example", GroupName: "cache", Severity: SeverityWarning}))
+	assert.Len(t, n.events, 1)
+
+	_, err := mutes.Set("example", "", "", time.Hour)
+	assert.Nil(t, err)
+
+	assert.Nil(t, f.Notify(&Event{JobID: "example", GroupName: "cache", Severity: SeverityWarning}))
+	assert.Len(t, n.events, 1)
+
+	// Critical events are delivered even when muted.
+	assert.Nil(t, f.Notify(&Event{JobID: "example", GroupName: "cache", Severity: SeverityCritical}))
+	assert.Len(t, n.events, 2)
+}
+
+func TestParseSeverity(t *testing.T) {
+	s, err := ParseSeverity("")
+	assert.Nil(t, err)
+	assert.Equal(t, SeverityInfo, s)
+
+	s, err = ParseSeverity("critical")
+	assert.Nil(t, err)
+	assert.Equal(t, SeverityCritical, s)
+
+	_, err = ParseSeverity("fatal")
+	assert.NotNil(t, err)
+
+	assert.True(t, SeverityCritical.AtLeast(SeverityWarning))
+	assert.False(t, SeverityInfo.AtLeast(SeverityWarning))
+	assert.True(t, Severity("").AtLeast(SeverityInfo))
+}
@@ -0,0 +1,86 @@
+package notify
+
+import "github.com/pkg/errors"
+
+// Severity classifies how important a scaling event is to operators, allowing notifications of
+// routine scaling to be filtered or muted while failures are still delivered.
+type Severity string
+
+const (
+	// SeverityInfo is used for routine scaling events.
+	SeverityInfo Severity = "info"
+
+	// SeverityWarning is used for scaling events which completed, but indicate the group is not
+	// being scaled using its configured checks, such as bounds enforcement or metrics fallback.
+	SeverityWarning Severity = "warning"
+
+	// SeverityCritical is used for scaling events which failed. Critical events are not muted.
+	SeverityCritical Severity = "critical"
+)
+
+// level returns the ordering of the severity, with unknown severities treated as info.
+func (s Severity) level() int {
+	switch s {
+	case SeverityWarning:
+		return 1
+	case SeverityCritical:
+		return 2
+	default:
+		return 0
+	}
+}
+
+// AtLeast returns whether the severity is equal to or more severe than the minimum.
+func (s Severity) AtLeast(min Severity) bool { return s.level() >= min.level() }
+
+// ParseSeverity parses the severity name. An empty name is parsed as info.
+func ParseSeverity(s string) (Severity, error) {
+	switch Severity(s) {
+	case "", SeverityInfo:
+		return SeverityInfo, nil
+	case SeverityWarning, SeverityCritical:
+		return Severity(s), nil
+	}
+	return "", errors.Errorf("unsupported severity %q, must be one of info, warning or critical", s)
+}
+
+// Event describes a scaling action of a single job group which is published to notifiers.
+type Event struct {
+	// ScalingID is the Sherpa scaling ID, and EvaluationID the ID of the Nomad evaluation created
+	// by registering the updated job.
+	ScalingID    string
+	EvaluationID string
+
+	JobID     string
+	GroupName string
+	Direction string
+	Count     int
+
+	// Source is how the scaling action was invoked, Status its end status, and Reason the code
+	// describing why it took place.
+	Source string
+	Status string
+	Reason string
+
+	// Severity classifies the importance of the event.
+	Severity Severity `json:",omitempty"`
+
+	// Time is the UnixNano time at which the scaling action was triggered.
+	Time int64
+
+	Meta map[string]string
+
+	// RunbookURL and Notes are the annotations of the group scaling policy, allowing notifications
+	// to direct responders to the relevant runbook.
+	RunbookURL string `json:",omitempty"`
+	Notes      string `json:",omitempty"`
+}
+
+// Notifier is the interface which integrations publishing scaling events must satisfy.
+type Notifier interface {
+	// Name returns the name of the notifier, used for logging.
+	Name() string
+
+	// Notify publishes the scaling event.
+	Notify(*Event) error
+}
@@ -0,0 +1,334 @@
+package notify
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/jrasell/sherpa/pkg/retry"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// queueNotifierName is the name of the queue when acting as a notifier, used for logging.
+	queueNotifierName = "queue"
+
+	// queueBaseBackoff is the delay before the first retry of a failed delivery, which doubles
+	// with each subsequent attempt up to queueMaxBackoff.
+	queueBaseBackoff = 5 * time.Second
+	queueMaxBackoff  = 5 * time.Minute
+
+	// queuePollInterval is how often the queue checks for deliveries which are due.
+	queuePollInterval = time.Second
+
+	// DefaultQueueMaxAttempts is the number of delivery attempts made before a notification is
+	// dropped, when the queue is not configured with its own limit.
+	DefaultQueueMaxAttempts = 10
+)
+
+var _ Notifier = (*Queue)(nil)
+
+// delivery is a single pending notification of an event to a named notifier.
+type delivery struct {
+	ID          string
+	Notifier    string
+	Event       *Event
+	Attempts    int
+	NextAttempt int64
+	LastError   string `json:",omitempty"`
+}
+
+// Queue delivers scaling event notifications asynchronously, retrying failed deliveries with
+// exponential backoff so that transient outages of an integration do not cause notifications to
+// be dropped. When configured with a path, pending deliveries are persisted to disk and restored
+// on start, so they also survive a Sherpa restart.
+type Queue struct {
+	notifiers   map[string]Notifier
+	path        string
+	maxAttempts int
+	logger      zerolog.Logger
+	now         func() time.Time
+
+	lock    sync.Mutex
+	pending []*delivery
+	seq     int64
+	wake    chan struct{}
+}
+
+// NewQueue creates a queue delivering to the notifiers. If path is not empty, pending deliveries
+// are persisted to the file, and any deliveries persisted by a previous run are restored.
+func NewQueue(log zerolog.Logger, path string, maxAttempts int, notifiers ...Notifier) (*Queue, error) {
+	if maxAttempts < 1 {
+		maxAttempts = DefaultQueueMaxAttempts
+	}
+
+	q := &Queue{
+		notifiers:   make(map[string]Notifier, len(notifiers)),
+		path:        path,
+		maxAttempts: maxAttempts,
+		logger:      log,
+		now:         time.Now,
+		wake:        make(chan struct{}, 1),
+	}
+
+	for _, n := range notifiers {
+		q.notifiers[n.Name()] = n
+	}
+
+	if err := q.restore(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+// Name satisfies the Name function of the Notifier interface.
+func (q *Queue) Name() string { return queueNotifierName }
+
+// Notify satisfies the Notify function of the Notifier interface. The event is queued for
+// delivery to each notifier, and an error is only returned if the queue could not be persisted.
+func (q *Queue) Notify(event *Event) error {
+	q.lock.Lock()
+
+	now := q.now().UnixNano()
+
+	for _, name := range q.Notifiers() {
+		q.seq++
+		q.pending = append(q.pending, &delivery{
+			ID:          strconv.FormatInt(now, 10) + "-" + strconv.FormatInt(q.seq, 10),
+			Notifier:    name,
+			Event:       event,
+			NextAttempt: now,
+		})
+	}
+	err := q.persist()
+	q.lock.Unlock()
+
+	select {
+	case q.wake <- struct{}{}:
+	default:
+	}
+	return err
+}
+
+// Notifiers returns the names of the notifiers the queue delivers to, sorted by name.
+func (q *Queue) Notifiers() []string {
+	names := make([]string, 0, len(q.notifiers))
+	for name := range q.notifiers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Depth returns the number of pending deliveries.
+func (q *Queue) Depth() int {
+	q.lock.Lock()
+	defer q.lock.Unlock()
+	return len(q.pending)
+}
+
+// Run delivers queued notifications until the stop channel is closed.
+func (q *Queue) Run(stopCh <-chan struct{}) {
+	t := time.NewTicker(queuePollInterval)
+	defer t.Stop()
+
+	for {
+		q.deliverDue()
+
+		select {
+		case <-t.C:
+		case <-q.wake:
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// deliverDue attempts each delivery whose next attempt time has passed. Deliveries are attempted
+// without holding the lock, so that events can be queued while a notifier is slow to respond. The
+// deliveries of each notifier are attempted concurrently with those of the other notifiers, so a
+// slow integration does not delay the notifications sent to the others.
+func (q *Queue) deliverDue() {
+	q.lock.Lock()
+	now := q.now().UnixNano()
+
+	due := make(map[string][]*delivery)
+	for _, d := range q.pending {
+		if d.NextAttempt <= now {
+			due[d.Notifier] = append(due[d.Notifier], d)
+		}
+	}
+	q.lock.Unlock()
+
+	if len(due) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	done := make(map[string]bool)
+
+	for name, deliveries := range due {
+		wg.Add(1)
+
+		go func(n Notifier, deliveries []*delivery) {
+			defer wg.Done()
+
+			for _, d := range deliveries {
+				err := n.Notify(d.Event)
+
+				q.lock.Lock()
+				if q.handleResult(d, err) {
+					done[d.ID] = true
+				}
+				q.lock.Unlock()
+			}
+		}(q.notifiers[name], deliveries)
+	}
+	wg.Wait()
+
+	q.lock.Lock()
+	defer q.lock.Unlock()
+
+	remaining := q.pending[:0]
+	for _, d := range q.pending {
+		if !done[d.ID] {
+			remaining = append(remaining, d)
+		}
+	}
+	q.pending = remaining
+
+	if err := q.persist(); err != nil {
+		q.logger.Error().Err(err).Msg("failed to persist notification queue")
+	}
+}
+
+// handleResult updates the delivery following an attempt, and returns whether the delivery is
+// complete and should be removed from the queue. Deliveries which failed with a permanent error
+// are dropped immediately, as retrying them will not produce a different result. The caller must
+// hold the lock.
+func (q *Queue) handleResult(d *delivery, err error) bool {
+	d.Attempts++
+
+	labels := []sendMetrics.Label{{Name: "notifier", Value: d.Notifier}}
+
+	switch {
+	case err == nil:
+		sendMetrics.IncrCounterWithLabels([]string{"notify", "queue", "delivered"}, 1, labels)
+		return true
+
+	case retry.IsPermanent(err) || d.Attempts >= q.maxAttempts:
+		sendMetrics.IncrCounterWithLabels([]string{"notify", "queue", "dropped"}, 1, labels)
+		q.logger.Error().
+			Str("job", d.Event.JobID).
+			Str("group", d.Event.GroupName).
+			Str("notifier", d.Notifier).
+			Int("attempts", d.Attempts).
+			Bool("permanent", retry.IsPermanent(err)).
+			Err(err).
+			Msg("failed to send scaling event notification, dropping notification")
+		return true
+
+	default:
+		d.LastError = err.Error()
+		d.NextAttempt = q.now().Add(queueBackoff(d.Attempts)).UnixNano()
+		sendMetrics.IncrCounterWithLabels([]string{"notify", "queue", "retry"}, 1, labels)
+		q.logger.Warn().
+			Str("job", d.Event.JobID).
+			Str("group", d.Event.GroupName).
+			Str("notifier", d.Notifier).
+			Int("attempts", d.Attempts).
+			Err(err).
+			Msg("failed to send scaling event notification, will retry")
+		return false
+	}
+}
+
+// queueBackoff returns the delay before the next attempt of a delivery which has failed the
+// passed number of attempts.
+func queueBackoff(attempts int) time.Duration {
+	delay := queueBaseBackoff
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= queueMaxBackoff {
+			return queueMaxBackoff
+		}
+	}
+	return delay
+}
+
+// persist writes the pending deliveries to the queue file, if configured, and updates the queue
+// depth metric. The file is replaced atomically so a crash cannot leave it partially written.
+// The caller must hold the lock.
+func (q *Queue) persist() error {
+	sendMetrics.SetGauge([]string{"notify", "queue", "depth"}, float32(len(q.pending)))
+
+	if q.path == "" {
+		return nil
+	}
+
+	b, err := json.Marshal(q.pending)
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal notification queue")
+	}
+
+	tmp, err := ioutil.TempFile(filepath.Dir(q.path), filepath.Base(q.path)+".tmp")
+	if err != nil {
+		return errors.Wrap(err, "failed to create notification queue file")
+	}
+
+	if _, err := tmp.Write(b); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "failed to write notification queue file")
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmp.Name())
+		return errors.Wrap(err, "failed to write notification queue file")
+	}
+	return errors.Wrap(os.Rename(tmp.Name(), q.path), "failed to replace notification queue file")
+}
+
+// restore loads the pending deliveries persisted by a previous run. Deliveries to notifiers which
+// are no longer configured are dropped.
+func (q *Queue) restore() error {
+	if q.path == "" {
+		return nil
+	}
+
+	b, err := ioutil.ReadFile(q.path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to read notification queue file")
+	}
+
+	var pending []*delivery
+	if err := json.Unmarshal(b, &pending); err != nil {
+		return errors.Wrap(err, "failed to unmarshal notification queue file")
+	}
+
+	for _, d := range pending {
+		if d == nil || d.Event == nil {
+			continue
+		}
+		if _, ok := q.notifiers[d.Notifier]; !ok {
+			q.logger.Warn().Str("notifier", d.Notifier).Msg("dropping queued notification for unconfigured notifier")
+			continue
+		}
+		q.pending = append(q.pending, d)
+	}
+
+	if len(q.pending) > 0 {
+		q.logger.Info().Int("pending", len(q.pending)).Msg("restored queued scaling event notifications")
+	}
+	sendMetrics.SetGauge([]string{"notify", "queue", "depth"}, float32(len(q.pending)))
+	return nil
+}
@@ -0,0 +1,191 @@
+package notify
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/retry"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNotifier struct {
+	name string
+
+	lock      sync.Mutex
+	fail      int
+	permanent bool
+	block     chan struct{}
+	events    []*Event
+}
+
+func (f *fakeNotifier) Name() string { return f.name }
+
+func (f *fakeNotifier) Notify(e *Event) error {
+	if f.block != nil {
+		<-f.block
+	}
+
+	f.lock.Lock()
+	defer f.lock.Unlock()
+
+	if f.fail > 0 {
+		f.fail--
+		if f.permanent {
+			return retry.Permanent(errors.New("bad request"))
+		}
+		return errors.New("unavailable")
+	}
+	f.events = append(f.events, e)
+	return nil
+}
+
+func TestQueue_deliverDue(t *testing.T) {
+	n := &fakeNotifier{name: "fake", fail: 1}
+
+	q, err := NewQueue(zerolog.Nop(), "", 3, n)
+	assert.Nil(t, err)
+
+	now := time.Unix(1580000000, 0)
+	q.now = func() time.Time { return now }
+
+	assert.Nil(t, q.Notify(&Event{JobID: "example", GroupName: "cache"}))
+	assert.Equal(t, 1, q.Depth())
+
+	// The first attempt fails, and the delivery is retried after the backoff.
+	q.deliverDue()
+	assert.Equal(t, 1, q.Depth())
+	assert.Equal(t, 1, q.pending[0].Attempts)
+	assert.Equal(t, "unavailable", q.pending[0].LastError)
+
+	q.deliverDue()
+	assert.Len(t, n.events, 0)
+
+	now = now.Add(queueBaseBackoff)
+	q.deliverDue()
+	assert.Equal(t, 0, q.Depth())
+	assert.Len(t, n.events, 1)
+
+	// Deliveries are dropped once the max attempts are reached.
+	n.fail = 3
+	assert.Nil(t, q.Notify(&Event{JobID: "example", GroupName: "cache"}))
+
+	for i := 0; i < 3; i++ {
+		now = now.Add(queueMaxBackoff)
+		q.deliverDue()
+	}
+	assert.Equal(t, 0, q.Depth())
+	assert.Len(t, n.events, 1)
+}
+
+func TestQueue_deliverDuePermanent(t *testing.T) {
+	n := &fakeNotifier{name: "fake", fail: 1, permanent: true}
+
+	q, err := NewQueue(zerolog.Nop(), "", 3, n)
+	assert.Nil(t, err)
+
+	// Deliveries failing with a permanent error are dropped without being retried.
+	assert.Nil(t, q.Notify(&Event{JobID: "example", GroupName: "cache"}))
+	q.deliverDue()
+	assert.Equal(t, 0, q.Depth())
+	assert.Len(t, n.events, 0)
+}
+
+func TestQueue_deliverDueConcurrent(t *testing.T) {
+	slow := &fakeNotifier{name: "slow", block: make(chan struct{})}
+	fast := &fakeNotifier{name: "fast"}
+
+	q, err := NewQueue(zerolog.Nop(), "", 3, slow, fast)
+	assert.Nil(t, err)
+	assert.Nil(t, q.Notify(&Event{JobID: "example", GroupName: "cache"}))
+
+	doneCh := make(chan struct{})
+	go func() {
+		q.deliverDue()
+		close(doneCh)
+	}()
+
+	// The fast notifier receives the event while the slow notifier is still blocked.
+	deadline := time.Now().Add(time.Second)
+	for time.Now().Before(deadline) {
+		fast.lock.Lock()
+		delivered := len(fast.events)
+		fast.lock.Unlock()
+		if delivered > 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Len(t, fast.events, 1)
+
+	close(slow.block)
+	<-doneCh
+	assert.Equal(t, 0, q.Depth())
+	assert.Len(t, slow.events, 1)
+}
+
+func TestQueue_persistence(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sherpa-notify")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "queue.json")
+
+	q, err := NewQueue(zerolog.Nop(), path, 0, &fakeNotifier{name: "fake", fail: 1}, &fakeNotifier{name: "removed", fail: 1})
+	assert.Nil(t, err)
+	assert.Equal(t, DefaultQueueMaxAttempts, q.maxAttempts)
+
+	assert.Nil(t, q.Notify(&Event{JobID: "example", GroupName: "cache"}))
+	q.deliverDue()
+
+	// Restoring the queue keeps the pending deliveries of configured notifiers only.
+	n := &fakeNotifier{name: "fake"}
+
+	restored, err := NewQueue(zerolog.Nop(), path, 3, n)
+	assert.Nil(t, err)
+	assert.Equal(t, 1, restored.Depth())
+	assert.Equal(t, 1, restored.pending[0].Attempts)
+	assert.Equal(t, "example", restored.pending[0].Event.JobID)
+
+	restored.now = func() time.Time { return time.Now().Add(queueMaxBackoff) }
+	restored.deliverDue()
+	assert.Len(t, n.events, 1)
+
+	_, err = NewQueue(zerolog.Nop(), path, 3, n)
+	assert.Nil(t, err)
+
+	assert.Nil(t, ioutil.WriteFile(path, []byte("not json"), 0600))
+	_, err = NewQueue(zerolog.Nop(), path, 3, n)
+	assert.NotNil(t, err)
+}
+
+func TestQueue_Run(t *testing.T) {
+	n := &fakeNotifier{name: "fake"}
+
+	q, err := NewQueue(zerolog.Nop(), "", 3, n)
+	assert.Nil(t, err)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go q.Run(stopCh)
+
+	assert.Nil(t, q.Notify(&Event{JobID: "example", GroupName: "cache"}))
+
+	deadline := time.Now().Add(time.Second)
+	for q.Depth() > 0 && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.Equal(t, 0, q.Depth())
+}
+
+func Test_queueBackoff(t *testing.T) {
+	assert.Equal(t, 5*time.Second, queueBackoff(1))
+	assert.Equal(t, 10*time.Second, queueBackoff(2))
+	assert.Equal(t, 40*time.Second, queueBackoff(4))
+	assert.Equal(t, queueMaxBackoff, queueBackoff(20))
+}
@@ -0,0 +1,161 @@
+package teams
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/pkg/errors"
+)
+
+const (
+	// requestTimeout is the timeout applied to webhook requests so a slow Teams does not block
+	// publishing of later events.
+	requestTimeout = 10 * time.Second
+
+	// notifierName is the name of the notifier used for logging.
+	notifierName = "teams"
+
+	// The theme colours of the card, indicating the outcome of the scaling event.
+	colourScaleOut = "2EB886"
+	colourScaleIn  = "1F6FEB"
+	colourFailed   = "D93F0B"
+	colourWarning  = "F2C744"
+)
+
+var _ notify.Notifier = (*Client)(nil)
+
+// Client is a notifier which posts a message card to a Microsoft Teams incoming webhook for each
+// scaling event.
+type Client struct {
+	webhookURL *secret.Value
+	httpClient *http.Client
+}
+
+// messageCard is the Microsoft Teams connector message card.
+type messageCard struct {
+	Type            string          `json:"@type"`
+	Context         string          `json:"@context"`
+	ThemeColor      string          `json:"themeColor"`
+	Summary         string          `json:"summary"`
+	Title           string          `json:"title"`
+	Sections        []cardSection   `json:"sections"`
+	PotentialAction []openURIAction `json:"potentialAction,omitempty"`
+}
+
+type cardSection struct {
+	Text  string     `json:"text,omitempty"`
+	Facts []cardFact `json:"facts"`
+}
+
+type cardFact struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type openURIAction struct {
+	Type    string          `json:"@type"`
+	Name    string          `json:"name"`
+	Targets []openURITarget `json:"targets"`
+}
+
+type openURITarget struct {
+	OS  string `json:"os"`
+	URI string `json:"uri"`
+}
+
+// NewClient builds a Microsoft Teams notifier. The webhook URL contains the credentials used to
+// post to the channel, so can be provided as a secret reference.
+func NewClient(webhookURL *secret.Value) *Client {
+	httpClient := cleanhttp.DefaultClient()
+	httpClient.Timeout = requestTimeout
+
+	return &Client{
+		webhookURL: webhookURL,
+		httpClient: httpClient,
+	}
+}
+
+// Name satisfies the Name function of the notify.Notifier interface.
+func (c *Client) Name() string { return notifierName }
+
+// Notify satisfies the Notify function of the notify.Notifier interface. Failed requests are
+// retried by the notification queue, which drops those failing with a permanent error.
+func (c *Client) Notify(event *notify.Event) error {
+	ctx := context.Background()
+
+	url, err := c.webhookURL.Get(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve Teams webhook URL")
+	}
+
+	body, err := json.Marshal(buildCard(event))
+	if err != nil {
+		return errors.Wrap(err, "failed to marshal Teams message card")
+	}
+
+	return notify.PostWebhook(c.httpClient, "Teams", url, body)
+}
+
+// buildCard creates the message card for the event, including a button linking to the runbook of
+// the group if the policy has one.
+func buildCard(event *notify.Event) *messageCard {
+	title := fmt.Sprintf("Sherpa scaled %s job %s group %s by %v", event.Direction, event.JobID, event.GroupName, event.Count)
+
+	facts := []cardFact{
+		{Name: "Job", Value: event.JobID},
+		{Name: "Group", Value: event.GroupName},
+		{Name: "Direction", Value: event.Direction},
+		{Name: "Count", Value: strconv.Itoa(event.Count)},
+		{Name: "Source", Value: event.Source},
+		{Name: "Status", Value: event.Status},
+	}
+	if event.Severity != "" {
+		facts = append(facts, cardFact{Name: "Severity", Value: string(event.Severity)})
+	}
+	if event.Reason != "" {
+		facts = append(facts, cardFact{Name: "Reason", Value: event.Reason})
+	}
+	facts = append(facts,
+		cardFact{Name: "Scaling ID", Value: event.ScalingID},
+		cardFact{Name: "Time", Value: time.Unix(0, event.Time).UTC().Format(time.RFC3339)},
+	)
+
+	card := &messageCard{
+		Type:       "MessageCard",
+		Context:    "http://schema.org/extensions",
+		ThemeColor: themeColour(event),
+		Summary:    title,
+		Title:      title,
+		Sections:   []cardSection{{Text: event.Notes, Facts: facts}},
+	}
+
+	if event.RunbookURL != "" {
+		card.PotentialAction = []openURIAction{{
+			Type:    "OpenUri",
+			Name:    "View runbook",
+			Targets: []openURITarget{{OS: "default", URI: event.RunbookURL}},
+		}}
+	}
+	return card
+}
+
+func themeColour(event *notify.Event) string {
+	switch {
+	case !strings.EqualFold(event.Status, "completed"):
+		return colourFailed
+	case event.Severity == notify.SeverityWarning:
+		return colourWarning
+	case event.Direction == "in":
+		return colourScaleIn
+	default:
+		return colourScaleOut
+	}
+}
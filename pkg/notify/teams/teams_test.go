@@ -0,0 +1,72 @@
+package teams
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/stretchr/testify/assert"
+)
+
+func testEvent() *notify.Event {
+	return &notify.Event{
+		ScalingID: "0c8e5b8a-7a4a-4d1a-9a3b-4b1f0e2d6c11",
+		JobID:     "example",
+		GroupName: "cache",
+		Direction: "out",
+		Count:     2,
+		Source:    "InternalAutoscaler",
+		Status:    "Completed",
+		Time:      time.Unix(1580000000, 0).UnixNano(),
+	}
+}
+
+func Test_buildCard(t *testing.T) {
+	card := buildCard(testEvent())
+	assert.Equal(t, "MessageCard", card.Type)
+	assert.Equal(t, colourScaleOut, card.ThemeColor)
+	assert.Equal(t, "Sherpa scaled out job example group cache by 2", card.Title)
+	assert.Equal(t, cardFact{Name: "Time", Value: "2020-01-26T00:53:20Z"}, card.Sections[0].Facts[len(card.Sections[0].Facts)-1])
+	assert.Nil(t, card.PotentialAction)
+
+	event := testEvent()
+	event.Direction = "in"
+	event.RunbookURL = "https://wiki.jrasell.system/runbooks/cache"
+	event.Notes = "Cache nodes are slow to warm."
+	card = buildCard(event)
+	assert.Equal(t, colourScaleIn, card.ThemeColor)
+	assert.Equal(t, "Cache nodes are slow to warm.", card.Sections[0].Text)
+	assert.Equal(t, "https://wiki.jrasell.system/runbooks/cache", card.PotentialAction[0].Targets[0].URI)
+
+	event.Severity = notify.SeverityWarning
+	assert.Equal(t, colourWarning, buildCard(event).ThemeColor)
+
+	event.Status = "Failed"
+	assert.Equal(t, colourFailed, buildCard(event).ThemeColor)
+}
+
+func TestClient_Notify(t *testing.T) {
+	var received messageCard
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&received))
+	}))
+	defer srv.Close()
+
+	var resolver *secret.Resolver
+
+	assert.Nil(t, NewClient(resolver.Value(srv.URL)).Notify(testEvent()))
+	assert.Equal(t, "Sherpa scaled out job example group cache by 2", received.Summary)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer failing.Close()
+
+	assert.NotNil(t, NewClient(resolver.Value(failing.URL)).Notify(testEvent()))
+}
@@ -0,0 +1,114 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/rs/zerolog"
+	"github.com/rs/zerolog/log"
+)
+
+// Muter is the interface used to manage the notification mutes.
+type Muter interface {
+	Set(job, group, reason string, ttl time.Duration) (*notify.Mute, error)
+	Delete(job, group string) error
+	List() []*notify.Mute
+}
+
+// MuteReq is the request body used to mute notifications. The TTL is a duration string, such as
+// "2h".
+type MuteReq struct {
+	TTL    string
+	Reason string
+}
+
+type Mutes struct {
+	logger zerolog.Logger
+	mutes  Muter
+}
+
+func NewMutesServer(l zerolog.Logger, m Muter) *Mutes {
+	return &Mutes{logger: l, mutes: m}
+}
+
+// GetMutes returns the currently active notification mutes.
+func (m *Mutes) GetMutes(w http.ResponseWriter, r *http.Request) {
+	bytes, err := json.Marshal(m.mutes.List())
+	if err != nil {
+		m.logger.Error().Err(err).Msg("failed to marshal notification mutes response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// PutMute mutes the notifications of the job, or job group if the group is part of the route.
+func (m *Mutes) PutMute(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	var req MuteReq
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "failed to decode request body", http.StatusUnprocessableEntity)
+		return
+	}
+
+	ttl, err := time.ParseDuration(req.TTL)
+	if err != nil {
+		http.Error(w, "failed to parse notification mute TTL as duration", http.StatusUnprocessableEntity)
+		return
+	}
+
+	mute, err := m.mutes.Set(vars["job_id"], vars["group"], req.Reason, ttl)
+	if err != nil {
+		http.Error(w, err.Error(), muteErrorStatusCode(err))
+		return
+	}
+
+	m.logger.Info().
+		Str("job", mute.JobID).
+		Str("group", mute.Group).
+		Time("expires", mute.Expires).
+		Msg("scaling event notifications muted")
+
+	bytes, err := json.Marshal(mute)
+	if err != nil {
+		m.logger.Error().Err(err).Msg("failed to marshal notification mute response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// DeleteMute removes the notification mute of the job, or job group if the group is part of the
+// route.
+func (m *Mutes) DeleteMute(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+
+	if err := m.mutes.Delete(vars["job_id"], vars["group"]); err != nil {
+		http.Error(w, err.Error(), muteErrorStatusCode(err))
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func muteErrorStatusCode(err error) int {
+	switch err {
+	case notify.ErrMuteNotFound:
+		return http.StatusNotFound
+	case notify.ErrInvalidMuteTTL:
+		return http.StatusUnprocessableEntity
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func writeJSONResponse(w http.ResponseWriter, bytes []byte, statusCode int) {
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(statusCode)
+	if _, err := w.Write(bytes); err != nil {
+		log.Error().Err(err).Msg("failed to write JSON response")
+	}
+}
@@ -0,0 +1,62 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMutes(t *testing.T) {
+	srv := NewMutesServer(zerolog.Nop(), notify.NewMutes())
+
+	r := mux.NewRouter()
+	r.HandleFunc("/v1/notify/mutes", srv.GetMutes).Methods(http.MethodGet)
+	r.HandleFunc("/v1/notify/mute/{job_id}", srv.PutMute).Methods(http.MethodPut)
+	r.HandleFunc("/v1/notify/mute/{job_id}/{group}", srv.PutMute).Methods(http.MethodPut)
+	r.HandleFunc("/v1/notify/mute/{job_id}/{group}", srv.DeleteMute).Methods(http.MethodDelete)
+
+	testCases := []struct {
+		path               string
+		body               string
+		expectedStatusCode int
+		name               string
+	}{
+		{path: "/v1/notify/mute/example/cache", body: `{"TTL": "2h", "Reason": "noisy"}`, expectedStatusCode: http.StatusOK, name: "group mute"},
+		{path: "/v1/notify/mute/batch", body: `{"TTL": "30m"}`, expectedStatusCode: http.StatusOK, name: "job mute"},
+		{path: "/v1/notify/mute/batch", body: `{"TTL": "thirty"}`, expectedStatusCode: http.StatusUnprocessableEntity, name: "invalid TTL"},
+		{path: "/v1/notify/mute/batch", body: `{"TTL": "720h"}`, expectedStatusCode: http.StatusUnprocessableEntity, name: "TTL too long"},
+		{path: "/v1/notify/mute/batch", body: `{`, expectedStatusCode: http.StatusUnprocessableEntity, name: "invalid body"},
+	}
+
+	for _, tc := range testCases {
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, tc.path, strings.NewReader(tc.body)))
+		assert.Equal(t, tc.expectedStatusCode, w.Code, tc.name)
+	}
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/notify/mutes", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var mutes []*notify.Mute
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &mutes))
+	assert.Len(t, mutes, 2)
+	assert.Equal(t, "batch", mutes[0].JobID)
+	assert.Equal(t, "cache", mutes[1].Group)
+	assert.Equal(t, "noisy", mutes[1].Reason)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/v1/notify/mute/example/cache", nil))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/v1/notify/mute/example/cache", nil))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
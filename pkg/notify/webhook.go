@@ -0,0 +1,35 @@
+package notify
+
+import (
+	"bytes"
+	"net/http"
+
+	"github.com/jrasell/sherpa/pkg/retry"
+	"github.com/pkg/errors"
+)
+
+// PostWebhook sends the JSON body to a chat service incoming webhook, such as those provided by
+// Microsoft Teams and Discord. Client error responses, other than rate limiting, are marked as
+// permanent as retrying the request will not change the response.
+func PostWebhook(client *http.Client, service, url string, body []byte) error {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return retry.Permanent(errors.Wrapf(err, "failed to build %s webhook request", service))
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return errors.Wrapf(err, "failed to call %s webhook", service)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		err := errors.Errorf("unexpected response code %v from %s webhook", resp.StatusCode, service)
+		if resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests {
+			return retry.Permanent(err)
+		}
+		return err
+	}
+	return nil
+}
@@ -1,30 +1,35 @@
 package backend
 
-import "github.com/jrasell/sherpa/pkg/policy"
+import (
+	"context"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+)
 
 // PolicyBackend is the interface required for a policy storage backend. A policy storage backend
-// is used to durably store job scaling policies outside of Sherpa.
+// is used to durably store job scaling policies outside of Sherpa. The context passed to each
+// function is used to cancel remote storage requests.
 type PolicyBackend interface {
 	// PutJobPolicy is used to insert or update the scaling policy for a job and all its associated
 	// task groups.
-	PutJobPolicy(string, map[string]*policy.GroupScalingPolicy) error
+	PutJobPolicy(context.Context, string, map[string]*policy.GroupScalingPolicy) error
 
 	// PutJobGroupPolicy is used to insert or update the scaling policy of a task group.
-	PutJobGroupPolicy(string, string, *policy.GroupScalingPolicy) error
+	PutJobGroupPolicy(context.Context, string, string, *policy.GroupScalingPolicy) error
 
 	// GetPolicies is used to retrieve all currently configured job scaling policies.
-	GetPolicies() (map[string]map[string]*policy.GroupScalingPolicy, error)
+	GetPolicies(context.Context) (map[string]map[string]*policy.GroupScalingPolicy, error)
 
 	// GetJobPolicy retrieves the scaling policy for a job.
-	GetJobPolicy(string) (map[string]*policy.GroupScalingPolicy, error)
+	GetJobPolicy(context.Context, string) (map[string]*policy.GroupScalingPolicy, error)
 
 	// GetJobGroupPolicy retrieves the scaling policy for a job task group.
-	GetJobGroupPolicy(string, string) (*policy.GroupScalingPolicy, error)
+	GetJobGroupPolicy(context.Context, string, string) (*policy.GroupScalingPolicy, error)
 
 	// DeleteJobPolicy is used to delete all task group scaling policies associated to the named
 	// job.
-	DeleteJobPolicy(string) error
+	DeleteJobPolicy(context.Context, string) error
 
 	// DeleteJobGroupPolicy deletes the stored policy for a particular job group.
-	DeleteJobGroupPolicy(string, string) error
+	DeleteJobGroupPolicy(context.Context, string, string) error
 }
@@ -1,14 +1,18 @@
 package consul
 
 import (
+	"context"
 	"encoding/json"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/armon/go-metrics"
 	"github.com/hashicorp/consul/api"
+	"github.com/jrasell/sherpa/pkg/encryption"
 	"github.com/jrasell/sherpa/pkg/policy"
 	"github.com/jrasell/sherpa/pkg/policy/backend"
+	"github.com/jrasell/sherpa/pkg/secret"
 	"github.com/pkg/errors"
 	"github.com/rs/zerolog"
 )
@@ -17,6 +21,11 @@ var _ backend.PolicyBackend = (*PolicyBackend)(nil)
 
 const (
 	baseKVPath = "policies/"
+
+	// watchWaitTime is the maximum time a blocking query used to refresh the policy cache waits
+	// for a change, and watchRetryInterval is the time waited before retrying a failed query.
+	watchWaitTime      = 5 * time.Minute
+	watchRetryInterval = 5 * time.Second
 )
 
 // Define our metric keys.
@@ -28,42 +37,230 @@ var (
 	metricKeyPutJobGroupPolicy    = []string{"policy", "consul", "put_job_group_policy"}
 	metricKeyDeleteJobPolicy      = []string{"policy", "consul", "delete_job_policy"}
 	metricKeyDeleteJobGroupPolicy = []string{"policy", "consul", "delete_job_group_policy"}
+	metricKeyWatchRefresh         = []string{"policy", "consul", "watch_refresh"}
 )
 
 type PolicyBackend struct {
 	path   string
 	logger zerolog.Logger
 
+	// keyring encrypts the sensitive fields of policies before they are written to Consul, and is
+	// nil if encryption at rest is disabled.
+	keyring *encryption.Keyring
+
+	// token is the ACL token used for policy requests. If nil or empty, the token of the Consul
+	// client is used.
+	token *secret.Value
+
 	kv *api.KV
+
+	// cache holds the policies read by the blocking query of WatchPolicies, and is used to serve
+	// reads while cacheReady is true. Writes increment the cache generation and mark it as not
+	// ready, so reads go to Consul until the watch has observed the write.
+	cacheLock  sync.RWMutex
+	cache      map[string]map[string]*policy.GroupScalingPolicy
+	cacheReady bool
+	cacheGen   uint64
 }
 
-func NewConsulPolicyBackend(log zerolog.Logger, path string, client *api.Client) backend.PolicyBackend {
+// NewConsulPolicyBackend creates a policy backend which stores policies within Consul KV under
+// the passed path prefix. If the token resolves to a non-empty value, it is used as the ACL token
+// for all policy requests instead of the token of the Consul client. If the keyring is not nil,
+// the sensitive fields of policies are encrypted before being written.
+func NewConsulPolicyBackend(log zerolog.Logger, path string, token *secret.Value, client *api.Client, keyring *encryption.Keyring) *PolicyBackend {
 	return &PolicyBackend{
-		path:   path + baseKVPath,
-		logger: log,
-		kv:     client.KV(),
+		path:    path + baseKVPath,
+		logger:  log,
+		keyring: keyring,
+		token:   token,
+		kv:      client.KV(),
+	}
+}
+
+func (p *PolicyBackend) queryOptions(ctx context.Context) (*api.QueryOptions, error) {
+	token, err := p.token.Get(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve Consul policy token")
+	}
+	return (&api.QueryOptions{Token: token}).WithContext(ctx), nil
+}
+
+func (p *PolicyBackend) writeOptions(ctx context.Context) (*api.WriteOptions, error) {
+	token, err := p.token.Get(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to resolve Consul policy token")
+	}
+	return (&api.WriteOptions{Token: token}).WithContext(ctx), nil
+}
+
+// WatchPolicies keeps a cache of all policies up to date using Consul blocking queries until the
+// stop channel is closed. While the cache is populated, policy reads are served from it rather
+// than Consul, which reduces the load placed on Consul by the autoscaler and allows changes made
+// by other Sherpa servers sharing the path to be picked up as they happen.
+func (p *PolicyBackend) WatchPolicies(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	p.logger.Info().Str("path", p.path).Msg("starting Consul policy cache watcher")
+
+	var index uint64
+
+	for {
+		p.cacheLock.RLock()
+		gen := p.cacheGen
+		p.cacheLock.RUnlock()
+
+		policies, meta, err := p.watchPolicies(ctx, index)
+		if err != nil {
+			if ctx.Err() != nil {
+				p.logger.Info().Msg("stopping Consul policy cache watcher")
+				return
+			}
+			p.logger.Error().Err(err).Msg("failed to refresh Consul policy cache")
+
+			select {
+			case <-time.After(watchRetryInterval):
+				continue
+			case <-stopCh:
+				p.logger.Info().Msg("stopping Consul policy cache watcher")
+				return
+			}
+		}
+
+		// The index is reset if it goes backwards, such as after a Consul snapshot restore, as
+		// recommended by the Consul blocking query documentation.
+		if meta.LastIndex < index {
+			index = 0
+		} else {
+			index = meta.LastIndex
+		}
+
+		// A policy written during the query invalidates the result, so the policies are read again
+		// immediately rather than waiting for a further change.
+		if !p.setCache(policies, gen) {
+			index = 0
+			continue
+		}
+		metrics.IncrCounter(metricKeyWatchRefresh, 1)
 	}
 }
 
-func (p *PolicyBackend) GetPolicies() (map[string]map[string]*policy.GroupScalingPolicy, error) {
+// watchPolicies performs a blocking query for all policies, returning once the Consul index is
+// beyond the passed index or the wait time is reached.
+func (p *PolicyBackend) watchPolicies(ctx context.Context, index uint64) (map[string]map[string]*policy.GroupScalingPolicy, *api.QueryMeta, error) {
+	opts, err := p.queryOptions(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	opts.WaitIndex = index
+	opts.WaitTime = watchWaitTime
+
+	return p.listPolicies(opts)
+}
+
+// setCache stores the policies read by the watcher, unless the cache generation has changed
+// since the read was started as a write may have been missed.
+func (p *PolicyBackend) setCache(policies map[string]map[string]*policy.GroupScalingPolicy, gen uint64) bool {
+	p.cacheLock.Lock()
+	defer p.cacheLock.Unlock()
+
+	if gen != p.cacheGen {
+		return false
+	}
+	p.cache = policies
+	p.cacheReady = true
+	return true
+}
+
+// invalidateCache marks the cache as not ready following a write, so that reads are performed
+// against Consul until the watcher has refreshed the cache.
+func (p *PolicyBackend) invalidateCache() {
+	p.cacheLock.Lock()
+	p.cacheGen++
+	p.cacheReady = false
+	p.cacheLock.Unlock()
+}
+
+// cachedPolicies returns the cached policies, and whether the cache is ready for use.
+func (p *PolicyBackend) cachedPolicies() (map[string]map[string]*policy.GroupScalingPolicy, bool) {
+	p.cacheLock.RLock()
+	defer p.cacheLock.RUnlock()
+	return p.cache, p.cacheReady
+}
+
+// decodePolicy unmarshals the stored policy, decrypting any encrypted sensitive fields.
+func (p *PolicyBackend) decodePolicy(value []byte, out *policy.GroupScalingPolicy) (*policy.GroupScalingPolicy, error) {
+	if err := json.Unmarshal(value, out); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal Consul KV value")
+	}
+
+	dec, err := out.TransformSensitive(p.keyring.Decrypt)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt Consul KV value")
+	}
+	return dec, nil
+}
+
+// encodePolicy marshals the policy for storage, encrypting its sensitive fields if encryption at
+// rest is enabled.
+func (p *PolicyBackend) encodePolicy(pol *policy.GroupScalingPolicy) ([]byte, error) {
+	if p.keyring != nil && pol != nil {
+		enc, err := pol.TransformSensitive(p.keyring.Encrypt)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encrypt policy")
+		}
+		pol = enc
+	}
+	return json.Marshal(pol)
+}
+
+func (p *PolicyBackend) GetPolicies(ctx context.Context) (map[string]map[string]*policy.GroupScalingPolicy, error) {
 	defer metrics.MeasureSince(metricKeyGetPolicies, time.Now())
 
-	kv, _, err := p.kv.List(p.path, nil)
+	if cached, ok := p.cachedPolicies(); ok {
+		if len(cached) == 0 {
+			return nil, nil
+		}
+		out := make(map[string]map[string]*policy.GroupScalingPolicy, len(cached))
+		for job, groups := range cached {
+			out[job] = copyJobPolicy(groups)
+		}
+		return out, nil
+	}
+
+	opts, err := p.queryOptions(ctx)
 	if err != nil {
 		return nil, err
 	}
 
+	out, _, err := p.listPolicies(opts)
+	return out, err
+}
+
+// listPolicies reads all policies from Consul using the passed query options, returning nil if
+// no policies are stored.
+func (p *PolicyBackend) listPolicies(opts *api.QueryOptions) (map[string]map[string]*policy.GroupScalingPolicy, *api.QueryMeta, error) {
+	kv, meta, err := p.kv.List(p.path, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	if kv == nil {
-		return nil, nil
+		return nil, meta, nil
 	}
 
 	out := make(map[string]map[string]*policy.GroupScalingPolicy)
 
 	for i := range kv {
-		keyPolicy := &policy.GroupScalingPolicy{ExternalChecks: make(map[string]*policy.ExternalCheck)}
-
-		if err := json.Unmarshal(kv[i].Value, keyPolicy); err != nil {
-			return nil, errors.Wrap(err, "failed to unmarshal Consul KV value")
+		keyPolicy, err := p.decodePolicy(kv[i].Value,
+			&policy.GroupScalingPolicy{ExternalChecks: make(map[string]*policy.ExternalCheck)})
+		if err != nil {
+			return nil, nil, err
 		}
 
 		keySplit := strings.Split(kv[i].Key, "/")
@@ -77,13 +274,34 @@ func (p *PolicyBackend) GetPolicies() (map[string]map[string]*policy.GroupScalin
 		out[jobName][groupName] = keyPolicy
 	}
 
-	return out, nil
+	return out, meta, nil
+}
+
+// copyJobPolicy returns a copy of the job policy map, so that callers cannot modify the cache.
+func copyJobPolicy(groups map[string]*policy.GroupScalingPolicy) map[string]*policy.GroupScalingPolicy {
+	out := make(map[string]*policy.GroupScalingPolicy, len(groups))
+	for group, pol := range groups {
+		out[group] = pol
+	}
+	return out
 }
 
-func (p *PolicyBackend) GetJobPolicy(job string) (map[string]*policy.GroupScalingPolicy, error) {
+func (p *PolicyBackend) GetJobPolicy(ctx context.Context, job string) (map[string]*policy.GroupScalingPolicy, error) {
 	defer metrics.MeasureSince(metricKeyGetJobPolicy, time.Now())
 
-	kv, _, err := p.kv.List(p.path+job, nil)
+	if cached, ok := p.cachedPolicies(); ok {
+		if groups, ok := cached[job]; ok {
+			return copyJobPolicy(groups), nil
+		}
+		return nil, nil
+	}
+
+	opts, err := p.queryOptions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	kv, _, err := p.kv.List(p.path+job+"/", opts)
 	if err != nil {
 		return nil, err
 	}
@@ -96,10 +314,10 @@ func (p *PolicyBackend) GetJobPolicy(job string) (map[string]*policy.GroupScalin
 
 	for i := range kv {
 
-		keyPolicy := &policy.GroupScalingPolicy{ExternalChecks: make(map[string]*policy.ExternalCheck)}
-
-		if err := json.Unmarshal(kv[i].Value, keyPolicy); err != nil {
-			return nil, errors.Wrap(err, "failed to unmarshal Consul KV value")
+		keyPolicy, err := p.decodePolicy(kv[i].Value,
+			&policy.GroupScalingPolicy{ExternalChecks: make(map[string]*policy.ExternalCheck)})
+		if err != nil {
+			return nil, err
 		}
 
 		keySplit := strings.Split(kv[i].Key, "/")
@@ -109,35 +327,38 @@ func (p *PolicyBackend) GetJobPolicy(job string) (map[string]*policy.GroupScalin
 	return out, nil
 }
 
-func (p *PolicyBackend) GetJobGroupPolicy(job, group string) (*policy.GroupScalingPolicy, error) {
+func (p *PolicyBackend) GetJobGroupPolicy(ctx context.Context, job, group string) (*policy.GroupScalingPolicy, error) {
 	defer metrics.MeasureSince(metricKeyGetJobGroupPolicy, time.Now())
 
-	kv, _, err := p.kv.Get(p.path+job+"/"+group, nil)
+	if cached, ok := p.cachedPolicies(); ok {
+		return cached[job][group], nil
+	}
+
+	opts, err := p.queryOptions(ctx)
 	if err != nil {
 		return nil, err
 	}
 
-	if kv == nil {
-		return nil, nil
+	kv, _, err := p.kv.Get(p.path+job+"/"+group, opts)
+	if err != nil {
+		return nil, err
 	}
 
-	out := &policy.GroupScalingPolicy{}
-
-	if err := json.Unmarshal(kv.Value, out); err != nil {
-		return nil, errors.Wrap(err, "failed to unmarshal Consul KV value")
+	if kv == nil {
+		return nil, nil
 	}
 
-	return out, nil
+	return p.decodePolicy(kv.Value, &policy.GroupScalingPolicy{})
 }
 
-func (p *PolicyBackend) PutJobPolicy(job string, groupPolicies map[string]*policy.GroupScalingPolicy) error {
+func (p *PolicyBackend) PutJobPolicy(ctx context.Context, job string, groupPolicies map[string]*policy.GroupScalingPolicy) error {
 	defer metrics.MeasureSince(metricKeyPutJobPolicy, time.Now())
 
 	var kvOpts []*api.KVTxnOp // nolint:prealloc
 
 	for group, pol := range groupPolicies {
 
-		marshal, err := json.Marshal(pol)
+		marshal, err := p.encodePolicy(pol)
 		if err != nil {
 			return err
 		}
@@ -150,7 +371,13 @@ func (p *PolicyBackend) PutJobPolicy(job string, groupPolicies map[string]*polic
 		kvOpts = append(kvOpts, kvOpt)
 	}
 
-	success, _, _, err := p.kv.Txn(kvOpts, nil)
+	opts, err := p.queryOptions(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.invalidateCache()
+
+	success, _, _, err := p.kv.Txn(kvOpts, opts)
 	if err != nil {
 		return err
 	}
@@ -162,10 +389,10 @@ func (p *PolicyBackend) PutJobPolicy(job string, groupPolicies map[string]*polic
 	return nil
 }
 
-func (p *PolicyBackend) PutJobGroupPolicy(job, group string, pol *policy.GroupScalingPolicy) error {
+func (p *PolicyBackend) PutJobGroupPolicy(ctx context.Context, job, group string, pol *policy.GroupScalingPolicy) error {
 	defer metrics.MeasureSince(metricKeyPutJobGroupPolicy, time.Now())
 
-	marshal, err := json.Marshal(pol)
+	marshal, err := p.encodePolicy(pol)
 	if err != nil {
 		return err
 	}
@@ -175,20 +402,38 @@ func (p *PolicyBackend) PutJobGroupPolicy(job, group string, pol *policy.GroupSc
 		Value: marshal,
 	}
 
-	_, err = p.kv.Put(pair, nil)
+	opts, err := p.writeOptions(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.invalidateCache()
+
+	_, err = p.kv.Put(pair, opts)
 	return err
 }
 
-func (p *PolicyBackend) DeleteJobPolicy(job string) error {
+func (p *PolicyBackend) DeleteJobPolicy(ctx context.Context, job string) error {
 	defer metrics.MeasureSince(metricKeyDeleteJobPolicy, time.Now())
 
-	_, err := p.kv.DeleteTree(p.path+job, nil)
+	opts, err := p.writeOptions(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.invalidateCache()
+
+	_, err = p.kv.DeleteTree(p.path+job+"/", opts)
 	return err
 }
 
-func (p *PolicyBackend) DeleteJobGroupPolicy(job, group string) error {
+func (p *PolicyBackend) DeleteJobGroupPolicy(ctx context.Context, job, group string) error {
 	defer metrics.MeasureSince(metricKeyDeleteJobGroupPolicy, time.Now())
 
-	_, err := p.kv.Delete(p.path+job+"/"+group, nil)
+	opts, err := p.writeOptions(ctx)
+	if err != nil {
+		return err
+	}
+	defer p.invalidateCache()
+
+	_, err = p.kv.Delete(p.path+job+"/"+group, opts)
 	return err
 }
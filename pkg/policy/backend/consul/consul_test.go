@@ -0,0 +1,170 @@
+package consul
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/consul/api"
+	"github.com/jrasell/sherpa/pkg/encryption"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyBackend_encodeDecodePolicy(t *testing.T) {
+	kr, err := encryption.NewKeyring([]encryption.Key{{ID: "primary", Key: bytes.Repeat([]byte{1}, encryption.KeySize)}})
+	assert.Nil(t, err)
+
+	pol := &policy.GroupScalingPolicy{
+		Enabled:       true,
+		MaxCount:      10,
+		PreScaleHooks: []*policy.ScalingHook{{URL: "https://hooks.example.com?token=s3cr3t"}},
+	}
+
+	// With encryption enabled, the stored policy does not contain the sensitive value, but is
+	// decoded back to the original policy.
+	p := &PolicyBackend{keyring: kr}
+
+	stored, err := p.encodePolicy(pol)
+	assert.Nil(t, err)
+	assert.NotContains(t, string(stored), "s3cr3t")
+	assert.Equal(t, "https://hooks.example.com?token=s3cr3t", pol.PreScaleHooks[0].URL)
+
+	decoded, err := p.decodePolicy(stored, &policy.GroupScalingPolicy{})
+	assert.Nil(t, err)
+	assert.Equal(t, pol, decoded)
+
+	// Encrypted policies cannot be read once encryption is disabled, while plaintext policies
+	// written before encryption was enabled can be read.
+	_, err = (&PolicyBackend{}).decodePolicy(stored, &policy.GroupScalingPolicy{})
+	assert.NotNil(t, err)
+
+	plain, err := (&PolicyBackend{}).encodePolicy(pol)
+	assert.Nil(t, err)
+	assert.Contains(t, string(plain), "s3cr3t")
+
+	decoded, err = p.decodePolicy(plain, &policy.GroupScalingPolicy{})
+	assert.Nil(t, err)
+	assert.Equal(t, pol, decoded)
+}
+
+// fakeKV is a minimal Consul KV list endpoint which supports blocking queries.
+type fakeKV struct {
+	lock    sync.Mutex
+	index   uint64
+	pairs   api.KVPairs
+	changed chan struct{}
+	tokens  []string
+}
+
+func (f *fakeKV) set(index uint64, pairs api.KVPairs) {
+	f.lock.Lock()
+	f.index, f.pairs = index, pairs
+	close(f.changed)
+	f.changed = make(chan struct{})
+	f.lock.Unlock()
+}
+
+func (f *fakeKV) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	wait, _ := strconv.ParseUint(r.URL.Query().Get("index"), 10, 64)
+
+	f.lock.Lock()
+	f.tokens = append(f.tokens, r.Header.Get("X-Consul-Token"))
+	for wait > 0 && wait >= f.index {
+		ch := f.changed
+		f.lock.Unlock()
+		select {
+		case <-ch:
+		case <-r.Context().Done():
+			return
+		}
+		f.lock.Lock()
+	}
+	index, pairs := f.index, f.pairs
+	f.lock.Unlock()
+
+	w.Header().Set("X-Consul-Index", strconv.FormatUint(index, 10))
+	_ = json.NewEncoder(w).Encode(pairs)
+}
+
+func waitForCache(t *testing.T, p *PolicyBackend, fn func(map[string]map[string]*policy.GroupScalingPolicy) bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cached, ok := p.cachedPolicies(); ok && fn(cached) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for policy cache")
+}
+
+func TestPolicyBackend_WatchPolicies(t *testing.T) {
+	kv := &fakeKV{index: 5, changed: make(chan struct{}), pairs: api.KVPairs{
+		{Key: "sherpa/policies/web/frontend", Value: []byte(`{"Enabled":true,"MaxCount":10}`)},
+	}}
+	srv := httptest.NewServer(kv)
+	defer srv.Close()
+
+	client, err := api.NewClient(&api.Config{Address: srv.URL})
+	assert.Nil(t, err)
+
+	token := secret.NewResolver(secret.Config{}, zerolog.Nop()).Value("policy-token")
+	p := NewConsulPolicyBackend(zerolog.Nop(), "sherpa/", token, client, nil)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go p.WatchPolicies(stopCh)
+
+	waitForCache(t, p, func(c map[string]map[string]*policy.GroupScalingPolicy) bool { return c["web"] != nil })
+
+	pol, err := p.GetJobGroupPolicy(context.Background(), "web", "frontend")
+	assert.Nil(t, err)
+	assert.Equal(t, 10, pol.MaxCount)
+
+	kv.set(6, api.KVPairs{
+		{Key: "sherpa/policies/web/frontend", Value: []byte(`{"Enabled":true,"MaxCount":20}`)},
+		{Key: "sherpa/policies/batch/worker", Value: []byte(`{"Enabled":true,"MaxCount":5}`)},
+	})
+	waitForCache(t, p, func(c map[string]map[string]*policy.GroupScalingPolicy) bool { return c["batch"] != nil })
+
+	policies, err := p.GetPolicies(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, 20, policies["web"]["frontend"].MaxCount)
+	assert.Equal(t, 5, policies["batch"]["worker"].MaxCount)
+
+	// Modifying the returned policies must not modify the cache.
+	delete(policies, "batch")
+	jobPolicy, err := p.GetJobPolicy(context.Background(), "batch")
+	assert.Nil(t, err)
+	assert.Len(t, jobPolicy, 1)
+
+	kv.lock.Lock()
+	for _, token := range kv.tokens {
+		assert.Equal(t, "policy-token", token)
+	}
+	kv.lock.Unlock()
+}
+
+func TestPolicyBackend_setCache(t *testing.T) {
+	p := &PolicyBackend{}
+
+	assert.True(t, p.setCache(map[string]map[string]*policy.GroupScalingPolicy{}, 0))
+	_, ready := p.cachedPolicies()
+	assert.True(t, ready)
+
+	// A write invalidates the cache, and a refresh started before the write is discarded.
+	p.invalidateCache()
+	_, ready = p.cachedPolicies()
+	assert.False(t, ready)
+
+	assert.False(t, p.setCache(map[string]map[string]*policy.GroupScalingPolicy{}, 0))
+	assert.True(t, p.setCache(map[string]map[string]*policy.GroupScalingPolicy{}, 1))
+}
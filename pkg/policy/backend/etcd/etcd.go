@@ -0,0 +1,625 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/hashicorp/go-cleanhttp"
+	"github.com/jrasell/sherpa/pkg/encryption"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/policy/backend"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var _ backend.PolicyBackend = (*PolicyBackend)(nil)
+
+const (
+	baseKeyPath = "policies/"
+
+	// watchWaitTime is the maximum time a watch waits for a change before the policies are read
+	// again, and watchRetryInterval is the time waited before retrying a failed read or watch.
+	watchWaitTime      = 5 * time.Minute
+	watchRetryInterval = 5 * time.Second
+
+	headerAuthorization = "Authorization"
+)
+
+// Define our metric keys.
+var (
+	metricKeyGetPolicies          = []string{"policy", "etcd", "get_policies"}
+	metricKeyGetJobPolicy         = []string{"policy", "etcd", "get_job_policy"}
+	metricKeyGetJobGroupPolicy    = []string{"policy", "etcd", "get_job_group_policy"}
+	metricKeyPutJobPolicy         = []string{"policy", "etcd", "put_job_policy"}
+	metricKeyPutJobGroupPolicy    = []string{"policy", "etcd", "put_job_group_policy"}
+	metricKeyDeleteJobPolicy      = []string{"policy", "etcd", "delete_job_policy"}
+	metricKeyDeleteJobGroupPolicy = []string{"policy", "etcd", "delete_job_group_policy"}
+	metricKeyWatchRefresh         = []string{"policy", "etcd", "watch_refresh"}
+)
+
+// PolicyBackend stores policies within etcd using the v3 JSON gateway, which is served by etcd on
+// its client URLs and so requires no gRPC client.
+type PolicyBackend struct {
+	endpoints []string
+	path      string
+	logger    zerolog.Logger
+	client    *http.Client
+
+	// keyring encrypts the sensitive fields of policies before they are written to etcd, and is
+	// nil if encryption at rest is disabled.
+	keyring *encryption.Keyring
+
+	// username and password authenticate requests when etcd auth is enabled. The token is the
+	// auth token obtained using them, and is renewed when etcd rejects it.
+	username  string
+	password  *secret.Value
+	tokenLock sync.Mutex
+	token     string
+
+	// cache holds the policies read by WatchPolicies, and is used to serve reads while cacheReady
+	// is true. Writes, and changes observed by the watch, increment the cache generation and mark
+	// it as not ready, so reads go to etcd until the policies have been read again.
+	cacheLock  sync.RWMutex
+	cache      map[string]map[string]*policy.GroupScalingPolicy
+	cacheReady bool
+	cacheGen   uint64
+}
+
+// NewEtcdPolicyBackend creates a policy backend which stores policies within etcd under the passed
+// key prefix. Requests are sent to the first endpoint which responds. If the username is not
+// empty, requests are authenticated using it and the password. If the keyring is not nil, the
+// sensitive fields of policies are encrypted before being written.
+func NewEtcdPolicyBackend(log zerolog.Logger, endpoints []string, path, username string, password *secret.Value, keyring *encryption.Keyring) *PolicyBackend {
+	eps := make([]string, len(endpoints))
+	for i, ep := range endpoints {
+		eps[i] = strings.TrimSuffix(ep, "/")
+	}
+
+	return &PolicyBackend{
+		endpoints: eps,
+		path:      path + baseKeyPath,
+		logger:    log,
+		client:    cleanhttp.DefaultPooledClient(),
+		keyring:   keyring,
+		username:  username,
+		password:  password,
+	}
+}
+
+// keyValue is an etcd key and its value. The JSON gateway encodes both as base64, which is the
+// encoding used for byte slices.
+type keyValue struct {
+	Key   []byte `json:"key"`
+	Value []byte `json:"value,omitempty"`
+}
+
+type responseHeader struct {
+	Revision int64 `json:"revision,string"`
+}
+
+type rangeRequest struct {
+	Key      []byte `json:"key"`
+	RangeEnd []byte `json:"range_end,omitempty"`
+}
+
+type rangeResponse struct {
+	Header responseHeader `json:"header"`
+	KVs    []*keyValue    `json:"kvs"`
+}
+
+type txnRequest struct {
+	Success []*txnOp `json:"success"`
+}
+
+type txnOp struct {
+	RequestPut *keyValue `json:"request_put"`
+}
+
+type txnResponse struct {
+	Succeeded bool `json:"succeeded"`
+}
+
+type watchRequest struct {
+	CreateRequest struct {
+		Key           []byte `json:"key"`
+		RangeEnd      []byte `json:"range_end"`
+		StartRevision int64  `json:"start_revision,string"`
+	} `json:"create_request"`
+}
+
+type watchResponse struct {
+	Result struct {
+		Created         bool              `json:"created"`
+		Canceled        bool              `json:"canceled"`
+		CompactRevision int64             `json:"compact_revision,string"`
+		Events          []json.RawMessage `json:"events"`
+	} `json:"result"`
+	Error *gatewayError `json:"error"`
+}
+
+// gatewayError is the error body returned by the etcd JSON gateway.
+type gatewayError struct {
+	Message string `json:"message"`
+	Code    int    `json:"code"`
+}
+
+// statusError is returned when etcd responds with an unexpected status code.
+type statusError struct {
+	code    int
+	message string
+}
+
+func (e *statusError) Error() string {
+	return "etcd responded with " + http.StatusText(e.code) + ": " + e.message
+}
+
+func isUnauthorized(err error) bool {
+	se, ok := errors.Cause(err).(*statusError)
+	return ok && se.code == http.StatusUnauthorized
+}
+
+// prefixEnd returns the range end which selects all keys with the prefix.
+func prefixEnd(prefix string) []byte {
+	end := []byte(prefix)
+	for i := len(end) - 1; i >= 0; i-- {
+		if end[i] < 0xff {
+			end[i]++
+			return end[:i+1]
+		}
+	}
+	return []byte{0}
+}
+
+// call performs the JSON gateway request. If auth is enabled and the token has expired, it is
+// renewed and the request retried once.
+func (p *PolicyBackend) call(ctx context.Context, path string, body, out interface{}) error {
+	err := p.callOnce(ctx, path, body, out)
+	if isUnauthorized(err) && p.username != "" {
+		p.tokenLock.Lock()
+		p.token = ""
+		p.tokenLock.Unlock()
+		err = p.callOnce(ctx, path, body, out)
+	}
+	return err
+}
+
+func (p *PolicyBackend) callOnce(ctx context.Context, path string, body, out interface{}) error {
+	token, err := p.authToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := p.post(ctx, path, token, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return errors.Wrap(err, "failed to decode etcd response")
+	}
+	return nil
+}
+
+// post sends the request to each endpoint in turn until one responds, returning the successful
+// response.
+func (p *PolicyBackend) post(ctx context.Context, path, token string, body interface{}) (*http.Response, error) {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to marshal etcd request")
+	}
+
+	var lastErr error
+
+	for _, ep := range p.endpoints {
+		req, err := http.NewRequest(http.MethodPost, ep+path, bytes.NewReader(payload))
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to build etcd request")
+		}
+		req.Header.Set("Content-Type", "application/json")
+		if token != "" {
+			req.Header.Set(headerAuthorization, token)
+		}
+
+		resp, err := p.client.Do(req.WithContext(ctx))
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, ctx.Err()
+			}
+			lastErr = errors.Wrapf(err, "failed to call etcd endpoint %s", ep)
+			continue
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			defer resp.Body.Close()
+
+			var gwErr gatewayError
+			data, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+			if json.Unmarshal(data, &gwErr) != nil || gwErr.Message == "" {
+				gwErr.Message = strings.TrimSpace(string(data))
+			}
+			return nil, &statusError{code: resp.StatusCode, message: gwErr.Message}
+		}
+		return resp, nil
+	}
+	return nil, lastErr
+}
+
+// authToken returns the current auth token, authenticating with etcd if there is none. An empty
+// token is returned if auth is not configured.
+func (p *PolicyBackend) authToken(ctx context.Context) (string, error) {
+	if p.username == "" {
+		return "", nil
+	}
+
+	p.tokenLock.Lock()
+	defer p.tokenLock.Unlock()
+
+	if p.token != "" {
+		return p.token, nil
+	}
+
+	password, err := p.password.Get(ctx)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to resolve etcd password")
+	}
+
+	resp, err := p.post(ctx, "/v3/auth/authenticate", "", map[string]string{"name": p.username, "password": password})
+	if err != nil {
+		return "", errors.Wrap(err, "failed to authenticate with etcd")
+	}
+	defer resp.Body.Close()
+
+	var auth struct {
+		Token string `json:"token"`
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(&auth); err != nil {
+		return "", errors.Wrap(err, "failed to decode etcd authenticate response")
+	}
+	if auth.Token == "" {
+		return "", errors.New("etcd authenticate response does not include a token")
+	}
+
+	p.token = auth.Token
+	return p.token, nil
+}
+
+// WatchPolicies keeps a cache of all policies up to date using an etcd watch until the stop
+// channel is closed. The policies are read, and then watched from the revision of the read; any
+// change invalidates the cache and the policies are read again. While the cache is populated,
+// policy reads are served from it rather than etcd, and changes made by other Sherpa servers
+// sharing the prefix are picked up as they happen.
+func (p *PolicyBackend) WatchPolicies(stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() {
+		<-stopCh
+		cancel()
+	}()
+
+	p.logger.Info().Str("path", p.path).Msg("starting etcd policy cache watcher")
+
+	for {
+		p.cacheLock.RLock()
+		gen := p.cacheGen
+		p.cacheLock.RUnlock()
+
+		policies, rev, err := p.listPolicies(ctx, p.path)
+		if err == nil {
+			// A policy written during the read invalidates the result, so the policies are read
+			// again immediately.
+			if !p.setCache(policies, gen) {
+				continue
+			}
+			metrics.IncrCounter(metricKeyWatchRefresh, 1)
+
+			err = p.watch(ctx, rev+1)
+		}
+
+		if ctx.Err() != nil {
+			p.logger.Info().Msg("stopping etcd policy cache watcher")
+			return
+		}
+		if err == nil {
+			continue
+		}
+
+		p.invalidateCache()
+		p.logger.Error().Err(err).Msg("failed to refresh etcd policy cache")
+
+		select {
+		case <-time.After(watchRetryInterval):
+		case <-stopCh:
+			p.logger.Info().Msg("stopping etcd policy cache watcher")
+			return
+		}
+	}
+}
+
+// watch blocks until a policy changes after the passed revision, invalidating the cache when it
+// does. It returns nil once a change is observed, the revision has been compacted, or the wait
+// time is reached, so the caller reads the policies again.
+func (p *PolicyBackend) watch(ctx context.Context, rev int64) error {
+	ctx, cancel := context.WithTimeout(ctx, watchWaitTime)
+	defer cancel()
+
+	token, err := p.authToken(ctx)
+	if err != nil {
+		return err
+	}
+
+	var req watchRequest
+	req.CreateRequest.Key = []byte(p.path)
+	req.CreateRequest.RangeEnd = prefixEnd(p.path)
+	req.CreateRequest.StartRevision = rev
+
+	resp, err := p.post(ctx, "/v3/watch", token, &req)
+	if err != nil {
+		if isUnauthorized(err) {
+			p.tokenLock.Lock()
+			p.token = ""
+			p.tokenLock.Unlock()
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil
+		}
+		return err
+	}
+	defer resp.Body.Close()
+
+	dec := json.NewDecoder(resp.Body)
+
+	for {
+		var msg watchResponse
+
+		if err := dec.Decode(&msg); err != nil {
+			if ctx.Err() == context.DeadlineExceeded {
+				return nil
+			}
+			return errors.Wrap(err, "etcd watch stream failed")
+		}
+		if msg.Error != nil {
+			return errors.Errorf("etcd watch failed: %s", msg.Error.Message)
+		}
+
+		switch {
+		case msg.Result.CompactRevision > 0:
+			p.logger.Debug().Int64("compact-revision", msg.Result.CompactRevision).Msg("etcd watch revision compacted")
+			p.invalidateCache()
+			return nil
+		case msg.Result.Canceled:
+			return errors.New("etcd watch was cancelled")
+		case len(msg.Result.Events) > 0:
+			p.invalidateCache()
+			return nil
+		}
+	}
+}
+
+// setCache stores the policies read by the watcher, unless the cache generation has changed
+// since the read was started as a write may have been missed.
+func (p *PolicyBackend) setCache(policies map[string]map[string]*policy.GroupScalingPolicy, gen uint64) bool {
+	p.cacheLock.Lock()
+	defer p.cacheLock.Unlock()
+
+	if gen != p.cacheGen {
+		return false
+	}
+	p.cache = policies
+	p.cacheReady = true
+	return true
+}
+
+// invalidateCache marks the cache as not ready, so that reads are performed against etcd until
+// the watcher has refreshed the cache.
+func (p *PolicyBackend) invalidateCache() {
+	p.cacheLock.Lock()
+	p.cacheGen++
+	p.cacheReady = false
+	p.cacheLock.Unlock()
+}
+
+// cachedPolicies returns the cached policies, and whether the cache is ready for use.
+func (p *PolicyBackend) cachedPolicies() (map[string]map[string]*policy.GroupScalingPolicy, bool) {
+	p.cacheLock.RLock()
+	defer p.cacheLock.RUnlock()
+	return p.cache, p.cacheReady
+}
+
+// decodePolicy unmarshals the stored policy, decrypting any encrypted sensitive fields.
+func (p *PolicyBackend) decodePolicy(value []byte) (*policy.GroupScalingPolicy, error) {
+	out := &policy.GroupScalingPolicy{ExternalChecks: make(map[string]*policy.ExternalCheck)}
+
+	if err := json.Unmarshal(value, out); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal etcd value")
+	}
+
+	dec, err := out.TransformSensitive(p.keyring.Decrypt)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt etcd value")
+	}
+	return dec, nil
+}
+
+// encodePolicy marshals the policy for storage, encrypting its sensitive fields if encryption at
+// rest is enabled.
+func (p *PolicyBackend) encodePolicy(pol *policy.GroupScalingPolicy) ([]byte, error) {
+	if p.keyring != nil && pol != nil {
+		enc, err := pol.TransformSensitive(p.keyring.Encrypt)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to encrypt policy")
+		}
+		pol = enc
+	}
+	return json.Marshal(pol)
+}
+
+// listPolicies reads all policies with the key prefix from etcd, returning nil if no policies
+// are stored, along with the revision of the read.
+func (p *PolicyBackend) listPolicies(ctx context.Context, prefix string) (map[string]map[string]*policy.GroupScalingPolicy, int64, error) {
+	var resp rangeResponse
+
+	if err := p.call(ctx, "/v3/kv/range", &rangeRequest{Key: []byte(prefix), RangeEnd: prefixEnd(prefix)}, &resp); err != nil {
+		return nil, 0, err
+	}
+
+	if len(resp.KVs) == 0 {
+		return nil, resp.Header.Revision, nil
+	}
+
+	out := make(map[string]map[string]*policy.GroupScalingPolicy)
+
+	for _, kv := range resp.KVs {
+		keyPolicy, err := p.decodePolicy(kv.Value)
+		if err != nil {
+			return nil, 0, err
+		}
+
+		keySplit := strings.Split(string(kv.Key), "/")
+		jobName := keySplit[len(keySplit)-2]
+		groupName := keySplit[len(keySplit)-1]
+
+		if _, ok := out[jobName]; !ok {
+			out[jobName] = map[string]*policy.GroupScalingPolicy{}
+		}
+
+		out[jobName][groupName] = keyPolicy
+	}
+
+	return out, resp.Header.Revision, nil
+}
+
+// copyJobPolicy returns a copy of the job policy map, so that callers cannot modify the cache.
+func copyJobPolicy(groups map[string]*policy.GroupScalingPolicy) map[string]*policy.GroupScalingPolicy {
+	out := make(map[string]*policy.GroupScalingPolicy, len(groups))
+	for group, pol := range groups {
+		out[group] = pol
+	}
+	return out
+}
+
+func (p *PolicyBackend) GetPolicies(ctx context.Context) (map[string]map[string]*policy.GroupScalingPolicy, error) {
+	defer metrics.MeasureSince(metricKeyGetPolicies, time.Now())
+
+	if cached, ok := p.cachedPolicies(); ok {
+		if len(cached) == 0 {
+			return nil, nil
+		}
+		out := make(map[string]map[string]*policy.GroupScalingPolicy, len(cached))
+		for job, groups := range cached {
+			out[job] = copyJobPolicy(groups)
+		}
+		return out, nil
+	}
+
+	out, _, err := p.listPolicies(ctx, p.path)
+	return out, err
+}
+
+func (p *PolicyBackend) GetJobPolicy(ctx context.Context, job string) (map[string]*policy.GroupScalingPolicy, error) {
+	defer metrics.MeasureSince(metricKeyGetJobPolicy, time.Now())
+
+	if cached, ok := p.cachedPolicies(); ok {
+		if groups, ok := cached[job]; ok {
+			return copyJobPolicy(groups), nil
+		}
+		return nil, nil
+	}
+
+	out, _, err := p.listPolicies(ctx, p.path+job+"/")
+	if err != nil || out == nil {
+		return nil, err
+	}
+	return out[job], nil
+}
+
+func (p *PolicyBackend) GetJobGroupPolicy(ctx context.Context, job, group string) (*policy.GroupScalingPolicy, error) {
+	defer metrics.MeasureSince(metricKeyGetJobGroupPolicy, time.Now())
+
+	if cached, ok := p.cachedPolicies(); ok {
+		return cached[job][group], nil
+	}
+
+	var resp rangeResponse
+
+	if err := p.call(ctx, "/v3/kv/range", &rangeRequest{Key: []byte(p.path + job + "/" + group)}, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.KVs) == 0 {
+		return nil, nil
+	}
+
+	return p.decodePolicy(resp.KVs[0].Value)
+}
+
+func (p *PolicyBackend) PutJobPolicy(ctx context.Context, job string, groupPolicies map[string]*policy.GroupScalingPolicy) error {
+	defer metrics.MeasureSince(metricKeyPutJobPolicy, time.Now())
+
+	var req txnRequest
+
+	for group, pol := range groupPolicies {
+		marshal, err := p.encodePolicy(pol)
+		if err != nil {
+			return err
+		}
+		req.Success = append(req.Success, &txnOp{RequestPut: &keyValue{Key: []byte(p.path + job + "/" + group), Value: marshal}})
+	}
+	defer p.invalidateCache()
+
+	var resp txnResponse
+
+	if err := p.call(ctx, "/v3/kv/txn", &req, &resp); err != nil {
+		return err
+	}
+
+	if !resp.Succeeded {
+		return errors.New("failed to write job policy etcd transaction")
+	}
+
+	return nil
+}
+
+func (p *PolicyBackend) PutJobGroupPolicy(ctx context.Context, job, group string, pol *policy.GroupScalingPolicy) error {
+	defer metrics.MeasureSince(metricKeyPutJobGroupPolicy, time.Now())
+
+	marshal, err := p.encodePolicy(pol)
+	if err != nil {
+		return err
+	}
+	defer p.invalidateCache()
+
+	var resp struct{}
+
+	return p.call(ctx, "/v3/kv/put", &keyValue{Key: []byte(p.path + job + "/" + group), Value: marshal}, &resp)
+}
+
+func (p *PolicyBackend) DeleteJobPolicy(ctx context.Context, job string) error {
+	defer metrics.MeasureSince(metricKeyDeleteJobPolicy, time.Now())
+	defer p.invalidateCache()
+
+	var resp struct{}
+
+	prefix := p.path + job + "/"
+	return p.call(ctx, "/v3/kv/deleterange", &rangeRequest{Key: []byte(prefix), RangeEnd: prefixEnd(prefix)}, &resp)
+}
+
+func (p *PolicyBackend) DeleteJobGroupPolicy(ctx context.Context, job, group string) error {
+	defer metrics.MeasureSince(metricKeyDeleteJobGroupPolicy, time.Now())
+	defer p.invalidateCache()
+
+	var resp struct{}
+
+	return p.call(ctx, "/v3/kv/deleterange", &rangeRequest{Key: []byte(p.path + job + "/" + group)}, &resp)
+}
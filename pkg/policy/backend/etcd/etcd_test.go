@@ -0,0 +1,260 @@
+package etcd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/secret"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeEtcd is an etcd v3 JSON gateway which stores keys in memory. Watches return once any key
+// within their range is changed.
+type fakeEtcd struct {
+	lock     sync.Mutex
+	rev      int64
+	kvs      map[string][]byte
+	changed  chan struct{}
+	auth     bool
+	tokens   int
+	revoked  bool
+	requests map[string]int
+}
+
+func newFakeEtcd(auth bool) (*fakeEtcd, *httptest.Server) {
+	f := &fakeEtcd{
+		rev:      1,
+		kvs:      make(map[string][]byte),
+		changed:  make(chan struct{}),
+		auth:     auth,
+		requests: make(map[string]int),
+	}
+	return f, httptest.NewServer(f)
+}
+
+// inRange returns whether the key is selected by the range request.
+func inRange(key string, req *rangeRequest) bool {
+	if len(req.RangeEnd) == 0 {
+		return key == string(req.Key)
+	}
+	return key >= string(req.Key) && key < string(req.RangeEnd)
+}
+
+// write applies the change and notifies watchers. The lock must be held.
+func (f *fakeEtcd) write(fn func()) {
+	fn()
+	f.rev++
+	close(f.changed)
+	f.changed = make(chan struct{})
+}
+
+func (f *fakeEtcd) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.lock.Lock()
+	f.requests[r.URL.Path]++
+
+	if r.URL.Path == "/v3/auth/authenticate" {
+		var req map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		if req["name"] != "sherpa" || req["password"] != "password" {
+			f.lock.Unlock()
+			w.WriteHeader(http.StatusUnauthorized)
+			_, _ = w.Write([]byte(`{"error": "authentication failed", "code": 16, "message": "authentication failed"}`))
+			return
+		}
+		f.tokens++
+		f.revoked = false
+		fmt.Fprintf(w, `{"token": "token-%d"}`, f.tokens)
+		f.lock.Unlock()
+		return
+	}
+
+	if f.auth && (f.revoked || r.Header.Get(headerAuthorization) != fmt.Sprintf("token-%d", f.tokens)) {
+		f.lock.Unlock()
+		w.WriteHeader(http.StatusUnauthorized)
+		_, _ = w.Write([]byte(`{"error": "invalid auth token", "code": 16, "message": "invalid auth token"}`))
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v3/kv/range":
+		var req rangeRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		resp := rangeResponse{Header: responseHeader{Revision: f.rev}}
+		for key, val := range f.kvs {
+			if inRange(key, &req) {
+				resp.KVs = append(resp.KVs, &keyValue{Key: []byte(key), Value: val})
+			}
+		}
+		sort.Slice(resp.KVs, func(i, j int) bool { return bytes.Compare(resp.KVs[i].Key, resp.KVs[j].Key) < 0 })
+		_ = json.NewEncoder(w).Encode(&resp)
+
+	case "/v3/kv/put":
+		var req keyValue
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.write(func() { f.kvs[string(req.Key)] = req.Value })
+		_, _ = w.Write([]byte(`{}`))
+
+	case "/v3/kv/txn":
+		var req txnRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.write(func() {
+			for _, op := range req.Success {
+				f.kvs[string(op.RequestPut.Key)] = op.RequestPut.Value
+			}
+		})
+		_, _ = w.Write([]byte(`{"succeeded": true}`))
+
+	case "/v3/kv/deleterange":
+		var req rangeRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.write(func() {
+			for key := range f.kvs {
+				if inRange(key, &req) {
+					delete(f.kvs, key)
+				}
+			}
+		})
+		_, _ = w.Write([]byte(`{}`))
+
+	case "/v3/watch":
+		var req watchRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+
+		changed := f.changed
+		current := f.rev
+		f.lock.Unlock()
+
+		_, _ = w.Write([]byte(`{"result": {"header": {"revision": "1"}, "created": true}}` + "\n"))
+		w.(http.Flusher).Flush()
+
+		// Changes made since the start revision are returned immediately.
+		if req.CreateRequest.StartRevision > current {
+			select {
+			case <-changed:
+			case <-r.Context().Done():
+				return
+			}
+		}
+		_, _ = w.Write([]byte(`{"result": {"header": {"revision": "2"}, "events": [{"kv": {}}]}}` + "\n"))
+		return
+
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+	f.lock.Unlock()
+}
+
+func (f *fakeEtcd) count(path string) int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.requests[path]
+}
+
+func waitForCache(t *testing.T, p *PolicyBackend, fn func(map[string]map[string]*policy.GroupScalingPolicy) bool) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		if cached, ok := p.cachedPolicies(); ok && fn(cached) {
+			return
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	t.Fatal("timed out waiting for policy cache")
+}
+
+func Test_prefixEnd(t *testing.T) {
+	assert.Equal(t, []byte("sherpa/policies0"), prefixEnd("sherpa/policies/"))
+	assert.Equal(t, []byte("b"), prefixEnd("a\xff"))
+	assert.Equal(t, []byte{0}, prefixEnd("\xff"))
+}
+
+func TestPolicyBackend(t *testing.T) {
+	fake, srv := newFakeEtcd(true)
+	defer srv.Close()
+
+	// The first endpoint is unavailable, so requests fail over to the second.
+	pb := NewEtcdPolicyBackend(zerolog.Nop(), []string{"http://127.0.0.1:1", srv.URL + "/"}, "sherpa/", "sherpa",
+		secret.NewResolver(secret.Config{}, zerolog.Nop()).Value("password"), nil)
+	ctx := context.Background()
+
+	out, err := pb.GetPolicies(ctx)
+	assert.Nil(t, err)
+	assert.Nil(t, out)
+
+	web := &policy.GroupScalingPolicy{Enabled: true, MinCount: 1, MaxCount: 10, ScaleOutCount: 1, ScaleInCount: 1}
+	assert.Nil(t, pb.PutJobPolicy(ctx, "example", map[string]*policy.GroupScalingPolicy{"web": web, "cache": web}))
+	assert.Nil(t, pb.PutJobGroupPolicy(ctx, "other", "api", web))
+	assert.Contains(t, fake.kvs, "sherpa/policies/example/web")
+
+	all, err := pb.GetPolicies(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, all, 2)
+	assert.Len(t, all["example"], 2)
+
+	// The token is renewed once etcd rejects it.
+	fake.revoked = true
+
+	job, err := pb.GetJobPolicy(ctx, "example")
+	assert.Nil(t, err)
+	assert.Len(t, job, 2)
+	assert.Equal(t, 2, fake.tokens)
+
+	group, err := pb.GetJobGroupPolicy(ctx, "other", "api")
+	assert.Nil(t, err)
+	assert.Equal(t, 10, group.MaxCount)
+
+	assert.Nil(t, pb.DeleteJobGroupPolicy(ctx, "example", "cache"))
+	job, err = pb.GetJobPolicy(ctx, "example")
+	assert.Nil(t, err)
+	assert.Len(t, job, 1)
+
+	assert.Nil(t, pb.DeleteJobPolicy(ctx, "example"))
+	job, err = pb.GetJobPolicy(ctx, "example")
+	assert.Nil(t, err)
+	assert.Nil(t, job)
+
+	group, err = pb.GetJobGroupPolicy(ctx, "example", "web")
+	assert.Nil(t, err)
+	assert.Nil(t, group)
+}
+
+func TestPolicyBackend_WatchPolicies(t *testing.T) {
+	fake, srv := newFakeEtcd(false)
+	defer srv.Close()
+
+	pb := NewEtcdPolicyBackend(zerolog.Nop(), []string{srv.URL}, "sherpa/", "", nil, nil)
+	ctx := context.Background()
+
+	web := &policy.GroupScalingPolicy{Enabled: true, MinCount: 1, MaxCount: 10}
+	assert.Nil(t, pb.PutJobGroupPolicy(ctx, "example", "web", web))
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go pb.WatchPolicies(stopCh)
+
+	waitForCache(t, pb, func(map[string]map[string]*policy.GroupScalingPolicy) bool { return true })
+
+	// Reads are served from the cache.
+	ranges := fake.count("/v3/kv/range")
+	job, err := pb.GetJobPolicy(ctx, "example")
+	assert.Nil(t, err)
+	assert.Len(t, job, 1)
+	assert.Equal(t, ranges, fake.count("/v3/kv/range"))
+
+	// A change made by another server invalidates the cache, which is then refreshed.
+	fake.lock.Lock()
+	fake.write(func() { fake.kvs["sherpa/policies/other/api"], _ = json.Marshal(web) })
+	fake.lock.Unlock()
+
+	waitForCache(t, pb, func(cached map[string]map[string]*policy.GroupScalingPolicy) bool { return len(cached) == 2 })
+}
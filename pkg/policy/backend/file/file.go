@@ -0,0 +1,281 @@
+package file
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/fsnotify/fsnotify"
+	"github.com/hashicorp/hcl"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/policy/backend"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var _ backend.PolicyBackend = (*PolicyBackend)(nil)
+
+// reloadDelay is the time waited after the last change to the policy directory before the
+// policies are reloaded, so that a burst of writes results in a single reload.
+const reloadDelay = time.Second
+
+// errReadOnly is returned by writes, as policies are changed by editing the policy files.
+var errReadOnly = errors.New("policies are managed by the file policy engine and cannot be changed using the API")
+
+// Define our metric keys.
+var (
+	metricKeyGetPolicies       = []string{"policy", "file", "get_policies"}
+	metricKeyGetJobPolicy      = []string{"policy", "file", "get_job_policy"}
+	metricKeyGetJobGroupPolicy = []string{"policy", "file", "get_job_group_policy"}
+	metricKeyReload            = []string{"policy", "file", "reload"}
+	metricKeyReloadError       = []string{"policy", "file", "reload_error"}
+)
+
+// PolicyBackend serves the policies defined within a directory of JSON and HCL files. The
+// policies are held in memory, and are replaced as a whole when the files are reloaded.
+type PolicyBackend struct {
+	dir         string
+	logger      zerolog.Logger
+	reloadDelay time.Duration
+
+	lock     sync.RWMutex
+	policies map[string]map[string]*policy.GroupScalingPolicy
+}
+
+// NewFilePolicyBackend creates a policy backend which serves the policies defined within the
+// files of the passed directory. An error is returned if the initial load of the files fails.
+func NewFilePolicyBackend(log zerolog.Logger, dir string) (*PolicyBackend, error) {
+	p := &PolicyBackend{
+		dir:         dir,
+		logger:      log,
+		reloadDelay: reloadDelay,
+	}
+
+	if err := p.Reload(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// Reload reads the policy files, replacing the policies held in memory. If any file cannot be
+// read or contains an invalid policy, the current policies are kept and an error returned.
+func (p *PolicyBackend) Reload() error {
+	defer metrics.MeasureSince(metricKeyReload, time.Now())
+
+	policies, err := loadDir(p.dir)
+	if err != nil {
+		metrics.IncrCounter(metricKeyReloadError, 1)
+		return err
+	}
+
+	p.lock.Lock()
+	p.policies = policies
+	p.lock.Unlock()
+
+	p.logger.Info().Str("dir", p.dir).Int("jobs", len(policies)).Msg("loaded scaling policies from files")
+	return nil
+}
+
+// WatchPolicies watches the policy directory, reloading the policies when files within it are
+// created, changed or removed. Any change within the directory triggers a reload, as directories
+// updated using an atomic symlink swap, such as Kubernetes ConfigMap mounts, only change the
+// symlink rather than the policy files. It runs until the stop channel is closed or receives.
+func (p *PolicyBackend) WatchPolicies(stopCh <-chan struct{}) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to create policy file watcher, policies will not be reloaded")
+		return
+	}
+	defer watcher.Close()
+
+	if err := watcher.Add(p.dir); err != nil {
+		p.logger.Error().Err(err).Str("dir", p.dir).Msg("failed to watch policy directory, policies will not be reloaded")
+		return
+	}
+
+	p.logger.Info().Str("dir", p.dir).Msg("starting policy file watcher")
+
+	var reload <-chan time.Time
+
+	for {
+		select {
+		case <-stopCh:
+			p.logger.Info().Msg("stopping policy file watcher")
+			return
+
+		case _, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			reload = time.After(p.reloadDelay)
+
+		case err, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+			p.logger.Error().Err(err).Msg("policy file watcher error")
+
+		case <-reload:
+			reload = nil
+			if err := p.Reload(); err != nil {
+				p.logger.Error().Err(err).Msg("failed to reload policy files, keeping current policies")
+			}
+		}
+	}
+}
+
+// GetPolicies satisfies the GetPolicies function of the backend.PolicyBackend interface.
+func (p *PolicyBackend) GetPolicies(_ context.Context) (map[string]map[string]*policy.GroupScalingPolicy, error) {
+	defer metrics.MeasureSince(metricKeyGetPolicies, time.Now())
+
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.policies, nil
+}
+
+// GetJobPolicy satisfies the GetJobPolicy function of the backend.PolicyBackend interface.
+func (p *PolicyBackend) GetJobPolicy(_ context.Context, job string) (map[string]*policy.GroupScalingPolicy, error) {
+	defer metrics.MeasureSince(metricKeyGetJobPolicy, time.Now())
+
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.policies[job], nil
+}
+
+// GetJobGroupPolicy satisfies the GetJobGroupPolicy function of the backend.PolicyBackend
+// interface.
+func (p *PolicyBackend) GetJobGroupPolicy(_ context.Context, job, group string) (*policy.GroupScalingPolicy, error) {
+	defer metrics.MeasureSince(metricKeyGetJobGroupPolicy, time.Now())
+
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.policies[job][group], nil
+}
+
+// PutJobPolicy satisfies the PutJobPolicy function of the backend.PolicyBackend interface.
+func (p *PolicyBackend) PutJobPolicy(_ context.Context, _ string, _ map[string]*policy.GroupScalingPolicy) error {
+	return errReadOnly
+}
+
+// PutJobGroupPolicy satisfies the PutJobGroupPolicy function of the backend.PolicyBackend
+// interface.
+func (p *PolicyBackend) PutJobGroupPolicy(_ context.Context, _, _ string, _ *policy.GroupScalingPolicy) error {
+	return errReadOnly
+}
+
+// DeleteJobPolicy satisfies the DeleteJobPolicy function of the backend.PolicyBackend interface.
+func (p *PolicyBackend) DeleteJobPolicy(_ context.Context, _ string) error {
+	return errReadOnly
+}
+
+// DeleteJobGroupPolicy satisfies the DeleteJobGroupPolicy function of the backend.PolicyBackend
+// interface.
+func (p *PolicyBackend) DeleteJobGroupPolicy(_ context.Context, _, _ string) error {
+	return errReadOnly
+}
+
+// isPolicyFile returns whether the file is read as a policy file, based on its extension.
+func isPolicyFile(name string) bool {
+	switch filepath.Ext(name) {
+	case ".json", ".hcl":
+		return true
+	}
+	return false
+}
+
+// hclFile is the layout of a HCL policy file, which contains job blocks each holding the
+// policies of its task groups as group blocks.
+type hclFile struct {
+	Jobs []*hclJob `hcl:"job"`
+}
+
+type hclJob struct {
+	Name   string                                `hcl:",key"`
+	Groups map[string]*policy.GroupScalingPolicy `hcl:"group"`
+}
+
+// loadDir reads the policies of every policy file within the directory. Each job group policy
+// may only be defined once across all the files.
+func loadDir(dir string) (map[string]map[string]*policy.GroupScalingPolicy, error) {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read policy directory")
+	}
+
+	policies := make(map[string]map[string]*policy.GroupScalingPolicy)
+	sources := make(map[string]string)
+
+	for _, info := range files {
+		if info.IsDir() || !isPolicyFile(info.Name()) {
+			continue
+		}
+
+		filePolicies, err := loadFile(filepath.Join(dir, info.Name()))
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to load policy file %s", info.Name())
+		}
+
+		for job, groups := range filePolicies {
+			for group, pol := range groups {
+				if pol == nil {
+					return nil, errors.Errorf("job %s group %s policy in %s is empty", job, group, info.Name())
+				}
+				if err := pol.Validate(); err != nil {
+					return nil, errors.Wrapf(err, "job %s group %s policy in %s is invalid", job, group, info.Name())
+				}
+
+				key := job + "/" + group
+				if src, ok := sources[key]; ok {
+					return nil, errors.Errorf("job %s group %s policy is defined in both %s and %s", job, group, src, info.Name())
+				}
+				sources[key] = info.Name()
+
+				if policies[job] == nil {
+					policies[job] = make(map[string]*policy.GroupScalingPolicy)
+				}
+				policies[job][group] = pol.MergeWithDefaults()
+			}
+		}
+	}
+	return policies, nil
+}
+
+// loadFile decodes a JSON or HCL policy file. JSON files hold an object of job names, each
+// holding an object of group names to policies.
+func loadFile(path string) (map[string]map[string]*policy.GroupScalingPolicy, error) {
+	src, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if filepath.Ext(path) == ".json" {
+		var out map[string]map[string]*policy.GroupScalingPolicy
+		if err := json.Unmarshal(src, &out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	}
+
+	var f hclFile
+	if err := hcl.Decode(&f, string(src)); err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]map[string]*policy.GroupScalingPolicy)
+	for _, job := range f.Jobs {
+		if out[job.Name] == nil {
+			out[job.Name] = make(map[string]*policy.GroupScalingPolicy)
+		}
+		for group, pol := range job.Groups {
+			if _, ok := out[job.Name][group]; ok {
+				return nil, errors.Errorf("job %s group %s policy is defined more than once", job.Name, group)
+			}
+			out[job.Name][group] = pol
+		}
+	}
+	return out, nil
+}
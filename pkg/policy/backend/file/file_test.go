@@ -0,0 +1,228 @@
+package file
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testJSONPolicy = `{"example": {"web": {"Enabled": true, "MinCount": 2, "MaxCount": 10}}}`
+
+	testHCLPolicy = `
+job "example" {
+  group "cache" {
+    Enabled  = true
+    MaxCount = 4
+  }
+}
+
+job "other" {
+  group "api" {
+    Enabled       = true
+    MaxCount      = 6
+    ScaleOutCount = 2
+
+    ExternalChecks "latency" {
+      Enabled            = true
+      Provider           = "prometheus"
+      Query              = "latency_ms"
+      ComparisonOperator = "greater-than"
+      ComparisonValue    = 100
+      Action             = "scale-out"
+    }
+  }
+}
+`
+)
+
+func writeFile(t *testing.T, dir, name, content string) {
+	assert.Nil(t, ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0600))
+}
+
+func newTestDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "sherpa-policies")
+	assert.Nil(t, err)
+	return dir
+}
+
+func TestPolicyBackend(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+
+	writeFile(t, dir, "example.json", testJSONPolicy)
+	writeFile(t, dir, "policies.hcl", testHCLPolicy)
+	writeFile(t, dir, "README.md", "not a policy")
+
+	pb, err := NewFilePolicyBackend(zerolog.Nop(), dir)
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	all, err := pb.GetPolicies(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, all, 2)
+
+	job, err := pb.GetJobPolicy(ctx, "example")
+	assert.Nil(t, err)
+	assert.Len(t, job, 2)
+	assert.Equal(t, 2, job["web"].MinCount)
+
+	// Unset parameters take the default values.
+	assert.Equal(t, policy.DefaultMinCount, job["cache"].MinCount)
+	assert.Equal(t, policy.DefaultCooldown, job["cache"].Cooldown)
+
+	group, err := pb.GetJobGroupPolicy(ctx, "other", "api")
+	assert.Nil(t, err)
+	assert.Equal(t, 2, group.ScaleOutCount)
+	assert.Equal(t, "latency_ms", group.ExternalChecks["latency"].Query)
+
+	group, err = pb.GetJobGroupPolicy(ctx, "missing", "api")
+	assert.Nil(t, err)
+	assert.Nil(t, group)
+
+	assert.Equal(t, errReadOnly, pb.PutJobGroupPolicy(ctx, "example", "web", group))
+	assert.Equal(t, errReadOnly, pb.DeleteJobPolicy(ctx, "example"))
+}
+
+func TestPolicyBackend_Reload(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+
+	writeFile(t, dir, "example.json", testJSONPolicy)
+
+	pb, err := NewFilePolicyBackend(zerolog.Nop(), dir)
+	assert.Nil(t, err)
+	ctx := context.Background()
+
+	testCases := []struct {
+		name, file, content, expectedErr string
+	}{
+		{
+			name:        "duplicate group",
+			file:        "duplicate.json",
+			content:     testJSONPolicy,
+			expectedErr: "job example group web policy is defined in both duplicate.json and example.json",
+		},
+		{
+			name:        "invalid policy",
+			file:        "invalid.json",
+			content:     `{"other": {"api": {"Enabled": true, "ResourceTasks": [""]}}}`,
+			expectedErr: "job other group api policy in invalid.json is invalid",
+		},
+		{
+			name:        "invalid syntax",
+			file:        "invalid.hcl",
+			content:     `job "other" {`,
+			expectedErr: "failed to load policy file invalid.hcl",
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			writeFile(t, dir, tc.file, tc.content)
+			defer os.Remove(filepath.Join(dir, tc.file))
+
+			err := pb.Reload()
+			if assert.NotNil(t, err) {
+				assert.Contains(t, err.Error(), tc.expectedErr)
+			}
+
+			// The policies loaded before the failed reload are kept.
+			all, err := pb.GetPolicies(ctx)
+			assert.Nil(t, err)
+			assert.Len(t, all, 1)
+		})
+	}
+
+	_, err = NewFilePolicyBackend(zerolog.Nop(), filepath.Join(dir, "missing"))
+	assert.NotNil(t, err)
+}
+
+func TestPolicyBackend_WatchPolicies(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+
+	writeFile(t, dir, "example.json", testJSONPolicy)
+
+	pb, err := NewFilePolicyBackend(zerolog.Nop(), dir)
+	assert.Nil(t, err)
+	pb.reloadDelay = 10 * time.Millisecond
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go pb.WatchPolicies(stopCh)
+
+	waitForPolicies := func(fn func(map[string]map[string]*policy.GroupScalingPolicy) bool) {
+		deadline := time.Now().Add(5 * time.Second)
+		for time.Now().Before(deadline) {
+			if all, _ := pb.GetPolicies(context.Background()); fn(all) {
+				return
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+		t.Fatal("timed out waiting for policies to reload")
+	}
+
+	// The watcher is started asynchronously, so the file is written until it is picked up.
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		writeFile(t, dir, "policies.hcl", testHCLPolicy)
+		if all, _ := pb.GetPolicies(context.Background()); len(all) == 2 || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	waitForPolicies(func(all map[string]map[string]*policy.GroupScalingPolicy) bool { return len(all) == 2 })
+
+	assert.Nil(t, os.Remove(filepath.Join(dir, "policies.hcl")))
+	waitForPolicies(func(all map[string]map[string]*policy.GroupScalingPolicy) bool { return len(all) == 1 })
+}
+
+func TestPolicyBackend_WatchPoliciesSymlinkSwap(t *testing.T) {
+	dir := newTestDir(t)
+	defer os.RemoveAll(dir)
+
+	// Lay the directory out as Kubernetes does for ConfigMap mounts, where the policy files are
+	// symlinks through the ..data symlink to a versioned directory.
+	writeVersion := func(version, content string) {
+		assert.Nil(t, os.Mkdir(filepath.Join(dir, version), 0700))
+		writeFile(t, filepath.Join(dir, version), "policies.json", content)
+	}
+	writeVersion("..v1", testJSONPolicy)
+	assert.Nil(t, os.Symlink("..v1", filepath.Join(dir, "..data")))
+	assert.Nil(t, os.Symlink(filepath.Join("..data", "policies.json"), filepath.Join(dir, "policies.json")))
+
+	pb, err := NewFilePolicyBackend(zerolog.Nop(), dir)
+	assert.Nil(t, err)
+	pb.reloadDelay = 10 * time.Millisecond
+
+	all, err := pb.GetPolicies(context.Background())
+	assert.Nil(t, err)
+	assert.Len(t, all, 1)
+
+	stopCh := make(chan struct{})
+	defer close(stopCh)
+	go pb.WatchPolicies(stopCh)
+
+	writeVersion("..v2", `{"example": {"web": {"Enabled": true}}, "other": {"api": {"Enabled": true}}}`)
+
+	// The watcher is started asynchronously, so the symlink is swapped until it is picked up.
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		assert.Nil(t, os.Symlink("..v2", filepath.Join(dir, "..data_tmp")))
+		assert.Nil(t, os.Rename(filepath.Join(dir, "..data_tmp"), filepath.Join(dir, "..data")))
+
+		time.Sleep(50 * time.Millisecond)
+		if all, _ := pb.GetPolicies(context.Background()); len(all) == 2 {
+			return
+		}
+	}
+	t.Fatal("timed out waiting for policies to reload")
+}
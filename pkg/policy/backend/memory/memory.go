@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"sync"
 	"time"
 
@@ -33,7 +34,7 @@ func NewJobScalingPolicies() backend.PolicyBackend {
 	}
 }
 
-func (p *PolicyBackend) GetPolicies() (map[string]map[string]*policy.GroupScalingPolicy, error) {
+func (p *PolicyBackend) GetPolicies(_ context.Context) (map[string]map[string]*policy.GroupScalingPolicy, error) {
 	defer metrics.MeasureSince(metricKeyGetPolicies, time.Now())
 
 	p.RLock()
@@ -42,7 +43,7 @@ func (p *PolicyBackend) GetPolicies() (map[string]map[string]*policy.GroupScalin
 	return val, nil
 }
 
-func (p *PolicyBackend) GetJobPolicy(job string) (map[string]*policy.GroupScalingPolicy, error) {
+func (p *PolicyBackend) GetJobPolicy(_ context.Context, job string) (map[string]*policy.GroupScalingPolicy, error) {
 	defer metrics.MeasureSince(metricKeyGetJobPolicy, time.Now())
 
 	p.RLock()
@@ -54,7 +55,7 @@ func (p *PolicyBackend) GetJobPolicy(job string) (map[string]*policy.GroupScalin
 	return nil, nil
 }
 
-func (p *PolicyBackend) GetJobGroupPolicy(job, group string) (*policy.GroupScalingPolicy, error) {
+func (p *PolicyBackend) GetJobGroupPolicy(_ context.Context, job, group string) (*policy.GroupScalingPolicy, error) {
 	defer metrics.MeasureSince(metricKeyGetJobGroupPolicy, time.Now())
 
 	p.RLock()
@@ -66,7 +67,7 @@ func (p *PolicyBackend) GetJobGroupPolicy(job, group string) (*policy.GroupScali
 	return nil, nil
 }
 
-func (p *PolicyBackend) PutJobPolicy(job string, policies map[string]*policy.GroupScalingPolicy) error {
+func (p *PolicyBackend) PutJobPolicy(_ context.Context, job string, policies map[string]*policy.GroupScalingPolicy) error {
 	defer metrics.MeasureSince(metricKeyPutJobPolicy, time.Now())
 
 	p.Lock()
@@ -82,7 +83,7 @@ func (p *PolicyBackend) PutJobPolicy(job string, policies map[string]*policy.Gro
 	return nil
 }
 
-func (p *PolicyBackend) PutJobGroupPolicy(job, group string, policies *policy.GroupScalingPolicy) error {
+func (p *PolicyBackend) PutJobGroupPolicy(_ context.Context, job, group string, policies *policy.GroupScalingPolicy) error {
 	defer metrics.MeasureSince(metricKeyPutJobGroupPolicy, time.Now())
 
 	p.Lock()
@@ -98,7 +99,7 @@ func (p *PolicyBackend) PutJobGroupPolicy(job, group string, policies *policy.Gr
 	return nil
 }
 
-func (p *PolicyBackend) DeleteJobGroupPolicy(job, group string) error {
+func (p *PolicyBackend) DeleteJobGroupPolicy(_ context.Context, job, group string) error {
 	defer metrics.MeasureSince(metricKeyDeleteJobPolicy, time.Now())
 
 	p.Lock()
@@ -110,7 +111,7 @@ func (p *PolicyBackend) DeleteJobGroupPolicy(job, group string) error {
 	return nil
 }
 
-func (p *PolicyBackend) DeleteJobPolicy(job string) error {
+func (p *PolicyBackend) DeleteJobPolicy(_ context.Context, job string) error {
 	defer metrics.MeasureSince(metricKeyDeleteJobGroupPolicy, time.Now())
 
 	p.Lock()
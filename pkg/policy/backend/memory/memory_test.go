@@ -1,6 +1,7 @@
 package memory
 
 import (
+	"context"
 	"testing"
 
 	"github.com/jrasell/sherpa/pkg/helper"
@@ -11,20 +12,21 @@ import (
 
 func TestPolicyBackend_Memory(t *testing.T) {
 	newBackend := NewJobScalingPolicies()
+	ctx := context.Background()
 
 	// Test putting a job group policy.
 	sherpaGroup1 := generateTestPolicy()
-	err := newBackend.PutJobGroupPolicy("sherpa-test-job-1", "sherpa-test-group-1", sherpaGroup1)
+	err := newBackend.PutJobGroupPolicy(ctx, "sherpa-test-job-1", "sherpa-test-group-1", sherpaGroup1)
 	assert.Nil(t, err)
 
 	// Test reading the job group back.
-	readSherpaGroup1, err := newBackend.GetJobGroupPolicy("sherpa-test-job-1", "sherpa-test-group-1")
+	readSherpaGroup1, err := newBackend.GetJobGroupPolicy(ctx, "sherpa-test-job-1", "sherpa-test-group-1")
 	assert.Nil(t, err)
 	assert.Equal(t, sherpaGroup1, readSherpaGroup1)
 
 	// Test adding a second job group.
 	sherpaGroup2 := generateTestPolicy()
-	err = newBackend.PutJobGroupPolicy("sherpa-test-job-1", "sherpa-test-group-2", sherpaGroup2)
+	err = newBackend.PutJobGroupPolicy(ctx, "sherpa-test-job-1", "sherpa-test-group-2", sherpaGroup2)
 	assert.Nil(t, err)
 
 	// Test reading the whole job back.
@@ -32,27 +34,27 @@ func TestPolicyBackend_Memory(t *testing.T) {
 		"sherpa-test-group-1": generateTestPolicy(),
 		"sherpa-test-group-2": generateTestPolicy(),
 	}
-	readSherpaJob1, err := newBackend.GetJobPolicy("sherpa-test-job-1")
+	readSherpaJob1, err := newBackend.GetJobPolicy(ctx, "sherpa-test-job-1")
 	assert.Nil(t, err)
 	assert.Equal(t, expectedJob1, readSherpaJob1)
 
 	// Test deleting a job group.
-	err = newBackend.DeleteJobGroupPolicy("sherpa-test-job-1", "sherpa-test-group-1")
+	err = newBackend.DeleteJobGroupPolicy(ctx, "sherpa-test-job-1", "sherpa-test-group-1")
 	assert.Nil(t, err)
 
 	// Read the job back and ensure the record has been deleted.
 	expectedJob2 := map[string]*policy.GroupScalingPolicy{"sherpa-test-group-2": generateTestPolicy()}
-	readSherpaJob2, err := newBackend.GetJobPolicy("sherpa-test-job-1")
+	readSherpaJob2, err := newBackend.GetJobPolicy(ctx, "sherpa-test-job-1")
 	assert.Nil(t, err)
 	assert.Equal(t, expectedJob2, readSherpaJob2)
 
 	// Test deleting a job policy.
-	err = newBackend.DeleteJobPolicy("sherpa-test-job-1")
+	err = newBackend.DeleteJobPolicy(ctx, "sherpa-test-job-1")
 	assert.Nil(t, err)
 
 	// Read the policies and check all are gone.
 	expectedSherpaPolicies1 := map[string]map[string]*policy.GroupScalingPolicy{}
-	sherpaPolicies1, err := newBackend.GetPolicies()
+	sherpaPolicies1, err := newBackend.GetPolicies(ctx)
 	assert.Nil(t, err)
 	assert.Equal(t, expectedSherpaPolicies1, sherpaPolicies1)
 
@@ -61,11 +63,11 @@ func TestPolicyBackend_Memory(t *testing.T) {
 		"sherpa-test-group-3": generateTestPolicy(),
 		"sherpa-test-group-4": generateTestPolicy(),
 	}
-	err = newBackend.PutJobPolicy("sherpa-test-2", putSherpaJob1)
+	err = newBackend.PutJobPolicy(ctx, "sherpa-test-2", putSherpaJob1)
 	assert.Nil(t, err)
 
 	// Read the job back and ensure it has been actually added.
-	readSherpaJob3, err := newBackend.GetJobPolicy("sherpa-test-2")
+	readSherpaJob3, err := newBackend.GetJobPolicy(ctx, "sherpa-test-2")
 	assert.Nil(t, err)
 	assert.Equal(t, putSherpaJob1, readSherpaJob3)
 }
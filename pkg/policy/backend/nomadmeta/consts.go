@@ -1,5 +1,9 @@
 package nomadmeta
 
+// metaKeyPrefix is the default prefix of all meta keys used to configure Sherpa scaling policies.
+// Meta keys using a configured prefix are normalised to this prefix before being parsed.
+const metaKeyPrefix = "sherpa_"
+
 const (
 	metaKeyEnabled                           = "sherpa_enabled"
 	metaKeyCooldown                          = "sherpa_cooldown"
@@ -11,5 +15,24 @@ const (
 	metaKeyScaleOutMemoryPercentageThreshold = "sherpa_scale_out_memory_percentage_threshold"
 	metaKeyScaleInCPUPercentageThreshold     = "sherpa_scale_in_cpu_percentage_threshold"
 	metaKeyScaleInMemoryPercentageThreshold  = "sherpa_scale_in_memory_percentage_threshold"
+	metaKeyScaleOutDiskPercentageThreshold   = "sherpa_scale_out_disk_percentage_threshold"
+	metaKeyScaleInDiskPercentageThreshold    = "sherpa_scale_in_disk_percentage_threshold"
+	metaKeyScaleOutGPUPercentageThreshold    = "sherpa_scale_out_gpu_percentage_threshold"
+	metaKeyScaleInGPUPercentageThreshold     = "sherpa_scale_in_gpu_percentage_threshold"
+	metaKeyResourceTasks                     = "sherpa_resource_tasks"
+	metaKeyCompositeCheck                    = "sherpa_composite_check"
 	metaKeyExternalChecks                    = "sherpa_external_checks"
+	metaKeyMetricsFallback                   = "sherpa_metrics_fallback"
+	metaKeyScaleOutSpread                    = "sherpa_scale_out_spread"
+	metaKeyScaleOrder                        = "sherpa_scale_order"
+	metaKeyWaitForHealthyTimeout             = "sherpa_wait_for_healthy_timeout"
+	metaKeyMinScaleDelta                     = "sherpa_min_scale_delta"
+	metaKeyMinScaleDeltaPercentage           = "sherpa_min_scale_delta_percentage"
+	metaKeyEvalPriority                      = "sherpa_eval_priority"
+	metaKeyRunbookURL                        = "sherpa_runbook_url"
+	metaKeyNotes                             = "sherpa_notes"
 )
+
+// metaKeyPrefixExternalCheck is the prefix of meta keys which configure a single external check,
+// with the remainder of the key used as the check name.
+const metaKeyPrefixExternalCheck = "sherpa_external_check_"
@@ -1,7 +1,8 @@
 package nomadmeta
 
 import (
-	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/filter"
 	"github.com/jrasell/sherpa/pkg/policy/backend"
 	"github.com/jrasell/sherpa/pkg/policy/backend/memory"
 	"github.com/rs/zerolog"
@@ -9,13 +10,22 @@ import (
 
 // NewJobScalingPolicies produces a new policy backend and processor. The policy backend is just
 // the memory backend. The processor is used to handle job watcher updates, where the job is
-// inspected for its status, and then any Sherpa meta parameters pulled out and validated.
-func NewJobScalingPolicies(logger zerolog.Logger, nomad *api.Client) (backend.PolicyBackend, *Processor) {
+// inspected for its status, and then any Sherpa meta parameters pulled out and validated. The
+// prefix identifies the meta keys used to configure policies; if empty, the default of sherpa_ is
+// used. Policies are only created for jobs which match the job filter.
+func NewJobScalingPolicies(logger zerolog.Logger, nomad *client.NomadPool, prefix string,
+	jobFilter *filter.JobFilter) (backend.PolicyBackend, *Processor) {
+	if prefix == "" {
+		prefix = metaKeyPrefix
+	}
+
 	b := memory.NewJobScalingPolicies()
 	return b, &Processor{
 		logger:        logger,
 		nomad:         nomad,
 		backend:       b,
+		keyPrefix:     prefix,
+		jobFilter:     jobFilter,
 		jobUpdateChan: make(chan interface{}),
 	}
 }
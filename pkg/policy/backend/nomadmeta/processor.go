@@ -1,20 +1,37 @@
 package nomadmeta
 
 import (
+	"context"
 	"encoding/json"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/filter"
+	"github.com/jrasell/sherpa/pkg/helper"
 	"github.com/jrasell/sherpa/pkg/policy"
 	"github.com/jrasell/sherpa/pkg/policy/backend"
 	"github.com/rs/zerolog"
 )
 
+// processTimeout is the maximum time spent processing a single job update, including calls to the
+// Nomad API and policy backend.
+const processTimeout = 30 * time.Second
+
 type Processor struct {
 	logger        zerolog.Logger
-	nomad         *api.Client
+	nomad         *client.NomadPool
 	backend       backend.PolicyBackend
 	jobUpdateChan chan interface{}
+
+	// keyPrefix is the configured prefix of meta keys used to configure scaling policies.
+	keyPrefix string
+
+	// jobFilter restricts the jobs which policies are created for, and is nil if all jobs are
+	// handled.
+	jobFilter *filter.JobFilter
 }
 
 func (pr *Processor) Run() {
@@ -39,6 +56,10 @@ func (pr *Processor) handleJobListMessage(msg interface{}) {
 	}
 	pr.logger.Debug().Msg("received job list update message to handle")
 
+	if !pr.jobFilter.MatchID(job.ID) {
+		return
+	}
+
 	switch job.Status {
 	case "running":
 		go pr.handleRunningJob(job.ID)
@@ -51,7 +72,10 @@ func (pr *Processor) handleJobListMessage(msg interface{}) {
 }
 
 func (pr *Processor) handleDeadJob(jobID string) {
-	if err := pr.backend.DeleteJobPolicy(jobID); err != nil {
+	ctx, cancel := context.WithTimeout(context.Background(), processTimeout)
+	defer cancel()
+
+	if err := pr.backend.DeleteJobPolicy(ctx, jobID); err != nil {
 		pr.logger.Error().
 			Str("job", jobID).
 			Err(err).
@@ -60,37 +84,61 @@ func (pr *Processor) handleDeadJob(jobID string) {
 }
 
 func (pr *Processor) handleRunningJob(jobID string) {
-	pr.logger.Debug().Str("job", jobID).Msg("reading job group meta stanzas")
+	pr.logger.Debug().Str("job", jobID).Msg("reading job group meta and scaling stanzas")
+
+	ctx, cancel := context.WithTimeout(context.Background(), processTimeout)
+	defer cancel()
+
+	var info scalingJob
 
-	info, _, err := pr.nomad.Jobs().Info(jobID, nil)
+	err := helper.CallWithContext(ctx, func() (err error) {
+		_, err = pr.nomad.Client().Raw().Query("/v1/job/"+jobID, &info, nil)
+		return err
+	})
 	if err != nil {
 		pr.logger.Error().Err(err).Msg("failed to call Nomad API for job information")
 		return
 	}
 
+	// Jobs excluded by the filter are treated as dead, so that any policy created before the job
+	// stopped matching the filter is removed.
+	if !pr.jobFilter.MatchJob(&api.Job{ID: &jobID, Namespace: info.Namespace, Meta: info.Meta}) {
+		pr.logger.Debug().Str("job", jobID).Msg("job does not match job filter, ignoring meta policies")
+		pr.handleDeadJob(jobID)
+		return
+	}
+
 	// Create a new object which will track all policies pulled from the job. Creating a new object
 	// helps remove policies which have been removed from task groups as the policy state will be
 	// overwritten.
 	policies := map[string]*policy.GroupScalingPolicy{}
 
-	for i := range info.TaskGroups {
-		if pr.hasMetaKeys(info.TaskGroups[i].Meta) {
-			policies[*info.TaskGroups[i].Name] = pr.policyFromMeta(info.TaskGroups[i].Meta)
+	for _, tg := range info.TaskGroups {
+		meta := mergeMeta(pr.keyPrefix, info.Meta, tg.Meta)
+
+		if !pr.hasMetaKeys(meta) && tg.Scaling == nil {
+			continue
+		}
+
+		pol := pr.policyFromMeta(meta)
+		if tg.Scaling != nil {
+			pr.applyScalingStanza(pol, meta, tg.Scaling)
 		}
+		policies[tg.Name] = pol
 	}
 
 	// If we have 0 policies, delete any stored policies for that job. This helps protect against
 	// situations where a jobs meta scaling policy has been removed, but the job is still running.
 	switch len(policies) {
 	case 0:
-		if err := pr.backend.DeleteJobPolicy(jobID); err != nil {
+		if err := pr.backend.DeleteJobPolicy(ctx, jobID); err != nil {
 			pr.logger.Error().
 				Str("job", jobID).
 				Err(err).
 				Msg("failed to delete job group policies from backend store")
 		}
 	default:
-		if err := pr.backend.PutJobPolicy(jobID, policies); err != nil {
+		if err := pr.backend.PutJobPolicy(ctx, jobID, policies); err != nil {
 			pr.logger.Error().
 				Str("job", jobID).
 				Err(err).
@@ -111,8 +159,64 @@ func (pr *Processor) policyFromMeta(meta map[string]string) *policy.GroupScaling
 		ScaleOutMemoryPercentageThreshold: pr.scaleOutMemoryThresholdValueOrNil(meta),
 		ScaleInCPUPercentageThreshold:     pr.scaleInCPUThresholdValueOrNil(meta),
 		ScaleInMemoryPercentageThreshold:  pr.scaleInMemoryThresholdValueOrNil(meta),
+		ScaleOutDiskPercentageThreshold:   pr.scaleOutDiskThresholdValueOrNil(meta),
+		ScaleInDiskPercentageThreshold:    pr.scaleInDiskThresholdValueOrNil(meta),
+		ScaleOutGPUPercentageThreshold:    pr.scaleOutGPUThresholdValueOrNil(meta),
+		ScaleInGPUPercentageThreshold:     pr.scaleInGPUThresholdValueOrNil(meta),
+		ResourceTasks:                     pr.resourceTasksFromMeta(meta),
+		CompositeCheck:                    pr.compositeCheckFromMeta(meta),
 		ExternalChecks:                    pr.externalChecksFromMeta(meta),
+		MetricsFallback:                   pr.metricsFallbackFromMeta(meta),
+		ScaleOutSpread:                    pr.scaleOutSpreadFromMeta(meta),
+		ScaleOrder:                        pr.intValueOrDefault(meta, metaKeyScaleOrder, 0),
+		WaitForHealthyTimeout:             pr.intValueOrDefault(meta, metaKeyWaitForHealthyTimeout, 0),
+		MinScaleDelta:                     pr.intValueOrDefault(meta, metaKeyMinScaleDelta, 0),
+		MinScaleDeltaPercentage:           pr.floatValueOrDefault(meta, metaKeyMinScaleDeltaPercentage, 0),
+		EvalPriority:                      pr.intValueOrDefault(meta, metaKeyEvalPriority, 0),
+		RunbookURL:                        meta[metaKeyRunbookURL],
+		Notes:                             meta[metaKeyNotes],
+	}
+}
+
+// mergeMeta returns the Sherpa meta keys of the job and group, allowing job level meta to provide
+// defaults for all groups of the job. Group level meta keys override job level keys of the same
+// name. Keys using the configured prefix are returned using the default sherpa_ prefix, so that
+// the policy parsing is independent of the prefix.
+func mergeMeta(prefix string, job, group map[string]string) map[string]string {
+	out := make(map[string]string, len(job)+len(group))
+
+	for _, meta := range []map[string]string{job, group} {
+		for k, v := range meta {
+			if strings.HasPrefix(k, prefix) {
+				out[metaKeyPrefix+strings.TrimPrefix(k, prefix)] = v
+			}
+		}
 	}
+	return out
+}
+
+func (pr *Processor) intValueOrDefault(meta map[string]string, key string, def int) int {
+	if val, ok := meta[key]; ok {
+		i, err := strconv.Atoi(val)
+		if err != nil {
+			pr.logger.Error().Err(err).Str("key", key).Msg("failed to convert meta value to int")
+			return def
+		}
+		return i
+	}
+	return def
+}
+
+func (pr *Processor) floatValueOrDefault(meta map[string]string, key string, def float64) float64 {
+	if val, ok := meta[key]; ok {
+		f, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			pr.logger.Error().Err(err).Str("key", key).Msg("failed to convert meta value to float64")
+			return def
+		}
+		return f
+	}
+	return def
 }
 
 func (pr *Processor) enabledValueOrDefault(meta map[string]string) bool {
@@ -235,14 +339,135 @@ func (pr *Processor) scaleInMemoryThresholdValueOrNil(meta map[string]string) *f
 	return nil
 }
 
+func (pr *Processor) scaleOutDiskThresholdValueOrNil(meta map[string]string) *float64 {
+	if val, ok := meta[metaKeyScaleOutDiskPercentageThreshold]; ok {
+		outThreshold, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			pr.logger.Error().Err(err).Msg("failed to convert scale out disk meta value to float64")
+			return nil
+		}
+		return &outThreshold
+	}
+	return nil
+}
+
+func (pr *Processor) scaleInDiskThresholdValueOrNil(meta map[string]string) *float64 {
+	if val, ok := meta[metaKeyScaleInDiskPercentageThreshold]; ok {
+		inThreshold, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			pr.logger.Error().Err(err).Msg("failed to convert scale in disk meta value to float64")
+			return nil
+		}
+		return &inThreshold
+	}
+	return nil
+}
+
+func (pr *Processor) scaleOutGPUThresholdValueOrNil(meta map[string]string) *float64 {
+	if val, ok := meta[metaKeyScaleOutGPUPercentageThreshold]; ok {
+		outThreshold, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			pr.logger.Error().Err(err).Msg("failed to convert scale out GPU meta value to float64")
+			return nil
+		}
+		return &outThreshold
+	}
+	return nil
+}
+
+func (pr *Processor) scaleInGPUThresholdValueOrNil(meta map[string]string) *float64 {
+	if val, ok := meta[metaKeyScaleInGPUPercentageThreshold]; ok {
+		inThreshold, err := strconv.ParseFloat(val, 64)
+		if err != nil {
+			pr.logger.Error().Err(err).Msg("failed to convert scale in GPU meta value to float64")
+			return nil
+		}
+		return &inThreshold
+	}
+	return nil
+}
+
+func (pr *Processor) resourceTasksFromMeta(meta map[string]string) []string {
+	val, ok := meta[metaKeyResourceTasks]
+	if !ok {
+		return nil
+	}
+
+	var tasks []string // nolint:prealloc
+
+	for _, task := range strings.Split(val, ",") {
+		if task = strings.TrimSpace(task); task != "" {
+			tasks = append(tasks, task)
+		}
+	}
+	return tasks
+}
+
+func (pr *Processor) compositeCheckFromMeta(meta map[string]string) *policy.CompositeCheck {
+	if val, ok := meta[metaKeyCompositeCheck]; ok {
+		var check policy.CompositeCheck
+		if err := json.Unmarshal([]byte(val), &check); err != nil {
+			pr.logger.Error().Err(err).Msg("failed to unmarshal composite check into struct")
+			return nil
+		}
+		return &check
+	}
+	return nil
+}
+
+// externalChecksFromMeta builds the external checks from the sherpa_external_checks key, which
+// holds all checks keyed by name, and the sherpa_external_check_<name> keys, which each hold a
+// single check. Individual check keys take precedence over a check of the same name.
 func (pr *Processor) externalChecksFromMeta(meta map[string]string) map[string]*policy.ExternalCheck {
+	var checks map[string]*policy.ExternalCheck
+
 	if val, ok := meta[metaKeyExternalChecks]; ok {
-		var checks map[string]*policy.ExternalCheck
 		if err := json.Unmarshal([]byte(val), &checks); err != nil {
 			pr.logger.Error().Err(err).Msg("failed to unmarshal external checks into struct")
+			checks = nil
+		}
+	}
+
+	for key, val := range meta {
+		name := strings.TrimPrefix(key, metaKeyPrefixExternalCheck)
+		if name == key || name == "" {
+			continue
+		}
+
+		var check policy.ExternalCheck
+		if err := json.Unmarshal([]byte(val), &check); err != nil {
+			pr.logger.Error().Err(err).Str("check", name).Msg("failed to unmarshal external check into struct")
+			continue
+		}
+
+		if checks == nil {
+			checks = make(map[string]*policy.ExternalCheck)
+		}
+		checks[name] = &check
+	}
+	return checks
+}
+
+func (pr *Processor) metricsFallbackFromMeta(meta map[string]string) *policy.MetricsFallback {
+	if val, ok := meta[metaKeyMetricsFallback]; ok {
+		var fallback policy.MetricsFallback
+		if err := json.Unmarshal([]byte(val), &fallback); err != nil {
+			pr.logger.Error().Err(err).Msg("failed to unmarshal metrics fallback into struct")
+			return nil
+		}
+		return &fallback
+	}
+	return nil
+}
+
+func (pr *Processor) scaleOutSpreadFromMeta(meta map[string]string) *policy.ScaleOutSpread {
+	if val, ok := meta[metaKeyScaleOutSpread]; ok {
+		var spread policy.ScaleOutSpread
+		if err := json.Unmarshal([]byte(val), &spread); err != nil {
+			pr.logger.Error().Err(err).Msg("failed to unmarshal scale out spread into struct")
 			return nil
 		}
-		return checks
+		return &spread
 	}
 	return nil
 }
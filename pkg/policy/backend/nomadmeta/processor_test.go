@@ -11,7 +11,7 @@ import (
 )
 
 func TestProcessor_policyFromMeta(t *testing.T) {
-	_, p := NewJobScalingPolicies(zerolog.Logger{}, nil)
+	_, p := NewJobScalingPolicies(zerolog.Logger{}, nil, "", nil)
 
 	testCases := []struct {
 		meta           map[string]string
@@ -29,6 +29,8 @@ func TestProcessor_policyFromMeta(t *testing.T) {
 				metaKeyScaleOutMemoryPercentageThreshold: "95",
 				metaKeyScaleInCPUPercentageThreshold:     "55",
 				metaKeyScaleInMemoryPercentageThreshold:  "55",
+				metaKeyScaleOutDiskPercentageThreshold:   "90",
+				metaKeyScaleInDiskPercentageThreshold:    "10",
 			},
 			expectedPolicy: &policy.GroupScalingPolicy{
 				Enabled:                           true,
@@ -41,6 +43,8 @@ func TestProcessor_policyFromMeta(t *testing.T) {
 				ScaleOutMemoryPercentageThreshold: helper.Float64ToPointer(95),
 				ScaleInCPUPercentageThreshold:     helper.Float64ToPointer(55),
 				ScaleInMemoryPercentageThreshold:  helper.Float64ToPointer(55),
+				ScaleOutDiskPercentageThreshold:   helper.Float64ToPointer(90),
+				ScaleInDiskPercentageThreshold:    helper.Float64ToPointer(10),
 			},
 		},
 		{
@@ -140,6 +144,99 @@ func TestProcessor_policyFromMeta(t *testing.T) {
 				},
 			},
 		},
+		{
+			meta: map[string]string{
+				metaKeyEnabled:        "true",
+				metaKeyResourceTasks:  "app, worker,",
+				metaKeyCompositeCheck: "{\"Weights\":{\"cpu\":0.7,\"memory\":0.3},\"ScaleOutPercentageThreshold\":80}",
+			},
+			expectedPolicy: &policy.GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      180,
+				MinCount:      2,
+				MaxCount:      10,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+				ResourceTasks: []string{"app", "worker"},
+				CompositeCheck: &policy.CompositeCheck{
+					Weights: map[policy.NomadResource]float64{
+						policy.NomadResourceCPU:    0.7,
+						policy.NomadResourceMemory: 0.3,
+					},
+					ScaleOutPercentageThreshold: helper.Float64ToPointer(80),
+				},
+			},
+		},
+		{
+			meta: map[string]string{
+				metaKeyEnabled:         "true",
+				metaKeyMetricsFallback: "{\"Action\":\"safe-count\",\"SafeCount\":4}",
+				metaKeyScaleOutSpread:  "{\"Targets\":{\"dc1\":50,\"dc2\":50}}",
+			},
+			expectedPolicy: &policy.GroupScalingPolicy{
+				Enabled:         true,
+				Cooldown:        180,
+				MinCount:        2,
+				MaxCount:        10,
+				ScaleOutCount:   1,
+				ScaleInCount:    1,
+				MetricsFallback: &policy.MetricsFallback{Action: policy.FallbackSafeCount, SafeCount: 4},
+				ScaleOutSpread:  &policy.ScaleOutSpread{Targets: map[string]int{"dc1": 50, "dc2": 50}},
+			},
+		},
+		{
+			meta: map[string]string{
+				metaKeyEnabled:    "true",
+				metaKeyRunbookURL: "https://wiki.jrasell.system/runbooks/cache",
+				metaKeyNotes:      "Contact the platform team before raising the max count.",
+			},
+			expectedPolicy: &policy.GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      180,
+				MinCount:      2,
+				MaxCount:      10,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+				RunbookURL:    "https://wiki.jrasell.system/runbooks/cache",
+				Notes:         "Contact the platform team before raising the max count.",
+			},
+		},
+		{
+			meta: map[string]string{
+				metaKeyEnabled:                                 "true",
+				metaKeyScaleOrder:                              "2",
+				metaKeyWaitForHealthyTimeout:                   "90",
+				metaKeyMinScaleDelta:                           "2",
+				metaKeyMinScaleDeltaPercentage:                 "7.5",
+				metaKeyEvalPriority:                            "80",
+				metaKeyExternalChecks:                          "{\"prometheus_test\":{\"Enabled\":false,\"Provider\":\"prometheus\"}}",
+				metaKeyPrefixExternalCheck + "prometheus_test": "{\"Enabled\":true,\"Provider\":\"prometheus\",\"Query\":\"job:nomad_redis_cache_memory:percentage\",\"ComparisonOperator\":\"less-than\",\"ComparisonValue\":30,\"Action\":\"scale-in\"}",
+				metaKeyPrefixExternalCheck + "invalid":         "untranslatable",
+			},
+			expectedPolicy: &policy.GroupScalingPolicy{
+				Enabled:                 true,
+				Cooldown:                180,
+				MinCount:                2,
+				MaxCount:                10,
+				ScaleOutCount:           1,
+				ScaleInCount:            1,
+				ScaleOrder:              2,
+				WaitForHealthyTimeout:   90,
+				MinScaleDelta:           2,
+				MinScaleDeltaPercentage: 7.5,
+				EvalPriority:            80,
+				ExternalChecks: map[string]*policy.ExternalCheck{
+					"prometheus_test": {
+						Enabled:            true,
+						Provider:           policy.ProviderPrometheus,
+						Query:              "job:nomad_redis_cache_memory:percentage",
+						ComparisonOperator: policy.ComparisonLessThan,
+						ComparisonValue:    30,
+						Action:             policy.ActionScaleIn,
+					},
+				},
+			},
+		},
 	}
 
 	for _, tc := range testCases {
@@ -147,3 +244,106 @@ func TestProcessor_policyFromMeta(t *testing.T) {
 		assert.Equal(t, tc.expectedPolicy, actualPolicy)
 	}
 }
+
+func Test_mergeMeta(t *testing.T) {
+	job := map[string]string{
+		metaKeyEnabled:  "true",
+		metaKeyMaxCount: "20",
+		"owner":         "platform",
+	}
+	group := map[string]string{
+		metaKeyMaxCount: "5",
+		metaKeyMinCount: "1",
+		"version":       "1.2.0",
+	}
+
+	expected := map[string]string{
+		metaKeyEnabled:  "true",
+		metaKeyMaxCount: "5",
+		metaKeyMinCount: "1",
+	}
+	assert.Equal(t, expected, mergeMeta(metaKeyPrefix, job, group))
+	assert.Equal(t, map[string]string{metaKeyMaxCount: "5", metaKeyMinCount: "1"}, mergeMeta(metaKeyPrefix, nil, group))
+	assert.Empty(t, mergeMeta(metaKeyPrefix, nil, nil))
+
+	// Keys using a custom prefix are normalised, and keys using the default prefix ignored.
+	custom := map[string]string{
+		"acme_autoscale_enabled":   "true",
+		"acme_autoscale_max_count": "8",
+		metaKeyMinCount:            "3",
+	}
+	assert.Equal(t, map[string]string{metaKeyEnabled: "true", metaKeyMaxCount: "8"},
+		mergeMeta("acme_autoscale_", nil, custom))
+}
+
+func TestProcessor_applyScalingStanza(t *testing.T) {
+	_, p := NewJobScalingPolicies(zerolog.Logger{}, nil, "", nil)
+
+	min := int64(3)
+
+	testCases := []struct {
+		meta           map[string]string
+		scaling        *scalingStanza
+		expectedPolicy *policy.GroupScalingPolicy
+		name           string
+	}{
+		{
+			meta: map[string]string{},
+			scaling: &scalingStanza{
+				Min:    &min,
+				Max:    12,
+				Policy: map[string]interface{}{scalingPolicyKeyCooldown: "2m"},
+			},
+			expectedPolicy: &policy.GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      120,
+				MinCount:      3,
+				MaxCount:      12,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+			},
+			name: "scaling stanza only",
+		},
+		{
+			meta: map[string]string{
+				metaKeyEnabled:  "true",
+				metaKeyMaxCount: "20",
+				metaKeyCooldown: "60",
+			},
+			scaling: &scalingStanza{
+				Enabled: helper.BoolToPointer(false),
+				Min:     &min,
+				Max:     12,
+				Policy:  map[string]interface{}{scalingPolicyKeyCooldown: "2m"},
+			},
+			expectedPolicy: &policy.GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      60,
+				MinCount:      3,
+				MaxCount:      20,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+			},
+			name: "meta keys take precedence",
+		},
+		{
+			meta:    map[string]string{},
+			scaling: &scalingStanza{Enabled: helper.BoolToPointer(false)},
+			expectedPolicy: &policy.GroupScalingPolicy{
+				Enabled:       false,
+				Cooldown:      180,
+				MinCount:      2,
+				MaxCount:      10,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+			},
+			name: "disabled scaling stanza",
+		},
+	}
+
+	for _, tc := range testCases {
+		pol := p.policyFromMeta(tc.meta)
+		p.applyScalingStanza(pol, tc.meta, tc.scaling)
+		assert.Equal(t, tc.expectedPolicy, pol, tc.name)
+	}
+}
@@ -0,0 +1,61 @@
+package nomadmeta
+
+import (
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+)
+
+// scalingPolicyKeyCooldown is the key within the scaling stanza policy block used to configure
+// the cooldown, in the form of a duration string such as "2m".
+const scalingPolicyKeyCooldown = "cooldown"
+
+// scalingJob is a minimal representation of a Nomad job which includes the task group scaling
+// stanza. The vendored Nomad API client predates the scaling stanza, so the job is read using the
+// raw API and decoded into this struct.
+type scalingJob struct {
+	Namespace  *string
+	Meta       map[string]string
+	TaskGroups []*scalingTaskGroup
+}
+
+type scalingTaskGroup struct {
+	Name    string
+	Meta    map[string]string
+	Scaling *scalingStanza
+}
+
+// scalingStanza represents the native Nomad task group scaling stanza.
+type scalingStanza struct {
+	Enabled *bool
+	Min     *int64
+	Max     int64
+	Policy  map[string]interface{}
+}
+
+// applyScalingStanza updates the policy using the values of the scaling stanza. Sherpa meta keys
+// take precedence, so the stanza is only used for parameters not configured within the meta.
+func (pr *Processor) applyScalingStanza(pol *policy.GroupScalingPolicy, meta map[string]string, scaling *scalingStanza) {
+	if _, ok := meta[metaKeyEnabled]; !ok {
+		pol.Enabled = scaling.Enabled == nil || *scaling.Enabled
+	}
+
+	if _, ok := meta[metaKeyMinCount]; !ok && scaling.Min != nil {
+		pol.MinCount = int(*scaling.Min)
+	}
+
+	if _, ok := meta[metaKeyMaxCount]; !ok && scaling.Max > 0 {
+		pol.MaxCount = int(scaling.Max)
+	}
+
+	if _, ok := meta[metaKeyCooldown]; !ok {
+		if cooldown, ok := scaling.Policy[scalingPolicyKeyCooldown].(string); ok {
+			d, err := time.ParseDuration(cooldown)
+			if err != nil {
+				pr.logger.Error().Err(err).Msg("failed to parse scaling stanza cooldown as duration")
+				return
+			}
+			pol.Cooldown = int(d.Seconds())
+		}
+	}
+}
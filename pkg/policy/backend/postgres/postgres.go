@@ -0,0 +1,225 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/jrasell/sherpa/pkg/encryption"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/policy/backend"
+	"github.com/jrasell/sherpa/pkg/postgres"
+	"github.com/pkg/errors"
+)
+
+var _ backend.PolicyBackend = (*PolicyBackend)(nil)
+
+// Define our metric keys.
+var (
+	metricKeyGetPolicies          = []string{"policy", "postgres", "get_policies"}
+	metricKeyGetJobPolicy         = []string{"policy", "postgres", "get_job_policy"}
+	metricKeyGetJobGroupPolicy    = []string{"policy", "postgres", "get_job_group_policy"}
+	metricKeyPutJobPolicy         = []string{"policy", "postgres", "put_job_policy"}
+	metricKeyPutJobGroupPolicy    = []string{"policy", "postgres", "put_job_group_policy"}
+	metricKeyDeleteJobPolicy      = []string{"policy", "postgres", "delete_job_policy"}
+	metricKeyDeleteJobGroupPolicy = []string{"policy", "postgres", "delete_job_group_policy"}
+)
+
+// PolicyBackend stores policies within the sherpa_policies table of a PostgreSQL database, which
+// must have been migrated using the postgres package. Each row holds the policy of a job group.
+type PolicyBackend struct {
+	db *sql.DB
+
+	// keyring encrypts the sensitive fields of policies before they are written to the database,
+	// and is nil if encryption at rest is disabled.
+	keyring *encryption.Keyring
+}
+
+// NewPostgresPolicyBackend creates a policy backend which stores policies within the database. If
+// the keyring is not nil, the sensitive fields of policies are encrypted before being written.
+func NewPostgresPolicyBackend(db *sql.DB, keyring *encryption.Keyring) *PolicyBackend {
+	return &PolicyBackend{
+		db:      db,
+		keyring: keyring,
+	}
+}
+
+// decodePolicy unmarshals the stored policy, decrypting any encrypted sensitive fields.
+func (p *PolicyBackend) decodePolicy(value string, out *policy.GroupScalingPolicy) (*policy.GroupScalingPolicy, error) {
+	if err := json.Unmarshal([]byte(value), out); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal PostgreSQL policy")
+	}
+
+	dec, err := out.TransformSensitive(p.keyring.Decrypt)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt PostgreSQL policy")
+	}
+	return dec, nil
+}
+
+// encodePolicy marshals the policy for storage, encrypting its sensitive fields if encryption at
+// rest is enabled.
+func (p *PolicyBackend) encodePolicy(pol *policy.GroupScalingPolicy) (string, error) {
+	if p.keyring != nil && pol != nil {
+		enc, err := pol.TransformSensitive(p.keyring.Encrypt)
+		if err != nil {
+			return "", errors.Wrap(err, "failed to encrypt policy")
+		}
+		pol = enc
+	}
+
+	marshal, err := json.Marshal(pol)
+	if err != nil {
+		return "", err
+	}
+	return string(marshal), nil
+}
+
+func (p *PolicyBackend) GetPolicies(ctx context.Context) (map[string]map[string]*policy.GroupScalingPolicy, error) {
+	defer metrics.MeasureSince(metricKeyGetPolicies, time.Now())
+
+	rows, err := p.db.QueryContext(ctx, `SELECT job_name, group_name, policy FROM sherpa_policies`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query policies")
+	}
+	defer rows.Close()
+
+	var out map[string]map[string]*policy.GroupScalingPolicy
+
+	for rows.Next() {
+		var job, group, value string
+		if err := rows.Scan(&job, &group, &value); err != nil {
+			return nil, errors.Wrap(err, "failed to scan policy")
+		}
+
+		pol, err := p.decodePolicy(value, &policy.GroupScalingPolicy{ExternalChecks: make(map[string]*policy.ExternalCheck)})
+		if err != nil {
+			return nil, err
+		}
+
+		if out == nil {
+			out = make(map[string]map[string]*policy.GroupScalingPolicy)
+		}
+		if _, ok := out[job]; !ok {
+			out[job] = make(map[string]*policy.GroupScalingPolicy)
+		}
+		out[job][group] = pol
+	}
+	return out, rows.Err()
+}
+
+func (p *PolicyBackend) GetJobPolicy(ctx context.Context, job string) (map[string]*policy.GroupScalingPolicy, error) {
+	defer metrics.MeasureSince(metricKeyGetJobPolicy, time.Now())
+
+	rows, err := p.db.QueryContext(ctx, `SELECT group_name, policy FROM sherpa_policies WHERE job_name = $1`, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query job policy")
+	}
+	defer rows.Close()
+
+	var out map[string]*policy.GroupScalingPolicy
+
+	for rows.Next() {
+		var group, value string
+		if err := rows.Scan(&group, &value); err != nil {
+			return nil, errors.Wrap(err, "failed to scan policy")
+		}
+
+		pol, err := p.decodePolicy(value, &policy.GroupScalingPolicy{ExternalChecks: make(map[string]*policy.ExternalCheck)})
+		if err != nil {
+			return nil, err
+		}
+
+		if out == nil {
+			out = make(map[string]*policy.GroupScalingPolicy)
+		}
+		out[group] = pol
+	}
+	return out, rows.Err()
+}
+
+func (p *PolicyBackend) GetJobGroupPolicy(ctx context.Context, job, group string) (*policy.GroupScalingPolicy, error) {
+	defer metrics.MeasureSince(metricKeyGetJobGroupPolicy, time.Now())
+
+	var value string
+
+	err := p.db.QueryRowContext(ctx, `SELECT policy FROM sherpa_policies WHERE job_name = $1 AND group_name = $2`,
+		job, group).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query job group policy")
+	}
+	return p.decodePolicy(value, &policy.GroupScalingPolicy{})
+}
+
+func (p *PolicyBackend) PutJobPolicy(ctx context.Context, job string, groupPolicies map[string]*policy.GroupScalingPolicy) error {
+	defer metrics.MeasureSince(metricKeyPutJobPolicy, time.Now())
+
+	values := make(map[string]string, len(groupPolicies))
+
+	for group, pol := range groupPolicies {
+		value, err := p.encodePolicy(pol)
+		if err != nil {
+			return err
+		}
+		values[group] = value
+	}
+
+	// The group policies are written in a single transaction, so the job policy is either
+	// written in full or not at all.
+	return postgres.WithTx(ctx, p.db, func(tx *sql.Tx) error {
+		for group, value := range values {
+			if err := upsertPolicy(ctx, tx, job, group, value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (p *PolicyBackend) PutJobGroupPolicy(ctx context.Context, job, group string, pol *policy.GroupScalingPolicy) error {
+	defer metrics.MeasureSince(metricKeyPutJobGroupPolicy, time.Now())
+
+	value, err := p.encodePolicy(pol)
+	if err != nil {
+		return err
+	}
+	return upsertPolicy(ctx, p.db, job, group, value)
+}
+
+// execer is satisfied by both the database and a transaction.
+type execer interface {
+	ExecContext(context.Context, string, ...interface{}) (sql.Result, error)
+}
+
+// upsertPolicy inserts or replaces the stored policy of the job group.
+func upsertPolicy(ctx context.Context, db execer, job, group, value string) error {
+	_, err := db.ExecContext(ctx, `INSERT INTO sherpa_policies (job_name, group_name, policy) VALUES ($1, $2, $3)
+		ON CONFLICT (job_name, group_name) DO UPDATE SET policy = EXCLUDED.policy`, job, group, value)
+	if err != nil {
+		return errors.Wrap(err, "failed to write job group policy")
+	}
+	return nil
+}
+
+func (p *PolicyBackend) DeleteJobPolicy(ctx context.Context, job string) error {
+	defer metrics.MeasureSince(metricKeyDeleteJobPolicy, time.Now())
+
+	if _, err := p.db.ExecContext(ctx, `DELETE FROM sherpa_policies WHERE job_name = $1`, job); err != nil {
+		return errors.Wrap(err, "failed to delete job policy")
+	}
+	return nil
+}
+
+func (p *PolicyBackend) DeleteJobGroupPolicy(ctx context.Context, job, group string) error {
+	defer metrics.MeasureSince(metricKeyDeleteJobGroupPolicy, time.Now())
+
+	if _, err := p.db.ExecContext(ctx, `DELETE FROM sherpa_policies WHERE job_name = $1 AND group_name = $2`, job, group); err != nil {
+		return errors.Wrap(err, "failed to delete job group policy")
+	}
+	return nil
+}
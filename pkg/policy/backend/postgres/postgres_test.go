@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/encryption"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/postgres/postgrestest"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicyBackend_encodeDecodePolicy(t *testing.T) {
+	kr, err := encryption.NewKeyring([]encryption.Key{{ID: "primary", Key: bytes.Repeat([]byte{1}, encryption.KeySize)}})
+	assert.Nil(t, err)
+
+	pol := &policy.GroupScalingPolicy{
+		Enabled:       true,
+		MaxCount:      10,
+		PreScaleHooks: []*policy.ScalingHook{{URL: "https://hooks.example.com?token=s3cr3t"}},
+	}
+
+	p := NewPostgresPolicyBackend(nil, kr)
+
+	stored, err := p.encodePolicy(pol)
+	assert.Nil(t, err)
+	assert.NotContains(t, stored, "s3cr3t")
+
+	decoded, err := p.decodePolicy(stored, &policy.GroupScalingPolicy{})
+	assert.Nil(t, err)
+	assert.Equal(t, pol, decoded)
+
+	_, err = NewPostgresPolicyBackend(nil, nil).decodePolicy(stored, &policy.GroupScalingPolicy{})
+	assert.NotNil(t, err)
+}
+
+// TestPolicyBackend runs against a PostgreSQL database, and is skipped unless the
+// SHERPA_TEST_POSTGRES_URL environment variable is set.
+func TestPolicyBackend(t *testing.T) {
+	postgrestest.SkipUnlessEnabled(t)
+
+	db, cleanup := postgrestest.NewDB(t)
+	defer cleanup()
+
+	p := NewPostgresPolicyBackend(db, nil)
+	ctx := context.Background()
+
+	all, err := p.GetPolicies(ctx)
+	assert.Nil(t, err)
+	assert.Nil(t, all)
+
+	job, err := p.GetJobPolicy(ctx, "example")
+	assert.Nil(t, err)
+	assert.Nil(t, job)
+
+	group, err := p.GetJobGroupPolicy(ctx, "example", "cache")
+	assert.Nil(t, err)
+	assert.Nil(t, group)
+
+	cache := &policy.GroupScalingPolicy{Enabled: true, MinCount: 2, MaxCount: 10}
+	web := &policy.GroupScalingPolicy{Enabled: true, MinCount: 1, MaxCount: 4}
+
+	assert.Nil(t, p.PutJobPolicy(ctx, "example", map[string]*policy.GroupScalingPolicy{"cache": cache, "web": web}))
+	assert.Nil(t, p.PutJobGroupPolicy(ctx, "other", "api", cache))
+
+	all, err = p.GetPolicies(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, all, 2)
+	assert.Len(t, all["example"], 2)
+
+	group, err = p.GetJobGroupPolicy(ctx, "example", "web")
+	assert.Nil(t, err)
+	assert.Equal(t, web, group)
+
+	// Writing a group policy replaces the stored policy.
+	web.MaxCount = 8
+	assert.Nil(t, p.PutJobGroupPolicy(ctx, "example", "web", web))
+
+	job, err = p.GetJobPolicy(ctx, "example")
+	assert.Nil(t, err)
+	assert.Equal(t, 8, job["web"].MaxCount)
+
+	assert.Nil(t, p.DeleteJobGroupPolicy(ctx, "example", "web"))
+	job, err = p.GetJobPolicy(ctx, "example")
+	assert.Nil(t, err)
+	assert.Len(t, job, 1)
+
+	assert.Nil(t, p.DeleteJobPolicy(ctx, "example"))
+	all, err = p.GetPolicies(ctx)
+	assert.Nil(t, err)
+	assert.Len(t, all, 1)
+}
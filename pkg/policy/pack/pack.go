@@ -0,0 +1,255 @@
+// Package pack implements policy packs, which bundle the scaling policies of a number of jobs
+// with variables, so that the same policies can be applied across environments.
+//
+// Variables are referenced within the job names, group names and policy values of a pack using
+// the ${name} syntax. A JSON string value consisting solely of a reference is replaced by the
+// typed variable value, allowing numeric and boolean fields to be set using variables.
+package pack
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+)
+
+// VariableType is the type of a pack variable value.
+type VariableType string
+
+const (
+	VariableTypeString VariableType = "string"
+	VariableTypeNumber VariableType = "number"
+	VariableTypeBool   VariableType = "bool"
+)
+
+// Pack is a bundle of job scaling policies, which are rendered using variables.
+type Pack struct {
+	Name        string
+	Description string
+
+	// Variables are the variables which can be referenced by the policies, keyed by name.
+	Variables map[string]*Variable
+
+	// Policies are the job scaling policies of the pack, keyed by the job name and then by the
+	// group name.
+	Policies map[string]map[string]json.RawMessage
+}
+
+// Variable is a pack variable. A variable without a default must be set when the pack is rendered.
+type Variable struct {
+	Description string
+	Type        VariableType
+	Default     interface{}
+}
+
+// referenceRegex matches a variable reference, capturing the variable name.
+var referenceRegex = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_-]*)\}`)
+
+// Parse parses and validates the pack definition.
+func Parse(b []byte) (*Pack, error) {
+	var p Pack
+
+	if err := json.Unmarshal(b, &p); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal policy pack")
+	}
+	if len(p.Policies) == 0 {
+		return nil, errors.New("policy pack contains no policies")
+	}
+
+	for name, v := range p.Variables {
+		if v == nil {
+			return nil, errors.Errorf("variable %q has no definition", name)
+		}
+		switch v.Type {
+		case "":
+			v.Type = VariableTypeString
+		case VariableTypeString, VariableTypeNumber, VariableTypeBool:
+		default:
+			return nil, errors.Errorf("variable %q has unsupported type %q", name, v.Type)
+		}
+	}
+	return &p, nil
+}
+
+// Render resolves the pack variables using the passed values and the variable defaults, returning
+// the validated job scaling policy document of each job. Values are passed in their string form,
+// and are converted to the declared variable type.
+func (p *Pack) Render(values map[string]string) (map[string]json.RawMessage, error) {
+	vars, err := p.resolveVariables(values)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]json.RawMessage)
+
+	for job, groups := range p.Policies {
+		jobName, err := interpolate(job, vars)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to render job name %q", job)
+		}
+
+		doc := make(map[string]interface{})
+
+		for group, raw := range groups {
+			groupName, err := interpolate(group, vars)
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to render group name %q", group)
+			}
+
+			var val interface{}
+			if err := json.Unmarshal(raw, &val); err != nil {
+				return nil, errors.Wrapf(err, "failed to unmarshal %s:%s policy", job, group)
+			}
+
+			if doc[groupName], err = substitute(val, vars); err != nil {
+				return nil, errors.Wrapf(err, "failed to render %s:%s policy", jobName, groupName)
+			}
+		}
+
+		if _, ok := out[jobName]; ok {
+			return nil, errors.Errorf("multiple policies render to job %q", jobName)
+		}
+
+		b, err := json.Marshal(doc)
+		if err != nil {
+			return nil, err
+		}
+		if err := validateJobDocument(b); err != nil {
+			return nil, errors.Wrapf(err, "invalid rendered %s policy", jobName)
+		}
+		out[jobName] = b
+	}
+	return out, nil
+}
+
+// resolveVariables returns the typed value of each variable, rejecting unknown and missing
+// variables.
+func (p *Pack) resolveVariables(values map[string]string) (map[string]interface{}, error) {
+	for name := range values {
+		if _, ok := p.Variables[name]; !ok {
+			return nil, errors.Errorf("variable %q is not defined by the pack", name)
+		}
+	}
+
+	names := make([]string, 0, len(p.Variables))
+	for name := range p.Variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make(map[string]interface{}, len(p.Variables))
+
+	for _, name := range names {
+		v := p.Variables[name]
+
+		raw, ok := values[name]
+		if !ok {
+			if v.Default == nil {
+				return nil, errors.Errorf("variable %q is required", name)
+			}
+			out[name] = v.Default
+			continue
+		}
+
+		val, err := v.convert(raw)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid value for variable %q", name)
+		}
+		out[name] = val
+	}
+	return out, nil
+}
+
+// convert parses the string form of a variable value into the variable type.
+func (v *Variable) convert(raw string) (interface{}, error) {
+	switch v.Type {
+	case VariableTypeNumber:
+		return strconv.ParseFloat(raw, 64)
+	case VariableTypeBool:
+		return strconv.ParseBool(raw)
+	default:
+		return raw, nil
+	}
+}
+
+// substitute replaces the variable references within the JSON value.
+func substitute(val interface{}, vars map[string]interface{}) (interface{}, error) {
+	switch v := val.(type) {
+	case string:
+		// A string consisting solely of a reference takes the typed variable value.
+		if m := referenceRegex.FindStringSubmatch(v); m != nil && m[0] == v {
+			out, ok := vars[m[1]]
+			if !ok {
+				return nil, errors.Errorf("undefined variable %q", m[1])
+			}
+			return out, nil
+		}
+		return interpolate(v, vars)
+
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k := range v {
+			key, err := interpolate(k, vars)
+			if err != nil {
+				return nil, err
+			}
+			if out[key], err = substitute(v[k], vars); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i := range v {
+			var err error
+			if out[i], err = substitute(v[i], vars); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+
+	default:
+		return v, nil
+	}
+}
+
+// interpolate replaces each variable reference within s with the string form of the variable.
+func interpolate(s string, vars map[string]interface{}) (string, error) {
+	var err error
+
+	out := referenceRegex.ReplaceAllStringFunc(s, func(ref string) string {
+		name := referenceRegex.FindStringSubmatch(ref)[1]
+		val, ok := vars[name]
+		if !ok {
+			err = errors.Errorf("undefined variable %q", name)
+			return ref
+		}
+		return fmt.Sprint(val)
+	})
+	return out, err
+}
+
+// validateJobDocument validates the rendered job policy in the same manner as the server when the
+// policy is written.
+func validateJobDocument(doc []byte) error {
+	if err := policy.ValidateJobDocument(doc); err != nil {
+		return err
+	}
+
+	var groups map[string]*policy.GroupScalingPolicy
+	if err := json.Unmarshal(doc, &groups); err != nil {
+		return err
+	}
+
+	for group, pol := range groups {
+		if err := pol.Validate(); err != nil {
+			return errors.Wrapf(err, "group %s", group)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package pack
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPack = `{
+  "Name": "web-service",
+  "Variables": {
+    "env": {"Description": "The deployment environment"},
+    "max_count": {"Type": "number", "Default": 10},
+    "enabled": {"Type": "bool", "Default": true}
+  },
+  "Policies": {
+    "web-${env}": {
+      "frontend": {
+        "Enabled": "${enabled}",
+        "MinCount": 2,
+        "MaxCount": "${max_count}",
+        "Labels": {"env": "${env}", "team": "web-${env}"}
+      }
+    }
+  }
+}`
+
+func TestParse(t *testing.T) {
+	p, err := Parse([]byte(testPack))
+	assert.Nil(t, err)
+	assert.Equal(t, "web-service", p.Name)
+	assert.Equal(t, VariableTypeString, p.Variables["env"].Type)
+
+	_, err = Parse([]byte(`{"Policies": {}}`))
+	assert.NotNil(t, err)
+
+	_, err = Parse([]byte(`{"Variables": {"x": {"Type": "list"}}, "Policies": {"a": {}}}`))
+	assert.NotNil(t, err)
+}
+
+func TestPack_Render(t *testing.T) {
+	p, err := Parse([]byte(testPack))
+	assert.Nil(t, err)
+
+	out, err := p.Render(map[string]string{"env": "prod", "max_count": "20"})
+	assert.Nil(t, err)
+	assert.Len(t, out, 1)
+
+	var doc map[string]map[string]interface{}
+	assert.Nil(t, json.Unmarshal(out["web-prod"], &doc))
+	assert.Equal(t, true, doc["frontend"]["Enabled"])
+	assert.Equal(t, float64(20), doc["frontend"]["MaxCount"])
+	assert.Equal(t, map[string]interface{}{"env": "prod", "team": "web-prod"}, doc["frontend"]["Labels"])
+
+	testCases := []struct {
+		name   string
+		values map[string]string
+	}{
+		{name: "missing required variable", values: map[string]string{}},
+		{name: "unknown variable", values: map[string]string{"env": "prod", "region": "eu"}},
+		{name: "invalid number", values: map[string]string{"env": "prod", "max_count": "ten"}},
+	}
+
+	for _, tc := range testCases {
+		_, err := p.Render(tc.values)
+		assert.NotNil(t, err, tc.name)
+	}
+}
+
+func TestPack_RenderInvalid(t *testing.T) {
+	testCases := []struct {
+		name string
+		pack string
+	}{
+		{
+			name: "undefined reference",
+			pack: `{"Policies": {"web": {"frontend": {"Enabled": true, "Notes": "owned by ${team}"}}}}`,
+		},
+		{
+			name: "policy fails validation",
+			pack: `{"Variables": {"count": {"Default": "two"}}, "Policies": {"web": {"frontend": {"MinCount": "${count}"}}}}`,
+		},
+	}
+
+	for _, tc := range testCases {
+		p, err := Parse([]byte(tc.pack))
+		assert.Nil(t, err, tc.name)
+
+		_, err = p.Render(nil)
+		assert.NotNil(t, err, tc.name)
+	}
+}
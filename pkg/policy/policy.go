@@ -1,6 +1,10 @@
 package policy
 
 import (
+	"net/url"
+	"strings"
+	"time"
+
 	"github.com/pkg/errors"
 )
 
@@ -49,10 +53,89 @@ type GroupScalingPolicy struct {
 	// indicating this check should not be performed.
 	ScaleInMemoryPercentageThreshold *float64 `json:"ScaleInMemoryPercentageThreshold,omitempty"`
 
+	// ScaleOutDiskPercentageThreshold is used to perform an upper bound check on the ephemeral disk
+	// consumption of a job group based on Nomad obtained metrics. This value can be nil indicating
+	// this check should not be performed.
+	ScaleOutDiskPercentageThreshold *float64 `json:"ScaleOutDiskPercentageThreshold,omitempty"`
+
+	// ScaleInDiskPercentageThreshold is used to perform a lower bound check on the ephemeral disk
+	// consumption of a job group based on Nomad obtained metrics. This value can be nil indicating
+	// this check should not be performed.
+	ScaleInDiskPercentageThreshold *float64 `json:"ScaleInDiskPercentageThreshold,omitempty"`
+
+	// ScaleOutGPUPercentageThreshold is used to perform an upper bound check on the average GPU
+	// utilisation of a job group based on Nomad device stats. This value can be nil indicating
+	// this check should not be performed.
+	ScaleOutGPUPercentageThreshold *float64 `json:"ScaleOutGPUPercentageThreshold,omitempty"`
+
+	// ScaleInGPUPercentageThreshold is used to perform a lower bound check on the average GPU
+	// utilisation of a job group based on Nomad device stats. This value can be nil indicating
+	// this check should not be performed.
+	ScaleInGPUPercentageThreshold *float64 `json:"ScaleInGPUPercentageThreshold,omitempty"`
+
+	// ResourceTasks optionally names the tasks within the group whose resource utilisation should
+	// be used when performing Nomad checks. When empty, the utilisation of all tasks within the
+	// group is used. This allows sidecar tasks to be excluded from scaling decisions.
+	ResourceTasks []string `json:"ResourceTasks,omitempty"`
+
+	// CompositeCheck is used to perform a weighted check across multiple Nomad resource metrics,
+	// producing a single blended utilisation score for the job group. This value can be nil
+	// indicating this check should not be performed.
+	CompositeCheck *CompositeCheck `json:"CompositeCheck,omitempty"`
+
 	// ExternalChecks represents metrics which are gathered from external sources for analysis
 	// during scaling evaluations. They are keyed by a user specified name which is a free form
 	// string and does not have any requirements which impact the running on the check itself.
 	ExternalChecks map[string]*ExternalCheck `json:"ExternalChecks,omitempty"`
+
+	// MetricsFallback configures the behaviour of the autoscaler when none of the metric sources
+	// configured for the group can be read. This value can be nil indicating the group should not
+	// be scaled until metrics are available again.
+	MetricsFallback *MetricsFallback `json:"MetricsFallback,omitempty"`
+
+	// ScaleOutSpread configures the Nomad spread stanza applied to the job group when it is
+	// scaled out, so that new allocations are not concentrated within a single datacenter. This
+	// value can be nil indicating the spread of the job group should not be changed.
+	ScaleOutSpread *ScaleOutSpread `json:"ScaleOutSpread,omitempty"`
+
+	// PreScaleHooks are run before a scaling action of the job group is submitted to Nomad, and
+	// PostScaleHooks once the scaling action has completed or failed. This allows integrations
+	// such as cache warmers or CDN purges to form part of the scaling lifecycle.
+	PreScaleHooks  []*ScalingHook `json:"PreScaleHooks,omitempty"`
+	PostScaleHooks []*ScalingHook `json:"PostScaleHooks,omitempty"`
+
+	// ScaleOrder controls the order in which job groups are scaled when more than one group of
+	// the job requires scaling within the same evaluation. Groups are scaled in ascending order,
+	// with groups sharing an order being scaled together. This allows upstream groups, such as
+	// database proxies, to be scaled before the groups which depend on them.
+	ScaleOrder int `json:"ScaleOrder,omitempty"`
+
+	// WaitForHealthyTimeout is a time period in seconds which the autoscaler will wait for the
+	// group to reach its new count with all allocations running, before scaling groups with a
+	// higher ScaleOrder. If the timeout is reached, the remaining groups are not scaled. A value
+	// of 0 means the autoscaler does not wait between ordered scaling steps.
+	WaitForHealthyTimeout int `json:"WaitForHealthyTimeout,omitempty"`
+
+	// MinScaleDelta and MinScaleDeltaPercentage are the smallest change in count the autoscaler
+	// will act upon, as an absolute number of allocations and as a percentage of the current
+	// count. Autoscaling decisions which would change the count by less than either are skipped,
+	// reducing churn from small adjustments. A value of 0 disables the respective check.
+	MinScaleDelta           int     `json:"MinScaleDelta,omitempty"`
+	MinScaleDeltaPercentage float64 `json:"MinScaleDeltaPercentage,omitempty"`
+
+	// EvalPriority is the priority, between 1 and 100, of the Nomad evaluation created by scaling
+	// actions of the job group. This allows the scaling of critical job groups to be scheduled
+	// ahead of lower priority evaluations. A value of 0 uses the priority of the job.
+	EvalPriority int `json:"EvalPriority,omitempty"`
+
+	// RunbookURL and Notes document the job group for the engineers responding to its scaling
+	// activity. They are included within scaling events, notifications and the status output.
+	RunbookURL string `json:"RunbookURL,omitempty"`
+	Notes      string `json:"Notes,omitempty"`
+
+	// Labels are arbitrary key/value pairs used to organise policies, such as by environment or
+	// owning team. They allow policies to be selected for bulk operations.
+	Labels map[string]string `json:"Labels,omitempty"`
 }
 
 // ExternalCheck is an individual check of a metric from an external source. The check contains all
@@ -64,11 +147,13 @@ type ExternalCheck struct {
 	// actively run or not.
 	Enabled bool `json:"Enabled"`
 
-	// Provider is the external provider source for the query to run against.
-	Provider MetricsProvider `json:"Provider"`
+	// Provider is the external provider source for the query to run against. If empty, the
+	// DefaultMetricsProvider is used.
+	Provider MetricsProvider `json:"Provider,omitempty"`
 
 	// Query is the string representation of the query that will be run against the external
-	// provider to obtain a single int value.
+	// provider to obtain a single int value. The query can reference the {{.Job}}, {{.Group}}
+	// and {{.Namespace}} template variables, which are replaced before the query is run.
 	Query string `json:"Query"`
 
 	// ComparisonOperator
@@ -81,6 +166,234 @@ type ExternalCheck struct {
 	// Action is the scaling action that should be taken if the queried metric fails the comparison
 	// check.
 	Action ComparisonAction `json:"Action"`
+
+	// PerAllocation indicates the metric value should be divided by the current count of the job
+	// group before comparison. This allows the ComparisonValue to describe a ratio, such as the
+	// number of queued messages each worker should handle.
+	PerAllocation bool `json:"PerAllocation,omitempty"`
+
+	// Endpoint is the name of a configured Prometheus-compatible endpoint, such as Thanos or
+	// VictoriaMetrics, to run the query against. If empty, the default Prometheus endpoint is used.
+	Endpoint string `json:"Endpoint,omitempty"`
+}
+
+// CompositeCheck describes a weighted utilisation score built from a number of Nomad resource
+// metrics. The score is calculated as the weighted sum of each resource percentage, divided by the
+// total of all weights, meaning weights of 0.7 and 0.3 produce the same result as 7 and 3.
+type CompositeCheck struct {
+
+	// Weights maps the Nomad resource name to the weight it carries within the composite score.
+	Weights map[NomadResource]float64 `json:"Weights"`
+
+	// ScaleOutPercentageThreshold is the composite score which if broken will result in the job
+	// group being scaled out. This value can be nil indicating this check should not be performed.
+	ScaleOutPercentageThreshold *float64 `json:"ScaleOutPercentageThreshold,omitempty"`
+
+	// ScaleInPercentageThreshold is the composite score which if broken will result in the job
+	// group being scaled in. This value can be nil indicating this check should not be performed.
+	ScaleInPercentageThreshold *float64 `json:"ScaleInPercentageThreshold,omitempty"`
+}
+
+// Validate performs a number of checks on the CompositeCheck to ensure it is valid for use.
+func (cc *CompositeCheck) Validate() error {
+	if len(cc.Weights) < 1 {
+		return errors.New("composite check requires at least one resource weight")
+	}
+
+	var total float64
+
+	for resource, weight := range cc.Weights {
+		if err := resource.Validate(); err != nil {
+			return err
+		}
+		if weight < 0 {
+			return errors.Errorf("composite check weight for %s must not be negative", resource.String())
+		}
+		total += weight
+	}
+
+	// A zero total weight would always score zero, and so always break the scale in threshold.
+	if total <= 0 {
+		return errors.New("composite check requires at least one positive resource weight")
+	}
+
+	if cc.ScaleOutPercentageThreshold == nil && cc.ScaleInPercentageThreshold == nil {
+		return errors.New("composite check requires at least one threshold")
+	}
+
+	if cc.ScaleOutPercentageThreshold != nil && cc.ScaleInPercentageThreshold != nil &&
+		*cc.ScaleInPercentageThreshold >= *cc.ScaleOutPercentageThreshold {
+		return errors.New("composite check scale in threshold must be less than scale out threshold")
+	}
+	return nil
+}
+
+// Score calculates the composite utilisation score using the passed resource utilisation
+// percentages. Resources which are weighted but not found within the usage are treated as zero.
+func (cc *CompositeCheck) Score(usage map[NomadResource]float64) float64 {
+	var total, weights float64
+
+	for resource, weight := range cc.Weights {
+		total += usage[resource] * weight
+		weights += weight
+	}
+
+	if weights == 0 {
+		return 0
+	}
+	return total / weights
+}
+
+// MetricsFallback describes the action taken for a job group when all of its metric sources fail
+// during an evaluation.
+type MetricsFallback struct {
+
+	// Action is the fallback behaviour to use when metrics are unavailable.
+	Action FallbackAction `json:"Action"`
+
+	// SafeCount is the count the job group is scaled to when using the safe-count action.
+	SafeCount int `json:"SafeCount,omitempty"`
+
+	// ScaleOutPercentageThreshold is the CPU and memory utilisation which if broken will result in
+	// the job group being scaled out when using the nomad-checks action. If nil, the default of
+	// DefaultFallbackScaleOutPercentageThreshold is used.
+	ScaleOutPercentageThreshold *float64 `json:"ScaleOutPercentageThreshold,omitempty"`
+
+	// ScaleInPercentageThreshold is the CPU and memory utilisation which if broken will result in
+	// the job group being scaled in when using the nomad-checks action. If nil, the default of
+	// DefaultFallbackScaleInPercentageThreshold is used.
+	ScaleInPercentageThreshold *float64 `json:"ScaleInPercentageThreshold,omitempty"`
+}
+
+// Validate performs a number of checks on the MetricsFallback to ensure it is valid for use.
+func (mf *MetricsFallback) Validate() error {
+	if err := mf.Action.Validate(); err != nil {
+		return err
+	}
+
+	if mf.Action == FallbackSafeCount && mf.SafeCount < 0 {
+		return errors.New("metrics fallback safe count must not be negative")
+	}
+
+	if out, in := mf.NomadThresholds(); in >= out {
+		return errors.New("metrics fallback scale in threshold must be less than scale out threshold")
+	}
+	return nil
+}
+
+// NomadThresholds returns the scale out and scale in utilisation thresholds used by the
+// nomad-checks action, applying the defaults where they have not been set.
+func (mf *MetricsFallback) NomadThresholds() (float64, float64) {
+	out, in := float64(DefaultFallbackScaleOutPercentageThreshold), float64(DefaultFallbackScaleInPercentageThreshold)
+
+	if mf.ScaleOutPercentageThreshold != nil {
+		out = *mf.ScaleOutPercentageThreshold
+	}
+	if mf.ScaleInPercentageThreshold != nil {
+		in = *mf.ScaleInPercentageThreshold
+	}
+	return out, in
+}
+
+// ScaleOutSpread describes the Nomad spread stanza applied to a job group when it is scaled out.
+type ScaleOutSpread struct {
+
+	// Attribute is the node attribute allocations are spread across. If empty, the default of
+	// DefaultSpreadAttribute is used.
+	Attribute string `json:"Attribute,omitempty"`
+
+	// Weight is the preference, between 1 and 100, given to the spread relative to the other
+	// placement scoring factors. If zero, the default of DefaultSpreadWeight is used.
+	Weight int `json:"Weight,omitempty"`
+
+	// Targets are the percentage of allocations to place on nodes with each attribute value. If
+	// empty, allocations are spread evenly across all values of the attribute.
+	Targets map[string]int `json:"Targets,omitempty"`
+}
+
+// Validate performs a number of checks on the ScaleOutSpread to ensure it is valid for use.
+func (sos *ScaleOutSpread) Validate() error {
+	if sos.Weight < 0 || sos.Weight > 100 {
+		return errors.New("scale out spread weight must be between 1 and 100")
+	}
+
+	var total int
+
+	for value, percent := range sos.Targets {
+		if value == "" {
+			return errors.New("scale out spread target values must not be empty")
+		}
+		if percent < 1 || percent > 100 {
+			return errors.New("scale out spread target percentages must be between 1 and 100")
+		}
+		total += percent
+	}
+
+	if total > 100 {
+		return errors.New("scale out spread target percentages must not total more than 100")
+	}
+	return nil
+}
+
+// GetAttribute returns the spread attribute, applying the default where it has not been set.
+func (sos *ScaleOutSpread) GetAttribute() string {
+	if sos.Attribute == "" {
+		return DefaultSpreadAttribute
+	}
+	return sos.Attribute
+}
+
+// GetWeight returns the spread weight, applying the default where it has not been set.
+func (sos *ScaleOutSpread) GetWeight() int {
+	if sos.Weight == 0 {
+		return DefaultSpreadWeight
+	}
+	return sos.Weight
+}
+
+// ScalingHook is an action run during the scaling lifecycle of a job group. The hook is either an
+// HTTP call, which POSTs the scaling event JSON to the URL, or the execution of a local command,
+// which receives the scaling event JSON on stdin.
+type ScalingHook struct {
+
+	// URL is the address the scaling event is POSTed to.
+	URL string `json:"URL,omitempty"`
+
+	// Command is the path of the local command to execute, and Args the arguments passed to it.
+	Command string   `json:"Command,omitempty"`
+	Args    []string `json:"Args,omitempty"`
+
+	// Timeout is the time in seconds the hook has to complete. If zero, the default of
+	// DefaultScalingHookTimeout is used.
+	Timeout int `json:"Timeout,omitempty"`
+}
+
+// DefaultScalingHookTimeout is the time in seconds a scaling hook has to complete when a timeout
+// is not configured.
+const DefaultScalingHookTimeout = 30
+
+// Validate performs a number of checks on the ScalingHook to ensure it is valid for use.
+func (sh *ScalingHook) Validate() error {
+	if (sh.URL == "") == (sh.Command == "") {
+		return errors.New("scaling hook requires exactly one of URL or Command")
+	}
+
+	if sh.URL != "" && len(sh.Args) > 0 {
+		return errors.New("scaling hook args are only supported with a command")
+	}
+
+	if sh.Timeout < 0 {
+		return errors.New("scaling hook timeout must not be negative")
+	}
+	return nil
+}
+
+// GetTimeout returns the hook timeout, applying the default where it has not been set.
+func (sh *ScalingHook) GetTimeout() time.Duration {
+	if sh.Timeout == 0 {
+		return DefaultScalingHookTimeout * time.Second
+	}
+	return time.Duration(sh.Timeout) * time.Second
 }
 
 // Validate performs a number of checks on the GroupScalingPolicy to ensure it is valid for use.
@@ -94,10 +407,71 @@ func (gsp GroupScalingPolicy) Validate() error {
 		return errors.New("please specify non-default scaling policy")
 	}
 
+	for i := range gsp.ResourceTasks {
+		if gsp.ResourceTasks[i] == "" {
+			return errors.New("resource task names must not be empty")
+		}
+	}
+
+	if gsp.CompositeCheck != nil {
+		if err := gsp.CompositeCheck.Validate(); err != nil {
+			return errors.Wrap(err, "failed to validate composite check")
+		}
+	}
+
+	if gsp.MetricsFallback != nil {
+		if err := gsp.MetricsFallback.Validate(); err != nil {
+			return errors.Wrap(err, "failed to validate metrics fallback")
+		}
+	}
+
+	if gsp.ScaleOutSpread != nil {
+		if err := gsp.ScaleOutSpread.Validate(); err != nil {
+			return errors.Wrap(err, "failed to validate scale out spread")
+		}
+	}
+
+	if gsp.ScaleOrder < 0 {
+		return errors.New("scale order must not be negative")
+	}
+
+	if gsp.WaitForHealthyTimeout < 0 {
+		return errors.New("wait for healthy timeout must not be negative")
+	}
+
+	if gsp.MinScaleDelta < 0 || gsp.MinScaleDeltaPercentage < 0 {
+		return errors.New("minimum scale delta must not be negative")
+	}
+
+	if gsp.EvalPriority < 0 || gsp.EvalPriority > 100 {
+		return errors.New("evaluation priority must be between 1 and 100")
+	}
+
+	if gsp.RunbookURL != "" {
+		u, err := url.Parse(gsp.RunbookURL)
+		if err != nil {
+			return errors.Wrap(err, "failed to parse runbook URL")
+		}
+		if (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("runbook URL must be an absolute http or https URL")
+		}
+	}
+
+	for _, hooks := range [][]*ScalingHook{gsp.PreScaleHooks, gsp.PostScaleHooks} {
+		for _, hook := range hooks {
+			if hook == nil {
+				return errors.New("scaling hooks must not be null")
+			}
+			if err := hook.Validate(); err != nil {
+				return errors.Wrap(err, "failed to validate scaling hook")
+			}
+		}
+	}
+
 	// Iterate over the external checks and validate the required components. The first error is
 	// returned, rather than collecting.
 	for name, check := range gsp.ExternalChecks {
-		if err := check.Provider.Validate(); err != nil {
+		if err := check.GetProvider().Validate(); err != nil {
 			return errors.Wrap(err, "failed to validate check"+name)
 		}
 
@@ -108,23 +482,95 @@ func (gsp GroupScalingPolicy) Validate() error {
 		if err := check.Action.Validate(); err != nil {
 			return errors.Wrap(err, "failed to validate check"+name)
 		}
+
+		if check.Endpoint != "" && check.Provider != ProviderPrometheus {
+			return errors.Errorf("check %s endpoint is only supported by the %s provider", name, ProviderPrometheus)
+		}
+
+		// Rendering the query using empty variables catches both syntax errors and references
+		// to unknown variables.
+		if _, err := check.RenderQuery(QueryVars{}); err != nil {
+			return errors.Wrap(err, "failed to validate check "+name)
+		}
 	}
 
 	return nil
 }
 
+// MatchLabels identifies whether the policy has all the passed labels set to the same values. A
+// policy always matches an empty set of labels.
+func (gsp GroupScalingPolicy) MatchLabels(labels map[string]string) bool {
+	for k, v := range labels {
+		if val, ok := gsp.Labels[k]; !ok || val != v {
+			return false
+		}
+	}
+	return true
+}
+
+// NomadResourcesRequired helps determine whether the resource usage of the group allocations must
+// be gathered from Nomad, either for the Nomad resource checks or for external checks which use
+// the Nomad resources provider.
+func (gsp GroupScalingPolicy) NomadResourcesRequired() bool {
+	if gsp.NomadChecksEnabled() {
+		return true
+	}
+	for _, check := range gsp.ExternalChecks {
+		if check != nil && check.Enabled && check.GetProvider() == ProviderNomadResources {
+			return true
+		}
+	}
+	return false
+}
+
 // NomadChecksEnabled helps determine whether the group policy ins configured to run scaling checks
 // based on Nomad resource metrics.
 func (gsp GroupScalingPolicy) NomadChecksEnabled() bool {
-	if gsp.ScaleInMemoryPercentageThreshold == nil && gsp.ScaleOutMemoryPercentageThreshold == nil &&
-		gsp.ScaleInCPUPercentageThreshold == nil && gsp.ScaleOutCPUPercentageThreshold == nil {
-		return false
+	if gsp.CompositeCheck != nil {
+		return true
+	}
+
+	for _, threshold := range []*float64{
+		gsp.ScaleInMemoryPercentageThreshold, gsp.ScaleOutMemoryPercentageThreshold,
+		gsp.ScaleInCPUPercentageThreshold, gsp.ScaleOutCPUPercentageThreshold,
+		gsp.ScaleInDiskPercentageThreshold, gsp.ScaleOutDiskPercentageThreshold,
+		gsp.ScaleInGPUPercentageThreshold, gsp.ScaleOutGPUPercentageThreshold,
+	} {
+		if threshold != nil && *threshold != 0 {
+			return true
+		}
 	}
-	if *gsp.ScaleInMemoryPercentageThreshold == 0 && *gsp.ScaleOutMemoryPercentageThreshold == 0 &&
-		*gsp.ScaleInCPUPercentageThreshold == 0 && *gsp.ScaleOutCPUPercentageThreshold == 0 {
-		return false
+	return false
+}
+
+// ExternalChecksEnabled helps determine whether the group policy has at least one enabled external
+// check.
+func (gsp GroupScalingPolicy) ExternalChecksEnabled() bool {
+	for _, check := range gsp.ExternalChecks {
+		if check != nil && check.Enabled {
+			return true
+		}
 	}
-	return true
+	return false
+}
+
+// NomadDiskChecksEnabled helps determine whether the group policy requires the ephemeral disk
+// usage of allocations to be gathered. Disk usage is comparatively expensive to collect, so is
+// only performed when needed.
+func (gsp GroupScalingPolicy) NomadDiskChecksEnabled() bool {
+	if gsp.ScaleOutDiskPercentageThreshold != nil || gsp.ScaleInDiskPercentageThreshold != nil {
+		return true
+	}
+	if gsp.CompositeCheck != nil && gsp.CompositeCheck.Weights[NomadResourceDisk] > 0 {
+		return true
+	}
+	for _, check := range gsp.ExternalChecks {
+		if check != nil && check.Enabled && check.GetProvider() == ProviderNomadResources &&
+			strings.HasSuffix(check.Query, "/"+NomadResourceDisk.String()) {
+			return true
+		}
+	}
+	return false
 }
 
 // MergeWithDefaults iterates the GroupScalingPolicy core parameters, merging this with default
@@ -159,7 +605,7 @@ func (mp MetricsProvider) String() string { return string(mp) }
 // Validate checks the MetricsProvider is a valid and that it can be handled within the autoscaler.
 func (mp MetricsProvider) Validate() error {
 	switch mp {
-	case ProviderPrometheus:
+	case ProviderPrometheus, ProviderEnvoy, ProviderTraefik, ProviderNGINX, ProviderHAProxy, ProviderRabbitMQ, ProviderNATS, ProviderInfluxDB, ProviderGraphite, ProviderElasticsearch, ProviderNewRelic, ProviderDatadog, ProviderNomad, ProviderNomadResources:
 		return nil
 	default:
 		return errors.Errorf("Provider %s is not a valid option", mp.String())
@@ -169,6 +615,110 @@ func (mp MetricsProvider) Validate() error {
 const (
 	// ProviderPrometheus is the Prometheus metrics backend.
 	ProviderPrometheus MetricsProvider = "prometheus"
+
+	// ProviderEnvoy is the Consul Connect Envoy sidecar proxy metrics backend.
+	ProviderEnvoy MetricsProvider = "envoy"
+
+	// ProviderTraefik is the Traefik ingress metrics backend.
+	ProviderTraefik MetricsProvider = "traefik"
+
+	// ProviderNGINX is the NGINX ingress metrics backend.
+	ProviderNGINX MetricsProvider = "nginx"
+
+	// ProviderHAProxy is the HAProxy runtime API and stats page metrics backend.
+	ProviderHAProxy MetricsProvider = "haproxy"
+
+	// ProviderRabbitMQ is the RabbitMQ management API queue metrics backend.
+	ProviderRabbitMQ MetricsProvider = "rabbitmq"
+
+	// ProviderNATS is the NATS JetStream consumer metrics backend.
+	ProviderNATS MetricsProvider = "nats"
+
+	// ProviderInfluxDB is the InfluxDB v2 Flux query metrics backend.
+	ProviderInfluxDB MetricsProvider = "influxdb"
+
+	// ProviderGraphite is the Graphite render API metrics backend.
+	ProviderGraphite MetricsProvider = "graphite"
+
+	// ProviderElasticsearch is the Elasticsearch search metrics backend.
+	ProviderElasticsearch MetricsProvider = "elasticsearch"
+
+	// ProviderNewRelic is the New Relic NRQL metrics backend.
+	ProviderNewRelic MetricsProvider = "newrelic"
+
+	// ProviderDatadog is the Datadog timeseries query metrics backend.
+	ProviderDatadog MetricsProvider = "datadog"
+
+	// ProviderNomad is the Nomad job placement metrics backend, providing the number of queued
+	// allocations and blocked evaluations of a job.
+	ProviderNomad MetricsProvider = "nomad"
+
+	// ProviderNomadResources is the Nomad allocation resource usage backend, providing the CPU,
+	// memory, disk and GPU utilisation percentage of the job group under evaluation. This is the
+	// provider of the Nomad resource checks.
+	ProviderNomadResources MetricsProvider = "nomad-resources"
+
+	// DefaultMetricsProvider is the provider used by external checks which do not set one.
+	DefaultMetricsProvider = ProviderNomadResources
+)
+
+// NomadResource represents a resource metric gathered from Nomad which can be used within composite
+// checks.
+type NomadResource string
+
+// String returns the string form of the NomadResource.
+func (nr NomadResource) String() string { return string(nr) }
+
+// Validate checks the NomadResource is a valid and that it can be handled within the autoscaler.
+func (nr NomadResource) Validate() error {
+	switch nr {
+	case NomadResourceCPU, NomadResourceMemory, NomadResourceDisk, NomadResourceGPU:
+		return nil
+	default:
+		return errors.Errorf("NomadResource %s is not a valid option", nr.String())
+	}
+}
+
+const (
+	// NomadResourceCPU is the CPU utilisation percentage of the job group.
+	NomadResourceCPU NomadResource = "cpu"
+
+	// NomadResourceMemory is the memory utilisation percentage of the job group.
+	NomadResourceMemory NomadResource = "memory"
+
+	// NomadResourceDisk is the ephemeral disk utilisation percentage of the job group.
+	NomadResourceDisk NomadResource = "disk"
+
+	// NomadResourceGPU is the average GPU utilisation percentage of the job group.
+	NomadResourceGPU NomadResource = "gpu"
+)
+
+// FallbackAction is the behaviour of the autoscaler when the metrics for a job group are
+// unavailable.
+type FallbackAction string
+
+// String returns the string form of the FallbackAction.
+func (fa FallbackAction) String() string { return string(fa) }
+
+// Validate checks the FallbackAction is a valid and that it can be handled within the autoscaler.
+func (fa FallbackAction) Validate() error {
+	switch fa {
+	case FallbackHold, FallbackSafeCount, FallbackNomadChecks:
+		return nil
+	default:
+		return errors.Errorf("FallbackAction %s is not a valid option", fa.String())
+	}
+}
+
+const (
+	// FallbackHold keeps the job group at its current count.
+	FallbackHold FallbackAction = "hold"
+
+	// FallbackSafeCount scales the job group to the configured safe count.
+	FallbackSafeCount FallbackAction = "safe-count"
+
+	// FallbackNomadChecks performs CPU and memory checks using Nomad resource metrics.
+	FallbackNomadChecks FallbackAction = "nomad-checks"
 )
 
 // ComparisonOperator is the operator used when evaluating a metric value against a threshold.
@@ -225,3 +775,13 @@ const (
 	DefaultScaleOutCount = 1
 	DefaultScaleInCount  = 1
 )
+
+const (
+	DefaultFallbackScaleOutPercentageThreshold = 80
+	DefaultFallbackScaleInPercentageThreshold  = 20
+)
+
+const (
+	DefaultSpreadAttribute = "${node.datacenter}"
+	DefaultSpreadWeight    = 50
+)
@@ -51,6 +51,226 @@ func TestGroupScalingPolicy_Validate(t *testing.T) {
 			expectedOutput: nil,
 			name:           "valid core params with external check",
 		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      100,
+				MinCount:      10,
+				MaxCount:      1000,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+				CompositeCheck: &CompositeCheck{
+					Weights:                     map[NomadResource]float64{NomadResourceCPU: 0.7, "network": 0.3},
+					ScaleOutPercentageThreshold: helper.Float64ToPointer(80),
+				},
+			},
+			expectedOutput: errors.New("failed to validate composite check: NomadResource network is not a valid option"),
+			name:           "composite check with invalid resource",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      100,
+				MinCount:      10,
+				MaxCount:      1000,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+				CompositeCheck: &CompositeCheck{
+					Weights:                    map[NomadResource]float64{NomadResourceCPU: 0, NomadResourceMemory: 0},
+					ScaleInPercentageThreshold: helper.Float64ToPointer(20),
+				},
+			},
+			expectedOutput: errors.New("failed to validate composite check: composite check requires at least one positive resource weight"),
+			name:           "composite check with zero weights",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      100,
+				MinCount:      10,
+				MaxCount:      1000,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+				ExternalChecks: map[string]*ExternalCheck{"test_endpoint_check": {
+					Enabled:            true,
+					Provider:           ProviderPrometheus,
+					Endpoint:           "thanos",
+					Query:              "sum(rate(http_requests_total[5m]))",
+					ComparisonOperator: ComparisonGreaterThan,
+					ComparisonValue:    42,
+					Action:             ActionScaleOut,
+				}},
+			},
+			expectedOutput: nil,
+			name:           "valid external check with named endpoint",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      100,
+				MinCount:      10,
+				MaxCount:      1000,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+				ExternalChecks: map[string]*ExternalCheck{"test_endpoint_check": {
+					Enabled:            true,
+					Provider:           ProviderGraphite,
+					Endpoint:           "thanos",
+					Query:              "app.requests",
+					ComparisonOperator: ComparisonGreaterThan,
+					ComparisonValue:    42,
+					Action:             ActionScaleOut,
+				}},
+			},
+			expectedOutput: errors.New("check test_endpoint_check endpoint is only supported by the prometheus provider"),
+			name:           "named endpoint with non Prometheus provider",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:         true,
+				Cooldown:        100,
+				MinCount:        10,
+				MaxCount:        1000,
+				ScaleOutCount:   1,
+				ScaleInCount:    1,
+				MetricsFallback: &MetricsFallback{Action: FallbackSafeCount, SafeCount: 20},
+			},
+			expectedOutput: nil,
+			name:           "valid metrics fallback",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:         true,
+				Cooldown:        100,
+				MinCount:        10,
+				MaxCount:        1000,
+				ScaleOutCount:   1,
+				ScaleInCount:    1,
+				MetricsFallback: &MetricsFallback{Action: "panic"},
+			},
+			expectedOutput: errors.New("failed to validate metrics fallback: FallbackAction panic is not a valid option"),
+			name:           "metrics fallback with invalid action",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:        true,
+				Cooldown:       100,
+				MinCount:       10,
+				MaxCount:       1000,
+				ScaleOutCount:  1,
+				ScaleInCount:   1,
+				PreScaleHooks:  []*ScalingHook{{URL: "http://cache.jrasell.system/warm"}},
+				PostScaleHooks: []*ScalingHook{{Command: "/usr/local/bin/purge-cdn", Args: []string{"--all"}}},
+			},
+			expectedOutput: nil,
+			name:           "valid scaling hooks",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:        true,
+				Cooldown:       100,
+				MinCount:       10,
+				MaxCount:       1000,
+				ScaleOutCount:  1,
+				ScaleInCount:   1,
+				PostScaleHooks: []*ScalingHook{{URL: "http://cache.jrasell.system/warm", Command: "/usr/local/bin/purge-cdn"}},
+			},
+			expectedOutput: errors.New("failed to validate scaling hook: scaling hook requires exactly one of URL or Command"),
+			name:           "scaling hook with URL and command",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:               true,
+				Cooldown:              100,
+				MinCount:              10,
+				MaxCount:              1000,
+				ScaleOutCount:         1,
+				ScaleInCount:          1,
+				ScaleOrder:            1,
+				WaitForHealthyTimeout: 120,
+			},
+			expectedOutput: nil,
+			name:           "valid scale order",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      100,
+				MinCount:      10,
+				MaxCount:      1000,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+				ScaleOrder:    -1,
+			},
+			expectedOutput: errors.New("scale order must not be negative"),
+			name:           "negative scale order",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:               true,
+				Cooldown:              100,
+				MinCount:              10,
+				MaxCount:              1000,
+				ScaleOutCount:         1,
+				ScaleInCount:          1,
+				WaitForHealthyTimeout: -10,
+			},
+			expectedOutput: errors.New("wait for healthy timeout must not be negative"),
+			name:           "negative wait for healthy timeout",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:                 true,
+				Cooldown:                100,
+				MinCount:                10,
+				MaxCount:                1000,
+				ScaleOutCount:           1,
+				ScaleInCount:            1,
+				MinScaleDeltaPercentage: -5,
+			},
+			expectedOutput: errors.New("minimum scale delta must not be negative"),
+			name:           "negative minimum scale delta percentage",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      100,
+				MinCount:      10,
+				MaxCount:      1000,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+				EvalPriority:  101,
+			},
+			expectedOutput: errors.New("evaluation priority must be between 1 and 100"),
+			name:           "evaluation priority out of range",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      100,
+				MinCount:      10,
+				MaxCount:      1000,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+				RunbookURL:    "https://wiki.jrasell.system/runbooks/cache",
+				Notes:         "Cache misses increase database load during scale in.",
+			},
+			expectedOutput: nil,
+			name:           "valid runbook URL",
+		},
+		{
+			policy: GroupScalingPolicy{
+				Enabled:       true,
+				Cooldown:      100,
+				MinCount:      10,
+				MaxCount:      1000,
+				ScaleOutCount: 1,
+				ScaleInCount:  1,
+				RunbookURL:    "wiki/runbooks/cache",
+			},
+			expectedOutput: errors.New("runbook URL must be an absolute http or https URL"),
+			name:           "relative runbook URL",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -84,6 +304,23 @@ func TestGroupScalingPolicy_NomadChecksEnabled(t *testing.T) {
 			expectedOutput: true,
 			name:           "nomad checks enabled",
 		},
+		{
+			policy: GroupScalingPolicy{
+				ScaleOutCPUPercentageThreshold: helper.Float64ToPointer(80),
+			},
+			expectedOutput: true,
+			name:           "single nomad check enabled",
+		},
+		{
+			policy: GroupScalingPolicy{
+				CompositeCheck: &CompositeCheck{
+					Weights:                     map[NomadResource]float64{NomadResourceCPU: 0.7, NomadResourceMemory: 0.3},
+					ScaleOutPercentageThreshold: helper.Float64ToPointer(80),
+				},
+			},
+			expectedOutput: true,
+			name:           "composite check enabled",
+		},
 	}
 
 	for _, tc := range testCases {
@@ -92,6 +329,172 @@ func TestGroupScalingPolicy_NomadChecksEnabled(t *testing.T) {
 	}
 }
 
+func TestGroupScalingPolicy_ExternalChecksEnabled(t *testing.T) {
+	testCases := []struct {
+		policy         GroupScalingPolicy
+		expectedOutput bool
+		name           string
+	}{
+		{
+			policy:         GroupScalingPolicy{},
+			expectedOutput: false,
+			name:           "no external checks",
+		},
+		{
+			policy: GroupScalingPolicy{
+				ExternalChecks: map[string]*ExternalCheck{"queue": {Enabled: false}},
+			},
+			expectedOutput: false,
+			name:           "external checks disabled",
+		},
+		{
+			policy: GroupScalingPolicy{
+				ExternalChecks: map[string]*ExternalCheck{"queue": {Enabled: false}, "latency": {Enabled: true}},
+			},
+			expectedOutput: true,
+			name:           "external check enabled",
+		},
+	}
+
+	for _, tc := range testCases {
+		actualOutput := tc.policy.ExternalChecksEnabled()
+		assert.Equal(t, tc.expectedOutput, actualOutput, tc.name)
+	}
+}
+
+func TestGroupScalingPolicy_NomadDiskChecksEnabled(t *testing.T) {
+	testCases := []struct {
+		policy         GroupScalingPolicy
+		expectedOutput bool
+		name           string
+	}{
+		{
+			policy: GroupScalingPolicy{
+				ScaleOutCPUPercentageThreshold: helper.Float64ToPointer(80),
+			},
+			expectedOutput: false,
+			name:           "no disk checks enabled",
+		},
+		{
+			policy: GroupScalingPolicy{
+				ScaleOutDiskPercentageThreshold: helper.Float64ToPointer(80),
+			},
+			expectedOutput: true,
+			name:           "disk threshold enabled",
+		},
+		{
+			policy: GroupScalingPolicy{
+				CompositeCheck: &CompositeCheck{
+					Weights: map[NomadResource]float64{NomadResourceCPU: 0.5, NomadResourceDisk: 0.5},
+				},
+			},
+			expectedOutput: true,
+			name:           "disk weighted composite check enabled",
+		},
+		{
+			policy: GroupScalingPolicy{
+				ExternalChecks: map[string]*ExternalCheck{
+					"disk": {Enabled: true, Query: "{{.Job}}/{{.Group}}/disk"},
+				},
+			},
+			expectedOutput: true,
+			name:           "Nomad resources disk external check enabled",
+		},
+		{
+			policy: GroupScalingPolicy{
+				ExternalChecks: map[string]*ExternalCheck{
+					"disk": {Enabled: true, Provider: ProviderPrometheus, Query: "node_disk/disk"},
+				},
+			},
+			expectedOutput: false,
+			name:           "other provider external check enabled",
+		},
+	}
+
+	for _, tc := range testCases {
+		actualOutput := tc.policy.NomadDiskChecksEnabled()
+		assert.Equal(t, tc.expectedOutput, actualOutput, tc.name)
+	}
+}
+
+func TestGroupScalingPolicy_NomadResourcesRequired(t *testing.T) {
+	testCases := []struct {
+		policy         GroupScalingPolicy
+		expectedOutput bool
+		name           string
+	}{
+		{
+			policy:         GroupScalingPolicy{ScaleOutCPUPercentageThreshold: helper.Float64ToPointer(80)},
+			expectedOutput: true,
+			name:           "Nomad checks enabled",
+		},
+		{
+			policy: GroupScalingPolicy{
+				ExternalChecks: map[string]*ExternalCheck{
+					"cpu": {Enabled: true, Query: "example/cache/cpu"},
+				},
+			},
+			expectedOutput: true,
+			name:           "default provider external check enabled",
+		},
+		{
+			policy: GroupScalingPolicy{
+				ExternalChecks: map[string]*ExternalCheck{
+					"cpu": {Enabled: false, Provider: ProviderNomadResources, Query: "example/cache/cpu"},
+				},
+			},
+			expectedOutput: false,
+			name:           "Nomad resources external check disabled",
+		},
+		{
+			policy: GroupScalingPolicy{
+				ExternalChecks: map[string]*ExternalCheck{
+					"queued": {Enabled: true, Provider: ProviderNomad, Query: "example/queued-allocations"},
+				},
+			},
+			expectedOutput: false,
+			name:           "other provider external check enabled",
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedOutput, tc.policy.NomadResourcesRequired(), tc.name)
+	}
+}
+
+func TestCompositeCheck_Score(t *testing.T) {
+	testCases := []struct {
+		check          CompositeCheck
+		usage          map[NomadResource]float64
+		expectedOutput float64
+		name           string
+	}{
+		{
+			check:          CompositeCheck{Weights: map[NomadResource]float64{NomadResourceCPU: 0.7, NomadResourceMemory: 0.3}},
+			usage:          map[NomadResource]float64{NomadResourceCPU: 90, NomadResourceMemory: 40},
+			expectedOutput: 75,
+			name:           "weights summing to one",
+		},
+		{
+			check:          CompositeCheck{Weights: map[NomadResource]float64{NomadResourceCPU: 7, NomadResourceMemory: 3}},
+			usage:          map[NomadResource]float64{NomadResourceCPU: 90, NomadResourceMemory: 40},
+			expectedOutput: 75,
+			name:           "weights not summing to one",
+		},
+		{
+			check:          CompositeCheck{Weights: map[NomadResource]float64{NomadResourceCPU: 0}},
+			usage:          map[NomadResource]float64{NomadResourceCPU: 90},
+			expectedOutput: 0,
+			name:           "zero total weight",
+		},
+	}
+
+	for _, tc := range testCases {
+		actualOutput := tc.check.Score(tc.usage)
+		assert.InDelta(t, tc.expectedOutput, actualOutput, 0.0001, tc.name)
+	}
+}
+
 func TestGroupScalingPolicy_MergeWithDefaults(t *testing.T) {
 	testCases := []struct {
 		inputPolicy    GroupScalingPolicy
@@ -136,6 +539,18 @@ func TestMetricsProvider_String(t *testing.T) {
 		expectedOutput string
 	}{
 		{inputProvider: ProviderPrometheus, expectedOutput: "prometheus"},
+		{inputProvider: ProviderEnvoy, expectedOutput: "envoy"},
+		{inputProvider: ProviderTraefik, expectedOutput: "traefik"},
+		{inputProvider: ProviderNGINX, expectedOutput: "nginx"},
+		{inputProvider: ProviderHAProxy, expectedOutput: "haproxy"},
+		{inputProvider: ProviderRabbitMQ, expectedOutput: "rabbitmq"},
+		{inputProvider: ProviderNATS, expectedOutput: "nats"},
+		{inputProvider: ProviderInfluxDB, expectedOutput: "influxdb"},
+		{inputProvider: ProviderGraphite, expectedOutput: "graphite"},
+		{inputProvider: ProviderElasticsearch, expectedOutput: "elasticsearch"},
+		{inputProvider: ProviderNewRelic, expectedOutput: "newrelic"},
+		{inputProvider: ProviderDatadog, expectedOutput: "datadog"},
+		{inputProvider: ProviderNomad, expectedOutput: "nomad"},
 	}
 
 	for _, tc := range testCases {
@@ -152,6 +567,19 @@ func TestMetricsProvider_Validate(t *testing.T) {
 		expectedOutput error
 	}{
 		{inputOperator: ProviderPrometheus, expectedOutput: nil},
+		{inputOperator: ProviderEnvoy, expectedOutput: nil},
+		{inputOperator: ProviderTraefik, expectedOutput: nil},
+		{inputOperator: ProviderNGINX, expectedOutput: nil},
+		{inputOperator: ProviderHAProxy, expectedOutput: nil},
+		{inputOperator: ProviderRabbitMQ, expectedOutput: nil},
+		{inputOperator: ProviderNATS, expectedOutput: nil},
+		{inputOperator: ProviderInfluxDB, expectedOutput: nil},
+		{inputOperator: ProviderGraphite, expectedOutput: nil},
+		{inputOperator: ProviderElasticsearch, expectedOutput: nil},
+		{inputOperator: ProviderNewRelic, expectedOutput: nil},
+		{inputOperator: ProviderDatadog, expectedOutput: nil},
+		{inputOperator: ProviderNomad, expectedOutput: nil},
+		{inputOperator: ProviderNomadResources, expectedOutput: nil},
 		{inputOperator: fakeProvider, expectedOutput: errors.Errorf("Provider %s is not a valid option", fakeProvider.String())},
 	}
 
@@ -238,3 +666,190 @@ func TestComparisonAction_Validate(t *testing.T) {
 		}
 	}
 }
+
+func TestMetricsFallback_NomadThresholds(t *testing.T) {
+	testCases := []struct {
+		fallback    MetricsFallback
+		expectedOut float64
+		expectedIn  float64
+		name        string
+	}{
+		{
+			fallback:    MetricsFallback{Action: FallbackNomadChecks},
+			expectedOut: DefaultFallbackScaleOutPercentageThreshold,
+			expectedIn:  DefaultFallbackScaleInPercentageThreshold,
+			name:        "default thresholds",
+		},
+		{
+			fallback: MetricsFallback{
+				Action:                      FallbackNomadChecks,
+				ScaleOutPercentageThreshold: helper.Float64ToPointer(70),
+				ScaleInPercentageThreshold:  helper.Float64ToPointer(30),
+			},
+			expectedOut: 70,
+			expectedIn:  30,
+			name:        "configured thresholds",
+		},
+	}
+
+	for _, tc := range testCases {
+		out, in := tc.fallback.NomadThresholds()
+		assert.Equal(t, tc.expectedOut, out, tc.name)
+		assert.Equal(t, tc.expectedIn, in, tc.name)
+	}
+}
+
+func TestMetricsFallback_Validate(t *testing.T) {
+	testCases := []struct {
+		fallback       MetricsFallback
+		expectedOutput error
+		name           string
+	}{
+		{
+			fallback:       MetricsFallback{Action: FallbackHold},
+			expectedOutput: nil,
+			name:           "hold action",
+		},
+		{
+			fallback:       MetricsFallback{Action: FallbackSafeCount, SafeCount: -1},
+			expectedOutput: errors.New("metrics fallback safe count must not be negative"),
+			name:           "negative safe count",
+		},
+		{
+			fallback: MetricsFallback{
+				Action:                     FallbackNomadChecks,
+				ScaleInPercentageThreshold: helper.Float64ToPointer(90),
+			},
+			expectedOutput: errors.New("metrics fallback scale in threshold must be less than scale out threshold"),
+			name:           "scale in threshold above default scale out",
+		},
+	}
+
+	for _, tc := range testCases {
+		actualOutput := tc.fallback.Validate()
+		if tc.expectedOutput == nil {
+			assert.Nil(t, actualOutput, tc.name)
+		} else {
+			assert.EqualError(t, actualOutput, tc.expectedOutput.Error(), tc.name)
+		}
+	}
+}
+
+func TestScaleOutSpread_Validate(t *testing.T) {
+	testCases := []struct {
+		spread         ScaleOutSpread
+		expectedOutput error
+		name           string
+	}{
+		{
+			spread:         ScaleOutSpread{},
+			expectedOutput: nil,
+			name:           "even spread",
+		},
+		{
+			spread:         ScaleOutSpread{Weight: 80, Targets: map[string]int{"dc1": 50, "dc2": 50}},
+			expectedOutput: nil,
+			name:           "targeted spread",
+		},
+		{
+			spread:         ScaleOutSpread{Weight: 101},
+			expectedOutput: errors.New("scale out spread weight must be between 1 and 100"),
+			name:           "weight out of range",
+		},
+		{
+			spread:         ScaleOutSpread{Targets: map[string]int{"dc1": 0}},
+			expectedOutput: errors.New("scale out spread target percentages must be between 1 and 100"),
+			name:           "zero target percentage",
+		},
+		{
+			spread:         ScaleOutSpread{Targets: map[string]int{"dc1": 70, "dc2": 40}},
+			expectedOutput: errors.New("scale out spread target percentages must not total more than 100"),
+			name:           "targets over 100 percent",
+		},
+	}
+
+	for _, tc := range testCases {
+		actualOutput := tc.spread.Validate()
+		if tc.expectedOutput == nil {
+			assert.Nil(t, actualOutput, tc.name)
+		} else {
+			assert.EqualError(t, actualOutput, tc.expectedOutput.Error(), tc.name)
+		}
+	}
+}
+
+func TestScaleOutSpread_Defaults(t *testing.T) {
+	spread := ScaleOutSpread{}
+	assert.Equal(t, DefaultSpreadAttribute, spread.GetAttribute())
+	assert.Equal(t, DefaultSpreadWeight, spread.GetWeight())
+
+	spread = ScaleOutSpread{Attribute: "${meta.rack}", Weight: 80}
+	assert.Equal(t, "${meta.rack}", spread.GetAttribute())
+	assert.Equal(t, 80, spread.GetWeight())
+}
+
+func TestFallbackAction_Validate(t *testing.T) {
+	const fakeAction FallbackAction = "fake-action"
+
+	testCases := []struct {
+		inputAction    FallbackAction
+		expectedOutput error
+	}{
+		{inputAction: FallbackHold, expectedOutput: nil},
+		{inputAction: FallbackSafeCount, expectedOutput: nil},
+		{inputAction: FallbackNomadChecks, expectedOutput: nil},
+		{inputAction: fakeAction, expectedOutput: errors.Errorf("FallbackAction %s is not a valid option", fakeAction.String())},
+	}
+
+	for _, tc := range testCases {
+		actualOutput := tc.inputAction.Validate()
+		if tc.expectedOutput == nil {
+			assert.Nil(t, actualOutput)
+		} else {
+			assert.EqualError(t, actualOutput, tc.expectedOutput.Error())
+		}
+	}
+}
+
+func TestScalingHook_Validate(t *testing.T) {
+	testCases := []struct {
+		hook           ScalingHook
+		expectedOutput error
+		name           string
+	}{
+		{
+			hook:           ScalingHook{URL: "http://cache.jrasell.system/warm", Timeout: 5},
+			expectedOutput: nil,
+			name:           "valid HTTP hook",
+		},
+		{
+			hook:           ScalingHook{Command: "/usr/local/bin/purge-cdn", Args: []string{"--all"}},
+			expectedOutput: nil,
+			name:           "valid command hook",
+		},
+		{
+			hook:           ScalingHook{},
+			expectedOutput: errors.New("scaling hook requires exactly one of URL or Command"),
+			name:           "empty hook",
+		},
+		{
+			hook:           ScalingHook{URL: "http://cache.jrasell.system/warm", Args: []string{"--all"}},
+			expectedOutput: errors.New("scaling hook args are only supported with a command"),
+			name:           "HTTP hook with args",
+		},
+		{
+			hook:           ScalingHook{Command: "/usr/local/bin/purge-cdn", Timeout: -1},
+			expectedOutput: errors.New("scaling hook timeout must not be negative"),
+			name:           "negative timeout",
+		},
+	}
+
+	for _, tc := range testCases {
+		actualOutput := tc.hook.Validate()
+		if tc.expectedOutput == nil {
+			assert.Nil(t, actualOutput, tc.name)
+		} else {
+			assert.EqualError(t, actualOutput, tc.expectedOutput.Error(), tc.name)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package policy
+
+import (
+	"bytes"
+	"strings"
+	"text/template"
+
+	"github.com/pkg/errors"
+)
+
+// QueryVars are the variables available to external check query templates. This allows a single
+// policy, such as one applied to many groups via a template, to produce the correct query for
+// each job group.
+type QueryVars struct {
+	Job       string
+	Group     string
+	Namespace string
+}
+
+// GetProvider returns the provider which runs the check query, which is the default provider if
+// the check does not set one.
+func (ec *ExternalCheck) GetProvider() MetricsProvider {
+	if ec.Provider == "" {
+		return DefaultMetricsProvider
+	}
+	return ec.Provider
+}
+
+// IsQueryTemplate returns whether the check query contains template actions which need to be
+// rendered before the query is run.
+func (ec *ExternalCheck) IsQueryTemplate() bool {
+	return strings.Contains(ec.Query, "{{")
+}
+
+// RenderQuery returns the check query with any template variables replaced using the passed
+// variables. Queries which are not templates are returned unchanged.
+func (ec *ExternalCheck) RenderQuery(vars QueryVars) (string, error) {
+	if !ec.IsQueryTemplate() {
+		return ec.Query, nil
+	}
+
+	tmpl, err := template.New("query").Option("missingkey=error").Parse(ec.Query)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to parse query template")
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, vars); err != nil {
+		return "", errors.Wrap(err, "failed to render query template")
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,79 @@
+package policy
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExternalCheck_GetProvider(t *testing.T) {
+	assert.Equal(t, DefaultMetricsProvider, (&ExternalCheck{}).GetProvider())
+	assert.Equal(t, ProviderPrometheus, (&ExternalCheck{Provider: ProviderPrometheus}).GetProvider())
+}
+
+func TestExternalCheck_RenderQuery(t *testing.T) {
+	vars := QueryVars{Job: "example", Group: "cache", Namespace: "platform"}
+
+	testCases := []struct {
+		name          string
+		query         string
+		expected      string
+		expectedError bool
+	}{
+		{
+			name:     "plain query",
+			query:    `sum(rate(http_requests_total{job="example"}[1m]))`,
+			expected: `sum(rate(http_requests_total{job="example"}[1m]))`,
+		},
+		{
+			name:     "templated query",
+			query:    `sum(nomad_queue_depth{namespace="{{.Namespace}}",job="{{.Job}}",group="{{.Group}}"})`,
+			expected: `sum(nomad_queue_depth{namespace="platform",job="example",group="cache"})`,
+		},
+		{
+			name:          "unknown variable",
+			query:         `queue_depth{task="{{.Task}}"}`,
+			expectedError: true,
+		},
+		{
+			name:          "invalid template",
+			query:         `queue_depth{group="{{.Group"}`,
+			expectedError: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			check := &ExternalCheck{Query: tc.query}
+			actual, err := check.RenderQuery(vars)
+			if tc.expectedError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestGroupScalingPolicy_Validate_queryTemplate(t *testing.T) {
+	pol := GroupScalingPolicy{
+		Enabled:       true,
+		MaxCount:      10,
+		ScaleOutCount: 1,
+		ScaleInCount:  1,
+		ExternalChecks: map[string]*ExternalCheck{
+			"queue": {
+				Enabled:            true,
+				Provider:           ProviderPrometheus,
+				Query:              `queue_depth{group="{{.Group}}"}`,
+				ComparisonOperator: ComparisonGreaterThan,
+				Action:             ActionScaleOut,
+			},
+		},
+	}
+	assert.Nil(t, pol.Validate())
+
+	pol.ExternalChecks["queue"].Query = `queue_depth{task="{{.Task}}"}`
+	assert.NotNil(t, pol.Validate())
+}
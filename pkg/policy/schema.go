@@ -0,0 +1,369 @@
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// schemaDocument is the JSON Schema describing a group scaling policy document. The schema of a
+// job scaling policy document, which maps group names to group policies, is found under the
+// JobScalingPolicy definition. The schema is published via the API so that editors and CI
+// pipelines can validate policy files before they are submitted.
+const schemaDocument = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$id": "https://github.com/jrasell/sherpa/policy.schema.json",
+  "title": "Sherpa group scaling policy",
+  "$ref": "#/definitions/GroupScalingPolicy",
+  "definitions": {
+    "JobScalingPolicy": {
+      "description": "The scaling policies of a job, keyed by task group name.",
+      "type": "object",
+      "additionalProperties": {"$ref": "#/definitions/GroupScalingPolicy"}
+    },
+    "GroupScalingPolicy": {
+      "description": "The scaling policy of a single job task group.",
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "Enabled": {"type": "boolean"},
+        "Cooldown": {"type": "integer", "minimum": 0},
+        "MinCount": {"type": "integer", "minimum": 0},
+        "MaxCount": {"type": "integer", "minimum": 0},
+        "ScaleOutCount": {"type": "integer", "minimum": 0},
+        "ScaleInCount": {"type": "integer", "minimum": 0},
+        "ScaleOutCPUPercentageThreshold": {"$ref": "#/definitions/Percentage"},
+        "ScaleOutMemoryPercentageThreshold": {"$ref": "#/definitions/Percentage"},
+        "ScaleInCPUPercentageThreshold": {"$ref": "#/definitions/Percentage"},
+        "ScaleInMemoryPercentageThreshold": {"$ref": "#/definitions/Percentage"},
+        "ScaleOutDiskPercentageThreshold": {"$ref": "#/definitions/Percentage"},
+        "ScaleInDiskPercentageThreshold": {"$ref": "#/definitions/Percentage"},
+        "ScaleOutGPUPercentageThreshold": {"$ref": "#/definitions/Percentage"},
+        "ScaleInGPUPercentageThreshold": {"$ref": "#/definitions/Percentage"},
+        "ResourceTasks": {"type": ["array", "null"], "items": {"type": "string"}},
+        "CompositeCheck": {"$ref": "#/definitions/CompositeCheck"},
+        "ExternalChecks": {
+          "type": ["object", "null"],
+          "additionalProperties": {"$ref": "#/definitions/ExternalCheck"}
+        },
+        "MetricsFallback": {"$ref": "#/definitions/MetricsFallback"},
+        "ScaleOutSpread": {"$ref": "#/definitions/ScaleOutSpread"},
+        "PreScaleHooks": {"type": ["array", "null"], "items": {"$ref": "#/definitions/ScalingHook"}},
+        "PostScaleHooks": {"type": ["array", "null"], "items": {"$ref": "#/definitions/ScalingHook"}},
+        "ScaleOrder": {"type": "integer", "minimum": 0},
+        "WaitForHealthyTimeout": {"type": "integer", "minimum": 0},
+        "MinScaleDelta": {"type": "integer", "minimum": 0},
+        "MinScaleDeltaPercentage": {"type": "number", "minimum": 0},
+        "EvalPriority": {"type": "integer", "minimum": 0, "maximum": 100},
+        "RunbookURL": {"type": "string"},
+        "Notes": {"type": "string"},
+        "Labels": {"type": ["object", "null"], "additionalProperties": {"type": "string"}}
+      }
+    },
+    "Percentage": {
+      "type": ["number", "null"],
+      "minimum": 0
+    },
+    "ExternalCheck": {
+      "type": "object",
+      "additionalProperties": false,
+      "required": ["ComparisonOperator", "Action"],
+      "properties": {
+        "Enabled": {"type": "boolean"},
+        "Provider": {
+          "type": "string",
+          "enum": ["prometheus", "envoy", "traefik", "nginx", "haproxy", "rabbitmq", "nats",
+            "influxdb", "graphite", "elasticsearch", "newrelic", "datadog", "nomad", "nomad-resources"]
+        },
+        "Query": {"type": "string"},
+        "ComparisonOperator": {"type": "string", "enum": ["greater-than", "less-than"]},
+        "ComparisonValue": {"type": "number"},
+        "Action": {"type": "string", "enum": ["scale-in", "scale-out"]},
+        "PerAllocation": {"type": "boolean"},
+        "Endpoint": {"type": "string"}
+      }
+    },
+    "CompositeCheck": {
+      "type": ["object", "null"],
+      "additionalProperties": false,
+      "required": ["Weights"],
+      "properties": {
+        "Weights": {
+          "type": "object",
+          "additionalProperties": false,
+          "properties": {
+            "cpu": {"type": "number", "minimum": 0},
+            "memory": {"type": "number", "minimum": 0},
+            "disk": {"type": "number", "minimum": 0},
+            "gpu": {"type": "number", "minimum": 0}
+          }
+        },
+        "ScaleOutPercentageThreshold": {"$ref": "#/definitions/Percentage"},
+        "ScaleInPercentageThreshold": {"$ref": "#/definitions/Percentage"}
+      }
+    },
+    "MetricsFallback": {
+      "type": ["object", "null"],
+      "additionalProperties": false,
+      "required": ["Action"],
+      "properties": {
+        "Action": {"type": "string", "enum": ["hold", "safe-count", "nomad-checks"]},
+        "SafeCount": {"type": "integer", "minimum": 0},
+        "ScaleOutPercentageThreshold": {"$ref": "#/definitions/Percentage"},
+        "ScaleInPercentageThreshold": {"$ref": "#/definitions/Percentage"}
+      }
+    },
+    "ScaleOutSpread": {
+      "type": ["object", "null"],
+      "additionalProperties": false,
+      "properties": {
+        "Attribute": {"type": "string"},
+        "Weight": {"type": "integer", "minimum": 0, "maximum": 100},
+        "Targets": {
+          "type": ["object", "null"],
+          "additionalProperties": {"type": "integer", "minimum": 1, "maximum": 100}
+        }
+      }
+    },
+    "ScalingHook": {
+      "type": "object",
+      "additionalProperties": false,
+      "properties": {
+        "URL": {"type": "string"},
+        "Command": {"type": "string"},
+        "Args": {"type": ["array", "null"], "items": {"type": "string"}},
+        "Timeout": {"type": "integer", "minimum": 0}
+      }
+    }
+  }
+}`
+
+const schemaDefinitionJobPolicy = "#/definitions/JobScalingPolicy"
+
+// policySchema is the parsed form of schemaDocument used to validate policy documents.
+var policySchema = mustParseSchema(schemaDocument)
+
+// Schema returns the JSON Schema describing scaling policy documents.
+func Schema() []byte { return []byte(schemaDocument) }
+
+// ValidateGroupDocument validates the raw JSON group scaling policy document against the policy
+// schema, returning an error describing all the violations found.
+func ValidateGroupDocument(doc []byte) error {
+	return validateDocument(doc, policySchema)
+}
+
+// ValidateJobDocument validates the raw JSON job scaling policy document against the policy
+// schema, returning an error describing all the violations found.
+func ValidateJobDocument(doc []byte) error {
+	return validateDocument(doc, &schema{Ref: schemaDefinitionJobPolicy})
+}
+
+func validateDocument(doc []byte, s *schema) error {
+	var val interface{}
+
+	if err := json.Unmarshal(doc, &val); err != nil {
+		return errors.Wrap(err, "failed to unmarshal policy document")
+	}
+
+	violations := s.validate(val, "")
+	if len(violations) == 0 {
+		return nil
+	}
+
+	sort.Strings(violations)
+	return errors.Errorf("policy document does not match schema: %s", strings.Join(violations, "; "))
+}
+
+// schema is the subset of JSON Schema used by schemaDocument. Only the keywords required to
+// describe policy documents are supported, and references must point to a root definition.
+type schema struct {
+	Ref                  string             `json:"$ref"`
+	Type                 schemaTypes        `json:"type"`
+	Properties           map[string]*schema `json:"properties"`
+	AdditionalProperties *schemaAdditional  `json:"additionalProperties"`
+	Required             []string           `json:"required"`
+	Enum                 []interface{}      `json:"enum"`
+	Minimum              *float64           `json:"minimum"`
+	Items                *schema            `json:"items"`
+	Definitions          map[string]*schema `json:"definitions"`
+}
+
+// schemaTypes handles the type keyword being either a single type or a list of types.
+type schemaTypes []string
+
+func (st *schemaTypes) UnmarshalJSON(b []byte) error {
+	var single string
+	if err := json.Unmarshal(b, &single); err == nil {
+		*st = schemaTypes{single}
+		return nil
+	}
+
+	var multi []string
+	if err := json.Unmarshal(b, &multi); err != nil {
+		return err
+	}
+	*st = multi
+	return nil
+}
+
+// schemaAdditional handles the additionalProperties keyword being either a boolean or a schema.
+type schemaAdditional struct {
+	denied bool
+	schema *schema
+}
+
+func (sa *schemaAdditional) UnmarshalJSON(b []byte) error {
+	var allowed bool
+	if err := json.Unmarshal(b, &allowed); err == nil {
+		sa.denied = !allowed
+		return nil
+	}
+
+	sa.schema = &schema{}
+	return json.Unmarshal(b, sa.schema)
+}
+
+func mustParseSchema(doc string) *schema {
+	var s schema
+	if err := json.Unmarshal([]byte(doc), &s); err != nil {
+		panic(fmt.Sprintf("failed to parse policy schema: %v", err))
+	}
+	return &s
+}
+
+// resolve returns the schema to validate against, following any reference to a root definition.
+func (s *schema) resolve() *schema {
+	if s.Ref == "" {
+		return s
+	}
+	return policySchema.Definitions[strings.TrimPrefix(s.Ref, "#/definitions/")]
+}
+
+// validate checks the decoded JSON value against the schema, returning the violations found. The
+// path identifies the location of the value within the document.
+func (s *schema) validate(val interface{}, path string) []string {
+	s = s.resolve()
+	if s == nil {
+		return []string{formatSchemaPath(path) + ": unknown schema reference"}
+	}
+
+	if len(s.Type) > 0 && !s.Type.matches(val) {
+		return []string{fmt.Sprintf("%s: must be of type %s, got %s",
+			formatSchemaPath(path), strings.Join(s.Type, " or "), jsonType(val))}
+	}
+
+	var violations []string
+
+	if len(s.Enum) > 0 && !enumContains(s.Enum, val) {
+		violations = append(violations, fmt.Sprintf("%s: must be one of %s",
+			formatSchemaPath(path), formatEnum(s.Enum)))
+	}
+
+	switch v := val.(type) {
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			violations = append(violations, fmt.Sprintf("%s: must be greater than or equal to %v",
+				formatSchemaPath(path), *s.Minimum))
+		}
+
+	case []interface{}:
+		if s.Items != nil {
+			for i, item := range v {
+				violations = append(violations, s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+
+	case map[string]interface{}:
+		for _, req := range s.Required {
+			if _, ok := v[req]; !ok {
+				violations = append(violations, fmt.Sprintf("%s: missing required property %s",
+					formatSchemaPath(path), req))
+			}
+		}
+
+		for key, child := range v {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+
+			if prop, ok := s.Properties[key]; ok {
+				violations = append(violations, prop.validate(child, childPath)...)
+				continue
+			}
+
+			if s.AdditionalProperties == nil {
+				continue
+			}
+			if s.AdditionalProperties.denied {
+				violations = append(violations, fmt.Sprintf("%s: unknown property", childPath))
+				continue
+			}
+			violations = append(violations, s.AdditionalProperties.schema.validate(child, childPath)...)
+		}
+	}
+	return violations
+}
+
+func (st schemaTypes) matches(val interface{}) bool {
+	actual := jsonType(val)
+
+	for _, t := range st {
+		if t == actual {
+			return true
+		}
+		if t == "number" && actual == "integer" {
+			return true
+		}
+	}
+	return false
+}
+
+// jsonType returns the JSON Schema type name of the decoded JSON value.
+func jsonType(val interface{}) string {
+	switch v := val.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case float64:
+		if v == math.Trunc(v) {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func enumContains(enum []interface{}, val interface{}) bool {
+	for _, e := range enum {
+		if e == val {
+			return true
+		}
+	}
+	return false
+}
+
+func formatEnum(enum []interface{}) string {
+	out := make([]string, len(enum))
+	for i, e := range enum {
+		out[i] = fmt.Sprint(e)
+	}
+	return strings.Join(out, ", ")
+}
+
+func formatSchemaPath(path string) string {
+	if path == "" {
+		return "document"
+	}
+	return path
+}
@@ -0,0 +1,87 @@
+package policy
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSchema(t *testing.T) {
+	var doc map[string]interface{}
+	assert.Nil(t, json.Unmarshal(Schema(), &doc))
+
+	// Ensure every policy field is described by the schema, so the two do not drift.
+	props := policySchema.Definitions["GroupScalingPolicy"].Properties
+	typ := reflect.TypeOf(GroupScalingPolicy{})
+
+	for i := 0; i < typ.NumField(); i++ {
+		name := strings.Split(typ.Field(i).Tag.Get("json"), ",")[0]
+		assert.Contains(t, props, name)
+	}
+}
+
+func TestValidateGroupDocument(t *testing.T) {
+	testCases := []struct {
+		doc         string
+		expectedErr string
+		name        string
+	}{
+		{
+			doc:         `{"Enabled":true,"MinCount":2,"MaxCount":10,"ScaleOutCPUPercentageThreshold":null}`,
+			expectedErr: "",
+			name:        "valid policy",
+		},
+		{
+			doc: `{"Enabled":true,"ExternalChecks":{"queue":{"Enabled":true,"Provider":"rabbitmq",` +
+				`"Query":"jobs","ComparisonOperator":"greater-than","ComparisonValue":10.5,"Action":"scale-out"}},` +
+				`"MetricsFallback":{"Action":"safe-count","SafeCount":4},"PreScaleHooks":[{"URL":"http://localhost"}]}`,
+			expectedErr: "",
+			name:        "valid nested objects",
+		},
+		{
+			doc:         `{"Enabled":true,"MaxCont":10}`,
+			expectedErr: "policy document does not match schema: MaxCont: unknown property",
+			name:        "unknown property",
+		},
+		{
+			doc:         `{"Enabled":"true","MaxCount":1.5,"Cooldown":-1}`,
+			expectedErr: "policy document does not match schema: Cooldown: must be greater than or equal to 0; Enabled: must be of type boolean, got string; MaxCount: must be of type integer, got number",
+			name:        "invalid types",
+		},
+		{
+			doc:         `{"ExternalChecks":{"queue":{"Provider":"statsd","Action":"scale-out"}}}`,
+			expectedErr: "policy document does not match schema: ExternalChecks.queue.Provider: must be one of prometheus, envoy, traefik, nginx, haproxy, rabbitmq, nats, influxdb, graphite, elasticsearch, newrelic, datadog, nomad, nomad-resources; ExternalChecks.queue: missing required property ComparisonOperator",
+			name:        "invalid external check",
+		},
+		{
+			doc:         `{"PreScaleHooks":[{"URL":"http://localhost","Retries":3}]}`,
+			expectedErr: "policy document does not match schema: PreScaleHooks[0].Retries: unknown property",
+			name:        "invalid scaling hook",
+		},
+		{
+			doc:         `[]`,
+			expectedErr: "policy document does not match schema: document: must be of type object, got array",
+			name:        "invalid document type",
+		},
+	}
+
+	for _, tc := range testCases {
+		err := ValidateGroupDocument([]byte(tc.doc))
+		if tc.expectedErr == "" {
+			assert.Nil(t, err, tc.name)
+		} else {
+			assert.EqualError(t, err, tc.expectedErr, tc.name)
+		}
+	}
+}
+
+func TestValidateJobDocument(t *testing.T) {
+	assert.Nil(t, ValidateJobDocument([]byte(`{"cache":{"Enabled":true},"proxy":{"MaxCount":4}}`)))
+	assert.EqualError(t, ValidateJobDocument([]byte(`{"cache":{"Enabled":true},"proxy":{"Max":4}}`)),
+		"policy document does not match schema: proxy.Max: unknown property")
+	assert.EqualError(t, ValidateJobDocument([]byte(`{"cache":true}`)),
+		"policy document does not match schema: cache: must be of type object, got boolean")
+}
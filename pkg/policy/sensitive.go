@@ -0,0 +1,50 @@
+package policy
+
+// TransformSensitive returns a copy of the policy with the fn applied to each of its sensitive
+// fields; the URL and args of its scaling hooks, which commonly contain tokens. The policy itself
+// is not modified. This allows storage backends to encrypt sensitive fields at rest.
+func (gsp GroupScalingPolicy) TransformSensitive(fn func(string) (string, error)) (*GroupScalingPolicy, error) {
+	n := gsp
+
+	var err error
+
+	if n.PreScaleHooks, err = transformHooks(gsp.PreScaleHooks, fn); err != nil {
+		return nil, err
+	}
+	if n.PostScaleHooks, err = transformHooks(gsp.PostScaleHooks, fn); err != nil {
+		return nil, err
+	}
+	return &n, nil
+}
+
+func transformHooks(hooks []*ScalingHook, fn func(string) (string, error)) ([]*ScalingHook, error) {
+	if hooks == nil {
+		return nil, nil
+	}
+
+	out := make([]*ScalingHook, len(hooks))
+
+	for i, hook := range hooks {
+		if hook == nil {
+			continue
+		}
+
+		h := *hook
+
+		var err error
+		if h.URL, err = fn(hook.URL); err != nil {
+			return nil, err
+		}
+
+		if hook.Args != nil {
+			h.Args = make([]string, len(hook.Args))
+			for j := range hook.Args {
+				if h.Args[j], err = fn(hook.Args[j]); err != nil {
+					return nil, err
+				}
+			}
+		}
+		out[i] = &h
+	}
+	return out, nil
+}
@@ -0,0 +1,32 @@
+package policy
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGroupScalingPolicy_TransformSensitive(t *testing.T) {
+	pol := GroupScalingPolicy{
+		Enabled:        true,
+		PreScaleHooks:  []*ScalingHook{{URL: "https://hooks.example.com?token=abc", Timeout: 5}},
+		PostScaleHooks: []*ScalingHook{{Command: "/usr/local/bin/notify", Args: []string{"--token", "abc"}}},
+	}
+
+	out, err := pol.TransformSensitive(func(s string) (string, error) { return strings.ToUpper(s), nil })
+	assert.Nil(t, err)
+	assert.Equal(t, "HTTPS://HOOKS.EXAMPLE.COM?TOKEN=ABC", out.PreScaleHooks[0].URL)
+	assert.Equal(t, 5, out.PreScaleHooks[0].Timeout)
+	assert.Equal(t, "/usr/local/bin/notify", out.PostScaleHooks[0].Command)
+	assert.Equal(t, []string{"--TOKEN", "ABC"}, out.PostScaleHooks[0].Args)
+	assert.True(t, out.Enabled)
+
+	// The original policy is not modified.
+	assert.Equal(t, "https://hooks.example.com?token=abc", pol.PreScaleHooks[0].URL)
+	assert.Equal(t, []string{"--token", "abc"}, pol.PostScaleHooks[0].Args)
+
+	_, err = pol.TransformSensitive(func(s string) (string, error) { return "", errors.New("boom") })
+	assert.NotNil(t, err)
+}
@@ -0,0 +1,141 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+)
+
+// BulkDeleteResponse is the response of a bulk policy deletion, detailing the job groups whose
+// policies were deleted, or would be deleted if the request was a dry-run.
+type BulkDeleteResponse struct {
+	DryRun  bool
+	Deleted map[string][]string
+}
+
+// policySelector identifies the policies targeted by a bulk operation. A policy is selected when
+// its job ID has the prefix and it has all of the labels.
+type policySelector struct {
+	jobPrefix string
+	labels    map[string]string
+}
+
+// parsePolicySelector builds the selector from the request query params. At least one selector
+// must be provided, to protect against accidentally removing all policies.
+func parsePolicySelector(q url.Values) (*policySelector, error) {
+	sel := &policySelector{jobPrefix: q.Get("job_prefix"), labels: make(map[string]string)}
+
+	// Labels can be passed as repeated params, or as a comma separated list within a single param.
+	for _, param := range q["label"] {
+		for _, label := range strings.Split(param, ",") {
+			split := strings.SplitN(label, "=", 2)
+			if len(split) != 2 || split[0] == "" {
+				return nil, errors.Errorf("invalid label selector %q, must be in the form key=value", label)
+			}
+			sel.labels[split[0]] = split[1]
+		}
+	}
+
+	if sel.jobPrefix == "" && len(sel.labels) == 0 {
+		return nil, errors.New("at least one job_prefix or label selector is required")
+	}
+	return sel, nil
+}
+
+// selectPolicies returns the groups of each job whose policies match the selector, with the
+// group names sorted.
+func (ps *policySelector) selectPolicies(policies map[string]map[string]*policy.GroupScalingPolicy) map[string][]string {
+	out := make(map[string][]string)
+
+	for job, groups := range policies {
+		if !strings.HasPrefix(job, ps.jobPrefix) {
+			continue
+		}
+
+		for group, pol := range groups {
+			if pol != nil && pol.MatchLabels(ps.labels) {
+				out[job] = append(out[job], group)
+			}
+		}
+
+		if len(out[job]) > 0 {
+			sort.Strings(out[job])
+		}
+	}
+	return out
+}
+
+// DeletePolicies deletes all the scaling policies which match the job prefix and label selectors
+// of the request. When the dry_run query param is set, the matching policies are listed without
+// being deleted.
+func (p *Policy) DeletePolicies(w http.ResponseWriter, r *http.Request) {
+	sel, err := parsePolicySelector(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	var dryRun bool
+
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		if dryRun, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, "failed to parse dry_run query param", http.StatusUnprocessableEntity)
+			return
+		}
+	}
+
+	policies, err := p.backend.GetPolicies(r.Context())
+	if err != nil {
+		p.logger.Error().Err(err).Msg("failed to call policy backend")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	resp := &BulkDeleteResponse{DryRun: dryRun, Deleted: sel.selectPolicies(policies)}
+
+	if !dryRun {
+		for job, groups := range resp.Deleted {
+			if err := p.deleteGroups(r, job, groups, len(policies[job])); err != nil {
+				p.logger.Error().Str("job", job).Err(err).Msg("failed to bulk delete job scaling policies")
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+		}
+	}
+
+	bytes, err := json.Marshal(resp)
+	if err != nil {
+		p.logger.Error().Err(err).Msg(marshalRespFailureMsg)
+		http.Error(w, marshalRespFailureMsg, http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// deleteGroups deletes the policies of the job groups. If every group of the job is being deleted,
+// the job policy is deleted as a whole.
+func (p *Policy) deleteGroups(r *http.Request, job string, groups []string, total int) error {
+	defer p.cancelRollout(job)
+
+	if len(groups) == total {
+		if err := p.backend.DeleteJobPolicy(r.Context(), job); err != nil {
+			return err
+		}
+		p.logger.Info().Str("job", job).Msg("bulk deleted job scaling policy")
+		return nil
+	}
+
+	for _, group := range groups {
+		if err := p.backend.DeleteJobGroupPolicy(r.Context(), job, group); err != nil {
+			return err
+		}
+		p.logger.Info().Str("job", job).Str("group", group).Msg("bulk deleted job group scaling policy")
+	}
+	return nil
+}
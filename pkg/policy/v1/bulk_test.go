@@ -0,0 +1,122 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/policy/backend/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPolicy_DeletePolicies(t *testing.T) {
+	staging := map[string]string{"env": "staging"}
+
+	setup := func() *Policy {
+		b := memory.NewJobScalingPolicies()
+		_ = b.PutJobPolicy(context.Background(), "staging-api", map[string]*policy.GroupScalingPolicy{
+			"web": {Enabled: true, Labels: staging},
+			"db":  {Enabled: true, Labels: staging},
+		})
+		_ = b.PutJobPolicy(context.Background(), "staging-worker", map[string]*policy.GroupScalingPolicy{
+			"queue": {Enabled: true},
+		})
+		_ = b.PutJobPolicy(context.Background(), "prod-api", map[string]*policy.GroupScalingPolicy{
+			"web":    {Enabled: true, Labels: map[string]string{"env": "prod"}},
+			"canary": {Enabled: true, Labels: staging},
+		})
+		return NewPolicyServer(zerolog.Nop(), b)
+	}
+
+	testCases := []struct {
+		query             string
+		expectedCode      int
+		expectedResp      *BulkDeleteResponse
+		expectedRemaining map[string][]string
+		name              string
+	}{
+		{
+			query:        "",
+			expectedCode: http.StatusUnprocessableEntity,
+			name:         "no selectors",
+		},
+		{
+			query:        "?label=env",
+			expectedCode: http.StatusUnprocessableEntity,
+			name:         "invalid label",
+		},
+		{
+			query:        "?job_prefix=staging-&dry_run=true",
+			expectedCode: http.StatusOK,
+			expectedResp: &BulkDeleteResponse{DryRun: true, Deleted: map[string][]string{
+				"staging-api": {"db", "web"}, "staging-worker": {"queue"},
+			}},
+			expectedRemaining: map[string][]string{
+				"staging-api": {"db", "web"}, "staging-worker": {"queue"}, "prod-api": {"canary", "web"},
+			},
+			name: "dry-run job prefix",
+		},
+		{
+			query:        "?job_prefix=staging-",
+			expectedCode: http.StatusOK,
+			expectedResp: &BulkDeleteResponse{Deleted: map[string][]string{
+				"staging-api": {"db", "web"}, "staging-worker": {"queue"},
+			}},
+			expectedRemaining: map[string][]string{"prod-api": {"canary", "web"}},
+			name:              "job prefix",
+		},
+		{
+			query:        "?label=env=staging",
+			expectedCode: http.StatusOK,
+			expectedResp: &BulkDeleteResponse{Deleted: map[string][]string{
+				"staging-api": {"db", "web"}, "prod-api": {"canary"},
+			}},
+			expectedRemaining: map[string][]string{"staging-worker": {"queue"}, "prod-api": {"web"}},
+			name:              "label",
+		},
+		{
+			query:        "?job_prefix=prod-&label=env=staging",
+			expectedCode: http.StatusOK,
+			expectedResp: &BulkDeleteResponse{Deleted: map[string][]string{"prod-api": {"canary"}}},
+			expectedRemaining: map[string][]string{
+				"staging-api": {"db", "web"}, "staging-worker": {"queue"}, "prod-api": {"web"},
+			},
+			name: "job prefix and label",
+		},
+	}
+
+	for _, tc := range testCases {
+		p := setup()
+
+		w := httptest.NewRecorder()
+		p.DeletePolicies(w, httptest.NewRequest(http.MethodDelete, "/v1/policies"+tc.query, nil))
+		assert.Equal(t, tc.expectedCode, w.Code, tc.name)
+
+		if tc.expectedResp == nil {
+			continue
+		}
+
+		var resp BulkDeleteResponse
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &resp), tc.name)
+		assert.Equal(t, tc.expectedResp, &resp, tc.name)
+
+		policies, err := p.backend.GetPolicies(context.Background())
+		assert.Nil(t, err, tc.name)
+
+		remaining := make(map[string][]string)
+		for job, groups := range policies {
+			for group := range groups {
+				remaining[job] = append(remaining[job], group)
+			}
+		}
+		for job := range remaining {
+			sort.Strings(remaining[job])
+		}
+		assert.Equal(t, tc.expectedRemaining, remaining, tc.name)
+	}
+}
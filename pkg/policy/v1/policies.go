@@ -16,14 +16,21 @@ import (
 type Policy struct {
 	logger  zerolog.Logger
 	backend backend.PolicyBackend
+
+	// rollouts are the job policy changes which have only been applied to canary groups.
+	rollouts *rollouts
+
+	// rolloutsDisabled causes requests passing a canary percentage to be refused, as rollouts are
+	// held in memory and would be lost when leadership changes.
+	rolloutsDisabled bool
 }
 
 func NewPolicyServer(l zerolog.Logger, backend backend.PolicyBackend) *Policy {
-	return &Policy{logger: l, backend: backend}
+	return &Policy{logger: l, backend: backend, rollouts: newRollouts()}
 }
 
 func (p *Policy) GetJobPolicies(w http.ResponseWriter, r *http.Request) {
-	policies, err := p.backend.GetPolicies()
+	policies, err := p.backend.GetPolicies(r.Context())
 	if err != nil {
 		p.logger.Error().Err(err).Msg("failed to call policy backend")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -44,7 +51,7 @@ func (p *Policy) GetJobPolicy(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	job := vars["job_id"]
 
-	policies, err := p.backend.GetJobPolicy(job)
+	policies, err := p.backend.GetJobPolicy(r.Context(), job)
 	if err != nil {
 		p.logger.Error().Err(err).Msg("failed to call policy backend")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -71,7 +78,7 @@ func (p *Policy) GetJobGroupPolicy(w http.ResponseWriter, r *http.Request) {
 	job := vars["job_id"]
 	group := vars["group"]
 
-	gPolicy, err := p.backend.GetJobGroupPolicy(job, group)
+	gPolicy, err := p.backend.GetJobGroupPolicy(r.Context(), job, group)
 	if err != nil {
 		p.logger.Error().Err(err).Msg("failed to call policy backend")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
@@ -109,14 +116,46 @@ func (p *Policy) PutJobPolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	pct, err := parseCanaryPercentage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if pct > 0 && p.rolloutsDisabled {
+		http.Error(w, errRolloutsDisabled.Error(), http.StatusBadRequest)
+		return
+	}
+
 	vars := mux.Vars(r)
 	job := vars["job_id"]
 
-	if err := p.backend.PutJobPolicy(job, jobPolicy); err != nil {
+	// When a canary percentage is passed, the policy is only applied to a subset of the changed
+	// groups, with the rollout returned to the caller. The response is null if the policy was
+	// applied to all groups.
+	if pct > 0 {
+		ro, err := p.startRollout(r.Context(), job, jobPolicy, pct)
+		if err != nil {
+			p.logger.Error().Err(err).Msg("failed to start policy rollout")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		bytes, err := json.Marshal(ro)
+		if err != nil {
+			p.logger.Error().Err(err).Msg(marshalRespFailureMsg)
+			http.Error(w, marshalRespFailureMsg, http.StatusInternalServerError)
+			return
+		}
+		writeJSONResponse(w, bytes, http.StatusCreated)
+		return
+	}
+
+	if err := p.backend.PutJobPolicy(r.Context(), job, jobPolicy); err != nil {
 		p.logger.Error().Err(err).Msg("failed to call policy backend")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	p.cancelRollout(job)
 
 	w.WriteHeader(http.StatusCreated)
 }
@@ -141,10 +180,11 @@ func (p *Policy) PutJobGroupPolicy(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := p.backend.PutJobGroupPolicy(job, group, groupPolicy); err != nil {
+	if err := p.backend.PutJobGroupPolicy(r.Context(), job, group, groupPolicy); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	p.cancelRollout(job)
 
 	w.WriteHeader(http.StatusCreated)
 }
@@ -155,10 +195,11 @@ func (p *Policy) DeleteJobGroupPolicy(w http.ResponseWriter, r *http.Request) {
 	job := vars["job_id"]
 	group := vars["group"]
 
-	if err := p.backend.DeleteJobGroupPolicy(job, group); err != nil {
+	if err := p.backend.DeleteJobGroupPolicy(r.Context(), job, group); err != nil {
 		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return
 	}
+	p.cancelRollout(job)
 	w.WriteHeader(http.StatusNoContent)
 }
 
@@ -167,13 +208,20 @@ func (p *Policy) DeleteJobPolicy(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	job := vars["job_id"]
 
-	if err := p.backend.DeleteJobPolicy(job); err != nil {
+	if err := p.backend.DeleteJobPolicy(r.Context(), job); err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	p.cancelRollout(job)
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// GetSchema returns the JSON Schema describing scaling policy documents, allowing editors and CI
+// pipelines to validate policy files before they are submitted.
+func (p *Policy) GetSchema(w http.ResponseWriter, r *http.Request) {
+	writeJSONResponse(w, policy.Schema(), http.StatusOK)
+}
+
 func writeJSONResponse(w http.ResponseWriter, bytes []byte, statusCode int) {
 	w.Header().Set("Content-Type", "application/json; charset=utf-8")
 	w.WriteHeader(statusCode)
@@ -189,6 +237,10 @@ func decodeGroupPolicyReqBodyAndValidate(body []byte) (*policy.GroupScalingPolic
 		return nil, errors.Wrap(err, "failed to unmarshal request body")
 	}
 
+	if err := policy.ValidateGroupDocument(body); err != nil {
+		return nil, err
+	}
+
 	if err := p.Validate(); err != nil {
 		return nil, errors.Wrap(err, "failed to validate policy document")
 	}
@@ -202,6 +254,10 @@ func decodeJobPolicyReqBodyAndValidate(body []byte) (map[string]*policy.GroupSca
 		return nil, errors.Wrap(err, "failed to unmarshal request body")
 	}
 
+	if err := policy.ValidateJobDocument(body); err != nil {
+		return nil, err
+	}
+
 	for _, pol := range p {
 		if err := pol.Validate(); err != nil {
 			return nil, errors.Wrap(err, "failed to validate policy document")
@@ -1,6 +1,7 @@
 package v1
 
 import (
+	"errors"
 	"testing"
 
 	"github.com/jrasell/sherpa/pkg/policy"
@@ -25,6 +26,11 @@ func Test_decodeGroupPolicyReqBodyAndValidate(t *testing.T) {
 			},
 			expectedErr: nil,
 		},
+		{
+			body:           []byte("{\"maxcount\":10,\"MinCount\":2,\"Enabled\":true}"),
+			expectedPolicy: nil,
+			expectedErr:    errors.New("policy document does not match schema: maxcount: unknown property"),
+		},
 	}
 
 	for _, tc := range testCases {
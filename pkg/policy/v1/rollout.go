@@ -0,0 +1,260 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/pkg/errors"
+)
+
+// errRolloutsDisabled is returned when a canary percentage is passed to a server which has
+// rollouts disabled.
+var errRolloutsDisabled = errors.New("canary_percentage is not supported when running in HA mode, as rollouts are not persisted")
+
+// Rollout is a change to the scaling policy of a job which has been applied to a subset of the
+// changed groups, the canaries, while the remaining changed groups continue to use their previous
+// policy until the rollout is promoted. This limits the impact of mistakes, such as incorrect
+// thresholds, to the canary groups.
+type Rollout struct {
+
+	// Percentage is the percentage of the changed groups which were selected as canaries.
+	Percentage int
+
+	// CanaryGroups are the groups which the new policy has been applied to, and PendingGroups are
+	// the changed groups which will receive the new policy when the rollout is promoted.
+	CanaryGroups  []string
+	PendingGroups []string
+
+	// Policy is the new scaling policy of the job, and Previous is the policy which was in place
+	// when the rollout started.
+	Policy   map[string]*policy.GroupScalingPolicy
+	Previous map[string]*policy.GroupScalingPolicy
+
+	// StartTime is when the rollout was started.
+	StartTime time.Time
+}
+
+// rollouts holds the in-progress policy rollouts, keyed by the job.
+type rollouts struct {
+	lock sync.Mutex
+	jobs map[string]*Rollout
+}
+
+func newRollouts() *rollouts {
+	return &rollouts{jobs: make(map[string]*Rollout)}
+}
+
+func (r *rollouts) get(job string) *Rollout {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.jobs[job]
+}
+
+func (r *rollouts) set(job string, ro *Rollout) {
+	r.lock.Lock()
+	r.jobs[job] = ro
+	r.lock.Unlock()
+}
+
+// remove deletes the rollout of the job, returning whether one was in progress.
+func (r *rollouts) remove(job string) bool {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	_, ok := r.jobs[job]
+	delete(r.jobs, job)
+	return ok
+}
+
+func (r *rollouts) list() map[string]*Rollout {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	out := make(map[string]*Rollout, len(r.jobs))
+	for job, ro := range r.jobs {
+		out[job] = ro
+	}
+	return out
+}
+
+// DisableRollouts causes policy writes which pass a canary percentage to be refused. Rollouts are
+// only held in memory by the server which started them, so this is called when running in HA mode,
+// where a leadership change would leave the canary groups with no rollout to promote or abort.
+func (p *Policy) DisableRollouts() { p.rolloutsDisabled = true }
+
+// parseCanaryPercentage reads the canary_percentage query param, returning 0 if it is not set.
+func parseCanaryPercentage(r *http.Request) (int, error) {
+	param := r.URL.Query().Get("canary_percentage")
+	if param == "" {
+		return 0, nil
+	}
+
+	pct, err := strconv.Atoi(param)
+	if err != nil || pct < 1 || pct > 100 {
+		return 0, errors.Errorf("invalid canary_percentage %q, must be an integer between 1 and 100", param)
+	}
+	return pct, nil
+}
+
+// changedGroups returns the sorted groups whose policy differs between the previous and new job
+// policies, including groups which have been added or removed.
+func changedGroups(previous, updated map[string]*policy.GroupScalingPolicy) []string {
+	var out []string
+
+	for group, pol := range updated {
+		if prev, ok := previous[group]; !ok || !reflect.DeepEqual(prev, pol) {
+			out = append(out, group)
+		}
+	}
+	for group := range previous {
+		if _, ok := updated[group]; !ok {
+			out = append(out, group)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// newRollout builds the rollout of the updated job policy, selecting the canaries from the
+// changed groups in name order. At least one changed group is always selected. Nil is returned if
+// the rollout would apply to every changed group, as the policy can be applied in full.
+func newRollout(previous, updated map[string]*policy.GroupScalingPolicy, pct int, t time.Time) *Rollout {
+	changed := changedGroups(previous, updated)
+
+	canaries := (len(changed)*pct + 99) / 100
+	if canaries < 1 {
+		canaries = 1
+	}
+	if canaries >= len(changed) {
+		return nil
+	}
+
+	return &Rollout{
+		Percentage:    pct,
+		CanaryGroups:  changed[:canaries],
+		PendingGroups: changed[canaries:],
+		Policy:        updated,
+		Previous:      previous,
+		StartTime:     t,
+	}
+}
+
+// applyGroups writes the policy of each group within the job policy, deleting the policies of
+// groups which are not present.
+func (p *Policy) applyGroups(ctx context.Context, job string, groups []string, jobPolicy map[string]*policy.GroupScalingPolicy) error {
+	for _, group := range groups {
+		pol, ok := jobPolicy[group]
+		if !ok {
+			if err := p.backend.DeleteJobGroupPolicy(ctx, job, group); err != nil {
+				return errors.Wrapf(err, "failed to delete policy of group %s", group)
+			}
+			continue
+		}
+		if err := p.backend.PutJobGroupPolicy(ctx, job, group, pol); err != nil {
+			return errors.Wrapf(err, "failed to write policy of group %s", group)
+		}
+	}
+	return nil
+}
+
+// startRollout applies the job policy to the canary groups, holding the rollout until it is
+// promoted or aborted. If every changed group would be a canary, the policy is applied in full
+// and nil is returned.
+func (p *Policy) startRollout(ctx context.Context, job string, jobPolicy map[string]*policy.GroupScalingPolicy, pct int) (*Rollout, error) {
+	previous, err := p.backend.GetJobPolicy(ctx, job)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read current job policy")
+	}
+
+	// The backend may return the policy it holds, which changes as the canary groups are written.
+	current := make(map[string]*policy.GroupScalingPolicy, len(previous))
+	for group, pol := range previous {
+		current[group] = pol
+	}
+
+	ro := newRollout(current, jobPolicy, pct, time.Now().UTC())
+	if ro == nil {
+		p.rollouts.remove(job)
+		return nil, p.backend.PutJobPolicy(ctx, job, jobPolicy)
+	}
+
+	if err := p.applyGroups(ctx, job, ro.CanaryGroups, jobPolicy); err != nil {
+		return nil, err
+	}
+	p.rollouts.set(job, ro)
+
+	p.logger.Info().
+		Str("job", job).
+		Strs("canary-groups", ro.CanaryGroups).
+		Strs("pending-groups", ro.PendingGroups).
+		Msg("started scaling policy rollout")
+	return ro, nil
+}
+
+// cancelRollout discards any rollout of the job, as its policy has been changed directly.
+func (p *Policy) cancelRollout(job string) {
+	if p.rollouts.remove(job) {
+		p.logger.Info().Str("job", job).Msg("scaling policy changed directly, cancelled policy rollout")
+	}
+}
+
+// GetRollouts returns the in-progress policy rollouts, keyed by the job.
+func (p *Policy) GetRollouts(w http.ResponseWriter, r *http.Request) {
+	bytes, err := json.Marshal(p.rollouts.list())
+	if err != nil {
+		p.logger.Error().Err(err).Msg(marshalRespFailureMsg)
+		http.Error(w, marshalRespFailureMsg, http.StatusInternalServerError)
+		return
+	}
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// PromoteRollout applies the new policy of the job rollout to all of its groups.
+func (p *Policy) PromoteRollout(w http.ResponseWriter, r *http.Request) {
+	job := mux.Vars(r)["job_id"]
+
+	ro := p.rollouts.get(job)
+	if ro == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := p.backend.PutJobPolicy(r.Context(), job, ro.Policy); err != nil {
+		p.logger.Error().Err(err).Msg("failed to call policy backend")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.rollouts.remove(job)
+
+	p.logger.Info().Str("job", job).Msg("promoted scaling policy rollout")
+	w.WriteHeader(http.StatusCreated)
+}
+
+// DeleteRollout aborts the job rollout, restoring the previous policy of the canary groups.
+func (p *Policy) DeleteRollout(w http.ResponseWriter, r *http.Request) {
+	job := mux.Vars(r)["job_id"]
+
+	ro := p.rollouts.get(job)
+	if ro == nil {
+		http.NotFound(w, r)
+		return
+	}
+
+	if err := p.applyGroups(r.Context(), job, ro.CanaryGroups, ro.Previous); err != nil {
+		p.logger.Error().Err(err).Msg("failed to call policy backend")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	p.rollouts.remove(job)
+
+	p.logger.Info().Str("job", job).Msg("aborted scaling policy rollout")
+	w.WriteHeader(http.StatusNoContent)
+}
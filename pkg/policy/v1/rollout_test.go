@@ -0,0 +1,171 @@
+package v1
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/policy/backend/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_newRollout(t *testing.T) {
+	previous := map[string]*policy.GroupScalingPolicy{
+		"api":    {Enabled: true, MaxCount: 10},
+		"cache":  {Enabled: true, MaxCount: 10},
+		"db":     {Enabled: true, MaxCount: 10},
+		"legacy": {Enabled: true, MaxCount: 10},
+	}
+	updated := map[string]*policy.GroupScalingPolicy{
+		"api":    {Enabled: true, MaxCount: 20},
+		"cache":  {Enabled: true, MaxCount: 20},
+		"db":     {Enabled: true, MaxCount: 10},
+		"worker": {Enabled: true, MaxCount: 20},
+	}
+
+	testCases := []struct {
+		pct              int
+		expectedCanaries []string
+		expectedPending  []string
+		name             string
+	}{
+		{
+			pct:              10,
+			expectedCanaries: []string{"api"},
+			expectedPending:  []string{"cache", "legacy", "worker"},
+			name:             "at least one canary group",
+		},
+		{
+			pct:              50,
+			expectedCanaries: []string{"api", "cache"},
+			expectedPending:  []string{"legacy", "worker"},
+			name:             "half of the changed groups",
+		},
+		{
+			pct:              60,
+			expectedCanaries: []string{"api", "cache", "legacy"},
+			expectedPending:  []string{"worker"},
+			name:             "canary count rounded up",
+		},
+	}
+
+	for _, tc := range testCases {
+		ro := newRollout(previous, updated, tc.pct, time.Time{})
+		assert.Equal(t, tc.expectedCanaries, ro.CanaryGroups, tc.name)
+		assert.Equal(t, tc.expectedPending, ro.PendingGroups, tc.name)
+	}
+
+	// A rollout covering all changed groups is applied in full.
+	assert.Nil(t, newRollout(previous, updated, 100, time.Time{}))
+	assert.Nil(t, newRollout(previous, previous, 50, time.Time{}))
+}
+
+func Test_parseCanaryPercentage(t *testing.T) {
+	pct, err := parseCanaryPercentage(httptest.NewRequest("POST", "/v1/policy/example", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 0, pct)
+
+	pct, err = parseCanaryPercentage(httptest.NewRequest("POST", "/v1/policy/example?canary_percentage=25", nil))
+	assert.Nil(t, err)
+	assert.Equal(t, 25, pct)
+
+	_, err = parseCanaryPercentage(httptest.NewRequest("POST", "/v1/policy/example?canary_percentage=0", nil))
+	assert.Error(t, err)
+}
+
+func TestPolicy_Rollout(t *testing.T) {
+	b := memory.NewJobScalingPolicies()
+	p := NewPolicyServer(zerolog.Nop(), b)
+
+	putJobPolicy := func(body, query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/v1/policy/example"+query, strings.NewReader(body))
+		r = mux.SetURLVars(r, map[string]string{"job_id": "example"})
+		w := httptest.NewRecorder()
+		p.PutJobPolicy(w, r)
+		return w
+	}
+	maxCounts := func() map[string]int {
+		out := make(map[string]int)
+		pols, err := b.GetJobPolicy(context.Background(), "example")
+		assert.Nil(t, err)
+		for group, pol := range pols {
+			out[group] = pol.MaxCount
+		}
+		return out
+	}
+	jobVars := map[string]string{"job_id": "example"}
+
+	assert.Equal(t, http.StatusCreated, putJobPolicy(`{"api":{"Enabled":true,"MaxCount":10},"cache":{"Enabled":true,"MaxCount":10}}`, "").Code)
+
+	// The changed policy is only applied to the canary group.
+	w := putJobPolicy(`{"api":{"Enabled":true,"MaxCount":20},"cache":{"Enabled":true,"MaxCount":20}}`, "?canary_percentage=50")
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var ro Rollout
+	assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &ro))
+	assert.Equal(t, []string{"api"}, ro.CanaryGroups)
+	assert.Equal(t, []string{"cache"}, ro.PendingGroups)
+	assert.Equal(t, map[string]int{"api": 20, "cache": 10}, maxCounts())
+
+	w = httptest.NewRecorder()
+	p.GetRollouts(w, httptest.NewRequest("GET", "/v1/policies/rollouts", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"example"`)
+
+	// Aborting the rollout restores the previous policy of the canary group.
+	w = httptest.NewRecorder()
+	p.DeleteRollout(w, mux.SetURLVars(httptest.NewRequest("DELETE", "/v1/policies/rollouts/example", nil), jobVars))
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, map[string]int{"api": 10, "cache": 10}, maxCounts())
+
+	w = httptest.NewRecorder()
+	p.DeleteRollout(w, mux.SetURLVars(httptest.NewRequest("DELETE", "/v1/policies/rollouts/example", nil), jobVars))
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// Promoting the rollout applies the policy to all groups.
+	assert.Equal(t, http.StatusCreated, putJobPolicy(`{"api":{"Enabled":true,"MaxCount":20},"cache":{"Enabled":true,"MaxCount":20}}`, "?canary_percentage=50").Code)
+
+	w = httptest.NewRecorder()
+	p.PromoteRollout(w, mux.SetURLVars(httptest.NewRequest("POST", "/v1/policies/rollouts/example/promote", nil), jobVars))
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, map[string]int{"api": 20, "cache": 20}, maxCounts())
+	assert.Nil(t, p.rollouts.get("example"))
+
+	// Writing the job policy directly cancels the rollout.
+	assert.Equal(t, http.StatusCreated, putJobPolicy(`{"api":{"Enabled":true,"MaxCount":30},"cache":{"Enabled":true,"MaxCount":30}}`, "?canary_percentage=50").Code)
+	assert.NotNil(t, p.rollouts.get("example"))
+	assert.Equal(t, http.StatusCreated, putJobPolicy(`{"api":{"Enabled":true,"MaxCount":30},"cache":{"Enabled":true,"MaxCount":30}}`, "").Code)
+	assert.Nil(t, p.rollouts.get("example"))
+}
+
+func TestPolicy_RolloutsDisabled(t *testing.T) {
+	b := memory.NewJobScalingPolicies()
+	p := NewPolicyServer(zerolog.Nop(), b)
+	p.DisableRollouts()
+
+	putJobPolicy := func(query string) *httptest.ResponseRecorder {
+		r := httptest.NewRequest("POST", "/v1/policy/example"+query, strings.NewReader(`{"api":{"Enabled":true,"MaxCount":10}}`))
+		r = mux.SetURLVars(r, map[string]string{"job_id": "example"})
+		w := httptest.NewRecorder()
+		p.PutJobPolicy(w, r)
+		return w
+	}
+
+	// The policy is not written when a canary percentage is passed.
+	w := putJobPolicy("?canary_percentage=50")
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "HA mode")
+
+	pols, err := b.GetJobPolicy(context.Background(), "example")
+	assert.Nil(t, err)
+	assert.Nil(t, pols)
+
+	assert.Equal(t, http.StatusCreated, putJobPolicy("").Code)
+}
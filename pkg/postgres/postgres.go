@@ -0,0 +1,148 @@
+// Package postgres opens the PostgreSQL database used by the PostgreSQL policy and scaling state
+// backends, and manages the schema of the tables they use.
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	// Register the PostgreSQL database/sql driver.
+	_ "github.com/lib/pq"
+	"github.com/pkg/errors"
+)
+
+const (
+	driverName = "postgres"
+
+	// pingTimeout is the time allowed for the initial connection to the database.
+	pingTimeout = 10 * time.Second
+
+	// migrationLockID is the transaction scoped advisory lock held while the schema is migrated,
+	// so that servers starting at the same time do not apply the same migration.
+	migrationLockID = 0x5e4a5a
+)
+
+// Config is the configuration of the PostgreSQL connection pool.
+type Config struct {
+	// URL is the libpq connection string or URL of the database.
+	URL string
+
+	// MaxOpenConns and MaxIdleConns bound the number of open and idle connections in the pool, and
+	// ConnMaxLifetime is the time after which a connection is closed and replaced. Zero values
+	// mean no limit.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+// migrations are the statements which create and update the schema, applied in order. Each entry
+// is applied once, and the number of applied entries is recorded as the schema version, so
+// existing entries must never be changed; schema changes are made by appending an entry.
+var migrations = []string{
+	`CREATE TABLE sherpa_policies (
+		job_name   TEXT NOT NULL,
+		group_name TEXT NOT NULL,
+		policy     TEXT NOT NULL,
+		PRIMARY KEY (job_name, group_name)
+	);
+	CREATE TABLE sherpa_scaling_events (
+		id        UUID   NOT NULL,
+		job_group TEXT   NOT NULL,
+		time      BIGINT NOT NULL,
+		event     TEXT   NOT NULL,
+		PRIMARY KEY (id, job_group)
+	);
+	CREATE INDEX sherpa_scaling_events_time ON sherpa_scaling_events (time);
+	CREATE TABLE sherpa_latest_scaling_events (
+		job_group TEXT PRIMARY KEY,
+		id        UUID NOT NULL,
+		event     TEXT NOT NULL
+	);
+	CREATE TABLE sherpa_cooldowns (
+		job_group TEXT PRIMARY KEY,
+		time      BIGINT NOT NULL
+	);`,
+	`CREATE INDEX sherpa_scaling_events_job_group ON sherpa_scaling_events (job_group text_pattern_ops);`,
+}
+
+// Open connects to the database using the passed config, and migrates the schema to the version
+// required by this version of Sherpa.
+func Open(cfg *Config) (*sql.DB, error) {
+	db, err := sql.Open(driverName, cfg.URL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to open PostgreSQL database")
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	ctx, cancel := context.WithTimeout(context.Background(), pingTimeout)
+	defer cancel()
+
+	if err := db.PingContext(ctx); err != nil {
+		_ = db.Close()
+		return nil, errors.Wrap(err, "failed to connect to PostgreSQL database")
+	}
+
+	if err := Migrate(context.Background(), db); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// Migrate applies the schema migrations which have not yet been applied to the database. An
+// error is returned if the database schema is newer than this version of Sherpa supports.
+func Migrate(ctx context.Context, db *sql.DB) error {
+	return WithTx(ctx, db, func(tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, `SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+			return errors.Wrap(err, "failed to lock PostgreSQL schema")
+		}
+
+		_, err := tx.ExecContext(ctx, `CREATE TABLE IF NOT EXISTS sherpa_schema_migrations (
+			version    INTEGER PRIMARY KEY,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+		)`)
+		if err != nil {
+			return errors.Wrap(err, "failed to create PostgreSQL schema migrations table")
+		}
+
+		var version int
+		if err := tx.QueryRowContext(ctx, `SELECT COALESCE(MAX(version), 0) FROM sherpa_schema_migrations`).Scan(&version); err != nil {
+			return errors.Wrap(err, "failed to read PostgreSQL schema version")
+		}
+		if version > len(migrations) {
+			return errors.Errorf("PostgreSQL schema version %d is newer than the supported version %d", version, len(migrations))
+		}
+
+		for i := version; i < len(migrations); i++ {
+			if _, err := tx.ExecContext(ctx, migrations[i]); err != nil {
+				return errors.Wrapf(err, "failed to apply PostgreSQL schema migration %d", i+1)
+			}
+			if _, err := tx.ExecContext(ctx, `INSERT INTO sherpa_schema_migrations (version) VALUES ($1)`, i+1); err != nil {
+				return errors.Wrapf(err, "failed to record PostgreSQL schema migration %d", i+1)
+			}
+		}
+		return nil
+	})
+}
+
+// WithTx runs the function within a transaction, which is committed if the function succeeds and
+// rolled back otherwise.
+func WithTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin PostgreSQL transaction")
+	}
+
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return errors.Wrap(err, "failed to commit PostgreSQL transaction")
+	}
+	return nil
+}
@@ -0,0 +1,36 @@
+package postgres_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/postgres"
+	"github.com/jrasell/sherpa/pkg/postgres/postgrestest"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestMigrate runs against a PostgreSQL database, and is skipped unless the
+// SHERPA_TEST_POSTGRES_URL environment variable is set.
+func TestMigrate(t *testing.T) {
+	postgrestest.SkipUnlessEnabled(t)
+
+	db, cleanup := postgrestest.NewDB(t)
+	defer cleanup()
+	ctx := context.Background()
+
+	// The database is migrated when opened, so migrating again applies nothing.
+	assert.Nil(t, postgres.Migrate(ctx, db))
+
+	var count int
+	assert.Nil(t, db.QueryRow(`SELECT COUNT(*) FROM sherpa_schema_migrations`).Scan(&count))
+	assert.Equal(t, 1, count)
+
+	// A schema written by a newer version of Sherpa is rejected.
+	_, err := db.Exec(`INSERT INTO sherpa_schema_migrations (version) VALUES (100)`)
+	assert.Nil(t, err)
+
+	err = postgres.Migrate(ctx, db)
+	if assert.NotNil(t, err) {
+		assert.Contains(t, err.Error(), "PostgreSQL schema version 100 is newer than the supported version 1")
+	}
+}
@@ -0,0 +1,59 @@
+// Package postgrestest provides the PostgreSQL databases used by the tests of the PostgreSQL
+// backends. The tests are skipped unless the SHERPA_TEST_POSTGRES_URL environment variable is set
+// to the URL of a database the tests can create schemas within.
+package postgrestest
+
+import (
+	"database/sql"
+	"net/url"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/postgres"
+)
+
+// EnvURL is the environment variable holding the URL of the test database.
+const EnvURL = "SHERPA_TEST_POSTGRES_URL"
+
+// SkipUnlessEnabled skips the test unless the test database URL is set.
+func SkipUnlessEnabled(t *testing.T) {
+	if os.Getenv(EnvURL) == "" {
+		t.Skip(EnvURL + " not set, skipping PostgreSQL tests")
+	}
+}
+
+// NewDB creates a schema holding no state, and returns a migrated database using it along with a
+// function which drops the schema and closes the database.
+func NewDB(t *testing.T) (*sql.DB, func()) {
+	// The schema is created without migrating the database the URL refers to.
+	admin, err := sql.Open("postgres", os.Getenv(EnvURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	schema := "sherpa_test_" + strings.Replace(uuid.Must(uuid.NewV4()).String(), "-", "", -1)
+	if _, err := admin.Exec(`CREATE SCHEMA ` + schema); err != nil {
+		t.Fatal(err)
+	}
+
+	u, err := url.Parse(os.Getenv(EnvURL))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	q.Set("search_path", schema)
+	u.RawQuery = q.Encode()
+
+	db, err := postgres.Open(&postgres.Config{URL: u.String(), MaxOpenConns: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return db, func() {
+		_ = db.Close()
+		_, _ = admin.Exec(`DROP SCHEMA ` + schema + ` CASCADE`)
+		_ = admin.Close()
+	}
+}
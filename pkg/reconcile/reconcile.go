@@ -0,0 +1,287 @@
+// Package reconcile performs a reconciliation pass of the stored scaling state against the jobs
+// running on the Nomad cluster. This is run when a server obtains leadership, so that internal
+// tracking is re-established and anomalies are reported before the autoscaler starts evaluating.
+package reconcile
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	policyBackend "github.com/jrasell/sherpa/pkg/policy/backend"
+	"github.com/jrasell/sherpa/pkg/state"
+	stateBackend "github.com/jrasell/sherpa/pkg/state/scale"
+	"github.com/rs/zerolog"
+)
+
+// Anomaly describes a difference identified between the stored scaling state and the running job.
+type Anomaly string
+
+const (
+	// AnomalyJobNotFound means a scaling policy exists for a job which is not registered on the
+	// Nomad cluster.
+	AnomalyJobNotFound Anomaly = "job-not-found"
+
+	// AnomalyGroupNotFound means a scaling policy exists for a group which is not part of the
+	// running job.
+	AnomalyGroupNotFound Anomaly = "group-not-found"
+
+	// AnomalyCountOutOfBounds means the running group count is outside the min and max counts of
+	// the group scaling policy.
+	AnomalyCountOutOfBounds Anomaly = "count-out-of-bounds"
+
+	// AnomalyCountDrift means the running group count does not match the count stored by the
+	// latest completed scaling event, indicating the job was modified outside of Sherpa.
+	AnomalyCountDrift Anomaly = "count-drift"
+
+	// AnomalyEventInFuture means the latest scaling event has a timestamp in the future, which
+	// would hold the group in cooldown for longer than configured.
+	AnomalyEventInFuture Anomaly = "event-in-future"
+)
+
+func (a Anomaly) String() string { return string(a) }
+
+// Finding is a single anomaly identified during the reconciliation pass.
+type Finding struct {
+	Job     string
+	Group   string
+	Anomaly Anomaly
+	Detail  string
+}
+
+// Config is the configuration used to build a Reconciler.
+type Config struct {
+	Logger        zerolog.Logger
+	Nomad         *client.NomadPool
+	NomadTimeout  time.Duration
+	PolicyBackend policyBackend.PolicyBackend
+	StateBackend  stateBackend.Backend
+
+	// DeploymentChan is the scaler deployment channel. Running deployments found during the pass
+	// are sent here so that the scaler tracks them without waiting for the deployment watcher.
+	DeploymentChan chan interface{}
+}
+
+// Reconciler compares the stored scaling state with the running Nomad jobs.
+type Reconciler struct {
+	logger         zerolog.Logger
+	nomad          *client.NomadPool
+	nomadTimeout   time.Duration
+	policies       policyBackend.PolicyBackend
+	state          stateBackend.Backend
+	deploymentChan chan interface{}
+}
+
+// NewReconciler returns a new Reconciler using the passed config.
+func NewReconciler(cfg *Config) *Reconciler {
+	return &Reconciler{
+		logger:         cfg.Logger,
+		nomad:          cfg.Nomad,
+		nomadTimeout:   cfg.NomadTimeout,
+		policies:       cfg.PolicyBackend,
+		state:          cfg.StateBackend,
+		deploymentChan: cfg.DeploymentChan,
+	}
+}
+
+// Run performs the reconciliation pass, returning the anomalies identified. Errors reading the
+// stored state or calling Nomad are logged, and the affected jobs skipped, so that the pass never
+// blocks the server from starting its leader processes.
+func (r *Reconciler) Run() []*Finding {
+	defer sendMetrics.MeasureSince([]string{"reconcile", "run"}, time.Now())
+
+	r.logger.Info().Msg("starting scaling state reconciliation")
+
+	policies, err := r.policies.GetPolicies(context.Background())
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to read scaling policies for reconciliation")
+		return nil
+	}
+
+	latest, err := r.state.GetLatestScalingEvents()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("failed to read latest scaling events for reconciliation")
+		return nil
+	}
+
+	now := time.Now().UTC().UnixNano()
+
+	var findings []*Finding // nolint:prealloc
+
+	for _, job := range sortedJobs(policies) {
+		nomadJob, err := r.getJob(job)
+		if err != nil {
+			if isNotFound(err) {
+				findings = append(findings, &Finding{Job: job, Anomaly: AnomalyJobNotFound,
+					Detail: "job has scaling policies but is not registered on Nomad"})
+				continue
+			}
+			r.logger.Error().Str("job", job).Err(err).Msg("failed to read job for reconciliation")
+			continue
+		}
+
+		findings = append(findings, reconcileJob(job, nomadJob, policies[job], latest, now)...)
+		r.logCooldowns(job, policies[job], latest, now)
+		r.trackDeployment(job)
+	}
+
+	for _, f := range findings {
+		r.logger.Warn().
+			Str("job", f.Job).
+			Str("group", f.Group).
+			Str("anomaly", f.Anomaly.String()).
+			Msg(f.Detail)
+		sendAnomalyMetrics(f)
+	}
+
+	r.logger.Info().
+		Int("jobs", len(policies)).
+		Int("anomalies", len(findings)).
+		Msg("completed scaling state reconciliation")
+
+	return findings
+}
+
+// reconcileJob compares the running job with the group policies and latest scaling events of the
+// job, returning any anomalies found.
+func reconcileJob(job string, nomadJob *api.Job, groups map[string]*policy.GroupScalingPolicy,
+	latest map[string]*state.ScalingEvent, now int64) []*Finding {
+	var findings []*Finding // nolint:prealloc
+
+	counts := make(map[string]int, len(nomadJob.TaskGroups))
+	for _, tg := range nomadJob.TaskGroups {
+		if tg.Name != nil && tg.Count != nil {
+			counts[*tg.Name] = *tg.Count
+		}
+	}
+
+	for _, group := range sortedGroups(groups) {
+		count, ok := counts[group]
+		if !ok {
+			findings = append(findings, &Finding{Job: job, Group: group, Anomaly: AnomalyGroupNotFound,
+				Detail: "group has a scaling policy but is not part of the running job"})
+			continue
+		}
+
+		if pol := groups[group]; pol != nil && pol.Enabled && (count < pol.MinCount || count > pol.MaxCount) {
+			findings = append(findings, &Finding{Job: job, Group: group, Anomaly: AnomalyCountOutOfBounds,
+				Detail: "running group count is outside the bounds of the scaling policy"})
+		}
+
+		event, ok := latest[job+":"+group]
+		if !ok || event == nil {
+			continue
+		}
+
+		if event.Time > now {
+			findings = append(findings, &Finding{Job: job, Group: group, Anomaly: AnomalyEventInFuture,
+				Detail: "latest scaling event has a timestamp in the future"})
+		}
+
+		// Events written before the desired count was stored have a zero value, and so cannot be
+		// checked for drift.
+		if event.Status == state.StatusCompleted && event.Details.DesiredCount > 0 &&
+			event.Details.DesiredCount != count {
+			findings = append(findings, &Finding{Job: job, Group: group, Anomaly: AnomalyCountDrift,
+				Detail: "running group count does not match the latest scaling event"})
+		}
+	}
+	return findings
+}
+
+// logCooldowns logs the groups of the job which are still within their cooldown period, so that
+// operators can see why scaling does not occur immediately after a leadership change.
+func (r *Reconciler) logCooldowns(job string, groups map[string]*policy.GroupScalingPolicy,
+	latest map[string]*state.ScalingEvent, now int64) {
+	for _, group := range sortedGroups(groups) {
+		pol, event := groups[group], latest[job+":"+group]
+		if pol == nil || event == nil {
+			continue
+		}
+
+		remaining := time.Duration(event.Time + int64(pol.Cooldown)*int64(time.Second) - now)
+		if remaining > 0 {
+			r.logger.Info().
+				Str("job", job).
+				Str("group", group).
+				Dur("remaining", remaining).
+				Msg("job group is within scaling cooldown")
+		}
+	}
+}
+
+// trackDeployment sends the latest deployment of the job to the scaler if it is running, so that
+// groups in deployment are not scaled before the deployment watcher has caught up.
+func (r *Reconciler) trackDeployment(job string) {
+	if r.deploymentChan == nil {
+		return
+	}
+
+	var deployment *api.Deployment
+
+	ctx, cancel := helper.ContextWithTimeout(context.Background(), r.nomadTimeout)
+	defer cancel()
+
+	err := r.nomad.Call(ctx, func() (err error) {
+		deployment, _, err = r.nomad.Client().Jobs().LatestDeployment(job, nil)
+		return err
+	})
+	if err != nil {
+		r.logger.Error().Str("job", job).Err(err).Msg("failed to read latest job deployment")
+		return
+	}
+
+	if deployment != nil && deployment.Status == "running" {
+		r.deploymentChan <- deployment
+	}
+}
+
+func (r *Reconciler) getJob(job string) (*api.Job, error) {
+	var nomadJob *api.Job
+
+	ctx, cancel := helper.ContextWithTimeout(context.Background(), r.nomadTimeout)
+	defer cancel()
+
+	err := r.nomad.Call(ctx, func() (err error) {
+		nomadJob, _, err = r.nomad.Client().Jobs().Info(job, nil)
+		return err
+	})
+	return nomadJob, err
+}
+
+// isNotFound identifies whether the Nomad API error was a result of the job not being found.
+func isNotFound(err error) bool {
+	return strings.Contains(err.Error(), "404")
+}
+
+func sortedJobs(policies map[string]map[string]*policy.GroupScalingPolicy) []string {
+	jobs := make([]string, 0, len(policies))
+	for job := range policies {
+		jobs = append(jobs, job)
+	}
+	sort.Strings(jobs)
+	return jobs
+}
+
+func sortedGroups(groups map[string]*policy.GroupScalingPolicy) []string {
+	names := make([]string, 0, len(groups))
+	for group := range groups {
+		names = append(names, group)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sendAnomalyMetrics(f *Finding) {
+	sendMetrics.IncrCounterWithLabels([]string{"reconcile", "anomaly"}, 1, []sendMetrics.Label{
+		{Name: "job", Value: f.Job},
+		{Name: "group", Value: f.Group},
+		{Name: "anomaly", Value: f.Anomaly.String()},
+	})
+}
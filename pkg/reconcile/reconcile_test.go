@@ -0,0 +1,90 @@
+package reconcile
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_reconcileJob(t *testing.T) {
+	nomadJob := &api.Job{TaskGroups: []*api.TaskGroup{
+		{Name: helper.StringToPointer("cache"), Count: helper.IntToPointer(3)},
+	}}
+	pol := &policy.GroupScalingPolicy{Enabled: true, MinCount: 1, MaxCount: 5}
+
+	testCases := []struct {
+		groups         map[string]*policy.GroupScalingPolicy
+		latest         map[string]*state.ScalingEvent
+		expectedOutput []Anomaly
+		name           string
+	}{
+		{
+			groups:         map[string]*policy.GroupScalingPolicy{"cache": pol},
+			latest:         map[string]*state.ScalingEvent{},
+			expectedOutput: nil,
+			name:           "group without scaling events",
+		},
+		{
+			groups:         map[string]*policy.GroupScalingPolicy{"proxy": pol},
+			latest:         map[string]*state.ScalingEvent{},
+			expectedOutput: []Anomaly{AnomalyGroupNotFound},
+			name:           "group not part of running job",
+		},
+		{
+			groups:         map[string]*policy.GroupScalingPolicy{"cache": {Enabled: true, MinCount: 4, MaxCount: 10}},
+			latest:         map[string]*state.ScalingEvent{},
+			expectedOutput: []Anomaly{AnomalyCountOutOfBounds},
+			name:           "count below policy minimum",
+		},
+		{
+			groups: map[string]*policy.GroupScalingPolicy{"cache": pol},
+			latest: map[string]*state.ScalingEvent{"example:cache": {
+				Time: 50, Status: state.StatusCompleted, Details: state.EventDetails{DesiredCount: 3},
+			}},
+			expectedOutput: nil,
+			name:           "count matches latest event",
+		},
+		{
+			groups: map[string]*policy.GroupScalingPolicy{"cache": pol},
+			latest: map[string]*state.ScalingEvent{"example:cache": {
+				Time: 50, Status: state.StatusCompleted, Details: state.EventDetails{DesiredCount: 4},
+			}},
+			expectedOutput: []Anomaly{AnomalyCountDrift},
+			name:           "count drifted from latest event",
+		},
+		{
+			groups: map[string]*policy.GroupScalingPolicy{"cache": pol},
+			latest: map[string]*state.ScalingEvent{"example:cache": {
+				Time: 50, Status: state.StatusFailed, Details: state.EventDetails{DesiredCount: 4},
+			}},
+			expectedOutput: nil,
+			name:           "failed latest event is not checked for drift",
+		},
+		{
+			groups: map[string]*policy.GroupScalingPolicy{"cache": pol},
+			latest: map[string]*state.ScalingEvent{"example:cache": {
+				Time: 150, Status: state.StatusCompleted,
+			}},
+			expectedOutput: []Anomaly{AnomalyEventInFuture},
+			name:           "latest event in the future",
+		},
+	}
+
+	for _, tc := range testCases {
+		var actualOutput []Anomaly
+		for _, f := range reconcileJob("example", nomadJob, tc.groups, tc.latest, 100) {
+			actualOutput = append(actualOutput, f.Anomaly)
+		}
+		assert.Equal(t, tc.expectedOutput, actualOutput, tc.name)
+	}
+}
+
+func Test_isNotFound(t *testing.T) {
+	assert.True(t, isNotFound(errors.New("Unexpected response code: 404 (job not found)")))
+	assert.False(t, isNotFound(errors.New("Unexpected response code: 500 (rpc error)")))
+}
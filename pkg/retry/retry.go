@@ -0,0 +1,132 @@
+// Package retry provides the jittered exponential backoff used when retrying calls to external
+// systems such as Nomad, Consul, metric providers and webhooks, so that all retries behave and are
+// reported consistently.
+package retry
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+)
+
+// The targets used to label retry metrics by the external system being called.
+const (
+	TargetConsul   = "consul"
+	TargetHook     = "hook"
+	TargetMetrics  = "metrics"
+	TargetNomad    = "nomad"
+	TargetPostgres = "postgres"
+)
+
+// Policy controls how a failed call is retried.
+type Policy struct {
+
+	// Attempts is the maximum number of times the call is made, including the first attempt. A
+	// value of zero or one means the call is not retried.
+	Attempts int
+
+	// BaseDelay is the delay before the first retry, which doubles with each subsequent retry.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay between retries.
+	MaxDelay time.Duration
+}
+
+// Default is the policy used for calls to external systems which do not require their own.
+var Default = Policy{Attempts: 3, BaseDelay: 200 * time.Millisecond, MaxDelay: 5 * time.Second}
+
+// permanentError wraps an error which should not be retried.
+type permanentError struct {
+	err error
+}
+
+func (p *permanentError) Error() string { return p.err.Error() }
+
+// Cause satisfies the causer interface used by github.com/pkg/errors.
+func (p *permanentError) Cause() error { return p.err }
+
+// Permanent wraps the error so that Do returns it immediately without retrying. This should be
+// used for errors such as client errors, where retrying the call will not produce a different
+// result.
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsPermanent returns whether the error, or any error it wraps, was marked as permanent. This
+// allows callers which retry outside of Do, such as the notification queue, to honour it.
+func IsPermanent(err error) bool {
+	for err != nil {
+		if _, ok := err.(*permanentError); ok {
+			return true
+		}
+		cause, ok := err.(interface{ Cause() error })
+		if !ok {
+			return false
+		}
+		err = cause.Cause()
+	}
+	return false
+}
+
+// Do calls f until it succeeds, returns a permanent error, the policy attempts are exhausted or
+// the context is done, returning the last error from f. The target names the external system
+// being called and is used to label the retry metrics.
+func Do(ctx context.Context, target string, p Policy, f func() error) error {
+	for attempt := 0; ; attempt++ {
+		err := f()
+		if err == nil {
+			return nil
+		}
+
+		if perm, ok := err.(*permanentError); ok {
+			return perm.err
+		}
+
+		if attempt+1 >= p.Attempts || ctx.Err() != nil {
+			if p.Attempts > 1 {
+				sendRetryMetrics("exhausted", target)
+			}
+			return err
+		}
+
+		sendRetryMetrics("attempt", target)
+
+		select {
+		case <-time.After(Backoff(p, attempt)):
+		case <-ctx.Done():
+			return err
+		}
+	}
+}
+
+// Backoff returns the delay before the retry following the passed zero indexed attempt. The delay
+// grows exponentially from the base delay up to the max delay, with jitter applied so that the
+// delay is between half and all of the calculated value. This avoids many callers retrying in
+// lockstep against a recovering system.
+func Backoff(p Policy, attempt int) time.Duration {
+	if p.BaseDelay <= 0 {
+		return 0
+	}
+
+	d := p.BaseDelay
+	for i := 0; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+
+	half := d / 2
+	return half + time.Duration(rand.Int63n(int64(d-half)+1)) // nolint:gosec
+}
+
+func sendRetryMetrics(result, target string) {
+	sendMetrics.IncrCounterWithLabels([]string{"retry", result}, 1, []sendMetrics.Label{
+		{Name: "target", Value: target},
+	})
+}
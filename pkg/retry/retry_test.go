@@ -0,0 +1,76 @@
+package retry
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDo(t *testing.T) {
+	p := Policy{Attempts: 3, BaseDelay: time.Millisecond, MaxDelay: 2 * time.Millisecond}
+	failure := errors.New("connection refused")
+
+	calls := 0
+	assert.Nil(t, Do(context.Background(), TargetNomad, p, func() error {
+		calls++
+		if calls < 3 {
+			return failure
+		}
+		return nil
+	}))
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	assert.Equal(t, failure, Do(context.Background(), TargetNomad, p, func() error { calls++; return failure }))
+	assert.Equal(t, 3, calls)
+
+	calls = 0
+	assert.Equal(t, failure, Do(context.Background(), TargetNomad, p, func() error { calls++; return Permanent(failure) }))
+	assert.Equal(t, 1, calls)
+
+	calls = 0
+	assert.Equal(t, failure, Do(context.Background(), TargetNomad, Policy{}, func() error { calls++; return failure }))
+	assert.Equal(t, 1, calls)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	calls = 0
+	assert.Equal(t, failure, Do(ctx, TargetNomad, p, func() error { calls++; return failure }))
+	assert.Equal(t, 1, calls)
+}
+
+func TestIsPermanent(t *testing.T) {
+	failure := errors.New("failure")
+
+	assert.False(t, IsPermanent(nil))
+	assert.False(t, IsPermanent(failure))
+	assert.True(t, IsPermanent(Permanent(failure)))
+	assert.True(t, IsPermanent(errors.Wrap(Permanent(failure), "wrapped")))
+}
+
+func TestBackoff(t *testing.T) {
+	p := Policy{BaseDelay: 100 * time.Millisecond, MaxDelay: time.Second}
+
+	testCases := []struct {
+		attempt     int
+		expectedMin time.Duration
+		expectedMax time.Duration
+	}{
+		{attempt: 0, expectedMin: 50 * time.Millisecond, expectedMax: 100 * time.Millisecond},
+		{attempt: 2, expectedMin: 200 * time.Millisecond, expectedMax: 400 * time.Millisecond},
+		{attempt: 10, expectedMin: 500 * time.Millisecond, expectedMax: time.Second},
+	}
+
+	for _, tc := range testCases {
+		for i := 0; i < 20; i++ {
+			actual := Backoff(p, tc.attempt)
+			assert.True(t, actual >= tc.expectedMin && actual <= tc.expectedMax, actual)
+		}
+	}
+
+	assert.Equal(t, time.Duration(0), Backoff(Policy{}, 3))
+}
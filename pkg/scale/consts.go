@@ -1,16 +1,61 @@
 package scale
 
 import (
+	"context"
+
 	"github.com/gofrs/uuid"
 	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/config/server"
 	"github.com/jrasell/sherpa/pkg/policy"
 	"github.com/jrasell/sherpa/pkg/state"
 )
 
+// MetaKeyRequestID is the scaling request meta key which details the ID of the API request which
+// triggered the scaling activity. This allows the request to be traced to the Nomad evaluation.
+const MetaKeyRequestID = "request-id"
+
+// MetaKeyCooldownOverride is the scaling request meta key which details the cooldown override
+// used by a manual scaling request, so that overrides can be audited via the scaling events.
+const MetaKeyCooldownOverride = "cooldown-override"
+
+// MetaKeyDeploymentID is the scaling event meta key which details the ID of the deployment, with
+// canaries awaiting promotion, which caused a scale in request to be deferred.
+const MetaKeyDeploymentID = "deployment-id"
+
+// MetaKeyDeferredEventID is the scaling request meta key which details the ID of the scaling event
+// which recorded the deferral of a scale in request, once the request has been submitted.
+const MetaKeyDeferredEventID = "deferred-event-id"
+
+// CooldownOverride allows manual scaling requests to be performed while the job group is in
+// scaling cooldown.
+type CooldownOverride string
+
+const (
+	// CooldownOverrideBypass ignores the job group cooldown. The resulting scaling event starts a
+	// new cooldown as normal, preventing the autoscaler from immediately reverting the change.
+	CooldownOverrideBypass CooldownOverride = "bypass"
+
+	// CooldownOverrideReset ignores and clears the job group cooldown. The resulting scaling event
+	// does not start a new cooldown, so the autoscaler can act on its next evaluation.
+	CooldownOverrideReset CooldownOverride = "reset"
+)
+
+// cooldownResetTime is stored as the cooldown start time of job groups whose cooldown has been
+// reset. It is non-zero, as a zero time results in the latest scaling event being used instead.
+const cooldownResetTime int64 = 1
+
+// Fence is used to ensure only the current cluster leader submits scaling actions to Nomad, so
+// that a brief dual-leader scenario cannot scale a job group twice.
+type Fence interface {
+	// CheckFence returns an error if the server should not perform scaling actions.
+	CheckFence() error
+}
+
 // Scale is the interface used for scaling a Nomad job.
 type Scale interface {
-	// Trigger performs scaling of 1 or more job groups which belong to the same job.
-	Trigger(string, []*GroupReq, state.Source) (*ScalingResponse, int, error)
+	// Trigger performs scaling of 1 or more job groups which belong to the same job. The context
+	// bounds the time spent calling the Nomad API.
+	Trigger(context.Context, string, []*GroupReq, state.Source) (*ScalingResponse, int, error)
 
 	// GetDeploymentChannel is used to return the channel where updates to Nomad deployments should
 	// be sent.
@@ -24,6 +69,26 @@ type Scale interface {
 	// currently in deployment.
 	JobGroupIsDeploying(job, group string) bool
 
+	// SetFence sets the fence which is checked before each scaling action is submitted to Nomad.
+	SetFence(Fence)
+
+	// SetPromotionGuard sets the action taken on scale in requests of job groups whose deployment
+	// has canaries awaiting promotion.
+	SetPromotionGuard(server.PromotionGuard)
+
+	// JobGroupIsAwaitingPromotion checks internal references to identify if the queried job group
+	// has a deployment with canaries awaiting promotion.
+	JobGroupIsAwaitingPromotion(job, group string) bool
+
+	// DefersScaleIn checks whether scale in requests of the queried job group are currently
+	// deferred until its deployment completes, rather than rejected.
+	DefersScaleIn(job, group string) bool
+
+	// RecordSkip records a scaling event for each of the job groups detailing why they were not
+	// evaluated or scaled. The event does not start the group cooldown. The ID of the event is
+	// returned.
+	RecordSkip(job string, groups []string, reason state.Reason, source state.Source, meta map[string]string) uuid.UUID
+
 	// JobGroupIsInCooldown checks whether the job group in question is currently in scaling
 	// cooldown using the input time as the comparison.
 	JobGroupIsInCooldown(job, group string, cooldown int, time int64) (bool, error)
@@ -46,6 +111,19 @@ type GroupReq struct {
 	// populate this field for use and moves this logic away from the trigger.
 	Count int
 
+	// Absolute indicates Count is the exact count the job group should be scaled to, rather than
+	// the number to change the count by. The scaler converts absolute requests into a direction
+	// and change count using the current count of the job group, and resets this field.
+	Absolute bool
+
+	// Force skips the job group scaling policy minimum and maximum count checks. It is only used
+	// by absolute scaling requests from operators.
+	Force bool
+
+	// CooldownOverride, if set, describes how the request was permitted to ignore the job group
+	// scaling cooldown. It is only used by manual scaling requests from operators.
+	CooldownOverride CooldownOverride
+
 	// GroupName is the name of the job group to scale in this request.
 	GroupName string
 
@@ -58,9 +136,21 @@ type GroupReq struct {
 	// can be used.
 	Time int64
 
+	// Reason is the code which describes why the scaling activity was requested. If empty, the
+	// resulting scaling event uses the unknown reason.
+	Reason state.Reason
+
 	// Meta is the meta data which is optionally submitted when requesting a scaling activity for a
 	// job group. This is free-form and can contain any information the user deems relevant.
 	Meta map[string]string
+
+	// DesiredCount is the resulting count of the job group. It is populated by the scaler once the
+	// new count has been calculated and is stored alongside the scaling event.
+	DesiredCount int
+
+	// Snapshot is the data the internal autoscaler acted upon when requesting the scaling
+	// activity, and is stored alongside the scaling event.
+	Snapshot *state.ResourceSnapshot
 }
 
 type ScalingResponse struct {
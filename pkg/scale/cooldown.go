@@ -3,19 +3,30 @@ package scale
 // JobGroupIsInCooldown satisfies the JobGroupIsInCooldown func within the Scale interface.
 func (s *Scaler) JobGroupIsInCooldown(job, group string, cooldown int, time int64) (bool, error) {
 
-	// Pull the latest scaling event for the job group out of the state.
-	last, err := s.state.GetLatestScalingEvent(job, group)
+	// Pull the cooldown timestamp for the job group out of the state.
+	last, err := s.state.GetCooldown(job, group)
 	if err != nil {
 		return true, err
 	}
 
-	// It is possible to return nil for the last event. This means that we were able to call the
-	// backend successfully, but there is no latest event for the job group.
-	if last == nil {
-		return false, nil
+	// State written prior to cooldown timestamps being stored will not have an entry for the job
+	// group, in which case the time of the latest scaling event is used.
+	if last == 0 {
+		event, err := s.state.GetLatestScalingEvent(job, group)
+		if err != nil {
+			return true, err
+		}
+
+		// It is possible to return nil for the last event. This means that we were able to call
+		// the backend successfully, but there is no latest event for the job group. Skip events
+		// record that the group was not changed, so do not start a cooldown.
+		if event == nil || event.Reason.IsSkip() {
+			return false, nil
+		}
+		last = event.Time
 	}
 
-	if (time - int64(cooldown*1000000000)) < last.Time {
+	if (time - int64(cooldown*1000000000)) < last {
 		return true, nil
 	}
 	return false, nil
@@ -18,6 +18,7 @@ func TestScaler_JobGroupIsInCooldown(t *testing.T) {
 		inputCoolDown        int
 		inputTime            int64
 		lastScalingEvent     *state.ScalingEventMessage
+		cooldownTime         int64
 		expectedCooldownResp bool
 		name                 string
 	}{
@@ -65,20 +66,79 @@ func TestScaler_JobGroupIsInCooldown(t *testing.T) {
 				Direction: "in",
 			},
 		},
+		{
+			inputJobName:         "test-job-1",
+			inputGroupName:       "test-group-1",
+			inputCoolDown:        300,
+			inputTime:            helper.GenerateEventTimestamp(),
+			cooldownTime:         helper.GenerateEventTimestamp(),
+			expectedCooldownResp: true,
+			name:                 "stored cooldown timestamp takes precedence over last event",
+			lastScalingEvent: &state.ScalingEventMessage{
+				ID:        uuid.UUID{},
+				GroupName: "test-group-1",
+				EvalID:    "test",
+				Source:    "test",
+				Time:      helper.GenerateEventTimestamp() - 1000000000000,
+				Status:    "test",
+				Count:     1,
+				Direction: "in",
+			},
+		},
+		{
+			inputJobName:         "test-job-1",
+			inputGroupName:       "test-group-1",
+			inputCoolDown:        300,
+			inputTime:            helper.GenerateEventTimestamp(),
+			expectedCooldownResp: false,
+			name:                 "job group whose last event was a skip",
+			lastScalingEvent: &state.ScalingEventMessage{
+				ID:        uuid.UUID{},
+				GroupName: "test-group-1",
+				Source:    "test",
+				Time:      helper.GenerateEventTimestamp(),
+				Status:    state.StatusCompleted,
+				Reason:    state.ReasonJobOrphaned,
+			},
+		},
 	}
 
 	for _, tc := range testCases {
 
 		// Create a new Scaler for each test, to ensure to conflicting resources.
-		sc := Scaler{logger: zerolog.Logger{}, nomadClient: nil, state: stateMemory.NewStateBackend(), strict: true}
+		sc := Scaler{logger: zerolog.Logger{}, nomad: nil, state: stateMemory.NewStateBackend(), strict: true}
 
 		// Write the last event to check against if this isn't nil, meaning we do not have one.
 		if tc.lastScalingEvent != nil {
 			assert.Nil(t, sc.state.PutScalingEvent(tc.inputJobName, tc.lastScalingEvent), tc.name)
 		}
 
+		if tc.cooldownTime != 0 {
+			assert.Nil(t, sc.state.PutCooldown(tc.inputJobName, tc.inputGroupName, tc.cooldownTime), tc.name)
+		}
+
 		cooldown, err := sc.JobGroupIsInCooldown(tc.inputJobName, tc.inputGroupName, tc.inputCoolDown, tc.inputTime)
 		assert.Nil(t, err, tc.name)
 		assert.Equal(t, tc.expectedCooldownResp, cooldown, tc.name)
 	}
 }
+
+func TestScaler_sendScalingEventToState_cooldownOverride(t *testing.T) {
+	sc := Scaler{logger: zerolog.Logger{}, state: stateMemory.NewStateBackend()}
+	now := helper.GenerateEventTimestamp()
+
+	sc.sendScalingEventToState("test-job-1", "", state.SourceAPI, []*GroupReq{
+		{GroupName: "bypass", Direction: DirectionOut, Time: now, CooldownOverride: CooldownOverrideBypass},
+		{GroupName: "reset", Direction: DirectionOut, Time: now, CooldownOverride: CooldownOverrideReset},
+	}, nil)
+
+	// A bypassed cooldown is restarted by the scaling event, whereas a reset leaves the job group
+	// out of cooldown.
+	cooldown, err := sc.JobGroupIsInCooldown("test-job-1", "bypass", 300, now)
+	assert.Nil(t, err)
+	assert.True(t, cooldown)
+
+	cooldown, err = sc.JobGroupIsInCooldown("test-job-1", "reset", 300, now)
+	assert.Nil(t, err)
+	assert.False(t, cooldown)
+}
@@ -50,7 +50,6 @@ func (s *Scaler) handleDeploymentMessage(msg interface{}) {
 		Msg("received deployment update message to handle")
 
 	s.deploymentsLock.Lock()
-	defer s.deploymentsLock.Unlock()
 
 	switch deployment.Status {
 	case "running":
@@ -68,4 +67,11 @@ func (s *Scaler) handleDeploymentMessage(msg interface{}) {
 			delete(s.deployments, deploymentsKey{job: deployment.JobID, group: tg})
 		}
 	}
+
+	deferred := s.updatePromotions(deployment)
+	s.deploymentsLock.Unlock()
+
+	// Deferred scale in requests are submitted once the lock is released, as triggering scaling
+	// checks the deployment tracking.
+	s.applyDeferredScaleIns(deferred)
 }
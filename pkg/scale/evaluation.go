@@ -0,0 +1,101 @@
+package scale
+
+import (
+	"context"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/pkg/errors"
+)
+
+const (
+	// evalPollInterval is the time between reads of a scaling evaluation while waiting for the
+	// Nomad scheduler to process it.
+	evalPollInterval = time.Second
+
+	// evalPollTimeout bounds the time spent waiting for a scaling evaluation to be processed.
+	evalPollTimeout = 30 * time.Second
+)
+
+// recordEvalDeployment waits for the Nomad scheduler to process the scaling evaluation, and stores
+// the ID of the deployment it created against the scaling event of each group. Jobs without an
+// update stanza do not create deployments, in which case the scaling event is left unchanged.
+func (s *Scaler) recordEvalDeployment(job, evalID string, scaleID uuid.UUID, groupReqs []*GroupReq) {
+	deploymentID, err := s.waitForEvalDeployment(evalID)
+	if err != nil {
+		s.logger.Warn().
+			Str("job", job).
+			Str("eval", evalID).
+			Err(err).
+			Msg("failed to read deployment ID of scaling evaluation")
+		return
+	}
+
+	if deploymentID == "" {
+		return
+	}
+
+	for i := range groupReqs {
+		if err := s.state.PutScalingEventDeployment(scaleID, job, groupReqs[i].GroupName, deploymentID); err != nil {
+			s.logger.Error().
+				Str("job", job).
+				Str("group", groupReqs[i].GroupName).
+				Err(err).
+				Msg("failed to update state with scaling event deployment")
+		}
+	}
+}
+
+// waitForEvalDeployment polls the evaluation until it has been processed by the Nomad scheduler,
+// returning the ID of the deployment it created, if any.
+func (s *Scaler) waitForEvalDeployment(evalID string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.evalPollTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(s.evalPollInterval)
+	defer ticker.Stop()
+
+	for {
+		eval, err := s.getEvaluation(ctx, evalID)
+		if err != nil {
+			return "", err
+		}
+
+		if eval.DeploymentID != "" || evalIsTerminal(eval.Status) {
+			return eval.DeploymentID, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return "", errors.New("timed out waiting for evaluation to be processed")
+		case <-s.shutdownChan:
+			return "", errors.New("scaler shutting down")
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scaler) getEvaluation(ctx context.Context, evalID string) (*api.Evaluation, error) {
+	ctx, cancel := helper.ContextWithTimeout(ctx, s.nomadTimeout)
+	defer cancel()
+
+	var eval *api.Evaluation
+
+	err := s.nomad.Call(ctx, func() (err error) {
+		eval, _, err = s.nomad.Client().Evaluations().Info(evalID, nil)
+		return err
+	})
+	return eval, err
+}
+
+// evalIsTerminal identifies whether the evaluation status indicates the Nomad scheduler has
+// finished processing the evaluation.
+func evalIsTerminal(status string) bool {
+	switch status {
+	case "complete", "failed", "canceled":
+		return true
+	}
+	return false
+}
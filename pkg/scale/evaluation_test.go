@@ -0,0 +1,61 @@
+package scale
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/jrasell/sherpa/pkg/state/scale/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaler_recordEvalDeployment(t *testing.T) {
+	var calls int32
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/evaluation/e05a8d0f", r.URL.Path)
+
+		// The first read of the evaluation returns it before it has been processed.
+		eval := api.Evaluation{ID: "e05a8d0f", Status: "pending"}
+		if atomic.AddInt32(&calls, 1) > 1 {
+			eval.Status, eval.DeploymentID = "complete", "d2b8cc34"
+		}
+		_ = json.NewEncoder(w).Encode(eval)
+	}))
+	defer srv.Close()
+
+	pool, err := client.NewNomadPool(zerolog.Nop(), []string{srv.URL}, time.Second)
+	assert.Nil(t, err)
+
+	backend := memory.NewStateBackend()
+	scaler := NewScaler(pool, zerolog.Nop(), backend, false, time.Second, nil).(*Scaler)
+	scaler.evalPollInterval = time.Millisecond
+
+	id := uuid.Must(uuid.NewV4())
+	assert.Nil(t, backend.PutScalingEvent("example", &state.ScalingEventMessage{
+		ID: id, GroupName: "cache", EvalID: "e05a8d0f", Time: time.Now().UnixNano(),
+	}))
+
+	scaler.recordEvalDeployment("example", "e05a8d0f", id, []*GroupReq{{GroupName: "cache"}})
+
+	event, err := backend.GetScalingEvent(id)
+	assert.Nil(t, err)
+	assert.Equal(t, "d2b8cc34", event["example:cache"].DeploymentID)
+	assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+}
+
+func Test_evalIsTerminal(t *testing.T) {
+	assert.True(t, evalIsTerminal("complete"))
+	assert.True(t, evalIsTerminal("failed"))
+	assert.True(t, evalIsTerminal("canceled"))
+	assert.False(t, evalIsTerminal("pending"))
+	assert.False(t, evalIsTerminal("blocked"))
+}
@@ -1,6 +1,7 @@
 package scale
 
 import (
+	sendMetrics "github.com/armon/go-metrics"
 	"github.com/gofrs/uuid"
 	"github.com/jrasell/sherpa/pkg/state"
 )
@@ -15,17 +16,29 @@ func (s *Scaler) sendScalingEventToState(job, id string, source state.Source, gr
 	}
 
 	for i := range groupReqs {
+		reason := groupReqs[i].Reason
+		if reason == "" {
+			reason = state.ReasonUnknown
+		}
+
 		event := state.ScalingEventMessage{
-			ID:        scaleID,
-			EvalID:    id,
-			GroupName: groupReqs[i].GroupName,
-			Status:    status,
-			Source:    source,
-			Time:      groupReqs[i].Time,
-			Count:     groupReqs[i].Count,
-			Direction: groupReqs[i].Direction.String(),
-			Meta:      groupReqs[i].Meta,
+			ID:           scaleID,
+			EvalID:       id,
+			GroupName:    groupReqs[i].GroupName,
+			Status:       status,
+			Source:       source,
+			Time:         groupReqs[i].Time,
+			Count:        groupReqs[i].Count,
+			DesiredCount: groupReqs[i].DesiredCount,
+			Direction:    groupReqs[i].Direction.String(),
+			Reason:       reason,
+			Meta:         groupReqs[i].Meta,
+			Snapshot:     groupReqs[i].Snapshot,
+		}
+		if pol := groupReqs[i].GroupScalingPolicy; pol != nil {
+			event.RunbookURL, event.Notes = pol.RunbookURL, pol.Notes
 		}
+		sendScalingEventMetrics(job, &event)
 
 		if err := s.state.PutScalingEvent(job, &event); err != nil {
 			s.logger.Error().
@@ -33,6 +46,23 @@ func (s *Scaler) sendScalingEventToState(job, id string, source state.Source, gr
 				Str("group", event.GroupName).
 				Err(err).Msg("failed to update state with scaling event")
 		}
+
+		// The cooldown is started by both completed and failed scaling events, so that a failing
+		// group is not continually resubmitted to Nomad. Requests which reset the cooldown leave
+		// the job group out of cooldown.
+		cooldown := event.Time
+		if groupReqs[i].CooldownOverride == CooldownOverrideReset {
+			cooldown = cooldownResetTime
+		}
+
+		if err := s.state.PutCooldown(job, event.GroupName, cooldown); err != nil {
+			s.logger.Error().
+				Str("job", job).
+				Str("group", event.GroupName).
+				Err(err).Msg("failed to update state with scaling cooldown")
+		}
+		s.sendScalingEventNotifications(job, &event)
+		s.runPostScaleHooks(job, &event, groupReqs[i].GroupScalingPolicy)
 	}
 
 	return scaleID
@@ -46,3 +76,14 @@ func (s *Scaler) generateEventStatus(err error) state.Status {
 		return state.StatusFailed
 	}
 }
+
+// sendScalingEventMetrics tracks the number of scaling events, labelled by the job, group, reason
+// and status so that scaling activity can be aggregated by reason.
+func sendScalingEventMetrics(job string, event *state.ScalingEventMessage) {
+	sendMetrics.IncrCounterWithLabels([]string{"scale", "event"}, 1, []sendMetrics.Label{
+		{Name: "job", Value: job},
+		{Name: "group", Value: event.GroupName},
+		{Name: "reason", Value: event.Reason.String()},
+		{Name: "status", Value: event.Status.String()},
+	})
+}
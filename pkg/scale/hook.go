@@ -0,0 +1,78 @@
+package scale
+
+import (
+	"context"
+
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/hook"
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/state"
+)
+
+// runPreScaleHooks runs the pre-scale hooks of each group request before the scaling action is
+// submitted to Nomad. Hooks are run in order and synchronously, so that actions such as cache
+// warming complete before scaling. Hook failures are logged, but do not affect the scaling action.
+func (s *Scaler) runPreScaleHooks(ctx context.Context, job string, groupReqs []*GroupReq, source state.Source) {
+	if s.hooks == nil {
+		return
+	}
+
+	for _, req := range groupReqs {
+		if req.GroupScalingPolicy == nil || len(req.GroupScalingPolicy.PreScaleHooks) == 0 {
+			continue
+		}
+
+		reason := req.Reason
+		if reason == "" {
+			reason = state.ReasonUnknown
+		}
+
+		event := &hook.Event{
+			Phase: hook.PhasePreScale,
+			Event: notify.Event{
+				JobID:      job,
+				GroupName:  req.GroupName,
+				Direction:  req.Direction.String(),
+				Count:      req.Count,
+				Source:     source.String(),
+				Reason:     reason.String(),
+				Time:       req.Time,
+				Meta:       req.Meta,
+				RunbookURL: req.GroupScalingPolicy.RunbookURL,
+				Notes:      req.GroupScalingPolicy.Notes,
+			},
+		}
+		s.runHooks(ctx, req.GroupScalingPolicy.PreScaleHooks, event)
+	}
+}
+
+// runPostScaleHooks runs the post-scale hooks of the group once the scaling action has completed
+// or failed. This is performed asynchronously so that slow hooks do not delay the scaling response.
+func (s *Scaler) runPostScaleHooks(job string, msg *state.ScalingEventMessage, pol *policy.GroupScalingPolicy) {
+	if s.hooks == nil || pol == nil || len(pol.PostScaleHooks) == 0 {
+		return
+	}
+
+	ctx := context.Background()
+	if id, ok := msg.Meta[MetaKeyRequestID]; ok {
+		ctx = helper.ContextWithRequestID(ctx, id)
+	}
+
+	event := &hook.Event{Phase: hook.PhasePostScale, Event: *buildNotifyEvent(job, msg)}
+	go s.runHooks(ctx, pol.PostScaleHooks, event)
+}
+
+func (s *Scaler) runHooks(ctx context.Context, hooks []*policy.ScalingHook, event *hook.Event) {
+	for i, h := range hooks {
+		if err := s.hooks.Run(ctx, h, event); err != nil {
+			s.logger.Error().
+				Str("job", event.JobID).
+				Str("group", event.GroupName).
+				Str("phase", event.Phase.String()).
+				Int("hook", i).
+				Err(err).
+				Msg("failed to run scaling hook")
+		}
+	}
+}
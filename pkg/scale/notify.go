@@ -0,0 +1,63 @@
+package scale
+
+import (
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/jrasell/sherpa/pkg/state"
+)
+
+// sendScalingEventNotifications publishes the scaling event to each configured notifier. This is
+// performed asynchronously so that slow or unavailable integrations do not delay scaling.
+func (s *Scaler) sendScalingEventNotifications(job string, msg *state.ScalingEventMessage) {
+	if len(s.notifiers) == 0 {
+		return
+	}
+
+	event := buildNotifyEvent(job, msg)
+
+	for _, n := range s.notifiers {
+		go func(n notify.Notifier) {
+			if err := n.Notify(event); err != nil {
+				s.logger.Error().
+					Str("job", event.JobID).
+					Str("group", event.GroupName).
+					Str("notifier", n.Name()).
+					Err(err).
+					Msg("failed to send scaling event notification")
+			}
+		}(n)
+	}
+}
+
+// buildNotifyEvent converts the scaling event message into the event published to notifiers and
+// post-scale hooks.
+func buildNotifyEvent(job string, msg *state.ScalingEventMessage) *notify.Event {
+	return &notify.Event{
+		ScalingID:    msg.ID.String(),
+		EvaluationID: msg.EvalID,
+		JobID:        job,
+		GroupName:    msg.GroupName,
+		Direction:    msg.Direction,
+		Count:        msg.Count,
+		Source:       msg.Source.String(),
+		Status:       msg.Status.String(),
+		Reason:       msg.Reason.String(),
+		Severity:     eventSeverity(msg),
+		Time:         msg.Time,
+		Meta:         msg.Meta,
+		RunbookURL:   msg.RunbookURL,
+		Notes:        msg.Notes,
+	}
+}
+
+// eventSeverity classifies the scaling event. Failed events are critical, and events which
+// completed without using the configured checks of the group policy are warnings.
+func eventSeverity(msg *state.ScalingEventMessage) notify.Severity {
+	switch {
+	case msg.Status != state.StatusCompleted:
+		return notify.SeverityCritical
+	case msg.Reason == state.ReasonBoundsEnforcement, msg.Reason == state.ReasonMetricsFallback:
+		return notify.SeverityWarning
+	default:
+		return notify.SeverityInfo
+	}
+}
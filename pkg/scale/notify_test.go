@@ -0,0 +1,70 @@
+package scale
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeNotifier struct {
+	events chan *notify.Event
+}
+
+func (f *fakeNotifier) Name() string { return "fake" }
+
+func (f *fakeNotifier) Notify(e *notify.Event) error {
+	f.events <- e
+	return nil
+}
+
+func TestScaler_sendScalingEventNotifications(t *testing.T) {
+	n := &fakeNotifier{events: make(chan *notify.Event, 1)}
+	scaler := NewScaler(nil, zerolog.Logger{}, nil, false, 0, nil, n).(*Scaler)
+
+	id, _ := uuid.NewV4()
+
+	scaler.sendScalingEventNotifications("example", &state.ScalingEventMessage{
+		ID:         id,
+		EvalID:     "eval",
+		GroupName:  "cache",
+		Status:     state.StatusCompleted,
+		Source:     state.SourceAPI,
+		Time:       1580000000000000000,
+		Count:      1,
+		Direction:  "out",
+		Reason:     state.ReasonManual,
+		RunbookURL: "https://wiki.jrasell.system/runbooks/cache",
+	})
+
+	select {
+	case e := <-n.events:
+		assert.Equal(t, &notify.Event{
+			ScalingID:    id.String(),
+			EvaluationID: "eval",
+			JobID:        "example",
+			GroupName:    "cache",
+			Direction:    "out",
+			Count:        1,
+			Source:       "API",
+			Status:       "Completed",
+			Reason:       "manual",
+			Severity:     notify.SeverityInfo,
+			Time:         1580000000000000000,
+			RunbookURL:   "https://wiki.jrasell.system/runbooks/cache",
+		}, e)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for scaling event notification")
+	}
+}
+
+func Test_eventSeverity(t *testing.T) {
+	assert.Equal(t, notify.SeverityInfo, eventSeverity(&state.ScalingEventMessage{Status: state.StatusCompleted, Reason: state.ReasonManual}))
+	assert.Equal(t, notify.SeverityWarning, eventSeverity(&state.ScalingEventMessage{Status: state.StatusCompleted, Reason: state.ReasonBoundsEnforcement}))
+	assert.Equal(t, notify.SeverityWarning, eventSeverity(&state.ScalingEventMessage{Status: state.StatusCompleted, Reason: state.ReasonMetricsFallback}))
+	assert.Equal(t, notify.SeverityCritical, eventSeverity(&state.ScalingEventMessage{Status: state.StatusFailed, Reason: state.ReasonManual}))
+}
@@ -0,0 +1,50 @@
+package scale
+
+import (
+	"github.com/hashicorp/nomad/api"
+)
+
+// registerJobRequest is the Nomad job register request. The vendored Nomad API client does not
+// support setting the evaluation priority, so the request is written using the raw client.
+type registerJobRequest struct {
+	Job            *api.Job
+	EnforceIndex   bool   `json:",omitempty"`
+	JobModifyIndex uint64 `json:",omitempty"`
+	EvalPriority   int    `json:",omitempty"`
+}
+
+// evalPriority returns the highest evaluation priority configured by the policies of the job
+// groups being scaled, or 0 if none is configured.
+func evalPriority(groupReqs []*GroupReq) int {
+	var priority int
+
+	for _, req := range groupReqs {
+		if req.GroupScalingPolicy != nil && req.GroupScalingPolicy.EvalPriority > priority {
+			priority = req.GroupScalingPolicy.EvalPriority
+		}
+	}
+	return priority
+}
+
+// registerWithEvalPriority registers the job with Nomad using the evaluation priority. As with
+// the standard registration, the job modify index read by the scaler is enforced when known.
+func (s *Scaler) registerWithEvalPriority(job *api.Job, priority int, q *api.WriteOptions) (*api.JobRegisterResponse, error) {
+	req := registerJobRequest{Job: job, EvalPriority: priority}
+
+	if job.JobModifyIndex != nil {
+		req.EnforceIndex, req.JobModifyIndex = true, *job.JobModifyIndex
+	}
+
+	var resp api.JobRegisterResponse
+
+	if _, err := s.nomad.Client().Raw().Write("/v1/jobs", &req, &resp, q); err != nil {
+		return nil, err
+	}
+
+	s.logger.Debug().
+		Str("job", *job.ID).
+		Int("eval-priority", priority).
+		Str("evaluation-id", resp.EvalID).
+		Msg("registered job with Nomad using evaluation priority")
+	return &resp, nil
+}
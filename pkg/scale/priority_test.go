@@ -0,0 +1,61 @@
+package scale
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_evalPriority(t *testing.T) {
+	assert.Equal(t, 0, evalPriority(nil))
+	assert.Equal(t, 0, evalPriority([]*GroupReq{{GroupName: "cache"}}))
+	assert.Equal(t, 80, evalPriority([]*GroupReq{
+		{GroupName: "cache", GroupScalingPolicy: &policy.GroupScalingPolicy{EvalPriority: 80}},
+		{GroupName: "proxy", GroupScalingPolicy: &policy.GroupScalingPolicy{EvalPriority: 60}},
+		{GroupName: "web", GroupScalingPolicy: &policy.GroupScalingPolicy{}},
+	}))
+}
+
+func TestScaler_triggerNomadRegister(t *testing.T) {
+	var (
+		req   registerJobRequest
+		index uint64 = 42
+	)
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/jobs", r.URL.Path)
+		assert.Nil(t, json.NewDecoder(r.Body).Decode(&req))
+		_ = json.NewEncoder(w).Encode(api.JobRegisterResponse{EvalID: "e05a8d0f"})
+	}))
+	defer srv.Close()
+
+	pool, err := client.NewNomadPool(zerolog.Nop(), []string{srv.URL}, time.Second)
+	assert.Nil(t, err)
+
+	scaler := NewScaler(pool, zerolog.Nop(), nil, false, time.Second, nil).(*Scaler)
+	job := &api.Job{ID: helper.StringToPointer("example"), JobModifyIndex: &index}
+
+	resp, err := scaler.triggerNomadRegister(context.Background(), job, 80)
+	assert.Nil(t, err)
+	assert.Equal(t, "e05a8d0f", resp.EvalID)
+	assert.Equal(t, 80, req.EvalPriority)
+	assert.True(t, req.EnforceIndex)
+	assert.Equal(t, uint64(42), req.JobModifyIndex)
+
+	// Without a priority, the standard registration is used.
+	req = registerJobRequest{}
+	_, err = scaler.triggerNomadRegister(context.Background(), job, 0)
+	assert.Nil(t, err)
+	assert.Equal(t, 0, req.EvalPriority)
+	assert.True(t, req.EnforceIndex)
+}
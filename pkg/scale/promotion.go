@@ -0,0 +1,237 @@
+package scale
+
+import (
+	"context"
+
+	"github.com/gofrs/uuid"
+	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/pkg/errors"
+)
+
+// deferredScaleIn is a scale in request held by the scaler until the deployment of the job group
+// has completed.
+type deferredScaleIn struct {
+	job    string
+	req    *GroupReq
+	source state.Source
+
+	// count is the number by which the request reduces the count of the job group.
+	count int
+
+	// deploymentID is the ID of the deployment awaiting promotion, and eventID the ID of the
+	// scaling event which recorded the deferral.
+	deploymentID string
+	eventID      uuid.UUID
+}
+
+// SetPromotionGuard satisfies the SetPromotionGuard function of the Scale interface.
+func (s *Scaler) SetPromotionGuard(g server.PromotionGuard) { s.promotionGuard = g }
+
+// JobGroupIsAwaitingPromotion returns a boolean to indicate whether or not the specified job and
+// group has a deployment with canaries which have not yet been promoted.
+func (s *Scaler) JobGroupIsAwaitingPromotion(job, group string) bool {
+	s.deploymentsLock.RLock()
+	_, ok := s.promotions[deploymentsKey{job: job, group: group}]
+	s.deploymentsLock.RUnlock()
+	return ok
+}
+
+// DefersScaleIn satisfies the DefersScaleIn function of the Scale interface.
+func (s *Scaler) DefersScaleIn(job, group string) bool {
+	return s.promotionGuard == server.PromotionGuardDefer && s.JobGroupIsAwaitingPromotion(job, group)
+}
+
+// awaitingPromotion identifies whether the deployment state of a job group has canaries which
+// have not yet been promoted.
+func awaitingPromotion(ds *api.DeploymentState) bool {
+	return ds != nil && ds.DesiredCanaries > 0 && !ds.Promoted
+}
+
+// scaleInCount returns the number by which the request reduces the count of the job group, and
+// whether the request is a scale in. Absolute requests are compared against the current count of
+// the group.
+func (s *Scaler) scaleInCount(job *api.Job, req *GroupReq) (int, bool) {
+	if !req.Absolute {
+		return req.Count, req.Direction == DirectionIn
+	}
+	tg := s.checkJobGroupExists(job, req.GroupName)
+	if tg == nil || tg.Count == nil || req.Count >= *tg.Count {
+		return 0, false
+	}
+	return *tg.Count - req.Count, true
+}
+
+// guardPromotions checks the scale in requests of job groups which are awaiting deployment
+// promotion. Depending on the promotion guard, these either cause the scaling action to be
+// rejected, or are removed from the returned requests and deferred. The ID of the scaling event
+// which recorded any deferrals is returned.
+func (s *Scaler) guardPromotions(job *api.Job, groupReqs []*GroupReq, source state.Source) ([]*GroupReq, uuid.UUID, error) {
+	var (
+		remaining []*GroupReq
+		deferred  []*deferredScaleIn
+	)
+
+	for _, req := range groupReqs {
+		count, in := s.scaleInCount(job, req)
+		if !in || !s.JobGroupIsAwaitingPromotion(*job.ID, req.GroupName) {
+			remaining = append(remaining, req)
+			continue
+		}
+
+		if s.promotionGuard != server.PromotionGuardDefer {
+			return nil, uuid.Nil, errors.Errorf("job group %s cannot be scaled in while its deployment awaits promotion",
+				req.GroupName)
+		}
+		deferred = append(deferred, &deferredScaleIn{job: *job.ID, req: req, source: source, count: count})
+	}
+
+	if len(deferred) == 0 {
+		return remaining, uuid.Nil, nil
+	}
+	return remaining, s.deferScaleIns(deferred), nil
+}
+
+// deferScaleIns stores the scale in requests until the deployments of their job groups complete,
+// and records a scaling event for each documenting the deferral. A newer request for a job group
+// replaces any existing deferred request.
+func (s *Scaler) deferScaleIns(deferred []*deferredScaleIn) uuid.UUID {
+	id, err := uuid.NewV4()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to generate scaling UUID")
+	}
+
+	s.deploymentsLock.Lock()
+	for _, d := range deferred {
+		key := deploymentsKey{job: d.job, group: d.req.GroupName}
+		d.eventID, d.deploymentID = id, s.promotions[key]
+
+		if _, ok := s.deferred[key]; ok {
+			s.logger.Info().
+				Str("job", d.job).
+				Str("group", d.req.GroupName).
+				Msg("replacing previously deferred scale in request of job group")
+		}
+		s.deferred[key] = d
+	}
+	s.deploymentsLock.Unlock()
+
+	for _, d := range deferred {
+		s.sendDeferralEventToState(d)
+	}
+	return id
+}
+
+// sendDeferralEventToState records the deferral of a scale in request. The event does not start
+// the job group cooldown, as no change has been made to the group.
+func (s *Scaler) sendDeferralEventToState(d *deferredScaleIn) {
+	meta := make(map[string]string, len(d.req.Meta)+1)
+	for k, v := range d.req.Meta {
+		meta[k] = v
+	}
+	meta[MetaKeyDeploymentID] = d.deploymentID
+
+	event := state.ScalingEventMessage{
+		ID:        d.eventID,
+		GroupName: d.req.GroupName,
+		Status:    state.StatusCompleted,
+		Source:    d.source,
+		Time:      d.req.Time,
+		Count:     d.count,
+		Direction: string(DirectionIn),
+		Reason:    state.ReasonPromotionDeferred,
+		Meta:      meta,
+	}
+	if pol := d.req.GroupScalingPolicy; pol != nil {
+		event.RunbookURL, event.Notes = pol.RunbookURL, pol.Notes
+	}
+	sendScalingEventMetrics(d.job, &event)
+
+	if err := s.state.PutScalingEvent(d.job, &event); err != nil {
+		s.logger.Error().
+			Str("job", d.job).
+			Str("group", event.GroupName).
+			Err(err).Msg("failed to update state with scaling event")
+	}
+
+	s.logger.Info().
+		Str("job", d.job).
+		Str("group", event.GroupName).
+		Str("deployment-id", d.deploymentID).
+		Msg("deferred scale in of job group until deployment completes")
+	s.sendScalingEventNotifications(d.job, &event)
+}
+
+// updatePromotions tracks the job groups of the deployment which are awaiting promotion. Once
+// the deployment has finished, the deferred scale in requests of its job groups are removed and,
+// if the deployment was successful, returned so they can be submitted. The caller must hold the
+// deployments lock.
+func (s *Scaler) updatePromotions(deployment *api.Deployment) []*deferredScaleIn {
+	var apply []*deferredScaleIn
+
+	for tg, ds := range deployment.TaskGroups {
+		key := deploymentsKey{job: deployment.JobID, group: tg}
+
+		switch deployment.Status {
+		case "successful":
+			if d, ok := s.deferred[key]; ok {
+				apply = append(apply, d)
+			}
+		case "failed", "cancelled":
+			if _, ok := s.deferred[key]; ok {
+				s.logger.Warn().
+					Str("job", deployment.JobID).
+					Str("group", tg).
+					Str("status", deployment.Status).
+					Msg("dropping deferred scale in request of job group as deployment did not complete")
+			}
+		default:
+			// Running and paused deployments may still be awaiting promotion.
+			if awaitingPromotion(ds) {
+				s.promotions[key] = deployment.ID
+			} else {
+				delete(s.promotions, key)
+			}
+			continue
+		}
+
+		delete(s.promotions, key)
+		delete(s.deferred, key)
+	}
+	return apply
+}
+
+// applyDeferredScaleIns submits the deferred scale in requests now the deployment of their job
+// groups has completed. Each request uses the current time, and references the event which
+// recorded its deferral.
+func (s *Scaler) applyDeferredScaleIns(deferred []*deferredScaleIn) {
+	for _, d := range deferred {
+		req := *d.req
+		req.Time = helper.GenerateEventTimestamp()
+		req.Meta = make(map[string]string, len(d.req.Meta)+1)
+		for k, v := range d.req.Meta {
+			req.Meta[k] = v
+		}
+		req.Meta[MetaKeyDeferredEventID] = d.eventID.String()
+
+		resp, _, err := s.Trigger(context.Background(), d.job, []*GroupReq{&req}, d.source)
+		if err != nil {
+			s.logger.Error().
+				Str("job", d.job).
+				Str("group", req.GroupName).
+				Err(err).
+				Msg("failed to trigger deferred scale in of job group")
+			continue
+		}
+
+		if resp != nil {
+			s.logger.Info().
+				Str("job", d.job).
+				Str("group", req.GroupName).
+				Str("id", resp.ID.String()).
+				Msg("successfully triggered deferred scale in of job group")
+		}
+	}
+}
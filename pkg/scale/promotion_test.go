@@ -0,0 +1,107 @@
+package scale
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/jrasell/sherpa/pkg/state/scale/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestScaler_updatePromotions(t *testing.T) {
+	scaler := NewScaler(nil, zerolog.Nop(), nil, false, 0, nil).(*Scaler)
+
+	deployment := &api.Deployment{
+		ID:     "d2b8cc34",
+		JobID:  "example",
+		Status: "running",
+		TaskGroups: map[string]*api.DeploymentState{
+			"cache": {DesiredCanaries: 1},
+			"proxy": {},
+		},
+	}
+	assert.Len(t, scaler.updatePromotions(deployment), 0)
+	assert.True(t, scaler.JobGroupIsAwaitingPromotion("example", "cache"))
+	assert.False(t, scaler.JobGroupIsAwaitingPromotion("example", "proxy"))
+
+	// Paused deployments are still awaiting promotion.
+	deployment.Status = "paused"
+	scaler.updatePromotions(deployment)
+	assert.True(t, scaler.JobGroupIsAwaitingPromotion("example", "cache"))
+
+	// Once promoted, the group is no longer awaiting promotion, but deferred requests are held
+	// until the deployment completes.
+	pending := &deferredScaleIn{job: "example", req: &GroupReq{GroupName: "cache"}}
+	scaler.deferred[deploymentsKey{job: "example", group: "cache"}] = pending
+
+	deployment.Status = "running"
+	deployment.TaskGroups["cache"].Promoted = true
+	assert.Len(t, scaler.updatePromotions(deployment), 0)
+	assert.False(t, scaler.JobGroupIsAwaitingPromotion("example", "cache"))
+
+	deployment.Status = "successful"
+	assert.Equal(t, []*deferredScaleIn{pending}, scaler.updatePromotions(deployment))
+	assert.Len(t, scaler.deferred, 0)
+
+	// Deferred requests are dropped when the deployment does not complete.
+	deployment.Status = "running"
+	deployment.TaskGroups["cache"].Promoted = false
+	scaler.updatePromotions(deployment)
+	scaler.deferred[deploymentsKey{job: "example", group: "cache"}] = pending
+
+	deployment.Status = "failed"
+	assert.Len(t, scaler.updatePromotions(deployment), 0)
+	assert.Len(t, scaler.deferred, 0)
+	assert.False(t, scaler.JobGroupIsAwaitingPromotion("example", "cache"))
+}
+
+func TestScaler_guardPromotions(t *testing.T) {
+	job := &api.Job{
+		ID: helper.StringToPointer("example"),
+		TaskGroups: []*api.TaskGroup{
+			{Name: helper.StringToPointer("cache"), Count: helper.IntToPointer(5)},
+			{Name: helper.StringToPointer("proxy"), Count: helper.IntToPointer(2)},
+		},
+	}
+
+	backend := memory.NewStateBackend()
+	scaler := NewScaler(nil, zerolog.Nop(), backend, false, 0, nil).(*Scaler)
+	scaler.promotions[deploymentsKey{job: "example", group: "cache"}] = "d2b8cc34"
+
+	scaleIn := &GroupReq{GroupName: "cache", Direction: DirectionIn, Count: 1, Time: 100, Reason: state.ReasonManual}
+	scaleOut := &GroupReq{GroupName: "cache", Direction: DirectionOut, Count: 1}
+	proxyIn := &GroupReq{GroupName: "proxy", Direction: DirectionIn, Count: 1}
+
+	// By default, scale in of a group awaiting promotion is rejected.
+	_, _, err := scaler.guardPromotions(job, []*GroupReq{scaleIn, proxyIn}, state.SourceAPI)
+	assert.EqualError(t, err, "job group cache cannot be scaled in while its deployment awaits promotion")
+
+	remaining, _, err := scaler.guardPromotions(job, []*GroupReq{scaleOut, proxyIn}, state.SourceAPI)
+	assert.Nil(t, err)
+	assert.Equal(t, []*GroupReq{scaleOut, proxyIn}, remaining)
+
+	// When deferring, the request is held and the deferral recorded without starting cooldown.
+	scaler.SetPromotionGuard(server.PromotionGuardDefer)
+	assert.True(t, scaler.DefersScaleIn("example", "cache"))
+	assert.False(t, scaler.DefersScaleIn("example", "proxy"))
+
+	absolute := &GroupReq{GroupName: "cache", Absolute: true, Count: 3, Time: 100}
+	remaining, id, err := scaler.guardPromotions(job, []*GroupReq{absolute, proxyIn}, state.SourceAPI)
+	assert.Nil(t, err)
+	assert.Equal(t, []*GroupReq{proxyIn}, remaining)
+	assert.Equal(t, absolute, scaler.deferred[deploymentsKey{job: "example", group: "cache"}].req)
+
+	events, err := backend.GetScalingEvent(id)
+	assert.Nil(t, err)
+	assert.Equal(t, state.ReasonPromotionDeferred, events["example:cache"].Reason)
+	assert.Equal(t, state.EventDetails{Count: 2, Direction: "in"}, events["example:cache"].Details)
+	assert.Equal(t, "d2b8cc34", events["example:cache"].Meta[MetaKeyDeploymentID])
+
+	cooldown, err := backend.GetCooldown("example", "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), cooldown)
+}
@@ -1,11 +1,18 @@
 package scale
 
 import (
+	"context"
 	"net/http"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/hook"
+	"github.com/jrasell/sherpa/pkg/notify"
 	"github.com/jrasell/sherpa/pkg/state"
 	"github.com/jrasell/sherpa/pkg/state/scale"
 	"github.com/pkg/errors"
@@ -15,10 +22,36 @@ import (
 var _ Scale = (*Scaler)(nil)
 
 type Scaler struct {
-	logger      zerolog.Logger
-	nomadClient *api.Client
-	state       scale.Backend
-	strict      bool
+	logger zerolog.Logger
+	nomad  *client.NomadPool
+	state  scale.Backend
+	strict bool
+
+	// nomadTimeout bounds each call made to the Nomad API.
+	nomadTimeout time.Duration
+
+	// evalPollInterval and evalPollTimeout control how the scaling evaluation is polled while
+	// waiting to record the resulting deployment ID.
+	evalPollInterval time.Duration
+	evalPollTimeout  time.Duration
+
+	// hooks runs the pre-scale and post-scale hooks configured within group policies.
+	hooks *hook.Runner
+
+	// notifiers are the integrations which scaling events are published to.
+	notifiers []notify.Notifier
+
+	// fence is checked before scaling actions are submitted to Nomad, and is nil when no check is
+	// required.
+	fence Fence
+
+	// promotionGuard is the action taken on scale in requests of job groups within promotions,
+	// which tracks the deployment ID of job groups with canaries awaiting promotion. Deferred
+	// holds the scale in requests waiting for those deployments to complete. Both maps are
+	// guarded by the deployments lock.
+	promotionGuard server.PromotionGuard
+	promotions     map[deploymentsKey]string
+	deferred       map[deploymentsKey]*deferredScaleIn
 
 	deployments          map[deploymentsKey]interface{}
 	deploymentsLock      sync.RWMutex
@@ -27,28 +60,38 @@ type Scaler struct {
 	shutdownChan chan interface{}
 }
 
-func NewScaler(c *api.Client, l zerolog.Logger, state scale.Backend, strictChecking bool) Scale {
+func NewScaler(c *client.NomadPool, l zerolog.Logger, state scale.Backend, strictChecking bool, nomadTimeout time.Duration,
+	hooks *hook.Runner, notifiers ...notify.Notifier) Scale {
 	return &Scaler{
 		logger:               l,
-		nomadClient:          c,
+		nomad:                c,
 		state:                state,
 		strict:               strictChecking,
+		nomadTimeout:         nomadTimeout,
+		evalPollInterval:     evalPollInterval,
+		evalPollTimeout:      evalPollTimeout,
+		hooks:                hooks,
+		notifiers:            notifiers,
+		promotionGuard:       server.PromotionGuardBlock,
+		promotions:           make(map[deploymentsKey]string),
+		deferred:             make(map[deploymentsKey]*deferredScaleIn),
 		deployments:          make(map[deploymentsKey]interface{}),
 		deploymentUpdateChan: make(chan interface{}),
 	}
 }
 
-// Trigger performs scaling of 1 or more job groups which belong to the same job.
+// Trigger performs scaling of 1 or more job groups which belong to the same job. Each Nomad API
+// call is bound by the context and the scaler Nomad timeout.
 //
 // The return values indicate:
 //		- the Nomad API job register response
 //		- the HTTP return code, used for the Sherpa API
 //		- any error
-func (s *Scaler) Trigger(jobID string, groupReqs []*GroupReq, source state.Source) (*ScalingResponse, int, error) {
+func (s *Scaler) Trigger(ctx context.Context, jobID string, groupReqs []*GroupReq, source state.Source) (*ScalingResponse, int, error) {
 
 	// In order to submit a job for scaling we need to read the entire job back to Nomad as it does
 	// not currently have convenience methods for changing job group counts.
-	job, found, err := s.getJob(jobID)
+	job, found, err := s.getJob(ctx, jobID)
 	if !found && err == nil {
 		s.logger.Info().Str("job", jobID).Msg("job not found to be running")
 		return nil, http.StatusNotFound, errors.New("job not found")
@@ -57,6 +100,17 @@ func (s *Scaler) Trigger(jobID string, groupReqs []*GroupReq, source state.Sourc
 		return nil, http.StatusInternalServerError, err
 	}
 
+	// Scale in requests of job groups awaiting deployment promotion are either rejected, or
+	// deferred until the deployment completes. If all requests were deferred, there is nothing
+	// further to do.
+	groupReqs, deferredID, err := s.guardPromotions(job, groupReqs, source)
+	if err != nil {
+		return nil, http.StatusConflict, err
+	}
+	if len(groupReqs) == 0 {
+		return &ScalingResponse{ID: deferredID}, http.StatusAccepted, nil
+	}
+
 	var changes bool
 
 	if s.strict {
@@ -78,11 +132,33 @@ func (s *Scaler) Trigger(jobID string, groupReqs []*GroupReq, source state.Sourc
 		return nil, http.StatusNotModified, nil
 	}
 
-	resp, err := s.triggerNomadRegister(job)
+	// Policies can configure the spread of new allocations when the job group is scaled out.
+	s.applyScaleOutSpreads(job, groupReqs)
+
+	if s.fence != nil {
+		if err := s.fence.CheckFence(); err != nil {
+			s.logger.Warn().Str("job", jobID).Err(err).Msg("scaling action rejected by cluster fence")
+			return nil, http.StatusServiceUnavailable, err
+		}
+	}
+
+	s.runPreScaleHooks(ctx, jobID, groupReqs, source)
+
+	resp, err := s.triggerNomadRegister(ctx, job, evalPriority(groupReqs))
+
+	// If the job was modified after it was read, another scaling action or deployment has taken
+	// place. This is not recorded as a scaling event, as the action was never applied.
+	if err != nil && isJobModifyIndexConflict(err) {
+		s.logger.Warn().Str("job", jobID).Err(err).Msg("job was modified during scaling, skipping scaling action")
+		return nil, http.StatusConflict, errors.New("job was modified during scaling")
+	}
 
 	return s.handleEndState(jobID, resp, err, groupReqs, source)
 }
 
+// SetFence satisfies the SetFence function of the Scale interface.
+func (s *Scaler) SetFence(f Fence) { s.fence = f }
+
 func (s *Scaler) handleEndState(job string, apiResp *api.JobRegisterResponse, apiErr error, groupReqs []*GroupReq,
 	source state.Source) (*ScalingResponse, int, error) {
 
@@ -97,6 +173,12 @@ func (s *Scaler) handleEndState(job string, apiResp *api.JobRegisterResponse, ap
 	if apiErr != nil {
 		return nil, http.StatusInternalServerError, apiErr
 	}
+
+	// The deployment created by the scaling action is only known once Nomad has processed the
+	// evaluation, so is recorded in the background.
+	if eval != "" {
+		go s.recordEvalDeployment(job, eval, scaleID, groupReqs)
+	}
 	return &ScalingResponse{ID: scaleID, EvaluationID: eval}, http.StatusOK, nil
 }
 
@@ -121,6 +203,10 @@ func (s *Scaler) triggerWithStrictChecking(job *api.Job, groupReqs []*GroupReq)
 			return changes, errors.New("job group not found on Nomad cluster")
 		}
 
+		if !resolveAbsoluteCount(tg, groupReqs[i]) {
+			continue
+		}
+
 		// Important: when dealing with a Nomad job we are dealing with a pointer. In strict
 		// checking we should check the count outside of the job before modifying the job as its
 		// possible some task groups pass checks and have updates and some don't. In this situation
@@ -137,6 +223,7 @@ func (s *Scaler) triggerWithStrictChecking(job *api.Job, groupReqs []*GroupReq)
 		// Once the check is completed, update the job group count and ensure changes are marked as
 		// true.
 		*tg.Count = newCount
+		groupReqs[i].DesiredCount = newCount
 		changes = true
 	}
 
@@ -152,12 +239,17 @@ func (s *Scaler) triggerWithoutStrictChecking(job *api.Job, groupReqs []*GroupRe
 			return changes, errors.New("job group not found on Nomad cluster")
 		}
 
+		if !resolveAbsoluteCount(tg, groupReqs[i]) {
+			continue
+		}
+
 		// Once we have confirmed the job group exists within the running Nomad job, we can assume
 		// there are changes to the job to submit to Nomad.
 		changes = true
 
 		// As we do not have strict checking, we can blindly update the task group count.
 		*tg.Count = s.getNewGroupCount(tg, groupReqs[i])
+		groupReqs[i].DesiredCount = *tg.Count
 	}
 
 	return changes, nil
@@ -173,7 +265,31 @@ func (s *Scaler) getNewGroupCount(taskGroup *api.TaskGroup, req *GroupReq) int {
 	return 0
 }
 
+// resolveAbsoluteCount converts an absolute scaling request into a direction and change count using
+// the current count of the job group, so the resulting scaling event details the change made. False
+// is returned if the job group is already at the requested count.
+func resolveAbsoluteCount(tg *api.TaskGroup, req *GroupReq) bool {
+	if !req.Absolute {
+		return true
+	}
+	req.Absolute = false
+
+	switch current := *tg.Count; {
+	case req.Count > current:
+		req.Direction, req.Count = DirectionOut, req.Count-current
+	case req.Count < current:
+		req.Direction, req.Count = DirectionIn, current-req.Count
+	default:
+		return false
+	}
+	return true
+}
+
 func (s *Scaler) checkNewGroupCount(newCount int, req *GroupReq) error {
+	if req.Force {
+		return nil
+	}
+
 	switch req.Direction {
 	case DirectionIn:
 		if newCount < req.GroupScalingPolicy.MinCount {
@@ -187,14 +303,52 @@ func (s *Scaler) checkNewGroupCount(newCount int, req *GroupReq) error {
 	return nil
 }
 
-// triggerNomadRegister is used to submit the updated job to the Nomad API.
-func (s *Scaler) triggerNomadRegister(job *api.Job) (*api.JobRegisterResponse, error) {
-	resp, _, err := s.nomadClient.Jobs().Register(job, nil)
-	return resp, err
+// triggerNomadRegister registers the updated job with Nomad. The registration enforces the job
+// modify index read by the scaler, so that concurrent scaling actions based on the same job
+// version cannot both be applied. If the priority is non-zero, it is used as the priority of the
+// resulting Nomad evaluation. The registration uses the context, so that the request ID of the API
+// request which triggered the scaling is sent to Nomad.
+func (s *Scaler) triggerNomadRegister(ctx context.Context, job *api.Job, priority int) (*api.JobRegisterResponse, error) {
+	ctx, cancel := helper.ContextWithTimeout(ctx, s.nomadTimeout)
+	defer cancel()
+
+	var resp *api.JobRegisterResponse
+	q := (&api.WriteOptions{}).WithContext(ctx)
+
+	err := s.nomad.Call(ctx, func() (err error) {
+		if priority > 0 {
+			resp, err = s.registerWithEvalPriority(job, priority, q)
+			return err
+		}
+		if job.JobModifyIndex == nil {
+			resp, _, err = s.nomad.Client().Jobs().Register(job, q)
+			return err
+		}
+		resp, _, err = s.nomad.Client().Jobs().EnforceRegister(job, *job.JobModifyIndex, q)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
 }
 
-func (s *Scaler) getJob(jobID string) (*api.Job, bool, error) {
-	job, _, err := s.nomadClient.Jobs().Info(jobID, nil)
+// isJobModifyIndexConflict identifies whether the Nomad job register error was caused by the job
+// modify index changing since the job was read.
+func isJobModifyIndexConflict(err error) bool {
+	return strings.Contains(err.Error(), "conflicting job modify index")
+}
+
+func (s *Scaler) getJob(ctx context.Context, jobID string) (*api.Job, bool, error) {
+	ctx, cancel := helper.ContextWithTimeout(ctx, s.nomadTimeout)
+	defer cancel()
+
+	var job *api.Job
+
+	err := s.nomad.Call(ctx, func() (err error) {
+		job, _, err = s.nomad.Client().Jobs().Info(jobID, (&api.QueryOptions{}).WithContext(ctx))
+		return err
+	})
 
 	// If the job is not running on the cluster, the Nomad API will return an error which contains
 	// the 404 not found message. We want to be able to tell the difference between a 404 and an
@@ -11,7 +11,7 @@ import (
 )
 
 func TestScaler_getNewGroupCount(t *testing.T) {
-	scaler := NewScaler(nil, zerolog.Logger{}, nil, false)
+	scaler := NewScaler(nil, zerolog.Logger{}, nil, false, 0, nil)
 
 	testCases := []struct {
 		taskGroup      *api.TaskGroup
@@ -37,7 +37,7 @@ func TestScaler_getNewGroupCount(t *testing.T) {
 }
 
 func TestScaler_checkNewGroupCount(t *testing.T) {
-	scaler := NewScaler(nil, zerolog.Logger{}, nil, true)
+	scaler := NewScaler(nil, zerolog.Logger{}, nil, true, 0, nil)
 
 	testCases := []struct {
 		newCount       int
@@ -84,6 +84,17 @@ func TestScaler_checkNewGroupCount(t *testing.T) {
 			},
 			expectedReturn: errors.New("scaling action will break job group minimum threshold"),
 		},
+		{
+			newCount: 1,
+			groupReq: &GroupReq{
+				Direction: DirectionIn,
+				Force:     true,
+				GroupScalingPolicy: &policy.GroupScalingPolicy{
+					MinCount: 2,
+				},
+			},
+			expectedReturn: nil,
+		},
 	}
 
 	for _, tc := range testCases {
@@ -96,8 +107,47 @@ func TestScaler_checkNewGroupCount(t *testing.T) {
 	}
 }
 
+func Test_resolveAbsoluteCount(t *testing.T) {
+	testCases := []struct {
+		taskGroup       *api.TaskGroup
+		groupReq        *GroupReq
+		expectedReturn  bool
+		expectedRequest *GroupReq
+	}{
+		{
+			taskGroup:       api.NewTaskGroup("cache", 3),
+			groupReq:        &GroupReq{Absolute: true, Count: 7},
+			expectedReturn:  true,
+			expectedRequest: &GroupReq{Direction: DirectionOut, Count: 4},
+		},
+		{
+			taskGroup:       api.NewTaskGroup("cache", 3),
+			groupReq:        &GroupReq{Absolute: true, Count: 0},
+			expectedReturn:  true,
+			expectedRequest: &GroupReq{Direction: DirectionIn, Count: 3},
+		},
+		{
+			taskGroup:       api.NewTaskGroup("cache", 3),
+			groupReq:        &GroupReq{Absolute: true, Count: 3},
+			expectedReturn:  false,
+			expectedRequest: &GroupReq{Count: 3},
+		},
+		{
+			taskGroup:       api.NewTaskGroup("cache", 3),
+			groupReq:        &GroupReq{Direction: DirectionOut, Count: 2},
+			expectedReturn:  true,
+			expectedRequest: &GroupReq{Direction: DirectionOut, Count: 2},
+		},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedReturn, resolveAbsoluteCount(tc.taskGroup, tc.groupReq))
+		assert.Equal(t, tc.expectedRequest, tc.groupReq)
+	}
+}
+
 func TestScaler_jobGroupExists(t *testing.T) {
-	scaler := NewScaler(nil, zerolog.Logger{}, nil, false)
+	scaler := NewScaler(nil, zerolog.Logger{}, nil, false, 0, nil)
 
 	testCases := []struct {
 		job            *api.Job
@@ -128,3 +178,9 @@ func generateJobWithTargetGroup(groupName string) *api.Job {
 	newJob.TaskGroups = append(newJob.TaskGroups, api.NewTaskGroup(groupName, 1))
 	return &newJob
 }
+
+func Test_isJobModifyIndexConflict(t *testing.T) {
+	err := errors.New("Unexpected response code: 500 (Enforcing job modify index 41: job exists with conflicting job modify index: 42)")
+	assert.True(t, isJobModifyIndexConflict(err))
+	assert.False(t, isJobModifyIndexConflict(errors.New("job not found")))
+}
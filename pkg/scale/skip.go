@@ -0,0 +1,39 @@
+package scale
+
+import (
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/state"
+)
+
+// RecordSkip satisfies the RecordSkip function of the Scale interface.
+func (s *Scaler) RecordSkip(job string, groups []string, reason state.Reason, source state.Source, meta map[string]string) uuid.UUID {
+	id, err := uuid.NewV4()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to generate scaling UUID")
+	}
+
+	t := helper.GenerateEventTimestamp()
+
+	for _, group := range groups {
+		event := state.ScalingEventMessage{
+			ID:        id,
+			GroupName: group,
+			Status:    state.StatusCompleted,
+			Source:    source,
+			Time:      t,
+			Reason:    reason,
+			Meta:      meta,
+		}
+		sendScalingEventMetrics(job, &event)
+
+		if err := s.state.PutScalingEvent(job, &event); err != nil {
+			s.logger.Error().
+				Str("job", job).
+				Str("group", group).
+				Err(err).Msg("failed to update state with scaling event")
+		}
+		s.sendScalingEventNotifications(job, &event)
+	}
+	return id
+}
@@ -0,0 +1,86 @@
+package scale
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/policy"
+)
+
+// applyScaleOutSpreads updates the spread stanza of the job groups being scaled out whose policy
+// configures a scale out spread, so that the new allocations are placed according to the spread.
+func (s *Scaler) applyScaleOutSpreads(job *api.Job, groupReqs []*GroupReq) {
+	for _, req := range groupReqs {
+		if req.Direction != DirectionOut || req.DesiredCount == 0 ||
+			req.GroupScalingPolicy == nil || req.GroupScalingPolicy.ScaleOutSpread == nil {
+			continue
+		}
+
+		tg := s.checkJobGroupExists(job, req.GroupName)
+		if tg == nil {
+			continue
+		}
+
+		if setGroupSpread(tg, buildSpread(req.GroupScalingPolicy.ScaleOutSpread)) {
+			s.logger.Info().
+				Str("job", *job.ID).
+				Str("group", req.GroupName).
+				Str("attribute", req.GroupScalingPolicy.ScaleOutSpread.GetAttribute()).
+				Msg("updated job group spread for scale out")
+		}
+	}
+}
+
+// buildSpread converts the policy scale out spread into a Nomad spread stanza. Targets are sorted
+// by value so the stanza is consistent between scaling actions.
+func buildSpread(sos *policy.ScaleOutSpread) *api.Spread {
+	weight := int8(sos.GetWeight())
+
+	spread := &api.Spread{Attribute: sos.GetAttribute(), Weight: &weight}
+
+	for value, percent := range sos.Targets {
+		spread.SpreadTarget = append(spread.SpreadTarget, &api.SpreadTarget{Value: value, Percent: uint8(percent)})
+	}
+	sort.Slice(spread.SpreadTarget, func(i, j int) bool {
+		return spread.SpreadTarget[i].Value < spread.SpreadTarget[j].Value
+	})
+	return spread
+}
+
+// setGroupSpread sets the spread of the job group, replacing any existing group spread of the same
+// attribute. False is returned if the group already has the spread, so the job is not modified.
+func setGroupSpread(tg *api.TaskGroup, spread *api.Spread) bool {
+	for i, existing := range tg.Spreads {
+		if existing == nil || existing.Attribute != spread.Attribute {
+			continue
+		}
+		if spreadsEqual(existing, spread) {
+			return false
+		}
+		tg.Spreads[i] = spread
+		return true
+	}
+
+	tg.Spreads = append(tg.Spreads, spread)
+	return true
+}
+
+// spreadsEqual compares the weight and targets of two spreads of the same attribute, ignoring the
+// order of the targets.
+func spreadsEqual(a, b *api.Spread) bool {
+	if (a.Weight == nil) != (b.Weight == nil) || (a.Weight != nil && *a.Weight != *b.Weight) {
+		return false
+	}
+
+	targets := func(s *api.Spread) map[string]uint8 {
+		out := make(map[string]uint8, len(s.SpreadTarget))
+		for _, t := range s.SpreadTarget {
+			if t != nil {
+				out[t.Value] = t.Percent
+			}
+		}
+		return out
+	}
+	return reflect.DeepEqual(targets(a), targets(b))
+}
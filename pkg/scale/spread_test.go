@@ -0,0 +1,66 @@
+package scale
+
+import (
+	"testing"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_buildSpread(t *testing.T) {
+	weight := int8(80)
+
+	assert.Equal(t, &api.Spread{
+		Attribute: "${meta.rack}",
+		Weight:    &weight,
+		SpreadTarget: []*api.SpreadTarget{
+			{Value: "r1", Percent: 60},
+			{Value: "r2", Percent: 40},
+		},
+	}, buildSpread(&policy.ScaleOutSpread{Attribute: "${meta.rack}", Weight: 80, Targets: map[string]int{"r2": 40, "r1": 60}}))
+
+	spread := buildSpread(&policy.ScaleOutSpread{})
+	assert.Equal(t, policy.DefaultSpreadAttribute, spread.Attribute)
+	assert.Equal(t, int8(policy.DefaultSpreadWeight), *spread.Weight)
+	assert.Len(t, spread.SpreadTarget, 0)
+}
+
+func Test_setGroupSpread(t *testing.T) {
+	weight := int8(50)
+	rack := &api.Spread{Attribute: "${meta.rack}", Weight: &weight}
+	tg := &api.TaskGroup{Spreads: []*api.Spread{rack}}
+
+	even := buildSpread(&policy.ScaleOutSpread{})
+	assert.True(t, setGroupSpread(tg, even))
+	assert.Equal(t, []*api.Spread{rack, even}, tg.Spreads)
+
+	// Applying the same spread does not modify the group.
+	assert.False(t, setGroupSpread(tg, buildSpread(&policy.ScaleOutSpread{})))
+
+	targeted := buildSpread(&policy.ScaleOutSpread{Targets: map[string]int{"dc1": 70, "dc2": 30}})
+	assert.True(t, setGroupSpread(tg, targeted))
+	assert.Equal(t, []*api.Spread{rack, targeted}, tg.Spreads)
+}
+
+func TestScaler_applyScaleOutSpreads(t *testing.T) {
+	job := &api.Job{
+		ID: helper.StringToPointer("example"),
+		TaskGroups: []*api.TaskGroup{
+			{Name: helper.StringToPointer("cache"), Count: helper.IntToPointer(5)},
+			{Name: helper.StringToPointer("proxy"), Count: helper.IntToPointer(1)},
+		},
+	}
+	pol := &policy.GroupScalingPolicy{ScaleOutSpread: &policy.ScaleOutSpread{}}
+
+	scaler := NewScaler(nil, zerolog.Nop(), nil, false, 0, nil).(*Scaler)
+	scaler.applyScaleOutSpreads(job, []*GroupReq{
+		{GroupName: "cache", Direction: DirectionOut, Count: 2, DesiredCount: 5, GroupScalingPolicy: pol},
+		{GroupName: "proxy", Direction: DirectionIn, Count: 1, DesiredCount: 1, GroupScalingPolicy: pol},
+	})
+
+	assert.Len(t, job.TaskGroups[0].Spreads, 1)
+	assert.Len(t, job.TaskGroups[1].Spreads, 0)
+}
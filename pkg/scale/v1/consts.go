@@ -18,7 +18,13 @@ const (
 )
 
 var (
-	errInternalScaleOutNoPolicy = errors.New("scale out forbidden, no scaling policy found")
-	errInternalScaleInNoPolicy  = errors.New("scale in forbidden, no scaling policy found")
-	errJobGroupInDeployment     = errors.New("scale forbidden, job group currently deploying")
+	errInternalScaleOutNoPolicy   = errors.New("scale out forbidden, no scaling policy found")
+	errInternalScaleInNoPolicy    = errors.New("scale in forbidden, no scaling policy found")
+	errInternalScaleCountNoPolicy = errors.New("scale to count forbidden, no scaling policy found")
+	errJobGroupInDeployment       = errors.New("scale forbidden, job group currently deploying")
+	errCountRequired              = errors.New("count query param is required and must be a non-negative integer")
+	errForceNotEnabled            = errors.New("scale force forbidden, not enabled on this server")
+	errCountOutOfBounds           = errors.New("scaling action will break job group minimum or maximum threshold")
+	errGroupAlreadyAtCount        = errors.New("job group is already at the requested count")
+	errInvalidCooldownOverride    = errors.New("cooldown query param must be one of bypass or reset")
 )
@@ -0,0 +1,177 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/pkg/errors"
+)
+
+// CountJobGroup scales the job group to the exact count passed via the count query param. The
+// count is validated against the job group scaling policy bounds, unless the force query param is
+// set and the server has force enabled.
+func (s *Scale) CountJobGroup(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	jobID := vars["job_id"]
+	groupID := vars["group"]
+
+	count, force, err := parseCountRequest(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if force && !s.forceEnabled {
+		s.logger.Info().
+			Str("job", jobID).
+			Str("group", groupID).
+			Msg("scale force requested but not enabled")
+		http.Error(w, errForceNotEnabled.Error(), http.StatusForbidden)
+		return
+	}
+
+	body, err := parseScaleRequestBody(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	newReq := &scale.GroupReq{
+		Count:     count,
+		Absolute:  true,
+		Force:     force,
+		GroupName: groupID,
+		Time:      helper.GenerateEventTimestamp(),
+		Reason:    state.ReasonManual,
+		Meta:      body.Meta,
+
+		CooldownOverride: body.CooldownOverride,
+	}
+
+	if s.scaler.JobGroupIsDeploying(jobID, groupID) {
+		s.logger.Info().
+			Str("job", jobID).
+			Str("group", groupID).
+			Msg("job group is currently in deployment and cannot be scaled")
+		http.Error(w, errJobGroupInDeployment.Error(), http.StatusForbidden)
+		return
+	}
+
+	pol, err := s.policyBackend.GetJobGroupPolicy(r.Context(), jobID, groupID)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if s.strictChecking && pol == nil {
+		s.logger.Info().
+			Str("job", jobID).
+			Str("group", groupID).
+			Msg("strict checking enabled and job group does not have scaling policy")
+		http.Error(w, errInternalScaleCountNoPolicy.Error(), http.StatusForbidden)
+		return
+	}
+	newReq.GroupScalingPolicy = pol
+
+	if pol != nil {
+		if !force && !countWithinBounds(count, pol) {
+			http.Error(w, errCountOutOfBounds.Error(), http.StatusConflict)
+			return
+		}
+
+		cd, err := s.jobGroupIsInCooldown(jobID, groupID, newReq)
+		if err != nil {
+			s.logger.Error().
+				Err(err).
+				Str("job", jobID).
+				Str("group", groupID).
+				Msg("failed to check if job group is currently in scaling cooldown")
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+
+		if cd {
+			s.logger.Info().
+				Str("job", jobID).
+				Str("group", groupID).
+				Msg(jobGroupInCooldownMsg)
+			http.Error(w, jobGroupInCooldownMsg, http.StatusConflict)
+			return
+		}
+	}
+
+	scaleResp, respCode, err := s.scaler.Trigger(r.Context(), jobID, []*scale.GroupReq{newReq}, state.SourceAPI)
+	if err != nil {
+		s.logger.Error().
+			Err(err).
+			Str("job", jobID).
+			Str("group", groupID).
+			Msg("failed to scale Nomad job group to count")
+		http.Error(w, err.Error(), respCode)
+		return
+	}
+
+	if respCode == http.StatusNotFound {
+		http.NotFound(w, r)
+		return
+	}
+
+	if respCode == http.StatusNotModified {
+		http.Error(w, errGroupAlreadyAtCount.Error(), http.StatusNotModified)
+		return
+	}
+
+	bytes, err := json.Marshal(scaleResp)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to marshal scaling response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if respCode == http.StatusAccepted {
+		s.logger.Info().
+			Str("job", jobID).
+			Str("group", groupID).
+			Int("count", count).
+			Msg("deferred scaling of Nomad job group to count until deployment completes")
+		writeJSONResponse(w, bytes, http.StatusAccepted)
+		return
+	}
+
+	s.logger.Info().
+		Str("job", jobID).
+		Str("group", groupID).
+		Int("count", count).
+		Bool("force", force).
+		Str("request-id", helper.RequestIDFromContext(r.Context())).
+		Str("evaluation-id", scaleResp.EvaluationID).
+		Msg("successfully scaled Nomad job group to count")
+
+	writeJSONResponse(w, bytes, http.StatusCreated)
+}
+
+// parseCountRequest returns the count and force query params of an absolute scaling request.
+func parseCountRequest(r *http.Request) (int, bool, error) {
+	count, err := strconv.Atoi(r.URL.Query().Get("count"))
+	if err != nil || count < 0 {
+		return 0, false, errCountRequired
+	}
+
+	var force bool
+
+	if v := r.URL.Query().Get("force"); v != "" {
+		if force, err = strconv.ParseBool(v); err != nil {
+			return 0, false, errors.New("failed to parse force query param")
+		}
+	}
+	return count, force, nil
+}
+
+func countWithinBounds(count int, pol *policy.GroupScalingPolicy) bool {
+	return count >= pol.MinCount && count <= pol.MaxCount
+}
@@ -0,0 +1,66 @@
+package v1
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseCountRequest(t *testing.T) {
+	testCases := []struct {
+		query         string
+		expectedCount int
+		expectedForce bool
+		expectError   bool
+		name          string
+	}{
+		{
+			query:         "?count=5",
+			expectedCount: 5,
+			name:          "count",
+		},
+		{
+			query:         "?count=0&force=true",
+			expectedCount: 0,
+			expectedForce: true,
+			name:          "zero count with force",
+		},
+		{
+			query:       "",
+			expectError: true,
+			name:        "missing count",
+		},
+		{
+			query:       "?count=-1",
+			expectError: true,
+			name:        "negative count",
+		},
+		{
+			query:       "?count=2&force=maybe",
+			expectError: true,
+			name:        "invalid force",
+		},
+	}
+
+	for _, tc := range testCases {
+		count, force, err := parseCountRequest(httptest.NewRequest("POST", "/v1/scale/count/example/cache"+tc.query, nil))
+		if tc.expectError {
+			assert.NotNil(t, err, tc.name)
+			continue
+		}
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.expectedCount, count, tc.name)
+		assert.Equal(t, tc.expectedForce, force, tc.name)
+	}
+}
+
+func Test_countWithinBounds(t *testing.T) {
+	pol := &policy.GroupScalingPolicy{MinCount: 2, MaxCount: 10}
+
+	assert.True(t, countWithinBounds(2, pol))
+	assert.True(t, countWithinBounds(10, pol))
+	assert.False(t, countWithinBounds(1, pol))
+	assert.False(t, countWithinBounds(11, pol))
+}
@@ -0,0 +1,214 @@
+package v1
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"mime"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/pkg/errors"
+)
+
+// Supported scaling event listing formats. The JSON format is the default and returns the events
+// keyed by ID; the CSV and NDJSON formats return one flattened event per row or line, suitable for
+// importing into spreadsheets and data pipelines.
+const (
+	eventFormatJSON   = "json"
+	eventFormatCSV    = "csv"
+	eventFormatNDJSON = "ndjson"
+
+	headerKeyAccept              = "Accept"
+	headerValueContentTypeCSV    = "text/csv; charset=utf-8"
+	headerValueContentTypeNDJSON = "application/x-ndjson"
+)
+
+// eventCSVHeader is the header row of CSV scaling event exports.
+var eventCSVHeader = []string{
+	"id", "eval_id", "deployment_id", "job_id", "group", "source", "time", "status",
+	"direction", "count", "desired_count", "reason", "meta",
+}
+
+// exportEvent is the flattened representation of a single scaling event of a job group.
+type exportEvent struct {
+	ID           uuid.UUID         `json:"id"`
+	EvalID       string            `json:"eval_id"`
+	DeploymentID string            `json:"deployment_id,omitempty"`
+	JobID        string            `json:"job_id"`
+	Group        string            `json:"group"`
+	Source       state.Source      `json:"source"`
+	Time         time.Time         `json:"time"`
+	Status       state.Status      `json:"status"`
+	Direction    string            `json:"direction"`
+	Count        int               `json:"count"`
+	DesiredCount int               `json:"desired_count,omitempty"`
+	Reason       state.Reason      `json:"reason,omitempty"`
+	Meta         map[string]string `json:"meta,omitempty"`
+}
+
+// negotiateEventFormat determines the scaling event listing format. The format query param takes
+// precedence over the Accept header, and requests without either use JSON.
+func negotiateEventFormat(r *http.Request) (string, error) {
+	if f := r.URL.Query().Get("format"); f != "" {
+		switch f {
+		case eventFormatJSON, eventFormatCSV, eventFormatNDJSON:
+			return f, nil
+		}
+		return "", errors.Errorf("unsupported format %q, must be one of json, csv or ndjson", f)
+	}
+
+	for _, accept := range strings.Split(r.Header.Get(headerKeyAccept), ",") {
+		mediaType, _, err := mime.ParseMediaType(strings.TrimSpace(accept))
+		if err != nil {
+			continue
+		}
+
+		switch mediaType {
+		case "text/csv":
+			return eventFormatCSV, nil
+		case "application/x-ndjson":
+			return eventFormatNDJSON, nil
+		case "application/json":
+			return eventFormatJSON, nil
+		}
+	}
+	return eventFormatJSON, nil
+}
+
+// flattenEvents converts the scaling events into a single list, ordered by time and then ID so that
+// exports are stable.
+func flattenEvents(events map[uuid.UUID]map[string]*state.ScalingEvent) []*exportEvent {
+	var out []*exportEvent // nolint:prealloc
+
+	for id, jobEvents := range events {
+		for jg, event := range jobEvents {
+			if event == nil {
+				continue
+			}
+
+			job, group := jg, ""
+			if split := strings.SplitN(jg, ":", 2); len(split) == 2 {
+				job, group = split[0], split[1]
+			}
+
+			out = append(out, &exportEvent{
+				ID:           id,
+				EvalID:       event.EvalID,
+				DeploymentID: event.DeploymentID,
+				JobID:        job,
+				Group:        group,
+				Source:       event.Source,
+				Time:         time.Unix(0, event.Time).UTC(),
+				Status:       event.Status,
+				Direction:    event.Details.Direction,
+				Count:        event.Details.Count,
+				DesiredCount: event.Details.DesiredCount,
+				Reason:       event.Reason,
+				Meta:         event.Meta,
+			})
+		}
+	}
+
+	sort.Slice(out, func(i, j int) bool {
+		if !out[i].Time.Equal(out[j].Time) {
+			return out[i].Time.Before(out[j].Time)
+		}
+		if out[i].ID != out[j].ID {
+			return out[i].ID.String() < out[j].ID.String()
+		}
+		return out[i].JobID+":"+out[i].Group < out[j].JobID+":"+out[j].Group
+	})
+	return out
+}
+
+// writeEventsCSV writes the events as CSV, including a header row. The meta of each event is
+// written as a single column of semicolon separated key=value pairs, ordered by key.
+func writeEventsCSV(w io.Writer, events []*exportEvent) error {
+	cw := csv.NewWriter(w)
+
+	if err := cw.Write(eventCSVHeader); err != nil {
+		return err
+	}
+
+	for _, e := range events {
+		desired := ""
+		if e.DesiredCount > 0 {
+			desired = strconv.Itoa(e.DesiredCount)
+		}
+
+		record := []string{
+			e.ID.String(), e.EvalID, e.DeploymentID, e.JobID, e.Group, string(e.Source),
+			e.Time.Format(time.RFC3339Nano), string(e.Status), e.Direction,
+			strconv.Itoa(e.Count), desired, string(e.Reason), formatEventMeta(e.Meta),
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// writeEventsNDJSON writes the events as newline delimited JSON, one event per line.
+func writeEventsNDJSON(w io.Writer, events []*exportEvent) error {
+	enc := json.NewEncoder(w)
+	for _, e := range events {
+		if err := enc.Encode(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func formatEventMeta(meta map[string]string) string {
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	pairs := make([]string, len(keys))
+	for i, k := range keys {
+		pairs[i] = k + "=" + meta[k]
+	}
+	return strings.Join(pairs, ";")
+}
+
+// writeEventList writes the scaling events using the format negotiated from the request.
+func (s *Scale) writeEventList(w http.ResponseWriter, r *http.Request, events map[uuid.UUID]map[string]*state.ScalingEvent) {
+	format, err := negotiateEventFormat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	switch format {
+	case eventFormatCSV:
+		w.Header().Set(headerKeyContentType, headerValueContentTypeCSV)
+		w.WriteHeader(http.StatusOK)
+		err = writeEventsCSV(w, flattenEvents(events))
+	case eventFormatNDJSON:
+		w.Header().Set(headerKeyContentType, headerValueContentTypeNDJSON)
+		w.WriteHeader(http.StatusOK)
+		err = writeEventsNDJSON(w, flattenEvents(events))
+	default:
+		bytes, mErr := json.Marshal(events)
+		if mErr != nil {
+			s.logger.Error().Err(mErr).Msg("failed to marshal scaling state response")
+			http.Error(w, mErr.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSONResponse(w, bytes, http.StatusOK)
+	}
+
+	if err != nil {
+		s.logger.Error().Str("format", format).Err(err).Msg("failed to write scaling event export")
+	}
+}
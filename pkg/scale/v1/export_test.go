@@ -0,0 +1,78 @@
+package v1
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_negotiateEventFormat(t *testing.T) {
+	testCases := []struct {
+		url            string
+		accept         string
+		expectedOutput string
+		expectedError  bool
+	}{
+		{url: "/v1/scale/status", expectedOutput: eventFormatJSON},
+		{url: "/v1/scale/status?format=csv", expectedOutput: eventFormatCSV},
+		{url: "/v1/scale/status?format=ndjson", accept: "text/csv", expectedOutput: eventFormatNDJSON},
+		{url: "/v1/scale/status?format=xml", expectedError: true},
+		{url: "/v1/scale/status", accept: "text/csv; charset=utf-8", expectedOutput: eventFormatCSV},
+		{url: "/v1/scale/status", accept: "text/html, application/x-ndjson", expectedOutput: eventFormatNDJSON},
+		{url: "/v1/scale/status", accept: "*/*", expectedOutput: eventFormatJSON},
+	}
+
+	for _, tc := range testCases {
+		r := httptest.NewRequest("GET", tc.url, nil)
+		if tc.accept != "" {
+			r.Header.Set(headerKeyAccept, tc.accept)
+		}
+
+		actualOutput, err := negotiateEventFormat(r)
+		assert.Equal(t, tc.expectedOutput, actualOutput, tc.url)
+		assert.Equal(t, tc.expectedError, err != nil, tc.url)
+	}
+}
+
+func Test_writeEvents(t *testing.T) {
+	first := uuid.FromStringOrNil("1a2b3c4d-0000-0000-0000-000000000001")
+	second := uuid.FromStringOrNil("1a2b3c4d-0000-0000-0000-000000000002")
+	ts := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+
+	events := flattenEvents(map[uuid.UUID]map[string]*state.ScalingEvent{
+		second: {"example:cache": {
+			EvalID:       "e05a8d0f",
+			DeploymentID: "d2b8cc34",
+			Source:       state.SourceAPI,
+			Time:         ts.Add(time.Minute).UnixNano(),
+			Status:       state.StatusCompleted,
+			Details:      state.EventDetails{Count: 1, Direction: "in", DesiredCount: 2},
+			Meta:         map[string]string{"b": "2", "a": "1"},
+		}},
+		first: {"example:cache": {
+			Source:  state.SourceAPI,
+			Time:    ts.UnixNano(),
+			Status:  state.StatusFailed,
+			Details: state.EventDetails{Count: 2, Direction: "out"},
+		}},
+	})
+
+	var buf bytes.Buffer
+	assert.Nil(t, writeEventsCSV(&buf, events))
+	assert.Equal(t, "id,eval_id,deployment_id,job_id,group,source,time,status,direction,count,desired_count,reason,meta\n"+
+		first.String()+",,,example,cache,API,2020-01-26T10:00:00Z,Failed,out,2,,,\n"+
+		second.String()+",e05a8d0f,d2b8cc34,example,cache,API,2020-01-26T10:01:00Z,Completed,in,1,2,,a=1;b=2\n",
+		buf.String())
+
+	buf.Reset()
+	assert.Nil(t, writeEventsNDJSON(&buf, events))
+	assert.Equal(t,
+		`{"id":"`+first.String()+`","eval_id":"","job_id":"example","group":"cache","source":"API","time":"2020-01-26T10:00:00Z","status":"Failed","direction":"out","count":2}`+"\n"+
+			`{"id":"`+second.String()+`","eval_id":"e05a8d0f","deployment_id":"d2b8cc34","job_id":"example","group":"cache","source":"API","time":"2020-01-26T10:01:00Z","status":"Completed","direction":"in","count":1,"desired_count":2,"meta":{"a":"1","b":"2"}}`+"\n",
+		buf.String())
+}
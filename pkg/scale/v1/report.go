@@ -0,0 +1,86 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/jrasell/sherpa/pkg/state/report"
+	"github.com/pkg/errors"
+)
+
+// defaultReportRange is the report time range used when the from query parameter is not set. This
+// matches the scaling event garbage collection threshold.
+const defaultReportRange = 24 * time.Hour
+
+// Report returns aggregated statistics of the scaling events which took place within the range
+// set by the from and to query parameters.
+func (s *Scale) Report(w http.ResponseWriter, r *http.Request) {
+	from, to, flapThreshold, err := parseReportParams(r.URL.Query(), time.Now().UTC())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
+		return
+	}
+
+	events, err := s.stateBackend.GetScalingEvents(&state.EventFilter{From: from.UnixNano(), To: to.UnixNano()})
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to get scaling events from state")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	policies, err := s.policyBackend.GetPolicies(r.Context())
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to call policy backend")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	bytes, err := json.Marshal(report.Build(events, policies, from, to, flapThreshold))
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to marshal scaling report response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONResponse(w, bytes, http.StatusOK)
+}
+
+// parseReportParams parses the report time range and flap threshold from the query parameters.
+// The from and to parameters are RFC3339 timestamps which default to 24 hours ago and now.
+func parseReportParams(q url.Values, now time.Time) (time.Time, time.Time, int, error) {
+	to := now
+	if v := q.Get("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, 0, errors.Wrap(err, "failed to parse to query param")
+		}
+		to = t.UTC()
+	}
+
+	from := to.Add(-defaultReportRange)
+	if v := q.Get("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return time.Time{}, time.Time{}, 0, errors.Wrap(err, "failed to parse from query param")
+		}
+		from = t.UTC()
+	}
+
+	if !from.Before(to) {
+		return time.Time{}, time.Time{}, 0, errors.New("from query param must be before to")
+	}
+
+	flapThreshold := report.DefaultFlapThreshold
+	if v := q.Get("flap_threshold"); v != "" {
+		i, err := strconv.Atoi(v)
+		if err != nil || i < 1 {
+			return time.Time{}, time.Time{}, 0, errors.New("flap_threshold query param must be a positive integer")
+		}
+		flapThreshold = i
+	}
+	return from, to, flapThreshold, nil
+}
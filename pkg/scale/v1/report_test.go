@@ -0,0 +1,65 @@
+package v1
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/state/report"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseReportParams(t *testing.T) {
+	now := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		query                 url.Values
+		expectedFrom          time.Time
+		expectedTo            time.Time
+		expectedFlapThreshold int
+		expectError           bool
+		name                  string
+	}{
+		{
+			query:                 url.Values{},
+			expectedFrom:          now.Add(-24 * time.Hour),
+			expectedTo:            now,
+			expectedFlapThreshold: report.DefaultFlapThreshold,
+			name:                  "default range",
+		},
+		{
+			query:                 url.Values{"from": {"2020-01-01T00:00:00Z"}, "to": {"2020-01-02T00:00:00Z"}, "flap_threshold": {"5"}},
+			expectedFrom:          time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC),
+			expectedTo:            time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC),
+			expectedFlapThreshold: 5,
+			name:                  "custom range and threshold",
+		},
+		{
+			query:       url.Values{"from": {"yesterday"}},
+			expectError: true,
+			name:        "invalid from",
+		},
+		{
+			query:       url.Values{"from": {"2020-01-27T00:00:00Z"}},
+			expectError: true,
+			name:        "from after to",
+		},
+		{
+			query:       url.Values{"flap_threshold": {"0"}},
+			expectError: true,
+			name:        "invalid flap threshold",
+		},
+	}
+
+	for _, tc := range testCases {
+		from, to, flapThreshold, err := parseReportParams(tc.query, now)
+		if tc.expectError {
+			assert.NotNil(t, err, tc.name)
+			continue
+		}
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.expectedFrom, from, tc.name)
+		assert.Equal(t, tc.expectedTo, to, tc.name)
+		assert.Equal(t, tc.expectedFlapThreshold, flapThreshold, tc.name)
+	}
+}
@@ -19,6 +19,7 @@ type Scale struct {
 	policyBackend  policyBackend.PolicyBackend
 	stateBackend   stateBackend.Backend
 	strictChecking bool
+	forceEnabled   bool
 	scaler         scale.Scale
 }
 
@@ -29,10 +30,17 @@ type ScaleConfig struct {
 	Policy policyBackend.PolicyBackend
 	Scale  scale.Scale
 	State  stateBackend.Backend
+
+	// ForceEnabled allows absolute count scaling requests to use the force param, skipping the
+	// job group scaling policy minimum and maximum count checks.
+	ForceEnabled bool
 }
 
 type scaleRequestBody struct {
 	Meta map[string]string
+
+	// CooldownOverride is read from the cooldown query param, rather than the request body.
+	CooldownOverride scale.CooldownOverride `json:"-"`
 }
 
 func NewScaleServer(strict bool, cfg *ScaleConfig) *Scale {
@@ -42,6 +50,7 @@ func NewScaleServer(strict bool, cfg *ScaleConfig) *Scale {
 		policyBackend:  cfg.Policy,
 		stateBackend:   cfg.State,
 		strictChecking: strict,
+		forceEnabled:   cfg.ForceEnabled,
 	}
 }
 
@@ -59,10 +68,15 @@ func (s *Scale) InJobGroup(w http.ResponseWriter, r *http.Request) {
 		Direction: scale.DirectionIn,
 		GroupName: groupID,
 		Time:      helper.GenerateEventTimestamp(),
+		Reason:    state.ReasonManual,
 		Meta:      body.Meta,
+
+		CooldownOverride: body.CooldownOverride,
 	}
 
-	if s.scaler.JobGroupIsDeploying(jobID, groupID) {
+	// Job groups awaiting deployment promotion can be scaled in if the scaler defers the request
+	// until the deployment completes.
+	if s.scaler.JobGroupIsDeploying(jobID, groupID) && !s.scaler.DefersScaleIn(jobID, groupID) {
 		s.logger.Info().
 			Str("job", jobID).
 			Str("group", groupID).
@@ -71,7 +85,7 @@ func (s *Scale) InJobGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pol, err := s.policyBackend.GetJobGroupPolicy(jobID, groupID)
+	pol, err := s.policyBackend.GetJobGroupPolicy(r.Context(), jobID, groupID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -87,7 +101,7 @@ func (s *Scale) InJobGroup(w http.ResponseWriter, r *http.Request) {
 	newReq.GroupScalingPolicy = pol
 
 	if newReq.GroupScalingPolicy != nil {
-		cd, err := s.scaler.JobGroupIsInCooldown(jobID, groupID, pol.Cooldown, newReq.Time)
+		cd, err := s.jobGroupIsInCooldown(jobID, groupID, newReq)
 		if err != nil {
 			s.logger.Error().
 				Err(err).
@@ -119,7 +133,7 @@ func (s *Scale) InJobGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	scaleResp, respCode, err := s.scaler.Trigger(jobID, []*scale.GroupReq{newReq}, state.SourceAPI)
+	scaleResp, respCode, err := s.scaler.Trigger(r.Context(), jobID, []*scale.GroupReq{newReq}, state.SourceAPI)
 	if err != nil {
 		s.logger.Error().
 			Err(err).
@@ -140,11 +154,6 @@ func (s *Scale) InJobGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	s.logger.Info().
-		Str("job", jobID).
-		Str("group", groupID).
-		Msg("successfully scaled in Nomad job group")
-
 	bytes, err := json.Marshal(scaleResp)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("failed to marshal scaling response")
@@ -152,6 +161,22 @@ func (s *Scale) InJobGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if respCode == http.StatusAccepted {
+		s.logger.Info().
+			Str("job", jobID).
+			Str("group", groupID).
+			Msg("deferred scale in of Nomad job group until deployment completes")
+		writeJSONResponse(w, bytes, http.StatusAccepted)
+		return
+	}
+
+	s.logger.Info().
+		Str("job", jobID).
+		Str("group", groupID).
+		Str("request-id", helper.RequestIDFromContext(r.Context())).
+		Str("evaluation-id", scaleResp.EvaluationID).
+		Msg("successfully scaled in Nomad job group")
+
 	writeJSONResponse(w, bytes, http.StatusCreated)
 }
 
@@ -169,7 +194,10 @@ func (s *Scale) OutJobGroup(w http.ResponseWriter, r *http.Request) {
 		Direction: scale.DirectionOut,
 		GroupName: groupID,
 		Time:      helper.GenerateEventTimestamp(),
+		Reason:    state.ReasonManual,
 		Meta:      body.Meta,
+
+		CooldownOverride: body.CooldownOverride,
 	}
 
 	if s.scaler.JobGroupIsDeploying(jobID, groupID) {
@@ -181,7 +209,7 @@ func (s *Scale) OutJobGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	pol, err := s.policyBackend.GetJobGroupPolicy(jobID, groupID)
+	pol, err := s.policyBackend.GetJobGroupPolicy(r.Context(), jobID, groupID)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
@@ -198,7 +226,7 @@ func (s *Scale) OutJobGroup(w http.ResponseWriter, r *http.Request) {
 	newReq.GroupScalingPolicy = pol
 
 	if newReq.GroupScalingPolicy != nil {
-		cd, err := s.scaler.JobGroupIsInCooldown(jobID, groupID, pol.Cooldown, newReq.Time)
+		cd, err := s.jobGroupIsInCooldown(jobID, groupID, newReq)
 		if err != nil {
 			s.logger.Error().
 				Err(err).
@@ -230,7 +258,7 @@ func (s *Scale) OutJobGroup(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	scaleResp, respCode, err := s.scaler.Trigger(jobID, []*scale.GroupReq{newReq}, state.SourceAPI)
+	scaleResp, respCode, err := s.scaler.Trigger(r.Context(), jobID, []*scale.GroupReq{newReq}, state.SourceAPI)
 	if err != nil {
 		s.logger.Error().
 			Err(err).
@@ -254,6 +282,8 @@ func (s *Scale) OutJobGroup(w http.ResponseWriter, r *http.Request) {
 	s.logger.Info().
 		Str("job", jobID).
 		Str("group", groupID).
+		Str("request-id", helper.RequestIDFromContext(r.Context())).
+		Str("evaluation-id", scaleResp.EvaluationID).
 		Msg("successfully scaled out Nomad job group")
 
 	bytes, err := json.Marshal(scaleResp)
@@ -267,18 +297,60 @@ func (s *Scale) OutJobGroup(w http.ResponseWriter, r *http.Request) {
 }
 
 func parseScaleRequestBody(r *http.Request) (*scaleRequestBody, error) {
-	if r.ContentLength < 1 {
-		empty := &scaleRequestBody{
-			Meta: map[string]string{},
-		}
-		return empty, nil
-	}
+	body := scaleRequestBody{}
 
-	var body scaleRequestBody
-	err := json.NewDecoder(r.Body).Decode(&body)
+	override, err := parseCooldownOverride(r)
 	if err != nil {
 		return nil, err
 	}
+	body.CooldownOverride = override
+
+	if r.ContentLength > 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			return nil, err
+		}
+	}
 
+	if body.Meta == nil {
+		body.Meta = map[string]string{}
+	}
+
+	// Record the request ID within the scaling meta, so the resulting scaling event can be traced
+	// back to the API request.
+	if id := helper.RequestIDFromContext(r.Context()); id != "" {
+		body.Meta[scale.MetaKeyRequestID] = id
+	}
+
+	if body.CooldownOverride != "" {
+		body.Meta[scale.MetaKeyCooldownOverride] = string(body.CooldownOverride)
+	}
 	return &body, nil
 }
+
+// parseCooldownOverride returns the cooldown override requested using the cooldown query param.
+func parseCooldownOverride(r *http.Request) (scale.CooldownOverride, error) {
+	switch v := scale.CooldownOverride(r.URL.Query().Get("cooldown")); v {
+	case "", scale.CooldownOverrideBypass, scale.CooldownOverrideReset:
+		return v, nil
+	default:
+		return "", errInvalidCooldownOverride
+	}
+}
+
+// jobGroupIsInCooldown checks whether the job group is in scaling cooldown. Requests which
+// override the cooldown are never in cooldown; overriding an active cooldown is logged so that
+// operators acting during incidents can be audited.
+func (s *Scale) jobGroupIsInCooldown(job, group string, req *scale.GroupReq) (bool, error) {
+	cd, err := s.scaler.JobGroupIsInCooldown(job, group, req.GroupScalingPolicy.Cooldown, req.Time)
+	if err != nil || !cd || req.CooldownOverride == "" {
+		return cd, err
+	}
+
+	s.logger.Warn().
+		Str("job", job).
+		Str("group", group).
+		Str("request-id", req.Meta[scale.MetaKeyRequestID]).
+		Str("cooldown-override", string(req.CooldownOverride)).
+		Msg("job group scaling cooldown overridden by manual scaling request")
+	return false, nil
+}
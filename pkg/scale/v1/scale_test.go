@@ -0,0 +1,53 @@
+package v1
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseScaleRequestBody(t *testing.T) {
+	testCases := []struct {
+		query            string
+		expectedOverride scale.CooldownOverride
+		expectedMeta     map[string]string
+		expectError      bool
+		name             string
+	}{
+		{
+			query:        "",
+			expectedMeta: map[string]string{},
+			name:         "no cooldown override",
+		},
+		{
+			query:            "?cooldown=bypass",
+			expectedOverride: scale.CooldownOverrideBypass,
+			expectedMeta:     map[string]string{scale.MetaKeyCooldownOverride: "bypass"},
+			name:             "bypass cooldown override",
+		},
+		{
+			query:            "?cooldown=reset",
+			expectedOverride: scale.CooldownOverrideReset,
+			expectedMeta:     map[string]string{scale.MetaKeyCooldownOverride: "reset"},
+			name:             "reset cooldown override",
+		},
+		{
+			query:       "?cooldown=ignore",
+			expectError: true,
+			name:        "invalid cooldown override",
+		},
+	}
+
+	for _, tc := range testCases {
+		body, err := parseScaleRequestBody(httptest.NewRequest("POST", "/v1/scale/out/example/cache"+tc.query, nil))
+		if tc.expectError {
+			assert.NotNil(t, err, tc.name)
+			continue
+		}
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.expectedOverride, body.CooldownOverride, tc.name)
+		assert.Equal(t, tc.expectedMeta, body.Meta, tc.name)
+	}
+}
@@ -3,36 +3,39 @@ package v1
 import (
 	"encoding/json"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/gorilla/mux"
+	"github.com/jrasell/sherpa/pkg/scale"
 	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/pkg/errors"
 )
 
 func (s *Scale) StatusList(w http.ResponseWriter, r *http.Request) {
-	if l := r.URL.Query().Get("latest"); l == "true" {
-		s.statusListLatest(w)
+	filter, err := parseEventFilter(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnprocessableEntity)
 		return
 	}
 
-	list, err := s.stateBackend.GetScalingEvents()
-	if err != nil {
-		s.logger.Error().Err(err).Msg("failed to get scaling events from state")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+	if l := r.URL.Query().Get("latest"); l == "true" {
+		s.statusListLatest(w, r, filter)
 		return
 	}
 
-	bytes, err := json.Marshal(list)
+	list, err := s.stateBackend.GetScalingEvents(filter)
 	if err != nil {
-		s.logger.Error().Err(err).Msg("failed to marshal scaling state response")
+		s.logger.Error().Err(err).Msg("failed to get scaling events from state")
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
 
-	writeJSONResponse(w, bytes, http.StatusOK)
+	s.writeEventList(w, r, list)
 }
 
-func (s *Scale) statusListLatest(w http.ResponseWriter) {
+func (s *Scale) statusListLatest(w http.ResponseWriter, r *http.Request, filter *state.EventFilter) {
 	list, err := s.stateBackend.GetLatestScalingEvents()
 	if err != nil {
 		s.logger.Error().Err(err).Msg("failed to get latest scaling events from state")
@@ -42,17 +45,18 @@ func (s *Scale) statusListLatest(w http.ResponseWriter) {
 
 	out := map[uuid.UUID]map[string]*state.ScalingEvent{}
 	for jg, event := range list {
-		out[event.ID] = map[string]*state.ScalingEvent{jg: event}
-	}
-
-	bytes, err := json.Marshal(out)
-	if err != nil {
-		s.logger.Error().Err(err).Msg("failed to marshal latest scaling state response")
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+		if !filter.Match(jg, event) {
+			continue
+		}
+
+		// The latest events of multiple groups can belong to the same scaling event.
+		if _, ok := out[event.ID]; !ok {
+			out[event.ID] = make(map[string]*state.ScalingEvent)
+		}
+		out[event.ID][jg] = event
 	}
 
-	writeJSONResponse(w, bytes, http.StatusOK)
+	s.writeEventList(w, r, out)
 }
 
 func (s *Scale) StatusInfo(w http.ResponseWriter, r *http.Request) {
@@ -87,3 +91,47 @@ func (s *Scale) StatusInfo(w http.ResponseWriter, r *http.Request) {
 
 	writeJSONResponse(w, bytes, http.StatusOK)
 }
+
+// parseEventFilter parses the scaling event filter from the query parameters. The from and to
+// parameters are RFC3339 timestamps.
+func parseEventFilter(q url.Values) (*state.EventFilter, error) {
+	filter := state.EventFilter{
+		Job:       q.Get("job"),
+		Group:     q.Get("group"),
+		Direction: q.Get("direction"),
+		Outcome:   state.Outcome(q.Get("outcome")),
+	}
+
+	switch filter.Direction {
+	case "", string(scale.DirectionIn), string(scale.DirectionOut):
+	default:
+		return nil, errors.Errorf("unsupported direction %q, must be one of in or out", filter.Direction)
+	}
+
+	switch filter.Outcome {
+	case "", state.OutcomeSuccess, state.OutcomeFailure, state.OutcomeSkipped:
+	default:
+		return nil, errors.Errorf("unsupported outcome %q, must be one of success, failure or skipped", filter.Outcome)
+	}
+
+	for _, param := range []struct {
+		name string
+		dst  *int64
+	}{{"from", &filter.From}, {"to", &filter.To}} {
+		v := q.Get(param.name)
+		if v == "" {
+			continue
+		}
+
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s query param", param.name)
+		}
+		*param.dst = t.UnixNano()
+	}
+
+	if filter.From != 0 && filter.To != 0 && filter.From > filter.To {
+		return nil, errors.New("from query param must not be after the to query param")
+	}
+	return &filter, nil
+}
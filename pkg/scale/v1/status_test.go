@@ -0,0 +1,69 @@
+package v1
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_parseEventFilter(t *testing.T) {
+	from := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2020, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	testCases := []struct {
+		query          url.Values
+		expectedFilter *state.EventFilter
+		expectError    bool
+		name           string
+	}{
+		{
+			query:          url.Values{},
+			expectedFilter: &state.EventFilter{},
+			name:           "no filter",
+		},
+		{
+			query: url.Values{
+				"job": {"example"}, "group": {"cache"}, "direction": {"out"}, "outcome": {"failure"},
+				"from": {"2020-01-01T00:00:00Z"}, "to": {"2020-01-02T00:00:00Z"},
+			},
+			expectedFilter: &state.EventFilter{
+				Job: "example", Group: "cache", Direction: "out", Outcome: state.OutcomeFailure,
+				From: from.UnixNano(), To: to.UnixNano(),
+			},
+			name: "all params",
+		},
+		{
+			query:       url.Values{"direction": {"none"}},
+			expectError: true,
+			name:        "invalid direction",
+		},
+		{
+			query:       url.Values{"outcome": {"completed"}},
+			expectError: true,
+			name:        "invalid outcome",
+		},
+		{
+			query:       url.Values{"to": {"yesterday"}},
+			expectError: true,
+			name:        "invalid to",
+		},
+		{
+			query:       url.Values{"from": {"2020-01-02T00:00:00Z"}, "to": {"2020-01-01T00:00:00Z"}},
+			expectError: true,
+			name:        "from after to",
+		},
+	}
+
+	for _, tc := range testCases {
+		filter, err := parseEventFilter(tc.query)
+		if tc.expectError {
+			assert.NotNil(t, err, tc.name)
+			continue
+		}
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.expectedFilter, filter, tc.name)
+	}
+}
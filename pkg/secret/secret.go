@@ -0,0 +1,228 @@
+// Package secret resolves credentials which are referenced indirectly within the Sherpa config,
+// rather than being set as plain values, and periodically re-resolves them so that rotated
+// credentials are picked up without a restart.
+package secret
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	cleanhttp "github.com/hashicorp/go-cleanhttp"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+// The supported secret reference schemes. Values without one of these prefixes are plain values
+// and are used as is.
+const (
+	schemeEnv   = "env://"
+	schemeFile  = "file://"
+	schemeVault = "vault://"
+
+	envVaultAddr  = "VAULT_ADDR"
+	envVaultToken = "VAULT_TOKEN"
+
+	// minRefreshBackoff is the delay before retrying a failed secret refresh. The delay doubles
+	// with each consecutive failure, up to the refresh interval.
+	minRefreshBackoff = 5 * time.Second
+)
+
+// Config is the configuration used to resolve secret references.
+type Config struct {
+
+	// VaultAddr and VaultToken are used to read Vault secret references. If empty, the
+	// VAULT_ADDR and VAULT_TOKEN environment variables are used.
+	VaultAddr  string
+	VaultToken string
+
+	// RefreshInterval is how often a secret is re-resolved, allowing rotated secrets to be
+	// picked up. A zero interval resolves each secret once.
+	RefreshInterval time.Duration
+}
+
+// Resolver resolves secret references from the environment, files and Vault.
+type Resolver struct {
+	cfg        Config
+	httpClient *http.Client
+	logger     zerolog.Logger
+}
+
+// NewResolver creates a new resolver using the passed config.
+func NewResolver(cfg Config, log zerolog.Logger) *Resolver {
+	if cfg.VaultAddr == "" {
+		cfg.VaultAddr = os.Getenv(envVaultAddr)
+	}
+	if cfg.VaultToken == "" {
+		cfg.VaultToken = os.Getenv(envVaultToken)
+	}
+	return &Resolver{cfg: cfg, httpClient: cleanhttp.DefaultClient(), logger: log}
+}
+
+// IsReference returns whether the value is a secret reference rather than a plain value.
+func IsReference(val string) bool {
+	return strings.HasPrefix(val, schemeEnv) || strings.HasPrefix(val, schemeFile) ||
+		strings.HasPrefix(val, schemeVault)
+}
+
+// Resolve returns the secret the reference points to. Plain values are returned unchanged. The
+// supported references are:
+//   - env://NAME reads the NAME environment variable
+//   - file:///path reads the file at the path, trimming surrounding whitespace
+//   - vault://path#field reads the field of the Vault secret at the path
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	switch {
+	case strings.HasPrefix(ref, schemeEnv):
+		name := strings.TrimPrefix(ref, schemeEnv)
+		val, ok := os.LookupEnv(name)
+		if !ok {
+			return "", errors.Errorf("environment variable %s is not set", name)
+		}
+		return val, nil
+
+	case strings.HasPrefix(ref, schemeFile):
+		b, err := ioutil.ReadFile(strings.TrimPrefix(ref, schemeFile))
+		if err != nil {
+			return "", errors.Wrap(err, "failed to read secret file")
+		}
+		return strings.TrimSpace(string(b)), nil
+
+	case strings.HasPrefix(ref, schemeVault):
+		return r.readVault(ctx, strings.TrimPrefix(ref, schemeVault))
+	}
+	return ref, nil
+}
+
+// vaultSecret is the response of a Vault secret read.
+type vaultSecret struct {
+	Data map[string]interface{} `json:"data"`
+}
+
+// readVault reads the field of the secret at the path. Both version 1 and version 2 KV secrets
+// are supported; the latter being detected by the nested data and metadata objects.
+func (r *Resolver) readVault(ctx context.Context, ref string) (string, error) {
+	split := strings.SplitN(ref, "#", 2)
+	if len(split) != 2 || split[0] == "" || split[1] == "" {
+		return "", errors.Errorf("vault secret reference %q must be in the form vault://path#field", ref)
+	}
+	if r.cfg.VaultAddr == "" {
+		return "", errors.New("vault address is not configured")
+	}
+
+	req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(r.cfg.VaultAddr, "/")+"/v1/"+split[0], nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("X-Vault-Token", r.cfg.VaultToken)
+
+	resp, err := r.httpClient.Do(req.WithContext(ctx))
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read Vault secret")
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", errors.Errorf("unexpected response code %v reading Vault secret %s", resp.StatusCode, split[0])
+	}
+
+	var secret vaultSecret
+	if err := json.NewDecoder(resp.Body).Decode(&secret); err != nil {
+		return "", errors.Wrap(err, "failed to decode Vault secret")
+	}
+
+	data := secret.Data
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		if _, ok := data["metadata"]; ok {
+			data = inner
+		}
+	}
+
+	val, ok := data[split[1]].(string)
+	if !ok {
+		return "", errors.Errorf("Vault secret %s does not contain string field %s", split[0], split[1])
+	}
+	return val, nil
+}
+
+// Value is a secret which is lazily resolved, and re-resolved once the resolver refresh interval
+// has passed. If re-resolving fails, the previously resolved secret continues to be used and the
+// refresh is retried with an exponential backoff, rather than on every use of the secret.
+type Value struct {
+	ref      string
+	resolver *Resolver
+	now      func() time.Time
+
+	lock     sync.Mutex
+	val      string
+	resolved time.Time
+
+	// failures is the number of consecutive failed refreshes, and retryAt the time before which
+	// the refresh is not retried.
+	failures int
+	retryAt  time.Time
+}
+
+// Value returns a Value for the reference.
+func (r *Resolver) Value(ref string) *Value {
+	return &Value{ref: ref, resolver: r, now: time.Now}
+}
+
+// Get returns the current secret.
+func (v *Value) Get(ctx context.Context) (string, error) {
+	if v == nil {
+		return "", nil
+	}
+	if v.resolver == nil || !IsReference(v.ref) {
+		return v.ref, nil
+	}
+
+	v.lock.Lock()
+	defer v.lock.Unlock()
+
+	if !v.resolved.IsZero() && (v.resolver.cfg.RefreshInterval == 0 || v.now().Sub(v.resolved) < v.resolver.cfg.RefreshInterval ||
+		v.now().Before(v.retryAt)) {
+		return v.val, nil
+	}
+
+	val, err := v.resolver.Resolve(ctx, v.ref)
+	if err != nil {
+		if v.resolved.IsZero() {
+			return "", err
+		}
+		v.failures++
+		backoff := v.refreshBackoff()
+		v.retryAt = v.now().Add(backoff)
+
+		v.resolver.logger.Warn().
+			Err(err).
+			Str("secret", v.ref).
+			Dur("retry-in", backoff).
+			Msg("failed to refresh secret, using previous value")
+		return v.val, nil
+	}
+
+	if !v.resolved.IsZero() && val != v.val {
+		v.resolver.logger.Info().Str("secret", v.ref).Msg("secret has been rotated")
+	}
+	v.val, v.resolved = val, v.now()
+	v.failures, v.retryAt = 0, time.Time{}
+	return v.val, nil
+}
+
+// refreshBackoff returns the delay before retrying the refresh, given the number of consecutive
+// failures. The delay is capped at the refresh interval.
+func (v *Value) refreshBackoff() time.Duration {
+	backoff := minRefreshBackoff
+	for i := 1; i < v.failures && backoff < v.resolver.cfg.RefreshInterval; i++ {
+		backoff *= 2
+	}
+	if backoff > v.resolver.cfg.RefreshInterval {
+		backoff = v.resolver.cfg.RefreshInterval
+	}
+	return backoff
+}
@@ -0,0 +1,132 @@
+package secret
+
+import (
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sherpa-secret")
+	assert.Nil(t, err)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "token")
+	assert.Nil(t, ioutil.WriteFile(path, []byte("file-secret\n"), 0600))
+
+	assert.Nil(t, os.Setenv("SHERPA_TEST_SECRET", "env-secret"))
+	defer os.Unsetenv("SHERPA_TEST_SECRET")
+
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch r.URL.Path {
+		case "/v1/secret/data/newrelic":
+			_, _ = w.Write([]byte(`{"data":{"data":{"api_key":"kv2-secret"},"metadata":{"version":2}}}`))
+		case "/v1/kv/influxdb":
+			_, _ = w.Write([]byte(`{"data":{"token":"kv1-secret"}}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer vault.Close()
+
+	r := NewResolver(Config{VaultAddr: vault.URL, VaultToken: "root"}, zerolog.Nop())
+
+	testCases := []struct {
+		name          string
+		ref           string
+		expected      string
+		expectedError bool
+	}{
+		{name: "plain value", ref: "plain-secret", expected: "plain-secret"},
+		{name: "env reference", ref: "env://SHERPA_TEST_SECRET", expected: "env-secret"},
+		{name: "unset env reference", ref: "env://SHERPA_TEST_UNSET", expectedError: true},
+		{name: "file reference", ref: "file://" + path, expected: "file-secret"},
+		{name: "missing file reference", ref: "file://" + filepath.Join(dir, "missing"), expectedError: true},
+		{name: "vault kv2 reference", ref: "vault://secret/data/newrelic#api_key", expected: "kv2-secret"},
+		{name: "vault kv1 reference", ref: "vault://kv/influxdb#token", expected: "kv1-secret"},
+		{name: "vault missing field", ref: "vault://kv/influxdb#password", expectedError: true},
+		{name: "vault missing path", ref: "vault://kv/missing#token", expectedError: true},
+		{name: "vault reference without field", ref: "vault://kv/influxdb", expectedError: true},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual, err := r.Resolve(context.Background(), tc.ref)
+			if tc.expectedError {
+				assert.NotNil(t, err)
+				return
+			}
+			assert.Nil(t, err)
+			assert.Equal(t, tc.expected, actual)
+		})
+	}
+}
+
+func TestValue_Get(t *testing.T) {
+	assert.Nil(t, os.Setenv("SHERPA_TEST_ROTATE", "first"))
+	defer os.Unsetenv("SHERPA_TEST_ROTATE")
+
+	now := time.Unix(1000, 0)
+	r := NewResolver(Config{RefreshInterval: time.Minute}, zerolog.Nop())
+	v := r.Value("env://SHERPA_TEST_ROTATE")
+	v.now = func() time.Time { return now }
+
+	val, err := v.Get(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "first", val)
+
+	// The rotated secret is not picked up until the refresh interval has passed.
+	assert.Nil(t, os.Setenv("SHERPA_TEST_ROTATE", "second"))
+	val, _ = v.Get(context.Background())
+	assert.Equal(t, "first", val)
+
+	now = now.Add(time.Minute)
+	val, _ = v.Get(context.Background())
+	assert.Equal(t, "second", val)
+
+	// A failed refresh continues to use the previous secret.
+	assert.Nil(t, os.Unsetenv("SHERPA_TEST_ROTATE"))
+	now = now.Add(time.Minute)
+	val, err = v.Get(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "second", val)
+
+	// The failed refresh is not retried until the backoff has passed, and the backoff doubles
+	// with each consecutive failure.
+	assert.Nil(t, os.Setenv("SHERPA_TEST_ROTATE", "third"))
+	now = now.Add(minRefreshBackoff - time.Second)
+	val, _ = v.Get(context.Background())
+	assert.Equal(t, "second", val)
+
+	assert.Nil(t, os.Unsetenv("SHERPA_TEST_ROTATE"))
+	now = now.Add(time.Second)
+	val, _ = v.Get(context.Background())
+	assert.Equal(t, "second", val)
+	assert.Equal(t, 2, v.failures)
+	assert.Equal(t, now.Add(2*minRefreshBackoff), v.retryAt)
+
+	// A successful refresh resets the backoff.
+	assert.Nil(t, os.Setenv("SHERPA_TEST_ROTATE", "third"))
+	now = now.Add(2 * minRefreshBackoff)
+	val, _ = v.Get(context.Background())
+	assert.Equal(t, "third", val)
+	assert.Equal(t, 0, v.failures)
+
+	// Values without a resolver, or which are not references, are used as is.
+	var nilResolver *Resolver
+	val, err = nilResolver.Value("env://SHERPA_TEST_ROTATE").Get(context.Background())
+	assert.Nil(t, err)
+	assert.Equal(t, "env://SHERPA_TEST_ROTATE", val)
+}
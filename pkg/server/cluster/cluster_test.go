@@ -3,6 +3,8 @@ package cluster
 import (
 	"testing"
 
+	"github.com/jrasell/sherpa/pkg/state/cluster/memory"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -44,3 +46,19 @@ func TestMember_verifyClusterName(t *testing.T) {
 		}
 	}
 }
+
+func TestMember_CheckFence(t *testing.T) {
+	store := memory.NewStateBackend()
+	m := Member{clusterStorage: store, logger: zerolog.Nop()}
+
+	// A member which has not obtained leadership should fail the fence check.
+	assert.Equal(t, ErrNotLeader, m.CheckFence())
+
+	assert.Nil(t, m.setAsLeader())
+	assert.Nil(t, m.CheckFence())
+
+	// Another server obtaining leadership increments the fencing token, superseding the member.
+	_, err := store.IncrementFencingToken()
+	assert.Nil(t, err)
+	assert.Equal(t, ErrFenced, m.CheckFence())
+}
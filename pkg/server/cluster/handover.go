@@ -0,0 +1,209 @@
+package cluster
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/build"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/pkg/errors"
+)
+
+const (
+	// handoverCheckInterval is the interval at which the leader checks the storage backend for
+	// handover requests from other servers.
+	handoverCheckInterval = 5 * time.Second
+
+	// handoverRequestTTL is the time after which a handover request which has not been completed
+	// is discarded, so a request from a server which failed to start does not move leadership.
+	handoverRequestTTL = 5 * time.Minute
+
+	// handoverHoldoff is the time after handing over leadership during which the server does not
+	// contend for leadership, allowing the requesting server to obtain the lock.
+	handoverHoldoff = time.Minute
+
+	updateMsgHandedOverLeadership = "handed over leadership"
+)
+
+var (
+	// ErrHandoverUnsupported is returned when a handover is requested but the cluster does not
+	// use a HA storage backend, or evaluation sharding is enabled and so no handover is needed.
+	ErrHandoverUnsupported = errors.New("leadership handover requires a HA storage backend with evaluation sharding disabled")
+
+	// ErrHandoverInProgress is returned when a handover is triggered while another is underway.
+	ErrHandoverInProgress = errors.New("leadership handover is already in progress")
+)
+
+// HandoverFunc is called by the leader when handing over leadership. It must stop the autoscaler,
+// waiting for in-flight evaluations to finish, and return the autoscaler internal state.
+type HandoverFunc func() ([]byte, error)
+
+// SetHandoverFunc sets the function called by the leader when handing over leadership. It must be
+// called before the leadership loop is started.
+func (m *Member) SetHandoverFunc(f HandoverFunc) { m.handoverFunc = f }
+
+// HandoverSupported returns whether leadership can be handed over between servers.
+func (m *Member) HandoverSupported() bool { return m.clusterStorageHA && !m.sharding }
+
+// RequestHandover writes a request for the current leader to hand over leadership to this server.
+// It is used by newly started servers, such as during a rolling upgrade, so that the autoscaler
+// moves to the new server without a gap in evaluation.
+func (m *Member) RequestHandover() error {
+	if !m.HandoverSupported() {
+		return ErrHandoverUnsupported
+	}
+
+	req := state.Handover{
+		RequestedBy: m.id,
+		Version:     build.Version,
+		RequestTime: time.Now().UTC().UnixNano(),
+	}
+	if err := m.clusterStorage.PutHandover(&req); err != nil {
+		return errors.Wrap(err, "failed to write leadership handover request")
+	}
+	return nil
+}
+
+// Handover triggers this server to hand over leadership, if it is the leader. The handover is
+// performed asynchronously by the leadership loop.
+func (m *Member) Handover() error {
+	if !m.HandoverSupported() {
+		return ErrHandoverUnsupported
+	}
+
+	m.stateLock.RLock()
+	standby := m.standby
+	m.stateLock.RUnlock()
+
+	if standby {
+		return ErrNotLeader
+	}
+
+	select {
+	case m.handoverCh <- struct{}{}:
+		return nil
+	default:
+		return ErrHandoverInProgress
+	}
+}
+
+// TakeHandover returns the last completed handover if it completed within the passed duration,
+// so that the new leader can restore the autoscaler state. The completed handover is deleted once
+// read, so the state is only restored once.
+func (m *Member) TakeHandover(ttl time.Duration) (*state.Handover, error) {
+	handover, err := m.clusterStorage.GetHandover()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read leadership handover")
+	}
+	if handover == nil || handover.IsPending() {
+		return nil, nil
+	}
+
+	if err := m.clusterStorage.DeleteHandover(); err != nil {
+		return nil, errors.Wrap(err, "failed to delete completed leadership handover")
+	}
+
+	if time.Since(time.Unix(0, handover.CompleteTime)) > ttl {
+		return nil, nil
+	}
+	return handover, nil
+}
+
+// watchHandoverRequests periodically checks for handover requests from other servers while this
+// server is the leader, triggering the handover when one is found.
+func (m *Member) watchHandoverRequests(stopCh chan struct{}) {
+	t := time.NewTicker(handoverCheckInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			m.stateLock.RLock()
+			standby := m.standby
+			m.stateLock.RUnlock()
+
+			if standby {
+				continue
+			}
+
+			pending, err := m.pendingHandover(time.Now())
+			if err != nil {
+				m.logger.Error().Err(err).Msg("failed to check for leadership handover requests")
+				continue
+			}
+			if pending {
+				select {
+				case m.handoverCh <- struct{}{}:
+				default:
+				}
+			}
+
+		case <-stopCh:
+			return
+		}
+	}
+}
+
+// pendingHandover returns whether another server has requested a handover which has not expired.
+// Expired requests, and requests made by this server, are deleted as there is no leader to hand
+// over from.
+func (m *Member) pendingHandover(t time.Time) (bool, error) {
+	handover, err := m.clusterStorage.GetHandover()
+	if err != nil {
+		return false, err
+	}
+	if handover == nil || !handover.IsPending() {
+		return false, nil
+	}
+
+	if handover.RequestedBy == m.id || t.Sub(time.Unix(0, handover.RequestTime)) > handoverRequestTTL {
+		return false, m.clusterStorage.DeleteHandover()
+	}
+	return true, nil
+}
+
+// handOver stops the autoscaler using the handover function, and writes the completed handover
+// including the autoscaler internal state to the backend for the next leader to restore. The
+// fencing token is cleared once in-flight evaluations have finished, so that no further scaling
+// actions pass the fence check.
+func (m *Member) handOver() {
+	m.logger.Info().Msg("handing over cluster leadership")
+
+	handover, err := m.clusterStorage.GetHandover()
+	if err != nil {
+		m.logger.Error().Err(err).Msg("failed to read leadership handover request")
+	}
+	if handover == nil || !handover.IsPending() {
+		handover = &state.Handover{RequestTime: time.Now().UTC().UnixNano()}
+	}
+
+	if m.handoverFunc != nil {
+		s, err := m.handoverFunc()
+		if err != nil {
+			m.logger.Error().Err(err).Msg("failed to capture autoscaler state, the next leader will start without it")
+		} else {
+			handover.State = s
+		}
+	}
+
+	atomic.StoreUint64(&m.fencingToken, 0)
+
+	handover.CompletedBy = m.id
+	handover.CompleteTime = time.Now().UTC().UnixNano()
+
+	if err := m.clusterStorage.PutHandover(handover); err != nil {
+		m.logger.Error().Err(err).Msg("failed to write completed leadership handover")
+	}
+
+	// Discard any handover triggered while this one was in progress.
+	select {
+	case <-m.handoverCh:
+	default:
+	}
+
+	m.logger.Info().
+		Str("requested-by", handover.RequestedBy.String()).
+		Str("requested-version", handover.Version).
+		Msg("cluster leadership handed over")
+	m.UpdateChan <- &MembershipUpdate{IsLeader: false, Msg: updateMsgHandedOverLeadership}
+}
@@ -0,0 +1,140 @@
+package cluster
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/jrasell/sherpa/pkg/state/cluster/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newHandoverTestMember(t *testing.T) *Member {
+	id, err := uuid.NewV4()
+	assert.Nil(t, err)
+
+	return &Member{
+		id:               id,
+		clusterStorage:   memory.NewStateBackend(),
+		clusterStorageHA: true,
+		logger:           zerolog.Nop(),
+		standby:          true,
+		handoverCh:       make(chan struct{}, 1),
+		UpdateChan:       make(chan *MembershipUpdate),
+	}
+}
+
+func TestMember_pendingHandover(t *testing.T) {
+	m := newHandoverTestMember(t)
+	now := time.Now()
+
+	other, err := uuid.NewV4()
+	assert.Nil(t, err)
+
+	pending, err := m.pendingHandover(now)
+	assert.Nil(t, err)
+	assert.False(t, pending)
+
+	// A request from another server is pending.
+	assert.Nil(t, m.clusterStorage.PutHandover(&state.Handover{RequestedBy: other, RequestTime: now.UnixNano()}))
+	pending, err = m.pendingHandover(now)
+	assert.Nil(t, err)
+	assert.True(t, pending)
+
+	// An expired request is discarded.
+	pending, err = m.pendingHandover(now.Add(handoverRequestTTL + time.Second))
+	assert.Nil(t, err)
+	assert.False(t, pending)
+
+	handover, err := m.clusterStorage.GetHandover()
+	assert.Nil(t, err)
+	assert.Nil(t, handover)
+
+	// A request made by the leader itself is discarded.
+	assert.Nil(t, m.clusterStorage.PutHandover(&state.Handover{RequestedBy: m.id, RequestTime: now.UnixNano()}))
+	pending, err = m.pendingHandover(now)
+	assert.Nil(t, err)
+	assert.False(t, pending)
+}
+
+func TestMember_Handover(t *testing.T) {
+	m := newHandoverTestMember(t)
+	m.SetHandoverFunc(func() ([]byte, error) { return []byte(`{"Cooldowns":{}}`), nil })
+
+	// Handovers are rejected when not the leader, or without a HA storage backend.
+	assert.Equal(t, ErrNotLeader, m.Handover())
+
+	m.clusterStorageHA = false
+	assert.Equal(t, ErrHandoverUnsupported, m.Handover())
+	assert.Equal(t, ErrHandoverUnsupported, m.RequestHandover())
+	m.clusterStorageHA = true
+
+	other, err := uuid.NewV4()
+	assert.Nil(t, err)
+	assert.Nil(t, m.clusterStorage.PutHandover(&state.Handover{RequestedBy: other, Version: "0.5.0", RequestTime: time.Now().UnixNano()}))
+
+	stopCh := make(chan struct{})
+	doneCh := make(chan struct{})
+
+	go func() {
+		m.waitForLeadership(stopCh)
+		close(doneCh)
+	}()
+
+	msg := <-m.UpdateChan
+	assert.True(t, msg.IsLeader)
+
+	assert.Nil(t, m.Handover())
+
+	msg = <-m.UpdateChan
+	assert.False(t, msg.IsLeader)
+	assert.Equal(t, updateMsgHandedOverLeadership, msg.Msg)
+
+	// The leader has stopped scaling and handed over its state, completing the request.
+	assert.Equal(t, ErrNotLeader, m.CheckFence())
+
+	handover, err := m.clusterStorage.GetHandover()
+	assert.Nil(t, err)
+	assert.False(t, handover.IsPending())
+	assert.Equal(t, other, handover.RequestedBy)
+	assert.Equal(t, "0.5.0", handover.Version)
+	assert.Equal(t, m.id, handover.CompletedBy)
+	assert.JSONEq(t, `{"Cooldowns":{}}`, string(handover.State))
+
+	close(stopCh)
+	<-doneCh
+}
+
+func TestMember_TakeHandover(t *testing.T) {
+	m := newHandoverTestMember(t)
+
+	// Pending handovers are not taken.
+	assert.Nil(t, m.RequestHandover())
+	handover, err := m.TakeHandover(time.Minute)
+	assert.Nil(t, err)
+	assert.Nil(t, handover)
+
+	completed := state.Handover{CompleteTime: time.Now().Add(-2 * time.Minute).UnixNano(), State: []byte(`{}`)}
+
+	// Completed handovers are returned once.
+	assert.Nil(t, m.clusterStorage.PutHandover(&completed))
+	handover, err = m.TakeHandover(5 * time.Minute)
+	assert.Nil(t, err)
+	assert.NotNil(t, handover)
+
+	handover, err = m.TakeHandover(5 * time.Minute)
+	assert.Nil(t, err)
+	assert.Nil(t, handover)
+
+	// Stale handovers are discarded.
+	assert.Nil(t, m.clusterStorage.PutHandover(&completed))
+	handover, err = m.TakeHandover(time.Minute)
+	assert.Nil(t, err)
+	assert.Nil(t, handover)
+
+	stored, err := m.clusterStorage.GetHandover()
+	assert.Nil(t, err)
+	assert.Nil(t, stored)
+}
@@ -5,9 +5,11 @@ import (
 	"time"
 
 	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/retry"
 	"github.com/jrasell/sherpa/pkg/state"
 	"github.com/jrasell/sherpa/pkg/state/cluster"
 	"github.com/oklog/run"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -16,16 +18,29 @@ const (
 	// leader.
 	leaderCheckInterval = 2500 * time.Millisecond
 
-	// lockRetryInterval is the interval we re-attempt to acquire the HA lock if an error is
-	// encountered.
+	// lockRetryInterval is the maximum interval we re-attempt to acquire the HA lock if an error
+	// is encountered.
 	lockRetryInterval = 10 * time.Second
 )
 
+// lockRetryPolicy controls the backoff between attempts to acquire the HA lock. Acquisition is
+// retried until it succeeds or the server stops, so the policy does not limit attempts.
+var lockRetryPolicy = retry.Policy{BaseDelay: time.Second, MaxDelay: lockRetryInterval}
+
 const (
 	updateMsgObtainedLeadership = "obtained leadership"
 	updateMsgLostLeadership     = "lost leadership"
 )
 
+var (
+	// ErrNotLeader is returned by CheckFence when the server is not the cluster leader.
+	ErrNotLeader = errors.New("server is not the cluster leader")
+
+	// ErrFenced is returned by CheckFence when another server has obtained leadership since this
+	// server became leader.
+	ErrFenced = errors.New("server leadership has been superseded by another server")
+)
+
 func (m *Member) RunLeadershipLoop() {
 
 	// Group collects functions and runs them concurrently. When one function returns, all funcs
@@ -66,6 +81,32 @@ func (m *Member) RunLeadershipLoop() {
 		})
 	}
 
+	if m.HandoverSupported() {
+		// Watch for handover requests from other servers while leader.
+		handoverStopCh := make(chan struct{})
+
+		g.Add(func() error {
+			m.watchHandoverRequests(handoverStopCh)
+			return nil
+		}, func(error) {
+			close(handoverStopCh)
+			m.logger.Debug().Msg("shutting down leadership handover watcher")
+		})
+	}
+
+	if m.sharding {
+		// Maintain the member registration and shard ring used to distribute evaluations.
+		shardStopCh := make(chan struct{})
+
+		g.Add(func() error {
+			m.runShardMembership(shardStopCh)
+			return nil
+		}, func(error) {
+			close(shardStopCh)
+			m.logger.Debug().Msg("shutting down evaluation shard membership handler")
+		})
+	}
+
 	if err := g.Run(); err != nil {
 		m.logger.Error().Err(err).Msg("failed to correctly start leadership loop actors")
 	}
@@ -139,11 +180,38 @@ func (m *Member) Leader() (bool, string, string, error) {
 	return false, leader.Addr, leader.AdvertiseAddr, nil
 }
 
+// CheckFence returns an error if the server is not the cluster leader, or if another server has
+// obtained leadership since this server became leader. During a brief dual-leader scenario, only
+// the server holding the latest fencing token passes the check, preventing a server which has lost
+// leadership, but has not yet been informed, from performing scaling actions. When evaluation
+// sharding is enabled, every member performs scaling actions, so the check instead ensures the
+// server is a member of the current shard ring.
+func (m *Member) CheckFence() error {
+	if m.sharding {
+		return m.checkShardFence()
+	}
+
+	token := atomic.LoadUint64(&m.fencingToken)
+	if token == 0 {
+		return ErrNotLeader
+	}
+
+	current, err := m.clusterStorage.GetFencingToken()
+	if err != nil {
+		return errors.Wrap(err, "failed to read cluster fencing token")
+	}
+	if current != token {
+		return ErrFenced
+	}
+	return nil
+}
+
 // ClearLeadership is used to coordinate the shutdown of the cluster membership processes so we can
 // exist cleanly. This allows other servers to quickly take over as leader and therefore resume
 // cluster operations.
 func (m *Member) ClearLeadership() {
 	m.logger.Info().Msg("shutting down leadership handler")
+	atomic.StoreUint64(&m.fencingToken, 0)
 	m.stopChan <- struct{}{}
 	close(m.stopChan)
 
@@ -159,7 +227,7 @@ func (m *Member) ClearLeadership() {
 }
 
 func (m *Member) acquireLock(lock cluster.BackendLock, stopCh <-chan struct{}) <-chan struct{} {
-	for {
+	for attempt := 0; ; attempt++ {
 		// Attempt lock acquisition.
 		leaderLostCh, err := lock.Acquire(stopCh)
 		if err == nil {
@@ -169,7 +237,7 @@ func (m *Member) acquireLock(lock cluster.BackendLock, stopCh <-chan struct{}) <
 		// Retry the acquisition.
 		m.logger.Error().Err(err).Msg("failed to acquire lock")
 		select {
-		case <-time.After(lockRetryInterval):
+		case <-time.After(retry.Backoff(lockRetryPolicy, attempt)):
 		case <-stopCh:
 			return nil
 		}
@@ -226,14 +294,23 @@ func (m *Member) waitForLeadership(stopCh chan struct{}) {
 		m.UpdateChan <- &MembershipUpdate{IsLeader: true, Msg: updateMsgObtainedLeadership}
 		m.stateLock.Unlock()
 
-		// Block on either being stopped, or in the event that we lose leadership.
+		// Block on either being stopped, losing leadership, or being asked to hand over
+		// leadership.
+		var handedOver bool
+
 		select {
 		case <-leaderLostCh:
 			// If we have lost leadership, inform the server so that Sherpa process can be stopped,
-			// then continue through the rest of the loop.
+			// then continue through the rest of the loop. The fencing token is cleared first so
+			// that no further scaling actions pass the fence check.
+			atomic.StoreUint64(&m.fencingToken, 0)
 			m.logger.Warn().Msg("cluster leadership has been lost")
 			m.UpdateChan <- &MembershipUpdate{IsLeader: false, Msg: updateMsgLostLeadership}
 
+		case <-m.handoverCh:
+			m.handOver()
+			handedOver = true
+
 		case <-stopCh:
 			// If we are told to stop, then we should just return here. Another process is
 			// responsible for performing shutdown cleanup.
@@ -263,6 +340,16 @@ func (m *Member) waitForLeadership(stopCh chan struct{}) {
 			}
 			m.stateLock.Unlock()
 		}
+
+		// Having handed over leadership, hold off from contending for it so that the requesting
+		// server is able to obtain the lock.
+		if handedOver {
+			select {
+			case <-time.After(handoverHoldoff):
+			case <-stopCh:
+				return
+			}
+		}
 	}
 }
 
@@ -324,7 +411,19 @@ func (m *Member) setAsLeader() error {
 	go m.clusterStorage.DeleteLeaderEntries(m.id)
 
 	leaderEntry := state.ClusterMember{ID: m.id, Addr: m.addr, AdvertiseAddr: m.advAddr}
-	return m.clusterStorage.PutClusterLeader(&leaderEntry)
+	if err := m.clusterStorage.PutClusterLeader(&leaderEntry); err != nil {
+		return err
+	}
+
+	// Obtain a new fencing token for this leadership term. Any previous leader will fail its
+	// fence check from this point.
+	token, err := m.clusterStorage.IncrementFencingToken()
+	if err != nil {
+		return errors.Wrap(err, "failed to obtain cluster fencing token")
+	}
+	atomic.StoreUint64(&m.fencingToken, token)
+	m.logger.Debug().Uint64("fencing-token", token).Msg("obtained cluster fencing token")
+	return nil
 }
 
 // removeAsLeader removes our backend storage entry within the leader partition.
@@ -2,6 +2,7 @@ package cluster
 
 import (
 	"sync"
+	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/jrasell/sherpa/pkg/state/cluster"
@@ -22,6 +23,10 @@ type Member struct { // nolint:maligned
 
 	clusterLock cluster.BackendLock
 
+	// fencingToken is the cluster fencing token obtained when this server became leader, and is 0
+	// when the server is not the leader. It is accessed atomically.
+	fencingToken uint64
+
 	stateLock sync.RWMutex
 	logger    zerolog.Logger
 
@@ -39,6 +44,21 @@ type Member struct { // nolint:maligned
 	clusterLeaderAddr          string
 	clusterLeaderAdvertiseAddr string
 
+	// sharding indicates whether autoscaling evaluation is sharded across all healthy members.
+	// The shard ring assigns jobs to the healthy members, and is rebuilt on each heartbeat.
+	sharding       bool
+	shardLock      sync.RWMutex
+	shardRing      *hashRing
+	shardRefreshed time.Time
+
+	// membership is the optional source of healthy members used to build the shard ring.
+	membership MembershipSource
+
+	// handoverCh triggers the leader to hand over leadership, and handoverFunc is called during
+	// the handover to stop the autoscaler and capture its internal state.
+	handoverCh   chan struct{}
+	handoverFunc HandoverFunc
+
 	// stopChan is used by the cluster member to coordinate the stopping of background tasks.
 	stopChan chan struct{}
 
@@ -75,6 +95,7 @@ func NewMember(log zerolog.Logger, store cluster.Backend, addr, advAddr, name st
 		clusterName:      name,
 		UpdateChan:       make(chan *MembershipUpdate),
 		stopChan:         make(chan struct{}),
+		handoverCh:       make(chan struct{}, 1),
 		standby:          true,
 	}
 
@@ -91,3 +112,6 @@ func NewMember(log zerolog.Logger, store cluster.Backend, addr, advAddr, name st
 }
 
 func (m *Member) IsHA() bool { return m.clusterStorageHA }
+
+// ID returns the unique identifier of the cluster member.
+func (m *Member) ID() uuid.UUID { return m.id }
@@ -0,0 +1,87 @@
+package cluster
+
+import (
+	"hash/fnv"
+	"sort"
+	"strconv"
+)
+
+// hashRingReplicas is the number of points each member is placed at on the hash ring. Using
+// multiple points per member evens out the distribution of jobs across the members.
+const hashRingReplicas = 64
+
+// hashRing is a consistent hash ring which assigns jobs to cluster members. When a member joins or
+// leaves the ring, only the jobs owned by that member move, limiting the disruption caused by
+// membership changes.
+type hashRing struct {
+	members []string
+	points  []uint32
+	owners  map[uint32]string
+}
+
+// newHashRing builds the hash ring from the member IDs.
+func newHashRing(members []string) *hashRing {
+	r := hashRing{
+		members: make([]string, len(members)),
+		owners:  make(map[uint32]string, len(members)*hashRingReplicas),
+	}
+	copy(r.members, members)
+	sort.Strings(r.members)
+
+	for _, member := range r.members {
+		for i := 0; i < hashRingReplicas; i++ {
+			point := hashKey(member + "-" + strconv.Itoa(i))
+
+			// In the unlikely event of a collision, the lowest member ID keeps the point so that
+			// all members build an identical ring.
+			if _, ok := r.owners[point]; ok {
+				continue
+			}
+			r.owners[point] = member
+			r.points = append(r.points, point)
+		}
+	}
+
+	sort.Slice(r.points, func(i, j int) bool { return r.points[i] < r.points[j] })
+	return &r
+}
+
+// owner returns the member ID which owns the key, or an empty string if the ring has no members.
+func (r *hashRing) owner(key string) string {
+	if len(r.points) == 0 {
+		return ""
+	}
+
+	h := hashKey(key)
+
+	idx := sort.Search(len(r.points), func(i int) bool { return r.points[i] >= h })
+	if idx == len(r.points) {
+		idx = 0
+	}
+	return r.owners[r.points[idx]]
+}
+
+// equal returns whether the ring was built from the same members as the passed member IDs, which
+// must be sorted.
+func (r *hashRing) equal(members []string) bool {
+	if len(r.members) != len(members) {
+		return false
+	}
+	for i := range members {
+		if r.members[i] != members[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *hashRing) contains(member string) bool {
+	idx := sort.SearchStrings(r.members, member)
+	return idx < len(r.members) && r.members[idx] == member
+}
+
+func hashKey(key string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(key))
+	return h.Sum32()
+}
@@ -0,0 +1,178 @@
+package cluster
+
+import (
+	"sort"
+	"time"
+
+	sendMetrics "github.com/armon/go-metrics"
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/pkg/errors"
+)
+
+const (
+	// memberHeartbeatInterval is the interval at which a member refreshes its registration and
+	// the hash ring when evaluation sharding is enabled.
+	memberHeartbeatInterval = 5 * time.Second
+
+	// memberHeartbeatTTL is the time after its last heartbeat at which a member is considered
+	// unhealthy, and its jobs are rebalanced to the remaining members.
+	memberHeartbeatTTL = 3 * memberHeartbeatInterval
+)
+
+// ErrNotShardMember is returned by CheckFence when evaluation sharding is enabled and the server
+// is not a member of the current hash ring.
+var ErrNotShardMember = errors.New("server is not a healthy member of the evaluation shard ring")
+
+// MembershipSource provides the IDs of the healthy cluster members, such as the gossip layer.
+type MembershipSource interface {
+	AliveMembers() []string
+}
+
+// EnableSharding configures the member to shard autoscaling evaluation across all the healthy
+// cluster members, rather than the leader performing all evaluations. It must be called before
+// the leadership loop is started.
+func (m *Member) EnableSharding() { m.sharding = true }
+
+// SetMembershipSource sets the source of healthy members used to build the shard ring, in place
+// of the member registrations within the storage backend. It must be called before the leadership
+// loop is started.
+func (m *Member) SetMembershipSource(src MembershipSource) { m.membership = src }
+
+// ShardingEnabled returns whether evaluation sharding is enabled.
+func (m *Member) ShardingEnabled() bool { return m.sharding }
+
+// OwnsJob returns whether this member is responsible for evaluating the job. When sharding is
+// disabled, the member owns all jobs, as only the leader runs the autoscaler. When enabled, the
+// job is owned by the member it hashes to on the ring of healthy members.
+func (m *Member) OwnsJob(job string) bool {
+	if !m.sharding {
+		return true
+	}
+
+	m.shardLock.RLock()
+	defer m.shardLock.RUnlock()
+
+	if m.shardRing == nil || !m.shardRingFresh(time.Now()) {
+		return false
+	}
+	return m.shardRing.owner(job) == m.id.String()
+}
+
+// checkShardFence returns an error if the member is not part of a recently refreshed hash ring.
+// A member which cannot refresh its registration may have been expired by the other members, and
+// its jobs reassigned, so it must not perform scaling actions.
+func (m *Member) checkShardFence() error {
+	m.shardLock.RLock()
+	defer m.shardLock.RUnlock()
+
+	if m.shardRing == nil || !m.shardRingFresh(time.Now()) || !m.shardRing.contains(m.id.String()) {
+		return ErrNotShardMember
+	}
+	return nil
+}
+
+// shardRingFresh returns whether the hash ring was refreshed within the heartbeat TTL. The caller
+// must hold the shard lock.
+func (m *Member) shardRingFresh(now time.Time) bool {
+	return now.Sub(m.shardRefreshed) <= memberHeartbeatTTL
+}
+
+// runShardMembership periodically refreshes the member registration and rebuilds the hash ring
+// from the healthy members, until the stop channel is closed.
+func (m *Member) runShardMembership(stopCh chan struct{}) {
+	m.logger.Info().Msg("starting evaluation shard membership handler")
+
+	t := time.NewTicker(memberHeartbeatInterval)
+	defer t.Stop()
+
+	for {
+		if err := m.refreshShardRing(time.Now()); err != nil {
+			m.logger.Error().Err(err).Msg("failed to refresh evaluation shard membership")
+		}
+
+		select {
+		case <-t.C:
+		case <-stopCh:
+			if err := m.clusterStorage.DeleteClusterMember(m.id); err != nil {
+				m.logger.Error().Err(err).Msg("failed to remove cluster member registration")
+			}
+			return
+		}
+	}
+}
+
+// refreshShardRing rebuilds the hash ring from the healthy members. When the healthy members
+// change, the jobs are rebalanced across the new ring on the next autoscaling run.
+func (m *Member) refreshShardRing(now time.Time) error {
+	healthy, err := m.healthyMembers(now)
+	if err != nil {
+		return err
+	}
+
+	m.shardLock.Lock()
+	defer m.shardLock.Unlock()
+
+	if m.shardRing == nil || !m.shardRing.equal(healthy) {
+		m.logger.Info().
+			Int("members", len(healthy)).
+			Msg("evaluation shard membership changed, rebalancing job ownership")
+		sendMetrics.IncrCounter([]string{"cluster", "shard", "rebalance"}, 1)
+		m.shardRing = newHashRing(healthy)
+	}
+	m.shardRefreshed = now
+
+	sendMetrics.SetGauge([]string{"cluster", "shard", "members"}, float32(len(healthy)))
+	return nil
+}
+
+// healthyMembers returns the sorted IDs of the healthy members. If a membership source is set it
+// is used, otherwise the member heartbeat is registered within the storage backend, and members
+// whose heartbeat has expired are removed.
+func (m *Member) healthyMembers(now time.Time) ([]string, error) {
+	if m.membership != nil {
+		healthy := m.membership.AliveMembers()
+		sort.Strings(healthy)
+		return healthy, nil
+	}
+
+	self := state.ClusterMember{ID: m.id, Addr: m.addr, AdvertiseAddr: m.advAddr, Heartbeat: now.UnixNano()}
+	if err := m.clusterStorage.PutClusterMember(&self); err != nil {
+		return nil, errors.Wrap(err, "failed to register cluster member")
+	}
+
+	members, err := m.clusterStorage.GetClusterMembers()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list cluster members")
+	}
+
+	healthy, expired := partitionMembers(members, now)
+
+	for _, id := range expired {
+		m.logger.Info().Str("member-id", id.String()).Msg("removing expired cluster member registration")
+		if err := m.clusterStorage.DeleteClusterMember(id); err != nil {
+			m.logger.Error().Err(err).Str("member-id", id.String()).Msg("failed to remove expired cluster member")
+		}
+	}
+	return healthy, nil
+}
+
+// partitionMembers splits the member entries into the sorted IDs of the healthy members, and the
+// IDs of the members whose heartbeat has expired.
+func partitionMembers(members []*state.ClusterMember, now time.Time) ([]string, []uuid.UUID) {
+	var (
+		healthy []string
+		expired []uuid.UUID
+	)
+
+	for _, member := range members {
+		if now.Sub(time.Unix(0, member.Heartbeat)) > memberHeartbeatTTL {
+			expired = append(expired, member.ID)
+			continue
+		}
+		healthy = append(healthy, member.ID.String())
+	}
+
+	sort.Strings(healthy)
+	return healthy, expired
+}
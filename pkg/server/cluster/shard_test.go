@@ -0,0 +1,119 @@
+package cluster
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/jrasell/sherpa/pkg/state/cluster/memory"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_hashRing(t *testing.T) {
+	assert.Equal(t, "", newHashRing(nil).owner("example"))
+
+	ring := newHashRing([]string{"c", "a", "b"})
+	assert.True(t, ring.equal([]string{"a", "b", "c"}))
+	assert.False(t, ring.equal([]string{"a", "b"}))
+	assert.True(t, ring.contains("b"))
+	assert.False(t, ring.contains("d"))
+
+	// All members should own a share of the jobs.
+	owned := make(map[string]int)
+	for i := 0; i < 300; i++ {
+		owned[ring.owner(fmt.Sprintf("job-%d", i))]++
+	}
+	assert.Len(t, owned, 3)
+
+	// Removing a member should only move the jobs owned by that member.
+	smaller := newHashRing([]string{"a", "b"})
+	for i := 0; i < 300; i++ {
+		job := fmt.Sprintf("job-%d", i)
+		if before := ring.owner(job); before != "c" {
+			assert.Equal(t, before, smaller.owner(job), job)
+		}
+	}
+}
+
+func Test_partitionMembers(t *testing.T) {
+	now := time.Now()
+	idA, idB, idC := uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4()), uuid.Must(uuid.NewV4())
+
+	healthy, expired := partitionMembers([]*state.ClusterMember{
+		{ID: idA, Heartbeat: now.UnixNano()},
+		{ID: idB, Heartbeat: now.Add(-memberHeartbeatTTL - time.Second).UnixNano()},
+		{ID: idC, Heartbeat: now.Add(-memberHeartbeatInterval).UnixNano()},
+	}, now)
+
+	expectedHealthy := []string{idA.String(), idC.String()}
+	if expectedHealthy[0] > expectedHealthy[1] {
+		expectedHealthy[0], expectedHealthy[1] = expectedHealthy[1], expectedHealthy[0]
+	}
+	assert.Equal(t, expectedHealthy, healthy)
+	assert.Equal(t, []uuid.UUID{idB}, expired)
+}
+
+func TestMember_Sharding(t *testing.T) {
+	store := memory.NewStateBackend()
+	now := time.Now()
+
+	newMember := func() *Member {
+		m := &Member{id: uuid.Must(uuid.NewV4()), clusterStorage: store, logger: zerolog.Nop()}
+		m.EnableSharding()
+		return m
+	}
+
+	// Without sharding, the member owns all jobs.
+	assert.True(t, (&Member{}).OwnsJob("example"))
+
+	a, b := newMember(), newMember()
+
+	// Before the ring is built, the member owns no jobs and fails the fence check.
+	assert.False(t, a.OwnsJob("example"))
+	assert.Equal(t, ErrNotShardMember, a.CheckFence())
+
+	assert.Nil(t, a.refreshShardRing(now))
+	assert.Nil(t, b.refreshShardRing(now))
+	assert.Nil(t, a.refreshShardRing(now))
+
+	assert.Nil(t, a.CheckFence())
+	assert.Nil(t, b.CheckFence())
+
+	// Each job should be owned by exactly one member.
+	for i := 0; i < 50; i++ {
+		job := fmt.Sprintf("job-%d", i)
+		assert.NotEqual(t, a.OwnsJob(job), b.OwnsJob(job), job)
+	}
+
+	// Once member b stops sending heartbeats, member a rebalances to own all jobs.
+	assert.Nil(t, a.refreshShardRing(now.Add(memberHeartbeatTTL+time.Second)))
+	for i := 0; i < 50; i++ {
+		assert.True(t, a.OwnsJob(fmt.Sprintf("job-%d", i)))
+	}
+
+	members, err := store.GetClusterMembers()
+	assert.Nil(t, err)
+	assert.Len(t, members, 1)
+}
+
+type fakeMembershipSource []string
+
+func (f fakeMembershipSource) AliveMembers() []string { return f }
+
+func TestMember_SetMembershipSource(t *testing.T) {
+	m := &Member{id: uuid.Must(uuid.NewV4()), clusterStorage: memory.NewStateBackend(), logger: zerolog.Nop()}
+	m.EnableSharding()
+	m.SetMembershipSource(fakeMembershipSource{m.id.String()})
+
+	assert.Nil(t, m.refreshShardRing(time.Now()))
+	assert.True(t, m.OwnsJob("example"))
+	assert.Nil(t, m.CheckFence())
+
+	// The member is not registered within the storage backend when using a membership source.
+	members, err := m.clusterStorage.GetClusterMembers()
+	assert.Nil(t, err)
+	assert.Len(t, members, 0)
+}
@@ -6,13 +6,51 @@ import (
 
 type Config struct {
 	Debug          bool
+	Chaos          *serverCfg.ChaosConfig
 	Cluster        *serverCfg.ClusterConfig
+	ClusterScaling *serverCfg.ClusterScalingConfig
+	JobFilter      *serverCfg.JobFilterConfig
 	MetricProvider *serverCfg.MetricProviderConfig
+	Nomad          *serverCfg.NomadConfig
+	Notify         *serverCfg.NotifyConfig
+	Secrets        *serverCfg.SecretsConfig
 	Server         *serverCfg.Config
 	TLS            *serverCfg.TLSConfig
 	Telemetry      *serverCfg.TelemetryConfig
 }
 
+// redacted returns a copy of the config with secret values redacted, so the effective config can
+// be returned by the API.
+func (c *Config) redacted() *Config {
+	out := *c
+
+	if c.Cluster != nil {
+		cluster := c.Cluster.Redacted()
+		out.Cluster = &cluster
+	}
+	if c.ClusterScaling != nil {
+		scaling := c.ClusterScaling.Redacted()
+		out.ClusterScaling = &scaling
+	}
+	if c.MetricProvider != nil {
+		provider := c.MetricProvider.Redacted()
+		out.MetricProvider = &provider
+	}
+	if c.Notify != nil {
+		notify := c.Notify.Redacted()
+		out.Notify = &notify
+	}
+	if c.Secrets != nil {
+		secrets := c.Secrets.Redacted()
+		out.Secrets = &secrets
+	}
+	if c.Server != nil {
+		server := c.Server.Redacted()
+		out.Server = &server
+	}
+	return &out
+}
+
 const (
 	routeUIRedirectName    = "UIRedirect"
 	routeUIRedirectPattern = "/"
@@ -23,8 +61,12 @@ const (
 	routeGetScalingStatusName               = "GetScalingStatus"
 	routeGetScalingInfoPattern              = "/v1/scale/status/{id}"
 	routeGetScalingInfoName                 = "GetScalingInfo"
+	routeGetScalingReportPattern            = "/v1/scale/report"
+	routeGetScalingReportName               = "GetScalingReport"
 	routeScaleOutJobGroupName               = "ScaleOutJobGroup"
 	routeScaleOutJobGroupPattern            = "/v1/scale/out/{job_id}/{group}"
+	routePostScaleCountJobGroupName         = "ScaleCountJobGroup"
+	routePostScaleCountJobGroupPattern      = "/v1/scale/count/{job_id}/{group}"
 	routeScaleInJobGroupName                = "ScaleInJobGroup"
 	routeScaleInJobGroupPattern             = "/v1/scale/in/{job_id}/{group}"
 	routePostScaleOutJobGroupName           = "ScaleOutJobGroup"
@@ -33,6 +75,16 @@ const (
 	routePostScaleInJobGroupPattern         = "/v1/scale/in/{job_id}/{group}"
 	routeGetJobScalingPoliciesName          = "GetJobScalingPolicies"
 	routeGetJobScalingPoliciesPattern       = "/v1/policies"
+	routeDeleteScalingPoliciesName          = "DeleteScalingPolicies"
+	routeDeleteScalingPoliciesPattern       = "/v1/policies"
+	routeGetPolicySchemaName                = "GetPolicySchema"
+	routeGetPolicySchemaPattern             = "/v1/policies/schema"
+	routeGetPolicyRolloutsName              = "GetPolicyRollouts"
+	routeGetPolicyRolloutsPattern           = "/v1/policies/rollouts"
+	routePostPolicyRolloutPromoteName       = "PostPolicyRolloutPromote"
+	routePostPolicyRolloutPromotePattern    = "/v1/policies/rollouts/{job_id}/promote"
+	routeDeletePolicyRolloutName            = "DeletePolicyRollout"
+	routeDeletePolicyRolloutPattern         = "/v1/policies/rollouts/{job_id}"
 	routeGetJobScalingPolicyName            = "GetJobScalingPolicy"
 	routeGetJobScalingPolicyPattern         = "/v1/policy/{job_id}"
 	routeGetJobGroupScalingPolicyName       = "GetJobGroupScalingPolicy"
@@ -53,12 +105,72 @@ const (
 
 // System server routes.
 const (
-	routeGetSystemLeaderName    = "GetSystemLeader"
-	routeGetSystemLeaderPattern = "/v1/system/leader"
-	routeSystemHealthName       = "GetSystemHealth"
-	routeSystemHealthPattern    = "/v1/system/health"
-	routeSystemInfoName         = "GetSystemInfo"
-	routeSystemInfoPattern      = "/v1/system/info"
+	routeGetSystemLeaderName      = "GetSystemLeader"
+	routeGetSystemLeaderPattern   = "/v1/system/leader"
+	routeSystemHealthName         = "GetSystemHealth"
+	routeSystemHealthPattern      = "/v1/system/health"
+	routeSystemInfoName           = "GetSystemInfo"
+	routeSystemInfoPattern        = "/v1/system/info"
+	routePutSystemLogLevelName    = "PutSystemLogLevel"
+	routePutSystemLogLevelPattern = "/v1/system/loglevel"
+	routeGetSystemConfigName      = "GetSystemConfig"
+	routeGetSystemConfigPattern   = "/v1/system/config"
+	routeGetSystemMembersName     = "GetSystemMembers"
+	routeGetSystemMembersPattern  = "/v1/system/members"
+	routePutSystemHandoverName    = "PutSystemHandover"
+	routePutSystemHandoverPattern = "/v1/system/handover"
+)
+
+// Metric provider server routes.
+const (
+	routeGetProvidersStatusName    = "GetProvidersStatus"
+	routeGetProvidersStatusPattern = "/v1/providers/status"
+)
+
+// Notify server routes.
+const (
+	routeGetNotifyMutesName           = "GetNotifyMutes"
+	routeGetNotifyMutesPattern        = "/v1/notify/mutes"
+	routePutNotifyMuteJobName         = "PutNotifyMuteJob"
+	routePutNotifyMuteJobPattern      = "/v1/notify/mute/{job_id}"
+	routePutNotifyMuteGroupName       = "PutNotifyMuteGroup"
+	routePutNotifyMuteGroupPattern    = "/v1/notify/mute/{job_id}/{group}"
+	routeDeleteNotifyMuteJobName      = "DeleteNotifyMuteJob"
+	routeDeleteNotifyMuteJobPattern   = "/v1/notify/mute/{job_id}"
+	routeDeleteNotifyMuteGroupName    = "DeleteNotifyMuteGroup"
+	routeDeleteNotifyMuteGroupPattern = "/v1/notify/mute/{job_id}/{group}"
+)
+
+// Autoscaler server routes.
+const (
+	routePostAutoscalerEvaluateJobName         = "PostAutoscalerEvaluateJob"
+	routePostAutoscalerEvaluateJobPattern      = "/v1/autoscaler/evaluate/{job_id}"
+	routePostAutoscalerEvaluateJobGroupName    = "PostAutoscalerEvaluateJobGroup"
+	routePostAutoscalerEvaluateJobGroupPattern = "/v1/autoscaler/evaluate/{job_id}/{group}"
+	routeGetAutoscalerOverridesName            = "GetAutoscalerOverrides"
+	routeGetAutoscalerOverridesPattern         = "/v1/autoscaler/overrides"
+	routePutAutoscalerOverrideName             = "PutAutoscalerOverride"
+	routePutAutoscalerOverridePattern          = "/v1/autoscaler/override/{job_id}/{group}/{check}"
+	routeDeleteAutoscalerOverrideName          = "DeleteAutoscalerOverride"
+	routeDeleteAutoscalerOverridePattern       = "/v1/autoscaler/override/{job_id}/{group}/{check}"
+	routeGetAutoscalerStateName                = "GetAutoscalerState"
+	routeGetAutoscalerStatePattern             = "/v1/autoscaler/state"
+	routePutAutoscalerStateName                = "PutAutoscalerState"
+	routePutAutoscalerStatePattern             = "/v1/autoscaler/state"
+)
+
+// Cluster scaling server routes.
+const (
+	routeGetClusterClassesName           = "GetClusterClasses"
+	routeGetClusterClassesPattern        = "/v1/cluster/classes"
+	routeGetClusterDecisionsName         = "GetClusterDecisions"
+	routeGetClusterDecisionsPattern      = "/v1/cluster/decisions"
+	routeGetClusterInterruptionsName     = "GetClusterInterruptions"
+	routeGetClusterInterruptionsPattern  = "/v1/cluster/interruptions"
+	routePostClusterInterruptionName     = "PostClusterInterruption"
+	routePostClusterInterruptionPattern  = "/v1/cluster/interruptions"
+	routeGetClusterScaleInPreviewName    = "GetClusterScaleInPreview"
+	routeGetClusterScaleInPreviewPattern = "/v1/cluster/scale-in/preview"
 )
 
 // Debug server routes.
@@ -0,0 +1,36 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/jrasell/sherpa/pkg/server/gossip"
+	"github.com/rs/zerolog"
+)
+
+// GossipServer exposes the cluster membership discovered using gossip.
+type GossipServer struct {
+	logger zerolog.Logger
+	gossip *gossip.Gossip
+}
+
+// SystemMembersResp is the response of the cluster members endpoint.
+type SystemMembersResp struct {
+	Members []*gossip.Node
+}
+
+func NewGossipServer(l zerolog.Logger, g *gossip.Gossip) *GossipServer {
+	return &GossipServer{logger: l, gossip: g}
+}
+
+// GetMembers returns the cluster members known to the server, along with their observed health.
+func (g *GossipServer) GetMembers(w http.ResponseWriter, r *http.Request) {
+	out, err := json.Marshal(&SystemMembersResp{Members: g.gossip.Members()})
+	if err != nil {
+		g.logger.Error().Err(err).Msg("failed to marshal HTTP response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONResponse(w, out)
+}
@@ -0,0 +1,25 @@
+package v1
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/server/gossip"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGossipServer_GetMembers(t *testing.T) {
+	g, err := gossip.New(&gossip.Config{Logger: zerolog.Nop(), ID: "a", Addr: "http://a:8000", BindAddr: "127.0.0.1"})
+	assert.Nil(t, err)
+	defer g.Stop()
+
+	s := NewGossipServer(zerolog.Nop(), g)
+
+	w := httptest.NewRecorder()
+	s.GetMembers(w, httptest.NewRequest(http.MethodGet, "/v1/system/members", nil))
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"ID":"a","Addr":"http://a:8000"`)
+	assert.Contains(t, w.Body.String(), `"Status":"alive"`)
+}
@@ -7,8 +7,10 @@ import (
 
 	metrics "github.com/armon/go-metrics"
 	"github.com/gofrs/uuid"
-	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/build"
+	"github.com/jrasell/sherpa/pkg/client"
 	serverCfg "github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/logger"
 	"github.com/jrasell/sherpa/pkg/server/cluster"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -23,9 +25,11 @@ const (
 	defaultHealthResp           = "{\"status\":\"ok\"}"
 	defaultAPIPolicyResp        = "Sherpa API"
 	defaultMetaPolicyResp       = "Nomad Job Group Meta"
+	defaultFilePolicyResp       = "Policy Files"
 	defaultDisabledPolicyResp   = "Disabled"
 	defaultStorageBackend       = "In Memory"
 	defaultStorageBackendConsul = "Consul"
+	defaultStorageBackendPG     = "PostgreSQL"
 )
 
 var (
@@ -36,9 +40,25 @@ var (
 type SystemServer struct {
 	logger    zerolog.Logger
 	member    *cluster.Member
-	nomad     *api.Client
+	nomad     *client.NomadPool
 	server    *serverCfg.Config
 	telemetry *metrics.InmemSink
+
+	// config is the effective server configuration with secrets redacted, returned as is by the
+	// config endpoint.
+	config interface{}
+
+	// components are the optional components enabled on the server, included within the info
+	// response.
+	components *SystemComponents
+}
+
+// SystemComponents describes the optional components enabled on the server. Features lists the
+// experimental features of the server, and whether each is enabled.
+type SystemComponents struct {
+	MetricProviders []string
+	Notifiers       []string
+	Features        map[string]bool
 }
 
 type SystemInfoResp struct {
@@ -47,6 +67,11 @@ type SystemInfoResp struct {
 	StorageBackend            string
 	InternalAutoScalingEngine bool
 	StrictPolicyChecking      bool
+
+	Build           build.Info
+	MetricProviders []string
+	Notifiers       []string
+	Features        map[string]bool
 }
 
 type SystemStatusResp struct {
@@ -56,6 +81,13 @@ type SystemStatusResp struct {
 	Version     string
 }
 
+// SystemLogLevelReq is the request to change the server log level and component level overrides. An
+// empty Level leaves the server level unchanged, and an empty component level removes the override.
+type SystemLogLevelReq struct {
+	Level      string
+	Components map[string]string
+}
+
 type SystemLeaderResp struct {
 	IsSelf               bool
 	HAEnabled            bool
@@ -63,13 +95,20 @@ type SystemLeaderResp struct {
 	LeaderClusterAddress string
 }
 
-func NewSystemServer(l zerolog.Logger, nomad *api.Client, server *serverCfg.Config, tel *metrics.InmemSink, mem *cluster.Member) *SystemServer {
+func NewSystemServer(l zerolog.Logger, nomad *client.NomadPool, server *serverCfg.Config, tel *metrics.InmemSink,
+	mem *cluster.Member, config interface{}, components *SystemComponents) *SystemServer {
+	if components == nil {
+		components = &SystemComponents{}
+	}
+
 	return &SystemServer{
-		logger:    l,
-		member:    mem,
-		nomad:     nomad,
-		server:    server,
-		telemetry: tel,
+		logger:     l,
+		member:     mem,
+		nomad:      nomad,
+		server:     server,
+		telemetry:  tel,
+		config:     config,
+		components: components,
 	}
 }
 
@@ -79,17 +118,25 @@ func (s *SystemServer) GetHealth(w http.ResponseWriter, r *http.Request) {
 
 func (s *SystemServer) GetInfo(w http.ResponseWriter, r *http.Request) {
 	resp := &SystemInfoResp{
-		NomadAddress:              s.nomad.Address(),
+		NomadAddress:              s.nomad.Client().Address(),
 		StrictPolicyChecking:      s.server.StrictPolicyChecking,
 		InternalAutoScalingEngine: s.server.InternalAutoScaler,
 		PolicyEngine:              defaultDisabledPolicyResp,
 		StorageBackend:            defaultStorageBackend,
+		Build:                     build.GetInfo(),
+		MetricProviders:           s.components.MetricProviders,
+		Notifiers:                 s.components.Notifiers,
+		Features:                  s.components.Features,
 	}
 
 	if s.server.ConsulStorageBackend {
 		resp.StorageBackend = defaultStorageBackendConsul
 	}
 
+	if s.server.PostgresStorageBackendURL != "" {
+		resp.StorageBackend = defaultStorageBackendPG
+	}
+
 	if s.server.APIPolicyEngine {
 		resp.PolicyEngine = defaultAPIPolicyResp
 	}
@@ -98,6 +145,10 @@ func (s *SystemServer) GetInfo(w http.ResponseWriter, r *http.Request) {
 		resp.PolicyEngine = defaultMetaPolicyResp
 	}
 
+	if s.server.PolicyEngineFileDir != "" {
+		resp.PolicyEngine = defaultFilePolicyResp
+	}
+
 	out, err := json.Marshal(resp)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("failed to marshal HTTP response")
@@ -108,6 +159,19 @@ func (s *SystemServer) GetInfo(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, out)
 }
 
+// GetConfig returns the effective configuration of the server, merged from flags, environment
+// variables and the config file, with secret values redacted.
+func (s *SystemServer) GetConfig(w http.ResponseWriter, r *http.Request) {
+	out, err := json.Marshal(s.config)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to marshal HTTP response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONResponse(w, out)
+}
+
 func (s *SystemServer) GetLeader(w http.ResponseWriter, r *http.Request) {
 
 	// Pull the leadership information from the local member.
@@ -135,6 +199,54 @@ func (s *SystemServer) GetLeader(w http.ResponseWriter, r *http.Request) {
 	writeJSONResponse(w, out)
 }
 
+// PutHandover triggers the server, which must be the cluster leader, to hand over leadership to
+// another server. The handover is performed asynchronously, with the leader stopping the autoscaler
+// and handing over its state before releasing leadership.
+func (s *SystemServer) PutHandover(w http.ResponseWriter, r *http.Request) {
+	switch err := s.member.Handover(); err {
+	case nil:
+		s.logger.Info().Msg("leadership handover triggered using the API")
+		w.WriteHeader(http.StatusAccepted)
+	case cluster.ErrHandoverUnsupported, cluster.ErrHandoverInProgress, cluster.ErrNotLeader:
+		http.Error(w, err.Error(), http.StatusConflict)
+	default:
+		s.logger.Error().Err(err).Msg("failed to trigger leadership handover")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+// PutLogLevel changes the log level of the server, and optionally the components, without
+// requiring a restart. The response details the levels in place after the change.
+func (s *SystemServer) PutLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req SystemLogLevelReq
+
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		s.logger.Error().Err(err).Msg("failed to decode request body")
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if err := logger.SetLevels(req.Level, req.Components); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	levels := logger.GetLevels()
+	s.logger.Info().
+		Str("level", levels.Level).
+		Interface("components", levels.Components).
+		Msg("server log level updated")
+
+	out, err := json.Marshal(levels)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("failed to marshal HTTP response")
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	writeJSONResponse(w, out)
+}
+
 func (s *SystemServer) GetMetrics(w http.ResponseWriter, r *http.Request) {
 	if format := r.URL.Query().Get("format"); format == "prometheus" {
 		s.prometheusHandler().ServeHTTP(w, r)
@@ -1,17 +1,23 @@
 package v1
 
 import (
+	"encoding/json"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
+	"github.com/jrasell/sherpa/pkg/build"
 	"github.com/jrasell/sherpa/pkg/client"
 	"github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/logger"
+	"github.com/jrasell/sherpa/pkg/server/cluster"
+	"github.com/jrasell/sherpa/pkg/state/cluster/memory"
 	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestSystem_GetHealth(t *testing.T) {
-	s := NewSystemServer(zerolog.Logger{}, nil, nil, nil, nil)
+	s := NewSystemServer(zerolog.Logger{}, nil, nil, nil, nil, nil, nil)
 
 	r := httptest.NewRequest("GET", "http://jrasell.com/v1/system/health", nil)
 	w := httptest.NewRecorder()
@@ -24,41 +30,131 @@ func TestSystem_GetHealth(t *testing.T) {
 func TestSystem_GetInfo(t *testing.T) {
 	testCases := []struct {
 		systemServerConfig *server.Config
+		components         *SystemComponents
 		expectedRespCode   int
-		expectedRespBody   string
+		expectedResp       SystemInfoResp
 	}{
 		{
 			systemServerConfig: &server.Config{APIPolicyEngine: true, ConsulStorageBackend: true},
 			expectedRespCode:   200,
-			expectedRespBody:   "{\"NomadAddress\":\"http://127.0.0.1:4646\",\"PolicyEngine\":\"Sherpa API\",\"StorageBackend\":\"Consul\",\"InternalAutoScalingEngine\":false,\"StrictPolicyChecking\":false}",
+			expectedResp: SystemInfoResp{NomadAddress: "http://127.0.0.1:4646", PolicyEngine: "Sherpa API",
+				StorageBackend: "Consul"},
 		},
 		{
 			systemServerConfig: &server.Config{NomadMetaPolicyEngine: true},
 			expectedRespCode:   200,
-			expectedRespBody:   "{\"NomadAddress\":\"http://127.0.0.1:4646\",\"PolicyEngine\":\"Nomad Job Group Meta\",\"StorageBackend\":\"In Memory\",\"InternalAutoScalingEngine\":false,\"StrictPolicyChecking\":false}",
+			expectedResp: SystemInfoResp{NomadAddress: "http://127.0.0.1:4646", PolicyEngine: "Nomad Job Group Meta",
+				StorageBackend: "In Memory"},
 		},
 		{
-			systemServerConfig: &server.Config{APIPolicyEngine: true, InternalAutoScaler: true},
+			systemServerConfig: &server.Config{PolicyEngineFileDir: "/etc/sherpa/policies"},
 			expectedRespCode:   200,
-			expectedRespBody:   "{\"NomadAddress\":\"http://127.0.0.1:4646\",\"PolicyEngine\":\"Sherpa API\",\"StorageBackend\":\"In Memory\",\"InternalAutoScalingEngine\":true,\"StrictPolicyChecking\":false}",
+			expectedResp: SystemInfoResp{NomadAddress: "http://127.0.0.1:4646", PolicyEngine: "Policy Files",
+				StorageBackend: "In Memory"},
+		},
+		{
+			systemServerConfig: &server.Config{APIPolicyEngine: true, InternalAutoScaler: true},
+			components: &SystemComponents{
+				MetricProviders: []string{"prometheus"},
+				Notifiers:       []string{"grafana"},
+				Features:        map[string]bool{"shadow-mode": false},
+			},
+			expectedRespCode: 200,
+			expectedResp: SystemInfoResp{NomadAddress: "http://127.0.0.1:4646", PolicyEngine: "Sherpa API",
+				StorageBackend: "In Memory", InternalAutoScalingEngine: true, MetricProviders: []string{"prometheus"},
+				Notifiers: []string{"grafana"}, Features: map[string]bool{"shadow-mode": false}},
 		},
 		{
 			systemServerConfig: &server.Config{},
 			expectedRespCode:   200,
-			expectedRespBody:   "{\"NomadAddress\":\"http://127.0.0.1:4646\",\"PolicyEngine\":\"Disabled\",\"StorageBackend\":\"In Memory\",\"InternalAutoScalingEngine\":false,\"StrictPolicyChecking\":false}",
+			expectedResp: SystemInfoResp{NomadAddress: "http://127.0.0.1:4646", PolicyEngine: "Disabled",
+				StorageBackend: "In Memory"},
 		},
 	}
 
-	nomadClient, _ := client.NewNomadClient()
+	nomadPool, _ := client.NewNomadPool(zerolog.Logger{}, nil, 0)
 
 	for _, tc := range testCases {
 		r := httptest.NewRequest("GET", "http://jrasell.com/v1/system/info", nil)
 		w := httptest.NewRecorder()
 
-		s := NewSystemServer(zerolog.Logger{}, nomadClient, tc.systemServerConfig, nil, nil)
+		s := NewSystemServer(zerolog.Logger{}, nomadPool, tc.systemServerConfig, nil, nil, nil, tc.components)
 		s.GetInfo(w, r)
 
+		assert.Equal(t, tc.expectedRespCode, w.Code)
+
+		var actual SystemInfoResp
+		assert.Nil(t, json.Unmarshal(w.Body.Bytes(), &actual))
+
+		tc.expectedResp.Build = build.GetInfo()
+		assert.Equal(t, tc.expectedResp, actual)
+	}
+}
+
+func TestSystem_PutLogLevel(t *testing.T) {
+	defer func() { _ = logger.SetLevels("info", map[string]string{logger.ComponentAutoscale: ""}) }()
+
+	testCases := []struct {
+		reqBody          string
+		expectedRespCode int
+		expectedRespBody string
+	}{
+		{
+			reqBody:          "{\"Components\":{\"autoscale\":\"debug\"}}",
+			expectedRespCode: 200,
+			expectedRespBody: "{\"Level\":\"info\",\"Components\":{\"autoscale\":\"debug\"}}",
+		},
+		{
+			reqBody:          "{\"Level\":\"warn\",\"Components\":{\"autoscale\":\"\"}}",
+			expectedRespCode: 200,
+			expectedRespBody: "{\"Level\":\"warn\",\"Components\":{}}",
+		},
+		{
+			reqBody:          "{\"Level\":\"verbose\"}",
+			expectedRespCode: 400,
+			expectedRespBody: "unsupported error level: \"verbose\" (supported levels: debug info warn error fatal)\n",
+		},
+		{
+			reqBody:          "{\"Components\":{\"metrics\":\"debug\"}}",
+			expectedRespCode: 400,
+			expectedRespBody: "unsupported log component: \"metrics\" (supported components: autoscale scale api cluster policy state watcher)\n",
+		},
+	}
+
+	s := NewSystemServer(zerolog.Logger{}, nil, nil, nil, nil, nil, nil)
+
+	for _, tc := range testCases {
+		r := httptest.NewRequest("PUT", "http://jrasell.com/v1/system/loglevel", strings.NewReader(tc.reqBody))
+		w := httptest.NewRecorder()
+		s.PutLogLevel(w, r)
+
 		assert.Equal(t, tc.expectedRespCode, w.Code)
 		assert.Equal(t, tc.expectedRespBody, w.Body.String())
 	}
 }
+
+func TestSystemServer_GetConfig(t *testing.T) {
+	cfg := struct{ Server *server.Config }{Server: &server.Config{Bind: "127.0.0.1", Port: 8000}}
+	s := NewSystemServer(zerolog.Logger{}, nil, nil, nil, nil, cfg, nil)
+
+	w := httptest.NewRecorder()
+	s.GetConfig(w, httptest.NewRequest("GET", "http://jrasell.com/v1/system/config", nil))
+
+	assert.Equal(t, 200, w.Code)
+	assert.Contains(t, w.Body.String(), `"Bind":"127.0.0.1"`)
+	assert.Contains(t, w.Body.String(), `"Port":8000`)
+}
+
+func TestSystemServer_PutHandover(t *testing.T) {
+	mem, err := cluster.NewMember(zerolog.Nop(), memory.NewStateBackend(), "127.0.0.1:8000", "http://127.0.0.1:8000", "")
+	assert.Nil(t, err)
+
+	s := NewSystemServer(zerolog.Nop(), nil, nil, nil, mem, nil, nil)
+
+	// The in-memory storage backend does not support HA, so leadership cannot be handed over.
+	w := httptest.NewRecorder()
+	s.PutHandover(w, httptest.NewRequest("PUT", "http://jrasell.com/v1/system/handover", nil))
+
+	assert.Equal(t, 409, w.Code)
+	assert.Contains(t, w.Body.String(), cluster.ErrHandoverUnsupported.Error())
+}
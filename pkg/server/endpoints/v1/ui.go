@@ -88,6 +88,7 @@ var tmplScaleEvent = template.Must(template.New("scaling-events").Parse(`
             thead += '<th>Count</th>';
             thead += '<th>Status</th>';
             thead += '<th>Time</th>';
+            thead += '<th>Runbook</th>';
             thead += '</tr></thead>';
             var $tbody = $('<tbody />');
             for (var [id, job] of Object.entries(events)) {
@@ -99,6 +100,7 @@ var tmplScaleEvent = template.Must(template.New("scaling-events").Parse(`
                     $tr.append($('<td />').text(event.Details.Count))
                     $tr.append($('<td />').text(event.Status));
                     $tr.append($('<td />').text(timeConverter(event.Time)));
+                    $tr.append(runbookCell(event));
                     $tr.appendTo($tbody);
                 }
             }
@@ -106,6 +108,17 @@ var tmplScaleEvent = template.Must(template.New("scaling-events").Parse(`
             $table.empty().append($(thead)).append($tbody);
         }
 
+        function runbookCell(event) {
+            var $td = $('<td />');
+            if (/^https?:\/\//.test(event.RunbookURL || '')) {
+                $td.append($('<a />').attr('href', event.RunbookURL).attr('target', '_blank').text('Runbook'));
+            }
+            if (event.Notes) {
+                $td.attr('title', event.Notes);
+            }
+            return $td;
+        }
+
         function timeConverter(ts){
             var a = new Date(ts/1000000);
             var year = a.getUTCFullYear();
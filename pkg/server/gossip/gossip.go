@@ -0,0 +1,375 @@
+// Package gossip implements the gossip layer which Sherpa servers use to discover each other and
+// share their health without relying on the storage backend. Membership and failure detection are
+// provided by hashicorp/memberlist, while the Sherpa state of each server, such as its API address
+// and whether it holds the leadership lock, is gossiped as memberlist node meta.
+package gossip
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"log"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+const (
+	// joinRetryInterval is the time between attempts to join the cluster using the join addresses,
+	// which are made while no other members are known.
+	joinRetryInterval = 5 * time.Second
+
+	// reapTimeout is the time after which members which have failed or left are forgotten.
+	reapTimeout = 5 * time.Minute
+
+	// broadcastTimeout bounds the time waited for updates of the local server state, and the
+	// notification of leaving the cluster, to be broadcast to peers.
+	broadcastTimeout = 5 * time.Second
+)
+
+// Status is the health status of a cluster member, as observed by the local member.
+type Status string
+
+const (
+	StatusAlive Status = "alive"
+	StatusDead  Status = "dead"
+	StatusLeft  Status = "left"
+)
+
+// Node is the gossiped state of a single Sherpa server.
+type Node struct {
+	// ID is the unique identifier of the server, which is its cluster member ID.
+	ID string
+
+	// Addr is the advertise address of the server's Sherpa API.
+	Addr string
+
+	// GossipAddr is the address the server gossips using.
+	GossipAddr string
+
+	// Leader indicates whether the server holds the cluster leadership lock.
+	Leader bool
+
+	// Status is the health of the server as observed by the local server.
+	Status Status
+}
+
+// meta is the Sherpa state of a server, which is gossiped as its memberlist node meta.
+type meta struct {
+	Addr   string
+	Leader bool `json:",omitempty"`
+
+	// Left is set before the server gracefully leaves the cluster, so that peers can distinguish
+	// it from a failed server.
+	Left bool `json:",omitempty"`
+}
+
+// Config is the configuration used to create the gossip layer.
+type Config struct {
+	Logger zerolog.Logger
+
+	// ID and Addr identify the local server, and its Sherpa API advertise address.
+	ID   string
+	Addr string
+
+	// BindAddr and BindPort are the address and port the gossip layer listens on. If the port is
+	// zero, a free port is used.
+	BindAddr string
+	BindPort int
+
+	// Join are the gossip addresses of servers contacted to join the cluster. If an address does
+	// not include a port, the bind port is used.
+	Join []string
+
+	// Key is the optional shared key used to encrypt gossip between servers.
+	Key string
+}
+
+// Gossip maintains the local view of the cluster membership.
+type Gossip struct {
+	logger zerolog.Logger
+	id     string
+	join   []string
+	list   *memberlist.Memberlist
+
+	// nodes is the local view of the members, which is maintained using the memberlist events
+	// rather than read from memberlist, as the memberlist nodes are updated without holding a
+	// lock the caller can use.
+	lock  sync.RWMutex
+	meta  meta
+	nodes map[string]*nodeState
+
+	doneChan chan struct{}
+}
+
+// nodeState is the local record of a member. Departed is set when the member fails or leaves the
+// cluster, and the record is kept until the reap timeout so the member can still be listed.
+type nodeState struct {
+	node     Node
+	departed time.Time
+}
+
+// New creates the gossip layer for the local server, which starts listening on the bind address.
+// The cluster is not joined until Run is called.
+func New(cfg *Config) (*Gossip, error) {
+	g := Gossip{
+		logger:   cfg.Logger,
+		id:       cfg.ID,
+		join:     cfg.Join,
+		meta:     meta{Addr: strings.TrimSuffix(cfg.Addr, "/")},
+		nodes:    make(map[string]*nodeState),
+		doneChan: make(chan struct{}),
+	}
+
+	mlCfg := memberlist.DefaultLANConfig()
+	mlCfg.Name = cfg.ID
+	if cfg.BindAddr != "" {
+		mlCfg.BindAddr = cfg.BindAddr
+	}
+	mlCfg.BindPort = cfg.BindPort
+	mlCfg.AdvertisePort = cfg.BindPort
+	mlCfg.Delegate = &delegate{gossip: &g}
+	mlCfg.Events = &events{gossip: &g}
+	mlCfg.Logger = log.New(&logWriter{logger: cfg.Logger}, "", 0)
+
+	// Memberlist requires an AES key, so the shared key is hashed to provide an AES-256 key.
+	if cfg.Key != "" {
+		key := sha256.Sum256([]byte(cfg.Key))
+		mlCfg.SecretKey = key[:]
+	}
+
+	list, err := memberlist.Create(mlCfg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to start gossip")
+	}
+	g.list = list
+
+	return &g, nil
+}
+
+// Run joins the cluster and maintains the local view of departed members, and only stops when
+// Stop() is called. While no other members are known, such as when the join addresses were not
+// yet running, joining the cluster is periodically retried.
+func (g *Gossip) Run() {
+	g.logger.Info().
+		Strs("join", g.join).
+		Str("gossip-addr", g.list.LocalNode().Address()).
+		Msg("starting cluster gossip")
+
+	g.joinCluster()
+
+	t := time.NewTicker(joinRetryInterval)
+	defer t.Stop()
+
+	for {
+		select {
+		case <-t.C:
+			g.joinCluster()
+			g.reap(time.Now())
+		case <-g.doneChan:
+			return
+		}
+	}
+}
+
+// Stop informs peers that the server is leaving the cluster, and stops the gossip layer.
+func (g *Gossip) Stop() {
+	g.lock.Lock()
+	g.meta.Left = true
+	g.lock.Unlock()
+
+	if err := g.list.UpdateNode(broadcastTimeout); err != nil {
+		g.logger.Debug().Err(err).Msg("failed to notify peers of cluster leave")
+	}
+	if err := g.list.Leave(broadcastTimeout); err != nil {
+		g.logger.Error().Err(err).Msg("failed to leave cluster gossip")
+	}
+	if err := g.list.Shutdown(); err != nil {
+		g.logger.Error().Err(err).Msg("failed to shutdown cluster gossip")
+	}
+	close(g.doneChan)
+}
+
+// SetLeader updates whether the local server holds the cluster leadership lock. The update is
+// broadcast to peers in the background, so that leadership changes are not delayed by
+// unreachable peers.
+func (g *Gossip) SetLeader(leader bool) {
+	g.lock.Lock()
+	g.meta.Leader = leader
+	if self, ok := g.nodes[g.id]; ok {
+		self.node.Leader = leader
+	}
+	g.lock.Unlock()
+
+	go func() {
+		if err := g.list.UpdateNode(broadcastTimeout); err != nil {
+			g.logger.Debug().Err(err).Msg("failed to broadcast leadership update")
+		}
+	}()
+}
+
+// Members returns all the known members, including the local server and members which have
+// recently failed or left, with the health status observed by the local server. The members are
+// sorted by ID.
+func (g *Gossip) Members() []*Node {
+	g.lock.RLock()
+	out := make([]*Node, 0, len(g.nodes))
+	for _, ns := range g.nodes {
+		n := ns.node
+		out = append(out, &n)
+	}
+	g.lock.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].ID < out[j].ID })
+	return out
+}
+
+// AliveMembers returns the sorted IDs of the members considered alive, including the local server.
+// Members which memberlist suspects of failing are included, as they may only be briefly
+// unreachable.
+func (g *Gossip) AliveMembers() []string {
+	var out []string
+	for _, n := range g.Members() {
+		if n.Status == StatusAlive {
+			out = append(out, n.ID)
+		}
+	}
+	return out
+}
+
+// joinCluster attempts to join the cluster using the join addresses, if no other members are
+// known.
+func (g *Gossip) joinCluster() {
+	if len(g.join) == 0 || g.list.NumMembers() > 1 {
+		return
+	}
+
+	if n, err := g.list.Join(g.join); err != nil {
+		g.logger.Warn().Strs("join", g.join).Err(err).Msg("failed to join cluster gossip")
+	} else {
+		g.logger.Info().Int("contacted", n).Msg("joined cluster gossip")
+	}
+}
+
+// reap removes the departed members which failed or left longer ago than the reap timeout.
+func (g *Gossip) reap(now time.Time) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+
+	for id, ns := range g.nodes {
+		if !ns.departed.IsZero() && now.Sub(ns.departed) > reapTimeout {
+			g.logger.Debug().Str("member-id", id).Msg("forgetting departed cluster member")
+			delete(g.nodes, id)
+		}
+	}
+}
+
+// node converts the memberlist node to the gossiped state of the Sherpa server. Meta which cannot
+// be decoded is logged, and the node returned without it.
+func (g *Gossip) node(n *memberlist.Node, status Status) *Node {
+	m := g.decodeMeta(n)
+	return &Node{ID: n.Name, Addr: m.Addr, GossipAddr: n.Address(), Leader: m.Leader, Status: status}
+}
+
+func (g *Gossip) decodeMeta(n *memberlist.Node) meta {
+	var m meta
+
+	if len(n.Meta) > 0 {
+		if err := json.Unmarshal(n.Meta, &m); err != nil {
+			g.logger.Error().Str("member-id", n.Name).Err(err).Msg("failed to decode cluster member meta")
+		}
+	}
+	return m
+}
+
+// delegate provides the local server meta to memberlist. Sherpa does not send any other user
+// data using the gossip layer.
+type delegate struct {
+	gossip *Gossip
+}
+
+func (d *delegate) NodeMeta(limit int) []byte {
+	d.gossip.lock.RLock()
+	defer d.gossip.lock.RUnlock()
+
+	out, err := json.Marshal(&d.gossip.meta)
+	if err != nil || len(out) > limit {
+		d.gossip.logger.Error().Err(err).Int("limit", limit).Msg("failed to encode local cluster member meta")
+		return nil
+	}
+	return out
+}
+
+func (d *delegate) NotifyMsg([]byte)                           {}
+func (d *delegate) GetBroadcasts(overhead, limit int) [][]byte { return nil }
+func (d *delegate) LocalState(join bool) []byte                { return nil }
+func (d *delegate) MergeRemoteState(buf []byte, join bool)     {}
+
+// events maintains the local view of the members as they join, update, fail or leave the
+// cluster.
+type events struct {
+	gossip *Gossip
+}
+
+func (e *events) NotifyJoin(n *memberlist.Node) {
+	e.setNode(n, StatusAlive)
+
+	if n.Name != e.gossip.id {
+		e.gossip.logger.Info().Str("member-id", n.Name).Str("gossip-addr", n.Address()).Msg("discovered new cluster member")
+	}
+}
+
+func (e *events) NotifyLeave(n *memberlist.Node) {
+	status := StatusDead
+	if e.gossip.decodeMeta(n).Left {
+		status = StatusLeft
+		e.gossip.logger.Info().Str("member-id", n.Name).Msg("cluster member has left")
+	} else {
+		e.gossip.logger.Warn().Str("member-id", n.Name).Msg("cluster member has failed")
+	}
+
+	e.setNode(n, status)
+}
+
+func (e *events) NotifyUpdate(n *memberlist.Node) {
+	e.setNode(n, StatusAlive)
+	e.gossip.logger.Debug().Str("member-id", n.Name).Msg("cluster member updated")
+}
+
+// setNode records the member with the status. Members which have departed are not considered the
+// leader, and the time of their departure is recorded.
+func (e *events) setNode(n *memberlist.Node, status Status) {
+	ns := nodeState{node: *e.gossip.node(n, status)}
+	if status != StatusAlive {
+		ns.node.Leader = false
+		ns.departed = time.Now()
+	}
+
+	e.gossip.lock.Lock()
+	e.gossip.nodes[n.Name] = &ns
+	e.gossip.lock.Unlock()
+}
+
+// logWriter writes the memberlist log lines using the Sherpa logger, at the level indicated by
+// the line prefix.
+type logWriter struct {
+	logger zerolog.Logger
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSpace(string(p))
+
+	switch {
+	case strings.HasPrefix(msg, "[ERR]"):
+		w.logger.Error().Msg(msg)
+	case strings.HasPrefix(msg, "[WARN]"):
+		w.logger.Warn().Msg(msg)
+	default:
+		w.logger.Debug().Msg(msg)
+	}
+	return len(p), nil
+}
@@ -0,0 +1,86 @@
+package gossip
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/memberlist"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestGossip(t *testing.T, id, key string, join ...string) *Gossip {
+	g, err := New(&Config{
+		Logger:   zerolog.Nop(),
+		ID:       id,
+		Addr:     "http://" + id + ":8000/",
+		BindAddr: "127.0.0.1",
+		Join:     join,
+		Key:      key,
+	})
+	assert.Nil(t, err)
+	return g
+}
+
+func TestGossip(t *testing.T) {
+	a := newTestGossip(t, "a", "secret")
+	defer a.Stop()
+
+	b := newTestGossip(t, "b", "secret", a.list.LocalNode().Address())
+
+	// Server b only knows the join address, and discovers server a by joining the cluster.
+	b.joinCluster()
+	assert.Equal(t, []string{"a", "b"}, a.AliveMembers())
+	assert.Equal(t, []string{"a", "b"}, b.AliveMembers())
+
+	members := b.Members()
+	assert.Equal(t, "http://a:8000", members[0].Addr)
+	assert.Equal(t, a.list.LocalNode().Address(), members[0].GossipAddr)
+
+	// Leadership is shared with the other members.
+	a.SetLeader(true)
+	assert.Eventually(t, func() bool { return b.Members()[0].Leader }, 5*time.Second, 50*time.Millisecond)
+	assert.False(t, b.Members()[1].Leader)
+
+	// Servers without the shared key cannot join the cluster.
+	c := newTestGossip(t, "c", "wrong", a.list.LocalNode().Address())
+	c.joinCluster()
+	assert.Equal(t, []string{"c"}, c.AliveMembers())
+	assert.Equal(t, []string{"a", "b"}, a.AliveMembers())
+	c.Stop()
+
+	// Leaving the cluster is gossiped to the other members.
+	b.Stop()
+	assert.Eventually(t, func() bool {
+		members := a.Members()
+		return len(members) == 2 && members[1].Status == StatusLeft
+	}, 5*time.Second, 50*time.Millisecond)
+	assert.Equal(t, []string{"a"}, a.AliveMembers())
+}
+
+func TestGossip_events(t *testing.T) {
+	now := time.Now()
+	g := newTestGossip(t, "a", "")
+	defer g.Stop()
+
+	e := &events{gossip: g}
+
+	// Members which leave without first announcing it are recorded as failed.
+	e.NotifyLeave(&memberlist.Node{Name: "b", Addr: net.ParseIP("10.0.0.2"), Port: 7946, Meta: []byte(`{"Addr":"http://b:8000","Leader":true}`)})
+	e.NotifyLeave(&memberlist.Node{Name: "c", Meta: []byte(`{"Addr":"http://c:8000","Left":true}`)})
+
+	members := g.Members()
+	assert.Len(t, members, 3)
+	assert.Equal(t, &Node{ID: "b", Addr: "http://b:8000", GossipAddr: "10.0.0.2:7946", Status: StatusDead}, members[1])
+	assert.Equal(t, StatusLeft, members[2].Status)
+
+	// Rejoining members are no longer departed.
+	e.NotifyJoin(&memberlist.Node{Name: "c", Meta: []byte(`{"Addr":"http://c:8000"}`)})
+	assert.Equal(t, []string{"a", "c"}, g.AliveMembers())
+
+	// Departed members are reaped after the reap timeout.
+	g.reap(now.Add(reapTimeout + time.Minute))
+	assert.Equal(t, []string{"a", "c"}, g.AliveMembers())
+	assert.Len(t, g.Members(), 2)
+}
@@ -7,6 +7,17 @@ import (
 	"github.com/jrasell/sherpa/pkg/server/cluster"
 )
 
+// readOnlyProtectedHandler is a HTTP handler to be used on all endpoints which trigger scaling or
+// mutate scaling policies. When the server is running in read-only mode, requests are rejected.
+func readOnlyProtectedHandler(readOnly bool, handler http.Handler) http.Handler {
+	if !readOnly {
+		return handler
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "server is running in read-only mode", http.StatusForbidden)
+	})
+}
+
 // leaderProtectedHandler is a HTTP handler to be used on all endpoints which require a response
 // from the current Sherpa cluster leader.
 func leaderProtectedHandler(mem *cluster.Member, handler http.HandlerFunc) http.Handler {
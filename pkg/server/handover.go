@@ -0,0 +1,59 @@
+package server
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/jrasell/sherpa/pkg/autoscale"
+)
+
+// handoverStateTTL is the time after a leadership handover completed within which the new leader
+// restores the autoscaler state captured by the previous leader. Older state is discarded as it no
+// longer reflects the recent scaling decisions.
+const handoverStateTTL = 5 * time.Minute
+
+// handoverAutoScaling stops the autoscaler, waiting for in-flight evaluations to finish, and
+// returns its internal state. It is called by the cluster member when handing over leadership.
+func (h *HTTPServer) handoverAutoScaling() ([]byte, error) {
+	if h.autoScale == nil {
+		return nil, nil
+	}
+
+	if h.autoScale.IsRunning() {
+		h.autoScale.Stop()
+	}
+
+	internal, err := h.autoScale.InternalState()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(internal)
+}
+
+// restoreHandoverState restores the autoscaler internal state handed over by the previous leader,
+// if a handover recently completed.
+func (h *HTTPServer) restoreHandoverState() {
+	handover, err := h.clusterMember.TakeHandover(handoverStateTTL)
+	if err != nil {
+		h.logger.Error().Err(err).Msg("failed to read leadership handover")
+		return
+	}
+	if handover == nil || len(handover.State) == 0 {
+		return
+	}
+
+	var internal autoscale.InternalState
+
+	if err := json.Unmarshal(handover.State, &internal); err != nil {
+		h.logger.Error().Err(err).Msg("failed to unmarshal handed over autoscaler state")
+		return
+	}
+
+	if err := h.autoScale.RestoreInternalState(&internal); err != nil {
+		h.logger.Error().Err(err).Msg("failed to restore handed over autoscaler state")
+		return
+	}
+	h.logger.Info().
+		Str("handed-over-by", handover.CompletedBy.String()).
+		Msg("restored autoscaler state handed over by previous leader")
+}
@@ -3,9 +3,15 @@ package server
 import (
 	"net/http"
 
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/helper"
 	"github.com/rs/zerolog"
 )
 
+// maxRequestIDLength is the maximum length of a client supplied request ID. Longer IDs are
+// replaced, so that clients cannot inflate the size of log lines.
+const maxRequestIDLength = 128
+
 type loggingResponseWriter struct {
 	http.ResponseWriter
 	statusCode int
@@ -21,11 +27,32 @@ func middlewareLogger(h http.Handler, l zerolog.Logger) http.Handler {
 			Str("method", req.Method).
 			Str("path", req.RequestURI).
 			Str("remote-addr", req.RemoteAddr).
+			Str("request-id", helper.RequestIDFromContext(req.Context())).
 			Int("response-code", respWriter.statusCode).
 			Msg("server responded to request")
 	})
 }
 
+// middlewareRequestID ensures each API call has a request ID, accepting the ID supplied by the
+// client or generating one. The ID is added to the request context and the response headers.
+func middlewareRequestID(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		id := req.Header.Get(helper.RequestIDHeader)
+
+		if id == "" || len(id) > maxRequestIDLength {
+			newID, err := uuid.NewV4()
+			if err != nil {
+				h.ServeHTTP(w, req)
+				return
+			}
+			id = newID.String()
+		}
+
+		w.Header().Set(helper.RequestIDHeader, id)
+		h.ServeHTTP(w, req.WithContext(helper.ContextWithRequestID(req.Context(), id)))
+	})
+}
+
 func newLoggingResponseWriter(w http.ResponseWriter) *loggingResponseWriter {
 	return &loggingResponseWriter{w, http.StatusOK}
 }
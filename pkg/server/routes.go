@@ -4,6 +4,9 @@ import (
 	"net/http"
 	"net/http/pprof"
 
+	autoscaleV1 "github.com/jrasell/sherpa/pkg/autoscale/v1"
+	clusterScaleV1 "github.com/jrasell/sherpa/pkg/clusterscale/v1"
+	notifyV1 "github.com/jrasell/sherpa/pkg/notify/v1"
 	policyV1 "github.com/jrasell/sherpa/pkg/policy/v1"
 	scaleV1 "github.com/jrasell/sherpa/pkg/scale/v1"
 	v1 "github.com/jrasell/sherpa/pkg/server/endpoints/v1"
@@ -11,10 +14,17 @@ import (
 )
 
 type routes struct {
-	System *v1.SystemServer
-	Policy *policyV1.Policy
-	Scale  *scaleV1.Scale
-	UI     *v1.UIServer
+	System    *v1.SystemServer
+	Gossip    *v1.GossipServer
+	Providers *autoscaleV1.Providers
+	Evaluate  *autoscaleV1.Evaluate
+	Overrides *autoscaleV1.Overrides
+	State     *autoscaleV1.State
+	Cluster   *clusterScaleV1.ClusterScale
+	Mutes     *notifyV1.Mutes
+	Policy    *policyV1.Policy
+	Scale     *scaleV1.Scale
+	UI        *v1.UIServer
 }
 
 func (h *HTTPServer) setupRoutes() *router.RouteTable {
@@ -34,6 +44,25 @@ func (h *HTTPServer) setupRoutes() *router.RouteTable {
 	policyRoutes := h.setupPolicyRoutes()
 	r = append(r, policyRoutes)
 
+	// Setup the notification routes.
+	notifyRoutes := h.setupNotifyRoutes()
+	r = append(r, notifyRoutes)
+
+	// Setup the metric provider and autoscaler routes if the internal autoscaler is enabled.
+	if h.autoScale != nil {
+		providerRoutes := h.setupProviderRoutes()
+		r = append(r, providerRoutes)
+
+		autoscalerRoutes := h.setupAutoscalerRoutes()
+		r = append(r, autoscalerRoutes)
+	}
+
+	// Setup the cluster scaling routes if the experimental feature is enabled.
+	if h.clusterScaler != nil {
+		clusterScaleRoutes := h.setupClusterScaleRoutes()
+		r = append(r, clusterScaleRoutes)
+	}
+
 	// Setup the server debug routes if enabled.
 	if h.cfg.Debug {
 		debugRoutes := h.setupDebugRoutes()
@@ -55,6 +84,12 @@ func (h *HTTPServer) setupRoutes() *router.RouteTable {
 	return &r
 }
 
+// readOnlyProtected wraps the handler so that it is rejected when the server is running in
+// read-only mode.
+func (h *HTTPServer) readOnlyProtected(handler http.Handler) http.Handler {
+	return readOnlyProtectedHandler(h.cfg.Server.ReadOnly, handler)
+}
+
 func (h *HTTPServer) setupUIRoutes() []router.Route {
 	h.logger.Debug().Msg("setting up server UI routes")
 
@@ -80,10 +115,12 @@ func (h *HTTPServer) setupScaleRoutes() []router.Route {
 	h.logger.Debug().Msg("setting up server scale routes")
 
 	h.routes.Scale = scaleV1.NewScaleServer(h.cfg.Server.StrictPolicyChecking, &scaleV1.ScaleConfig{
-		Logger: h.logger,
+		Logger: h.apiLogger,
 		Policy: h.policyBackend,
 		Scale:  h.scaleBackend,
 		State:  h.stateBackend,
+
+		ForceEnabled: h.cfg.Server.ScaleForceEnabled,
 	})
 
 	return router.Routes{
@@ -93,7 +130,7 @@ func (h *HTTPServer) setupScaleRoutes() []router.Route {
 			Name:    routeScaleOutJobGroupName,
 			Method:  http.MethodPut,
 			Pattern: routeScaleOutJobGroupPattern,
-			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Scale.OutJobGroup),
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Scale.OutJobGroup)),
 		},
 		// Deprecated: the PUT method is deprecated in favour of POST and will be removed in a
 		// future release.
@@ -101,19 +138,25 @@ func (h *HTTPServer) setupScaleRoutes() []router.Route {
 			Name:    routeScaleInJobGroupName,
 			Method:  http.MethodPut,
 			Pattern: routeScaleInJobGroupPattern,
-			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Scale.InJobGroup),
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Scale.InJobGroup)),
 		},
 		router.Route{
 			Name:    routePostScaleOutJobGroupName,
 			Method:  http.MethodPost,
 			Pattern: routePostScaleOutJobGroupPattern,
-			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Scale.OutJobGroup),
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Scale.OutJobGroup)),
 		},
 		router.Route{
 			Name:    routePostScaleInJobGroupName,
 			Method:  http.MethodPost,
 			Pattern: routePostScaleInJobGroupPattern,
-			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Scale.InJobGroup),
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Scale.InJobGroup)),
+		},
+		router.Route{
+			Name:    routePostScaleCountJobGroupName,
+			Method:  http.MethodPost,
+			Pattern: routePostScaleCountJobGroupPattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Scale.CountJobGroup)),
 		},
 		router.Route{
 			Name:    routeGetScalingStatusName,
@@ -127,15 +170,22 @@ func (h *HTTPServer) setupScaleRoutes() []router.Route {
 			Pattern: routeGetScalingInfoPattern,
 			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Scale.StatusInfo),
 		},
+		router.Route{
+			Name:    routeGetScalingReportName,
+			Method:  http.MethodGet,
+			Pattern: routeGetScalingReportPattern,
+			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Scale.Report),
+		},
 	}
 }
 
 func (h *HTTPServer) setupSystemRoutes() []router.Route {
 	h.logger.Debug().Msg("setting up server system routes")
 
-	h.routes.System = v1.NewSystemServer(h.logger, h.nomad, h.cfg.Server, h.telemetry, h.clusterMember)
+	h.routes.System = v1.NewSystemServer(h.apiLogger, h.nomad, h.cfg.Server, h.telemetry, h.clusterMember,
+		h.cfg.redacted(), h.systemComponents())
 
-	return router.Routes{
+	routes := router.Routes{
 		router.Route{
 			Name:        routeSystemHealthName,
 			Method:      http.MethodGet,
@@ -160,13 +210,226 @@ func (h *HTTPServer) setupSystemRoutes() []router.Route {
 			Pattern:     routeGetSystemLeaderPattern,
 			HandlerFunc: h.routes.System.GetLeader,
 		},
+		router.Route{
+			Name:        routePutSystemLogLevelName,
+			Method:      http.MethodPut,
+			Pattern:     routePutSystemLogLevelPattern,
+			HandlerFunc: h.routes.System.PutLogLevel,
+		},
+		router.Route{
+			Name:        routeGetSystemConfigName,
+			Method:      http.MethodGet,
+			Pattern:     routeGetSystemConfigPattern,
+			HandlerFunc: h.routes.System.GetConfig,
+		},
+		router.Route{
+			Name:    routePutSystemHandoverName,
+			Method:  http.MethodPut,
+			Pattern: routePutSystemHandoverPattern,
+			Handler: leaderProtectedHandler(h.clusterMember, h.routes.System.PutHandover),
+		},
+	}
+
+	// Setup the members route if gossip is enabled. This is served by every server, rather than
+	// being redirected to the leader.
+	if h.gossip != nil {
+		h.routes.Gossip = v1.NewGossipServer(h.apiLogger, h.gossip)
+
+		routes = append(routes, router.Route{
+			Name:        routeGetSystemMembersName,
+			Method:      http.MethodGet,
+			Pattern:     routeGetSystemMembersPattern,
+			HandlerFunc: h.routes.Gossip.GetMembers,
+		})
+	}
+	return routes
+}
+
+// systemComponents describes the metric providers, notifiers and experimental features enabled on
+// the server, for inclusion in the system info response.
+func (h *HTTPServer) systemComponents() *v1.SystemComponents {
+	components := &v1.SystemComponents{
+		MetricProviders: []string{},
+		Notifiers:       []string{},
+		Features: map[string]bool{
+			"alloc-stats-cache":     h.cfg.Server.InternalAutoScalerAllocStatsCacheTTL > 0,
+			"drain-aware-scale-in":  h.cfg.Server.InternalAutoScalerDrainAwareScaleIn,
+			"evaluation-sharding":   h.cfg.Cluster.Sharding,
+			"fault-injection":       h.cfg.Chaos.Enabled,
+			"gossip":                h.cfg.Cluster.Gossip,
+			"metric-provider-cache": h.cfg.MetricProvider.CacheEnabled,
+			"shadow-mode":           h.cfg.Server.InternalAutoScalerShadowMode,
+		},
+	}
+
+	for name, enabled := range h.features.Status() {
+		components.Features[name] = enabled
+	}
+
+	if h.autoScale != nil {
+		for _, p := range h.autoScale.ProviderStatus() {
+			components.MetricProviders = append(components.MetricProviders, p.Name)
+		}
+	}
+	if h.notifyQueue != nil {
+		components.Notifiers = h.notifyQueue.Notifiers()
+	}
+	return components
+}
+
+func (h *HTTPServer) setupNotifyRoutes() []router.Route {
+	h.logger.Debug().Msg("setting up server notify routes")
+
+	h.routes.Mutes = notifyV1.NewMutesServer(h.apiLogger, h.notifyMutes)
+
+	return router.Routes{
+		router.Route{
+			Name:    routeGetNotifyMutesName,
+			Method:  http.MethodGet,
+			Pattern: routeGetNotifyMutesPattern,
+			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Mutes.GetMutes),
+		},
+		router.Route{
+			Name:    routePutNotifyMuteJobName,
+			Method:  http.MethodPut,
+			Pattern: routePutNotifyMuteJobPattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Mutes.PutMute)),
+		},
+		router.Route{
+			Name:    routePutNotifyMuteGroupName,
+			Method:  http.MethodPut,
+			Pattern: routePutNotifyMuteGroupPattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Mutes.PutMute)),
+		},
+		router.Route{
+			Name:    routeDeleteNotifyMuteJobName,
+			Method:  http.MethodDelete,
+			Pattern: routeDeleteNotifyMuteJobPattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Mutes.DeleteMute)),
+		},
+		router.Route{
+			Name:    routeDeleteNotifyMuteGroupName,
+			Method:  http.MethodDelete,
+			Pattern: routeDeleteNotifyMuteGroupPattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Mutes.DeleteMute)),
+		},
+	}
+}
+
+func (h *HTTPServer) setupProviderRoutes() []router.Route {
+	h.logger.Debug().Msg("setting up server metric provider routes")
+
+	h.routes.Providers = autoscaleV1.NewProvidersServer(h.apiLogger, h.autoScale)
+
+	return router.Routes{
+		router.Route{
+			Name:    routeGetProvidersStatusName,
+			Method:  http.MethodGet,
+			Pattern: routeGetProvidersStatusPattern,
+			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Providers.GetStatus),
+		},
+	}
+}
+
+func (h *HTTPServer) setupClusterScaleRoutes() []router.Route {
+	h.logger.Debug().Msg("setting up server cluster scaling routes")
+
+	h.routes.Cluster = clusterScaleV1.NewClusterScaleServer(h.apiLogger, h.clusterScaler)
+
+	return router.Routes{
+		router.Route{
+			Name:        routeGetClusterClassesName,
+			Method:      http.MethodGet,
+			Pattern:     routeGetClusterClassesPattern,
+			HandlerFunc: h.routes.Cluster.GetClasses,
+		},
+		router.Route{
+			Name:    routeGetClusterDecisionsName,
+			Method:  http.MethodGet,
+			Pattern: routeGetClusterDecisionsPattern,
+			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Cluster.GetDecisions),
+		},
+		router.Route{
+			Name:    routeGetClusterInterruptionsName,
+			Method:  http.MethodGet,
+			Pattern: routeGetClusterInterruptionsPattern,
+			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Cluster.GetInterruptions),
+		},
+		router.Route{
+			Name:    routePostClusterInterruptionName,
+			Method:  http.MethodPost,
+			Pattern: routePostClusterInterruptionPattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Cluster.PostInterruption)),
+		},
+		router.Route{
+			Name:        routeGetClusterScaleInPreviewName,
+			Method:      http.MethodGet,
+			Pattern:     routeGetClusterScaleInPreviewPattern,
+			HandlerFunc: h.routes.Cluster.PreviewScaleIn,
+		},
+	}
+}
+
+func (h *HTTPServer) setupAutoscalerRoutes() []router.Route {
+	h.logger.Debug().Msg("setting up server autoscaler routes")
+
+	h.routes.Evaluate = autoscaleV1.NewEvaluateServer(h.apiLogger, h.autoScale)
+	h.routes.Overrides = autoscaleV1.NewOverridesServer(h.apiLogger, h.autoScale)
+	h.routes.State = autoscaleV1.NewStateServer(h.apiLogger, h.autoScale)
+
+	return router.Routes{
+		router.Route{
+			Name:    routePostAutoscalerEvaluateJobName,
+			Method:  http.MethodPost,
+			Pattern: routePostAutoscalerEvaluateJobPattern,
+			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Evaluate.EvaluateJob),
+		},
+		router.Route{
+			Name:    routePostAutoscalerEvaluateJobGroupName,
+			Method:  http.MethodPost,
+			Pattern: routePostAutoscalerEvaluateJobGroupPattern,
+			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Evaluate.EvaluateJob),
+		},
+		router.Route{
+			Name:    routeGetAutoscalerOverridesName,
+			Method:  http.MethodGet,
+			Pattern: routeGetAutoscalerOverridesPattern,
+			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Overrides.GetOverrides),
+		},
+		router.Route{
+			Name:    routePutAutoscalerOverrideName,
+			Method:  http.MethodPut,
+			Pattern: routePutAutoscalerOverridePattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Overrides.PutOverride)),
+		},
+		router.Route{
+			Name:    routeDeleteAutoscalerOverrideName,
+			Method:  http.MethodDelete,
+			Pattern: routeDeleteAutoscalerOverridePattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Overrides.DeleteOverride)),
+		},
+		router.Route{
+			Name:    routeGetAutoscalerStateName,
+			Method:  http.MethodGet,
+			Pattern: routeGetAutoscalerStatePattern,
+			Handler: leaderProtectedHandler(h.clusterMember, h.routes.State.GetState),
+		},
+		router.Route{
+			Name:    routePutAutoscalerStateName,
+			Method:  http.MethodPut,
+			Pattern: routePutAutoscalerStatePattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.State.PutState)),
+		},
 	}
 }
 
 func (h *HTTPServer) setupPolicyRoutes() []router.Route {
 	h.logger.Debug().Msg("setting up server policy routes")
 
-	h.routes.Policy = policyV1.NewPolicyServer(h.logger, h.policyBackend)
+	h.routes.Policy = policyV1.NewPolicyServer(h.apiLogger, h.policyBackend)
+	if h.clusterMember.IsHA() {
+		h.routes.Policy.DisableRollouts()
+	}
 
 	return router.Routes{
 		router.Route{
@@ -175,6 +438,12 @@ func (h *HTTPServer) setupPolicyRoutes() []router.Route {
 			Pattern: routeGetJobScalingPoliciesPattern,
 			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Policy.GetJobPolicies),
 		},
+		router.Route{
+			Name:        routeGetPolicySchemaName,
+			Method:      http.MethodGet,
+			Pattern:     routeGetPolicySchemaPattern,
+			HandlerFunc: h.routes.Policy.GetSchema,
+		},
 		router.Route{
 			Name:    routeGetJobScalingPolicyName,
 			Method:  http.MethodGet,
@@ -198,25 +467,49 @@ func (h *HTTPServer) setupAPIPolicyRoutes() []router.Route {
 			Name:    routePostJobScalingPolicyName,
 			Method:  http.MethodPost,
 			Pattern: routePutJobScalingPolicyPattern,
-			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Policy.PutJobPolicy),
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Policy.PutJobPolicy)),
 		},
 		router.Route{
 			Name:    routePostJobGroupScalingPolicyName,
 			Method:  http.MethodPost,
 			Pattern: routePutJobGroupScalingPolicyPattern,
-			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Policy.PutJobGroupPolicy),
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Policy.PutJobGroupPolicy)),
 		},
 		router.Route{
 			Name:    routeDeleteJobGroupScalingPolicyName,
 			Method:  http.MethodDelete,
 			Pattern: routeDeleteJobGroupScalingPolicyPattern,
-			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Policy.DeleteJobGroupPolicy),
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Policy.DeleteJobGroupPolicy)),
 		},
 		router.Route{
 			Name:    routeDeleteJobScalingPolicyName,
 			Method:  http.MethodDelete,
 			Pattern: routeDeleteJobScalingPolicyPattern,
-			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Policy.DeleteJobPolicy),
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Policy.DeleteJobPolicy)),
+		},
+		router.Route{
+			Name:    routeDeleteScalingPoliciesName,
+			Method:  http.MethodDelete,
+			Pattern: routeDeleteScalingPoliciesPattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Policy.DeletePolicies)),
+		},
+		router.Route{
+			Name:    routeGetPolicyRolloutsName,
+			Method:  http.MethodGet,
+			Pattern: routeGetPolicyRolloutsPattern,
+			Handler: leaderProtectedHandler(h.clusterMember, h.routes.Policy.GetRollouts),
+		},
+		router.Route{
+			Name:    routePostPolicyRolloutPromoteName,
+			Method:  http.MethodPost,
+			Pattern: routePostPolicyRolloutPromotePattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Policy.PromoteRollout)),
+		},
+		router.Route{
+			Name:    routeDeletePolicyRolloutName,
+			Method:  http.MethodDelete,
+			Pattern: routeDeletePolicyRolloutPattern,
+			Handler: h.readOnlyProtected(leaderProtectedHandler(h.clusterMember, h.routes.Policy.DeleteRollout)),
 		},
 	}
 }
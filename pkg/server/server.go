@@ -3,24 +3,48 @@ package server
 import (
 	"context"
 	"crypto/tls"
+	"database/sql"
 	"fmt"
 	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/armon/go-metrics"
 	consulAPI "github.com/hashicorp/consul/api"
-	nomadAPI "github.com/hashicorp/nomad/api"
 	"github.com/jrasell/sherpa/pkg/autoscale"
+	"github.com/jrasell/sherpa/pkg/chaos"
 	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/clusterscale"
+	"github.com/jrasell/sherpa/pkg/clusterscale/target"
+	targetOpenStack "github.com/jrasell/sherpa/pkg/clusterscale/target/openstack"
+	targetPlugin "github.com/jrasell/sherpa/pkg/clusterscale/target/plugin"
+	targetVSphere "github.com/jrasell/sherpa/pkg/clusterscale/target/vsphere"
+	serverCfg "github.com/jrasell/sherpa/pkg/config/server"
+	"github.com/jrasell/sherpa/pkg/encryption"
+	"github.com/jrasell/sherpa/pkg/feature"
+	"github.com/jrasell/sherpa/pkg/filter"
+	"github.com/jrasell/sherpa/pkg/hook"
+	"github.com/jrasell/sherpa/pkg/logger"
+	"github.com/jrasell/sherpa/pkg/notify"
+	"github.com/jrasell/sherpa/pkg/notify/discord"
+	"github.com/jrasell/sherpa/pkg/notify/grafana"
+	"github.com/jrasell/sherpa/pkg/notify/teams"
 	policyBackend "github.com/jrasell/sherpa/pkg/policy/backend"
 	"github.com/jrasell/sherpa/pkg/policy/backend/consul"
+	"github.com/jrasell/sherpa/pkg/policy/backend/etcd"
+	policyFile "github.com/jrasell/sherpa/pkg/policy/backend/file"
 	policyMemory "github.com/jrasell/sherpa/pkg/policy/backend/memory"
 	"github.com/jrasell/sherpa/pkg/policy/backend/nomadmeta"
+	policyPostgres "github.com/jrasell/sherpa/pkg/policy/backend/postgres"
+	"github.com/jrasell/sherpa/pkg/postgres"
+	"github.com/jrasell/sherpa/pkg/reconcile"
 	"github.com/jrasell/sherpa/pkg/scale"
+	"github.com/jrasell/sherpa/pkg/secret"
 	"github.com/jrasell/sherpa/pkg/server/cluster"
+	"github.com/jrasell/sherpa/pkg/server/gossip"
 	"github.com/jrasell/sherpa/pkg/server/router"
 	clusterBackend "github.com/jrasell/sherpa/pkg/state/cluster"
 	clusterConsul "github.com/jrasell/sherpa/pkg/state/cluster/consul"
@@ -28,6 +52,7 @@ import (
 	stateBackend "github.com/jrasell/sherpa/pkg/state/scale"
 	stateConsul "github.com/jrasell/sherpa/pkg/state/scale/consul"
 	stateMemory "github.com/jrasell/sherpa/pkg/state/scale/memory"
+	statePostgres "github.com/jrasell/sherpa/pkg/state/scale/postgres"
 	"github.com/jrasell/sherpa/pkg/watcher"
 	"github.com/jrasell/sherpa/pkg/watcher/deployment"
 	"github.com/jrasell/sherpa/pkg/watcher/job"
@@ -40,6 +65,9 @@ type HTTPServer struct {
 	cfg    *Config
 	logger zerolog.Logger
 
+	// apiLogger is the component logger used by the HTTP API endpoints.
+	apiLogger zerolog.Logger
+
 	policyBackend  policyBackend.PolicyBackend
 	stateBackend   stateBackend.Backend
 	scaleBackend   scale.Scale
@@ -58,11 +86,55 @@ type HTTPServer struct {
 
 	clusterMember *cluster.Member
 
-	// Store the Nomad and Consul API clients for resuse.
-	nomad  *nomadAPI.Client
+	// faults is the fault injector used for resilience testing, and is nil unless the operator has
+	// enabled fault injection.
+	faults *chaos.Injector
+
+	// secrets resolves credentials which are configured as secret references.
+	secrets *secret.Resolver
+
+	// keyring encrypts sensitive fields before they are written to the storage backend, and is
+	// nil unless the operator has configured encryption keys.
+	keyring *encryption.Keyring
+
+	// postgres is the database used by the PostgreSQL storage backend, and is nil unless the
+	// operator has configured it.
+	postgres *sql.DB
+
+	// gossip is the gossip layer used to discover and share health with the other servers, and is
+	// nil unless the operator has enabled gossip.
+	gossip *gossip.Gossip
+
+	// features are the experimental features enabled by the operator.
+	features *feature.Flags
+
+	// notifyMutes holds the active notification mutes, managed using the API.
+	notifyMutes *notify.Mutes
+
+	// notifyQueue asynchronously delivers scaling event notifications, and is nil unless the
+	// operator has configured a notifier.
+	notifyQueue *notify.Queue
+
+	// jobFilter restricts the jobs which this server evaluates, and is nil if all jobs are
+	// evaluated.
+	jobFilter *filter.JobFilter
+
+	// Store the Nomad client pool and Consul API client for resuse.
+	nomad  *client.NomadPool
 	consul *consulAPI.Client
 
 	autoScale *autoscale.AutoScale
+
+	// clusterScaler selects the Nomad client nodes to act on when scaling the cluster, and is nil
+	// unless the cluster-scaling feature is enabled. clusterScalerStop is closed to stop the
+	// cluster scaler when leadership is lost, and is nil when it is not running.
+	clusterScaler     *clusterscale.Scaler
+	clusterScalerStop chan struct{}
+
+	// reconciler performs the startup reconciliation pass of the stored scaling state when this
+	// server obtains leadership.
+	reconciler *reconcile.Reconciler
+
 	telemetry *metrics.InmemSink
 
 	http.Server
@@ -79,11 +151,14 @@ type HTTPServer struct {
 
 func New(l zerolog.Logger, cfg *Config) *HTTPServer {
 	return &HTTPServer{
-		addr:     fmt.Sprintf("%s:%d", cfg.Server.Bind, cfg.Server.Port),
-		cfg:      cfg,
-		logger:   l,
-		routes:   &routes{},
-		stopChan: make(chan struct{}),
+		addr:      fmt.Sprintf("%s:%d", cfg.Server.Bind, cfg.Server.Port),
+		cfg:       cfg,
+		logger:    l,
+		apiLogger: logger.Component(l, logger.ComponentAPI),
+		routes:    &routes{},
+		stopChan:  make(chan struct{}),
+
+		notifyMutes: notify.NewMutes(),
 	}
 }
 
@@ -96,6 +171,31 @@ func (h *HTTPServer) Start() error {
 	}
 
 	go h.leaderUpdateHandler()
+	go h.nomad.Run(h.stopChan)
+
+	if h.notifyQueue != nil {
+		go h.notifyQueue.Run(h.stopChan)
+	}
+
+	if h.gossip != nil {
+		go h.gossip.Run()
+	}
+
+	// When evaluation sharding is enabled, every server runs the autoscaler, evaluating only the
+	// jobs it owns. Otherwise, the autoscaler is started when the server obtains leadership.
+	if h.clusterMember.ShardingEnabled() && h.autoScale != nil {
+		go h.autoScale.Run()
+	}
+
+	// When replacing a server, such as during a rolling upgrade, ask the leader to hand over
+	// leadership and the autoscaler state now this server is ready to take over.
+	if h.cfg.Cluster.Handover {
+		if err := h.clusterMember.RequestHandover(); err != nil {
+			h.logger.Error().Err(err).Msg("failed to request leadership handover")
+		} else {
+			h.logger.Info().Msg("requested leadership handover from the cluster leader")
+		}
+	}
 
 	// Start the deployment watcher, using the scale deployment channel for updates.
 	go h.deploymentWatcher.Run(h.scaleBackend.GetDeploymentChannel())
@@ -111,16 +211,125 @@ func (h *HTTPServer) Start() error {
 	return nil
 }
 
+func (h *HTTPServer) setupClusterScaler() error {
+	var classes []*serverCfg.NodeClassPolicy
+
+	if h.cfg.ClusterScaling.NodeClassesFile != "" {
+		c, err := serverCfg.LoadNodeClassPolicies(h.cfg.ClusterScaling.NodeClassesFile)
+		if err != nil {
+			return err
+		}
+		classes = c
+	}
+
+	// Decisions are not performed in read-only mode, in the same way as the autoscaler runs in
+	// dry-run mode.
+	var provider target.Provider
+
+	switch {
+	case h.cfg.ClusterScaling.TargetPlugin != "":
+		provider = targetPlugin.NewClient(h.cfg.ClusterScaling.TargetPlugin, h.cfg.ClusterScaling.TargetPluginArgs,
+			logger.Component(h.logger, logger.ComponentScale))
+
+	case h.cfg.ClusterScaling.OpenStack != nil:
+		osCfg := h.cfg.ClusterScaling.OpenStack
+		provider = targetOpenStack.NewClient(&targetOpenStack.Config{
+			AuthURL:    osCfg.AuthURL,
+			Username:   osCfg.Username,
+			Password:   h.secrets.Value(osCfg.Password),
+			UserDomain: osCfg.UserDomain,
+			ProjectID:  osCfg.ProjectID,
+			Region:     osCfg.Region,
+			Classes:    classes,
+			Logger:     logger.Component(h.logger, logger.ComponentScale),
+		})
+
+	case h.cfg.ClusterScaling.VSphere != nil:
+		vsCfg := h.cfg.ClusterScaling.VSphere
+		provider = targetVSphere.NewClient(&targetVSphere.Config{
+			URL:      vsCfg.URL,
+			Username: vsCfg.Username,
+			Password: h.secrets.Value(vsCfg.Password),
+			Insecure: vsCfg.Insecure,
+			Classes:  classes,
+			Logger:   logger.Component(h.logger, logger.ComponentScale),
+		})
+	}
+
+	softScaleIn := h.cfg.ClusterScaling.SoftScaleIn
+
+	if (provider != nil || softScaleIn) && h.cfg.Server.ReadOnly {
+		h.logger.Warn().Msg("server is read-only, cluster scaling decisions will not be performed")
+		provider, softScaleIn = nil, false
+	}
+
+	h.clusterScaler = clusterscale.NewScaler(&clusterscale.Config{
+		Logger:       logger.Component(h.logger, logger.ComponentScale),
+		Nomad:        h.nomad,
+		NomadTimeout: time.Duration(h.cfg.Server.NomadAPITimeout) * time.Second,
+		Weights: clusterscale.ScoreWeights{
+			Empty:       h.cfg.ClusterScaling.ScaleInWeightEmpty,
+			Allocations: h.cfg.ClusterScaling.ScaleInWeightAllocations,
+			Utilization: h.cfg.ClusterScaling.ScaleInWeightUtilization,
+			Age:         h.cfg.ClusterScaling.ScaleInWeightAge,
+		},
+		Classes:             classes,
+		ExcludedJobs:        h.cfg.ClusterScaling.ExcludedJobs,
+		EvaluationInterval:  time.Duration(h.cfg.ClusterScaling.EvaluationInterval) * time.Second,
+		ScaleOutCooldown:    time.Duration(h.cfg.ClusterScaling.ScaleOutCooldown) * time.Second,
+		ScaleInCooldown:     time.Duration(h.cfg.ClusterScaling.ScaleInCooldown) * time.Second,
+		StabilizationWindow: time.Duration(h.cfg.ClusterScaling.StabilizationWindow) * time.Second,
+		ReplaceInterrupted:  h.cfg.ClusterScaling.SpotReplace,
+		Target:              provider,
+		DrainDeadline:       time.Duration(h.cfg.ClusterScaling.DrainDeadline) * time.Second,
+		SoftScaleIn:         softScaleIn,
+		ParkedNodes:         h.clusterBackend,
+	})
+	return nil
+}
+
 func (h *HTTPServer) logServerConfig() {
 	h.logger.Info().
 		Object("server", h.cfg.Server).
 		Object("tls", h.cfg.TLS).
 		Object("telemetry", h.cfg.Telemetry).
 		Object("cluster", h.cfg.Cluster).
+		Object("cluster-scaling", h.cfg.ClusterScaling).
+		Object("notify", h.cfg.Notify).
+		Object("nomad", h.cfg.Nomad).
+		Object("chaos", h.cfg.Chaos).
+		Object("job-filter", h.cfg.JobFilter).
 		Msg("Sherpa server configuration")
 }
 
 func (h *HTTPServer) setup() error {
+	h.setupFaultInjector()
+	h.setupSecrets()
+
+	features, err := feature.New(h.cfg.Server.FeatureFlags)
+	if err != nil {
+		return err
+	}
+	h.features = features
+
+	if enabled := features.EnabledFlags(); len(enabled) > 0 {
+		h.logger.Warn().Strs("features", enabled).Msg("experimental features enabled")
+	}
+
+	if err := h.setupEncryption(); err != nil {
+		return errors.Wrap(err, "failed to setup encryption at rest")
+	}
+
+	jobFilter, err := filter.NewJobFilter(h.cfg.JobFilter)
+	if err != nil {
+		return err
+	}
+	h.jobFilter = jobFilter
+
+	if h.cfg.Server.ReadOnly {
+		h.logger.Warn().Msg("read-only mode enabled, mutating API requests will be rejected and the autoscaler will not trigger scaling")
+	}
+
 	if err := h.setupNomadClient(); err != nil {
 		return err
 	}
@@ -134,19 +343,57 @@ func (h *HTTPServer) setup() error {
 		return errors.Wrap(err, "failed to setup telemetry handler")
 	}
 
-	h.setupStoredBackends()
+	if err := h.setupStoredBackends(); err != nil {
+		return errors.Wrap(err, "failed to setup storage backends")
+	}
 
-	h.setupScaler()
+	if err := h.setupScaler(); err != nil {
+		return errors.Wrap(err, "failed to setup scaler")
+	}
 	go h.scaleBackend.RunDeploymentUpdateHandler()
 
 	h.setupDeploymentWatcher()
 
-	mem, err := cluster.NewMember(h.logger, h.clusterBackend, h.addr, h.cfg.Cluster.Addr, h.cfg.Cluster.Name)
+	h.reconciler = reconcile.NewReconciler(&reconcile.Config{
+		Logger:         logger.Component(h.logger, logger.ComponentState),
+		Nomad:          h.nomad,
+		NomadTimeout:   time.Duration(h.cfg.Server.NomadAPITimeout) * time.Second,
+		PolicyBackend:  h.policyBackend,
+		StateBackend:   h.stateBackend,
+		DeploymentChan: h.scaleBackend.GetDeploymentChannel(),
+	})
+
+	mem, err := cluster.NewMember(logger.Component(h.logger, logger.ComponentCluster), h.clusterBackend, h.addr, h.cfg.Cluster.Addr, h.cfg.Cluster.Name)
 	if err != nil {
 		return err
 	}
 	h.clusterMember = mem
 
+	if h.cfg.Cluster.Gossip {
+		if err := h.setupGossip(); err != nil {
+			return err
+		}
+	}
+
+	if h.cfg.Cluster.Sharding {
+		if !h.clusterMember.IsHA() && h.gossip == nil {
+			h.logger.Warn().Msg("evaluation sharding enabled without a HA storage backend or gossip, this server will evaluate all jobs")
+		}
+		h.clusterMember.EnableSharding()
+
+		// When gossip is enabled, the shard ring is built from the gossiped cluster membership.
+		if h.gossip != nil {
+			h.clusterMember.SetMembershipSource(h.gossip)
+		}
+	}
+
+	// Only the server holding the latest leadership fencing token can submit scaling actions.
+	h.scaleBackend.SetFence(h.clusterMember)
+
+	// When handing over leadership, the autoscaler is stopped and its state captured for the next
+	// leader.
+	h.clusterMember.SetHandoverFunc(h.handoverAutoScaling)
+
 	go h.clusterMember.RunLeadershipLoop()
 
 	// If the server has been set to enable the internal autoscaler, set this up. We should not
@@ -158,10 +405,16 @@ func (h *HTTPServer) setup() error {
 		}
 	}
 
+	if h.features.Enabled(feature.ClusterScaling) {
+		if err := h.setupClusterScaler(); err != nil {
+			return errors.Wrap(err, "failed to setup cluster scaler")
+		}
+	}
+
 	initialRoutes := h.setupRoutes()
 
-	r := router.WithRoutes(h.logger, *initialRoutes)
-	http.Handle("/", middlewareLogger(r, h.logger))
+	r := router.WithRoutes(h.apiLogger, *initialRoutes)
+	http.Handle("/", middlewareRequestID(middlewareLogger(r, h.apiLogger)))
 
 	// Run the TLS setup process so that if the user has configured a TLS certificate pair the
 	// server uses these.
@@ -198,45 +451,193 @@ func (h *HTTPServer) setupTLS() error {
 	return nil
 }
 
-func (h *HTTPServer) setupStoredBackends() {
+func (h *HTTPServer) setupStoredBackends() error {
 
 	// Setup the standard backends based on the operators storage type.
 	if h.cfg.Server.ConsulStorageBackend {
 		h.logger.Debug().Msg("setting up Consul storage backend")
-		h.stateBackend = stateConsul.NewStateBackend(h.logger, h.cfg.Server.ConsulStorageBackendPath, h.consul)
-		h.clusterBackend = clusterConsul.NewStateBackend(h.logger, h.cfg.Server.ConsulStorageBackendPath, h.consul)
+		h.stateBackend = stateConsul.NewStateBackend(logger.Component(h.logger, logger.ComponentState), h.cfg.Server.ConsulStorageBackendPath, h.consul)
+		h.clusterBackend = clusterConsul.NewStateBackend(logger.Component(h.logger, logger.ComponentCluster), h.cfg.Server.ConsulStorageBackendPath, h.consul)
+	} else if h.cfg.Server.PostgresStorageBackendURL != "" {
+		h.logger.Debug().Msg("setting up PostgreSQL storage backend")
+		if err := h.setupPostgres(); err != nil {
+			return err
+		}
+		h.stateBackend = statePostgres.NewStateBackend(logger.Component(h.logger, logger.ComponentState), h.postgres)
+
+		// PostgreSQL does not provide leadership locking, so cluster state is held in memory.
+		h.clusterBackend = clusterMemory.NewStateBackend()
 	} else {
 		h.logger.Debug().Msg("setting up in-memory storage backend")
 		h.stateBackend = stateMemory.NewStateBackend()
 		h.clusterBackend = clusterMemory.NewStateBackend()
 	}
-	h.setupPolicyBackend()
+	if err := h.setupPolicyBackend(); err != nil {
+		return err
+	}
+
+	if h.faults != nil {
+		h.policyBackend = chaos.NewPolicyBackend(h.faults, h.policyBackend)
+	}
+	return nil
 }
 
-func (h *HTTPServer) setupPolicyBackend() {
+func (h *HTTPServer) setupPolicyBackend() error {
 	h.logger.Debug().Msg("setting up policy backend")
 
 	if h.cfg.Server.NomadMetaPolicyEngine {
-		h.nomadMetaWatcher = job.NewWatcher(h.logger, h.nomad)
-		h.policyBackend, h.nomadMetaProcessor = nomadmeta.NewJobScalingPolicies(h.logger, h.nomad)
-		return
+		h.nomadMetaWatcher = job.NewWatcher(logger.Component(h.logger, logger.ComponentWatcher), h.nomad)
+		h.policyBackend, h.nomadMetaProcessor = nomadmeta.NewJobScalingPolicies(logger.Component(h.logger, logger.ComponentPolicy), h.nomad,
+			h.cfg.Server.NomadMetaPolicyEngineKeyPrefix, h.jobFilter)
+		return nil
+	}
+
+	// The file policy engine loads the policies from disk on every server, so reloads are picked
+	// up by whichever server is the cluster leader.
+	if h.cfg.Server.PolicyEngineFileDir != "" {
+		pb, err := policyFile.NewFilePolicyBackend(logger.Component(h.logger, logger.ComponentPolicy), h.cfg.Server.PolicyEngineFileDir)
+		if err != nil {
+			return errors.Wrap(err, "failed to load policy files")
+		}
+		go pb.WatchPolicies(h.stopChan)
+		h.policyBackend = pb
+		return nil
 	}
 
 	if h.cfg.Server.ConsulStorageBackend {
-		h.policyBackend = consul.NewConsulPolicyBackend(h.logger, h.cfg.Server.ConsulStorageBackendPath, h.consul)
-		return
+		pb := consul.NewConsulPolicyBackend(logger.Component(h.logger, logger.ComponentPolicy),
+			h.cfg.Server.ConsulStorageBackendPath, h.secrets.Value(h.cfg.Server.ConsulStorageBackendPolicyToken), h.consul, h.keyring)
+		if h.cfg.Server.ConsulStorageBackendPolicyWatch {
+			go pb.WatchPolicies(h.stopChan)
+		}
+		h.policyBackend = pb
+		return nil
+	}
+
+	if len(h.cfg.Server.EtcdStorageBackendEndpoints) > 0 {
+		pb := etcd.NewEtcdPolicyBackend(logger.Component(h.logger, logger.ComponentPolicy), h.cfg.Server.EtcdStorageBackendEndpoints,
+			h.cfg.Server.EtcdStorageBackendPath, h.cfg.Server.EtcdStorageBackendUsername,
+			h.secrets.Value(h.cfg.Server.EtcdStorageBackendPassword), h.keyring)
+		if h.cfg.Server.EtcdStorageBackendPolicyWatch {
+			go pb.WatchPolicies(h.stopChan)
+		}
+		h.policyBackend = pb
+		return nil
+	}
+
+	if h.postgres != nil {
+		h.policyBackend = policyPostgres.NewPostgresPolicyBackend(h.postgres, h.keyring)
+		return nil
 	}
 	h.policyBackend = policyMemory.NewJobScalingPolicies()
+	return nil
+}
+
+// setupPostgres connects to the PostgreSQL database used for storage, migrating its schema.
+func (h *HTTPServer) setupPostgres() error {
+	url, err := h.secrets.Value(h.cfg.Server.PostgresStorageBackendURL).Get(context.Background())
+	if err != nil {
+		return errors.Wrap(err, "failed to resolve PostgreSQL URL")
+	}
+
+	db, err := postgres.Open(&postgres.Config{
+		URL:             url,
+		MaxOpenConns:    h.cfg.Server.PostgresStorageBackendMaxOpenConns,
+		MaxIdleConns:    h.cfg.Server.PostgresStorageBackendMaxIdleConns,
+		ConnMaxLifetime: time.Duration(h.cfg.Server.PostgresStorageBackendConnMaxLifetime) * time.Second,
+	})
+	if err != nil {
+		return err
+	}
+	h.postgres = db
+	return nil
+}
+
+// setupFaultInjector creates the fault injector if the operator has enabled fault injection. This
+// must be called before the clients and backends which use the injector are setup.
+func (h *HTTPServer) setupFaultInjector() {
+	if h.cfg.Chaos == nil || !h.cfg.Chaos.Enabled {
+		return
+	}
+
+	h.logger.Warn().
+		Float64("failure-rate", h.cfg.Chaos.FailureRate).
+		Int("max-delay", h.cfg.Chaos.MaxDelay).
+		Msg("fault injection enabled, Nomad, policy backend and metric provider calls will randomly fail")
+
+	h.faults = chaos.NewInjector(h.logger, h.cfg.Chaos.FailureRate, time.Duration(h.cfg.Chaos.MaxDelay)*time.Millisecond)
+}
+
+// setupSecrets creates the resolver used for credentials which are configured as secret
+// references rather than plain values.
+func (h *HTTPServer) setupSecrets() {
+	var cfg secret.Config
+
+	if h.cfg.Secrets != nil {
+		cfg = secret.Config{
+			VaultAddr:       h.cfg.Secrets.VaultAddr,
+			VaultToken:      h.cfg.Secrets.VaultToken,
+			RefreshInterval: time.Duration(h.cfg.Secrets.RefreshInterval) * time.Second,
+		}
+	}
+	h.secrets = secret.NewResolver(cfg, h.logger)
+}
+
+// setupEncryption creates the keyring used to encrypt sensitive fields at rest, if encryption keys
+// are configured. Keys are resolved once, so rotating keys requires a restart.
+func (h *HTTPServer) setupEncryption() error {
+	if h.cfg.Secrets == nil || h.cfg.Secrets.EncryptionKeys == "" {
+		return nil
+	}
+
+	keys, err := encryption.ParseKeys(h.cfg.Secrets.EncryptionKeys, func(ref string) (string, error) {
+		return h.secrets.Resolve(context.Background(), ref)
+	})
+	if err != nil {
+		return err
+	}
+
+	kr, err := encryption.NewKeyring(keys)
+	if err != nil {
+		return err
+	}
+	h.keyring = kr
+
+	h.logger.Info().Str("primary-key-id", keys[0].ID).Int("keys", len(keys)).Msg("encryption at rest enabled")
+	return nil
+}
+
+// setupGossip creates the gossip layer, identified using the cluster member ID. This must be
+// called after the cluster member is setup.
+func (h *HTTPServer) setupGossip() error {
+	h.logger.Debug().Msg("setting up cluster gossip")
+
+	g, err := gossip.New(&gossip.Config{
+		Logger:   logger.Component(h.logger, logger.ComponentCluster),
+		ID:       h.clusterMember.ID().String(),
+		Addr:     h.cfg.Cluster.Addr,
+		BindAddr: h.cfg.Cluster.GossipBindAddr,
+		BindPort: h.cfg.Cluster.GossipPort,
+		Join:     h.cfg.Cluster.GossipJoin,
+		Key:      h.cfg.Cluster.GossipKey,
+	})
+	if err != nil {
+		return err
+	}
+	h.gossip = g
+
+	return nil
 }
 
 func (h *HTTPServer) setupNomadClient() error {
 	h.logger.Debug().Msg("setting up Nomad client")
 
-	nc, err := client.NewNomadClient()
+	pool, err := client.NewNomadPool(h.logger, h.cfg.Nomad.Addrs, time.Duration(h.cfg.Nomad.ProbeInterval)*time.Second)
 	if err != nil {
 		return err
 	}
-	h.nomad = nc
+	pool.SetFaultInjector(h.faults)
+	h.nomad = pool
 
 	return nil
 }
@@ -256,14 +657,38 @@ func (h *HTTPServer) setupConsulClient() error {
 func (h *HTTPServer) setupAutoScaling() error {
 	h.logger.Debug().Msg("setting up Sherpa internal auto-scaling engine")
 	autoscaleCfg := &autoscale.SetupConfig{
-		StrictChecking:    h.cfg.Server.StrictPolicyChecking,
-		ScalingInterval:   h.cfg.Server.InternalAutoScalerEvalPeriod,
-		ScalingThreads:    h.cfg.Server.InternalAutoScalerNumThreads,
-		MetricProviderCfg: h.cfg.MetricProvider,
-		Logger:            h.logger,
-		PolicyBackend:     h.policyBackend,
-		Scale:             h.scaleBackend,
-		Nomad:             h.nomad,
+		StrictChecking:        h.cfg.Server.StrictPolicyChecking,
+		ScalingInterval:       h.cfg.Server.InternalAutoScalerEvalPeriod,
+		ScalingThreads:        h.cfg.Server.InternalAutoScalerNumThreads,
+		ScalingThreadsMin:     h.cfg.Server.InternalAutoScalerMinThreads,
+		ScalingThreadsMax:     h.cfg.Server.InternalAutoScalerMaxThreads,
+		MetricProviderCfg:     h.cfg.MetricProvider,
+		EvaluationLogPath:     h.cfg.Server.InternalAutoScalerEvalLogPath,
+		NomadLatencyThreshold: h.cfg.Server.InternalAutoScalerNomadLatencyThreshold,
+		MaxStaleness:          h.cfg.Server.InternalAutoScalerMaxStaleness,
+		MinStatsCoverage:      h.cfg.Server.InternalAutoScalerMinStatsCoverage,
+		GroupConcurrency:      h.cfg.Server.InternalAutoScalerGroupConcurrency,
+		AllocStatsCacheTTL:    h.cfg.Server.InternalAutoScalerAllocStatsCacheTTL,
+		OrphanPolicyGC:        h.cfg.Server.InternalAutoScalerOrphanPolicyGC,
+		DrainAwareScaleIn:     h.cfg.Server.InternalAutoScalerDrainAwareScaleIn,
+		EvaluationTimeout:     h.cfg.Server.InternalAutoScalerEvalTimeout,
+		NomadTimeout:          h.cfg.Server.NomadAPITimeout,
+		DryRun:                h.cfg.Server.ReadOnly || h.cfg.Server.InternalAutoScalerShadowMode,
+		ShadowMode:            h.cfg.Server.InternalAutoScalerShadowMode,
+		BoundsEnforcement:     h.cfg.Server.InternalAutoScalerBoundsEnforcement,
+		Logger:                logger.Component(h.logger, logger.ComponentAutoscale),
+		PolicyBackend:         h.policyBackend,
+		Scale:                 h.scaleBackend,
+		Cooldowns:             h.stateBackend,
+		Nomad:                 h.nomad,
+		Consul:                h.consul,
+		FaultInjector:         h.faults,
+		Secrets:               h.secrets,
+		JobFilter:             h.jobFilter,
+	}
+
+	if h.clusterMember.ShardingEnabled() {
+		autoscaleCfg.Shard = h.clusterMember
 	}
 
 	as, err := autoscale.NewAutoScaleServer(autoscaleCfg)
@@ -275,12 +700,62 @@ func (h *HTTPServer) setupAutoScaling() error {
 	return nil
 }
 
-func (h *HTTPServer) setupScaler() {
-	h.scaleBackend = scale.NewScaler(h.nomad, h.logger, h.stateBackend, h.cfg.Server.StrictPolicyChecking)
+func (h *HTTPServer) setupScaler() error {
+	notifiers, err := h.setupNotifiers()
+	if err != nil {
+		return err
+	}
+
+	h.scaleBackend = scale.NewScaler(h.nomad, logger.Component(h.logger, logger.ComponentScale), h.stateBackend,
+		h.cfg.Server.StrictPolicyChecking, time.Duration(h.cfg.Server.NomadAPITimeout)*time.Second,
+		hook.NewRunner(h.cfg.Server.ScalingHooksExecEnabled, h.secrets.Value(h.cfg.Server.ScalingHooksSigningSecret)),
+		notifiers...)
+	h.scaleBackend.SetPromotionGuard(h.cfg.Server.ScaleInPromotionGuard)
+	return nil
+}
+
+// setupNotifiers builds the configured scaling event notifiers. These are wrapped by the
+// notification queue, so that deliveries are retried if an integration is unavailable, and by a
+// filter which drops events below the minimum severity or of muted job groups.
+func (h *HTTPServer) setupNotifiers() ([]notify.Notifier, error) {
+	var notifiers []notify.Notifier
+
+	if h.cfg.Notify.GrafanaAddr != "" {
+		h.logger.Debug().Msg("setting up Grafana scaling event notifier")
+		notifiers = append(notifiers, grafana.NewClient(h.cfg.Notify.GrafanaAddr, h.cfg.Notify.GrafanaToken, h.cfg.Notify.GrafanaTags))
+	}
+
+	if h.cfg.Notify.TeamsWebhookURL != "" {
+		h.logger.Debug().Msg("setting up Microsoft Teams scaling event notifier")
+		notifiers = append(notifiers, teams.NewClient(h.secrets.Value(h.cfg.Notify.TeamsWebhookURL)))
+	}
+
+	if h.cfg.Notify.DiscordWebhookURL != "" {
+		h.logger.Debug().Msg("setting up Discord scaling event notifier")
+		notifiers = append(notifiers, discord.NewClient(h.secrets.Value(h.cfg.Notify.DiscordWebhookURL)))
+	}
+
+	if len(notifiers) == 0 {
+		return nil, nil
+	}
+
+	minSeverity, err := notify.ParseSeverity(h.cfg.Notify.MinSeverity)
+	if err != nil {
+		return nil, err
+	}
+
+	q, err := notify.NewQueue(logger.Component(h.logger, logger.ComponentScale), h.cfg.Notify.QueuePath,
+		h.cfg.Notify.QueueMaxAttempts, notifiers...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to setup notification queue")
+	}
+	h.notifyQueue = q
+
+	return []notify.Notifier{notify.NewFilter(q, h.notifyMutes, minSeverity)}, nil
 }
 
 func (h *HTTPServer) setupDeploymentWatcher() {
-	h.deploymentWatcher = deployment.New(h.logger, h.nomad)
+	h.deploymentWatcher = deployment.New(logger.Component(h.logger, logger.ComponentWatcher), h.nomad)
 }
 
 func (h *HTTPServer) setupListener() net.Listener {
@@ -320,24 +795,68 @@ func (h *HTTPServer) leaderUpdateHandler() {
 // handleLeaderUpdateMsg is responsible for acting on a leadership message and performing the
 // start/stop actions as a result.
 func (h *HTTPServer) handleLeaderUpdateMsg(isLeader bool) {
+	if h.gossip != nil {
+		h.gossip.SetLeader(isLeader)
+	}
+
 	switch isLeader {
 	case true:
-		if h.autoScale != nil && !h.autoScale.IsRunning() {
-			go h.autoScale.Run()
+		// With sharding enabled the autoscaler is already running, so the leader only performs
+		// the reconciliation pass of the stored scaling state.
+		if h.clusterMember.ShardingEnabled() {
+			go h.reconciler.Run()
+		} else {
+			go h.startAutoScaling()
 		}
 		if !h.gcIsRunning {
 			go h.runGarbageCollectionLoop()
 		}
+		h.startClusterScaling()
 	default:
-		if h.autoScale != nil && h.autoScale.IsRunning() {
+		if !h.clusterMember.ShardingEnabled() && h.autoScale != nil && h.autoScale.IsRunning() {
 			h.autoScale.Stop()
 		}
+		h.stopClusterScaling()
 		if h.gcIsRunning {
 			h.stopChan <- struct{}{}
 		}
 	}
 }
 
+// startAutoScaling performs the reconciliation pass of the stored scaling state, and restores any
+// autoscaler state handed over by the previous leader, so that the autoscaler does not start
+// evaluating cold, before starting the autoscaler. The autoscaler is not started if leadership was
+// lost while reconciling.
+func (h *HTTPServer) startAutoScaling() {
+	h.reconciler.Run()
+
+	if isLeader, _, _, _ := h.clusterMember.Leader(); !isLeader {
+		return
+	}
+	if h.autoScale != nil && !h.autoScale.IsRunning() {
+		h.restoreHandoverState()
+		h.autoScale.Run()
+	}
+}
+
+// startClusterScaling starts the cluster scaler, if it is enabled and not already running.
+func (h *HTTPServer) startClusterScaling() {
+	if h.clusterScaler == nil || h.clusterScalerStop != nil {
+		return
+	}
+	h.clusterScalerStop = make(chan struct{})
+	go h.clusterScaler.Run(h.clusterScalerStop)
+}
+
+// stopClusterScaling stops the cluster scaler, if it is running.
+func (h *HTTPServer) stopClusterScaling() {
+	if h.clusterScalerStop == nil {
+		return
+	}
+	close(h.clusterScalerStop)
+	h.clusterScalerStop = nil
+}
+
 // Stop is used to synchronise the shutdown of background tasks before the server exits.
 func (h *HTTPServer) Stop() error {
 	h.logger.Info().Msg("gracefully shutting down HTTP server and sub-processes")
@@ -348,6 +867,13 @@ func (h *HTTPServer) Stop() error {
 	if h.autoScale != nil && h.autoScale.IsRunning() {
 		h.autoScale.Stop()
 	}
+	h.stopClusterScaling()
+
+	// Inform the other servers that this server is leaving, so they do not wait for it to be
+	// detected as dead.
+	if h.gossip != nil {
+		h.gossip.Stop()
+	}
 
 	// Stop the leadership loop and remove any stored leadership information. It is not important
 	// that this happens cleanly, but preferred.
@@ -358,7 +884,12 @@ func (h *HTTPServer) Stop() error {
 
 	// When calling shutdown, the process will wait for all active connections to finish. This
 	// protects against interrupting scaling events triggered via the API.
-	return h.Shutdown(context.Background())
+	err := h.Shutdown(context.Background())
+
+	if h.postgres != nil {
+		_ = h.postgres.Close()
+	}
+	return err
 }
 
 // handleSignals is responsible for blocking on receiving OS signals, then handling signals as
@@ -1,6 +1,10 @@
 package state
 
-import "github.com/gofrs/uuid"
+import (
+	"encoding/json"
+
+	"github.com/gofrs/uuid"
+)
 
 // ClusterInfo is our high level cluster information which holds unique identifiers for each
 // cluster.
@@ -28,4 +32,38 @@ type ClusterMember struct {
 	// AdvertiseAddr is the Sherpa server advertise address which can be used for NAT traversal
 	// when redirecting requests to the cluster leader.
 	AdvertiseAddr string
+
+	// Heartbeat is the unix nano time at which the member last refreshed its registration. It is
+	// only set on member registrations, which are used when evaluation sharding is enabled.
+	Heartbeat int64 `json:",omitempty"`
 }
+
+// Handover is used to transfer the running of the autoscaler from the cluster leader to another
+// server, such as a newer version of Sherpa during a rolling upgrade. A handover is requested by
+// the new server, or the operator, and completed by the leader once it has stopped evaluating.
+type Handover struct {
+
+	// RequestedBy is the ID of the server which requested the handover. It is empty when the
+	// handover was triggered by the operator using the API.
+	RequestedBy uuid.UUID
+
+	// Version is the Sherpa version of the server which requested the handover.
+	Version string
+
+	// RequestTime is the unix nano time at which the handover was requested.
+	RequestTime int64
+
+	// CompletedBy is the ID of the leader which completed the handover.
+	CompletedBy uuid.UUID
+
+	// CompleteTime is the unix nano time at which the leader completed the handover, and is 0
+	// while the handover is pending.
+	CompleteTime int64
+
+	// State is the internal state of the autoscaler, captured by the leader once all in-flight
+	// evaluations finished, which is restored by the next leader.
+	State json.RawMessage `json:",omitempty"`
+}
+
+// IsPending returns whether the handover has been requested, but not yet completed by the leader.
+func (h *Handover) IsPending() bool { return h.CompleteTime == 0 }
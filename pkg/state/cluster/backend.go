@@ -32,6 +32,43 @@ type Backend interface {
 	// member ID.
 	GetClusterLeader(id string) (*state.ClusterMember, error)
 
+	// IncrementFencingToken atomically increments the cluster fencing token, returning the new
+	// value. This is called each time a server obtains leadership, so that the token identifies
+	// the current leadership term.
+	IncrementFencingToken() (uint64, error)
+
+	// GetFencingToken returns the current cluster fencing token, or 0 if leadership has never
+	// been obtained.
+	GetFencingToken() (uint64, error)
+
+	// PutClusterMember is used to register or refresh the entry of a cluster member. The member
+	// entries are used to determine the healthy members when evaluation sharding is enabled.
+	PutClusterMember(member *state.ClusterMember) error
+
+	// GetClusterMembers returns all the registered cluster member entries, including those which
+	// have not recently refreshed their registration.
+	GetClusterMembers() ([]*state.ClusterMember, error)
+
+	// DeleteClusterMember will delete the member entry of the passed ID if it exists.
+	DeleteClusterMember(uuid uuid.UUID) error
+
+	// PutHandover is used to write the current leadership handover, replacing any existing entry.
+	PutHandover(handover *state.Handover) error
+
+	// GetHandover returns the current leadership handover, or nil if there is none.
+	GetHandover() (*state.Handover, error)
+
+	// DeleteHandover will delete the current leadership handover if it exists.
+	DeleteHandover() error
+
+	// PutParkedNodes is used to write the IDs of the Nomad client nodes parked by the cluster
+	// scaler, replacing any existing entry.
+	PutParkedNodes(ids []string) error
+
+	// GetParkedNodes returns the IDs of the Nomad client nodes parked by the cluster scaler. Only
+	// these nodes are returned to service by a cluster scale out.
+	GetParkedNodes() ([]string, error)
+
 	// Lock is used for mutual exclusion based on the passed value.
 	Lock(value string) (BackendLock, error)
 
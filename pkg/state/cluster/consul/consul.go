@@ -1,12 +1,15 @@
 package consul
 
 import (
+	"context"
 	"encoding/json"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/gofrs/uuid"
 	"github.com/hashicorp/consul/api"
+	"github.com/jrasell/sherpa/pkg/retry"
 	"github.com/jrasell/sherpa/pkg/state"
 	"github.com/jrasell/sherpa/pkg/state/cluster"
 	"github.com/pkg/errors"
@@ -14,20 +17,42 @@ import (
 )
 
 const (
-	sessionLockName   = "Sherpa Lock"
-	clusterInfoPath   = "cluster/info"
-	clusterLockPath   = "cluster/lock"
-	clusterLeaderPath = "cluster/leader/"
+	sessionLockName     = "Sherpa Lock"
+	clusterInfoPath     = "cluster/info"
+	clusterLockPath     = "cluster/lock"
+	clusterLeaderPath   = "cluster/leader/"
+	clusterFencePath    = "cluster/fencing-token"
+	clusterMemberPath   = "cluster/members/"
+	clusterHandoverPath = "cluster/handover"
+	clusterParkedPath   = "cluster/parked-nodes"
+
+	// fencingTokenCASAttempts is the number of times incrementing the fencing token is attempted
+	// when the check-and-set fails due to a concurrent update.
+	fencingTokenCASAttempts = 5
 )
 
+// fencingTokenRetryPolicy is used to retry incrementing the fencing token. The jittered backoff
+// prevents servers contending for the token from retrying in lockstep.
+var fencingTokenRetryPolicy = retry.Policy{
+	Attempts:  fencingTokenCASAttempts,
+	BaseDelay: 50 * time.Millisecond,
+	MaxDelay:  time.Second,
+}
+
+var errFencingTokenConflict = errors.New("failed to increment fencing token due to concurrent updates")
+
 type ClusterBackend struct {
 	client *api.Client
 	kv     *api.KV
 	logger zerolog.Logger
 
-	clusterInfoPath   string
-	clusterLockPath   string
-	clusterLeaderPath string
+	clusterInfoPath     string
+	clusterLockPath     string
+	clusterLeaderPath   string
+	clusterFencePath    string
+	clusterMemberPath   string
+	clusterHandoverPath string
+	clusterParkedPath   string
 
 	sessionTTL   string
 	lockWaitTime time.Duration
@@ -41,14 +66,18 @@ type ClusterLock struct {
 
 func NewStateBackend(log zerolog.Logger, path string, client *api.Client) cluster.Backend {
 	return &ClusterBackend{
-		client:            client,
-		kv:                client.KV(),
-		clusterInfoPath:   path + clusterInfoPath,
-		clusterLockPath:   path + clusterLockPath,
-		clusterLeaderPath: path + clusterLeaderPath,
-		logger:            log,
-		sessionTTL:        api.DefaultLockSessionTTL,
-		lockWaitTime:      api.DefaultLockWaitTime,
+		client:              client,
+		kv:                  client.KV(),
+		clusterInfoPath:     path + clusterInfoPath,
+		clusterLockPath:     path + clusterLockPath,
+		clusterLeaderPath:   path + clusterLeaderPath,
+		clusterFencePath:    path + clusterFencePath,
+		clusterMemberPath:   path + clusterMemberPath,
+		clusterHandoverPath: path + clusterHandoverPath,
+		clusterParkedPath:   path + clusterParkedPath,
+		logger:              log,
+		sessionTTL:          api.DefaultLockSessionTTL,
+		lockWaitTime:        api.DefaultLockWaitTime,
 	}
 }
 
@@ -135,6 +164,154 @@ func (c ClusterBackend) PutClusterLeader(leader *state.ClusterMember) error {
 	return err
 }
 
+func (c ClusterBackend) IncrementFencingToken() (uint64, error) {
+	var token uint64
+
+	err := retry.Do(context.Background(), retry.TargetConsul, fencingTokenRetryPolicy, func() error {
+		kv, _, err := c.kv.Get(c.clusterFencePath, &api.QueryOptions{RequireConsistent: true})
+		if err != nil {
+			return err
+		}
+
+		var index uint64
+		token = 0
+
+		if kv != nil {
+			if token, err = strconv.ParseUint(string(kv.Value), 10, 64); err != nil {
+				return retry.Permanent(errors.Wrap(err, "failed to parse fencing token"))
+			}
+			index = kv.ModifyIndex
+		}
+		token++
+
+		// The check-and-set ensures that two servers cannot obtain the same token. A modify index
+		// of 0 only succeeds if the key does not yet exist.
+		pair := api.KVPair{Key: c.clusterFencePath, Value: []byte(strconv.FormatUint(token, 10)), ModifyIndex: index}
+
+		ok, _, err := c.kv.CAS(&pair, nil)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errFencingTokenConflict
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return token, nil
+}
+
+func (c ClusterBackend) GetFencingToken() (uint64, error) {
+	kv, _, err := c.kv.Get(c.clusterFencePath, &api.QueryOptions{RequireConsistent: true})
+	if err != nil {
+		return 0, err
+	}
+
+	if kv == nil {
+		return 0, nil
+	}
+	return strconv.ParseUint(string(kv.Value), 10, 64)
+}
+
+func (c ClusterBackend) PutClusterMember(member *state.ClusterMember) error {
+	bytes, err := json.Marshal(member)
+	if err != nil {
+		return err
+	}
+
+	kv := api.KVPair{
+		Key:   c.clusterMemberPath + member.ID.String(),
+		Value: bytes,
+	}
+
+	_, err = c.kv.Put(&kv, nil)
+	return err
+}
+
+func (c ClusterBackend) GetClusterMembers() ([]*state.ClusterMember, error) {
+	kvs, _, err := c.kv.List(c.clusterMemberPath, &api.QueryOptions{RequireConsistent: true})
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]*state.ClusterMember, 0, len(kvs))
+
+	for _, kv := range kvs {
+		mem := &state.ClusterMember{}
+		if err := json.Unmarshal(kv.Value, mem); err != nil {
+			return nil, errors.Wrapf(err, "failed to unmarshal cluster member entry %s", kv.Key)
+		}
+		out = append(out, mem)
+	}
+	return out, nil
+}
+
+func (c ClusterBackend) DeleteClusterMember(uuid uuid.UUID) error {
+	_, err := c.kv.Delete(c.clusterMemberPath+uuid.String(), nil)
+	return err
+}
+
+func (c ClusterBackend) PutHandover(handover *state.Handover) error {
+	bytes, err := json.Marshal(handover)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.kv.Put(&api.KVPair{Key: c.clusterHandoverPath, Value: bytes}, nil)
+	return err
+}
+
+func (c ClusterBackend) GetHandover() (*state.Handover, error) {
+	kv, _, err := c.kv.Get(c.clusterHandoverPath, &api.QueryOptions{RequireConsistent: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if kv == nil {
+		return nil, nil
+	}
+
+	handover := &state.Handover{}
+	if err := json.Unmarshal(kv.Value, handover); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal leadership handover entry")
+	}
+	return handover, nil
+}
+
+func (c ClusterBackend) DeleteHandover() error {
+	_, err := c.kv.Delete(c.clusterHandoverPath, nil)
+	return err
+}
+
+func (c ClusterBackend) PutParkedNodes(ids []string) error {
+	bytes, err := json.Marshal(ids)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.kv.Put(&api.KVPair{Key: c.clusterParkedPath, Value: bytes}, nil)
+	return err
+}
+
+func (c ClusterBackend) GetParkedNodes() ([]string, error) {
+	kv, _, err := c.kv.Get(c.clusterParkedPath, &api.QueryOptions{RequireConsistent: true})
+	if err != nil {
+		return nil, err
+	}
+
+	if kv == nil {
+		return nil, nil
+	}
+
+	var ids []string
+	if err := json.Unmarshal(kv.Value, &ids); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal parked nodes entry")
+	}
+	return ids, nil
+}
+
 func (c ClusterBackend) Lock(value string) (cluster.BackendLock, error) {
 	opts := &api.LockOptions{
 		Key:            c.clusterLockPath,
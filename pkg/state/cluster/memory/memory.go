@@ -3,6 +3,7 @@ package memory
 import (
 	"fmt"
 	"sync"
+	"sync/atomic"
 
 	"github.com/gofrs/uuid"
 	"github.com/jrasell/sherpa/pkg/state"
@@ -10,10 +11,18 @@ import (
 )
 
 type ClusterBackend struct {
-	leaderInfo  map[uuid.UUID]*state.ClusterMember
-	leaderLock  sync.RWMutex
-	clusterInfo *state.ClusterInfo
-	clusterLock sync.RWMutex
+	fencingToken uint64
+
+	leaderInfo   map[uuid.UUID]*state.ClusterMember
+	leaderLock   sync.RWMutex
+	members      map[uuid.UUID]*state.ClusterMember
+	membersLock  sync.RWMutex
+	clusterInfo  *state.ClusterInfo
+	clusterLock  sync.RWMutex
+	handover     *state.Handover
+	handoverLock sync.RWMutex
+	parked       []string
+	parkedLock   sync.RWMutex
 }
 
 type ClusterLock struct {
@@ -26,6 +35,7 @@ type ClusterLock struct {
 func NewStateBackend() cluster.Backend {
 	return &ClusterBackend{
 		leaderInfo: make(map[uuid.UUID]*state.ClusterMember),
+		members:    make(map[uuid.UUID]*state.ClusterMember),
 	}
 }
 
@@ -86,6 +96,73 @@ func (c *ClusterBackend) GetClusterLeader(id string) (*state.ClusterMember, erro
 	return nil, nil
 }
 
+func (c *ClusterBackend) IncrementFencingToken() (uint64, error) {
+	return atomic.AddUint64(&c.fencingToken, 1), nil
+}
+
+func (c *ClusterBackend) GetFencingToken() (uint64, error) {
+	return atomic.LoadUint64(&c.fencingToken), nil
+}
+
+func (c *ClusterBackend) PutClusterMember(member *state.ClusterMember) error {
+	c.membersLock.Lock()
+	c.members[member.ID] = member
+	c.membersLock.Unlock()
+	return nil
+}
+
+func (c *ClusterBackend) GetClusterMembers() ([]*state.ClusterMember, error) {
+	c.membersLock.RLock()
+	defer c.membersLock.RUnlock()
+
+	out := make([]*state.ClusterMember, 0, len(c.members))
+	for _, member := range c.members {
+		out = append(out, member)
+	}
+	return out, nil
+}
+
+func (c *ClusterBackend) DeleteClusterMember(uuid uuid.UUID) error {
+	c.membersLock.Lock()
+	delete(c.members, uuid)
+	c.membersLock.Unlock()
+	return nil
+}
+
+func (c *ClusterBackend) PutHandover(handover *state.Handover) error {
+	c.handoverLock.Lock()
+	c.handover = handover
+	c.handoverLock.Unlock()
+	return nil
+}
+
+func (c *ClusterBackend) GetHandover() (*state.Handover, error) {
+	c.handoverLock.RLock()
+	handover := c.handover
+	c.handoverLock.RUnlock()
+	return handover, nil
+}
+
+func (c *ClusterBackend) DeleteHandover() error {
+	c.handoverLock.Lock()
+	c.handover = nil
+	c.handoverLock.Unlock()
+	return nil
+}
+
+func (c *ClusterBackend) PutParkedNodes(ids []string) error {
+	c.parkedLock.Lock()
+	c.parked = append([]string(nil), ids...)
+	c.parkedLock.Unlock()
+	return nil
+}
+
+func (c *ClusterBackend) GetParkedNodes() ([]string, error) {
+	c.parkedLock.RLock()
+	defer c.parkedLock.RUnlock()
+	return append([]string(nil), c.parked...), nil
+}
+
 func (c *ClusterBackend) Lock(value string) (cluster.BackendLock, error) {
 	return &ClusterLock{value: value}, nil
 }
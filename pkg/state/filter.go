@@ -0,0 +1,105 @@
+package state
+
+import (
+	"strings"
+
+	"github.com/gofrs/uuid"
+)
+
+// Outcome groups scaling event statuses and reasons into the result of the scaling event, for use
+// when filtering events.
+type Outcome string
+
+const (
+	// OutcomeSuccess matches events which completed a scaling action.
+	OutcomeSuccess Outcome = "success"
+
+	// OutcomeFailure matches events which failed to complete a scaling action.
+	OutcomeFailure Outcome = "failure"
+
+	// OutcomeSkipped matches events recording that a job group was not scaled due to a skip
+	// reason, such as the group being in cooldown.
+	OutcomeSkipped Outcome = "skipped"
+)
+
+func (o Outcome) String() string { return string(o) }
+
+// EventFilter selects scaling events by job, group, direction, outcome and time range. Fields with
+// their zero value do not filter events.
+type EventFilter struct {
+	Job       string
+	Group     string
+	Direction string
+	Outcome   Outcome
+
+	// From and To are inclusive UnixNano timestamps bounding the time of the scaling event.
+	From int64
+	To   int64
+}
+
+// IsEmpty returns true if the filter does not filter any events.
+func (f *EventFilter) IsEmpty() bool {
+	return f == nil || *f == EventFilter{}
+}
+
+// Match returns true if the scaling event of the job group, identified using the job-name:group-name
+// key, passes the filter.
+func (f *EventFilter) Match(jobGroup string, event *ScalingEvent) bool {
+	if f.IsEmpty() {
+		return true
+	}
+	if event == nil {
+		return false
+	}
+
+	job, group := jobGroup, ""
+	if split := strings.SplitN(jobGroup, ":", 2); len(split) == 2 {
+		job, group = split[0], split[1]
+	}
+
+	switch {
+	case f.Job != "" && f.Job != job,
+		f.Group != "" && f.Group != group,
+		f.Direction != "" && f.Direction != event.Details.Direction,
+		f.Outcome != "" && f.Outcome != event.Outcome(),
+		f.From != 0 && event.Time < f.From,
+		f.To != 0 && event.Time > f.To:
+		return false
+	}
+	return true
+}
+
+// Filter returns the scaling events which pass the filter. Scaling events which include multiple
+// job groups only contain the groups which pass the filter.
+func (f *EventFilter) Filter(events map[uuid.UUID]map[string]*ScalingEvent) map[uuid.UUID]map[string]*ScalingEvent {
+	if f.IsEmpty() {
+		return events
+	}
+
+	out := make(map[uuid.UUID]map[string]*ScalingEvent)
+
+	for id, jobEvents := range events {
+		for jg, event := range jobEvents {
+			if !f.Match(jg, event) {
+				continue
+			}
+			if _, ok := out[id]; !ok {
+				out[id] = make(map[string]*ScalingEvent)
+			}
+			out[id][jg] = event
+		}
+	}
+	return out
+}
+
+// Outcome returns the outcome of the scaling event.
+func (e *ScalingEvent) Outcome() Outcome {
+	switch {
+	case e.Reason.IsSkip():
+		return OutcomeSkipped
+	case e.Status == StatusFailed:
+		return OutcomeFailure
+	default:
+		return OutcomeSuccess
+	}
+}
@@ -0,0 +1,89 @@
+package state
+
+import (
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEventFilter_Match(t *testing.T) {
+	event := &ScalingEvent{
+		Time:    100,
+		Status:  StatusCompleted,
+		Details: EventDetails{Direction: "out"},
+		Reason:  ReasonThresholdCPUOut,
+	}
+
+	testCases := []struct {
+		filter         *EventFilter
+		jobGroup       string
+		event          *ScalingEvent
+		expectedOutput bool
+		name           string
+	}{
+		{filter: nil, jobGroup: "example:cache", event: event, expectedOutput: true, name: "nil filter"},
+		{filter: &EventFilter{}, jobGroup: "example:cache", event: event, expectedOutput: true, name: "empty filter"},
+		{filter: &EventFilter{Job: "example", Group: "cache"}, jobGroup: "example:cache", event: event, expectedOutput: true, name: "job and group match"},
+		{filter: &EventFilter{Group: "proxy"}, jobGroup: "example:cache", event: event, expectedOutput: false, name: "group mismatch"},
+		{filter: &EventFilter{Job: "other"}, jobGroup: "example:cache", event: event, expectedOutput: false, name: "job mismatch"},
+		{filter: &EventFilter{Direction: "in"}, jobGroup: "example:cache", event: event, expectedOutput: false, name: "direction mismatch"},
+		{filter: &EventFilter{Outcome: OutcomeSuccess}, jobGroup: "example:cache", event: event, expectedOutput: true, name: "success outcome"},
+		{filter: &EventFilter{Outcome: OutcomeFailure}, jobGroup: "example:cache", event: event, expectedOutput: false, name: "failure outcome mismatch"},
+		{
+			filter:         &EventFilter{Outcome: OutcomeFailure},
+			jobGroup:       "example:cache",
+			event:          &ScalingEvent{Status: StatusFailed},
+			expectedOutput: true,
+			name:           "failure outcome",
+		},
+		{
+			filter:         &EventFilter{Outcome: OutcomeSkipped},
+			jobGroup:       "example:cache",
+			event:          &ScalingEvent{Status: StatusCompleted, Reason: ReasonCooldownSkip},
+			expectedOutput: true,
+			name:           "skipped outcome",
+		},
+		{
+			filter:         &EventFilter{Outcome: OutcomeSkipped},
+			jobGroup:       "example:cache",
+			event:          &ScalingEvent{Status: StatusCompleted, Reason: ReasonPromotionDeferred},
+			expectedOutput: true,
+			name:           "deferred outcome",
+		},
+		{
+			filter:         &EventFilter{Outcome: OutcomeSkipped},
+			jobGroup:       "example:cache",
+			event:          &ScalingEvent{Status: StatusCompleted, Reason: ReasonJobOrphaned},
+			expectedOutput: true,
+			name:           "orphaned outcome",
+		},
+		{filter: &EventFilter{From: 100, To: 100}, jobGroup: "example:cache", event: event, expectedOutput: true, name: "inclusive time range"},
+		{filter: &EventFilter{From: 101}, jobGroup: "example:cache", event: event, expectedOutput: false, name: "event before range"},
+		{filter: &EventFilter{To: 99}, jobGroup: "example:cache", event: event, expectedOutput: false, name: "event after range"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expectedOutput, tc.filter.Match(tc.jobGroup, tc.event), tc.name)
+	}
+}
+
+func TestEventFilter_Filter(t *testing.T) {
+	id := uuid.Must(uuid.NewV4())
+	other := uuid.Must(uuid.NewV4())
+
+	events := map[uuid.UUID]map[string]*ScalingEvent{
+		id: {
+			"example:cache": {Details: EventDetails{Direction: "out"}},
+			"example:proxy": {Details: EventDetails{Direction: "in"}},
+		},
+		other: {"example:cache": {Details: EventDetails{Direction: "in"}}},
+	}
+
+	filter := &EventFilter{Direction: "out"}
+	assert.Equal(t, map[uuid.UUID]map[string]*ScalingEvent{
+		id: {"example:cache": events[id]["example:cache"]},
+	}, filter.Filter(events))
+
+	assert.Equal(t, events, (&EventFilter{}).Filter(events))
+}
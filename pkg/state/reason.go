@@ -0,0 +1,74 @@
+package state
+
+// Reason is a code from a fixed taxonomy which describes why a scaling event took place, or why a
+// job group was skipped during an autoscaling evaluation. Unlike free-form meta, reasons allow
+// tooling to reliably aggregate scaling activity.
+type Reason string
+
+const (
+	// ReasonThresholdCPUOut and ReasonThresholdCPUIn indicate the group was scaled due to a Nomad
+	// CPU utilisation check.
+	ReasonThresholdCPUOut Reason = "threshold-cpu-out"
+	ReasonThresholdCPUIn  Reason = "threshold-cpu-in"
+
+	// ReasonThresholdMemoryOut and ReasonThresholdMemoryIn indicate the group was scaled due to a
+	// Nomad memory utilisation check.
+	ReasonThresholdMemoryOut Reason = "threshold-memory-out"
+	ReasonThresholdMemoryIn  Reason = "threshold-memory-in"
+
+	// ReasonThresholdDiskOut and ReasonThresholdDiskIn indicate the group was scaled due to a
+	// Nomad ephemeral disk utilisation check.
+	ReasonThresholdDiskOut Reason = "threshold-disk-out"
+	ReasonThresholdDiskIn  Reason = "threshold-disk-in"
+
+	// ReasonThresholdGPUOut and ReasonThresholdGPUIn indicate the group was scaled due to a Nomad
+	// GPU utilisation check.
+	ReasonThresholdGPUOut Reason = "threshold-gpu-out"
+	ReasonThresholdGPUIn  Reason = "threshold-gpu-in"
+
+	// ReasonThresholdCompositeOut and ReasonThresholdCompositeIn indicate the group was scaled due
+	// to a Nomad composite utilisation check.
+	ReasonThresholdCompositeOut Reason = "threshold-composite-out"
+	ReasonThresholdCompositeIn  Reason = "threshold-composite-in"
+
+	// ReasonExternalCheckOut and ReasonExternalCheckIn indicate the group was scaled due to an
+	// external metric provider check.
+	ReasonExternalCheckOut Reason = "external-check-out"
+	ReasonExternalCheckIn  Reason = "external-check-in"
+
+	// ReasonMetricsFallback indicates the group was scaled by the metrics fallback action as all
+	// of its metric sources were unavailable.
+	ReasonMetricsFallback Reason = "metrics-fallback"
+
+	// ReasonBoundsEnforcement indicates the group was scaled as its count was outside the min and
+	// max counts of its policy.
+	ReasonBoundsEnforcement Reason = "bounds-enforcement"
+
+	// ReasonManual indicates the group was scaled by a request to the scaling API.
+	ReasonManual Reason = "manual"
+
+	// ReasonCooldownSkip indicates the group was not evaluated as it is in scaling cooldown.
+	ReasonCooldownSkip Reason = "cooldown-skip"
+
+	// ReasonDeploymentSkip indicates the group was not evaluated as it is currently deploying.
+	ReasonDeploymentSkip Reason = "deployment-skip"
+
+	// ReasonPromotionDeferred indicates a scale in request for the group was deferred until its
+	// deployment, which has canaries awaiting promotion, completes.
+	ReasonPromotionDeferred Reason = "promotion-deferred"
+
+	// ReasonJobOrphaned indicates the group was not evaluated as its job has been stopped or
+	// purged from Nomad, leaving the policy without a running job to scale.
+	ReasonJobOrphaned Reason = "job-orphaned"
+
+	// ReasonUnknown is used when a scaling request does not specify a reason.
+	ReasonUnknown Reason = "unknown"
+)
+
+func (r Reason) String() string { return string(r) }
+
+// IsSkip returns true if the reason describes why a job group was skipped, rather than scaled.
+func (r Reason) IsSkip() bool {
+	return r == ReasonCooldownSkip || r == ReasonDeploymentSkip || r == ReasonPromotionDeferred ||
+		r == ReasonJobOrphaned
+}
@@ -0,0 +1,182 @@
+// Package report aggregates the stored scaling events into summary statistics over a time range,
+// for use in reviews of scaling behaviour and capacity.
+package report
+
+import (
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/state"
+)
+
+// DefaultFlapThreshold is the number of scaling direction reversals within the report range at
+// which a group is marked as flapping.
+const DefaultFlapThreshold = 3
+
+// Report is the summary of scaling activity over a time range.
+type Report struct {
+	From   time.Time
+	To     time.Time
+	Groups []*GroupReport
+}
+
+// GroupReport is the summary of scaling activity for a single job group.
+type GroupReport struct {
+	JobID string
+	Group string
+
+	// ScaleOut and ScaleIn are the number of completed scaling events in each direction, and
+	// Failed is the number of scaling events which failed.
+	ScaleOut int
+	ScaleIn  int
+	Failed   int
+
+	// FailureRate is the percentage of scaling events which failed.
+	FailureRate float64
+
+	// DirectionChanges is the number of times consecutive completed scaling events reversed
+	// direction, and Flapping is true if this reached the flap threshold.
+	DirectionChanges int
+	Flapping         bool
+
+	// TimeAtMax is the time in seconds the group spent at the max count of its policy, and
+	// TimeAtMaxPercent is this as a percentage of the report range. This can only be calculated
+	// for groups with a policy, using events which stored the resulting group count.
+	TimeAtMax        float64
+	TimeAtMaxPercent float64
+}
+
+// Build aggregates the scaling events which took place between from and to. Events prior to the
+// range are used to determine the count of each group at the start of the range.
+func Build(events map[uuid.UUID]map[string]*state.ScalingEvent, policies map[string]map[string]*policy.GroupScalingPolicy,
+	from, to time.Time, flapThreshold int) *Report {
+
+	grouped := make(map[string][]*state.ScalingEvent)
+
+	for _, jobEvents := range events {
+		for jg, event := range jobEvents {
+			if event.Time <= to.UnixNano() {
+				grouped[jg] = append(grouped[jg], event)
+			}
+		}
+	}
+
+	out := &Report{From: from, To: to, Groups: []*GroupReport{}}
+
+	for jg, groupEvents := range grouped {
+		split := strings.SplitN(jg, ":", 2)
+		if len(split) != 2 {
+			continue
+		}
+
+		sort.Slice(groupEvents, func(i, j int) bool { return groupEvents[i].Time < groupEvents[j].Time })
+
+		var pol *policy.GroupScalingPolicy
+		if jobPolicies, ok := policies[split[0]]; ok {
+			pol = jobPolicies[split[1]]
+		}
+
+		if gr := buildGroupReport(split[0], split[1], groupEvents, pol, from, to, flapThreshold); gr != nil {
+			out.Groups = append(out.Groups, gr)
+		}
+	}
+
+	sort.Slice(out.Groups, func(i, j int) bool {
+		if out.Groups[i].JobID != out.Groups[j].JobID {
+			return out.Groups[i].JobID < out.Groups[j].JobID
+		}
+		return out.Groups[i].Group < out.Groups[j].Group
+	})
+	return out
+}
+
+// buildGroupReport aggregates the time ordered events of a single group. Nil is returned if no
+// events took place within the range.
+func buildGroupReport(job, group string, events []*state.ScalingEvent, pol *policy.GroupScalingPolicy,
+	from, to time.Time, flapThreshold int) *GroupReport {
+
+	gr := &GroupReport{JobID: job, Group: group}
+
+	var (
+		total         int
+		lastDirection string
+
+		// atMax tracks whether the group was at its max count, and since when. The count of the
+		// group is only known after a completed event which stored the resulting count.
+		atMax      bool
+		atMaxSince int64
+		timeAtMax  int64
+	)
+
+	for _, event := range events {
+
+		// Skip events, such as deferred scale in requests, did not change the group count.
+		if event.Reason.IsSkip() {
+			continue
+		}
+		inRange := event.Time >= from.UnixNano()
+
+		if inRange {
+			total++
+		}
+
+		if event.Status != state.StatusCompleted {
+			if inRange {
+				gr.Failed++
+			}
+			continue
+		}
+
+		if inRange {
+			switch event.Details.Direction {
+			case "out":
+				gr.ScaleOut++
+			case "in":
+				gr.ScaleIn++
+			}
+
+			if lastDirection != "" && event.Details.Direction != lastDirection {
+				gr.DirectionChanges++
+			}
+			lastDirection = event.Details.Direction
+		}
+
+		if pol == nil || event.Details.DesiredCount == 0 {
+			continue
+		}
+
+		start := maxInt64(event.Time, from.UnixNano())
+
+		if atMax {
+			timeAtMax += maxInt64(start-atMaxSince, 0)
+		}
+		atMax, atMaxSince = event.Details.DesiredCount >= pol.MaxCount, start
+	}
+
+	if total == 0 {
+		return nil
+	}
+
+	if atMax {
+		timeAtMax += maxInt64(to.UnixNano()-atMaxSince, 0)
+	}
+
+	gr.FailureRate = float64(gr.Failed) * 100 / float64(total)
+	gr.Flapping = flapThreshold > 0 && gr.DirectionChanges >= flapThreshold
+	gr.TimeAtMax = time.Duration(timeAtMax).Seconds()
+
+	if rng := to.Sub(from); rng > 0 {
+		gr.TimeAtMaxPercent = float64(timeAtMax) * 100 / float64(rng)
+	}
+	return gr
+}
+
+func maxInt64(a, b int64) int64 {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,72 @@
+package report
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/policy"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuild(t *testing.T) {
+	from := time.Date(2020, 1, 26, 10, 0, 0, 0, time.UTC)
+	to := from.Add(10 * time.Hour)
+
+	at := func(h int) int64 { return from.Add(time.Duration(h) * time.Hour).UnixNano() }
+
+	event := func(h int, status state.Status, direction string, count int) map[string]*state.ScalingEvent {
+		return map[string]*state.ScalingEvent{"example:cache": {
+			Time:    at(h),
+			Status:  status,
+			Details: state.EventDetails{Direction: direction, DesiredCount: count},
+		}}
+	}
+
+	events := map[uuid.UUID]map[string]*state.ScalingEvent{
+		uuid.Must(uuid.NewV4()): event(-1, state.StatusCompleted, "out", 5),
+		uuid.Must(uuid.NewV4()): event(2, state.StatusCompleted, "in", 4),
+		uuid.Must(uuid.NewV4()): event(4, state.StatusCompleted, "out", 5),
+		uuid.Must(uuid.NewV4()): event(5, state.StatusFailed, "out", 0),
+		uuid.Must(uuid.NewV4()): event(6, state.StatusCompleted, "in", 3),
+		uuid.Must(uuid.NewV4()): event(11, state.StatusCompleted, "out", 4),
+		uuid.Must(uuid.NewV4()): {"example:proxy": {Time: at(-2), Status: state.StatusCompleted}},
+
+		// Deferred scale in requests did not change the group count, so are not counted.
+		uuid.Must(uuid.NewV4()): {"example:cache": {
+			Time:    at(3),
+			Status:  state.StatusCompleted,
+			Reason:  state.ReasonPromotionDeferred,
+			Details: state.EventDetails{Direction: "in"},
+		}},
+	}
+	policies := map[string]map[string]*policy.GroupScalingPolicy{
+		"example": {"cache": {MaxCount: 5}},
+	}
+
+	actual := Build(events, policies, from, to, 2)
+
+	assert.Equal(t, from, actual.From)
+	assert.Equal(t, to, actual.To)
+	assert.Equal(t, []*GroupReport{
+		{
+			JobID:            "example",
+			Group:            "cache",
+			ScaleOut:         1,
+			ScaleIn:          2,
+			Failed:           1,
+			FailureRate:      25,
+			DirectionChanges: 2,
+			Flapping:         true,
+			TimeAtMax:        (4 * time.Hour).Seconds(),
+			TimeAtMaxPercent: 40,
+		},
+	}, actual.Groups)
+
+	// Without the policy, the time at max cannot be calculated.
+	actual = Build(events, nil, from, to, DefaultFlapThreshold)
+	assert.Len(t, actual.Groups, 1)
+	assert.Equal(t, float64(0), actual.Groups[0].TimeAtMax)
+	assert.False(t, actual.Groups[0].Flapping)
+}
@@ -16,6 +16,10 @@ type ScalingState struct {
 	// particular job group, the entry here should be overwritten. This provides and fast way to
 	// lookup last events and is currently ignored from GC.
 	LatestEvents map[string]*ScalingEvent
+
+	// Cooldowns holds the UnixNano time from which the scaling cooldown of each job group is
+	// measured, using the same job-name:group-name key as LatestEvents.
+	Cooldowns map[string]int64
 }
 
 // ScalingEvent represents a single scaling event state entry that is persisted to the backend
@@ -28,6 +32,11 @@ type ScalingEvent struct {
 	// job to the Nomad API.
 	EvalID string
 
+	// DeploymentID is the Nomad deployment ID which was created when the scheduler processed the
+	// evaluation. It is populated once the evaluation has been processed, and is empty if the job
+	// change did not result in a deployment.
+	DeploymentID string `json:",omitempty"`
+
 	// Source shows the origin source of the scaling event.
 	Source Source
 
@@ -41,7 +50,43 @@ type ScalingEvent struct {
 	// scaling event.
 	Details EventDetails
 
+	// Reason is the code which describes why the scaling event took place.
+	Reason Reason
+
 	Meta map[string]string
+
+	// RunbookURL and Notes are the annotations of the group scaling policy at the time of the
+	// scaling event, so responders can reach the relevant runbook.
+	RunbookURL string `json:",omitempty"`
+	Notes      string `json:",omitempty"`
+
+	// Snapshot is the data the internal autoscaler acted upon when deciding to scale the job
+	// group. It is nil for scaling events which were not triggered by the internal autoscaler.
+	Snapshot *ResourceSnapshot `json:",omitempty"`
+}
+
+// ResourceSnapshot records the metric values gathered for a job group during the autoscaler
+// evaluation which triggered a scaling event, so the decision can be reviewed afterwards.
+type ResourceSnapshot struct {
+	// Nomad is the aggregated resource utilisation of the group allocations, and is nil if the
+	// group was not evaluated using Nomad checks.
+	Nomad *NomadResourceSnapshot `json:",omitempty"`
+
+	// External is the value returned by each external check of the group, keyed by the check
+	// name. Checks which failed to return a value are not included.
+	External map[string]float64 `json:",omitempty"`
+}
+
+// NomadResourceSnapshot is the aggregated resource utilisation of the allocations of a job group,
+// as percentages of the resources allocated to them.
+type NomadResourceSnapshot struct {
+	CPU    float64
+	Memory float64
+	Disk   float64
+	GPU    float64
+
+	// StatsCoverage is the percentage of the group allocations whose resource usage was gathered.
+	StatsCoverage float64
 }
 
 // EventDetails contains information to describe what changes took place during the scaling action.
@@ -52,20 +97,29 @@ type EventDetails struct {
 	// Direction is direction in which the scaling took place. This can be either in or out
 	// representing subtraction and addition respectively.
 	Direction string
+
+	// DesiredCount is the count of the group once the scaling action was applied. It is used to
+	// reconcile the stored state against the running job when a server obtains leadership.
+	DesiredCount int `json:",omitempty"`
 }
 
 // ScalingEventMessage is the message sent to the state writer containing all the required
 // information to construct the persistent state entry.
 type ScalingEventMessage struct {
-	ID        uuid.UUID
-	GroupName string
-	EvalID    string
-	Source    Source
-	Time      int64
-	Status    Status
-	Count     int
-	Direction string
-	Meta      map[string]string
+	ID           uuid.UUID
+	GroupName    string
+	EvalID       string
+	Source       Source
+	Time         int64
+	Status       Status
+	Count        int
+	DesiredCount int
+	Direction    string
+	Reason       Reason
+	Meta         map[string]string
+	RunbookURL   string
+	Notes        string
+	Snapshot     *ResourceSnapshot
 }
 
 // Source represents how the scaling action was invoked.
@@ -6,7 +6,9 @@ import (
 )
 
 // Backend is the interface required for a state storage backend. A state storage backend is used
-// to durably store job scaling state outside of Sherpa.
+// to durably store job scaling state outside of Sherpa. Implementations should pass the shared
+// tests within the conformance package, and emit timing metrics for each function using the
+// scale.state.<backend>.<function> metric keys.
 type Backend interface {
 
 	// GetLatestScalingEvents is used to pull all the currently stored latest scaling events from
@@ -17,8 +19,10 @@ type Backend interface {
 	// from the storage backend if we have a record.
 	GetLatestScalingEvent(job, group string) (*state.ScalingEvent, error)
 
-	// GetScalingEvents returns all scaling events held within the state.
-	GetScalingEvents() (map[uuid.UUID]map[string]*state.ScalingEvent, error)
+	// GetScalingEvents returns the scaling events held within the state which pass the filter.
+	// Scaling events which include multiple job groups only contain the groups which pass the
+	// filter. A nil filter returns all scaling events.
+	GetScalingEvents(filter *state.EventFilter) (map[uuid.UUID]map[string]*state.ScalingEvent, error)
 
 	// GetScalingEvent is used to find an individual event in the state.
 	GetScalingEvent(id uuid.UUID) (map[string]*state.ScalingEvent, error)
@@ -28,6 +32,21 @@ type Backend interface {
 	// manipulated.
 	PutScalingEvent(string, *state.ScalingEventMessage) error
 
+	// PutScalingEventDeployment is used to update the stored scaling event of the job group with
+	// the ID of the Nomad deployment created by the scaling action. The latest scaling event of the
+	// group should only be updated if it is the same event. No error is returned if the scaling
+	// event is not held within the state.
+	PutScalingEventDeployment(id uuid.UUID, job, group, deploymentID string) error
+
+	// GetCooldown returns the UnixNano time from which the scaling cooldown of the job group is
+	// measured. Zero is returned if the backend does not hold a cooldown timestamp for the group.
+	GetCooldown(job, group string) (int64, error)
+
+	// PutCooldown is used to store the UnixNano time from which the scaling cooldown of the job
+	// group is measured. Like the latest scaling events, cooldown timestamps are not subject to
+	// garbage collection.
+	PutCooldown(job, group string, t int64) error
+
 	// RunGarbageCollection triggers are run of the state event garbage collection which is used to
 	// clear up old state entries. This ensures the state backend doesn't just continually grow.
 	RunGarbageCollection()
@@ -0,0 +1,321 @@
+// Package conformance provides the tests which each scaling state backend must pass, ensuring
+// backends behave identically and can be used interchangeably by the Sherpa server.
+package conformance
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/jrasell/sherpa/pkg/state/scale"
+	"github.com/stretchr/testify/assert"
+)
+
+// Run runs the conformance tests against the backend. Each test calls newBackend, which should
+// return a backend which does not hold any state, along with a function which is deferred to
+// clean up the state written by the test.
+func Run(t *testing.T, newBackend func() (scale.Backend, func())) {
+	tests := []struct {
+		name string
+		fn   func(*testing.T, scale.Backend)
+	}{
+		{name: "empty state", fn: testEmptyState},
+		{name: "scaling events", fn: testScalingEvents},
+		{name: "multi group scaling event", fn: testMultiGroupScalingEvent},
+		{name: "filtered scaling events", fn: testFilteredScalingEvents},
+		{name: "latest scaling events", fn: testLatestScalingEvents},
+		{name: "scaling event deployment", fn: testScalingEventDeployment},
+		{name: "cooldowns", fn: testCooldowns},
+		{name: "garbage collection", fn: testGarbageCollection},
+	}
+
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			b, cleanup := newBackend()
+			defer cleanup()
+			tc.fn(t, b)
+		})
+	}
+}
+
+func testEmptyState(t *testing.T, b scale.Backend) {
+	events, err := b.GetScalingEvents(nil)
+	assert.Nil(t, err)
+	assert.Len(t, events, 0)
+
+	event, err := b.GetScalingEvent(uuid.Must(uuid.NewV4()))
+	assert.Nil(t, err)
+	assert.Len(t, event, 0)
+
+	latestEvents, err := b.GetLatestScalingEvents()
+	assert.Nil(t, err)
+	assert.Len(t, latestEvents, 0)
+
+	latest, err := b.GetLatestScalingEvent("example", "cache")
+	assert.Nil(t, err)
+	assert.Nil(t, latest)
+
+	cooldown, err := b.GetCooldown("example", "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), cooldown)
+}
+
+func testScalingEvents(t *testing.T, b scale.Backend) {
+	first := newEventMessage("cache", time.Now().UnixNano())
+	second := newEventMessage("cache", time.Now().UnixNano())
+
+	assert.Nil(t, b.PutScalingEvent("example", first))
+	assert.Nil(t, b.PutScalingEvent("example", second))
+
+	event, err := b.GetScalingEvent(first.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*state.ScalingEvent{"example:cache": expectedEvent(first)}, event)
+
+	events, err := b.GetScalingEvents(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[uuid.UUID]map[string]*state.ScalingEvent{
+		first.ID:  {"example:cache": expectedEvent(first)},
+		second.ID: {"example:cache": expectedEvent(second)},
+	}, events)
+}
+
+func testMultiGroupScalingEvent(t *testing.T, b scale.Backend) {
+	cache := newEventMessage("cache", time.Now().UnixNano())
+	proxy := newEventMessage("proxy", cache.Time)
+	proxy.ID = cache.ID
+
+	assert.Nil(t, b.PutScalingEvent("example", cache))
+	assert.Nil(t, b.PutScalingEvent("example", proxy))
+
+	expected := map[string]*state.ScalingEvent{
+		"example:cache": expectedEvent(cache),
+		"example:proxy": expectedEvent(proxy),
+	}
+
+	event, err := b.GetScalingEvent(cache.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, expected, event)
+
+	events, err := b.GetScalingEvents(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[uuid.UUID]map[string]*state.ScalingEvent{cache.ID: expected}, events)
+}
+
+func testFilteredScalingEvents(t *testing.T, b scale.Backend) {
+	now := time.Now().UnixNano()
+
+	older := newEventMessage("cache", now-int64(time.Hour))
+	cache := newEventMessage("cache", now)
+	proxy := newEventMessage("proxy", now)
+	proxy.ID = cache.ID
+	proxy.Direction = "in"
+	other := newEventMessage("cache", now)
+
+	assert.Nil(t, b.PutScalingEvent("example", older))
+	assert.Nil(t, b.PutScalingEvent("example", cache))
+	assert.Nil(t, b.PutScalingEvent("example", proxy))
+	assert.Nil(t, b.PutScalingEvent("example_2", other))
+
+	testCases := []struct {
+		filter   *state.EventFilter
+		expected map[uuid.UUID]map[string]*state.ScalingEvent
+		name     string
+	}{
+		{
+			filter: &state.EventFilter{Job: "example"},
+			expected: map[uuid.UUID]map[string]*state.ScalingEvent{
+				older.ID: {"example:cache": expectedEvent(older)},
+				cache.ID: {"example:cache": expectedEvent(cache), "example:proxy": expectedEvent(proxy)},
+			},
+			name: "job",
+		},
+		{
+			filter: &state.EventFilter{Job: "example", Group: "proxy"},
+			expected: map[uuid.UUID]map[string]*state.ScalingEvent{
+				cache.ID: {"example:proxy": expectedEvent(proxy)},
+			},
+			name: "job group",
+		},
+		{
+			filter: &state.EventFilter{Group: "cache", From: now - int64(time.Minute)},
+			expected: map[uuid.UUID]map[string]*state.ScalingEvent{
+				cache.ID: {"example:cache": expectedEvent(cache)},
+				other.ID: {"example_2:cache": expectedEvent(other)},
+			},
+			name: "group and from",
+		},
+		{
+			filter: &state.EventFilter{To: now - int64(time.Minute)},
+			expected: map[uuid.UUID]map[string]*state.ScalingEvent{
+				older.ID: {"example:cache": expectedEvent(older)},
+			},
+			name: "to",
+		},
+		{
+			filter: &state.EventFilter{Direction: "in"},
+			expected: map[uuid.UUID]map[string]*state.ScalingEvent{
+				cache.ID: {"example:proxy": expectedEvent(proxy)},
+			},
+			name: "direction",
+		},
+		{
+			filter:   &state.EventFilter{Job: "missing"},
+			expected: map[uuid.UUID]map[string]*state.ScalingEvent{},
+			name:     "no matches",
+		},
+	}
+
+	for _, tc := range testCases {
+		events, err := b.GetScalingEvents(tc.filter)
+		assert.Nil(t, err, tc.name)
+		assert.Equal(t, tc.expected, events, tc.name)
+	}
+}
+
+func testLatestScalingEvents(t *testing.T, b scale.Backend) {
+	now := time.Now().UnixNano()
+
+	older := newEventMessage("cache", now-int64(time.Minute))
+	newer := newEventMessage("cache", now)
+	proxy := newEventMessage("proxy", now)
+
+	assert.Nil(t, b.PutScalingEvent("example", older))
+	assert.Nil(t, b.PutScalingEvent("example", newer))
+	assert.Nil(t, b.PutScalingEvent("example", proxy))
+
+	latest, err := b.GetLatestScalingEvent("example", "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, expectedEvent(newer), latest)
+
+	latestEvents, err := b.GetLatestScalingEvents()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*state.ScalingEvent{
+		"example:cache": expectedEvent(newer),
+		"example:proxy": expectedEvent(proxy),
+	}, latestEvents)
+}
+
+func testScalingEventDeployment(t *testing.T, b scale.Backend) {
+	now := time.Now().UnixNano()
+
+	older := newEventMessage("cache", now-int64(time.Minute))
+	newer := newEventMessage("cache", now)
+	proxy := newEventMessage("proxy", now)
+
+	assert.Nil(t, b.PutScalingEvent("example", older))
+	assert.Nil(t, b.PutScalingEvent("example", newer))
+	assert.Nil(t, b.PutScalingEvent("example", proxy))
+
+	// Updating an event which is not the latest event of the group should not modify the latest
+	// event.
+	assert.Nil(t, b.PutScalingEventDeployment(older.ID, "example", "cache", "d2b8cc34"))
+	assert.Nil(t, b.PutScalingEventDeployment(proxy.ID, "example", "proxy", "5f6c9a1e"))
+
+	// Updating an event which is not held within the state is not an error.
+	assert.Nil(t, b.PutScalingEventDeployment(uuid.Must(uuid.NewV4()), "example", "cache", "a41e0c7b"))
+
+	expectedOlder := expectedEvent(older)
+	expectedOlder.DeploymentID = "d2b8cc34"
+	expectedProxy := expectedEvent(proxy)
+	expectedProxy.DeploymentID = "5f6c9a1e"
+
+	event, err := b.GetScalingEvent(older.ID)
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*state.ScalingEvent{"example:cache": expectedOlder}, event)
+
+	latestEvents, err := b.GetLatestScalingEvents()
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]*state.ScalingEvent{
+		"example:cache": expectedEvent(newer),
+		"example:proxy": expectedProxy,
+	}, latestEvents)
+}
+
+func testCooldowns(t *testing.T, b scale.Backend) {
+	now := time.Now().UnixNano()
+
+	assert.Nil(t, b.PutCooldown("example", "cache", now-int64(time.Minute)))
+	assert.Nil(t, b.PutCooldown("example", "cache", now))
+
+	cooldown, err := b.GetCooldown("example", "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, now, cooldown)
+
+	cooldown, err = b.GetCooldown("example", "proxy")
+	assert.Nil(t, err)
+	assert.Equal(t, int64(0), cooldown)
+}
+
+func testGarbageCollection(t *testing.T, b scale.Backend) {
+	now := time.Now().UnixNano()
+
+	stale := newEventMessage("cache", now-(scale.GarbageCollectionThreshold*2))
+	current := newEventMessage("proxy", now)
+
+	assert.Nil(t, b.PutScalingEvent("example", stale))
+	assert.Nil(t, b.PutScalingEvent("example", current))
+	assert.Nil(t, b.PutCooldown("example", "cache", stale.Time))
+
+	b.RunGarbageCollection()
+
+	event, err := b.GetScalingEvent(stale.ID)
+	assert.Nil(t, err)
+	assert.Len(t, event, 0)
+
+	events, err := b.GetScalingEvents(nil)
+	assert.Nil(t, err)
+	assert.Equal(t, map[uuid.UUID]map[string]*state.ScalingEvent{
+		current.ID: {"example:proxy": expectedEvent(current)},
+	}, events)
+
+	// The latest events and cooldowns are not subject to garbage collection.
+	latest, err := b.GetLatestScalingEvent("example", "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, expectedEvent(stale), latest)
+
+	cooldown, err := b.GetCooldown("example", "cache")
+	assert.Nil(t, err)
+	assert.Equal(t, stale.Time, cooldown)
+}
+
+func newEventMessage(group string, t int64) *state.ScalingEventMessage {
+	id := uuid.Must(uuid.NewV4())
+
+	return &state.ScalingEventMessage{
+		ID:           id,
+		GroupName:    group,
+		EvalID:       id.String(),
+		Source:       state.SourceAPI,
+		Time:         t,
+		Status:       state.StatusCompleted,
+		Count:        1,
+		DesiredCount: 3,
+		Direction:    "out",
+		Reason:       state.ReasonManual,
+		Meta:         map[string]string{"metric": "cpu"},
+		RunbookURL:   "https://wiki.jrasell.system/runbooks/" + group,
+		Snapshot: &state.ResourceSnapshot{
+			Nomad:    &state.NomadResourceSnapshot{CPU: 82.5, Memory: 40, StatsCoverage: 100},
+			External: map[string]float64{"queue": 120},
+		},
+	}
+}
+
+// expectedEvent is the stored representation of the scaling event message.
+func expectedEvent(msg *state.ScalingEventMessage) *state.ScalingEvent {
+	return &state.ScalingEvent{
+		ID:         msg.ID,
+		EvalID:     msg.EvalID,
+		Source:     msg.Source,
+		Time:       msg.Time,
+		Status:     msg.Status,
+		Details:    state.EventDetails{Count: msg.Count, Direction: msg.Direction, DesiredCount: msg.DesiredCount},
+		Reason:     msg.Reason,
+		Meta:       msg.Meta,
+		RunbookURL: msg.RunbookURL,
+		Notes:      msg.Notes,
+		Snapshot:   msg.Snapshot,
+	}
+}
@@ -1,14 +1,17 @@
 package consul
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
 	"github.com/armon/go-metrics"
 	"github.com/gofrs/uuid"
 	"github.com/hashicorp/consul/api"
+	"github.com/jrasell/sherpa/pkg/retry"
 	"github.com/jrasell/sherpa/pkg/state"
 	"github.com/jrasell/sherpa/pkg/state/scale"
 	"github.com/pkg/errors"
@@ -21,6 +24,7 @@ const (
 	baseKVPath         = "state/"
 	eventsKVPath       = "state/events/"
 	latestEventsKVPath = "state/latest-events/"
+	cooldownsKVPath    = "state/cooldowns/"
 )
 
 // Define our metric keys.
@@ -30,13 +34,17 @@ var (
 	metricKeyGetLatestEvents = []string{"scale", "state", "consul", "get_latest_events"}
 	metricKeyGetLatestEvent  = []string{"scale", "state", "consul", "get_latest_event"}
 	metricKeyPutEvent        = []string{"scale", "state", "consul", "put_event"}
+	metricKeyPutDeployment   = []string{"scale", "state", "consul", "put_deployment"}
 	metricKeyGC              = []string{"scale", "state", "consul", "gc"}
+	metricKeyGetCooldown     = []string{"scale", "state", "consul", "get_cooldown"}
+	metricKeyPutCooldown     = []string{"scale", "state", "consul", "put_cooldown"}
 )
 
 type StateBackend struct {
 	basePath         string
 	eventsPath       string
 	latestEventsPath string
+	cooldownsPath    string
 	gcThreshold      int64
 	logger           zerolog.Logger
 
@@ -48,6 +56,7 @@ func NewStateBackend(log zerolog.Logger, path string, client *api.Client) scale.
 		basePath:         path + baseKVPath,
 		eventsPath:       path + eventsKVPath,
 		latestEventsPath: path + latestEventsKVPath,
+		cooldownsPath:    path + cooldownsKVPath,
 		gcThreshold:      scale.GarbageCollectionThreshold,
 		logger:           log,
 		kv:               client.KV(),
@@ -101,7 +110,7 @@ func (s StateBackend) GetLatestScalingEvent(job, group string) (*state.ScalingEv
 	return &out, nil
 }
 
-func (s StateBackend) GetScalingEvents() (map[uuid.UUID]map[string]*state.ScalingEvent, error) {
+func (s StateBackend) GetScalingEvents(filter *state.EventFilter) (map[uuid.UUID]map[string]*state.ScalingEvent, error) {
 	defer metrics.MeasureSince(metricKeyGetEvents, time.Now())
 
 	kv, _, err := s.kv.List(s.eventsPath, nil)
@@ -123,13 +132,25 @@ func (s StateBackend) GetScalingEvents() (map[uuid.UUID]map[string]*state.Scalin
 		}
 
 		keySplit := strings.Split(kv[i].Key, "/")
+		key := keySplit[len(keySplit)-1]
+
+		// Consul KV cannot query by the event fields, so the filter is applied as the events
+		// are read.
+		if !filter.Match(key, keyState) {
+			continue
+		}
 
 		id, err := uuid.FromString(keySplit[len(keySplit)-2])
 		if err != nil {
 			return nil, errors.Wrap(err, "failed to get UUID from string")
 		}
 
-		out[id] = map[string]*state.ScalingEvent{keySplit[len(keySplit)-1]: keyState}
+		// A single scaling event can include multiple groups of the job, each of which is
+		// stored under the event ID.
+		if _, ok := out[id]; !ok {
+			out[id] = make(map[string]*state.ScalingEvent)
+		}
+		out[id][key] = keyState
 	}
 
 	return out, nil
@@ -166,13 +187,17 @@ func (s StateBackend) PutScalingEvent(job string, event *state.ScalingEventMessa
 	defer metrics.MeasureSince(metricKeyPutEvent, time.Now())
 
 	sEntry := &state.ScalingEvent{
-		ID:      event.ID,
-		EvalID:  event.EvalID,
-		Source:  event.Source,
-		Time:    event.Time,
-		Status:  event.Status,
-		Details: state.EventDetails{Count: event.Count, Direction: event.Direction},
-		Meta:    event.Meta,
+		ID:         event.ID,
+		EvalID:     event.EvalID,
+		Source:     event.Source,
+		Time:       event.Time,
+		Status:     event.Status,
+		Details:    state.EventDetails{Count: event.Count, Direction: event.Direction, DesiredCount: event.DesiredCount},
+		Reason:     event.Reason,
+		Meta:       event.Meta,
+		RunbookURL: event.RunbookURL,
+		Notes:      event.Notes,
+		Snapshot:   event.Snapshot,
 	}
 
 	marshal, err := json.Marshal(sEntry)
@@ -185,7 +210,7 @@ func (s StateBackend) PutScalingEvent(job string, event *state.ScalingEventMessa
 		Key:   fmt.Sprintf("%s%s/%s:%s", s.eventsPath, event.ID.String(), job, event.GroupName),
 		Value: marshal,
 	}
-	if _, err = s.kv.Put(ePair, nil); err != nil {
+	if err := s.put(ePair); err != nil {
 		return err
 	}
 
@@ -194,10 +219,91 @@ func (s StateBackend) PutScalingEvent(job string, event *state.ScalingEventMessa
 		Key:   fmt.Sprintf("%s%s:%s", s.latestEventsPath, job, event.GroupName),
 		Value: marshal,
 	}
-	_, err = s.kv.Put(lePair, nil)
+	return s.put(lePair)
+}
+
+func (s StateBackend) PutScalingEventDeployment(id uuid.UUID, job, group, deploymentID string) error {
+	defer metrics.MeasureSince(metricKeyPutDeployment, time.Now())
+
+	eKey := fmt.Sprintf("%s%s/%s:%s", s.eventsPath, id.String(), job, group)
+
+	found, err := s.updateDeploymentID(eKey, id, deploymentID)
+	if err != nil || !found {
+		return err
+	}
+
+	// The latest event of the group is only updated if no newer scaling event has since been
+	// written.
+	_, err = s.updateDeploymentID(fmt.Sprintf("%s%s:%s", s.latestEventsPath, job, group), id, deploymentID)
 	return err
 }
 
+// updateDeploymentID sets the deployment ID of the scaling event stored at the key, if the stored
+// event has the passed ID. The returned bool indicates whether the key held the event.
+func (s StateBackend) updateDeploymentID(key string, id uuid.UUID, deploymentID string) (bool, error) {
+	kv, _, err := s.kv.Get(key, nil)
+	if err != nil {
+		return false, err
+	}
+
+	if kv == nil {
+		return false, nil
+	}
+
+	event := state.ScalingEvent{}
+	if err := json.Unmarshal(kv.Value, &event); err != nil {
+		return false, errors.Wrap(err, "failed to unmarshal Consul KV value")
+	}
+
+	if event.ID != id {
+		return false, nil
+	}
+	event.DeploymentID = deploymentID
+
+	marshal, err := json.Marshal(event)
+	if err != nil {
+		return false, err
+	}
+	return true, s.put(&api.KVPair{Key: key, Value: marshal})
+}
+
+func (s StateBackend) GetCooldown(job, group string) (int64, error) {
+	defer metrics.MeasureSince(metricKeyGetCooldown, time.Now())
+
+	kv, _, err := s.kv.Get(s.cooldownsPath+job+":"+group, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if kv == nil {
+		return 0, nil
+	}
+
+	t, err := strconv.ParseInt(string(kv.Value), 10, 64)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to parse Consul KV cooldown value")
+	}
+	return t, nil
+}
+
+func (s StateBackend) PutCooldown(job, group string, t int64) error {
+	defer metrics.MeasureSince(metricKeyPutCooldown, time.Now())
+
+	return s.put(&api.KVPair{
+		Key:   s.cooldownsPath + job + ":" + group,
+		Value: []byte(strconv.FormatInt(t, 10)),
+	})
+}
+
+// put writes the KV pair, retrying failed writes so that scaling events are not lost due to a
+// transient Consul error. KV puts are idempotent, so retrying is always safe.
+func (s StateBackend) put(pair *api.KVPair) error {
+	return retry.Do(context.Background(), retry.TargetConsul, retry.Default, func() error {
+		_, err := s.kv.Put(pair, nil)
+		return err
+	})
+}
+
 func (s StateBackend) RunGarbageCollection() {
 	t := time.Now()
 	defer metrics.MeasureSince(metricKeyGC, t)
@@ -0,0 +1,29 @@
+package consul
+
+import (
+	"os"
+	"testing"
+
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/client"
+	"github.com/jrasell/sherpa/pkg/state/scale"
+	"github.com/jrasell/sherpa/pkg/state/scale/conformance"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStateBackend_conformance runs the state backend conformance tests against a Consul agent.
+// It is skipped unless the CONSUL_HTTP_ADDR environment variable is set.
+func TestStateBackend_conformance(t *testing.T) {
+	if os.Getenv("CONSUL_HTTP_ADDR") == "" {
+		t.Skip("CONSUL_HTTP_ADDR not set, skipping Consul state backend tests")
+	}
+
+	consul, err := client.NewConsulClient()
+	assert.Nil(t, err)
+
+	conformance.Run(t, func() (scale.Backend, func()) {
+		path := "sherpa-test/" + uuid.Must(uuid.NewV4()).String() + "/"
+		return NewStateBackend(zerolog.Nop(), path, consul), func() { _, _ = consul.KV().DeleteTree(path, nil) }
+	})
+}
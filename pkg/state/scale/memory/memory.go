@@ -19,7 +19,10 @@ var (
 	metricKeyGetLatestEvents = []string{"scale", "state", "memory", "get_latest_events"}
 	metricKeyGetLatestEvent  = []string{"scale", "state", "memory", "get_latest_event"}
 	metricKeyPutEvent        = []string{"scale", "state", "memory", "put_event"}
+	metricKeyPutDeployment   = []string{"scale", "state", "memory", "put_deployment"}
 	metricKeyGC              = []string{"scale", "state", "memory", "gc"}
+	metricKeyGetCooldown     = []string{"scale", "state", "memory", "get_cooldown"}
+	metricKeyPutCooldown     = []string{"scale", "state", "memory", "put_cooldown"}
 )
 
 type StateBackend struct {
@@ -34,6 +37,7 @@ func NewStateBackend() scale.Backend {
 		state: &state.ScalingState{
 			Events:       make(map[uuid.UUID]map[string]*state.ScalingEvent),
 			LatestEvents: make(map[string]*state.ScalingEvent),
+			Cooldowns:    make(map[string]int64),
 		},
 	}
 }
@@ -56,11 +60,11 @@ func (s *StateBackend) GetLatestScalingEvent(job, group string) (*state.ScalingE
 	return latest, nil
 }
 
-func (s *StateBackend) GetScalingEvents() (map[uuid.UUID]map[string]*state.ScalingEvent, error) {
+func (s *StateBackend) GetScalingEvents(filter *state.EventFilter) (map[uuid.UUID]map[string]*state.ScalingEvent, error) {
 	defer metrics.MeasureSince(metricKeyGetEvents, time.Now())
 
 	s.RLock()
-	events := s.state.Events
+	events := filter.Filter(s.state.Events)
 	s.RUnlock()
 	return events, nil
 }
@@ -74,22 +78,55 @@ func (s *StateBackend) PutScalingEvent(job string, event *state.ScalingEventMess
 	k := job + ":" + event.GroupName
 
 	sEntry := &state.ScalingEvent{
-		ID:      event.ID,
-		EvalID:  event.EvalID,
-		Source:  event.Source,
-		Time:    event.Time,
-		Status:  event.Status,
-		Details: state.EventDetails{Count: event.Count, Direction: event.Direction},
-		Meta:    event.Meta,
+		ID:         event.ID,
+		EvalID:     event.EvalID,
+		Source:     event.Source,
+		Time:       event.Time,
+		Status:     event.Status,
+		Details:    state.EventDetails{Count: event.Count, Direction: event.Direction, DesiredCount: event.DesiredCount},
+		Reason:     event.Reason,
+		Meta:       event.Meta,
+		RunbookURL: event.RunbookURL,
+		Notes:      event.Notes,
+		Snapshot:   event.Snapshot,
 	}
 
-	s.state.Events[event.ID] = make(map[string]*state.ScalingEvent)
+	// A single scaling event can include multiple groups of the job, each of which is written
+	// separately using the same ID.
+	if _, ok := s.state.Events[event.ID]; !ok {
+		s.state.Events[event.ID] = make(map[string]*state.ScalingEvent)
+	}
 	s.state.Events[event.ID][k] = sEntry
 	s.state.LatestEvents[k] = sEntry
 
 	return nil
 }
 
+func (s *StateBackend) PutScalingEventDeployment(id uuid.UUID, job, group, deploymentID string) error {
+	defer metrics.MeasureSince(metricKeyPutDeployment, time.Now())
+
+	s.Lock()
+	defer s.Unlock()
+
+	k := job + ":" + group
+
+	event, ok := s.state.Events[id][k]
+	if !ok {
+		return nil
+	}
+
+	// Replace the stored event with an updated copy, rather than modifying it in place, as callers
+	// may hold references to the stored event.
+	updated := *event
+	updated.DeploymentID = deploymentID
+	s.state.Events[id][k] = &updated
+
+	if latest, ok := s.state.LatestEvents[k]; ok && latest.ID == id {
+		s.state.LatestEvents[k] = &updated
+	}
+	return nil
+}
+
 func (s *StateBackend) GetScalingEvent(id uuid.UUID) (map[string]*state.ScalingEvent, error) {
 	defer metrics.MeasureSince(metricKeyGetEvent, time.Now())
 
@@ -99,6 +136,24 @@ func (s *StateBackend) GetScalingEvent(id uuid.UUID) (map[string]*state.ScalingE
 	return e, nil
 }
 
+func (s *StateBackend) GetCooldown(job, group string) (int64, error) {
+	defer metrics.MeasureSince(metricKeyGetCooldown, time.Now())
+
+	s.RLock()
+	t := s.state.Cooldowns[job+":"+group]
+	s.RUnlock()
+	return t, nil
+}
+
+func (s *StateBackend) PutCooldown(job, group string, t int64) error {
+	defer metrics.MeasureSince(metricKeyPutCooldown, time.Now())
+
+	s.Lock()
+	s.state.Cooldowns[job+":"+group] = t
+	s.Unlock()
+	return nil
+}
+
 func (s *StateBackend) RunGarbageCollection() {
 	t := time.Now()
 	defer metrics.MeasureSince(metricKeyGC, t)
@@ -115,7 +170,9 @@ func (s *StateBackend) RunGarbageCollection() {
 	for id, jgEvent := range s.state.Events {
 		for name, event := range jgEvent {
 			if event.Time > gc {
-				newEventState[id] = make(map[string]*state.ScalingEvent)
+				if _, ok := newEventState[id]; !ok {
+					newEventState[id] = make(map[string]*state.ScalingEvent)
+				}
 				newEventState[id][name] = event
 			}
 		}
@@ -7,9 +7,14 @@ import (
 	"github.com/gofrs/uuid"
 	"github.com/jrasell/sherpa/pkg/state"
 	"github.com/jrasell/sherpa/pkg/state/scale"
+	"github.com/jrasell/sherpa/pkg/state/scale/conformance"
 	"github.com/stretchr/testify/assert"
 )
 
+func TestStateBackend_conformance(t *testing.T) {
+	conformance.Run(t, func() (scale.Backend, func()) { return NewStateBackend(), func() {} })
+}
+
 func Test_MemoryStateBackend(t *testing.T) {
 	newBackend := NewStateBackend()
 
@@ -50,7 +55,7 @@ func Test_MemoryStateBackend(t *testing.T) {
 	assert.Equal(t, expectedEvent2, actualEvent2)
 
 	// Attempt to read out entire state out.
-	actualStateRead1, err := newBackend.GetScalingEvents()
+	actualStateRead1, err := newBackend.GetScalingEvents(nil)
 
 	// Check the expected results of reading back the whole state.
 	expectedStateRead1 := map[uuid.UUID]map[string]*state.ScalingEvent{
@@ -88,7 +93,7 @@ func Test_MemoryStateBackend(t *testing.T) {
 	assert.Nil(t, gcEvent1)
 
 	// Attempt to read out entire state out.
-	actualStateRead2, err := newBackend.GetScalingEvents()
+	actualStateRead2, err := newBackend.GetScalingEvents(nil)
 
 	// Check the expected results of reading back the whole state.
 	expectedStateRead2 := map[uuid.UUID]map[string]*state.ScalingEvent{
@@ -0,0 +1,350 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/armon/go-metrics"
+	"github.com/gofrs/uuid"
+	"github.com/jrasell/sherpa/pkg/postgres"
+	"github.com/jrasell/sherpa/pkg/retry"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/jrasell/sherpa/pkg/state/scale"
+	"github.com/pkg/errors"
+	"github.com/rs/zerolog"
+)
+
+var _ scale.Backend = (*StateBackend)(nil)
+
+// Define our metric keys.
+var (
+	metricKeyGetEvents       = []string{"scale", "state", "postgres", "get_events"}
+	metricKeyGetEvent        = []string{"scale", "state", "postgres", "get_event"}
+	metricKeyGetLatestEvents = []string{"scale", "state", "postgres", "get_latest_events"}
+	metricKeyGetLatestEvent  = []string{"scale", "state", "postgres", "get_latest_event"}
+	metricKeyPutEvent        = []string{"scale", "state", "postgres", "put_event"}
+	metricKeyPutDeployment   = []string{"scale", "state", "postgres", "put_deployment"}
+	metricKeyGC              = []string{"scale", "state", "postgres", "gc"}
+	metricKeyGetCooldown     = []string{"scale", "state", "postgres", "get_cooldown"}
+	metricKeyPutCooldown     = []string{"scale", "state", "postgres", "put_cooldown"}
+)
+
+// StateBackend stores the scaling state within the tables of a PostgreSQL database, which must
+// have been migrated using the postgres package. Events are keyed by the scaling event ID and the
+// job-name:group-name key used by the other backends.
+type StateBackend struct {
+	db          *sql.DB
+	gcThreshold int64
+	logger      zerolog.Logger
+}
+
+func NewStateBackend(log zerolog.Logger, db *sql.DB) scale.Backend {
+	return &StateBackend{
+		db:          db,
+		gcThreshold: scale.GarbageCollectionThreshold,
+		logger:      log,
+	}
+}
+
+func (s *StateBackend) GetLatestScalingEvents() (map[string]*state.ScalingEvent, error) {
+	defer metrics.MeasureSince(metricKeyGetLatestEvents, time.Now())
+
+	rows, err := s.db.Query(`SELECT job_group, event FROM sherpa_latest_scaling_events`)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query latest scaling events")
+	}
+	defer rows.Close()
+
+	out := make(map[string]*state.ScalingEvent)
+
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, errors.Wrap(err, "failed to scan latest scaling event")
+		}
+
+		event := &state.ScalingEvent{}
+		if err := json.Unmarshal([]byte(value), event); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal PostgreSQL scaling event")
+		}
+		out[key] = event
+	}
+	return out, rows.Err()
+}
+
+func (s *StateBackend) GetLatestScalingEvent(job, group string) (*state.ScalingEvent, error) {
+	defer metrics.MeasureSince(metricKeyGetLatestEvent, time.Now())
+
+	var value string
+
+	err := s.db.QueryRow(`SELECT event FROM sherpa_latest_scaling_events WHERE job_group = $1`, job+":"+group).Scan(&value)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query latest scaling event")
+	}
+
+	out := state.ScalingEvent{}
+	if err := json.Unmarshal([]byte(value), &out); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal PostgreSQL scaling event")
+	}
+	return &out, nil
+}
+
+func (s *StateBackend) GetScalingEvents(filter *state.EventFilter) (map[uuid.UUID]map[string]*state.ScalingEvent, error) {
+	defer metrics.MeasureSince(metricKeyGetEvents, time.Now())
+
+	query, args := scalingEventsQuery(filter)
+
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query scaling events")
+	}
+	defer rows.Close()
+
+	out := make(map[uuid.UUID]map[string]*state.ScalingEvent)
+
+	for rows.Next() {
+		var rawID, key, value string
+		if err := rows.Scan(&rawID, &key, &value); err != nil {
+			return nil, errors.Wrap(err, "failed to scan scaling event")
+		}
+
+		id, err := uuid.FromString(rawID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to get UUID from string")
+		}
+
+		event := &state.ScalingEvent{}
+		if err := json.Unmarshal([]byte(value), event); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal PostgreSQL scaling event")
+		}
+
+		// The direction and outcome are only held within the encoded event, so are filtered
+		// once the event has been read.
+		if !filter.Match(key, event) {
+			continue
+		}
+
+		// A single scaling event can include multiple groups of the job, each of which is
+		// stored as a row using the event ID.
+		if _, ok := out[id]; !ok {
+			out[id] = make(map[string]*state.ScalingEvent)
+		}
+		out[id][key] = event
+	}
+	return out, rows.Err()
+}
+
+// scalingEventsQuery builds the query which reads the scaling events passing the time range and
+// job group of the filter, so that the database indexes are used to limit the rows read.
+func scalingEventsQuery(filter *state.EventFilter) (string, []interface{}) {
+	var (
+		conds []string
+		args  []interface{}
+	)
+
+	where := func(cond string, arg interface{}) {
+		args = append(args, arg)
+		conds = append(conds, fmt.Sprintf(cond, len(args)))
+	}
+
+	if filter != nil {
+		if filter.From != 0 {
+			where("time >= $%d", filter.From)
+		}
+		if filter.To != 0 {
+			where("time <= $%d", filter.To)
+		}
+
+		switch {
+		case filter.Job != "" && filter.Group != "":
+			where("job_group = $%d", filter.Job+":"+filter.Group)
+		case filter.Job != "":
+			where("job_group LIKE $%d", likeEscaper.Replace(filter.Job)+":%")
+		}
+	}
+
+	query := `SELECT id, job_group, event FROM sherpa_scaling_events`
+	if len(conds) > 0 {
+		query += ` WHERE ` + strings.Join(conds, ` AND `)
+	}
+	return query, args
+}
+
+// likeEscaper escapes the LIKE pattern characters of a value, so that it is matched literally.
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+func (s *StateBackend) GetScalingEvent(id uuid.UUID) (map[string]*state.ScalingEvent, error) {
+	defer metrics.MeasureSince(metricKeyGetEvent, time.Now())
+
+	rows, err := s.db.Query(`SELECT job_group, event FROM sherpa_scaling_events WHERE id = $1`, id.String())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query scaling event")
+	}
+	defer rows.Close()
+
+	out := make(map[string]*state.ScalingEvent)
+
+	for rows.Next() {
+		var key, value string
+		if err := rows.Scan(&key, &value); err != nil {
+			return nil, errors.Wrap(err, "failed to scan scaling event")
+		}
+
+		event := &state.ScalingEvent{}
+		if err := json.Unmarshal([]byte(value), event); err != nil {
+			return nil, errors.Wrap(err, "failed to unmarshal PostgreSQL scaling event")
+		}
+		out[key] = event
+	}
+	return out, rows.Err()
+}
+
+func (s *StateBackend) PutScalingEvent(job string, event *state.ScalingEventMessage) error {
+	defer metrics.MeasureSince(metricKeyPutEvent, time.Now())
+
+	sEntry := &state.ScalingEvent{
+		ID:         event.ID,
+		EvalID:     event.EvalID,
+		Source:     event.Source,
+		Time:       event.Time,
+		Status:     event.Status,
+		Details:    state.EventDetails{Count: event.Count, Direction: event.Direction, DesiredCount: event.DesiredCount},
+		Reason:     event.Reason,
+		Meta:       event.Meta,
+		RunbookURL: event.RunbookURL,
+		Notes:      event.Notes,
+		Snapshot:   event.Snapshot,
+	}
+
+	marshal, err := json.Marshal(sEntry)
+	if err != nil {
+		return err
+	}
+	key := job + ":" + event.GroupName
+
+	// The event and the latest event of the group are written in a single transaction, which is
+	// retried so that scaling events are not lost due to a transient error. The writes are
+	// upserts, so retrying is always safe.
+	return s.retry(func(ctx context.Context) error {
+		return postgres.WithTx(ctx, s.db, func(tx *sql.Tx) error {
+			_, err := tx.ExecContext(ctx, `INSERT INTO sherpa_scaling_events (id, job_group, time, event) VALUES ($1, $2, $3, $4)
+				ON CONFLICT (id, job_group) DO UPDATE SET time = EXCLUDED.time, event = EXCLUDED.event`,
+				event.ID.String(), key, event.Time, string(marshal))
+			if err != nil {
+				return errors.Wrap(err, "failed to write scaling event")
+			}
+
+			_, err = tx.ExecContext(ctx, `INSERT INTO sherpa_latest_scaling_events (job_group, id, event) VALUES ($1, $2, $3)
+				ON CONFLICT (job_group) DO UPDATE SET id = EXCLUDED.id, event = EXCLUDED.event`,
+				key, event.ID.String(), string(marshal))
+			if err != nil {
+				return errors.Wrap(err, "failed to write latest scaling event")
+			}
+			return nil
+		})
+	})
+}
+
+func (s *StateBackend) PutScalingEventDeployment(id uuid.UUID, job, group, deploymentID string) error {
+	defer metrics.MeasureSince(metricKeyPutDeployment, time.Now())
+
+	key := job + ":" + group
+	ctx := context.Background()
+
+	return postgres.WithTx(ctx, s.db, func(tx *sql.Tx) error {
+		var value string
+
+		err := tx.QueryRowContext(ctx, `SELECT event FROM sherpa_scaling_events WHERE id = $1 AND job_group = $2 FOR UPDATE`,
+			id.String(), key).Scan(&value)
+		if err == sql.ErrNoRows {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrap(err, "failed to query scaling event")
+		}
+
+		event := state.ScalingEvent{}
+		if err := json.Unmarshal([]byte(value), &event); err != nil {
+			return errors.Wrap(err, "failed to unmarshal PostgreSQL scaling event")
+		}
+		event.DeploymentID = deploymentID
+
+		marshal, err := json.Marshal(event)
+		if err != nil {
+			return err
+		}
+
+		if _, err := tx.ExecContext(ctx, `UPDATE sherpa_scaling_events SET event = $3 WHERE id = $1 AND job_group = $2`,
+			id.String(), key, string(marshal)); err != nil {
+			return errors.Wrap(err, "failed to update scaling event")
+		}
+
+		// The latest event of the group is only updated if no newer scaling event has since been
+		// written.
+		if _, err := tx.ExecContext(ctx, `UPDATE sherpa_latest_scaling_events SET event = $3 WHERE job_group = $1 AND id = $2`,
+			key, id.String(), string(marshal)); err != nil {
+			return errors.Wrap(err, "failed to update latest scaling event")
+		}
+		return nil
+	})
+}
+
+func (s *StateBackend) GetCooldown(job, group string) (int64, error) {
+	defer metrics.MeasureSince(metricKeyGetCooldown, time.Now())
+
+	var t int64
+
+	err := s.db.QueryRow(`SELECT time FROM sherpa_cooldowns WHERE job_group = $1`, job+":"+group).Scan(&t)
+	if err == sql.ErrNoRows {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to query cooldown")
+	}
+	return t, nil
+}
+
+func (s *StateBackend) PutCooldown(job, group string, t int64) error {
+	defer metrics.MeasureSince(metricKeyPutCooldown, time.Now())
+
+	return s.retry(func(ctx context.Context) error {
+		_, err := s.db.ExecContext(ctx, `INSERT INTO sherpa_cooldowns (job_group, time) VALUES ($1, $2)
+			ON CONFLICT (job_group) DO UPDATE SET time = EXCLUDED.time`, job+":"+group, t)
+		if err != nil {
+			return errors.Wrap(err, "failed to write cooldown")
+		}
+		return nil
+	})
+}
+
+// retry performs the write, retrying failures so that state is not lost due to a transient
+// database error.
+func (s *StateBackend) retry(fn func(context.Context) error) error {
+	ctx := context.Background()
+	return retry.Do(ctx, retry.TargetPostgres, retry.Default, func() error { return fn(ctx) })
+}
+
+func (s *StateBackend) RunGarbageCollection() {
+	t := time.Now()
+	defer metrics.MeasureSince(metricKeyGC, t)
+
+	gc := t.UTC().UnixNano() - s.gcThreshold
+
+	// As with the other backends, the latest events and cooldowns are not garbage collected.
+	res, err := s.db.Exec(`DELETE FROM sherpa_scaling_events WHERE time < $1`, gc)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("GC failed to delete stale events in backend store")
+		return
+	}
+
+	if n, err := res.RowsAffected(); err == nil && n > 0 {
+		s.logger.Debug().Int64("count", n).Msg("GC deleted stale events in backend store")
+	}
+}
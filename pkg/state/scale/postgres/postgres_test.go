@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/jrasell/sherpa/pkg/postgres/postgrestest"
+	"github.com/jrasell/sherpa/pkg/state"
+	"github.com/jrasell/sherpa/pkg/state/scale"
+	"github.com/jrasell/sherpa/pkg/state/scale/conformance"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/assert"
+)
+
+// TestStateBackend_conformance runs the state backend conformance tests against a PostgreSQL
+// database. It is skipped unless the SHERPA_TEST_POSTGRES_URL environment variable is set.
+func TestStateBackend_conformance(t *testing.T) {
+	postgrestest.SkipUnlessEnabled(t)
+
+	conformance.Run(t, func() (scale.Backend, func()) {
+		db, cleanup := postgrestest.NewDB(t)
+		return NewStateBackend(zerolog.Nop(), db), cleanup
+	})
+}
+
+func Test_scalingEventsQuery(t *testing.T) {
+	testCases := []struct {
+		filter        *state.EventFilter
+		expectedQuery string
+		expectedArgs  []interface{}
+		name          string
+	}{
+		{
+			filter:        nil,
+			expectedQuery: `SELECT id, job_group, event FROM sherpa_scaling_events`,
+			name:          "nil filter",
+		},
+		{
+			filter:        &state.EventFilter{Direction: "in"},
+			expectedQuery: `SELECT id, job_group, event FROM sherpa_scaling_events`,
+			name:          "event field filter",
+		},
+		{
+			filter:        &state.EventFilter{Job: "example", Group: "cache", From: 10, To: 20},
+			expectedQuery: `SELECT id, job_group, event FROM sherpa_scaling_events WHERE time >= $1 AND time <= $2 AND job_group = $3`,
+			expectedArgs:  []interface{}{int64(10), int64(20), "example:cache"},
+			name:          "job group and time range",
+		},
+		{
+			filter:        &state.EventFilter{Job: "web_100%", To: 20},
+			expectedQuery: `SELECT id, job_group, event FROM sherpa_scaling_events WHERE time <= $1 AND job_group LIKE $2`,
+			expectedArgs:  []interface{}{int64(20), `web\_100\%:%`},
+			name:          "escaped job prefix",
+		},
+	}
+
+	for _, tc := range testCases {
+		query, args := scalingEventsQuery(tc.filter)
+		assert.Equal(t, tc.expectedQuery, query, tc.name)
+		assert.Equal(t, tc.expectedArgs, args, tc.name)
+	}
+}
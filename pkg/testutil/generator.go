@@ -0,0 +1,48 @@
+// Package testutil provides helpers for writing tests against Sherpa, such as generators of
+// realistic random scaling policies, Nomad jobs and allocation stats, along with golden file
+// assertions. It should only be imported by test files.
+package testutil
+
+import (
+	"fmt"
+	"math/rand"
+)
+
+// Generator produces random, but realistic, test fixtures. Generators are seeded so that a failing
+// test can be reproduced by using the same seed.
+type Generator struct {
+	seed int64
+	rand *rand.Rand
+}
+
+// NewGenerator returns a fixture generator using the passed seed.
+func NewGenerator(seed int64) *Generator {
+	return &Generator{seed: seed, rand: rand.New(rand.NewSource(seed))} // nolint:gosec
+}
+
+// Seed returns the seed of the generator, which should be logged by tests using randomly seeded
+// generators so failures can be reproduced.
+func (g *Generator) Seed() int64 { return g.seed }
+
+// intBetween returns a random int in the range [min, max].
+func (g *Generator) intBetween(min, max int) int {
+	if max <= min {
+		return min
+	}
+	return min + g.rand.Intn(max-min+1)
+}
+
+// float64Between returns a random float64 in the range [min, max), rounded to two decimal places
+// so generated values remain readable within golden files.
+func (g *Generator) float64Between(min, max float64) float64 {
+	v := min + g.rand.Float64()*(max-min)
+	return float64(int(v*100)) / 100
+}
+
+// bool returns a random bool.
+func (g *Generator) bool() bool { return g.rand.Intn(2) == 0 }
+
+// name returns a random name using the passed prefix.
+func (g *Generator) name(prefix string) string {
+	return fmt.Sprintf("%s-%04d", prefix, g.rand.Intn(10000))
+}
@@ -0,0 +1,48 @@
+package testutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerator_GroupScalingPolicy(t *testing.T) {
+	g := NewGenerator(1)
+
+	for i := 0; i < 100; i++ {
+		pol := g.GroupScalingPolicy()
+		assert.Nil(t, pol.Validate())
+		assert.True(t, pol.MinCount < pol.MaxCount)
+		assert.True(t, *pol.ScaleInCPUPercentageThreshold < *pol.ScaleOutCPUPercentageThreshold)
+		assert.True(t, *pol.ScaleInMemoryPercentageThreshold < *pol.ScaleOutMemoryPercentageThreshold)
+	}
+
+	// Generators using the same seed must produce the same fixtures.
+	assert.Equal(t, NewGenerator(13).JobScalingPolicies("cache"), NewGenerator(13).JobScalingPolicies("cache"))
+	AssertGolden(t, "group_scaling_policy", NewGenerator(13).GroupScalingPolicy())
+}
+
+func TestGenerator_AllocResourceUsage(t *testing.T) {
+	g := NewGenerator(1)
+	job := g.Job("example", "cache", "web")
+	allocs := g.Allocations(job)
+
+	assert.Equal(t, *job.TaskGroups[0].Count+*job.TaskGroups[1].Count, len(allocs))
+
+	for _, alloc := range allocs {
+		usage := g.AllocResourceUsage(job, alloc)
+		assert.Len(t, usage.Tasks, 1)
+
+		task := usage.Tasks[alloc.TaskGroup]
+		assert.NotNil(t, task)
+		assert.Equal(t, task.ResourceUsage.CpuStats.TotalTicks, usage.ResourceUsage.CpuStats.TotalTicks)
+		assert.Equal(t, task.ResourceUsage.MemoryStats.RSS, usage.ResourceUsage.MemoryStats.RSS)
+
+		for _, tg := range job.TaskGroups {
+			if *tg.Name == alloc.TaskGroup {
+				assert.True(t, usage.ResourceUsage.CpuStats.TotalTicks <= float64(*tg.Tasks[0].Resources.CPU))
+				assert.True(t, usage.ResourceUsage.MemoryStats.RSS <= uint64(*tg.Tasks[0].Resources.MemoryMB)*1024*1024)
+			}
+		}
+	}
+}
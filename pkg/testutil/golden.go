@@ -0,0 +1,46 @@
+package testutil
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// envUpdateGolden is the environment variable which, when set to true, causes golden files to be
+// written with the actual test output rather than compared.
+const envUpdateGolden = "SHERPA_UPDATE_GOLDEN"
+
+// AssertGolden compares the indented JSON encoding of actual to the golden file testdata/name.golden
+// within the package under test. When the SHERPA_UPDATE_GOLDEN env var is set to true, the golden
+// file is written instead, allowing expected output to be regenerated after intended changes.
+func AssertGolden(t testing.TB, name string, actual interface{}) {
+	t.Helper()
+
+	out, err := json.MarshalIndent(actual, "", "  ")
+	if err != nil {
+		t.Fatalf("failed to marshal golden file output: %v", err)
+	}
+	out = append(out, '\n')
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if os.Getenv(envUpdateGolden) == "true" {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("failed to create golden file directory: %v", err)
+		}
+		if err := ioutil.WriteFile(path, out, 0644); err != nil { // nolint:gosec
+			t.Fatalf("failed to write golden file: %v", err)
+		}
+		return
+	}
+
+	expected, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read golden file, set %s=true to create it: %v", envUpdateGolden, err)
+	}
+	assert.Equal(t, string(expected), string(out), "output does not match golden file "+path)
+}
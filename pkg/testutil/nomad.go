@@ -0,0 +1,82 @@
+package testutil
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/helper"
+)
+
+// Job generates a Nomad service job containing the passed groups. Each group is given a random
+// count and a single task with random CPU and memory resources.
+func (g *Generator) Job(id string, groups ...string) *api.Job {
+	job := &api.Job{
+		ID:   helper.StringToPointer(id),
+		Name: helper.StringToPointer(id),
+		Type: helper.StringToPointer("service"),
+	}
+
+	for _, group := range groups {
+		job.TaskGroups = append(job.TaskGroups, &api.TaskGroup{
+			Name:  helper.StringToPointer(group),
+			Count: helper.IntToPointer(g.intBetween(1, 10)),
+			Tasks: []*api.Task{{
+				Name:   group,
+				Driver: "docker",
+				Resources: &api.Resources{
+					CPU:      helper.IntToPointer(g.intBetween(1, 20) * 100),
+					MemoryMB: helper.IntToPointer(g.intBetween(1, 16) * 128),
+				},
+			}},
+		})
+	}
+	return job
+}
+
+// Allocations generates a running allocation for each count of each group within the job.
+func (g *Generator) Allocations(job *api.Job) []*api.Allocation {
+	var allocs []*api.Allocation
+
+	for _, tg := range job.TaskGroups {
+		for i := 0; i < *tg.Count; i++ {
+			allocs = append(allocs, &api.Allocation{
+				ID:            fmt.Sprintf("%08x-0000-0000-0000-%012x", g.rand.Uint32(), g.rand.Int63n(1<<48)),
+				Name:          fmt.Sprintf("%s.%s[%d]", *job.ID, *tg.Name, i),
+				JobID:         *job.ID,
+				TaskGroup:     *tg.Name,
+				ClientStatus:  "running",
+				DesiredStatus: "run",
+			})
+		}
+	}
+	return allocs
+}
+
+// AllocResourceUsage generates resource usage stats for the allocation. Each task of the group
+// uses between 0 and 100 percent of its CPU and memory resources, with the allocation usage being
+// the sum of its tasks.
+func (g *Generator) AllocResourceUsage(job *api.Job, alloc *api.Allocation) *api.AllocResourceUsage {
+	usage := &api.AllocResourceUsage{
+		ResourceUsage: &api.ResourceUsage{CpuStats: &api.CpuStats{}, MemoryStats: &api.MemoryStats{}},
+		Tasks:         make(map[string]*api.TaskResourceUsage),
+	}
+
+	for _, tg := range job.TaskGroups {
+		if *tg.Name != alloc.TaskGroup {
+			continue
+		}
+
+		for _, task := range tg.Tasks {
+			cpu := g.float64Between(0, float64(*task.Resources.CPU))
+			mem := uint64(g.float64Between(0, float64(*task.Resources.MemoryMB))) * 1024 * 1024
+
+			usage.Tasks[task.Name] = &api.TaskResourceUsage{ResourceUsage: &api.ResourceUsage{
+				CpuStats:    &api.CpuStats{TotalTicks: cpu},
+				MemoryStats: &api.MemoryStats{RSS: mem},
+			}}
+			usage.ResourceUsage.CpuStats.TotalTicks += cpu
+			usage.ResourceUsage.MemoryStats.RSS += mem
+		}
+	}
+	return usage
+}
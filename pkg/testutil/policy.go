@@ -0,0 +1,77 @@
+package testutil
+
+import (
+	"github.com/jrasell/sherpa/pkg/helper"
+	"github.com/jrasell/sherpa/pkg/policy"
+)
+
+// externalProviders are the metric providers used when generating external checks. Providers
+// which require additional query formats, such as Nomad, are excluded.
+var externalProviders = []policy.MetricsProvider{
+	policy.ProviderPrometheus,
+	policy.ProviderInfluxDB,
+	policy.ProviderGraphite,
+	policy.ProviderNewRelic,
+	policy.ProviderDatadog,
+}
+
+// GroupScalingPolicy generates a random, valid, job group scaling policy. Nomad CPU and memory
+// checks are always configured, while disk checks and external checks are randomly included.
+func (g *Generator) GroupScalingPolicy() *policy.GroupScalingPolicy {
+	minCount := g.intBetween(1, 5)
+
+	pol := &policy.GroupScalingPolicy{
+		Enabled:                           true,
+		Cooldown:                          g.intBetween(60, 600),
+		MinCount:                          minCount,
+		MaxCount:                          minCount + g.intBetween(1, 20),
+		ScaleOutCount:                     g.intBetween(1, 3),
+		ScaleInCount:                      g.intBetween(1, 3),
+		ScaleOutCPUPercentageThreshold:    helper.Float64ToPointer(g.float64Between(70, 95)),
+		ScaleInCPUPercentageThreshold:     helper.Float64ToPointer(g.float64Between(5, 40)),
+		ScaleOutMemoryPercentageThreshold: helper.Float64ToPointer(g.float64Between(70, 95)),
+		ScaleInMemoryPercentageThreshold:  helper.Float64ToPointer(g.float64Between(5, 40)),
+	}
+
+	if g.bool() {
+		pol.ScaleOutDiskPercentageThreshold = helper.Float64ToPointer(g.float64Between(70, 95))
+		pol.ScaleInDiskPercentageThreshold = helper.Float64ToPointer(g.float64Between(5, 40))
+	}
+
+	if checks := g.intBetween(0, 2); checks > 0 {
+		pol.ExternalChecks = make(map[string]*policy.ExternalCheck, checks)
+		for i := 0; i < checks; i++ {
+			pol.ExternalChecks[g.name("check")] = g.ExternalCheck()
+		}
+	}
+	return pol
+}
+
+// JobScalingPolicies generates a random group scaling policy for each of the passed groups.
+func (g *Generator) JobScalingPolicies(groups ...string) map[string]*policy.GroupScalingPolicy {
+	out := make(map[string]*policy.GroupScalingPolicy, len(groups))
+
+	for _, group := range groups {
+		out[group] = g.GroupScalingPolicy()
+	}
+	return out
+}
+
+// ExternalCheck generates a random, valid, external check. Scale out checks use the greater-than
+// operator and scale in checks the less-than operator, matching typical policy configuration.
+func (g *Generator) ExternalCheck() *policy.ExternalCheck {
+	check := &policy.ExternalCheck{
+		Enabled:            true,
+		Provider:           externalProviders[g.rand.Intn(len(externalProviders))],
+		Query:              g.name("query"),
+		ComparisonOperator: policy.ComparisonGreaterThan,
+		ComparisonValue:    g.float64Between(10, 1000),
+		Action:             policy.ActionScaleOut,
+	}
+
+	if g.bool() {
+		check.ComparisonOperator = policy.ComparisonLessThan
+		check.Action = policy.ActionScaleIn
+	}
+	return check
+}
@@ -4,17 +4,18 @@ import (
 	"time"
 
 	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/client"
 	"github.com/jrasell/sherpa/pkg/watcher"
 	"github.com/rs/zerolog"
 )
 
 type Watcher struct {
 	logger          zerolog.Logger
-	nomad           *api.Client
+	nomad           *client.NomadPool
 	lastChangeIndex uint64
 }
 
-func New(logger zerolog.Logger, nomad *api.Client) watcher.Watcher {
+func New(logger zerolog.Logger, nomad *client.NomadPool) watcher.Watcher {
 	return &Watcher{
 		logger: logger,
 		nomad:  nomad,
@@ -30,7 +31,7 @@ func (w *Watcher) Run(updateChan chan interface{}) {
 
 	for {
 
-		deployments, meta, err := w.nomad.Deployments().List(q)
+		deployments, meta, err := w.nomad.Client().Deployments().List(q)
 		if err != nil {
 			w.logger.Error().Err(err).Msg("failed to call Nomad API for deployment listing")
 			time.Sleep(10 * time.Second)
@@ -4,17 +4,18 @@ import (
 	"time"
 
 	"github.com/hashicorp/nomad/api"
+	"github.com/jrasell/sherpa/pkg/client"
 	"github.com/jrasell/sherpa/pkg/watcher"
 	"github.com/rs/zerolog"
 )
 
 type Watcher struct {
 	logger          zerolog.Logger
-	nomad           *api.Client
+	nomad           *client.NomadPool
 	lastChangeIndex uint64
 }
 
-func NewWatcher(logger zerolog.Logger, nomad *api.Client) watcher.Watcher {
+func NewWatcher(logger zerolog.Logger, nomad *client.NomadPool) watcher.Watcher {
 	return &Watcher{
 		logger: logger,
 		nomad:  nomad,
@@ -30,7 +31,7 @@ func (w *Watcher) Run(updateChan chan interface{}) {
 
 	for {
 
-		jobs, meta, err := w.nomad.Jobs().List(q)
+		jobs, meta, err := w.nomad.Client().Jobs().List(q)
 		if err != nil {
 			w.logger.Error().Err(err).Msg("failed to call Nomad API for job listing")
 			time.Sleep(10 * time.Second)